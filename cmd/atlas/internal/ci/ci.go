@@ -29,8 +29,9 @@ type (
 	// A ChangeLoader takes a set of migration files and will create multiple schema.Changes out of it.
 	// It will also label migration files as either "generated" or "handcrafted".
 	ChangeLoader interface {
-		// LoadChanges converts each of the given migration files into one Changes.
-		LoadChanges(context.Context, []migrate.File) ([]*sqlcheck.File, error)
+		// LoadChanges converts each of the given migration files into one Changes. The
+		// "base" files are loaded first, and their changes are not returned.
+		LoadChanges(ctx context.Context, base, files []migrate.File) ([]*sqlcheck.File, error)
 	}
 
 	// DirScanner stitches migrate.Dir and migrate.Scanner into one interface.
@@ -167,6 +168,14 @@ type DevLoader struct {
 	Dev *sqlclient.Client
 	// Scan is used for scanning the migration directory.
 	Scan migrate.Scanner
+	// Parser is an optional DDL parser used for detecting renames. Databases report
+	// renamed tables and columns as dropped and added ones. Hence, the statements
+	// that yield such changes are parsed to tell the two apart.
+	Parser migrate.DDLParser
+	// Unparsed holds the statements the Parser failed to parse, keyed by their file
+	// names. The changes of these statements were not checked for renames, and may
+	// report renamed tables or columns as dropped and added ones.
+	Unparsed map[string][]sqlcheck.Diagnostic
 }
 
 // LoadChanges implements the ChangesLoader interface.
@@ -202,7 +211,7 @@ func (d *DevLoader) LoadChanges(ctx context.Context, base, files []migrate.File)
 			return nil, &FileError{File: f.Name(), Err: fmt.Errorf("scanning statements: %w", err)}
 		}
 		for _, s := range stmt {
-			if _, err := d.Dev.ExecContext(ctx, s); err != nil {
+			if err := d.exec(ctx, s); err != nil {
 				return nil, &FileError{File: f.Name(), Err: fmt.Errorf("executing statement: %q: %w", s, err)}
 			}
 		}
@@ -222,7 +231,7 @@ func (d *DevLoader) LoadChanges(ctx context.Context, base, files []migrate.File)
 		}
 		start := current
 		for _, s := range stmts {
-			if _, err := d.Dev.ExecContext(ctx, s); err != nil {
+			if err := d.exec(ctx, s); err != nil {
 				return nil, &FileError{File: f.Name(), Err: fmt.Errorf("executing statement: %w", err)}
 			}
 			target, err := d.Dev.InspectRealm(ctx, nil)
@@ -233,11 +242,17 @@ func (d *DevLoader) LoadChanges(ctx context.Context, base, files []migrate.File)
 			if err != nil {
 				return nil, err
 			}
-			current = target
 			p, err := pos(f, s)
 			if err != nil {
 				return nil, err
 			}
+			if changes, err = d.renames(current, s, changes); err != nil {
+				if d.Unparsed == nil {
+					d.Unparsed = make(map[string][]sqlcheck.Diagnostic)
+				}
+				d.Unparsed[f.Name()] = append(d.Unparsed[f.Name()], sqlcheck.Diagnostic{Pos: p, Text: err.Error()})
+			}
+			current = target
 			diff[i].Changes = append(diff[i].Changes, &sqlcheck.Change{
 				Pos:     p,
 				Stmt:    s,
@@ -251,6 +266,256 @@ func (d *DevLoader) LoadChanges(ctx context.Context, base, files []migrate.File)
 	return diff, nil
 }
 
+// exec executes the statement on the dev database. Statements that invoke external
+// commands, such as online schema change tools, are executed as their SQL equivalents.
+func (d *DevLoader) exec(ctx context.Context, stmt string) error {
+	s, err := migrate.ReplayStmt(d.Dev.Driver, stmt)
+	if err != nil {
+		return err
+	}
+	_, err = d.Dev.ExecContext(ctx, s)
+	return err
+}
+
+// renames replaces the drop and add changes computed by the dev database with the
+// renames described by the statement, if any. The statement is parsed on a copy
+// of the current realm, as the parser modifies the realm it operates on. In case
+// the statement cannot be parsed, the changes are returned as is with the error.
+func (d *DevLoader) renames(current *schema.Realm, stmt string, changes schema.Changes) (schema.Changes, error) {
+	if d.Parser == nil || !dropAdd(changes) {
+		return changes, nil
+	}
+	parsed, err := d.Parser.ParseDDL(copyRealm(current), stmt)
+	if err != nil {
+		return changes, fmt.Errorf("parsing statement for renames: %w", err)
+	}
+	for _, c := range parsed {
+		switch c := c.(type) {
+		case *schema.RenameTable:
+			changes = renameTable(changes, c)
+		case *schema.ModifyTable:
+			for _, mc := range c.Changes {
+				if rc, ok := mc.(*schema.RenameColumn); ok {
+					changes = renameColumn(changes, c.T, rc)
+				}
+			}
+		}
+	}
+	return changes, nil
+}
+
+// dropAdd reports if the changes contain both drop and add changes of tables or columns.
+func dropAdd(changes schema.Changes) bool {
+	var drop, add bool
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.DropTable, *schema.DropColumn:
+			drop = true
+		case *schema.AddTable, *schema.AddColumn:
+			add = true
+		case *schema.ModifyTable:
+			if dropAdd(c.Changes) {
+				return true
+			}
+		}
+	}
+	return drop && add
+}
+
+// copyRealm returns a copy of the realm schemas, tables, columns, indexes and foreign keys,
+// with their references pointing to the copied elements. Attribute and object slices are
+// copied, but their elements are shared, as the parser replaces attributes and does not
+// modify them.
+func copyRealm(r *schema.Realm) *schema.Realm {
+	var (
+		cr = &schema.Realm{
+			Attrs:   append([]schema.Attr(nil), r.Attrs...),
+			Objects: append([]schema.Object(nil), r.Objects...),
+		}
+		tables  = make(map[*schema.Table]*schema.Table)
+		columns = make(map[*schema.Column]*schema.Column)
+	)
+	for _, s := range r.Schemas {
+		cs := &schema.Schema{
+			Name:    s.Name,
+			Realm:   cr,
+			Attrs:   append([]schema.Attr(nil), s.Attrs...),
+			Objects: append([]schema.Object(nil), s.Objects...),
+		}
+		for _, t := range s.Tables {
+			ct := &schema.Table{Name: t.Name, Schema: cs, Attrs: append([]schema.Attr(nil), t.Attrs...)}
+			for _, c := range t.Columns {
+				cc := &schema.Column{Name: c.Name, Default: c.Default, Attrs: append([]schema.Attr(nil), c.Attrs...)}
+				if c.Type != nil {
+					ctype := *c.Type
+					cc.Type = &ctype
+				}
+				columns[c] = cc
+				ct.Columns = append(ct.Columns, cc)
+			}
+			tables[t] = ct
+			cs.Tables = append(cs.Tables, ct)
+		}
+		cr.Schemas = append(cr.Schemas, cs)
+	}
+	// Elements that are not part of the realm (e.g. tables
+	// in other databases) are referenced as they are.
+	table := func(t *schema.Table) *schema.Table {
+		if ct, ok := tables[t]; ok {
+			return ct
+		}
+		return t
+	}
+	column := func(c *schema.Column) *schema.Column {
+		if cc, ok := columns[c]; ok {
+			return cc
+		}
+		return c
+	}
+	index := func(t *schema.Table, idx *schema.Index) *schema.Index {
+		ci := &schema.Index{Name: idx.Name, Unique: idx.Unique, Table: t, Attrs: append([]schema.Attr(nil), idx.Attrs...)}
+		for _, p := range idx.Parts {
+			cp := &schema.IndexPart{SeqNo: p.SeqNo, Desc: p.Desc, X: p.X, Attrs: append([]schema.Attr(nil), p.Attrs...)}
+			if p.C != nil {
+				cp.C = column(p.C)
+				cp.C.Indexes = append(cp.C.Indexes, ci)
+			}
+			ci.Parts = append(ci.Parts, cp)
+		}
+		return ci
+	}
+	for _, s := range r.Schemas {
+		for _, t := range s.Tables {
+			ct := tables[t]
+			if t.PrimaryKey != nil {
+				ct.PrimaryKey = index(ct, t.PrimaryKey)
+			}
+			for _, idx := range t.Indexes {
+				ct.Indexes = append(ct.Indexes, index(ct, idx))
+			}
+			for _, fk := range t.ForeignKeys {
+				cf := &schema.ForeignKey{
+					Symbol:   fk.Symbol,
+					Table:    ct,
+					RefTable: table(fk.RefTable),
+					OnUpdate: fk.OnUpdate,
+					OnDelete: fk.OnDelete,
+					Attrs:    append([]schema.Attr(nil), fk.Attrs...),
+				}
+				for _, c := range fk.Columns {
+					cc := column(c)
+					cc.ForeignKeys = append(cc.ForeignKeys, cf)
+					cf.Columns = append(cf.Columns, cc)
+				}
+				for _, c := range fk.RefColumns {
+					cf.RefColumns = append(cf.RefColumns, column(c))
+				}
+				ct.ForeignKeys = append(ct.ForeignKeys, cf)
+			}
+		}
+	}
+	return cr
+}
+
+// renameTable replaces the DropTable and AddTable changes that match the given rename.
+func renameTable(changes schema.Changes, rename *schema.RenameTable) schema.Changes {
+	drop, add := changes.IndexDropTable(rename.From.Name), changes.IndexAddTable(rename.To.Name)
+	if drop == -1 || add == -1 {
+		return changes
+	}
+	changes[drop] = &schema.RenameTable{
+		From: changes[drop].(*schema.DropTable).T,
+		To:   changes[add].(*schema.AddTable).T,
+	}
+	changes.RemoveIndex(add)
+	return changes
+}
+
+// renameColumn replaces the DropColumn and AddColumn changes
+// of the given table that match the given rename.
+func renameColumn(changes schema.Changes, t *schema.Table, rename *schema.RenameColumn) schema.Changes {
+	for _, c := range changes {
+		m, ok := c.(*schema.ModifyTable)
+		if !ok || m.T.Name != t.Name {
+			continue
+		}
+		mc := schema.Changes(m.Changes)
+		drop, add := mc.IndexDropColumn(rename.From.Name), mc.IndexAddColumn(rename.To.Name)
+		if drop == -1 || add == -1 {
+			continue
+		}
+		mc[drop] = &schema.RenameColumn{
+			From: mc[drop].(*schema.DropColumn).C,
+			To:   mc[add].(*schema.AddColumn).C,
+		}
+		mc.RemoveIndex(add)
+		m.Changes = mc
+	}
+	return changes
+}
+
+// ParseLoader implements the ChangesLoader interface using a DDL parser, and
+// can be used in case no dev-database is available. Note, unlike DevLoader,
+// the Sum of each file holds all changes described by its statements.
+type ParseLoader struct {
+	// Parser used for computing the changes of the statements.
+	Parser migrate.DDLParser
+	// Scan is used for scanning the migration directory.
+	Scan migrate.Scanner
+}
+
+// LoadChanges implements the ChangesLoader interface.
+func (d *ParseLoader) LoadChanges(_ context.Context, base, files []migrate.File) ([]*sqlcheck.File, error) {
+	r := schema.NewRealm()
+	// Bring the realm to the base point.
+	for _, f := range base {
+		stmts, err := d.Scan.Stmts(f)
+		if err != nil {
+			return nil, &FileError{File: f.Name(), Err: fmt.Errorf("scanning statements: %w", err)}
+		}
+		for _, s := range stmts {
+			if _, err := d.Parser.ParseDDL(r, s); err != nil {
+				return nil, &FileError{File: f.Name(), Err: fmt.Errorf("parsing statement: %w", err)}
+			}
+		}
+	}
+	diff := make([]*sqlcheck.File, len(files))
+	for i, f := range files {
+		diff[i] = &sqlcheck.File{
+			File: f,
+		}
+		stmts, err := d.Scan.Stmts(f)
+		if err != nil {
+			return nil, &FileError{File: f.Name(), Err: fmt.Errorf("scanning statements: %w", err)}
+		}
+		for _, s := range stmts {
+			changes, err := d.Parser.ParseDDL(r, s)
+			if err != nil {
+				return nil, &FileError{File: f.Name(), Err: fmt.Errorf("parsing statement: %w", err)}
+			}
+			// The parser modifies the tables in place. Hence, tables are copied
+			// to keep their names as they were when the statement was executed.
+			for j, c := range changes {
+				if m, ok := c.(*schema.ModifyTable); ok {
+					t := *m.T
+					changes[j] = &schema.ModifyTable{T: &t, Changes: m.Changes}
+				}
+			}
+			p, err := pos(f, s)
+			if err != nil {
+				return nil, err
+			}
+			diff[i].Changes = append(diff[i].Changes, &sqlcheck.Change{
+				Pos:     p,
+				Stmt:    s,
+				Changes: changes,
+			})
+			diff[i].Sum = append(diff[i].Sum, changes...)
+		}
+	}
+	return diff, nil
+}
+
 // pos returns the position of a statement in migration file.
 func pos(f migrate.File, stmt string) (int, error) {
 	i := bytes.Index(f.Bytes(), []byte(stmt))
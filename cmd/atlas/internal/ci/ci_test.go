@@ -6,6 +6,7 @@ package ci_test
 
 import (
 	"context"
+	"errors"
 	"io/fs"
 	"os"
 	"os/exec"
@@ -18,8 +19,10 @@ import (
 	"ariga.io/atlas/cmd/atlas/internal/ci"
 	"ariga.io/atlas/sql/migrate"
 	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlcheck"
+	"ariga.io/atlas/sql/sqlcheck/incompat"
 	"ariga.io/atlas/sql/sqlclient"
-	_ "ariga.io/atlas/sql/sqlite"
+	"ariga.io/atlas/sql/sqlite"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/require"
@@ -172,6 +175,120 @@ func TestDevLoader_LoadChanges(t *testing.T) {
 	require.ErrorAs(t, err, &migrate.NotCleanError{})
 }
 
+func TestDevLoader_Renames(t *testing.T) {
+	ctx := context.Background()
+	c, err := sqlclient.Open(ctx, "sqlite://ci_renames?mode=memory&cache=shared&_fk=1")
+	require.NoError(t, err)
+	defer c.Close()
+	base := []migrate.File{
+		testFile{name: "base.sql", content: "CREATE TABLE users (id INT, name TEXT)"},
+	}
+	files := []migrate.File{
+		testFile{name: "1.sql", content: "ALTER TABLE users RENAME COLUMN name TO first_name\nALTER TABLE users RENAME TO people"},
+	}
+	// Without a parser, renames are reported as dropped and added objects.
+	l := &ci.DevLoader{Dev: c, Scan: testDir{}}
+	diff, err := l.LoadChanges(ctx, base, files)
+	require.NoError(t, err)
+	m := diff[0].Changes[0].Changes[0].(*schema.ModifyTable)
+	require.IsType(t, (*schema.DropColumn)(nil), m.Changes[0])
+	require.IsType(t, (*schema.AddColumn)(nil), m.Changes[1])
+
+	l.Parser = sqlite.NewDDLParser("")
+	diff, err = l.LoadChanges(ctx, base, files)
+	require.NoError(t, err)
+	require.Len(t, diff[0].Changes, 2)
+	m = diff[0].Changes[0].Changes[0].(*schema.ModifyTable)
+	require.Equal(t, "users", m.T.Name)
+	require.Len(t, m.Changes, 1)
+	rc := m.Changes[0].(*schema.RenameColumn)
+	require.Equal(t, "name", rc.From.Name)
+	require.Equal(t, "first_name", rc.To.Name)
+	require.Len(t, diff[0].Changes[1].Changes, 1)
+	rt := diff[0].Changes[1].Changes[0].(*schema.RenameTable)
+	require.Equal(t, "users", rt.From.Name)
+	require.Equal(t, "people", rt.To.Name)
+
+	var report *sqlcheck.Report
+	err = incompat.New(incompat.Options{}).Analyze(ctx, &sqlcheck.Pass{
+		File: diff[0],
+		Dev:  c,
+		Reporter: sqlcheck.ReportWriterFunc(func(r sqlcheck.Report) {
+			report = &r
+		}),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Len(t, report.Diagnostics, 2)
+	require.Equal(t, `Renaming column "name" to "first_name" in table "users"`, report.Diagnostics[0].Text)
+	require.Equal(t, `Renaming table "users" to "people"`, report.Diagnostics[1].Text)
+	require.Empty(t, l.Unparsed)
+
+	// Statements that cannot be parsed are reported.
+	l.Parser = errParser{}
+	diff, err = l.LoadChanges(ctx, base, files)
+	require.NoError(t, err)
+	m = diff[0].Changes[0].Changes[0].(*schema.ModifyTable)
+	require.IsType(t, (*schema.DropColumn)(nil), m.Changes[0])
+	require.IsType(t, (*schema.AddColumn)(nil), m.Changes[1])
+	require.Equal(t, map[string][]sqlcheck.Diagnostic{
+		"1.sql": {
+			{Pos: 0, Text: "parsing statement for renames: unexpected statement"},
+			{Pos: 51, Text: "parsing statement for renames: unexpected statement"},
+		},
+	}, l.Unparsed)
+}
+
+// errParser is a DDL parser that fails to parse all statements.
+type errParser struct{}
+
+func (errParser) ParseDDL(*schema.Realm, string) (schema.Changes, error) {
+	return nil, errors.New("unexpected statement")
+}
+
+func TestParseLoader_LoadChanges(t *testing.T) {
+	ctx := context.Background()
+	l := &ci.ParseLoader{Parser: sqlite.NewDDLParser(""), Scan: testDir{}}
+	diff, err := l.LoadChanges(ctx, nil, nil)
+	require.NoError(t, err)
+	require.Empty(t, diff)
+
+	base := []migrate.File{
+		testFile{name: "base.sql", content: "CREATE TABLE users (id INT)"},
+	}
+	files := []migrate.File{
+		testFile{name: "1.sql", content: "CREATE TABLE t1 (id INT)\nINSERT INTO t1 (id) VALUES (1)"},
+		testFile{name: "2.sql", content: "CREATE TABLE t2 (id INT)\nDROP TABLE users"},
+	}
+	diff, err = l.LoadChanges(ctx, base, files)
+	require.NoError(t, err)
+	require.Len(t, diff, 2)
+
+	// File 1.
+	require.Equal(t, files[0], diff[0].File)
+	require.Len(t, diff[0].Changes, 2)
+	require.Equal(t, "CREATE TABLE t1 (id INT)", diff[0].Changes[0].Stmt)
+	require.IsType(t, (*schema.AddTable)(nil), diff[0].Changes[0].Changes[0])
+	require.Equal(t, "INSERT INTO t1 (id) VALUES (1)", diff[0].Changes[1].Stmt)
+	require.Empty(t, diff[0].Changes[1].Changes)
+	require.Len(t, diff[0].Sum, 1)
+
+	// File 2.
+	require.Equal(t, files[1], diff[1].File)
+	require.Len(t, diff[1].Changes, 2)
+	require.Equal(t, 25, diff[1].Changes[1].Pos)
+	require.IsType(t, (*schema.DropTable)(nil), diff[1].Changes[1].Changes[0])
+	require.Equal(t, "users", diff[1].Changes[1].Changes[0].(*schema.DropTable).T.Name)
+	require.Len(t, diff[1].Sum, 2)
+
+	// Unsupported statements are reported on their file.
+	_, err = l.LoadChanges(ctx, nil, []migrate.File{testFile{name: "1.sql", content: "CREATE VIEW v AS SELECT 1"}})
+	var fileErr *ci.FileError
+	require.ErrorAs(t, err, &fileErr)
+	require.Equal(t, "1.sql", fileErr.File)
+	require.EqualError(t, err, `parsing statement: unsupported statement "CREATE VIEW v AS SELECT 1": CREATE VIEW is not supported`)
+}
+
 type testDir struct {
 	ci.DirScanner
 	files []migrate.File
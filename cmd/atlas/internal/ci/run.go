@@ -23,6 +23,10 @@ type Runner struct {
 	// migration changes by the driver.
 	Dev *sqlclient.Client
 
+	// Parser is used for calculating the migration changes in case no
+	// dev driver was configured, and for detecting renames otherwise.
+	Parser migrate.DDLParser
+
 	// RunChangeDetector configures the ChangeDetector to
 	// be used by the runner.
 	ChangeDetector ChangeDetector
@@ -61,7 +65,13 @@ func (r *Runner) summary(ctx context.Context) (*SummaryReport, error) {
 	if err != nil {
 		return nil, err
 	}
-	l := &DevLoader{Dev: r.Dev, Scan: r.Dir}
+	var (
+		dl              = &DevLoader{Dev: r.Dev, Scan: r.Dir, Parser: r.Parser}
+		l  ChangeLoader = dl
+	)
+	if r.Dev == nil && r.Parser != nil {
+		l = &ParseLoader{Parser: r.Parser, Scan: r.Dir}
+	}
 	files, err := l.LoadChanges(ctx, base, feat)
 	if err != nil {
 		return nil, err
@@ -69,6 +79,9 @@ func (r *Runner) summary(ctx context.Context) (*SummaryReport, error) {
 	sum := &SummaryReport{Files: make([]*FileReport, 0, len(files))}
 	for _, f := range files {
 		fr := NewFileReport(f)
+		if ds := dl.Unparsed[f.Name()]; len(ds) > 0 {
+			fr.WriteReport(sqlcheck.Report{Text: "Statements that were not checked for renames", Diagnostics: ds})
+		}
 		if err := r.Analyzer.Analyze(ctx, &sqlcheck.Pass{
 			File:     f,
 			Dev:      r.Dev,
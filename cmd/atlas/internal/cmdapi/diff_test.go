@@ -13,6 +13,7 @@ import (
 	"os"
 	"testing"
 
+	"ariga.io/atlas/sql/schema"
 	"ariga.io/atlas/sql/sqlite"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -38,6 +39,36 @@ func TestDiffCmd_Synced(t *testing.T) {
 	require.EqualValues(t, "Schemas are synced, no changes to be made.\n", s)
 }
 
+func TestDiffCmd_DiffOptions(t *testing.T) {
+	from := openSQLite(t, "create table t1 (id int, name text);")
+	to := openSQLite(t, "create table t1 (id int); create table t2 (id int);")
+	s, err := runCmd(newDiffCmd(), "schema", "diff", "--from", from, "--to", to, "--diff-skip", "drop_column")
+	require.NoError(t, err)
+	require.EqualValues(t, "-- Create \"t2\" table\nCREATE TABLE `t2` (`id` int NULL)\n", s)
+
+	s, err = runCmd(newDiffCmd(), "schema", "diff", "--from", from, "--to", to, "--diff-exclude", "*.t2")
+	require.NoError(t, err)
+	require.Contains(t, s, "DROP TABLE `t1`")
+	require.NotContains(t, s, "t2")
+}
+
+func TestDiffFlags_Options(t *testing.T) {
+	opts, err := (&DiffFlags{Skip: []string{"drop_table"}, Exclude: []string{"*.tmp_*"}, Ignore: []string{"comment"}}).options()
+	require.NoError(t, err)
+	o := schema.NewDiffOptions(opts...)
+	require.True(t, o.Skipped(&schema.DropTable{}))
+	require.False(t, o.Skipped(&schema.DropColumn{}))
+	require.True(t, o.Excluded("public", "tmp_users"))
+	require.True(t, o.Ignore.Is(schema.ChangeComment))
+
+	_, err = (&DiffFlags{Skip: []string{"drop_everything"}}).options()
+	require.EqualError(t, err, `unknown change kind "drop_everything" for flag "diff-skip"`)
+	_, err = (&DiffFlags{Exclude: []string{"["}}).options()
+	require.Error(t, err)
+	_, err = (&DiffFlags{Ignore: []string{"default"}}).options()
+	require.EqualError(t, err, `unknown attribute "default" for flag "diff-ignore"`)
+}
+
 // openSQLite creates a sqlite db, seeds it with the seed query and returns the url to it.
 func openSQLite(t *testing.T, seed string) string {
 	f, err := ioutil.TempFile("", "sqlite.db")
@@ -190,56 +190,225 @@ func TestMigrate_Diff(t *testing.T) {
 	require.ErrorIs(t, err, errLock)
 }
 
+func TestMigrate_DiffSnapshot(t *testing.T) {
+	t.Cleanup(func() { InspectFlags.Format = formatHCL })
+	s, err := runCmd(
+		Root, "schema", "inspect",
+		"-u", openSQLite(t, "create table users (id int primary key, name text not null default 'a8m');"),
+		"--format", "snapshot",
+	)
+	require.NoError(t, err)
+	require.Contains(t, s, `"version": 1`)
+	snap := filepath.Join(t.TempDir(), "snapshot.json")
+	require.NoError(t, os.WriteFile(snap, []byte(s), 0600))
+
+	p := t.TempDir()
+	s, err = runCmd(
+		Root, "migrate", "diff",
+		"name",
+		"--dir", "file://"+p,
+		"--dev-url", openSQLite(t, ""),
+		"--to", "snapshot://"+snap,
+	)
+	require.NoError(t, err)
+	require.Zero(t, s)
+	b, err := os.ReadFile(filepath.Join(p, fmt.Sprintf("%s_name.sql", time.Now().UTC().Format("20060102150405"))))
+	require.NoError(t, err)
+	require.Contains(t, string(b), "CREATE TABLE `users`")
+
+	_, err = runCmd(Root, "schema", "inspect", "-u", openSQLite(t, ""), "--format", "yaml")
+	require.EqualError(t, err, `unknown format "yaml", expect one of: hcl, snapshot, mermaid, dot, plantuml, ent, go`)
+}
+
+func TestMigrate_DiffSQLFiles(t *testing.T) {
+	schema := filepath.Join(t.TempDir(), "schema.sql")
+	require.NoError(t, os.WriteFile(schema, []byte("CREATE TABLE users (\n  id int PRIMARY KEY,\n  name text NOT NULL\n);\nCREATE INDEX users_name ON users(name);\n"), 0600))
+	p := t.TempDir()
+	s, err := runCmd(
+		Root, "migrate", "diff",
+		"name",
+		"--dir", "file://"+p,
+		"--dev-url", openSQLite(t, ""),
+		"--to", "file://"+schema,
+	)
+	require.NoError(t, err)
+	require.Zero(t, s)
+	b, err := os.ReadFile(filepath.Join(p, fmt.Sprintf("%s_name.sql", time.Now().UTC().Format("20060102150405"))))
+	require.NoError(t, err)
+	require.Contains(t, string(b), "CREATE TABLE `users`")
+	require.Contains(t, string(b), "CREATE INDEX `users_name`")
+
+	// The migration directory is in sync with the SQL file.
+	s, err = runCmd(
+		Root, "migrate", "diff",
+		"--dir", "file://"+p,
+		"--dev-url", openSQLite(t, ""),
+		"--to", "file://"+schema,
+	)
+	require.NoError(t, err)
+	require.Equal(t, "The migration directory is synced with the desired state, no changes to be made\n", s)
+}
+
+func TestMigrate_DiffEnt(t *testing.T) {
+	p := t.TempDir()
+	s, err := runCmd(
+		Root, "migrate", "diff",
+		"name",
+		"--dir", "file://"+p,
+		"--dev-url", openSQLite(t, ""),
+		"--to", "ent://../entschema/testdata/schema",
+	)
+	require.NoError(t, err)
+	require.Zero(t, s)
+	b, err := os.ReadFile(filepath.Join(p, fmt.Sprintf("%s_name.sql", time.Now().UTC().Format("20060102150405"))))
+	require.NoError(t, err)
+	require.Contains(t, string(b), "CREATE TABLE `users`")
+	require.Contains(t, string(b), "CREATE TABLE `pets`")
+	require.Contains(t, string(b), "CREATE UNIQUE INDEX `user_name`")
+
+	// The migration directory is in sync with the ent schema.
+	s, err = runCmd(
+		Root, "migrate", "diff",
+		"--dir", "file://"+p,
+		"--dev-url", openSQLite(t, ""),
+		"--to", "ent://../entschema/testdata/schema",
+	)
+	require.NoError(t, err)
+	require.Equal(t, "The migration directory is synced with the desired state, no changes to be made\n", s)
+}
+
+func TestMigrate_OnlineSchemaChange(t *testing.T) {
+	// Global flags are set from other tests ...
+	MigrateFlags.DryRun = false
+	t.Cleanup(func() { MigrateFlags.OSC.Tool, MigrateFlags.OSC.Tables, MigrateFlags.OSC.Args = "", nil, nil })
+	p := t.TempDir()
+	s, err := runCmd(
+		Root, "migrate", "diff",
+		"--dir", "file://"+p,
+		"--dev-url", openSQLite(t, ""),
+		"--to", hclURL(t),
+		"--osc-tool", "lhm",
+	)
+	require.EqualError(t, err, `unknown online schema change tool "lhm", expect one of: gh-ost, pt-online-schema-change`)
+	require.NotEmpty(t, s)
+
+	s, err = runCmd(
+		Root, "migrate", "diff",
+		"--dir", "file://"+p,
+		"--dev-url", openSQLite(t, ""),
+		"--to", hclURL(t),
+		"--osc-tool", "gh-ost",
+		"--osc-table", "users",
+	)
+	require.EqualError(t, err, `--osc-tool is supported only by MySQL, got "sqlite3" dev database`)
+	require.NotEmpty(t, s)
+
+	// Statements that invoke a tool are not executed on the database.
+	dir, err := migrate.NewLocalDir(p)
+	require.NoError(t, err)
+	require.NoError(t, dir.WriteFile("1_init.sql", []byte("CREATE TABLE t (c int);\n")))
+	require.NoError(t, dir.WriteFile("2_alter.sql", []byte("-- alter t online\ngh-ost --table=t --alter=\"ADD COLUMN d int\" --execute;\n")))
+	sum, err := migrate.HashSum(dir)
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(dir, sum))
+	MigrateFlags.OSC.Tool = ""
+	s, err = runCmd(
+		Root, "migrate", "apply",
+		"--dir", "file://"+p,
+		"--url", openSQLite(t, ""),
+	)
+	require.EqualError(t, err, `migration file "2_alter.sql" invokes gh-ost, use the --osc-tool flag to execute it`)
+	require.NotEmpty(t, s)
+
+	// Files that precede the tool invocations can be applied.
+	s, err = runCmd(
+		Root, "migrate", "apply",
+		"--dir", "file://"+p,
+		"--url", openSQLite(t, ""),
+		"1",
+	)
+	require.NoError(t, err)
+	require.Contains(t, s, "CREATE TABLE t (c int);")
+}
+
+func TestMigrate_DiffPlanOptions(t *testing.T) {
+	t.Cleanup(func() {
+		MigrateFlags.Plan.ConcurrentIndex, MigrateFlags.Plan.Validate = false, false
+	})
+	s, err := runCmd(
+		Root, "migrate", "diff",
+		"--dir", "file://"+t.TempDir(),
+		"--dev-url", openSQLite(t, ""),
+		"--to", hclURL(t),
+		"--concurrent-index",
+	)
+	require.EqualError(t, err, `--concurrent-index is supported only by PostgreSQL, got "sqlite3" dev database`)
+	require.NotEmpty(t, s)
+
+	MigrateFlags.Plan.ConcurrentIndex = false
+	s, err = runCmd(
+		Root, "migrate", "diff",
+		"--dir", "file://"+t.TempDir(),
+		"--dev-url", openSQLite(t, ""),
+		"--to", hclURL(t),
+		"--validate-constraints",
+	)
+	require.EqualError(t, err, `--validate-constraints is supported only by PostgreSQL, got "sqlite3" dev database`)
+	require.NotEmpty(t, s)
+}
+
 func TestMigrate_New(t *testing.T) {
 	var (
-		p = t.TempDir()
-		v = time.Now().UTC().Format("20060102150405")
+		p   = t.TempDir()
+		now = time.Now().UTC()
+		// Versions of new files follow the latest version in the directory.
+		v = func(i int) string { return now.Add(time.Duration(i) * time.Second).Format("20060102150405") }
 	)
 
 	s, err := runCmd(Root, "migrate", "new", "--dir", "file://"+p)
 	require.Zero(t, s)
 	require.NoError(t, err)
-	require.FileExists(t, filepath.Join(p, v+".sql"))
+	require.FileExists(t, filepath.Join(p, v(0)+".sql"))
 	require.FileExists(t, filepath.Join(p, "atlas.sum"))
 	require.Equal(t, 2, countFiles(t, p))
 
 	s, err = runCmd(Root, "migrate", "new", "my-migration-file", "--dir", "file://"+p)
 	require.Zero(t, s)
 	require.NoError(t, err)
-	require.FileExists(t, filepath.Join(p, v+"_my-migration-file.sql"))
+	require.FileExists(t, filepath.Join(p, v(1)+"_my-migration-file.sql"))
 	require.FileExists(t, filepath.Join(p, "atlas.sum"))
 	require.Equal(t, 3, countFiles(t, p))
 
 	s, err = runCmd(Root, "migrate", "new", "golang-migrate", "--dir", "file://"+p, "--format", formatGolangMigrate)
 	require.Zero(t, s)
 	require.NoError(t, err)
-	require.FileExists(t, filepath.Join(p, v+"_golang-migrate.up.sql"))
-	require.FileExists(t, filepath.Join(p, v+"_golang-migrate.down.sql"))
+	require.FileExists(t, filepath.Join(p, v(2)+"_golang-migrate.up.sql"))
+	require.FileExists(t, filepath.Join(p, v(2)+"_golang-migrate.down.sql"))
 	require.Equal(t, 5, countFiles(t, p))
 
 	s, err = runCmd(Root, "migrate", "new", "goose", "--dir", "file://"+p, "--format", formatGoose)
 	require.Zero(t, s)
 	require.NoError(t, err)
-	require.FileExists(t, filepath.Join(p, v+"_goose.sql"))
+	require.FileExists(t, filepath.Join(p, v(3)+"_goose.sql"))
 	require.Equal(t, 6, countFiles(t, p))
 
 	s, err = runCmd(Root, "migrate", "new", "flyway", "--dir", "file://"+p, "--format", formatFlyway)
 	require.Zero(t, s)
 	require.NoError(t, err)
-	require.FileExists(t, filepath.Join(p, fmt.Sprintf("V%s__%s.sql", v, formatFlyway)))
-	require.FileExists(t, filepath.Join(p, fmt.Sprintf("U%s__%s.sql", v, formatFlyway)))
+	require.FileExists(t, filepath.Join(p, fmt.Sprintf("V%s__%s.sql", v(4), formatFlyway)))
+	require.FileExists(t, filepath.Join(p, fmt.Sprintf("U%s__%s.sql", v(4), formatFlyway)))
 	require.Equal(t, 8, countFiles(t, p))
 
 	s, err = runCmd(Root, "migrate", "new", "liquibase", "--dir", "file://"+p, "--format", formatLiquibase)
 	require.Zero(t, s)
 	require.NoError(t, err)
-	require.FileExists(t, filepath.Join(p, v+"_liquibase.sql"))
+	require.FileExists(t, filepath.Join(p, v(5)+"_liquibase.sql"))
 	require.Equal(t, 9, countFiles(t, p))
 
 	s, err = runCmd(Root, "migrate", "new", "dbmate", "--dir", "file://"+p, "--format", formatDbmate)
 	require.Zero(t, s)
 	require.NoError(t, err)
-	require.FileExists(t, filepath.Join(p, v+"_dbmate.sql"))
+	require.FileExists(t, filepath.Join(p, v(6)+"_dbmate.sql"))
 	require.Equal(t, 10, countFiles(t, p))
 
 	f := filepath.Join("testdata", "mysql", "new.sql")
@@ -340,6 +509,18 @@ func TestMigrate_Lint(t *testing.T) {
 	)
 	require.NoError(t, err)
 	require.Equal(t, "Destructive changes detected in file new.sql:\n\n\tL1: Dropping table \"t\"\n\n", s)
+
+	// Renames are detected, although the dev database reports them as dropped and added objects.
+	err = os.WriteFile(filepath.Join(p, "new.sql"), []byte("ALTER TABLE t RENAME COLUMN c TO d;\nALTER TABLE t RENAME TO t2;"), 0600)
+	require.NoError(t, err)
+	s, err = runCmd(
+		Root, "migrate", "lint",
+		"--dir", "file://"+p,
+		"--dev-url", openSQLite(t, ""),
+		"--latest", "1",
+	)
+	require.NoError(t, err)
+	require.Equal(t, "Backward incompatible changes detected in file new.sql:\n\n\tL1: Renaming column \"c\" to \"d\" in table \"t\"\n\tL2: Renaming table \"t\" to \"t2\"\n\n", s)
 	s, err = runCmd(
 		Root, "migrate", "lint",
 		"--dir", "file://"+p,
@@ -351,6 +532,44 @@ func TestMigrate_Lint(t *testing.T) {
 	require.Equal(t, "new.sql", s)
 }
 
+func TestMigrate_LintParser(t *testing.T) {
+	// Global flags are set from other tests ...
+	MigrateFlags.DevURL, MigrateFlags.Lint.Format = "", ""
+	t.Cleanup(func() { MigrateFlags.Lint.Dialect, MigrateFlags.Schemas = "", nil })
+	p := t.TempDir()
+	err := os.WriteFile(filepath.Join(p, "1_base.sql"), []byte("CREATE TABLE `users` (`id` int, `name` varchar(255), `bio` text);\n"), 0600)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(p, "2_new.sql"), []byte("USE `test`;\nALTER TABLE `users` ADD COLUMN `email` varchar(255) AFTER `id`, MODIFY `bio` varchar(64);\nRENAME TABLE `users` TO `people`;\nALTER TABLE `people` DROP COLUMN `name`;\n"), 0600)
+	require.NoError(t, err)
+	s, err := runCmd(
+		Root, "migrate", "lint",
+		"--dir", "file://"+p,
+		"--latest", "1",
+	)
+	require.EqualError(t, err, "--dev-url or --dialect is required")
+	require.NotEmpty(t, s)
+
+	s, err = runCmd(
+		Root, "migrate", "lint",
+		"--dir", "file://"+p,
+		"--dialect", "oracle",
+		"--latest", "1",
+	)
+	require.EqualError(t, err, `unknown dialect "oracle", expect one of: mysql, postgres, sqlite`)
+	require.NotEmpty(t, s)
+
+	s, err = runCmd(
+		Root, "migrate", "lint",
+		"--dir", "file://"+p,
+		"--dialect", "mysql",
+		"--schema", "test",
+		"--latest", "1",
+	)
+	require.NoError(t, err)
+	require.Equal(t, "Destructive changes detected in file 2_new.sql:\n\n\tL4: Dropping non-virtual column \"name\"\n\n"+
+		"Backward incompatible changes detected in file 2_new.sql:\n\n\tL2: Changing the type of column \"bio\" in table \"users\" from \"text\" to \"varchar(64)\"\n\tL3: Renaming table \"users\" to \"people\"\n\n", s)
+}
+
 const testSchema = `
 schema "main" {
 }
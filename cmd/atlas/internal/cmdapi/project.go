@@ -38,6 +38,14 @@ type MigrationDir struct {
 	Format string `spec:"format"`
 }
 
+// Diff represents the diff policy for the Env. Its attributes are
+// equivalent to the diff-skip, diff-exclude and diff-ignore flags.
+type Diff struct {
+	Skip    []string `spec:"skip"`
+	Exclude []string `spec:"exclude"`
+	Ignore  []string `spec:"ignore"`
+}
+
 // asMap returns the extra attributes stored in the Env as a map[string]string.
 func (e *Env) asMap() (map[string]string, error) {
 	m := make(map[string]string, len(e.Extra.Attrs))
@@ -72,9 +80,15 @@ type Env struct {
 	// List of schemas in this database that are managed by Atlas.
 	Schemas []string `spec:"schemas"`
 
+	// List of glob patterns of schemas or tables that are not managed by Atlas.
+	Exclude []string `spec:"exclude"`
+
 	// Directory containing the migrations for the env.
 	MigrationDir *MigrationDir `spec:"migration_dir"`
 
+	// Diff policy of the env.
+	Diff *Diff `spec:"diff"`
+
 	schemahcl.DefaultExtension
 }
 
@@ -27,11 +27,18 @@ env "local" {
 	dev = "docker://mysql/8"
 	src = "./app.hcl"
 	schemas = ["hello", "world"]
+	exclude = ["tmp_*"]
 	
 	migration_dir {
 		url = "file://migrations"
 		format = atlas
 	}
+
+	diff {
+		skip = ["drop_table", "drop_column"]
+		exclude = ["*.tmp_*"]
+		ignore = ["comment"]
+	}
 	
 	bool = true
 	integer = 42
@@ -54,10 +61,16 @@ env "local" {
 			DevURL:  "docker://mysql/8",
 			Source:  "./app.hcl",
 			Schemas: []string{"hello", "world"},
+			Exclude: []string{"tmp_*"},
 			MigrationDir: &MigrationDir{
 				URL:    "file://migrations",
 				Format: formatAtlas,
 			},
+			Diff: &Diff{
+				Skip:    []string{"drop_table", "drop_column"},
+				Exclude: []string{"*.tmp_*"},
+				Ignore:  []string{"comment"},
+			},
 			DefaultExtension: schemahcl.DefaultExtension{
 				Extra: schemahcl.Resource{
 					Attrs: []*schemahcl.Attr{
@@ -6,12 +6,15 @@ package cmdapi
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
 	"testing"
 
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
 	"github.com/stretchr/testify/require"
 )
 
@@ -146,3 +149,223 @@ func setupFmtTest(t *testing.T, inputDir map[string]string) string {
 	require.NoError(t, err)
 	return dir
 }
+
+func TestInspectOption(t *testing.T) {
+	opts, err := inspectOption("", []string{"public"}, []string{"app_*", "public.audit_*"}, []string{"tmp", "public.tmp_*"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"public", "app_*", "public.audit_*"}, opts.Schemas)
+	require.Equal(t, []string{"tmp", "public.tmp_*"}, opts.Exclude)
+
+	// Unqualified patterns select the tables of the schema the URL is bound to.
+	opts, err = inspectOption("app", []string{"app"}, []string{"audit_*", "!audit_tmp"}, []string{"schema_migrations"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"app", "app.audit_*", "!app.audit_tmp"}, opts.Schemas)
+	require.Equal(t, []string{"app.schema_migrations"}, opts.Exclude)
+
+	_, err = inspectOption("", nil, []string{"a.b.c"}, nil)
+	require.EqualError(t, err, `invalid pattern "a.b.c": expect schema or table patterns`)
+	_, err = inspectOption("", nil, nil, []string{"["})
+	require.Error(t, err)
+}
+
+func TestSchemaApply_SQLFiles(t *testing.T) {
+	t.Cleanup(func() {
+		ApplyFlags.DevURL, ApplyFlags.DryRun, ApplyFlags.Paths = "", false, nil
+	})
+	p := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(p, "1_users.sql"), []byte("CREATE TABLE users (id int PRIMARY KEY);\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(p, "2_pets.sql"), []byte("CREATE TABLE pets (\n  id int PRIMARY KEY,\n  owner_id int REFERENCES users(id)\n);\n"), 0600))
+	s, err := runCmd(
+		Root, "schema", "apply",
+		"-u", openSQLite(t, "create table t (c int);"),
+		"--dev-url", openSQLite(t, ""),
+		"-f", p,
+		"--dry-run",
+	)
+	require.NoError(t, err)
+	require.Contains(t, s, "DROP TABLE `t`")
+	require.Contains(t, s, "CREATE TABLE `users`")
+	require.Contains(t, s, "CREATE TABLE `pets`")
+
+	// Dev database is required.
+	ApplyFlags.DevURL, ApplyFlags.Paths = "", nil
+	_, err = runCmd(Root, "schema", "apply", "-u", openSQLite(t, ""), "-f", filepath.Join(p, "1_users.sql"))
+	require.EqualError(t, err, `flag "dev-url" is required for applying SQL schema files`)
+
+	// SQL and HCL files cannot be mixed.
+	require.NoError(t, os.WriteFile(filepath.Join(p, "schema.hcl"), []byte(`schema "main" {}`), 0600))
+	ApplyFlags.Paths = nil
+	_, err = runCmd(Root, "schema", "apply", "-u", openSQLite(t, ""), "--dev-url", openSQLite(t, ""), "-f", p)
+	require.EqualError(t, err, fmt.Sprintf("cannot mix SQL and HCL schema files in: [%s]", p))
+}
+
+func TestBindSchema(t *testing.T) {
+	var (
+		dev    = &sqlclient.Client{URL: &sqlclient.URL{Schema: "dev"}}
+		target = &sqlclient.Client{URL: &sqlclient.URL{Schema: "prod"}}
+	)
+	// A single dev schema is mapped to the target schema.
+	r := schema.NewRealm(schema.New("dev").AddTables(schema.NewTable("users")))
+	bindSchema(r, dev, target)
+	require.Equal(t, "prod", r.Schemas[0].Name)
+	require.Equal(t, "prod", r.Schemas[0].Tables[0].Schema.Name)
+
+	// The bound dev schema is mapped, other schemas are kept.
+	r = schema.NewRealm(schema.New("dev"), schema.New("other"))
+	bindSchema(r, dev, target)
+	require.Equal(t, "prod", r.Schemas[0].Name)
+	require.Equal(t, "other", r.Schemas[1].Name)
+
+	// Nothing is mapped for unbound targets.
+	r = schema.NewRealm(schema.New("dev"))
+	bindSchema(r, dev, &sqlclient.Client{URL: &sqlclient.URL{}})
+	require.Equal(t, "dev", r.Schemas[0].Name)
+}
+
+func TestSchemaApply_Ent(t *testing.T) {
+	t.Cleanup(func() {
+		ApplyFlags.DevURL, ApplyFlags.DryRun, ApplyFlags.Paths = "", false, nil
+	})
+	s, err := runCmd(
+		Root, "schema", "apply",
+		"-u", openSQLite(t, "create table t (c int);"),
+		"--dev-url", openSQLite(t, ""),
+		"-f", "ent://../entschema/testdata/schema",
+		"--dry-run",
+	)
+	require.NoError(t, err)
+	require.Contains(t, s, "DROP TABLE `t`")
+	require.Contains(t, s, "CREATE TABLE `users`")
+	require.Contains(t, s, "CREATE TABLE `pets`")
+
+	// Dev database is required.
+	ApplyFlags.DevURL, ApplyFlags.Paths = "", nil
+	_, err = runCmd(Root, "schema", "apply", "-u", openSQLite(t, ""), "-f", "ent://../entschema/testdata/schema")
+	require.EqualError(t, err, `flag "dev-url" is required for applying ent schema packages`)
+}
+
+func TestSchemaInspect_Diagram(t *testing.T) {
+	t.Cleanup(func() {
+		InspectFlags.Format = formatHCL
+		InspectFlags.Tables = nil
+		InspectFlags.Depth = 0
+	})
+	u := openSQLite(t, "create table users (id int primary key); create table posts (id int primary key, author_id int references users(id)); create table tags (id int primary key);")
+	s, err := runCmd(Root, "schema", "inspect", "-u", u, "--format", "mermaid", "--table", "posts", "--depth", "1")
+	require.NoError(t, err)
+	require.Regexp(t, "^erDiagram\n", s)
+	require.Contains(t, s, "  users {\n")
+	require.Contains(t, s, "  posts {\n")
+	require.Contains(t, s, "  users |o--o{ posts : \"0\"\n")
+	require.NotContains(t, s, "tags")
+
+	InspectFlags.Tables, InspectFlags.Depth = nil, 0
+	s, err = runCmd(Root, "schema", "inspect", "-u", u, "--format", "plantuml")
+	require.NoError(t, err)
+	require.Contains(t, s, `entity "tags" as tags {`)
+
+	_, err = runCmd(Root, "schema", "inspect", "-u", u, "--format", "hcl", "--table", "users")
+	require.EqualError(t, err, `flags "table" and "depth" are supported only by the diagram formats`)
+}
+
+func TestSchemaInspect_Concurrency(t *testing.T) {
+	t.Cleanup(func() { SchemaFlags.Concurrency = 0 })
+	u := openSQLite(t, "create table users (id int primary key); create table posts (id int primary key, author_id int references users(id));")
+	want, err := runCmd(Root, "schema", "inspect", "-u", u)
+	require.NoError(t, err)
+	s, err := runCmd(Root, "schema", "inspect", "-u", u, "--inspect-concurrency", "4")
+	require.NoError(t, err)
+	require.Equal(t, 4, SchemaFlags.Concurrency)
+	require.Equal(t, want, s)
+}
+
+func TestSchemaInspect_Code(t *testing.T) {
+	t.Cleanup(func() {
+		InspectFlags.Format = formatHCL
+		InspectFlags.Out = ""
+	})
+	u := openSQLite(t, "create table users (id integer primary key, name text not null default 'a8m'); create table posts (id integer primary key, author_id integer references users(id));")
+	s, err := runCmd(Root, "schema", "inspect", "-u", u, "--format", "ent")
+	require.NoError(t, err)
+	require.Contains(t, s, "// user.go\npackage schema\n")
+	require.Contains(t, s, `field.Text("name").
+			SchemaType(map[string]string{dialect.SQLite: "text"}).
+			Default("a8m"),`)
+	require.Contains(t, s, `edge.From("author", User.Type).Ref("posts").Field("author_id").Unique(),`)
+
+	out := filepath.Join(t.TempDir(), "models")
+	s, err = runCmd(Root, "schema", "inspect", "-u", u, "--format", "go", "--out", out)
+	require.NoError(t, err)
+	require.Empty(t, s)
+	b, err := os.ReadFile(filepath.Join(out, "post.go"))
+	require.NoError(t, err)
+	require.Equal(t, `package models
+
+// Post represents a row in the "posts" table.
+type Post struct {
+	AuthorID *int64 `+"`"+`db:"author_id"`+"`"+`
+	ID       int64  `+"`"+`db:"id"`+"`"+`
+}
+`, string(b))
+
+	_, err = runCmd(Root, "schema", "inspect", "-u", u, "--format", "hcl", "--out", out)
+	require.EqualError(t, err, `flag "out" is supported only by the code formats`)
+}
+
+func TestSchemaDoc(t *testing.T) {
+	t.Cleanup(func() {
+		DocFlags.Format = "markdown"
+		DocFlags.Out = ""
+	})
+	u := openSQLite(t, "create table users (id int primary key, name text not null default 'a8m'); create table posts (id int primary key, author_id int references users(id));")
+	s, err := runCmd(Root, "schema", "doc", "-u", u)
+	require.NoError(t, err)
+	require.Contains(t, s, "# main\n")
+	require.Contains(t, s, "| name | TEXT | NO | 'a8m' |  |  |\n")
+	require.NotContains(t, s, "CreateStmt")
+	require.Contains(t, s, "| 0 | author_id | [users](#users) (id) |")
+
+	out := t.TempDir()
+	s, err = runCmd(Root, "schema", "doc", "-u", u, "--format", "html", "--out", out)
+	require.NoError(t, err)
+	require.Empty(t, s)
+	b, err := os.ReadFile(filepath.Join(out, "main.html"))
+	require.NoError(t, err)
+	require.Contains(t, string(b), `<h3 id="posts">posts</h3>`)
+
+	_, err = runCmd(Root, "schema", "doc", "-u", "file://schema.hcl")
+	require.EqualError(t, err, `flag "dev-url" is required for reading schema files`)
+}
+
+func TestSchemaConvert(t *testing.T) {
+	t.Cleanup(func() {
+		ConvertFlags.From = ""
+		ConvertFlags.To = ""
+	})
+	u := openSQLite(t, "create table users (id integer primary key autoincrement, name varchar(255) not null default 'a8m', uid uuid);")
+	s, err := runCmd(Root, "schema", "convert", "-u", u, "--to", "postgres")
+	require.NoError(t, err)
+	require.Contains(t, s, `schema "public" {`)
+	require.Contains(t, s, `  column "id" {
+    null = false
+    type = bigint
+    identity {
+      generated = BY_DEFAULT
+    }
+  }`)
+	require.Contains(t, s, `  column "name" {
+    null    = false
+    type    = character_varying(255)
+    default = "a8m"
+  }`)
+
+	s, err = runCmd(Root, "schema", "convert", "-u", u, "--to", "mysql")
+	require.NoError(t, err)
+	require.Contains(t, s, "type = char(36)")
+	require.Contains(t, s, "lossy conversion: main.users.uid: uuid is converted to char(36)\n")
+
+	_, err = runCmd(Root, "schema", "convert", "-u", u, "--to", "oracle")
+	require.EqualError(t, err, `unknown dialect "oracle", expect one of: mysql, postgres, sqlite`)
+	_, err = runCmd(Root, "schema", "convert", "-u", "snapshot://realm.json", "--to", "mysql")
+	require.EqualError(t, err, `flag "from" is required when the dialect cannot be detected from the URL`)
+}
@@ -0,0 +1,632 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package codegen generates Go code from inspected schemas. It supports two
+// formats: ent schemas (one file per entity under ent/schema), and plain Go
+// structs with "db" tags. The generated code is a starting point for working
+// with existing databases, and is expected to be reviewed and edited by users.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	goformat "go/format"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlconvert"
+	"ariga.io/atlas/sql/sqlite"
+
+	"github.com/go-openapi/inflect"
+)
+
+// List of supported code formats.
+const (
+	FormatEnt = "ent"
+	FormatGo  = "go"
+)
+
+// Formats holds the names of the supported code formats.
+var Formats = []string{FormatEnt, FormatGo}
+
+type (
+	// Options configures the code generation.
+	Options struct {
+		// Package is the name of the generated package. Defaults to
+		// "schema" for the ent format, and "models" for Go structs.
+		Package string
+		// Dialect of the inspected database. One of sqlconvert.Dialects.
+		// It is required by the ent format for setting the schema types
+		// of columns that do not match the ent defaults.
+		Dialect string
+	}
+
+	// A File is a generated Go source file.
+	File struct {
+		Name    string // File name, e.g. "user.go".
+		Content []byte // Formatted Go source.
+	}
+
+	// generator holds the state of a code generation.
+	generator struct {
+		Options
+		nodes []*node
+		byT   map[*schema.Table]*node
+	}
+
+	// node describes the type generated for a table.
+	node struct {
+		name    string                  // Go type name, e.g. "User".
+		t       *schema.Table           // Table of the node.
+		id      *fieldSpec              // ID field, if the table has a single-column primary key.
+		fields  []*fieldSpec            // Non-ID fields, ordered by columns.
+		edges   []string                // Rendered ent edges.
+		indexes []*schema.Index         // Indexes, excluding the primary key.
+		names   map[string]bool         // Used names of fields and edges.
+		notes   []string                // Notes about parts that were not generated.
+		o2o     map[*schema.Column]bool // Columns of O2O edges, made unique by ent.
+		join    bool                    // M2M join tables are generated as edges.
+	}
+
+	// fieldSpec describes the field generated for a column.
+	fieldSpec struct {
+		name   string // Field name, e.g. "user_id".
+		c      *schema.Column
+		kind   string   // Function name of the ent field builder, e.g. "Int64".
+		args   []string // Extra arguments of the field builder, e.g. "json.RawMessage{}".
+		calls  []string // Rendered calls on the field builder, e.g. "MaxLen(64)".
+		goType string   // Type of the struct field, e.g. "int64".
+	}
+)
+
+// Generate generates the Go files for the realm in the given format. A file
+// is generated for each table, and files are sorted by their names.
+func Generate(r *schema.Realm, format string, opts *Options) ([]*File, error) {
+	g := &generator{byT: make(map[*schema.Table]*node)}
+	if opts != nil {
+		g.Options = *opts
+	}
+	var render func(*node) ([]byte, error)
+	switch format {
+	case FormatEnt:
+		if _, ok := formatters[g.Dialect]; !ok {
+			return nil, fmt.Errorf("codegen: unknown dialect %q, expect one of: %s", g.Dialect, strings.Join(sqlconvert.Dialects, ", "))
+		}
+		if g.Package == "" {
+			g.Package = "schema"
+		}
+		render = g.ent
+	case FormatGo:
+		if g.Package == "" {
+			g.Package = "models"
+		}
+		render = g.structs
+	default:
+		return nil, fmt.Errorf("codegen: unknown format %q", format)
+	}
+	if err := g.load(r); err != nil {
+		return nil, err
+	}
+	files := make([]*File, 0, len(g.nodes))
+	for _, n := range g.nodes {
+		if n.join && format == FormatEnt {
+			continue
+		}
+		b, err := render(n)
+		if err != nil {
+			return nil, err
+		}
+		src, err := goformat.Source(b)
+		if err != nil {
+			return nil, fmt.Errorf("codegen: formatting %s: %w", n.name, err)
+		}
+		files = append(files, &File{Name: strings.ToLower(n.name) + ".go", Content: src})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files, nil
+}
+
+// load creates the nodes of the realm tables, and connects them using edges.
+func (g *generator) load(r *schema.Realm) error {
+	types := make(map[string]*schema.Table)
+	for _, s := range r.Schemas {
+		for _, t := range s.Tables {
+			n := &node{name: typeName(t.Name), t: t, names: make(map[string]bool), o2o: make(map[*schema.Column]bool)}
+			if t2, ok := types[n.name]; ok {
+				return fmt.Errorf("codegen: tables %q and %q are both mapped to type %q", qualified(t2), qualified(t), n.name)
+			}
+			types[n.name] = t
+			g.nodes = append(g.nodes, n)
+			g.byT[t] = n
+		}
+	}
+	for _, n := range g.nodes {
+		if err := g.fields(n); err != nil {
+			return err
+		}
+	}
+	for _, n := range g.nodes {
+		n.join = g.isJoin(n)
+	}
+	for _, n := range g.nodes {
+		if n.join {
+			g.m2m(n)
+		} else {
+			g.edges(n)
+		}
+	}
+	return nil
+}
+
+// fields creates the fields of the node.
+func (g *generator) fields(n *node) error {
+	var pk []*schema.Column
+	if n.t.PrimaryKey != nil {
+		for _, p := range n.t.PrimaryKey.Parts {
+			if p.C == nil {
+				return fmt.Errorf("codegen: primary key of table %q contains an expression", n.t.Name)
+			}
+			pk = append(pk, p.C)
+		}
+	}
+	if len(pk) != 1 {
+		if len(pk) > 1 && !g.isJoinCandidate(n.t) {
+			n.notes = append(n.notes, fmt.Sprintf("The composite primary key (%s) of the table is not supported by ent.", columnNames(pk)))
+		} else if len(pk) == 0 {
+			n.notes = append(n.notes, "The table has no primary key, which is required by ent.")
+		}
+		pk = nil
+	}
+	for _, c := range n.t.Columns {
+		f, err := g.field(c)
+		if err != nil {
+			return fmt.Errorf("codegen: column %q of table %q: %w", c.Name, n.t.Name, err)
+		}
+		if len(pk) == 1 && pk[0] == c {
+			// Fields of the ID are required and managed by the database.
+			f.calls = removeCalls(f.calls, "StorageKey(", "Optional()", "Default(", "UpdateDefault(", "Annotations(")
+			f.goType = strings.TrimPrefix(f.goType, "*")
+			if f.name = "id"; c.Name != f.name {
+				f.calls = append([]string{fmt.Sprintf("StorageKey(%q)", c.Name)}, f.calls...)
+			}
+			n.id = f
+			n.names["id"] = true
+			continue
+		}
+		if n.names[f.name] {
+			return fmt.Errorf("codegen: column %q of table %q is mapped to duplicate field %q", c.Name, n.t.Name, f.name)
+		}
+		n.names[f.name] = true
+		n.fields = append(n.fields, f)
+	}
+	n.indexes = append(n.indexes, n.t.Indexes...)
+	return nil
+}
+
+// field creates the field of the column.
+func (g *generator) field(c *schema.Column) (*fieldSpec, error) {
+	f := &fieldSpec{name: fieldName(c.Name), c: c}
+	if f.name != c.Name {
+		f.calls = append(f.calls, fmt.Sprintf("StorageKey(%q)", c.Name))
+	}
+	// force indicates the type must be set explicitly, as it cannot be
+	// derived from the field type (e.g. decimal columns), and omit that
+	// the type is managed by ent (e.g. auto-increment serial columns).
+	var force, omit bool
+	switch t := c.Type.Type.(type) {
+	case *schema.BoolType:
+		f.kind, f.goType = "Bool", "bool"
+	case *schema.IntegerType:
+		f.intKind(intBits(g.Dialect, t.T), t.Unsigned)
+	case *postgres.SerialType:
+		f.intKind(serialBits[t.T], false)
+		omit = true
+	case *schema.FloatType:
+		f.kind, f.goType = "Float", "float64"
+		if floatBits(g.Dialect, t) == 32 {
+			f.kind, f.goType = "Float32", "float32"
+		}
+	case *schema.DecimalType:
+		f.kind, f.goType, force = "Float", "float64", true
+	case *schema.StringType:
+		f.kind, f.goType = "String", "string"
+		if textTypes[strings.ToLower(t.T)] {
+			f.kind = "Text"
+		} else if t.Size > 0 {
+			f.calls = append(f.calls, fmt.Sprintf("MaxLen(%d)", t.Size))
+		}
+	case *schema.BinaryType:
+		f.kind, f.goType = "Bytes", "[]byte"
+	case *schema.TimeType:
+		f.kind, f.goType = "Time", "time.Time"
+	case *schema.JSONType:
+		f.kind, f.goType, f.args = "JSON", "json.RawMessage", []string{"json.RawMessage{}"}
+	case *schema.EnumType:
+		f.kind, f.goType = "Enum", "string"
+		if len(t.Values) == 0 {
+			return nil, fmt.Errorf("enum type %q has no values", t.T)
+		}
+		vs := make([]string, len(t.Values))
+		for i, v := range t.Values {
+			vs[i] = strconv.Quote(v)
+		}
+		f.calls = append(f.calls, fmt.Sprintf("Values(%s)", strings.Join(vs, ", ")))
+	case *postgres.UUIDType, *sqlite.UUIDType:
+		f.kind, f.goType, f.args = "UUID", "string", []string{"uuid.UUID{}"}
+	default:
+		f.kind, f.goType, force = "String", "string", true
+	}
+	if g.Dialect != "" && f.kind != "Bool" && !omit {
+		if typ := g.formatType(c); typ != "" && (force || typ != g.defaultType(f)) {
+			f.calls = append(f.calls, fmt.Sprintf("SchemaType(map[string]string{%s: %q})", dialectConst[g.Dialect], typ))
+		}
+	}
+	if c.Type.Null {
+		f.calls = append(f.calls, "Optional()")
+		if f.goType != "[]byte" && f.goType != "json.RawMessage" {
+			f.goType = "*" + f.goType
+		}
+	}
+	f.defaults(c)
+	if c, ok := comment(c.Attrs); ok {
+		f.calls = append(f.calls, fmt.Sprintf("Comment(%q)", c))
+	}
+	return f, nil
+}
+
+// intKind sets the integer builder of the field by its size in bits.
+func (f *fieldSpec) intKind(bits int, unsigned bool) {
+	switch bits {
+	case 8, 16, 32:
+		f.kind = fmt.Sprintf("Int%d", bits)
+		f.goType = fmt.Sprintf("int%d", bits)
+	default:
+		f.kind, f.goType = "Int", "int64"
+	}
+	if unsigned {
+		f.kind, f.goType = "U"+strings.ToLower(f.kind[:1])+f.kind[1:], "u"+f.goType
+	}
+}
+
+// defaults sets the default value of the field from the column default.
+func (f *fieldSpec) defaults(c *schema.Column) {
+	if f.kind == "Time" {
+		if isNow(c.Default) {
+			f.calls = append(f.calls, "Default(time.Now)")
+		}
+		for _, a := range c.Attrs {
+			if u, ok := a.(*mysql.OnUpdate); ok && isNow(&schema.RawExpr{X: u.A}) {
+				f.calls = append(f.calls, "UpdateDefault(time.Now)")
+			}
+		}
+		if c.Default == nil || isNow(c.Default) {
+			return
+		}
+	}
+	var x string
+	switch d := c.Default.(type) {
+	case nil:
+		return
+	case *schema.Literal:
+		x = d.V
+		if v, ok := f.literal(d.V); ok {
+			f.calls = append(f.calls, fmt.Sprintf("Default(%s)", v))
+			return
+		}
+	case *schema.RawExpr:
+		x = d.X
+		// Sequences are managed by the database.
+		if strings.HasPrefix(strings.ToLower(x), "nextval(") {
+			return
+		}
+		// PostgreSQL returns string defaults with their type casts.
+		if v, ok := f.literal(d.X); ok && reCast.MatchString(d.X) {
+			f.calls = append(f.calls, fmt.Sprintf("Default(%s)", v))
+			return
+		}
+	default:
+		return
+	}
+	f.calls = append(f.calls, fmt.Sprintf("Annotations(entsql.Annotation{Default: %q})", x))
+}
+
+// reCast matches PostgreSQL string literals with a type cast, e.g. 'a'::text.
+var reCast = regexp.MustCompile(`^('.*')::[\w ]+$`)
+
+// literal returns the Go representation of a literal default value,
+// and reports if the value can be used as the default of the field.
+func (f *fieldSpec) literal(v string) (string, bool) {
+	if m := reCast.FindStringSubmatch(v); m != nil {
+		v = m[1]
+	}
+	s, quoted := unquote(v)
+	switch f.kind {
+	case "String", "Text", "Enum":
+		if quoted {
+			return strconv.Quote(s), true
+		}
+	case "Bool":
+		switch strings.ToLower(s) {
+		case "1", "true":
+			return "true", true
+		case "0", "false":
+			return "false", true
+		}
+	case "Float", "Float32":
+		if _, err := strconv.ParseFloat(s, 64); err == nil {
+			return s, true
+		}
+	case "Int", "Int8", "Int16", "Int32", "Uint", "Uint8", "Uint16", "Uint32":
+		if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// isJoin reports if the node is an M2M join table.
+func (g *generator) isJoin(n *node) bool {
+	if !g.isJoinCandidate(n.t) {
+		return false
+	}
+	for _, fk := range n.t.ForeignKeys {
+		if ref, ok := g.byT[fk.RefTable]; !ok || ref.id == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// isJoinCandidate reports if the table has the shape of an M2M join table: two
+// columns that are covered by the primary key and referencing other tables.
+func (g *generator) isJoinCandidate(t *schema.Table) bool {
+	if len(t.Columns) != 2 || len(t.ForeignKeys) != 2 || t.PrimaryKey == nil || len(t.PrimaryKey.Parts) != 2 {
+		return false
+	}
+	for i, fk := range t.ForeignKeys {
+		if len(fk.Columns) != 1 || len(fk.RefColumns) != 1 || fk.Columns[0] != t.Columns[i] && fk.Columns[0] != t.Columns[1-i] {
+			return false
+		}
+	}
+	return t.ForeignKeys[0].Columns[0] != t.ForeignKeys[1].Columns[0]
+}
+
+// edges creates the edges of the foreign keys of the node. The edge that
+// holds the foreign key is added to the node, and its inverse edge is added
+// to the referenced node.
+func (g *generator) edges(n *node) {
+	for _, fk := range n.t.ForeignKeys {
+		ref, ok := g.byT[fk.RefTable]
+		switch {
+		case !ok:
+			n.notes = append(n.notes, fmt.Sprintf("Foreign key %q references a table that was not inspected.", fk.Symbol))
+			continue
+		case len(fk.Columns) != 1 || ref.id == nil || ref.id.c != fk.RefColumns[0]:
+			n.notes = append(n.notes, fmt.Sprintf("Foreign key %q does not reference a single-column primary key, and is not supported by ent edges.", fk.Symbol))
+			continue
+		}
+		f := n.fieldOf(fk.Columns[0])
+		if f == nil {
+			n.notes = append(n.notes, fmt.Sprintf("Foreign key %q is defined on the primary key, and is not supported by ent edges.", fk.Symbol))
+			continue
+		}
+		unique := isUnique(n.t, fk.Columns[0])
+		from := n.name2(strings.TrimSuffix(f.name, "_id"), snake(ref.name))
+		n.names[from] = true
+		if unique {
+			n.o2o[fk.Columns[0]] = true
+		}
+		if ref == n {
+			// Self-reference edges are defined on the same node,
+			// using the edge.To(...).From(...) idiom.
+			to := "children"
+			if from != "parent" || n.names[to] {
+				to = n.name2(inflect.Pluralize(snake(n.name)), "inverse_"+from)
+			}
+			if unique {
+				to = n.name2("child", "inverse_"+from)
+			}
+			n.names[to] = true
+			e := fmt.Sprintf("edge.To(%q, %s.Type)", to, n.name)
+			if unique {
+				e += ".Unique()"
+			}
+			e += fmt.Sprintf(".From(%q).Field(%q).Unique()", from, f.name)
+			n.edges = append(n.edges, e)
+			continue
+		}
+		to := inflect.Pluralize(snake(n.name))
+		if unique {
+			to = snake(n.name)
+		}
+		to = ref.name2(to, to+"_"+from)
+		ref.names[to] = true
+		e := fmt.Sprintf("edge.To(%q, %s.Type)", to, n.name)
+		if unique {
+			e += ".Unique()"
+		}
+		ref.edges = append(ref.edges, e+symbol(fk))
+		e = fmt.Sprintf("edge.From(%q, %s.Type).Ref(%q).Field(%q).Unique()", from, ref.name, to, f.name)
+		if !fk.Columns[0].Type.Null {
+			e += ".Required()"
+		}
+		n.edges = append(n.edges, e)
+	}
+}
+
+// m2m creates the M2M edges of a join table.
+func (g *generator) m2m(n *node) {
+	fk1, fk2 := n.t.ForeignKeys[0], n.t.ForeignKeys[1]
+	// Follow the order of the primary key, as the
+	// first column is commonly the owner of the edge.
+	if n.t.PrimaryKey.Parts[0].C != fk1.Columns[0] {
+		fk1, fk2 = fk2, fk1
+	}
+	owner, ref := g.byT[fk1.RefTable], g.byT[fk2.RefTable]
+	key := fmt.Sprintf("StorageKey(edge.Table(%q), edge.Columns(%q, %q)", n.t.Name, fk1.Columns[0].Name, fk2.Columns[0].Name)
+	if fk1.Symbol != "" && fk2.Symbol != "" {
+		key += fmt.Sprintf(", edge.Symbols(%q, %q)", fk1.Symbol, fk2.Symbol)
+	}
+	key += ")"
+	if owner == ref {
+		to := owner.name2(snake(n.name), snake(n.name)+"_edges")
+		owner.names[to] = true
+		owner.edges = append(owner.edges, fmt.Sprintf("edge.To(%q, %s.Type).%s", to, owner.name, key))
+		return
+	}
+	to := ref.name2(inflect.Pluralize(snake(ref.name)), snake(n.name))
+	owner.names[to] = true
+	owner.edges = append(owner.edges, fmt.Sprintf("edge.To(%q, %s.Type).%s", to, ref.name, key))
+	from := ref.name2(inflect.Pluralize(snake(owner.name)), snake(n.name))
+	ref.names[from] = true
+	ref.edges = append(ref.edges, fmt.Sprintf("edge.From(%q, %s.Type).Ref(%q)", from, owner.name, to))
+}
+
+// symbol returns the storage-key option that preserves the foreign-key name.
+func symbol(fk *schema.ForeignKey) string {
+	if fk.Symbol == "" {
+		return ""
+	}
+	return fmt.Sprintf(".StorageKey(edge.Symbol(%q))", fk.Symbol)
+}
+
+// fieldOf returns the field of the given column.
+func (n *node) fieldOf(c *schema.Column) *fieldSpec {
+	for _, f := range n.fields {
+		if f.c == c {
+			return f
+		}
+	}
+	return nil
+}
+
+// name2 returns the given name if it is not used by the node, or
+// the alternative name (with a numeric suffix, if it is used as well).
+func (n *node) name2(name, alt string) string {
+	if name != "" && !n.names[name] {
+		return name
+	}
+	if !n.names[alt] {
+		return alt
+	}
+	for i := 2; ; i++ {
+		if s := fmt.Sprintf("%s%d", alt, i); !n.names[s] {
+			return s
+		}
+	}
+}
+
+// isUnique reports if the column is unique in the table.
+func isUnique(t *schema.Table, c *schema.Column) bool {
+	if pk := t.PrimaryKey; pk != nil && len(pk.Parts) == 1 && pk.Parts[0].C == c {
+		return true
+	}
+	for _, idx := range t.Indexes {
+		if idx.Unique && len(idx.Parts) == 1 && idx.Parts[0].C == c {
+			return true
+		}
+	}
+	return false
+}
+
+// isNow reports if the default value is the current timestamp.
+func isNow(x schema.Expr) bool {
+	var s string
+	switch x := x.(type) {
+	case *schema.RawExpr:
+		s = x.X
+	case *schema.Literal:
+		s = x.V
+	default:
+		return false
+	}
+	s = strings.ToLower(s)
+	return s == "now()" || s == "current_timestamp" || strings.HasPrefix(s, "current_timestamp(")
+}
+
+// unquote removes the single quotes of an SQL string literal.
+func unquote(s string) (string, bool) {
+	if len(s) < 2 || s[0] != '\'' || s[len(s)-1] != '\'' {
+		return s, false
+	}
+	return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), true
+}
+
+// comment returns the comment of the given attributes.
+func comment(attrs []schema.Attr) (string, bool) {
+	for _, a := range attrs {
+		if c, ok := a.(*schema.Comment); ok && c.Text != "" {
+			return c.Text, true
+		}
+	}
+	return "", false
+}
+
+// removeCalls removes the builder calls that start with one of the given prefixes.
+func removeCalls(calls []string, prefixes ...string) []string {
+	kept := make([]string, 0, len(calls))
+	for _, c := range calls {
+		var found bool
+		for _, p := range prefixes {
+			if strings.HasPrefix(c, p) {
+				found = true
+			}
+		}
+		if !found {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// columnNames returns the comma-separated names of the given columns.
+func columnNames(columns []*schema.Column) string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// qualified returns the qualified name of the table.
+func qualified(t *schema.Table) string {
+	if t.Schema == nil || t.Schema.Name == "" {
+		return t.Name
+	}
+	return t.Schema.Name + "." + t.Name
+}
+
+// header writes the package clause and the imports of a file to the buffer.
+func (g *generator) header(b *bytes.Buffer, imports map[string]bool) {
+	fmt.Fprintf(b, "package %s\n\n", g.Package)
+	if len(imports) == 0 {
+		return
+	}
+	var std, other []string
+	for p := range imports {
+		if strings.Contains(strings.SplitN(p, "/", 2)[0], ".") {
+			other = append(other, p)
+		} else {
+			std = append(std, p)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	b.WriteString("import (\n")
+	for _, p := range std {
+		fmt.Fprintf(b, "%q\n", p)
+	}
+	if len(std) > 0 && len(other) > 0 {
+		b.WriteString("\n")
+	}
+	for _, p := range other {
+		fmt.Fprintf(b, "%q\n", p)
+	}
+	b.WriteString(")\n\n")
+}
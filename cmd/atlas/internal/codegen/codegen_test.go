@@ -0,0 +1,273 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package codegen_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/cmd/atlas/internal/codegen"
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlconvert"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate_Ent(t *testing.T) {
+	files, err := codegen.Generate(newRealm(), codegen.FormatEnt, &codegen.Options{Dialect: sqlconvert.MySQL})
+	require.NoError(t, err)
+	// Join tables are generated as edges.
+	require.Equal(t, []string{"group.go", "post.go", "profile.go", "user.go"}, names(files))
+	require.Equal(t, `package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/entsql"
+	"entgo.io/ent/schema"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// Post holds the schema definition for the Post entity.
+//
+// Index "lower_title" is not defined on fields of the schema, and is not supported by ent.
+type Post struct {
+	ent.Schema
+}
+
+// Fields of the Post.
+func (Post) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int32("id"),
+		field.Uint("author_id"),
+		field.Int32("parent_id").
+			Optional(),
+		field.String("title").
+			MaxLen(255).
+			Default("it's"),
+		field.Text("body").
+			SchemaType(map[string]string{dialect.MySQL: "text"}),
+	}
+}
+
+// Edges of the Post.
+func (Post) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("author", User.Type).Ref("posts").Field("author_id").Unique().Required(),
+		edge.To("children", Post.Type).From("parent").Field("parent_id").Unique(),
+	}
+}
+
+// Indexes of the Post.
+func (Post) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("author_id", "title").
+			StorageKey("author_title").
+			Annotations(entsql.DescColumns("title")),
+		index.Fields("body").
+			StorageKey("body_prefix").
+			Annotations(entsql.PrefixColumn("body", 100)),
+	}
+}
+
+// Annotations of the Post.
+func (Post) Annotations() []schema.Annotation {
+	return []schema.Annotation{
+		entsql.Annotation{Table: "posts"},
+	}
+}
+`, string(files[1].Content))
+
+	user := string(files[3].Content)
+	require.Contains(t, user, "// User holds the schema definition for the User entity.\n//\n// Registered users.\n")
+	require.Contains(t, user, `field.Uint("id"),`)
+	require.Contains(t, user, `field.String("name").
+			MaxLen(64).
+			Optional().
+			Comment("Display name."),`)
+	require.Contains(t, user, `field.Enum("status").
+			Values("active", "blocked").
+			Default("active"),`)
+	require.Contains(t, user, `field.Uint8("age").
+			Default(0),`)
+	require.Contains(t, user, `field.Float("score").
+			SchemaType(map[string]string{dialect.MySQL: "decimal(10,2)"}),`)
+	require.Contains(t, user, `field.JSON("meta", json.RawMessage{}).
+			Optional(),`)
+	require.Contains(t, user, `field.Bool("is_active").
+			Default(true),`)
+	require.Contains(t, user, `field.Time("created_at").
+			Default(time.Now),`)
+	require.Contains(t, user, `field.Time("updated_at").
+			SchemaType(map[string]string{dialect.MySQL: "datetime"}).
+			Default(time.Now).
+			UpdateDefault(time.Now),`)
+	require.Contains(t, user, `
+		edge.To("posts", Post.Type).StorageKey(edge.Symbol("posts_author")),
+		edge.To("profile", Profile.Type).Unique().StorageKey(edge.Symbol("profiles_user")),
+		edge.To("groups", Group.Type).StorageKey(edge.Table("user_groups"), edge.Columns("user_id", "group_id"), edge.Symbols("ug_user", "ug_group")),
+`)
+	require.Contains(t, user, `index.Fields("email").
+			Unique().
+			StorageKey("users_email"),`)
+	require.Contains(t, string(files[0].Content), `edge.From("users", User.Type).Ref("groups"),`)
+
+	// The unique index of an O2O edge is created by ent.
+	profile := string(files[2].Content)
+	require.Contains(t, profile, `field.Int32("id").
+			StorageKey("profile_id"),`)
+	require.Contains(t, profile, `edge.From("user", User.Type).Ref("profile").Field("user_id").Unique().Required(),`)
+	require.NotContains(t, profile, "Indexes()")
+}
+
+func TestGenerate_Go(t *testing.T) {
+	files, err := codegen.Generate(newRealm(), codegen.FormatGo, &codegen.Options{Package: "db"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"group.go", "post.go", "profile.go", "user.go", "usergroup.go"}, names(files))
+	require.Equal(t, `package db
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// User represents a row in the "users" table.
+//
+// Registered users.
+type User struct {
+	ID        uint64          `+"`"+`db:"id"`+"`"+`
+	Email     string          `+"`"+`db:"email"`+"`"+`
+	Name      *string         `+"`"+`db:"name"`+"`"+` // Display name.
+	Status    string          `+"`"+`db:"status"`+"`"+`
+	Age       uint8           `+"`"+`db:"age"`+"`"+`
+	Score     float64         `+"`"+`db:"score"`+"`"+`
+	Meta      json.RawMessage `+"`"+`db:"meta"`+"`"+`
+	IsActive  bool            `+"`"+`db:"is_active"`+"`"+`
+	CreatedAt time.Time       `+"`"+`db:"created_at"`+"`"+`
+	UpdatedAt time.Time       `+"`"+`db:"updated_at"`+"`"+`
+}
+`, string(files[3].Content))
+}
+
+func TestGenerate_Postgres(t *testing.T) {
+	var (
+		id = &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &postgres.SerialType{T: "bigserial"}}, Default: &schema.RawExpr{X: "nextval('accounts_id_seq'::regclass)"}}
+		tb = schema.NewTable("accounts").
+			AddColumns(
+				id,
+				&schema.Column{Name: "uid", Type: &schema.ColumnType{Type: &postgres.UUIDType{T: "uuid"}}},
+				schema.NewStringColumn("name", "character varying", schema.StringSize(64)).SetDefault(&schema.RawExpr{X: "'a8m'::character varying"}),
+				schema.NewNullStringColumn("bio", "text"),
+				schema.NewEnumColumn("state", schema.EnumName("state"), schema.EnumValues("on", "off")),
+				schema.NewTimeColumn("created_at", "timestamp with time zone").SetDefault(&schema.RawExpr{X: "now()"}),
+				schema.NewJSONColumn("data", "jsonb"),
+				schema.NewFloatColumn("ratio", "real"),
+				&schema.Column{Name: "ip", Type: &schema.ColumnType{Type: &postgres.NetworkType{T: "inet"}}},
+			)
+	)
+	tb.SetPrimaryKey(schema.NewPrimaryKey(id))
+	files, err := codegen.Generate(schema.NewRealm(schema.New("public").AddTables(tb)), codegen.FormatEnt, &codegen.Options{Dialect: sqlconvert.Postgres})
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, "account.go", files[0].Name)
+	s := string(files[0].Content)
+	require.Contains(t, s, "\t\"github.com/google/uuid\"\n")
+	require.Contains(t, s, `
+		field.Int("id"),
+		field.UUID("uid", uuid.UUID{}),
+		field.String("name").
+			MaxLen(64).
+			SchemaType(map[string]string{dialect.Postgres: "character varying(64)"}).
+			Default("a8m"),
+		field.Text("bio").
+			Optional(),
+		field.Enum("state").
+			Values("on", "off").
+			SchemaType(map[string]string{dialect.Postgres: "state"}),
+		field.Time("created_at").
+			Default(time.Now),
+		field.JSON("data", json.RawMessage{}),
+		field.Float32("ratio"),
+		field.String("ip").
+			SchemaType(map[string]string{dialect.Postgres: "inet"}),
+`)
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	_, err := codegen.Generate(newRealm(), "proto", nil)
+	require.EqualError(t, err, `codegen: unknown format "proto"`)
+	_, err = codegen.Generate(newRealm(), codegen.FormatEnt, nil)
+	require.EqualError(t, err, `codegen: unknown dialect "", expect one of: mysql, postgres, sqlite`)
+
+	r := newRealm()
+	other := schema.New("other").AddTables(schema.NewTable("user"))
+	other.SetRealm(r)
+	r.Schemas = append(r.Schemas, other)
+	_, err = codegen.Generate(r, codegen.FormatGo, nil)
+	require.EqualError(t, err, `codegen: tables "main.users" and "other.user" are both mapped to type "User"`)
+}
+
+func newRealm() *schema.Realm {
+	var (
+		userID  = &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint", Unsigned: true}}, Attrs: []schema.Attr{&mysql.AutoIncrement{}}}
+		email   = schema.NewStringColumn("email", "varchar", schema.StringSize(255))
+		name    = schema.NewNullStringColumn("name", "varchar", schema.StringSize(64)).SetComment("Display name.")
+		status  = schema.NewEnumColumn("status", schema.EnumValues("active", "blocked")).SetDefault(&schema.Literal{V: "'active'"})
+		age     = &schema.Column{Name: "age", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "tinyint", Unsigned: true}}, Default: &schema.Literal{V: "0"}}
+		score   = schema.NewDecimalColumn("score", "decimal", schema.DecimalPrecision(10), schema.DecimalScale(2))
+		meta    = schema.NewNullJSONColumn("meta", "json")
+		active  = schema.NewBoolColumn("is_active", "bool").SetDefault(&schema.Literal{V: "1"})
+		created = schema.NewTimeColumn("created_at", "timestamp").SetDefault(&schema.RawExpr{X: "CURRENT_TIMESTAMP"})
+		updated = schema.NewTimeColumn("updated_at", "datetime").SetDefault(&schema.RawExpr{X: "CURRENT_TIMESTAMP"}).AddAttrs(&mysql.OnUpdate{A: "CURRENT_TIMESTAMP"})
+		users   = schema.NewTable("users").
+			SetComment("Registered users.").
+			AddColumns(userID, email, name, status, age, score, meta, active, created, updated).
+			SetPrimaryKey(schema.NewPrimaryKey(userID))
+		postID   = schema.NewIntColumn("id", "int")
+		authorID = &schema.Column{Name: "author_id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint", Unsigned: true}}}
+		parentID = schema.NewNullIntColumn("parent_id", "int")
+		title    = schema.NewStringColumn("title", "varchar", schema.StringSize(255)).SetDefault(&schema.Literal{V: "'it''s'"})
+		body     = schema.NewStringColumn("body", "text")
+		posts    = schema.NewTable("posts").
+				AddColumns(postID, authorID, parentID, title, body).
+				SetPrimaryKey(schema.NewPrimaryKey(postID))
+		profileID = schema.NewIntColumn("profile_id", "int")
+		profileU  = &schema.Column{Name: "user_id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint", Unsigned: true}}}
+		profiles  = schema.NewTable("profiles").
+				AddColumns(profileID, profileU).
+				SetPrimaryKey(schema.NewPrimaryKey(profileID))
+		groupID = schema.NewIntColumn("id", "int")
+		groups  = schema.NewTable("groups").AddColumns(groupID).SetPrimaryKey(schema.NewPrimaryKey(groupID))
+		ugUser  = &schema.Column{Name: "user_id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint", Unsigned: true}}}
+		ugGroup = schema.NewIntColumn("group_id", "int")
+		ug      = schema.NewTable("user_groups").AddColumns(ugUser, ugGroup).SetPrimaryKey(schema.NewPrimaryKey(ugUser, ugGroup))
+	)
+	users.AddIndexes(schema.NewUniqueIndex("users_email").AddColumns(email))
+	posts.AddIndexes(schema.NewIndex("author_title").AddParts(schema.NewColumnPart(authorID), schema.NewColumnPart(title).SetDesc(true)))
+	posts.AddIndexes(schema.NewIndex("body_prefix").AddParts(schema.NewColumnPart(body).AddAttrs(&mysql.SubPart{Len: 100})))
+	posts.AddIndexes(schema.NewIndex("lower_title").AddParts(schema.NewExprPart(&schema.RawExpr{X: "lower(title)"})))
+	posts.AddForeignKeys(
+		schema.NewForeignKey("posts_author").AddColumns(authorID).SetRefTable(users).AddRefColumns(userID),
+		schema.NewForeignKey("posts_parent").AddColumns(parentID).SetRefTable(posts).AddRefColumns(postID),
+	)
+	profiles.AddIndexes(schema.NewUniqueIndex("profiles_user").AddColumns(profileU))
+	profiles.AddForeignKeys(schema.NewForeignKey("profiles_user").AddColumns(profileU).SetRefTable(users).AddRefColumns(userID))
+	ug.AddForeignKeys(
+		schema.NewForeignKey("ug_user").AddColumns(ugUser).SetRefTable(users).AddRefColumns(userID),
+		schema.NewForeignKey("ug_group").AddColumns(ugGroup).SetRefTable(groups).AddRefColumns(groupID),
+	)
+	return schema.NewRealm(schema.New("main").AddTables(users, posts, profiles, groups, ug))
+}
+
+func names(files []*codegen.File) []string {
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = f.Name
+	}
+	return names
+}
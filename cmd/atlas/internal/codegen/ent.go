@@ -0,0 +1,155 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/schema"
+)
+
+// ent renders the ent schema of the node.
+func (g *generator) ent(n *node) ([]byte, error) {
+	var (
+		b       bytes.Buffer
+		indexes []string
+		notes   = append([]string(nil), n.notes...)
+		imports = map[string]bool{
+			"entgo.io/ent":                true,
+			"entgo.io/ent/dialect/entsql": true,
+			"entgo.io/ent/schema":         true,
+		}
+	)
+	for _, idx := range n.indexes {
+		// Columns of O2O edges are made unique by ent.
+		if idx.Unique && len(idx.Parts) == 1 && n.o2o[idx.Parts[0].C] {
+			continue
+		}
+		s, ok := n.index(idx)
+		if !ok {
+			notes = append(notes, fmt.Sprintf("Index %q is not defined on fields of the schema, and is not supported by ent.", idx.Name))
+			continue
+		}
+		indexes = append(indexes, s)
+	}
+	fmt.Fprintf(&b, "// %s holds the schema definition for the %s entity.\n", n.name, n.name)
+	if c, ok := comment(n.t.Attrs); ok {
+		writeComment(&b, c)
+	}
+	for _, note := range notes {
+		writeComment(&b, note)
+	}
+	fmt.Fprintf(&b, "type %s struct {\nent.Schema\n}\n\n", n.name)
+
+	fmt.Fprintf(&b, "// Fields of the %s.\nfunc (%s) Fields() []ent.Field {\nreturn []ent.Field{\n", n.name, n.name)
+	fields := n.fields
+	if n.id != nil {
+		fields = append([]*fieldSpec{n.id}, fields...)
+	}
+	if len(fields) > 0 {
+		imports["entgo.io/ent/schema/field"] = true
+	}
+	for _, f := range fields {
+		args := append([]string{strconv.Quote(f.name)}, f.args...)
+		fmt.Fprintf(&b, "field.%s(%s)", f.kind, strings.Join(args, ", "))
+		for _, c := range f.calls {
+			fmt.Fprintf(&b, ".\n%s", c)
+		}
+		b.WriteString(",\n")
+		for _, a := range f.args {
+			switch a {
+			case "json.RawMessage{}":
+				imports["encoding/json"] = true
+			case "uuid.UUID{}":
+				imports["github.com/google/uuid"] = true
+			}
+		}
+		for _, c := range f.calls {
+			switch {
+			case strings.Contains(c, "dialect."):
+				imports["entgo.io/ent/dialect"] = true
+			case strings.Contains(c, "time.Now"):
+				imports["time"] = true
+			}
+		}
+	}
+	b.WriteString("}\n}\n\n")
+
+	if len(n.edges) > 0 {
+		imports["entgo.io/ent/schema/edge"] = true
+		fmt.Fprintf(&b, "// Edges of the %s.\nfunc (%s) Edges() []ent.Edge {\nreturn []ent.Edge{\n", n.name, n.name)
+		for _, e := range n.edges {
+			b.WriteString(e + ",\n")
+		}
+		b.WriteString("}\n}\n\n")
+	}
+
+	if len(indexes) > 0 {
+		imports["entgo.io/ent/schema/index"] = true
+		fmt.Fprintf(&b, "// Indexes of the %s.\nfunc (%s) Indexes() []ent.Index {\nreturn []ent.Index{\n", n.name, n.name)
+		for _, s := range indexes {
+			b.WriteString(s + ",\n")
+		}
+		b.WriteString("}\n}\n\n")
+	}
+
+	fmt.Fprintf(&b, "// Annotations of the %s.\nfunc (%s) Annotations() []schema.Annotation {\n", n.name, n.name)
+	fmt.Fprintf(&b, "return []schema.Annotation{\nentsql.Annotation{Table: %q},\n}\n}\n", n.t.Name)
+
+	var file bytes.Buffer
+	g.header(&file, imports)
+	file.Write(b.Bytes())
+	return file.Bytes(), nil
+}
+
+// index renders the ent index of a table index, and reports if the index
+// can be defined on the fields of the node. e.g. expression indexes cannot.
+func (n *node) index(idx *schema.Index) (string, bool) {
+	var (
+		names []string
+		annot []string
+	)
+	for _, p := range idx.Parts {
+		var f *fieldSpec
+		if p.C != nil {
+			f = n.fieldOf(p.C)
+		}
+		if f == nil {
+			return "", false
+		}
+		names = append(names, strconv.Quote(f.name))
+		if p.Desc {
+			annot = append(annot, fmt.Sprintf("entsql.DescColumns(%q)", f.c.Name))
+		}
+		for _, a := range p.Attrs {
+			if s, ok := a.(*mysql.SubPart); ok {
+				annot = append(annot, fmt.Sprintf("entsql.PrefixColumn(%q, %d)", f.c.Name, s.Len))
+			}
+		}
+	}
+	s := fmt.Sprintf("index.Fields(%s)", strings.Join(names, ", "))
+	if idx.Unique {
+		s += ".\nUnique()"
+	}
+	if idx.Name != "" {
+		s += fmt.Sprintf(".\nStorageKey(%q)", idx.Name)
+	}
+	if len(annot) > 0 {
+		s += fmt.Sprintf(".\nAnnotations(%s)", strings.Join(annot, ", "))
+	}
+	return s, true
+}
+
+// writeComment writes a text to the buffer as a paragraph of a Go comment.
+func writeComment(b *bytes.Buffer, text string) {
+	b.WriteString("//\n")
+	for _, l := range strings.Split(strings.TrimSpace(text), "\n") {
+		b.WriteString(strings.TrimSpace("// "+l) + "\n")
+	}
+}
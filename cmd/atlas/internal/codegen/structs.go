@@ -0,0 +1,53 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// structs renders the Go struct of the node.
+func (g *generator) structs(n *node) ([]byte, error) {
+	var (
+		b       bytes.Buffer
+		imports = make(map[string]bool)
+		names   = make(map[string]bool)
+	)
+	fmt.Fprintf(&b, "// %s represents a row in the %q table.\n", n.name, n.t.Name)
+	if c, ok := comment(n.t.Attrs); ok {
+		writeComment(&b, c)
+	}
+	fmt.Fprintf(&b, "type %s struct {\n", n.name)
+	for _, c := range n.t.Columns {
+		f := n.fieldOf(c)
+		if f == nil {
+			f = n.id
+		}
+		name := pascal(c.Name)
+		for i := 2; names[name]; i++ {
+			name = fmt.Sprintf("%s%d", pascal(c.Name), i)
+		}
+		names[name] = true
+		fmt.Fprintf(&b, "%s %s `db:%q`", name, f.goType, c.Name)
+		if c, ok := comment(c.Attrs); ok {
+			b.WriteString(" // " + strings.Join(strings.Fields(c), " "))
+		}
+		b.WriteString("\n")
+		switch strings.TrimPrefix(f.goType, "*") {
+		case "time.Time":
+			imports["time"] = true
+		case "json.RawMessage":
+			imports["encoding/json"] = true
+		}
+	}
+	b.WriteString("}\n")
+
+	var file bytes.Buffer
+	g.header(&file, imports)
+	file.Write(b.Bytes())
+	return file.Bytes(), nil
+}
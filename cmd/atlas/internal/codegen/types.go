@@ -0,0 +1,189 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package codegen
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlconvert"
+	"ariga.io/atlas/sql/sqlite"
+
+	"github.com/go-openapi/inflect"
+)
+
+var (
+	// formatters holds the type formatters of each dialect.
+	formatters = map[string]func(schema.Type) (string, error){
+		sqlconvert.MySQL:    mysql.FormatType,
+		sqlconvert.Postgres: postgres.FormatType,
+		sqlconvert.SQLite:   sqlite.FormatType,
+	}
+
+	// dialectConst holds the ent constants of each dialect.
+	dialectConst = map[string]string{
+		sqlconvert.MySQL:    "dialect.MySQL",
+		sqlconvert.Postgres: "dialect.Postgres",
+		sqlconvert.SQLite:   "dialect.SQLite",
+	}
+
+	// entTypes holds the column types ent creates for each field builder,
+	// when no schema type is set. String fields are handled separately,
+	// as their types depend on their size.
+	entTypes = map[string]map[string]string{
+		sqlconvert.MySQL: {
+			"Int8": "tinyint", "Int16": "smallint", "Int32": "int", "Int": "bigint",
+			"Uint8": "tinyint unsigned", "Uint16": "smallint unsigned", "Uint32": "int unsigned", "Uint": "bigint unsigned",
+			"Float32": "double", "Float": "double", "Bytes": "blob", "JSON": "json",
+			"Text": "longtext", "Time": "timestamp", "UUID": "char(36)",
+		},
+		sqlconvert.Postgres: {
+			"Int8": "smallint", "Int16": "smallint", "Int32": "integer", "Int": "bigint",
+			"Uint8": "smallint", "Uint16": "smallint", "Uint32": "integer", "Uint": "bigint",
+			"Float32": "real", "Float": "double precision", "Bytes": "bytea", "JSON": "jsonb",
+			"String": "character varying", "Text": "text", "Enum": "character varying",
+			"Time": "timestamptz", "UUID": "uuid",
+		},
+		sqlconvert.SQLite: {
+			"Int8": "integer", "Int16": "integer", "Int32": "integer", "Int": "integer",
+			"Uint8": "integer", "Uint16": "integer", "Uint32": "integer", "Uint": "integer",
+			"Float32": "real", "Float": "real", "Bytes": "blob", "JSON": "json",
+			"String": "varchar(255)", "Text": "varchar(255)", "Enum": "varchar(255)",
+			"Time": "datetime", "UUID": "uuid",
+		},
+	}
+
+	// serialBits maps the PostgreSQL serial types to their size in bits.
+	serialBits = map[string]int{
+		postgres.TypeSmallSerial: 16, postgres.TypeSerial2: 16,
+		postgres.TypeSerial: 32, postgres.TypeSerial4: 32,
+		postgres.TypeBigSerial: 64, postgres.TypeSerial8: 64,
+	}
+
+	// textTypes holds the string types that are mapped to text fields.
+	textTypes = map[string]bool{
+		mysql.TypeTinyText: true, mysql.TypeText: true, mysql.TypeMediumText: true,
+		mysql.TypeLongText: true, "clob": true,
+	}
+
+	// initialisms holds the words that are written in upper case in Go identifiers.
+	initialisms = map[string]bool{
+		"acl": true, "api": true, "cpu": true, "css": true, "db": true, "dns": true,
+		"html": true, "http": true, "https": true, "id": true, "ip": true, "json": true,
+		"sql": true, "ssh": true, "tls": true, "ttl": true, "ui": true, "uid": true,
+		"uri": true, "url": true, "uuid": true, "xml": true,
+	}
+)
+
+// intBits returns the size in bits of an integer type.
+func intBits(dialect, t string) int {
+	switch strings.ToLower(t) {
+	case "tinyint":
+		return 8
+	case "smallint", "int2":
+		return 16
+	case "mediumint", "int", "int4":
+		return 32
+	case "integer":
+		// The INTEGER type of SQLite is a 64-bit integer.
+		if dialect != sqlconvert.SQLite {
+			return 32
+		}
+	}
+	return 64
+}
+
+// floatBits returns the size in bits of a floating-point type.
+func floatBits(dialect string, t *schema.FloatType) int {
+	switch strings.ToLower(t.T) {
+	case "real", "float4":
+		if dialect == sqlconvert.Postgres {
+			return 32
+		}
+	case "float":
+		if dialect == sqlconvert.MySQL && t.Precision <= 24 {
+			return 32
+		}
+	}
+	return 64
+}
+
+// formatType returns the column type in the dialect format.
+func (g *generator) formatType(c *schema.Column) string {
+	if t, err := formatters[g.Dialect](c.Type.Type); err == nil && t != "" {
+		return t
+	}
+	return strings.ToLower(c.Type.Raw)
+}
+
+// defaultType returns the column type ent creates for the field.
+func (g *generator) defaultType(f *fieldSpec) string {
+	switch {
+	case g.Dialect == sqlconvert.MySQL && f.kind == "String":
+		size := 255
+		if t, ok := f.c.Type.Type.(*schema.StringType); ok && t.Size > 0 {
+			size = t.Size
+		}
+		return fmt.Sprintf("varchar(%d)", size)
+	case g.Dialect == sqlconvert.MySQL && f.kind == "Enum":
+		// Enum values are set explicitly on the field.
+		return g.formatType(f.c)
+	}
+	return entTypes[g.Dialect][f.kind]
+}
+
+// typeName returns the Go type name of a table, e.g. "users" => "User".
+func typeName(table string) string {
+	return pascal(inflect.Singularize(table))
+}
+
+// fieldName returns the ent field name of a column. Field names
+// are lower-cased identifiers, e.g. "UserID" => "userid".
+func fieldName(column string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(column) {
+		switch {
+		case r == '_', r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "f_" + name
+	}
+	return name
+}
+
+// pascal returns the exported Go identifier of a name, e.g. "user_id" => "UserID".
+func pascal(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return r >= unicode.MaxASCII || !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, w := range words {
+		switch lw := strings.ToLower(w); {
+		case initialisms[lw]:
+			b.WriteString(strings.ToUpper(lw))
+		default:
+			b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+		}
+	}
+	s := b.String()
+	if s == "" || unicode.IsDigit(rune(s[0])) {
+		s = "T" + s
+	}
+	return s
+}
+
+// snake returns the snake-case form of a Go type name, e.g. "APIKey" => "api_key".
+func snake(name string) string {
+	return inflect.Underscore(name)
+}
@@ -0,0 +1,268 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package entschema reads the desired state of a database from an ent schema
+// package. The package is loaded into an ent graph, and the realm is built by
+// the migration engine of ent for the dialect of the dev database. Hence, the
+// state is identical to the one ent creates when running its own migration.
+package entschema
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
+	"ariga.io/atlas/sql/sqlite"
+
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
+	entschema "entgo.io/ent/dialect/sql/schema"
+	"entgo.io/ent/entc/gen"
+	"entgo.io/ent/entc/load"
+)
+
+// StateReader returns a migrate.StateReader that loads the ent schema package
+// in the given directory, and reads the state ent creates for it on the dev database.
+func StateReader(dev *sqlclient.Client, dir string) migrate.StateReader {
+	return migrate.StateReaderFunc(func(ctx context.Context) (*schema.Realm, error) {
+		g, err := Load(ctx, dir)
+		if err != nil {
+			return nil, err
+		}
+		return Realm(ctx, dev, g)
+	})
+}
+
+// Load loads the graph of the ent schema package in the given directory. Schemas
+// are the exported struct types of the package that embed the ent.Schema type.
+// The schema descriptors are loaded by running a Go program that imports the
+// package, and therefore, the package must be part of a Go module.
+func Load(ctx context.Context, dir string) (*gen.Graph, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	names, err := schemaNames(dir)
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := goCmd(ctx, dir, "list", "-f", "{{ .ImportPath }}", ".")
+	if err != nil {
+		return nil, err
+	}
+	pkg = strings.TrimSpace(pkg)
+	var b bytes.Buffer
+	if err := mainTmpl.Execute(&b, struct {
+		Package string
+		Names   []string
+	}{pkg, names}); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	// The program is written to a temporary directory outside the source tree, and
+	// is overlaid (see "go help build") on a path in the package directory, to be
+	// built using the module of the package without modifying the source tree.
+	tmp, err := os.MkdirTemp("", "atlas-ent")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	prog := filepath.Join(dir, ".atlas", "main.go")
+	overlay, err := json.Marshal(map[string]interface{}{
+		"Replace": map[string]string{prog: filepath.Join(tmp, "main.go")},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(tmp, "main.go"), src, 0600); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(tmp, "overlay.json"), overlay, 0600); err != nil {
+		return nil, err
+	}
+	out, err := goCmd(ctx, dir, "run", "-overlay", filepath.Join(tmp, "overlay.json"), prog)
+	if err != nil {
+		return nil, err
+	}
+	var schemas []*load.Schema
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		s, err := load.UnmarshalSchema([]byte(line))
+		if err != nil {
+			return nil, fmt.Errorf("entschema: unmarshal schema: %w", err)
+		}
+		schemas = append(schemas, s)
+	}
+	storage, err := gen.NewStorage("sql")
+	if err != nil {
+		return nil, err
+	}
+	g, err := gen.NewGraph(&gen.Config{Package: path.Dir(pkg), Storage: storage}, schemas...)
+	if err != nil {
+		return nil, fmt.Errorf("entschema: loading graph: %w", err)
+	}
+	return g, nil
+}
+
+// Realm returns the realm ent creates for the graph on the dev database. The
+// dev database is not modified, as the changes computed by ent are skipped.
+func Realm(ctx context.Context, dev *sqlclient.Client, g *gen.Graph) (*schema.Realm, error) {
+	var name string
+	switch dev.Driver.(type) {
+	case *mysql.Driver:
+		name = dialect.MySQL
+	case *postgres.Driver:
+		name = dialect.Postgres
+	case *sqlite.Driver:
+		name = dialect.SQLite
+	default:
+		return nil, fmt.Errorf("entschema: unsupported driver %T", dev.Driver)
+	}
+	tables, err := g.Tables()
+	if err != nil {
+		return nil, err
+	}
+	var desired *schema.Schema
+	m, err := entschema.NewMigrate(
+		entsql.OpenDB(name, dev.DB),
+		entschema.WithAtlas(true),
+		entschema.WithDiffHook(func(entschema.Differ) entschema.Differ {
+			return entschema.DiffFunc(func(_, s *schema.Schema) ([]schema.Change, error) {
+				desired = s
+				return nil, nil
+			})
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.Create(ctx, tables...); err != nil {
+		return nil, fmt.Errorf("entschema: computing state: %w", err)
+	}
+	if desired == nil {
+		return nil, errors.New("entschema: state was not computed by ent")
+	}
+	for _, t := range desired.Tables {
+		t.Schema = desired
+	}
+	return schema.NewRealm(desired), nil
+}
+
+// schemaNames returns the names of the ent schemas defined in the package directory.
+func schemaNames(dir string) ([]string, error) {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("entschema: parsing package: %w", err)
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("entschema: expect one package in %q, got %d", dir, len(pkgs))
+	}
+	var names []string
+	for _, p := range pkgs {
+		for _, f := range p.Files {
+			// Name of the ent package in the file.
+			var ent string
+			for _, imp := range f.Imports {
+				if p, _ := strconv.Unquote(imp.Path.Value); p == "entgo.io/ent" {
+					ent = "ent"
+					if imp.Name != nil {
+						ent = imp.Name.Name
+					}
+				}
+			}
+			if ent == "" {
+				continue
+			}
+			ast.Inspect(f, func(n ast.Node) bool {
+				spec, ok := n.(*ast.TypeSpec)
+				if !ok || !spec.Name.IsExported() {
+					return true
+				}
+				if st, ok := spec.Type.(*ast.StructType); ok && embeds(st, ent, "Schema") {
+					names = append(names, spec.Name.Name)
+				}
+				return false
+			})
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("entschema: no schemas found in %q", dir)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// embeds reports if the struct embeds the given type.
+func embeds(st *ast.StructType, pkg, name string) bool {
+	for _, f := range st.Fields.List {
+		if s, ok := f.Type.(*ast.SelectorExpr); ok && len(f.Names) == 0 && s.Sel.Name == name {
+			if x, ok := s.X.(*ast.Ident); ok && x.Name == pkg {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// goCmd runs the go command in the given directory, and returns its output.
+func goCmd(ctx context.Context, dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir, cmd.Stdout, cmd.Stderr = dir, &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("entschema: go %s: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// mainTmpl is the program that prints the descriptors of the schemas.
+var mainTmpl = template.Must(template.New("main").Parse(`package main
+
+import (
+	"fmt"
+	"os"
+
+	"entgo.io/ent"
+	"entgo.io/ent/entc/load"
+	target "{{ .Package }}"
+)
+
+func main() {
+	for _, s := range []ent.Interface{
+		{{- range .Names }}
+		target.{{ . }}{},
+		{{- end }}
+	} {
+		b, err := load.MarshalSchema(s)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(string(b))
+	}
+}
+`))
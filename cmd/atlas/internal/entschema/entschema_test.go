@@ -0,0 +1,91 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package entschema_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ariga.io/atlas/cmd/atlas/internal/entschema"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlclient"
+	_ "ariga.io/atlas/sql/sqlite"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateReader(t *testing.T) {
+	ctx := context.Background()
+	dev, err := sqlclient.Open(ctx, "sqlite://entschema?mode=memory&_fk=1")
+	require.NoError(t, err)
+	defer dev.Close()
+
+	before, err := filepath.Glob("testdata/schema/*")
+	require.NoError(t, err)
+	g, err := entschema.Load(ctx, "testdata/schema")
+	require.NoError(t, err)
+	require.Len(t, g.Nodes, 2)
+	// The source tree is not modified.
+	after, err := filepath.Glob("testdata/schema/*")
+	require.NoError(t, err)
+	require.Equal(t, before, after)
+	require.Equal(t, "Pet", g.Nodes[0].Name)
+	require.Equal(t, "User", g.Nodes[1].Name)
+
+	r, err := entschema.StateReader(dev, "testdata/schema").ReadState(ctx)
+	require.NoError(t, err)
+	require.Len(t, r.Schemas, 1)
+	require.Equal(t, "main", r.Schemas[0].Name)
+
+	users, ok := r.Schemas[0].Table("users")
+	require.True(t, ok)
+	require.Equal(t, users.Schema, r.Schemas[0])
+	require.Equal(t, []string{"id", "name", "age"}, columns(users))
+	name, ok := users.Column("name")
+	require.True(t, ok)
+	require.Equal(t, &schema.StringType{T: "text"}, name.Type.Type)
+	require.False(t, name.Type.Null)
+	age, ok := users.Column("age")
+	require.True(t, ok)
+	require.True(t, age.Type.Null)
+	require.Len(t, users.Indexes, 1)
+	require.True(t, users.Indexes[0].Unique)
+
+	pets, ok := r.Schemas[0].Table("pets")
+	require.True(t, ok)
+	require.Equal(t, []string{"id", "name", "user_pets"}, columns(pets))
+	require.Len(t, pets.ForeignKeys, 1)
+	require.Equal(t, users, pets.ForeignKeys[0].RefTable)
+
+	// The dev database is not modified.
+	r, err = dev.InspectRealm(ctx, nil)
+	require.NoError(t, err)
+	require.Empty(t, r.Schemas[0].Tables)
+	entries, err := os.ReadDir("testdata/schema")
+	require.NoError(t, err)
+	require.Len(t, entries, 2, "temporary program should be removed")
+}
+
+func TestLoad_Errors(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "doc.go"), []byte("package schema\n\ntype T struct{}\n"), 0600))
+	_, err := entschema.Load(ctx, dir)
+	require.EqualError(t, err, `entschema: no schemas found in "`+dir+`"`)
+
+	_, err = entschema.Load(ctx, filepath.Join(dir, "unknown"))
+	require.Error(t, err)
+}
+
+func columns(t *schema.Table) []string {
+	names := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		names[i] = c.Name
+	}
+	return names
+}
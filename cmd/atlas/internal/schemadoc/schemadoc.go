@@ -0,0 +1,408 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package schemadoc renders data-dictionary documentation of schemas using Go
+// templates. A page is rendered for each schema in the realm, and the pages are
+// linked to each other by the foreign keys of their tables.
+package schemadoc
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// List of supported documentation formats.
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+type (
+	// A Template renders the documentation page of a schema. The data passed
+	// to the template is a *Page. It is implemented by both text/template and
+	// html/template.
+	Template interface {
+		Execute(io.Writer, interface{}) error
+	}
+
+	// A Page contains the data used to render the documentation of a schema.
+	Page struct {
+		Schema  *schema.Schema   // The documented schema.
+		Schemas []*schema.Schema // All schemas in the realm, used for navigation.
+		Ext     string           // Extension of the page files, used for linking pages.
+	}
+
+	// Attr describes a dialect-specific attribute (e.g. AUTO_INCREMENT).
+	Attr struct {
+		Name  string // Name of the attribute type, e.g. "AutoIncrement".
+		Value string // Non-zero fields of the attribute, e.g. "V=100".
+	}
+)
+
+// Pages returns the pages of the realm, sorted by their schema names.
+func Pages(r *schema.Realm, format string) ([]*Page, error) {
+	ext, err := Ext(format)
+	if err != nil {
+		return nil, err
+	}
+	schemas := make([]*schema.Schema, len(r.Schemas))
+	copy(schemas, r.Schemas)
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].Name < schemas[j].Name })
+	pages := make([]*Page, len(schemas))
+	for i, s := range schemas {
+		pages[i] = &Page{Schema: s, Schemas: schemas, Ext: ext}
+	}
+	return pages, nil
+}
+
+// Name returns the file name of the page.
+func (p *Page) Name() string {
+	return p.Schema.Name + p.Ext
+}
+
+// Link returns the link to the given table. Tables in other schemas are
+// linked to the pages of their schemas.
+func (p *Page) Link(t *schema.Table) string {
+	if t.Schema == nil || t.Schema == p.Schema || t.Schema.Name == p.Schema.Name {
+		return "#" + Anchor(t.Name)
+	}
+	return t.Schema.Name + p.Ext + "#" + Anchor(t.Name)
+}
+
+// Ext returns the file extension of the given format.
+func Ext(format string) (string, error) {
+	switch format {
+	case FormatMarkdown:
+		return ".md", nil
+	case FormatHTML:
+		return ".html", nil
+	default:
+		return "", fmt.Errorf("unknown format %q, expect %q or %q", format, FormatMarkdown, FormatHTML)
+	}
+}
+
+// DefaultTemplate returns the default template of the given format.
+func DefaultTemplate(format string) (Template, error) {
+	switch format {
+	case FormatMarkdown:
+		return DefaultMarkdown, nil
+	case FormatHTML:
+		return DefaultHTML, nil
+	default:
+		_, err := Ext(format)
+		return nil, err
+	}
+}
+
+// ParseTemplate parses the template file at the given path. HTML templates are
+// parsed using html/template, in order to escape the schema contents.
+func ParseTemplate(format, path string) (Template, error) {
+	if _, err := Ext(format); err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	name := filepath.Base(path)
+	if format == FormatHTML {
+		return htmltemplate.New(name).Funcs(htmltemplate.FuncMap(TemplateFuncs)).Parse(string(b))
+	}
+	return template.New(name).Funcs(TemplateFuncs).Parse(string(b))
+}
+
+// Anchor returns the anchor of the given name, as generated
+// for Markdown headings (e.g. "User Groups" => "user-groups").
+func Anchor(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r == ' ':
+			return '-'
+		}
+		return -1
+	}, name)
+}
+
+var (
+	// TemplateFuncs are global functions available in templates.
+	TemplateFuncs = template.FuncMap{
+		"anchor":  Anchor,
+		"type":    typeName,
+		"expr":    exprString,
+		"comment": comment,
+		"attrs":   attrs,
+		"checks":  checks,
+		"parts":   parts,
+		"names":   names,
+		"pk":      pk,
+		"cell":    cell,
+	}
+
+	// DefaultMarkdown is the default template used for Markdown pages.
+	DefaultMarkdown = template.Must(template.New("markdown").
+			Funcs(TemplateFuncs).
+			Parse(`
+{{- $p := . -}}
+# {{ $p.Schema.Name }}
+{{- with comment $p.Schema.Attrs }}
+
+{{ . }}
+{{- end }}
+{{- if gt (len $p.Schemas) 1 }}
+
+Schemas:{{ range $s := $p.Schemas }} [{{ $s.Name }}]({{ $s.Name }}{{ $p.Ext }}){{ end }}
+{{- end }}
+
+## Tables
+{{ range $t := $p.Schema.Tables }}
+- [{{ $t.Name }}](#{{ anchor $t.Name }})
+{{- end }}
+{{- range $t := $p.Schema.Tables }}
+
+### {{ $t.Name }}
+{{- with comment $t.Attrs }}
+
+{{ . }}
+{{- end }}
+
+| Column | Type | Null | Default | Comment | Attributes |
+|--------|------|------|---------|---------|------------|
+{{- range $c := $t.Columns }}
+| {{ cell $c.Name }}{{ if pk $t $c }} (PK){{ end }} | {{ cell (type $c) }} | {{ if $c.Type.Null }}YES{{ else }}NO{{ end }} | {{ cell (expr $c.Default) }} | {{ cell (comment $c.Attrs) }} | {{ range $i, $a := attrs $c.Attrs }}{{ if $i }} {{ end }}{{ $a.Name }}{{ with $a.Value }}({{ cell . }}){{ end }}{{ end }} |
+{{- end }}
+{{- if $t.Indexes }}
+
+| Index | Unique | Parts |
+|-------|--------|-------|
+{{- range $idx := $t.Indexes }}
+| {{ cell $idx.Name }} | {{ if $idx.Unique }}YES{{ else }}NO{{ end }} | {{ cell (parts $idx) }} |
+{{- end }}
+{{- end }}
+{{- if $t.ForeignKeys }}
+
+| Foreign Key | Columns | References | On Update | On Delete |
+|-------------|---------|------------|-----------|-----------|
+{{- range $fk := $t.ForeignKeys }}
+| {{ $fk.Symbol }} | {{ names $fk.Columns }} | [{{ $fk.RefTable.Name }}]({{ $p.Link $fk.RefTable }}) ({{ names $fk.RefColumns }}) | {{ $fk.OnUpdate }} | {{ $fk.OnDelete }} |
+{{- end }}
+{{- end }}
+{{- with checks $t }}
+
+| Check | Expression |
+|-------|------------|
+{{- range $c := . }}
+| {{ cell $c.Name }} | {{ cell $c.Expr }} |
+{{- end }}
+{{- end }}
+{{- with attrs $t.Attrs }}
+
+Attributes:{{ range $a := . }} {{ $a.Name }}{{ with $a.Value }}({{ . }}){{ end }}{{ end }}
+{{- end }}
+{{- end }}
+`))
+
+	// DefaultHTML is the default template used for HTML pages.
+	DefaultHTML = htmltemplate.Must(htmltemplate.New("html").
+			Funcs(htmltemplate.FuncMap(TemplateFuncs)).
+			Parse(`
+{{- $p := . -}}
+<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ $p.Schema.Name }}</title>
+</head>
+<body>
+{{- if gt (len $p.Schemas) 1 }}
+<nav>{{ range $s := $p.Schemas }}<a href="{{ $s.Name }}{{ $p.Ext }}">{{ $s.Name }}</a> {{ end }}</nav>
+{{- end }}
+<h1>{{ $p.Schema.Name }}</h1>
+{{- with comment $p.Schema.Attrs }}
+<p>{{ . }}</p>
+{{- end }}
+<h2>Tables</h2>
+<ul>
+{{- range $t := $p.Schema.Tables }}
+<li><a href="#{{ anchor $t.Name }}">{{ $t.Name }}</a></li>
+{{- end }}
+</ul>
+{{- range $t := $p.Schema.Tables }}
+<h3 id="{{ anchor $t.Name }}">{{ $t.Name }}</h3>
+{{- with comment $t.Attrs }}
+<p>{{ . }}</p>
+{{- end }}
+<table>
+<tr><th>Column</th><th>Type</th><th>Null</th><th>Default</th><th>Comment</th><th>Attributes</th></tr>
+{{- range $c := $t.Columns }}
+<tr><td>{{ $c.Name }}{{ if pk $t $c }} (PK){{ end }}</td><td>{{ type $c }}</td><td>{{ if $c.Type.Null }}YES{{ else }}NO{{ end }}</td><td>{{ expr $c.Default }}</td><td>{{ comment $c.Attrs }}</td><td>{{ range $i, $a := attrs $c.Attrs }}{{ if $i }} {{ end }}{{ $a.Name }}{{ with $a.Value }}({{ . }}){{ end }}{{ end }}</td></tr>
+{{- end }}
+</table>
+{{- if $t.Indexes }}
+<table>
+<tr><th>Index</th><th>Unique</th><th>Parts</th></tr>
+{{- range $idx := $t.Indexes }}
+<tr><td>{{ $idx.Name }}</td><td>{{ if $idx.Unique }}YES{{ else }}NO{{ end }}</td><td>{{ parts $idx }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+{{- if $t.ForeignKeys }}
+<table>
+<tr><th>Foreign Key</th><th>Columns</th><th>References</th><th>On Update</th><th>On Delete</th></tr>
+{{- range $fk := $t.ForeignKeys }}
+<tr><td>{{ $fk.Symbol }}</td><td>{{ names $fk.Columns }}</td><td><a href="{{ $p.Link $fk.RefTable }}">{{ $fk.RefTable.Name }}</a> ({{ names $fk.RefColumns }})</td><td>{{ $fk.OnUpdate }}</td><td>{{ $fk.OnDelete }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+{{- with checks $t }}
+<table>
+<tr><th>Check</th><th>Expression</th></tr>
+{{- range $c := . }}
+<tr><td>{{ $c.Name }}</td><td>{{ $c.Expr }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+{{- with attrs $t.Attrs }}
+<p>Attributes:{{ range $a := . }} {{ $a.Name }}{{ with $a.Value }}({{ . }}){{ end }}{{ end }}</p>
+{{- end }}
+{{- end }}
+</body>
+</html>
+`))
+)
+
+// typeName returns the name of the column type. The raw type (as inspected
+// from the database) is preferred, and the type name is used otherwise.
+func typeName(c *schema.Column) string {
+	if c.Type == nil {
+		return ""
+	}
+	if c.Type.Raw != "" {
+		return c.Type.Raw
+	}
+	if v := reflect.Indirect(reflect.ValueOf(c.Type.Type)); v.Kind() == reflect.Struct {
+		if f := v.FieldByName("T"); f.Kind() == reflect.String {
+			return f.String()
+		}
+	}
+	return ""
+}
+
+// exprString returns the string representation of an expression.
+func exprString(x schema.Expr) string {
+	switch x := x.(type) {
+	case *schema.Literal:
+		return x.V
+	case *schema.RawExpr:
+		return x.X
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// comment returns the comment text from the attributes, if exists.
+func comment(attrs []schema.Attr) string {
+	for _, a := range attrs {
+		if c, ok := a.(*schema.Comment); ok {
+			return c.Text
+		}
+	}
+	return ""
+}
+
+// checks returns the checks of the table.
+func checks(t *schema.Table) []*schema.Check {
+	var checks []*schema.Check
+	for _, a := range t.Attrs {
+		if c, ok := a.(*schema.Check); ok {
+			checks = append(checks, c)
+		}
+	}
+	return checks
+}
+
+// attrs returns the attributes that are not documented by the templates
+// explicitly, such as the dialect-specific attributes of the drivers.
+func attrs(attrs []schema.Attr) []*Attr {
+	var list []*Attr
+	for _, a := range attrs {
+		switch a.(type) {
+		case *schema.Comment, *schema.Check:
+			continue
+		}
+		v := reflect.Indirect(reflect.ValueOf(a))
+		// The CREATE statements that are attached to the tables by
+		// the inspection of some drivers (e.g. SQLite) are skipped.
+		if v.Kind() != reflect.Struct || v.Type().Name() == "CreateStmt" {
+			continue
+		}
+		var fields []string
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if !f.IsExported() || f.Anonymous || v.Field(i).IsZero() {
+				continue
+			}
+			fields = append(fields, fmt.Sprintf("%s=%v", f.Name, reflect.Indirect(v.Field(i))))
+		}
+		list = append(list, &Attr{Name: v.Type().Name(), Value: strings.Join(fields, ", ")})
+	}
+	return list
+}
+
+// pk reports if the column is a part of the table primary key.
+func pk(t *schema.Table, c *schema.Column) bool {
+	if t.PrimaryKey == nil {
+		return false
+	}
+	for _, p := range t.PrimaryKey.Parts {
+		if p.C == c {
+			return true
+		}
+	}
+	return false
+}
+
+// cell escapes the given text for using it in a Markdown table cell.
+func cell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>").Replace(s)
+}
+
+// parts returns the columns and expressions of the index.
+func parts(idx *schema.Index) string {
+	list := make([]string, 0, len(idx.Parts))
+	for _, p := range idx.Parts {
+		s := exprString(p.X)
+		if p.C != nil {
+			s = p.C.Name
+		}
+		if p.Desc {
+			s += " DESC"
+		}
+		list = append(list, s)
+	}
+	return strings.Join(list, ", ")
+}
+
+// names returns the names of the columns.
+func names(columns []*schema.Column) string {
+	list := make([]string, len(columns))
+	for i, c := range columns {
+		list[i] = c.Name
+	}
+	return strings.Join(list, ", ")
+}
@@ -0,0 +1,126 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package schemadoc_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ariga.io/atlas/cmd/atlas/internal/schemadoc"
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkdown(t *testing.T) {
+	pages, err := schemadoc.Pages(newRealm(), schemadoc.FormatMarkdown)
+	require.NoError(t, err)
+	require.Len(t, pages, 2)
+	require.Equal(t, "app.md", pages[0].Name())
+	require.Equal(t, "auth.md", pages[1].Name())
+
+	var b bytes.Buffer
+	require.NoError(t, schemadoc.DefaultMarkdown.Execute(&b, pages[0]))
+	require.Equal(t, `# app
+
+Application data.
+
+Schemas: [app](app.md) [auth](auth.md)
+
+## Tables
+
+- [posts](#posts)
+
+### posts
+
+Blog posts.
+
+| Column | Type | Null | Default | Comment | Attributes |
+|--------|------|------|---------|---------|------------|
+| id (PK) | bigint | NO |  |  | AutoIncrement |
+| author_id | bigint | YES |  | the \| author |  |
+| title | varchar(255) | NO | 'untitled' |  |  |
+
+| Index | Unique | Parts |
+|-------|--------|-------|
+| title | YES | title DESC |
+
+| Foreign Key | Columns | References | On Update | On Delete |
+|-------------|---------|------------|-----------|-----------|
+| author | author_id | [users](auth.md#users) (id) |  | CASCADE |
+
+| Check | Expression |
+|-------|------------|
+| positive_id | id > 0 |
+
+Attributes: AutoIncrement(V=100)
+`, b.String())
+}
+
+func TestHTML(t *testing.T) {
+	pages, err := schemadoc.Pages(newRealm(), schemadoc.FormatHTML)
+	require.NoError(t, err)
+	var b bytes.Buffer
+	require.NoError(t, schemadoc.DefaultHTML.Execute(&b, pages[0]))
+	require.Contains(t, b.String(), `<h3 id="posts">posts</h3>`)
+	require.Contains(t, b.String(), `<td><a href="auth.html#users">users</a> (id)</td>`)
+	require.Contains(t, b.String(), `<td>the | author</td>`)
+	require.Contains(t, b.String(), `<td>&#39;untitled&#39;</td>`)
+
+	b.Reset()
+	require.NoError(t, schemadoc.DefaultHTML.Execute(&b, pages[1]))
+	require.Contains(t, b.String(), `<p>&lt;script&gt;</p>`)
+}
+
+func TestParseTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "doc.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte(`{{ range $t := .Schema.Tables }}{{ $t.Name }}: {{ comment $t.Attrs }}{{ end }}`), 0600))
+	pages, err := schemadoc.Pages(newRealm(), schemadoc.FormatHTML)
+	require.NoError(t, err)
+
+	tmpl, err := schemadoc.ParseTemplate(schemadoc.FormatMarkdown, path)
+	require.NoError(t, err)
+	var b bytes.Buffer
+	require.NoError(t, tmpl.Execute(&b, pages[1]))
+	require.Equal(t, "users: <script>", b.String())
+
+	tmpl, err = schemadoc.ParseTemplate(schemadoc.FormatHTML, path)
+	require.NoError(t, err)
+	b.Reset()
+	require.NoError(t, tmpl.Execute(&b, pages[1]))
+	require.Equal(t, "users: &lt;script&gt;", b.String())
+
+	_, err = schemadoc.ParseTemplate("pdf", path)
+	require.EqualError(t, err, `unknown format "pdf", expect "markdown" or "html"`)
+}
+
+func newRealm() *schema.Realm {
+	var (
+		userID = schema.NewIntColumn("id", "bigint")
+		users  = schema.NewTable("users").
+			SetComment("<script>").
+			AddColumns(userID).
+			SetPrimaryKey(schema.NewPrimaryKey(userID))
+		postID   = schema.NewIntColumn("id", "bigint").AddAttrs(&mysql.AutoIncrement{})
+		authorID = schema.NewNullIntColumn("author_id", "bigint").SetComment("the | author")
+		title    = schema.NewStringColumn("title", "varchar(255)").SetDefault(&schema.Literal{V: "'untitled'"})
+		posts    = schema.NewTable("posts").
+				SetComment("Blog posts.").
+				AddColumns(postID, authorID, title).
+				SetPrimaryKey(schema.NewPrimaryKey(postID)).
+				AddIndexes(schema.NewUniqueIndex("title").AddParts(schema.NewColumnPart(title).SetDesc(true))).
+				AddChecks(schema.NewCheck().SetName("positive_id").SetExpr("id > 0")).
+				AddAttrs(&mysql.AutoIncrement{V: 100})
+	)
+	posts.AddForeignKeys(schema.NewForeignKey("author").AddColumns(authorID).SetRefTable(users).AddRefColumns(userID).SetOnDelete(schema.Cascade))
+	title.Type.Raw = "varchar(255)"
+	return schema.NewRealm(
+		schema.New("auth").AddTables(users),
+		schema.New("app").SetComment("Application data.").AddTables(posts),
+	)
+}
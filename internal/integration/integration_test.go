@@ -525,6 +525,15 @@ func plan(t T, name string, changes ...schema.Change) *migrate.Plan {
 	return p
 }
 
+// txDriver is a migrate.TxDriver for drivers that were opened on a transaction.
+type txDriver struct {
+	migrate.Driver
+	tx *sql.Tx
+}
+
+func (d *txDriver) Commit() error   { return d.tx.Commit() }
+func (d *txDriver) Rollback() error { return d.tx.Rollback() }
+
 type rrw migrate.Revisions
 
 func (r *rrw) WriteRevision(_ context.Context, rev *migrate.Revision) error {
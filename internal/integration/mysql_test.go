@@ -9,7 +9,10 @@ import (
 	"database/sql"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -591,6 +594,29 @@ func TestMySQL_CLI(t *testing.T) {
 			testCLISchemaApply(t, fmt.Sprintf(h, charset.V, collate.V), t.dsn("test"))
 		})
 	})
+	t.Run("SchemaApplySQLFiles", func(t *testing.T) {
+		myRun(t, func(t *myTest) {
+			t.dropTables("users")
+			_, err := t.db.Exec("CREATE DATABASE IF NOT EXISTS dev")
+			require.NoError(t, err)
+			t.dropSchemas("dev")
+			// Statements are unqualified, and executed on the schema the dev URL is bound to.
+			f := filepath.Join(t.TempDir(), "schema.sql")
+			require.NoError(t, ioutil.WriteFile(f, []byte("CREATE TABLE users (id int PRIMARY KEY);"), 0644))
+			require.NoError(t, initCLI())
+			cmd := exec.Command("go", "run", "ariga.io/atlas/cmd/atlas",
+				"schema", "apply",
+				"-u", t.dsn("test"),
+				"--dev-url", t.dsn("dev"),
+				"-f", f,
+				"--auto-approve",
+			)
+			out, err := cmd.CombinedOutput()
+			require.NoError(t, err, string(out))
+			require.Contains(t, string(out), "`users`")
+			require.NotNil(t, t.loadUsers())
+		})
+	})
 	t.Run("SchemaApplyWithVars", func(t *testing.T) {
 		h := `
 variable "tenant" {
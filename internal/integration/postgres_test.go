@@ -85,6 +85,48 @@ func TestPostgres_Executor(t *testing.T) {
 	})
 }
 
+func TestPostgres_ExecutorTx(t *testing.T) {
+	pgRun(t, func(t *pgTest) {
+		usersT := t.users()
+		t.dropTables(usersT.Name)
+		t.migrate(&schema.AddTable{T: usersT})
+		t.Cleanup(func() {
+			t.revisionsStorage().(*rrw).clean()
+		})
+		dir, err := migrate.NewLocalDir(t.TempDir())
+		require.NoError(t, err)
+		idx := &schema.Index{Name: "users_x", Table: usersT, Parts: []*schema.IndexPart{{C: usersT.Columns[1]}}}
+		p, err := t.drv.PlanChanges(context.Background(), "add_index", []schema.Change{
+			&schema.ModifyTable{T: usersT, Changes: []schema.Change{&schema.AddIndex{I: idx}}},
+		}, postgres.ConcurrentIndex{})
+		require.NoError(t, err)
+		require.NoError(t, migrate.NewPlanner(t.drv, dir).WritePlan(p))
+
+		// Files that are marked as non-transactional are executed outside a transaction block.
+		ex, err := migrate.NewExecutor(t.drv, dir, t.revisionsStorage(), migrate.WithTx(t.tx))
+		require.NoError(t, err)
+		require.NoError(t, ex.ExecuteN(context.Background(), 0))
+		usersT.Indexes = append(usersT.Indexes, idx)
+		ensureNoChange(t, usersT)
+
+		// Constraints are added as NOT VALID and committed, before they are validated.
+		_, err = t.db.Exec(`INSERT INTO "users" ("x") VALUES (1)`)
+		require.NoError(t, err)
+		ck := schema.NewCheck().SetName("users_x_positive").SetExpr("(x > 0)")
+		p, err = t.drv.PlanChanges(context.Background(), "add_check", []schema.Change{
+			&schema.ModifyTable{T: usersT, Changes: []schema.Change{&schema.AddCheck{C: ck}}},
+		}, postgres.ValidateConstraints{SeparateFile: true})
+		require.NoError(t, err)
+		require.NoError(t, migrate.NewPlanner(t.drv, dir).WritePlan(p))
+		files, err := dir.Files()
+		require.NoError(t, err)
+		require.Len(t, files, 3)
+		require.NoError(t, ex.ExecuteN(context.Background(), 0))
+		usersT.AddChecks(ck)
+		ensureNoChange(t, usersT)
+	})
+}
+
 func TestPostgres_AddDropTable(t *testing.T) {
 	pgRun(t, func(t *pgTest) {
 		testAddDrop(t)
@@ -1089,6 +1131,19 @@ func (t *pgTest) driver() migrate.Driver {
 	return t.drv
 }
 
+// tx opens a transaction driver for the migrate.WithTx option.
+func (t *pgTest) tx(ctx context.Context) (migrate.TxDriver, error) {
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	drv, err := postgres.Open(tx)
+	if err != nil {
+		return nil, err
+	}
+	return &txDriver{Driver: drv, tx: tx}, nil
+}
+
 func (t *pgTest) revisionsStorage() migrate.RevisionReadWriter {
 	return t.rrw
 }
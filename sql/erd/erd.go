@@ -0,0 +1,490 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package erd renders entity-relationship diagrams of schema.Realm objects in
+// the Mermaid, Graphviz (DOT) and PlantUML formats. Tables are rendered as
+// entities with their columns, and foreign keys as relationships between them.
+//
+// The cardinality of a relationship is derived from the foreign-key columns.
+// A child table holds at most one row per parent row if its foreign-key columns
+// are covered by its primary key or one of its unique indexes, and the parent
+// row is optional if one of the foreign-key columns is nullable.
+package erd
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"reflect"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/schema"
+)
+
+// List of supported diagram formats.
+const (
+	FormatMermaid  = "mermaid"
+	FormatDOT      = "dot"
+	FormatPlantUML = "plantuml"
+)
+
+// Formats holds the names of the supported diagram formats.
+var Formats = []string{FormatMermaid, FormatDOT, FormatPlantUML}
+
+// Options configures the rendered diagram.
+type Options struct {
+	// Tables selects the tables to render by their names or glob patterns,
+	// that may be qualified with their schema names (e.g. "public.users").
+	// Patterns prefixed with "!" exclude tables. All tables are rendered if
+	// no tables were selected.
+	Tables []string
+
+	// Depth is the number of foreign-key hops to follow from the selected
+	// tables. The tables that reference, or are referenced by, the selected
+	// tables are rendered as well, and so on. Zero means no neighbours.
+	Depth int
+}
+
+// Marshal renders the entity-relationship diagram of the realm in the given format.
+func Marshal(r *schema.Realm, format string, opts *Options) ([]byte, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if opts.Depth < 0 {
+		return nil, fmt.Errorf("erd: invalid depth %d", opts.Depth)
+	}
+	tables, err := selectTables(r, opts)
+	if err != nil {
+		return nil, err
+	}
+	d := newDiagram(tables)
+	var b bytes.Buffer
+	switch format {
+	case FormatMermaid:
+		d.mermaid(&b)
+	case FormatDOT:
+		d.dot(&b)
+	case FormatPlantUML:
+		d.plantuml(&b)
+	default:
+		return nil, fmt.Errorf("erd: unknown format %q", format)
+	}
+	return b.Bytes(), nil
+}
+
+// selectTables returns the tables selected by the options,
+// in the order they are defined in the realm.
+func selectTables(r *schema.Realm, opts *Options) ([]*schema.Table, error) {
+	var all []*schema.Table
+	for _, s := range r.Schemas {
+		all = append(all, s.Tables...)
+	}
+	if len(opts.Tables) == 0 {
+		return all, nil
+	}
+	var (
+		frontier []*schema.Table
+		selected = make(map[*schema.Table]bool)
+		f        = sqlx.NewNameFilter(opts.Tables, nil)
+	)
+	for _, t := range all {
+		if f.Match(schemaName(t), t.Name) {
+			selected[t] = true
+			frontier = append(frontier, t)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("erd: no tables match %q", opts.Tables)
+	}
+	// Foreign keys are followed in both directions.
+	neighbours := make(map[*schema.Table][]*schema.Table)
+	for _, t := range all {
+		for _, fk := range t.ForeignKeys {
+			if fk.RefTable != nil && fk.RefTable != t {
+				neighbours[t] = append(neighbours[t], fk.RefTable)
+				neighbours[fk.RefTable] = append(neighbours[fk.RefTable], t)
+			}
+		}
+	}
+	for i := 0; i < opts.Depth && len(frontier) > 0; i++ {
+		var next []*schema.Table
+		for _, t := range frontier {
+			for _, n := range neighbours[t] {
+				if !selected[n] {
+					selected[n] = true
+					next = append(next, n)
+				}
+			}
+		}
+		frontier = next
+	}
+	tables := make([]*schema.Table, 0, len(selected))
+	for _, t := range all {
+		if selected[t] {
+			tables = append(tables, t)
+		}
+	}
+	return tables, nil
+}
+
+type (
+	// diagram is the format-agnostic representation of an ERD.
+	diagram struct {
+		entities []*entity
+		edges    []*edge
+	}
+
+	// entity represents a table in the diagram.
+	entity struct {
+		id    string // identifier, safe for all formats.
+		name  string // display name.
+		attrs []*attr
+	}
+
+	// attr represents a column of an entity.
+	attr struct {
+		name, typ  string
+		null       bool
+		pk, fk, uk bool
+	}
+
+	// edge represents a foreign key from a child entity to its parent.
+	edge struct {
+		child, parent *entity
+		name          string
+		// Ports (column positions) of the edge ends.
+		childCol, parentCol int
+		// The child row is unique per parent row (one-to-one).
+		unique bool
+		// The parent row is optional (nullable foreign key).
+		optional bool
+	}
+)
+
+// newDiagram builds the diagram of the given tables. Foreign keys
+// that reference tables that were not selected are left out.
+func newDiagram(tables []*schema.Table) *diagram {
+	var (
+		d       = &diagram{}
+		byTable = make(map[*schema.Table]*entity, len(tables))
+		schemas = make(map[string]bool)
+	)
+	for _, t := range tables {
+		schemas[schemaName(t)] = true
+	}
+	for _, t := range tables {
+		e := &entity{name: t.Name}
+		// Qualify the names in case
+		// the tables span multiple schemas.
+		if len(schemas) > 1 {
+			e.name = schemaName(t) + "." + t.Name
+		}
+		e.id = identifier(e.name)
+		for _, c := range t.Columns {
+			e.attrs = append(e.attrs, &attr{
+				name: c.Name,
+				typ:  typeName(c),
+				null: c.Type != nil && c.Type.Null,
+				pk:   t.PrimaryKey != nil && indexed(t.PrimaryKey, c),
+				fk:   len(c.ForeignKeys) > 0,
+				uk:   uniqueColumn(t, c),
+			})
+		}
+		byTable[t] = e
+		d.entities = append(d.entities, e)
+	}
+	for _, t := range tables {
+		for _, fk := range t.ForeignKeys {
+			parent, ok := byTable[fk.RefTable]
+			if !ok || len(fk.Columns) == 0 || len(fk.RefColumns) == 0 {
+				continue
+			}
+			e := &edge{
+				child:     byTable[t],
+				parent:    parent,
+				name:      fk.Symbol,
+				childCol:  columnIndex(t, fk.Columns[0]),
+				parentCol: columnIndex(fk.RefTable, fk.RefColumns[0]),
+				unique:    uniqueColumns(t, fk.Columns),
+			}
+			for _, c := range fk.Columns {
+				if c.Type != nil && c.Type.Null {
+					e.optional = true
+				}
+			}
+			d.edges = append(d.edges, e)
+		}
+	}
+	return d
+}
+
+// mermaid writes the diagram in the Mermaid erDiagram syntax.
+func (d *diagram) mermaid(w io.Writer) {
+	fmt.Fprintln(w, "erDiagram")
+	for _, e := range d.entities {
+		fmt.Fprintf(w, "  %s", e.id)
+		if e.id != e.name {
+			fmt.Fprintf(w, "[%q]", e.name)
+		}
+		fmt.Fprintln(w, " {")
+		for _, a := range e.attrs {
+			typ := a.typ
+			if typ == "" {
+				typ = "unknown"
+			}
+			fmt.Fprintf(w, "    %s %s", mermaidName(typ), mermaidName(a.name))
+			if keys := a.keys(); len(keys) > 0 {
+				fmt.Fprintf(w, " %s", strings.Join(keys, ","))
+			}
+			if a.null {
+				fmt.Fprint(w, ` "nullable"`)
+			}
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, "  }")
+	}
+	for _, e := range d.edges {
+		fmt.Fprintf(w, "  %s %s--%s %s : %q\n", e.parent.id, e.parentMark(), e.childMark(), e.child.id, e.name)
+	}
+}
+
+// dot writes the diagram in the Graphviz DOT language. Entities are
+// rendered as HTML-like tables, and columns as their rows.
+func (d *diagram) dot(w io.Writer) {
+	fmt.Fprintln(w, "digraph {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, `  node [shape=plaintext];`)
+	fmt.Fprintln(w, `  edge [dir=both];`)
+	for _, e := range d.entities {
+		fmt.Fprintf(w, "  %s [label=<\n", dotQuote(e.id))
+		fmt.Fprintln(w, `    <table border="0" cellborder="1" cellspacing="0" cellpadding="4">`)
+		fmt.Fprintf(w, "      <tr><td colspan=\"3\" bgcolor=\"lightgrey\"><b>%s</b></td></tr>\n", html.EscapeString(e.name))
+		for i, a := range e.attrs {
+			flags := a.keys()
+			if a.null {
+				flags = append(flags, "nullable")
+			}
+			fmt.Fprintf(
+				w, "      <tr><td port=\"c%d\" align=\"left\">%s</td><td align=\"left\">%s</td><td align=\"left\">%s</td></tr>\n",
+				i, html.EscapeString(a.name), html.EscapeString(a.typ), strings.Join(flags, ", "),
+			)
+		}
+		fmt.Fprintln(w, "    </table>")
+		fmt.Fprintln(w, "  >];")
+	}
+	for _, e := range d.edges {
+		head, tail := "teetee", "crowodot"
+		if e.optional {
+			head = "teeodot"
+		}
+		if e.unique {
+			tail = "teeodot"
+		}
+		fmt.Fprintf(
+			w, "  %s:c%d -> %s:c%d [arrowhead=%s, arrowtail=%s, label=%s];\n",
+			dotQuote(e.child.id), e.childCol, dotQuote(e.parent.id), e.parentCol, head, tail, dotQuote(e.name),
+		)
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// plantuml writes the diagram in the PlantUML entity-relationship syntax.
+// Mandatory (NOT NULL) columns are marked with "*", and the primary-key
+// columns are listed first, above the entity separator.
+func (d *diagram) plantuml(w io.Writer) {
+	fmt.Fprintln(w, "@startuml")
+	fmt.Fprintln(w, "hide circle")
+	fmt.Fprintln(w, "skinparam linetype ortho")
+	for _, e := range d.entities {
+		fmt.Fprintf(w, "entity %q as %s {\n", e.name, e.id)
+		var pk, rest []*attr
+		for _, a := range e.attrs {
+			if a.pk {
+				pk = append(pk, a)
+			} else {
+				rest = append(rest, a)
+			}
+		}
+		for _, a := range pk {
+			a.plantuml(w)
+		}
+		if len(pk) > 0 && len(rest) > 0 {
+			fmt.Fprintln(w, "  --")
+		}
+		for _, a := range rest {
+			a.plantuml(w)
+		}
+		fmt.Fprintln(w, "}")
+	}
+	for _, e := range d.edges {
+		fmt.Fprintf(w, "%s %s--%s %s : %s\n", e.parent.id, e.parentMark(), e.childMark(), e.child.id, e.name)
+	}
+	fmt.Fprintln(w, "@enduml")
+}
+
+func (a *attr) plantuml(w io.Writer) {
+	fmt.Fprint(w, "  ")
+	if !a.null {
+		fmt.Fprint(w, "* ")
+	}
+	fmt.Fprintf(w, "%s : %s", a.name, a.typ)
+	for _, k := range a.keys() {
+		fmt.Fprintf(w, " <<%s>>", k)
+	}
+	fmt.Fprintln(w)
+}
+
+// keys returns the key markers of the attribute.
+func (a *attr) keys() []string {
+	var keys []string
+	if a.pk {
+		keys = append(keys, "PK")
+	}
+	if a.fk {
+		keys = append(keys, "FK")
+	}
+	if a.uk {
+		keys = append(keys, "UK")
+	}
+	return keys
+}
+
+// parentMark returns the crow's foot marker of the parent side.
+// Both Mermaid and PlantUML share the same notation.
+func (e *edge) parentMark() string {
+	if e.optional {
+		return "|o"
+	}
+	return "||"
+}
+
+// childMark returns the crow's foot marker of the child side.
+func (e *edge) childMark() string {
+	if e.unique {
+		return "o|"
+	}
+	return "o{"
+}
+
+// typeName returns the name of the column type. The raw type (as inspected
+// from the database) is preferred, and the type name is used otherwise.
+func typeName(c *schema.Column) string {
+	if c.Type == nil {
+		return ""
+	}
+	if c.Type.Raw != "" {
+		return c.Type.Raw
+	}
+	// All types defined by atlas hold their names in the
+	// T field. e.g. schema.StringType or postgres.ArrayType.
+	if v := reflect.Indirect(reflect.ValueOf(c.Type.Type)); v.Kind() == reflect.Struct {
+		if f := v.FieldByName("T"); f.Kind() == reflect.String {
+			return f.String()
+		}
+	}
+	return ""
+}
+
+// uniqueColumn reports if the column is the only part of a unique index.
+func uniqueColumn(t *schema.Table, c *schema.Column) bool {
+	for _, idx := range t.Indexes {
+		if idx.Unique && len(idx.Parts) == 1 && idx.Parts[0].C == c {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueColumns reports if the given columns are covered by the primary key
+// or one of the unique indexes of the table. i.e. the values of these columns
+// are unique in the table.
+func uniqueColumns(t *schema.Table, columns []*schema.Column) bool {
+	covers := func(idx *schema.Index) bool {
+		if len(idx.Parts) == 0 {
+			return false
+		}
+		for _, p := range idx.Parts {
+			if p.C == nil || !containsColumn(columns, p.C) {
+				return false
+			}
+		}
+		return true
+	}
+	if t.PrimaryKey != nil && covers(t.PrimaryKey) {
+		return true
+	}
+	for _, idx := range t.Indexes {
+		if idx.Unique && covers(idx) {
+			return true
+		}
+	}
+	return false
+}
+
+// indexed reports if the column is a part of the index.
+func indexed(idx *schema.Index, c *schema.Column) bool {
+	for _, p := range idx.Parts {
+		if p.C == c {
+			return true
+		}
+	}
+	return false
+}
+
+func containsColumn(columns []*schema.Column, c *schema.Column) bool {
+	for i := range columns {
+		if columns[i] == c {
+			return true
+		}
+	}
+	return false
+}
+
+// columnIndex returns the position of the column in the table.
+func columnIndex(t *schema.Table, c *schema.Column) int {
+	for i := range t.Columns {
+		if t.Columns[i] == c || t.Columns[i].Name == c.Name {
+			return i
+		}
+	}
+	return 0
+}
+
+func schemaName(t *schema.Table) string {
+	if t.Schema == nil {
+		return ""
+	}
+	return t.Schema.Name
+}
+
+// identifier returns a name that is safe to use as an entity identifier
+// in all formats, by replacing the characters that are not allowed.
+func identifier(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// mermaidName returns a name that is safe to use as an attribute name or type
+// in Mermaid. Parentheses and brackets are allowed in types (e.g. varchar(255)).
+func mermaidName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune("_-()[]", r):
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// dotQuote returns the double-quoted DOT string of s.
+func dotQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
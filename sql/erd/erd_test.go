@@ -0,0 +1,161 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package erd_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/erd"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshal_Mermaid(t *testing.T) {
+	b, err := erd.Marshal(newRealm(), erd.FormatMermaid, nil)
+	require.NoError(t, err)
+	require.Equal(t, `erDiagram
+  users {
+    bigint id PK
+    varchar(255) email UK
+  }
+  profiles {
+    bigint user_id PK,FK
+    text bio "nullable"
+  }
+  posts {
+    bigint id PK
+    bigint author_id FK "nullable"
+    decimal(10_2) price
+  }
+  tags {
+    bigint id PK
+  }
+  users ||--o| profiles : "profiles_user_id_fkey"
+  users |o--o{ posts : "posts_author_id_fkey"
+`, string(b))
+}
+
+func TestMarshal_DOT(t *testing.T) {
+	b, err := erd.Marshal(newRealm(), erd.FormatDOT, &erd.Options{Tables: []string{"profiles"}, Depth: 1})
+	require.NoError(t, err)
+	require.Equal(t, `digraph {
+  rankdir=LR;
+  node [shape=plaintext];
+  edge [dir=both];
+  "users" [label=<
+    <table border="0" cellborder="1" cellspacing="0" cellpadding="4">
+      <tr><td colspan="3" bgcolor="lightgrey"><b>users</b></td></tr>
+      <tr><td port="c0" align="left">id</td><td align="left">bigint</td><td align="left">PK</td></tr>
+      <tr><td port="c1" align="left">email</td><td align="left">varchar(255)</td><td align="left">UK</td></tr>
+    </table>
+  >];
+  "profiles" [label=<
+    <table border="0" cellborder="1" cellspacing="0" cellpadding="4">
+      <tr><td colspan="3" bgcolor="lightgrey"><b>profiles</b></td></tr>
+      <tr><td port="c0" align="left">user_id</td><td align="left">bigint</td><td align="left">PK, FK</td></tr>
+      <tr><td port="c1" align="left">bio</td><td align="left">text</td><td align="left">nullable</td></tr>
+    </table>
+  >];
+  "profiles":c0 -> "users":c0 [arrowhead=teetee, arrowtail=teeodot, label="profiles_user_id_fkey"];
+}
+`, string(b))
+}
+
+func TestMarshal_PlantUML(t *testing.T) {
+	b, err := erd.Marshal(newRealm(), erd.FormatPlantUML, &erd.Options{Tables: []string{"posts"}})
+	require.NoError(t, err)
+	require.Equal(t, `@startuml
+hide circle
+skinparam linetype ortho
+entity "posts" as posts {
+  * id : bigint <<PK>>
+  --
+  author_id : bigint <<FK>>
+  * price : decimal(10,2)
+}
+@enduml
+`, string(b))
+}
+
+func TestMarshal_Select(t *testing.T) {
+	r := newRealm()
+	for _, tt := range []struct {
+		opts   *erd.Options
+		tables []string
+	}{
+		{opts: &erd.Options{Tables: []string{"tags"}, Depth: 5}, tables: []string{"tags"}},
+		{opts: &erd.Options{Tables: []string{"posts"}, Depth: 1}, tables: []string{"users", "posts"}},
+		{opts: &erd.Options{Tables: []string{"posts"}, Depth: 2}, tables: []string{"users", "profiles", "posts"}},
+		{opts: &erd.Options{Tables: []string{"main.p*"}}, tables: []string{"profiles", "posts"}},
+		{opts: &erd.Options{Tables: []string{"*", "!tags"}}, tables: []string{"users", "profiles", "posts"}},
+	} {
+		b, err := erd.Marshal(r, erd.FormatPlantUML, tt.opts)
+		require.NoError(t, err)
+		for _, s := range r.Schemas[0].Tables {
+			if contains(tt.tables, s.Name) {
+				require.Contains(t, string(b), "entity \""+s.Name+"\"", tt.opts.Tables)
+			} else {
+				require.NotContains(t, string(b), "entity \""+s.Name+"\"", tt.opts.Tables)
+			}
+		}
+	}
+	_, err := erd.Marshal(r, erd.FormatMermaid, &erd.Options{Tables: []string{"unknown"}})
+	require.EqualError(t, err, `erd: no tables match ["unknown"]`)
+	_, err = erd.Marshal(r, "svg", nil)
+	require.EqualError(t, err, `erd: unknown format "svg"`)
+}
+
+func TestMarshal_Schemas(t *testing.T) {
+	r := newRealm()
+	other := schema.New("other").AddTables(schema.NewTable("logs").AddColumns(schema.NewStringColumn("text", "text")))
+	other.SetRealm(r)
+	r.Schemas = append(r.Schemas, other)
+	b, err := erd.Marshal(r, erd.FormatMermaid, &erd.Options{Tables: []string{"logs", "tags"}})
+	require.NoError(t, err)
+	require.Equal(t, `erDiagram
+  main_tags["main.tags"] {
+    bigint id PK
+  }
+  other_logs["other.logs"] {
+    text text
+  }
+`, string(b))
+}
+
+func newRealm() *schema.Realm {
+	var (
+		userID = schema.NewIntColumn("id", "bigint")
+		users  = schema.NewTable("users").
+			AddColumns(userID, schema.NewStringColumn("email", "varchar(255)")).
+			SetPrimaryKey(schema.NewPrimaryKey(userID))
+		profileID = schema.NewIntColumn("user_id", "bigint")
+		profiles  = schema.NewTable("profiles").
+				AddColumns(profileID, schema.NewNullStringColumn("bio", "text")).
+				SetPrimaryKey(schema.NewPrimaryKey(profileID))
+		postID   = schema.NewIntColumn("id", "bigint")
+		authorID = schema.NewNullIntColumn("author_id", "bigint")
+		posts    = schema.NewTable("posts").
+				AddColumns(postID, authorID, schema.NewDecimalColumn("price", "decimal")).
+				SetPrimaryKey(schema.NewPrimaryKey(postID))
+		tagID = schema.NewIntColumn("id", "bigint")
+		tags  = schema.NewTable("tags").AddColumns(tagID).SetPrimaryKey(schema.NewPrimaryKey(tagID))
+	)
+	users.Columns[1].Type.Raw = "varchar(255)"
+	posts.Columns[2].Type.Raw = "decimal(10,2)"
+	users.AddIndexes(schema.NewUniqueIndex("users_email").AddColumns(users.Columns[1]))
+	profiles.AddForeignKeys(schema.NewForeignKey("profiles_user_id_fkey").AddColumns(profileID).SetRefTable(users).AddRefColumns(userID))
+	posts.AddForeignKeys(schema.NewForeignKey("posts_author_id_fkey").AddColumns(authorID).SetRefTable(users).AddRefColumns(userID))
+	return schema.NewRealm(schema.New("main").AddTables(users, profiles, posts, tags))
+}
+
+func contains(s []string, v string) bool {
+	for i := range s {
+		if s[i] == v {
+			return true
+		}
+	}
+	return false
+}
@@ -33,6 +33,38 @@ type (
 	CheckSpecFunc         func(*schema.Check) *sqlspec.Check
 )
 
+type (
+	// RoleSpec holds a specification for a database role (or user). The role
+	// options (e.g. login) are driver-specific, and stored as extra attributes.
+	RoleSpec struct {
+		Name string `spec:",name"`
+		schemahcl.DefaultExtension
+	}
+	// PermissionSpec holds a specification for the privileges granted to a role on a
+	// schema, a table or a column. The privileges and the grant option are stored as
+	// extra attributes.
+	PermissionSpec struct {
+		To *schemahcl.Ref `spec:"to"`
+		On *schemahcl.Ref `spec:"on"`
+		schemahcl.DefaultExtension
+	}
+	// Grant describes a PermissionSpec after its references were resolved. It is used
+	// by the drivers for converting their permissions from (and to) specs.
+	Grant struct {
+		Role       string
+		Schema     *schema.Schema
+		Table      *schema.Table
+		Column     *schema.Column
+		Privileges []string
+		Grantable  bool
+	}
+)
+
+func init() {
+	schemahcl.Register("role", &RoleSpec{})
+	schemahcl.Register("permission", &PermissionSpec{})
+}
+
 // Scan populates the Realm from the schemas and table specs.
 func Scan(r *schema.Realm, schemas []*sqlspec.Schema, tables []*sqlspec.Table, convertTable ConvertTableFunc) error {
 	// Build the schemas.
@@ -261,7 +293,7 @@ func linkForeignKeys(tbl *schema.Table, sch *schema.Schema, table *sqlspec.Table
 			fk.Columns = append(fk.Columns, c)
 		}
 		for i, ref := range spec.RefColumns {
-			t, c, err := externalRef(ref, sch)
+			t, c, err := ExternalColumnByRef(ref, sch)
 			if isLocalRef(ref) {
 				t = fk.Table
 				c, err = ColumnByRef(fk.Table, ref)
@@ -506,7 +538,7 @@ func FromForeignKey(s *schema.ForeignKey) (*sqlspec.ForeignKey, error) {
 	for _, v := range s.RefColumns {
 		ref := ColumnRef(v.Name)
 		if s.Table != s.RefTable {
-			ref = externalColRef(v.Name, s.RefTable.Name)
+			ref = ExternalColumnRef(v.Name, s.RefTable.Name)
 		}
 		r = append(r, ref)
 	}
@@ -557,7 +589,10 @@ func ColumnByRef(t *schema.Table, ref *schemahcl.Ref) (*schema.Column, error) {
 	return c, nil
 }
 
-func externalRef(ref *schemahcl.Ref, sch *schema.Schema) (*schema.Table, *schema.Column, error) {
+// ExternalColumnByRef returns the table and the column referenced by the given
+// reference. The table is searched in the given schema, and in other schemas in
+// its connected realm in case the reference is qualified.
+func ExternalColumnByRef(ref *schemahcl.Ref, sch *schema.Schema) (*schema.Table, *schema.Column, error) {
 	tbl, err := findTable(ref, sch)
 	if err != nil {
 		return nil, nil, err
@@ -577,6 +612,12 @@ func findTable(ref *schemahcl.Ref, sch *schema.Schema) (*schema.Table, error) {
 	if err != nil {
 		return nil, err
 	}
+	return lookupTable(qualifier, tblName, sch)
+}
+
+// lookupTable finds a table by its qualifier and name. Unqualified tables are
+// searched in the provided schema, and qualified tables in its connected realm.
+func lookupTable(qualifier, tblName string, sch *schema.Schema) (*schema.Table, error) {
 	// Search the same schema.
 	if qualifier == "" || qualifier == sch.Name {
 		tbl, ok := sch.Table(tblName)
@@ -600,6 +641,156 @@ func findTable(ref *schemahcl.Ref, sch *schema.Schema) (*schema.Table, error) {
 	return tbl, nil
 }
 
+// TableByRef returns the table referenced by ref (e.g. table.users or table.public.users).
+// If the reference is qualified with another schema, the table is searched in the realm.
+func TableByRef(ref *schemahcl.Ref, sch *schema.Schema) (*schema.Table, error) {
+	if ref == nil || !strings.HasPrefix(ref.V, "$table.") {
+		return nil, fmt.Errorf("sqlspec: expected table reference, got %v", ref)
+	}
+	switch s := strings.Split(strings.TrimPrefix(ref.V, "$table."), "."); len(s) {
+	case 1:
+		return lookupTable("", s[0], sch)
+	case 2:
+		return lookupTable(s[0], s[1], sch)
+	default:
+		return nil, fmt.Errorf("sqlspec: failed to extract table name from %q", ref.V)
+	}
+}
+
+// RealmObjectByRef returns the schema, table and column referenced by ref. The reference
+// can point to a schema (schema.public), a table (table.users or table.public.users) or a
+// table column (table.users.column.id). Unqualified tables are searched in all schemas of
+// the realm, and must be unique.
+func RealmObjectByRef(ref *schemahcl.Ref, r *schema.Realm) (*schema.Schema, *schema.Table, *schema.Column, error) {
+	if ref == nil {
+		return nil, nil, nil, errors.New("sqlspec: missing reference")
+	}
+	if strings.HasPrefix(ref.V, "$schema.") {
+		n, err := SchemaName(ref)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		s, ok := r.Schema(n)
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("sqlspec: schema %q not found", n)
+		}
+		return s, nil, nil, nil
+	}
+	if !strings.HasPrefix(ref.V, "$table.") {
+		return nil, nil, nil, fmt.Errorf("sqlspec: expected schema, table or column reference, got %q", ref.V)
+	}
+	tref, cname := ref.V, ""
+	if s := strings.Split(ref.V, ".$column."); len(s) == 2 {
+		tref, cname = s[0], s[1]
+	}
+	var (
+		t     *schema.Table
+		parts = strings.Split(strings.TrimPrefix(tref, "$table."), ".")
+	)
+	switch len(parts) {
+	case 1:
+		for _, s := range r.Schemas {
+			t1, ok := s.Table(parts[0])
+			if !ok {
+				continue
+			}
+			if t != nil {
+				return nil, nil, nil, fmt.Errorf("sqlspec: ambiguous reference to table %q, qualify it with its schema name", parts[0])
+			}
+			t = t1
+		}
+	case 2:
+		if s, ok := r.Schema(parts[0]); ok {
+			t, _ = s.Table(parts[1])
+		}
+	default:
+		return nil, nil, nil, fmt.Errorf("sqlspec: failed to extract table name from %q", ref.V)
+	}
+	if t == nil {
+		return nil, nil, nil, fmt.Errorf("sqlspec: table %q not found", strings.Join(parts, "."))
+	}
+	if cname == "" {
+		return t.Schema, t, nil, nil
+	}
+	c, ok := t.Column(cname)
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("sqlspec: unknown column %q in table %q", cname, t.Name)
+	}
+	return t.Schema, t, c, nil
+}
+
+// RealmObjectRef returns the reference of a schema, a table or a table column in the
+// realm. Tables are qualified with their schema name only if their name is not unique
+// in the realm. See RealmObjectByRef for more info.
+func RealmObjectRef(r *schema.Realm, s *schema.Schema, t *schema.Table, c *schema.Column) *schemahcl.Ref {
+	if t == nil {
+		return SchemaRef(s.Name)
+	}
+	var qualifier string
+	for _, s2 := range r.Schemas {
+		if _, ok := s2.Table(t.Name); ok && s2.Name != s.Name {
+			qualifier = s.Name
+		}
+	}
+	ref := TableRef(qualifier, t.Name)
+	if c != nil {
+		ref.V += ".$column." + c.Name
+	}
+	return ref
+}
+
+// ConvertGrant converts a PermissionSpec into a Grant by resolving its references in the
+// realm. Privileges are returned in upper-case, and their variable names are converted back
+// to the privilege names (e.g. CREATE_VIEW to CREATE VIEW).
+func ConvertGrant(spec *PermissionSpec, r *schema.Realm) (*Grant, error) {
+	if spec.To == nil || !strings.HasPrefix(spec.To.V, "$role.") {
+		return nil, errors.New("permission: expect 'to' attribute to reference a role")
+	}
+	var (
+		err error
+		g   = &Grant{Role: strings.TrimPrefix(spec.To.V, "$role.")}
+	)
+	if g.Schema, g.Table, g.Column, err = RealmObjectByRef(spec.On, r); err != nil {
+		return nil, fmt.Errorf("permission for role %q: %w", g.Role, err)
+	}
+	attr, ok := spec.Attr("privileges")
+	if !ok {
+		return nil, fmt.Errorf("permission for role %q: missing 'privileges' attribute", g.Role)
+	}
+	if g.Privileges, err = attr.Strings(); err != nil {
+		return nil, fmt.Errorf("permission for role %q: %w", g.Role, err)
+	}
+	for i := range g.Privileges {
+		g.Privileges[i] = strings.ToUpper(FromVar(g.Privileges[i]))
+	}
+	if attr, ok := spec.Attr("grantable"); ok {
+		if g.Grantable, err = attr.Bool(); err != nil {
+			return nil, fmt.Errorf("permission for role %q: %w", g.Role, err)
+		}
+	}
+	return g, nil
+}
+
+// FromGrant converts a Grant of the given realm into a PermissionSpec.
+func FromGrant(r *schema.Realm, g *Grant) (*PermissionSpec, error) {
+	if len(g.Privileges) == 0 {
+		return nil, fmt.Errorf("missing privileges for permission of role %q", g.Role)
+	}
+	spec := &PermissionSpec{
+		To: &schemahcl.Ref{V: "$role." + g.Role},
+		On: RealmObjectRef(r, g.Schema, g.Table, g.Column),
+	}
+	vars := make([]string, len(g.Privileges))
+	for i, p := range g.Privileges {
+		vars[i] = Var(p)
+	}
+	spec.Extra.Attrs = append(spec.Extra.Attrs, ListAttr("privileges", vars...))
+	if g.Grantable {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, BoolAttr("grantable", true))
+	}
+	return spec, nil
+}
+
 func tableName(ref *schemahcl.Ref) (qualifier, name string, err error) {
 	s := strings.Split(ref.V, "$column.")
 	if len(s) != 2 {
@@ -622,12 +813,22 @@ func isLocalRef(r *schemahcl.Ref) bool {
 	return strings.HasPrefix(r.V, "$column")
 }
 
+// TableRef returns the reference of a table by its name. A non-empty
+// qualifier is used for referencing tables in other schemas.
+func TableRef(qualifier, tName string) *schemahcl.Ref {
+	if qualifier != "" {
+		return &schemahcl.Ref{V: "$table." + qualifier + "." + tName}
+	}
+	return &schemahcl.Ref{V: "$table." + tName}
+}
+
 // ColumnRef returns the reference of a column by its name.
 func ColumnRef(cName string) *schemahcl.Ref {
 	return &schemahcl.Ref{V: "$column." + cName}
 }
 
-func externalColRef(cName string, tName string) *schemahcl.Ref {
+// ExternalColumnRef returns the reference of a column by its name and its table name.
+func ExternalColumnRef(cName string, tName string) *schemahcl.Ref {
 	return &schemahcl.Ref{V: "$table." + tName + ".$column." + cName}
 }
 
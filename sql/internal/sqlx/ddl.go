@@ -0,0 +1,1927 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+type (
+	// A DDLDriver wraps the dialect-specific parts of a DDLParser.
+	DDLDriver interface {
+		// ParseType returns the schema.Type of a column type. Keywords are
+		// lower-cased and whitespace is normalized (e.g. "varchar(255)").
+		ParseType(string) (schema.Type, error)
+
+		// DefaultExpr returns the schema.Expr of a column default value.
+		DefaultExpr(*schema.Column, string) schema.Expr
+
+		// ParseOption parses a dialect-specific option (e.g. AUTO_INCREMENT) of the
+		// given element at the current position of the scanner, and reports if the
+		// option was handled. The element is one of: *schema.Schema, *schema.Table,
+		// *schema.Column, *schema.Index, *schema.IndexPart, *schema.ForeignKey or
+		// *schema.Check.
+		ParseOption(*DDLScanner, interface{}) (bool, error)
+
+		// DefaultName returns the name the database gives to an unnamed element of
+		// the table. The element is the table primary key, or one of its indexes,
+		// foreign keys or checks.
+		DefaultName(*schema.Table, interface{}) string
+	}
+
+	// DDLParser implements the migrate.DDLParser interface for the common subset
+	// of DDL statements supported by the drivers: CREATE, ALTER and DROP of schemas
+	// and tables, CREATE and DROP of indexes and their constraints, RENAME TABLE and USE.
+	// Statements that do not change the schema (e.g. INSERT, SET or PRAGMA) are ignored,
+	// and any other statement is reported with a migrate.UnsupportedStmtError.
+	DDLParser struct {
+		DDLDriver
+		// Schema is the name of the schema unqualified names are resolved in.
+		// It is added to the realm on first use, in case it does not exist,
+		// and is changed by USE statements.
+		Schema string
+		// Quotes holds the characters used for quoting identifiers.
+		Quotes string
+		// Escapes indicates backslash escapes are used in string literals.
+		Escapes bool
+		// HashComments indicates '#' starts a single-line comment.
+		HashComments bool
+		// Fold indicates unquoted identifiers are folded to lower-case.
+		Fold bool
+		// NullPK indicates primary-key columns are not implicitly NOT NULL.
+		NullPK bool
+	}
+
+	// DDLScanner holds the tokens of a DDL statement. It is used by the
+	// DDLParser, and by DDLDriver implementations for parsing options.
+	DDLScanner struct {
+		p    *DDLParser
+		stmt string
+		toks []ddlToken
+		pos  int
+	}
+
+	ddlToken struct {
+		kind       ddlKind
+		text       string // raw text.
+		start, end int    // position in statement.
+	}
+
+	ddlKind uint8
+)
+
+const (
+	tokWord ddlKind = iota + 1
+	tokIdent
+	tokString
+	tokNumber
+	tokPunct
+)
+
+// ParseDDL implements the migrate.DDLParser interface.
+func (p *DDLParser) ParseDDL(r *schema.Realm, stmt string) (schema.Changes, error) {
+	s, err := p.scan(stmt)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		changes schema.Changes
+		d       = &ddl{DDLParser: p, DDLScanner: s, r: r}
+	)
+	switch {
+	// Empty statement (e.g. comments only).
+	case s.Done():
+		return nil, nil
+	case s.Accept("CREATE"):
+		changes, err = d.create()
+	case s.Accept("ALTER", "TABLE"):
+		changes, err = d.alterTable()
+	case s.Accept("DROP"):
+		changes, err = d.drop()
+	case s.Accept("COMMENT", "ON"):
+		changes, err = d.comment()
+	case s.Accept("RENAME", "TABLE"):
+		changes, err = d.renameTables()
+	case s.Accept("USE"):
+		err = d.use()
+	// Statements that do not change the schema.
+	case s.Is("SET"), s.Is("PRAGMA"), s.Is("BEGIN"), s.Is("START", "TRANSACTION"), s.Is("COMMIT"),
+		s.Is("INSERT"), s.Is("UPDATE"), s.Is("DELETE"):
+		return nil, nil
+	default:
+		return nil, s.unsupported()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !s.Done() {
+		return nil, s.Unexpected()
+	}
+	return changes, nil
+}
+
+// Is reports if the next tokens match the given words. Keywords are matched
+// case-insensitive, and never match quoted identifiers or literals.
+func (s *DDLScanner) Is(words ...string) bool {
+	for i, w := range words {
+		if s.pos+i >= len(s.toks) {
+			return false
+		}
+		switch t := s.toks[s.pos+i]; t.kind {
+		case tokWord:
+			if !strings.EqualFold(t.text, w) {
+				return false
+			}
+		case tokPunct:
+			if t.text != w {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Accept consumes the next tokens if they match the given words.
+func (s *DDLScanner) Accept(words ...string) bool {
+	if !s.Is(words...) {
+		return false
+	}
+	s.pos += len(words)
+	return true
+}
+
+// Expect is like Accept, but returns an error if the tokens do not match.
+func (s *DDLScanner) Expect(words ...string) error {
+	for _, w := range words {
+		if !s.Accept(w) {
+			return s.Unexpected()
+		}
+	}
+	return nil
+}
+
+// Done reports if all tokens of the statement were consumed.
+func (s *DDLScanner) Done() bool {
+	return s.pos >= len(s.toks)
+}
+
+// Ident consumes the next identifier and returns its name.
+func (s *DDLScanner) Ident() (string, error) {
+	if s.Done() {
+		return "", s.Unexpected()
+	}
+	switch t := s.toks[s.pos]; t.kind {
+	case tokWord:
+		s.pos++
+		if s.p.Fold {
+			return strings.ToLower(t.text), nil
+		}
+		return t.text, nil
+	case tokIdent:
+		s.pos++
+		return s.unquote(t), nil
+	default:
+		return "", s.Unexpected()
+	}
+}
+
+// Str consumes the next string literal and returns its unquoted value.
+func (s *DDLScanner) Str() (string, error) {
+	if s.Done() || s.toks[s.pos].kind != tokString {
+		return "", s.Unexpected()
+	}
+	s.pos++
+	return s.unquote(s.toks[s.pos-1]), nil
+}
+
+// Int consumes the next integer literal and returns its value.
+func (s *DDLScanner) Int() (int64, error) {
+	if s.Done() || s.toks[s.pos].kind != tokNumber {
+		return 0, s.Unexpected()
+	}
+	v, err := strconv.ParseInt(s.toks[s.pos].text, 10, 64)
+	if err != nil {
+		return 0, s.Unexpected()
+	}
+	s.pos++
+	return v, nil
+}
+
+// Value consumes the next literal, keyword or identifier (e.g. the value
+// of an option) and returns it. Strings and identifiers are unquoted.
+func (s *DDLScanner) Value() (string, error) {
+	if s.Done() {
+		return "", s.Unexpected()
+	}
+	switch t := s.toks[s.pos]; t.kind {
+	case tokWord, tokNumber:
+		s.pos++
+		return t.text, nil
+	case tokIdent, tokString:
+		s.pos++
+		return s.unquote(t), nil
+	default:
+		return "", s.Unexpected()
+	}
+}
+
+// Term consumes a simple expression (e.g. a literal, a function call or a
+// parenthesized expression), and returns its source text.
+func (s *DDLScanner) Term() (string, error) {
+	if s.Done() {
+		return "", s.Unexpected()
+	}
+	start := s.toks[s.pos].start
+	if s.Is("-") || s.Is("+") {
+		s.pos++
+	}
+	switch t := s.peek(0); {
+	case t == nil, t.kind == tokPunct && t.text != "(":
+		return "", s.Unexpected()
+	case t.text == "(":
+		if err := s.skipParens(); err != nil {
+			return "", err
+		}
+	default:
+		s.pos++
+		switch n := s.peek(0); {
+		case n == nil || t.kind != tokWord:
+		// Function calls (e.g. now()).
+		case n.text == "(":
+			if err := s.skipParens(); err != nil {
+				return "", err
+			}
+		// Typed literals (e.g. b'01').
+		case n.kind == tokString && n.start == t.end:
+			s.pos++
+		}
+	}
+	// Type casts (e.g. '{}'::text[]).
+	for s.Accept("::") {
+		if _, err := s.typ(); err != nil {
+			return "", err
+		}
+	}
+	return s.stmt[start:s.toks[s.pos-1].end], nil
+}
+
+// Paren consumes a parenthesized expression and returns its inner source text.
+func (s *DDLScanner) Paren() (string, error) {
+	if !s.Is("(") {
+		return "", s.Unexpected()
+	}
+	open := s.toks[s.pos]
+	if err := s.skipParens(); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(s.stmt[open.end:s.toks[s.pos-1].start]), nil
+}
+
+// Rest consumes the remaining tokens and returns their source text.
+func (s *DDLScanner) Rest() string {
+	if s.Done() {
+		return ""
+	}
+	start := s.toks[s.pos].start
+	s.pos = len(s.toks)
+	return s.stmt[start:s.toks[s.pos-1].end]
+}
+
+// Errorf returns a migrate.UnsupportedStmtError with the given reason.
+func (s *DDLScanner) Errorf(format string, args ...interface{}) error {
+	return migrate.UnsupportedStmtError{Stmt: s.stmt, Reason: fmt.Sprintf(format, args...)}
+}
+
+// Unexpected returns an error for the current token.
+func (s *DDLScanner) Unexpected() error {
+	if s.Done() {
+		return s.Errorf("unexpected end of statement")
+	}
+	return s.Errorf("unexpected %q", s.toks[s.pos].text)
+}
+
+// unsupported returns an error for an unsupported statement,
+// that is described by the words scanned until this point.
+func (s *DDLScanner) unsupported() error {
+	var words []string
+	for i := 0; i <= s.pos && i < len(s.toks) && s.toks[i].kind == tokWord; i++ {
+		words = append(words, strings.ToUpper(s.toks[i].text))
+	}
+	if len(words) == 0 {
+		return s.Unexpected()
+	}
+	return s.Errorf("%s is not supported", strings.Join(words, " "))
+}
+
+// peek returns the i-th token from the current position, or nil.
+func (s *DDLScanner) peek(i int) *ddlToken {
+	if s.pos+i >= len(s.toks) {
+		return nil
+	}
+	return &s.toks[s.pos+i]
+}
+
+// skipParens consumes a balanced parenthesized group of tokens.
+func (s *DDLScanner) skipParens() error {
+	if !s.Is("(") {
+		return s.Unexpected()
+	}
+	for depth := 0; !s.Done(); {
+		switch t := s.toks[s.pos]; {
+		case t.kind != tokPunct:
+		case t.text == "(":
+			depth++
+		case t.text == ")":
+			depth--
+		}
+		s.pos++
+		if depth == 0 {
+			return nil
+		}
+	}
+	return s.Unexpected()
+}
+
+// skipExpr consumes the tokens of an expression until
+// a comma, or a closing parenthesis at the top level.
+func (s *DDLScanner) skipExpr() error {
+	for !s.Done() && !s.Is(",") && !s.Is(")") {
+		if s.Is("(") {
+			if err := s.skipParens(); err != nil {
+				return err
+			}
+			continue
+		}
+		s.pos++
+	}
+	return nil
+}
+
+// typeStop holds the keywords that end a column type definition.
+var typeStop = []string{
+	"NOT", "NULL", "DEFAULT", "PRIMARY", "UNIQUE", "REFERENCES", "CHECK", "CONSTRAINT", "COLLATE", "COMMENT",
+	"AUTO_INCREMENT", "AUTOINCREMENT", "GENERATED", "AS", "ON", "CHARSET", "KEY", "USING", "FIRST", "AFTER",
+}
+
+// typ consumes a column type, and returns its normalized form.
+// An empty string is returned if the type is missing (e.g. SQLite).
+func (s *DDLScanner) typ() (string, error) {
+	var b strings.Builder
+	for i := 0; !s.Done(); i++ {
+		t := s.toks[s.pos]
+		if s.Is(",") || s.Is(")") || s.Is("::") || s.Is("CHARACTER", "SET") || s.isAny(typeStop...) {
+			break
+		}
+		if t.text == "(" {
+			start := s.pos
+			if err := s.skipParens(); err != nil {
+				return "", err
+			}
+			for _, t := range s.toks[start:s.pos] {
+				b.WriteString(s.typeText(t))
+			}
+			continue
+		}
+		if i > 0 && t.kind != tokPunct && t.text != "[" {
+			b.WriteByte(' ')
+		}
+		b.WriteString(s.typeText(t))
+		s.pos++
+	}
+	return b.String(), nil
+}
+
+// typeText returns the text of a type token. Keywords are lower-cased.
+func (s *DDLScanner) typeText(t ddlToken) string {
+	switch t.kind {
+	case tokWord:
+		return strings.ToLower(t.text)
+	case tokIdent:
+		return s.unquote(t)
+	default:
+		return t.text
+	}
+}
+
+// isAny reports if the next token matches one of the given words.
+func (s *DDLScanner) isAny(words ...string) bool {
+	for _, w := range words {
+		if s.Is(w) {
+			return true
+		}
+	}
+	return false
+}
+
+// unquote returns the value of a quoted token.
+func (s *DDLScanner) unquote(t ddlToken) string {
+	q, v := t.text[len(t.text)-1], t.text[1:len(t.text)-1]
+	if t.kind == tokString && s.p.Escapes {
+		var b strings.Builder
+		for i := 0; i < len(v); i++ {
+			switch c := v[i]; {
+			case c == '\\' && i+1 < len(v):
+				i++
+				switch c := v[i]; c {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				case 'r':
+					b.WriteByte('\r')
+				case '0':
+					b.WriteByte(0)
+				case '%', '_':
+					b.WriteByte('\\')
+					b.WriteByte(c)
+				default:
+					b.WriteByte(c)
+				}
+			case c == q && i+1 < len(v) && v[i+1] == q:
+				b.WriteByte(q)
+				i++
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return b.String()
+	}
+	return strings.ReplaceAll(v, string([]byte{q, q}), string(q))
+}
+
+// scan splits the statement into tokens.
+func (p *DDLParser) scan(stmt string) (*DDLScanner, error) {
+	s := &DDLScanner{p: p, stmt: stmt}
+	for i := 0; i < len(stmt); {
+		var (
+			c    = stmt[i]
+			kind ddlKind
+			end  int
+		)
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+			continue
+		case strings.HasPrefix(stmt[i:], "--") || c == '#' && p.HashComments:
+			if j := strings.IndexByte(stmt[i:], '\n'); j != -1 {
+				i += j + 1
+			} else {
+				i = len(stmt)
+			}
+			continue
+		case strings.HasPrefix(stmt[i:], "/*"):
+			j := strings.Index(stmt[i+2:], "*/")
+			if j == -1 {
+				return nil, s.Errorf("unterminated comment")
+			}
+			i += j + 4
+			continue
+		case c == '\'' || c == '"' && strings.IndexByte(p.Quotes, '"') == -1:
+			kind, end = tokString, p.quoted(stmt, i, c, true)
+		case strings.IndexByte(p.Quotes, c) != -1:
+			q := c
+			if c == '[' {
+				q = ']'
+			}
+			kind, end = tokIdent, p.quoted(stmt, i, q, false)
+		case isDigit(c) || c == '.' && i+1 < len(stmt) && isDigit(stmt[i+1]):
+			kind, end = tokNumber, i+1
+			for end < len(stmt) && (isDigit(stmt[end]) || stmt[end] == '.' || stmt[end] == 'e' || stmt[end] == 'E' ||
+				(stmt[end] == '+' || stmt[end] == '-') && (stmt[end-1] == 'e' || stmt[end-1] == 'E')) {
+				end++
+			}
+		case isWord(c, stmt[i:]):
+			kind, end = tokWord, i+1
+			for end < len(stmt) && (isWord(stmt[end], stmt[end:]) || isDigit(stmt[end]) || stmt[end] == '$') {
+				_, n := utf8.DecodeRuneInString(stmt[end:])
+				end += n
+			}
+		case strings.HasPrefix(stmt[i:], "::"):
+			kind, end = tokPunct, i+2
+		default:
+			_, n := utf8.DecodeRuneInString(stmt[i:])
+			kind, end = tokPunct, i+n
+		}
+		if end == -1 {
+			return nil, s.Errorf("unterminated quoted string or identifier")
+		}
+		s.toks = append(s.toks, ddlToken{kind: kind, text: stmt[i:end], start: i, end: end})
+		i = end
+	}
+	// Trim statement terminators.
+	for n := len(s.toks); n > 0 && s.toks[n-1].text == ";"; n-- {
+		s.toks = s.toks[:n-1]
+	}
+	return s, nil
+}
+
+// quoted returns the end position of the quoted token that
+// starts at position i and ends with q, or -1 if it is missing.
+func (p *DDLParser) quoted(stmt string, i int, q byte, str bool) int {
+	for j := i + 1; j < len(stmt); j++ {
+		switch {
+		case stmt[j] == '\\' && str && p.Escapes:
+			j++
+		case stmt[j] == q && j+1 < len(stmt) && stmt[j+1] == q:
+			j++
+		case stmt[j] == q:
+			return j + 1
+		}
+	}
+	return -1
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isWord(c byte, s string) bool {
+	if c < utf8.RuneSelf {
+		return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+	}
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsLetter(r)
+}
+
+// ddl holds the state of a parsed statement.
+type ddl struct {
+	*DDLParser
+	*DDLScanner
+	r *schema.Realm
+}
+
+// create parses the CREATE statements.
+func (d *ddl) create() (schema.Changes, error) {
+	switch {
+	case d.Accept("SCHEMA"), d.Accept("DATABASE"):
+		return d.createSchema()
+	case d.Accept("TABLE"):
+		return d.createTable()
+	}
+	idx := &schema.Index{}
+	// Index modifiers (e.g. UNIQUE).
+	for !d.Accept("INDEX") {
+		if d.Accept("UNIQUE") {
+			idx.Unique = true
+			continue
+		}
+		switch ok, err := d.ParseOption(d.DDLScanner, idx); {
+		case err != nil:
+			return nil, err
+		case !ok:
+			return nil, d.unsupported()
+		}
+	}
+	return d.createIndex(idx)
+}
+
+// createSchema parses the CREATE SCHEMA (or DATABASE) statement.
+func (d *ddl) createSchema() (schema.Changes, error) {
+	var extra []schema.Clause
+	if d.Accept("IF", "NOT", "EXISTS") {
+		extra = append(extra, &schema.IfNotExists{})
+	}
+	name, err := d.Ident()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := d.r.Schema(name); ok {
+		if len(extra) > 0 {
+			d.Rest()
+			return nil, nil
+		}
+		return nil, fmt.Errorf("schema %q already exists", name)
+	}
+	s := schema.New(name)
+	for !d.Done() {
+		d.Accept("DEFAULT")
+		switch {
+		case d.Accept("CHARACTER", "SET"), d.Accept("CHARSET"):
+			d.Accept("=")
+			v, err := d.Value()
+			if err != nil {
+				return nil, err
+			}
+			s.SetCharset(v)
+		case d.Accept("COLLATE"):
+			d.Accept("=")
+			v, err := d.Value()
+			if err != nil {
+				return nil, err
+			}
+			s.SetCollation(v)
+		default:
+			if err := d.option(s); err != nil {
+				return nil, err
+			}
+		}
+	}
+	d.addSchema(s)
+	return schema.Changes{&schema.AddSchema{S: s, Extra: extra}}, nil
+}
+
+// createTable parses the CREATE TABLE statement.
+func (d *ddl) createTable() (schema.Changes, error) {
+	var extra []schema.Clause
+	if d.Accept("IF", "NOT", "EXISTS") {
+		extra = append(extra, &schema.IfNotExists{})
+	}
+	s, name, err := d.tableName()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.Table(name); ok {
+		if len(extra) > 0 {
+			d.Rest()
+			return nil, nil
+		}
+		return nil, fmt.Errorf("table %q already exists", name)
+	}
+	if d.isAny("AS", "LIKE") {
+		return nil, d.Errorf("CREATE TABLE %s is not supported", strings.ToUpper(d.peek(0).text))
+	}
+	t := schema.NewTable(name)
+	t.Schema = s
+	if err := d.Expect("("); err != nil {
+		return nil, err
+	}
+	for {
+		if _, err := d.tableElem(t); err != nil {
+			return nil, err
+		}
+		if !d.Accept(",") {
+			break
+		}
+	}
+	if err := d.Expect(")"); err != nil {
+		return nil, err
+	}
+	for !d.Done() {
+		d.Accept(",")
+		if err := d.tableOption(t); err != nil {
+			return nil, err
+		}
+	}
+	d.finish(t)
+	s.AddTables(t)
+	return schema.Changes{&schema.AddTable{T: t, Extra: extra}}, nil
+}
+
+// tableOption parses an option that follows the table definition.
+func (d *ddl) tableOption(t *schema.Table) error {
+	d.Accept("DEFAULT")
+	switch {
+	case d.Accept("CHARACTER", "SET"), d.Accept("CHARSET"):
+		d.Accept("=")
+		v, err := d.Value()
+		if err != nil {
+			return err
+		}
+		t.SetCharset(v)
+	case d.Accept("COLLATE"):
+		d.Accept("=")
+		v, err := d.Value()
+		if err != nil {
+			return err
+		}
+		t.SetCollation(v)
+	case d.Accept("COMMENT"):
+		d.Accept("=")
+		v, err := d.Str()
+		if err != nil {
+			return err
+		}
+		t.SetComment(v)
+	default:
+		return d.option(t)
+	}
+	return nil
+}
+
+// tableElem parses a column or a constraint definition, adds
+// it to the table and returns the change it describes.
+func (d *ddl) tableElem(t *schema.Table) (schema.Change, error) {
+	var name string
+	if d.Accept("CONSTRAINT") && !d.isAny("PRIMARY", "UNIQUE", "FOREIGN", "CHECK") {
+		n, err := d.Ident()
+		if err != nil {
+			return nil, err
+		}
+		name = n
+	}
+	switch {
+	case d.Accept("PRIMARY", "KEY"):
+		pk := schema.NewPrimaryKey().SetName(name)
+		if err := d.index(t, pk); err != nil {
+			return nil, err
+		}
+		if err := d.setPK(t, pk); err != nil {
+			return nil, err
+		}
+		return &schema.AddIndex{I: pk}, nil
+	case d.Accept("UNIQUE"):
+		_ = d.Accept("INDEX") || d.Accept("KEY")
+		idx := schema.NewUniqueIndex(name)
+		if err := d.index(t, idx); err != nil {
+			return nil, err
+		}
+		t.AddIndexes(idx)
+		return &schema.AddIndex{I: idx}, nil
+	case d.Accept("FOREIGN", "KEY"):
+		// MySQL allows naming the index
+		// that backs the foreign key.
+		if !d.Is("(") {
+			n, err := d.Ident()
+			if err != nil {
+				return nil, err
+			}
+			if name == "" {
+				name = n
+			}
+		}
+		columns, err := d.columns(t)
+		if err != nil {
+			return nil, err
+		}
+		fk := schema.NewForeignKey(name).AddColumns(columns...)
+		if err := d.references(t, fk); err != nil {
+			return nil, err
+		}
+		t.AddForeignKeys(fk)
+		return &schema.AddForeignKey{F: fk}, nil
+	case d.Accept("CHECK"):
+		c, err := d.check(t, name)
+		if err != nil {
+			return nil, err
+		}
+		return &schema.AddCheck{C: c}, nil
+	case name != "":
+		return nil, d.Unexpected()
+	case d.Accept("INDEX"), d.Accept("KEY"), d.isAny("FULLTEXT", "SPATIAL"):
+		idx := schema.NewIndex("")
+		if d.isAny("FULLTEXT", "SPATIAL") {
+			if err := d.option(idx); err != nil {
+				return nil, err
+			}
+			_ = d.Accept("INDEX") || d.Accept("KEY")
+		}
+		if err := d.index(t, idx); err != nil {
+			return nil, err
+		}
+		t.AddIndexes(idx)
+		return &schema.AddIndex{I: idx}, nil
+	case d.Is("LIKE"):
+		return nil, d.Errorf("CREATE TABLE LIKE is not supported")
+	}
+	name, err := d.Ident()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := t.Column(name); ok {
+		return nil, fmt.Errorf("column %q already exists in table %q", name, t.Name)
+	}
+	c := schema.NewColumn(name)
+	t.AddColumns(c)
+	if err := d.column(t, c); err != nil {
+		return nil, err
+	}
+	if err := d.position(); err != nil {
+		return nil, err
+	}
+	return &schema.AddColumn{C: c}, nil
+}
+
+// column parses the type and the options of a column definition.
+func (d *ddl) column(t *schema.Table, c *schema.Column) error {
+	raw, err := d.typ()
+	if err != nil {
+		return err
+	}
+	ct, err := d.ParseType(raw)
+	if err != nil {
+		return fmt.Errorf("parse type %q of column %q: %w", raw, c.Name, err)
+	}
+	c.Type = &schema.ColumnType{Type: ct, Raw: raw, Null: true}
+	for !d.Done() && !d.Is(",") && !d.Is(")") && !d.isAny("FIRST", "AFTER") {
+		if err := d.columnOption(t, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// columnOption parses a column option (e.g. NOT NULL or DEFAULT) or an inline constraint.
+func (d *ddl) columnOption(t *schema.Table, c *schema.Column) error {
+	var name string
+	if d.Accept("CONSTRAINT") {
+		n, err := d.Ident()
+		if err != nil {
+			return err
+		}
+		name = n
+	}
+	switch {
+	case d.Accept("NOT", "NULL"):
+		c.Type.Null = false
+	case d.Accept("NULL"):
+		c.Type.Null = true
+	case d.Accept("DEFAULT", "NULL"):
+		c.Default = nil
+	case d.Accept("DEFAULT"):
+		x, err := d.Term()
+		if err != nil {
+			return err
+		}
+		c.Default = d.DefaultExpr(c, x)
+	case d.Accept("PRIMARY", "KEY"):
+		pk := schema.NewPrimaryKey().SetName(name)
+		part := schema.NewColumnPart(c)
+		if d.Accept("DESC") {
+			part.SetDesc(true)
+		} else {
+			d.Accept("ASC")
+		}
+		pk.AddParts(part)
+		return d.setPK(t, pk)
+	case d.Accept("UNIQUE"):
+		d.Accept("KEY")
+		t.AddIndexes(schema.NewUniqueIndex(name).AddColumns(c))
+	case d.Is("REFERENCES"):
+		fk := schema.NewForeignKey(name).AddColumns(c)
+		if err := d.references(t, fk); err != nil {
+			return err
+		}
+		t.AddForeignKeys(fk)
+	case d.Accept("CHECK"):
+		_, err := d.check(t, name)
+		return err
+	case name != "":
+		return d.Unexpected()
+	case d.Accept("COLLATE"):
+		v, err := d.Value()
+		if err != nil {
+			return err
+		}
+		c.SetCollation(v)
+	case d.Accept("CHARACTER", "SET"), d.Accept("CHARSET"):
+		v, err := d.Value()
+		if err != nil {
+			return err
+		}
+		c.SetCharset(v)
+	case d.Accept("COMMENT"):
+		v, err := d.Str()
+		if err != nil {
+			return err
+		}
+		c.SetComment(v)
+	case d.Is("GENERATED", "ALWAYS", "AS", "("), d.Is("AS", "("):
+		_ = d.Accept("GENERATED", "ALWAYS", "AS") || d.Accept("AS")
+		x, err := d.Paren()
+		if err != nil {
+			return err
+		}
+		g := &schema.GeneratedExpr{Expr: x}
+		switch {
+		case d.Accept("STORED"):
+			g.Type = "STORED"
+		case d.Accept("VIRTUAL"):
+			g.Type = "VIRTUAL"
+		}
+		c.SetGeneratedExpr(g)
+	default:
+		return d.option(c)
+	}
+	return nil
+}
+
+// index parses the name (if it is missing), the columns
+// and the options of an index defined in a table.
+func (d *ddl) index(t *schema.Table, idx *schema.Index) error {
+	if idx.Name == "" && !d.Is("(") && !d.Is("USING") {
+		name, err := d.Ident()
+		if err != nil {
+			return err
+		}
+		idx.Name = name
+	}
+	for !d.Is("(") {
+		if err := d.option(idx); err != nil {
+			return err
+		}
+	}
+	if err := d.parts(t, idx); err != nil {
+		return err
+	}
+	for !d.Done() && !d.Is(",") && !d.Is(")") {
+		if err := d.option(idx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createIndex parses the CREATE INDEX statement, after the INDEX keyword.
+func (d *ddl) createIndex(idx *schema.Index) (schema.Changes, error) {
+	var ifNotExists bool
+	for !d.Is("ON") && idx.Name == "" {
+		switch {
+		case d.Accept("IF", "NOT", "EXISTS"):
+			ifNotExists = true
+		case d.isAny("CONCURRENTLY", "USING"):
+			if err := d.option(idx); err != nil {
+				return nil, err
+			}
+		default:
+			name, err := d.Ident()
+			if err != nil {
+				return nil, err
+			}
+			idx.Name = name
+		}
+	}
+	for !d.Is("ON") {
+		if err := d.option(idx); err != nil {
+			return nil, err
+		}
+	}
+	d.Accept("ON")
+	d.Accept("ONLY")
+	t, err := d.table()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := t.Index(idx.Name); ok && idx.Name != "" {
+		if ifNotExists {
+			d.Rest()
+			return nil, nil
+		}
+		return nil, fmt.Errorf("index %q already exists in table %q", idx.Name, t.Name)
+	}
+	for !d.Is("(") {
+		if err := d.option(idx); err != nil {
+			return nil, err
+		}
+	}
+	if err := d.parts(t, idx); err != nil {
+		return nil, err
+	}
+	for !d.Done() {
+		if err := d.option(idx); err != nil {
+			return nil, err
+		}
+	}
+	t.AddIndexes(idx)
+	d.finish(t)
+	return schema.Changes{&schema.ModifyTable{T: t, Changes: []schema.Change{&schema.AddIndex{I: idx}}}}, nil
+}
+
+// parts parses the parts (columns or expressions) of an index.
+func (d *ddl) parts(t *schema.Table, idx *schema.Index) error {
+	if err := d.Expect("("); err != nil {
+		return err
+	}
+	for {
+		part := schema.NewIndexPart()
+		switch t1, t2, t3, t4 := d.peek(0), d.peek(1), d.peek(2), d.peek(3); {
+		case d.Is("("):
+			x, err := d.Paren()
+			if err != nil {
+				return err
+			}
+			part.SetExpr(&schema.RawExpr{X: x})
+		// Function calls. A column that is followed by a number in
+		// parentheses is an index prefix (e.g. "name(10)" in MySQL).
+		case t1 != nil && t1.kind == tokWord && t2 != nil && t2.text == "(" && (t3 == nil || t3.kind != tokNumber || t4 == nil || t4.text != ")"):
+			x, err := d.Term()
+			if err != nil {
+				return err
+			}
+			part.SetExpr(&schema.RawExpr{X: x})
+		default:
+			name, err := d.Ident()
+			if err != nil {
+				return err
+			}
+			c, ok := t.Column(name)
+			if !ok {
+				return fmt.Errorf("column %q was not found in table %q", name, t.Name)
+			}
+			part.SetColumn(c)
+		}
+		for !d.Is(",") && !d.Is(")") {
+			switch {
+			case d.Accept("ASC"):
+			case d.Accept("DESC"):
+				part.SetDesc(true)
+			default:
+				if err := d.option(part); err != nil {
+					return err
+				}
+			}
+		}
+		idx.AddParts(part)
+		if !d.Accept(",") {
+			break
+		}
+	}
+	return d.Expect(")")
+}
+
+// columns parses a list of columns of the given table.
+func (d *ddl) columns(t *schema.Table) ([]*schema.Column, error) {
+	if err := d.Expect("("); err != nil {
+		return nil, err
+	}
+	var columns []*schema.Column
+	for {
+		name, err := d.Ident()
+		if err != nil {
+			return nil, err
+		}
+		c, ok := t.Column(name)
+		if !ok {
+			return nil, fmt.Errorf("column %q was not found in table %q", name, t.Name)
+		}
+		columns = append(columns, c)
+		if !d.Accept(",") {
+			break
+		}
+	}
+	return columns, d.Expect(")")
+}
+
+// references parses the REFERENCES clause of a foreign key.
+func (d *ddl) references(t *schema.Table, fk *schema.ForeignKey) error {
+	if err := d.Expect("REFERENCES"); err != nil {
+		return err
+	}
+	s, name, err := d.tableName()
+	if err != nil {
+		return err
+	}
+	ref, ok := s.Table(name)
+	if !ok && s == t.Schema && name == t.Name {
+		ref, ok = t, true
+	}
+	if !ok {
+		return fmt.Errorf("referenced table %q was not found", name)
+	}
+	fk.SetRefTable(ref)
+	if d.Is("(") {
+		columns, err := d.columns(ref)
+		if err != nil {
+			return err
+		}
+		fk.AddRefColumns(columns...)
+	} else {
+		if ref.PrimaryKey == nil {
+			return fmt.Errorf("referenced table %q has no primary key", ref.Name)
+		}
+		for _, p := range ref.PrimaryKey.Parts {
+			fk.AddRefColumns(p.C)
+		}
+	}
+	if len(fk.Columns) != len(fk.RefColumns) {
+		return fmt.Errorf("mismatched number of columns in foreign key of table %q", t.Name)
+	}
+	for {
+		switch {
+		case d.Accept("ON", "DELETE"):
+			a, err := d.action()
+			if err != nil {
+				return err
+			}
+			fk.SetOnDelete(a)
+		case d.Accept("ON", "UPDATE"):
+			a, err := d.action()
+			if err != nil {
+				return err
+			}
+			fk.SetOnUpdate(a)
+		case d.Accept("MATCH"):
+			if _, err := d.Ident(); err != nil {
+				return err
+			}
+		default:
+			ok, err := d.ParseOption(d.DDLScanner, fk)
+			if err != nil || !ok {
+				return err
+			}
+		}
+	}
+}
+
+// action parses a referential action of a foreign key.
+func (d *ddl) action() (schema.ReferenceOption, error) {
+	switch {
+	case d.Accept("CASCADE"):
+		return schema.Cascade, nil
+	case d.Accept("RESTRICT"):
+		return schema.Restrict, nil
+	case d.Accept("NO", "ACTION"):
+		return schema.NoAction, nil
+	case d.Accept("SET", "NULL"):
+		return schema.SetNull, nil
+	case d.Accept("SET", "DEFAULT"):
+		return schema.SetDefault, nil
+	default:
+		return "", d.Unexpected()
+	}
+}
+
+// check parses a CHECK constraint, and adds it to the table.
+func (d *ddl) check(t *schema.Table, name string) (*schema.Check, error) {
+	x, err := d.Paren()
+	if err != nil {
+		return nil, err
+	}
+	c := schema.NewCheck().SetName(name).SetExpr(x)
+	for {
+		ok, err := d.ParseOption(d.DDLScanner, c)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+	}
+	t.AddChecks(c)
+	return c, nil
+}
+
+// alterTable parses the ALTER TABLE statement.
+func (d *ddl) alterTable() (schema.Changes, error) {
+	d.Accept("IF", "EXISTS")
+	d.Accept("ONLY")
+	t, err := d.table()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		m       = &schema.ModifyTable{T: t}
+		changes = schema.Changes{m}
+	)
+	for {
+		c, err := d.alterAction(t)
+		if err != nil {
+			return nil, err
+		}
+		switch c := c.(type) {
+		case nil:
+		case *schema.RenameTable:
+			changes = append(changes, c)
+		default:
+			m.Changes = append(m.Changes, c)
+		}
+		if !d.Accept(",") {
+			break
+		}
+	}
+	d.finish(t)
+	if len(m.Changes) == 0 {
+		changes = changes[1:]
+	}
+	return changes, nil
+}
+
+// alterAction parses a single action of the ALTER TABLE statement.
+func (d *ddl) alterAction(t *schema.Table) (schema.Change, error) {
+	switch {
+	case d.Accept("ADD", "COLUMN"):
+		if d.Accept("IF", "NOT", "EXISTS") {
+			pos := d.pos
+			name, err := d.Ident()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := t.Column(name); ok {
+				return nil, d.skipExpr()
+			}
+			d.pos = pos
+		}
+		if d.isAny("CONSTRAINT", "PRIMARY", "UNIQUE", "FOREIGN", "CHECK", "INDEX", "KEY") {
+			return nil, d.Unexpected()
+		}
+		return d.tableElem(t)
+	case d.Accept("ADD"):
+		return d.tableElem(t)
+	case d.Accept("DROP"):
+		c, err := d.dropElem(t)
+		if err != nil {
+			return nil, err
+		}
+		_ = d.Accept("CASCADE") || d.Accept("RESTRICT")
+		return c, nil
+	case d.Accept("RENAME"):
+		return d.rename(t)
+	case d.Accept("ALTER"):
+		return d.alterColumn(t)
+	case d.Accept("MODIFY"):
+		d.Accept("COLUMN")
+		c, err := d.tableColumn(t)
+		if err != nil {
+			return nil, err
+		}
+		return d.redefine(t, c, c.Name)
+	case d.Accept("CHANGE"):
+		d.Accept("COLUMN")
+		c, err := d.tableColumn(t)
+		if err != nil {
+			return nil, err
+		}
+		name, err := d.Ident()
+		if err != nil {
+			return nil, err
+		}
+		return d.redefine(t, c, name)
+	default:
+		from := comments(t.Attrs)
+		if err := d.tableOption(t); err != nil {
+			return nil, err
+		}
+		// Table options are not reported as changes,
+		// except for comments that are commonly diffed.
+		return CommentDiff(from, t.Attrs), nil
+	}
+}
+
+// dropElem parses the DROP action of the ALTER TABLE statement.
+func (d *ddl) dropElem(t *schema.Table) (schema.Change, error) {
+	switch {
+	case d.Accept("PRIMARY", "KEY"):
+		pk := t.PrimaryKey
+		if pk == nil {
+			return nil, fmt.Errorf("table %q has no primary key", t.Name)
+		}
+		removeIndex(t, pk)
+		return &schema.DropIndex{I: pk}, nil
+	case d.Accept("FOREIGN", "KEY"):
+		name, err := d.Ident()
+		if err != nil {
+			return nil, err
+		}
+		fk, ok := t.ForeignKey(name)
+		if !ok {
+			return nil, fmt.Errorf("foreign key %q was not found in table %q", name, t.Name)
+		}
+		removeForeignKey(t, fk)
+		return &schema.DropForeignKey{F: fk}, nil
+	case d.Accept("INDEX"), d.Accept("KEY"):
+		name, err := d.Ident()
+		if err != nil {
+			return nil, err
+		}
+		idx, ok := t.Index(name)
+		if !ok {
+			return nil, fmt.Errorf("index %q was not found in table %q", name, t.Name)
+		}
+		removeIndex(t, idx)
+		return &schema.DropIndex{I: idx}, nil
+	case d.Accept("CHECK"), d.Accept("CONSTRAINT"):
+		ifExists := d.Accept("IF", "EXISTS")
+		name, err := d.Ident()
+		if err != nil {
+			return nil, err
+		}
+		for i, a := range t.Attrs {
+			if c, ok := a.(*schema.Check); ok && c.Name == name {
+				t.Attrs = append(t.Attrs[:i], t.Attrs[i+1:]...)
+				return &schema.DropCheck{C: c}, nil
+			}
+		}
+		if fk, ok := t.ForeignKey(name); ok {
+			removeForeignKey(t, fk)
+			return &schema.DropForeignKey{F: fk}, nil
+		}
+		if idx, ok := t.Index(name); ok {
+			removeIndex(t, idx)
+			return &schema.DropIndex{I: idx}, nil
+		}
+		if pk := t.PrimaryKey; pk != nil && pk.Name == name {
+			removeIndex(t, pk)
+			return &schema.DropIndex{I: pk}, nil
+		}
+		if ifExists {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("constraint %q was not found in table %q", name, t.Name)
+	}
+	d.Accept("COLUMN")
+	ifExists := d.Accept("IF", "EXISTS")
+	name, err := d.Ident()
+	if err != nil {
+		return nil, err
+	}
+	c, ok := t.Column(name)
+	if !ok {
+		if ifExists {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("column %q was not found in table %q", name, t.Name)
+	}
+	removeColumn(t, c)
+	return &schema.DropColumn{C: c}, nil
+}
+
+// rename parses the RENAME action of the ALTER TABLE statement.
+func (d *ddl) rename(t *schema.Table) (schema.Change, error) {
+	switch {
+	case d.Accept("INDEX"), d.Accept("KEY"):
+		name, err := d.Ident()
+		if err != nil {
+			return nil, err
+		}
+		idx, ok := t.Index(name)
+		if !ok {
+			return nil, fmt.Errorf("index %q was not found in table %q", name, t.Name)
+		}
+		if err := d.Expect("TO"); err != nil {
+			return nil, err
+		}
+		from := *idx
+		if idx.Name, err = d.Ident(); err != nil {
+			return nil, err
+		}
+		return &schema.RenameIndex{From: &from, To: idx}, nil
+	case d.Accept("COLUMN"), !d.isAny("TO", "AS") && d.peek(1) != nil && strings.EqualFold(d.peek(1).text, "TO"):
+		c, err := d.tableColumn(t)
+		if err != nil {
+			return nil, err
+		}
+		if err := d.Expect("TO"); err != nil {
+			return nil, err
+		}
+		name, err := d.Ident()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := t.Column(name); ok {
+			return nil, fmt.Errorf("column %q already exists in table %q", name, t.Name)
+		}
+		from := copyColumn(c)
+		c.Name = name
+		return &schema.RenameColumn{From: from, To: c}, nil
+	}
+	_ = d.Accept("TO") || d.Accept("AS")
+	return d.renameTable(t)
+}
+
+// renameTables parses the RENAME TABLE statement.
+func (d *ddl) renameTables() (schema.Changes, error) {
+	var changes schema.Changes
+	for {
+		t, err := d.table()
+		if err != nil {
+			return nil, err
+		}
+		if err := d.Expect("TO"); err != nil {
+			return nil, err
+		}
+		c, err := d.renameTable(t)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+		if !d.Accept(",") {
+			return changes, nil
+		}
+	}
+}
+
+// renameTable parses the new name of the given table, and renames it.
+func (d *ddl) renameTable(t *schema.Table) (schema.Change, error) {
+	s, name, err := d.tableName()
+	if err != nil {
+		return nil, err
+	}
+	if s != t.Schema {
+		return nil, d.Errorf("moving tables between schemas is not supported")
+	}
+	if _, ok := s.Table(name); ok {
+		return nil, fmt.Errorf("table %q already exists", name)
+	}
+	from := *t
+	t.Name = name
+	return &schema.RenameTable{From: &from, To: t}, nil
+}
+
+// alterColumn parses the ALTER COLUMN action of the ALTER TABLE statement.
+func (d *ddl) alterColumn(t *schema.Table) (schema.Change, error) {
+	d.Accept("COLUMN")
+	c, err := d.tableColumn(t)
+	if err != nil {
+		return nil, err
+	}
+	from := copyColumn(c)
+	switch {
+	case d.Accept("SET", "DEFAULT"):
+		x, err := d.Term()
+		if err != nil {
+			return nil, err
+		}
+		c.Default = d.DefaultExpr(c, x)
+	case d.Accept("DROP", "DEFAULT"):
+		c.Default = nil
+	case d.Accept("SET", "NOT", "NULL"):
+		c.Type.Null = false
+	case d.Accept("DROP", "NOT", "NULL"):
+		c.Type.Null = true
+	case d.Accept("SET", "DATA", "TYPE"), d.Accept("TYPE"):
+		raw, err := d.typ()
+		if err != nil {
+			return nil, err
+		}
+		ct, err := d.ParseType(raw)
+		if err != nil {
+			return nil, fmt.Errorf("parse type %q of column %q: %w", raw, c.Name, err)
+		}
+		c.Type.Type, c.Type.Raw = ct, raw
+		if d.Accept("COLLATE") {
+			v, err := d.Value()
+			if err != nil {
+				return nil, err
+			}
+			c.SetCollation(v)
+		}
+		if d.Accept("USING") {
+			if err := d.skipExpr(); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		if err := d.option(c); err != nil {
+			return nil, err
+		}
+	}
+	return modifyColumn(from, c), nil
+}
+
+// position parses the optional position of a column
+// definition (i.e. FIRST or AFTER). Positions are not tracked.
+func (d *ddl) position() error {
+	switch {
+	case d.Accept("FIRST"):
+	case d.Accept("AFTER"):
+		if _, err := d.Ident(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redefine parses a new definition for an existing column (e.g. MODIFY COLUMN).
+func (d *ddl) redefine(t *schema.Table, c *schema.Column, name string) (schema.Change, error) {
+	if name != c.Name {
+		if _, ok := t.Column(name); ok {
+			return nil, fmt.Errorf("column %q already exists in table %q", name, t.Name)
+		}
+	}
+	from := copyColumn(c)
+	c.Name, c.Default, c.Attrs = name, nil, nil
+	if err := d.column(t, c); err != nil {
+		return nil, err
+	}
+	if err := d.position(); err != nil {
+		return nil, err
+	}
+	if name != from.Name {
+		return &schema.RenameColumn{From: from, To: c}, nil
+	}
+	return modifyColumn(from, c), nil
+}
+
+// use parses the USE statement, and sets the schema
+// unqualified names are resolved in by the next statements.
+func (d *ddl) use() error {
+	name, err := d.Ident()
+	if err != nil {
+		return err
+	}
+	d.Schema = name
+	return nil
+}
+
+// drop parses the DROP statements.
+func (d *ddl) drop() (schema.Changes, error) {
+	switch {
+	case d.Accept("TABLE"):
+		var (
+			changes schema.Changes
+			extra   []schema.Clause
+		)
+		if d.Accept("IF", "EXISTS") {
+			extra = append(extra, &schema.IfExists{})
+		}
+		for {
+			s, name, err := d.tableName()
+			if err != nil {
+				return nil, err
+			}
+			switch t, ok := s.Table(name); {
+			case ok:
+				removeTable(t)
+				changes = append(changes, &schema.DropTable{T: t, Extra: extra})
+			case len(extra) == 0:
+				return nil, fmt.Errorf("table %q was not found", name)
+			}
+			if !d.Accept(",") {
+				break
+			}
+		}
+		_ = d.Accept("CASCADE") || d.Accept("RESTRICT")
+		return changes, nil
+	case d.Accept("INDEX"):
+		d.Accept("CONCURRENTLY")
+		ifExists := d.Accept("IF", "EXISTS")
+		s, name, err := d.tableName()
+		if err != nil {
+			return nil, err
+		}
+		var tables []*schema.Table
+		if d.Accept("ON") {
+			t, err := d.table()
+			if err != nil {
+				return nil, err
+			}
+			tables = append(tables, t)
+		} else {
+			tables = s.Tables
+		}
+		_ = d.Accept("CASCADE") || d.Accept("RESTRICT")
+		for _, t := range tables {
+			if idx, ok := t.Index(name); ok {
+				removeIndex(t, idx)
+				return schema.Changes{&schema.ModifyTable{T: t, Changes: []schema.Change{&schema.DropIndex{I: idx}}}}, nil
+			}
+		}
+		if ifExists {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("index %q was not found", name)
+	case d.Accept("SCHEMA"), d.Accept("DATABASE"):
+		var extra []schema.Clause
+		if d.Accept("IF", "EXISTS") {
+			extra = append(extra, &schema.IfExists{})
+		}
+		name, err := d.Ident()
+		if err != nil {
+			return nil, err
+		}
+		_ = d.Accept("CASCADE") || d.Accept("RESTRICT")
+		s, ok := d.r.Schema(name)
+		if !ok {
+			if len(extra) > 0 {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("schema %q was not found", name)
+		}
+		for i := range d.r.Schemas {
+			if d.r.Schemas[i] == s {
+				d.r.Schemas = append(d.r.Schemas[:i], d.r.Schemas[i+1:]...)
+				break
+			}
+		}
+		return schema.Changes{&schema.DropSchema{S: s, Extra: extra}}, nil
+	default:
+		return nil, d.unsupported()
+	}
+}
+
+// comment parses the COMMENT ON statement.
+func (d *ddl) comment() (schema.Changes, error) {
+	switch {
+	case d.Accept("TABLE"):
+		t, err := d.table()
+		if err != nil {
+			return nil, err
+		}
+		from := comments(t.Attrs)
+		if err := d.commentIs(&t.Attrs); err != nil {
+			return nil, err
+		}
+		c := CommentDiff(from, t.Attrs)
+		if c == nil {
+			return nil, nil
+		}
+		return schema.Changes{&schema.ModifyTable{T: t, Changes: []schema.Change{c}}}, nil
+	case d.Accept("COLUMN"):
+		var names []string
+		for {
+			name, err := d.Ident()
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, name)
+			if !d.Accept(".") {
+				break
+			}
+		}
+		var s *schema.Schema
+		switch len(names) {
+		case 2:
+			s = d.defaultSchema()
+		case 3:
+			var ok bool
+			if s, ok = d.r.Schema(names[0]); !ok {
+				return nil, fmt.Errorf("schema %q was not found", names[0])
+			}
+			names = names[1:]
+		default:
+			return nil, d.Errorf("unexpected column name %q", strings.Join(names, "."))
+		}
+		t, ok := s.Table(names[0])
+		if !ok {
+			return nil, fmt.Errorf("table %q was not found", names[0])
+		}
+		c, ok := t.Column(names[1])
+		if !ok {
+			return nil, fmt.Errorf("column %q was not found in table %q", names[1], t.Name)
+		}
+		from := copyColumn(c)
+		if err := d.commentIs(&c.Attrs); err != nil {
+			return nil, err
+		}
+		m := modifyColumn(from, c)
+		if m == nil {
+			return nil, nil
+		}
+		return schema.Changes{&schema.ModifyTable{T: t, Changes: []schema.Change{m}}}, nil
+	default:
+		return nil, d.unsupported()
+	}
+}
+
+// commentIs parses the IS clause of the COMMENT ON statement.
+func (d *ddl) commentIs(attrs *[]schema.Attr) error {
+	if err := d.Expect("IS"); err != nil {
+		return err
+	}
+	var v string
+	if !d.Accept("NULL") {
+		s, err := d.Str()
+		if err != nil {
+			return err
+		}
+		v = s
+	}
+	for i, a := range *attrs {
+		if _, ok := a.(*schema.Comment); ok {
+			(*attrs)[i] = &schema.Comment{Text: v}
+			return nil
+		}
+	}
+	*attrs = append(*attrs, &schema.Comment{Text: v})
+	return nil
+}
+
+// option parses a dialect-specific option of the given element,
+// and returns an error in case the option was not handled.
+func (d *ddl) option(v interface{}) error {
+	if d.Done() {
+		return d.Unexpected()
+	}
+	switch ok, err := d.ParseOption(d.DDLScanner, v); {
+	case err != nil:
+		return err
+	case !ok:
+		return d.Unexpected()
+	}
+	return nil
+}
+
+// tableName parses a (possibly qualified) table name, and returns its schema.
+func (d *ddl) tableName() (*schema.Schema, string, error) {
+	name, err := d.Ident()
+	if err != nil {
+		return nil, "", err
+	}
+	if !d.Accept(".") {
+		return d.defaultSchema(), name, nil
+	}
+	s, ok := d.r.Schema(name)
+	if !ok {
+		return nil, "", fmt.Errorf("schema %q was not found", name)
+	}
+	if name, err = d.Ident(); err != nil {
+		return nil, "", err
+	}
+	return s, name, nil
+}
+
+// table parses a table name, and returns the table.
+func (d *ddl) table() (*schema.Table, error) {
+	s, name, err := d.tableName()
+	if err != nil {
+		return nil, err
+	}
+	t, ok := s.Table(name)
+	if !ok {
+		return nil, fmt.Errorf("table %q was not found", name)
+	}
+	return t, nil
+}
+
+// tableColumn parses a column name, and returns the column.
+func (d *ddl) tableColumn(t *schema.Table) (*schema.Column, error) {
+	name, err := d.Ident()
+	if err != nil {
+		return nil, err
+	}
+	c, ok := t.Column(name)
+	if !ok {
+		return nil, fmt.Errorf("column %q was not found in table %q", name, t.Name)
+	}
+	return c, nil
+}
+
+// defaultSchema returns the schema of unqualified names,
+// and adds it to the realm in case it does not exist.
+func (d *ddl) defaultSchema() *schema.Schema {
+	if s, ok := d.r.Schema(d.Schema); ok {
+		return s
+	}
+	s := schema.New(d.Schema)
+	d.addSchema(s)
+	return s
+}
+
+func (d *ddl) addSchema(s *schema.Schema) {
+	s.SetRealm(d.r)
+	d.r.Schemas = append(d.r.Schemas, s)
+}
+
+// setPK sets the primary key of the table.
+func (d *ddl) setPK(t *schema.Table, pk *schema.Index) error {
+	if t.PrimaryKey != nil {
+		return fmt.Errorf("multiple primary keys for table %q are not allowed", t.Name)
+	}
+	t.SetPrimaryKey(pk)
+	return nil
+}
+
+// finish sets the names of the unnamed table elements,
+// and the nullability of its primary-key columns.
+func (d *ddl) finish(t *schema.Table) {
+	if pk := t.PrimaryKey; pk != nil {
+		if pk.Name == "" {
+			pk.Name = d.DefaultName(t, pk)
+		}
+		for _, p := range pk.Parts {
+			if p.C != nil && !d.NullPK {
+				p.C.Type.Null = false
+			}
+		}
+	}
+	for _, idx := range t.Indexes {
+		if idx.Name == "" {
+			idx.Name = d.DefaultName(t, idx)
+		}
+	}
+	for _, fk := range t.ForeignKeys {
+		if fk.Symbol == "" {
+			fk.Symbol = d.DefaultName(t, fk)
+		}
+	}
+	for _, a := range t.Attrs {
+		if c, ok := a.(*schema.Check); ok && c.Name == "" {
+			c.Name = d.DefaultName(t, c)
+		}
+	}
+}
+
+// copyColumn returns a copy of the column, that can
+// be used as the "from" state of a column change.
+func copyColumn(c *schema.Column) *schema.Column {
+	from := *c
+	if c.Type != nil {
+		ct := *c.Type
+		from.Type = &ct
+	}
+	from.Attrs = append([]schema.Attr(nil), c.Attrs...)
+	return &from
+}
+
+// modifyColumn returns the change between the two column
+// states, or nil in case the column was not changed.
+func modifyColumn(from, to *schema.Column) schema.Change {
+	var k schema.ChangeKind
+	if from.Type.Raw != to.Type.Raw {
+		k |= schema.ChangeType
+	}
+	if from.Type.Null != to.Type.Null {
+		k |= schema.ChangeNull
+	}
+	x1, ok1 := DefaultValue(from)
+	x2, ok2 := DefaultValue(to)
+	if ok1 != ok2 || x1 != x2 {
+		k |= schema.ChangeDefault
+	}
+	k |= CommentChange(from.Attrs, to.Attrs)
+	if k == schema.NoChange {
+		return nil
+	}
+	return &schema.ModifyColumn{From: from, To: to, Change: k}
+}
+
+// removeTable removes the table from its schema.
+func removeTable(t *schema.Table) {
+	s := t.Schema
+	for i := range s.Tables {
+		if s.Tables[i] == t {
+			s.Tables = append(s.Tables[:i], s.Tables[i+1:]...)
+			return
+		}
+	}
+}
+
+// removeIndex removes the index (or the primary key) from the table.
+func removeIndex(t *schema.Table, idx *schema.Index) {
+	if t.PrimaryKey == idx {
+		t.PrimaryKey = nil
+	}
+	for i := range t.Indexes {
+		if t.Indexes[i] == idx {
+			t.Indexes = append(t.Indexes[:i], t.Indexes[i+1:]...)
+			break
+		}
+	}
+	for _, p := range idx.Parts {
+		if p.C == nil {
+			continue
+		}
+		for i := range p.C.Indexes {
+			if p.C.Indexes[i] == idx {
+				p.C.Indexes = append(p.C.Indexes[:i], p.C.Indexes[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// removeForeignKey removes the foreign key from the table.
+func removeForeignKey(t *schema.Table, fk *schema.ForeignKey) {
+	for i := range t.ForeignKeys {
+		if t.ForeignKeys[i] == fk {
+			t.ForeignKeys = append(t.ForeignKeys[:i], t.ForeignKeys[i+1:]...)
+			break
+		}
+	}
+	for _, c := range fk.Columns {
+		for i := range c.ForeignKeys {
+			if c.ForeignKeys[i] == fk {
+				c.ForeignKeys = append(c.ForeignKeys[:i], c.ForeignKeys[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// removeColumn removes the column from the table. Like most databases, the
+// column is removed from its indexes, and indexes and foreign keys that are
+// left without columns are dropped.
+func removeColumn(t *schema.Table, c *schema.Column) {
+	for i := range t.Columns {
+		if t.Columns[i] == c {
+			t.Columns = append(t.Columns[:i], t.Columns[i+1:]...)
+			break
+		}
+	}
+	for _, idx := range append([]*schema.Index{t.PrimaryKey}, t.Indexes...) {
+		if idx == nil {
+			continue
+		}
+		parts := idx.Parts[:0]
+		for _, p := range idx.Parts {
+			if p.C != c {
+				parts = append(parts, p)
+			}
+		}
+		if idx.Parts = parts; len(parts) == 0 {
+			removeIndex(t, idx)
+		}
+	}
+	for _, fk := range c.ForeignKeys {
+		removeForeignKey(t, fk)
+	}
+}
+
+// comments returns the comment attribute of the given attributes, if it exists.
+func comments(attrs []schema.Attr) []schema.Attr {
+	var c schema.Comment
+	if Has(attrs, &c) {
+		return []schema.Attr{&c}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockDDL struct{}
+
+func (mockDDL) ParseType(t string) (schema.Type, error) {
+	return &schema.UnsupportedType{T: t}, nil
+}
+
+func (mockDDL) DefaultExpr(_ *schema.Column, x string) schema.Expr {
+	return &schema.RawExpr{X: x}
+}
+
+func (mockDDL) ParseOption(s *DDLScanner, v interface{}) (bool, error) {
+	if _, ok := v.(*schema.Column); ok && s.Accept("AUTO_INCREMENT") {
+		return true, nil
+	}
+	return false, nil
+}
+
+func (mockDDL) DefaultName(t *schema.Table, v interface{}) string {
+	switch v.(type) {
+	case *schema.Index:
+		return t.Name + "_idx"
+	case *schema.ForeignKey:
+		return t.Name + "_fk"
+	case *schema.Check:
+		return t.Name + "_chk"
+	}
+	return ""
+}
+
+func TestDDLParser_ParseDDL(t *testing.T) {
+	var (
+		r = &schema.Realm{}
+		p = &DDLParser{DDLDriver: mockDDL{}, Schema: "public", Quotes: `"`}
+	)
+	changes, err := p.ParseDDL(r, `-- create users.
+CREATE TABLE "users" (
+  id int AUTO_INCREMENT,
+  name varchar(255) NOT NULL DEFAULT 'a8m' COMMENT 'user name',
+  age int CHECK (age > 0),
+  PRIMARY KEY (id),
+  UNIQUE (name)
+)`)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.IsType(t, &schema.AddTable{}, changes[0])
+	require.Len(t, r.Schemas, 1)
+	require.Equal(t, "public", r.Schemas[0].Name)
+	users, ok := r.Schemas[0].Table("users")
+	require.True(t, ok)
+	require.Len(t, users.Columns, 3)
+	require.False(t, users.Columns[0].Type.Null, "primary-key columns are not null")
+	require.Equal(t, &schema.UnsupportedType{T: "varchar(255)"}, users.Columns[1].Type.Type)
+	require.Equal(t, &schema.RawExpr{X: "'a8m'"}, users.Columns[1].Default)
+	require.Equal(t, []schema.Attr{&schema.Comment{Text: "user name"}}, users.Columns[1].Attrs)
+	require.Equal(t, "users_idx", users.Indexes[0].Name)
+	require.True(t, users.Indexes[0].Unique)
+	require.Equal(t, []schema.Attr{&schema.Check{Name: "users_chk", Expr: "age > 0"}}, users.Attrs)
+
+	changes, err = p.ParseDDL(r, `CREATE TABLE posts (id int PRIMARY KEY, author_id int REFERENCES users (id) ON DELETE CASCADE)`)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	posts, ok := r.Schemas[0].Table("posts")
+	require.True(t, ok)
+	require.Len(t, posts.ForeignKeys, 1)
+	require.Equal(t, "posts_fk", posts.ForeignKeys[0].Symbol)
+	require.Equal(t, users, posts.ForeignKeys[0].RefTable)
+	require.Equal(t, schema.Cascade, posts.ForeignKeys[0].OnDelete)
+
+	changes, err = p.ParseDDL(r, `ALTER TABLE users ADD COLUMN email text NULL, DROP COLUMN age`)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	modify, ok := changes[0].(*schema.ModifyTable)
+	require.True(t, ok)
+	require.Len(t, modify.Changes, 2)
+	require.IsType(t, &schema.AddColumn{}, modify.Changes[0])
+	require.IsType(t, &schema.DropColumn{}, modify.Changes[1])
+	_, ok = users.Column("email")
+	require.True(t, ok)
+	_, ok = users.Column("age")
+	require.False(t, ok)
+
+	changes, err = p.ParseDDL(r, `CREATE INDEX "posts_author" ON posts (author_id)`)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	_, ok = posts.Index("posts_author")
+	require.True(t, ok)
+
+	changes, err = p.ParseDDL(r, `DROP INDEX posts_author`)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	_, ok = posts.Index("posts_author")
+	require.False(t, ok)
+
+	changes, err = p.ParseDDL(r, `INSERT INTO users (id) VALUES (1)`)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	changes, err = p.ParseDDL(r, `DROP TABLE posts`)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	_, ok = r.Schemas[0].Table("posts")
+	require.False(t, ok)
+}
+
+func TestDDLParser_Errors(t *testing.T) {
+	p := &DDLParser{DDLDriver: mockDDL{}, Schema: "public", Quotes: `"`}
+	for stmt, reason := range map[string]string{
+		"CREATE VIEW v AS SELECT 1":            "CREATE VIEW is not supported",
+		"CREATE TABLE t (id int NULL UNKNOWN)": `unexpected "UNKNOWN"`,
+		"CREATE TABLE t (id int":               "unexpected end of statement",
+		"ALTER TABLE t ADD COLUMN id int":      `table "t" was not found`,
+		"CREATE TABLE t (name text 'unterm)":   "unterminated quoted string",
+	} {
+		_, err := p.ParseDDL(&schema.Realm{}, stmt)
+		require.Error(t, err, stmt)
+		require.Contains(t, err.Error(), reason, stmt)
+	}
+	_, err := p.ParseDDL(&schema.Realm{}, "CREATE VIEW v AS SELECT 1")
+	require.ErrorAs(t, err, &migrate.UnsupportedStmtError{})
+}
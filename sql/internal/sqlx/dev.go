@@ -54,6 +54,9 @@ func (d *DevDriver) NormalizeRealm(ctx context.Context, r *schema.Realm) (nr *sc
 		st := schema.New(dev).AddAttrs(s.Attrs...)
 		changes = append(changes, &schema.AddSchema{S: st})
 		reverse = append(reverse, &schema.DropSchema{S: st, Extra: append(d.DropClause, &schema.IfExists{})})
+		for _, o := range s.Objects {
+			changes = append(changes, &schema.AddObject{O: o})
+		}
 		for _, t := range s.Tables {
 			// If objects are not strongly connected.
 			if t.Schema != s {
@@ -85,6 +88,9 @@ func (d *DevDriver) NormalizeRealm(ctx context.Context, r *schema.Realm) (nr *sc
 		return nil, err
 	}
 	patch(nr)
+	// Realm-level objects (e.g. roles) are not created
+	// in the dev database, and are returned as-is.
+	nr.Objects = append(nr.Objects, r.Objects...)
 	return nr, nil
 }
 
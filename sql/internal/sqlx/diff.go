@@ -66,20 +66,61 @@ type (
 	Normalizer interface {
 		Normalize(from, to *schema.Table) error
 	}
+
+	// A ForeignKeyAttrChanger wraps the ForeignKeyAttrChanged method for reporting
+	// if the driver-specific attributes of a foreign key were changed. For example,
+	// a foreign key that was not validated yet (NOT VALID in PostgreSQL).
+	//
+	// If the DiffDriver implements the ForeignKeyAttrChanger interface, foreign keys
+	// with changed attributes are reported as modified with the ChangeAttr kind.
+	ForeignKeyAttrChanger interface {
+		ForeignKeyAttrChanged(from, to []schema.Attr) bool
+	}
+
+	// A SchemaObjectDiffer wraps the SchemaObjectDiff method for diffing the objects
+	// of two schemas (e.g. sequences). Unlike tables, objects are defined by the drivers
+	// and therefore, their diff logic is driver-specific.
+	//
+	// If the DiffDriver implements the SchemaObjectDiffer interface, SchemaDiff calls it
+	// after the schema tables were diffed.
+	SchemaObjectDiffer interface {
+		SchemaObjectDiff(from, to *schema.Schema) ([]schema.Change, error)
+	}
+
+	// A RealmObjectDiffer wraps the RealmObjectDiff method for diffing the realm-level
+	// objects of two realms (e.g. roles and permissions).
+	//
+	// If the DiffDriver implements the RealmObjectDiffer interface, RealmDiff calls it
+	// after the realm schemas were diffed.
+	RealmObjectDiffer interface {
+		RealmObjectDiff(from, to *schema.Realm, opts *schema.DiffOptions) ([]schema.Change, error)
+	}
 )
 
 // RealmDiff implements the schema.Differ for Realm objects and returns a list of changes
 // that need to be applied in order to move a database from the current state to the desired.
 func (d *Diff) RealmDiff(from, to *schema.Realm) ([]schema.Change, error) {
-	var changes []schema.Change
+	return d.RealmDiffWith(from, to)
+}
+
+// RealmDiffWith implements the schema.OptionsDiffer interface. It is like RealmDiff,
+// but skips or ignores the changes according to the given diff options.
+func (d *Diff) RealmDiffWith(from, to *schema.Realm, opts ...schema.DiffOption) ([]schema.Change, error) {
+	var (
+		changes []schema.Change
+		o       = schema.NewDiffOptions(opts...)
+	)
 	// Drop or modify schema.
 	for _, s1 := range from.Schemas {
+		if o.Excluded(s1.Name) {
+			continue
+		}
 		s2, ok := to.Schema(s1.Name)
 		if !ok {
 			changes = append(changes, &schema.DropSchema{S: s1})
 			continue
 		}
-		change, err := d.SchemaDiff(s1, s2)
+		change, err := d.SchemaDiffWith(s1, s2, opts...)
 		if err != nil {
 			return nil, err
 		}
@@ -87,24 +128,46 @@ func (d *Diff) RealmDiff(from, to *schema.Realm) ([]schema.Change, error) {
 	}
 	// Add schemas.
 	for _, s1 := range to.Schemas {
-		if _, ok := from.Schema(s1.Name); ok {
+		if _, ok := from.Schema(s1.Name); ok || o.Excluded(s1.Name) {
 			continue
 		}
 		changes = append(changes, &schema.AddSchema{S: s1})
+		for _, o := range s1.Objects {
+			changes = append(changes, &schema.AddObject{O: o})
+		}
 		for _, t := range s1.Tables {
-			changes = append(changes, &schema.AddTable{T: t})
+			if !o.Excluded(s1.Name, t.Name) {
+				changes = append(changes, &schema.AddTable{T: t})
+			}
+		}
+	}
+	// Add, drop or modify realm-level objects.
+	if od, ok := d.DiffDriver.(RealmObjectDiffer); ok {
+		change, err := od.RealmObjectDiff(from, to, o)
+		if err != nil {
+			return nil, err
 		}
+		changes = append(changes, change...)
 	}
-	return changes, nil
+	return filterChanges(changes, o), nil
 }
 
 // SchemaDiff implements the schema.Differ interface and returns a list of
 // changes that need to be applied in order to move from one state to the other.
 func (d *Diff) SchemaDiff(from, to *schema.Schema) ([]schema.Change, error) {
+	return d.SchemaDiffWith(from, to)
+}
+
+// SchemaDiffWith implements the schema.OptionsDiffer interface. It is like SchemaDiff,
+// but skips or ignores the changes according to the given diff options.
+func (d *Diff) SchemaDiffWith(from, to *schema.Schema, opts ...schema.DiffOption) ([]schema.Change, error) {
 	if from.Name != to.Name {
 		return nil, fmt.Errorf("mismatched schema names: %q != %q", from.Name, to.Name)
 	}
-	var changes []schema.Change
+	var (
+		changes []schema.Change
+		o       = schema.NewDiffOptions(opts...)
+	)
 	// Drop or modify attributes (collations, charset, etc).
 	if change := d.SchemaAttrDiff(from, to); len(change) > 0 {
 		changes = append(changes, &schema.ModifySchema{
@@ -115,12 +178,15 @@ func (d *Diff) SchemaDiff(from, to *schema.Schema) ([]schema.Change, error) {
 
 	// Drop or modify tables.
 	for _, t1 := range from.Tables {
+		if o.Excluded(from.Name, t1.Name) {
+			continue
+		}
 		t2, ok := to.Table(t1.Name)
 		if !ok {
 			changes = append(changes, &schema.DropTable{T: t1})
 			continue
 		}
-		change, err := d.TableDiff(t1, t2)
+		change, err := d.TableDiffWith(t1, t2, opts...)
 		if err != nil {
 			return nil, err
 		}
@@ -133,16 +199,30 @@ func (d *Diff) SchemaDiff(from, to *schema.Schema) ([]schema.Change, error) {
 	}
 	// Add tables.
 	for _, t1 := range to.Tables {
-		if _, ok := from.Table(t1.Name); !ok {
+		if _, ok := from.Table(t1.Name); !ok && !o.Excluded(from.Name, t1.Name) {
 			changes = append(changes, &schema.AddTable{T: t1})
 		}
 	}
-	return changes, nil
+	// Add, drop or modify objects.
+	if od, ok := d.DiffDriver.(SchemaObjectDiffer); ok {
+		change, err := od.SchemaObjectDiff(from, to)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change...)
+	}
+	return filterChanges(changes, o), nil
 }
 
 // TableDiff implements the schema.TableDiffer interface and returns a list of
 // changes that need to be applied in order to move from one state to the other.
 func (d *Diff) TableDiff(from, to *schema.Table) ([]schema.Change, error) {
+	return d.TableDiffWith(from, to)
+}
+
+// TableDiffWith implements the schema.OptionsDiffer interface. It is like TableDiff,
+// but skips or ignores the changes according to the given diff options.
+func (d *Diff) TableDiffWith(from, to *schema.Table, opts ...schema.DiffOption) ([]schema.Change, error) {
 	if from.Name != to.Name {
 		return nil, fmt.Errorf("mismatched table names: %q != %q", from.Name, to.Name)
 	}
@@ -152,7 +232,17 @@ func (d *Diff) TableDiff(from, to *schema.Table) ([]schema.Change, error) {
 			return nil, err
 		}
 	}
-	var changes []schema.Change
+	var (
+		changes  []schema.Change
+		o        = schema.NewDiffOptions(opts...)
+		excluded = func(name string) bool {
+			var s string
+			if from.Schema != nil {
+				s = from.Schema.Name
+			}
+			return o.Excluded(s, from.Name, name)
+		}
+	)
 	if from.Name != to.Name {
 		return nil, fmt.Errorf("mismatched table names: %q != %q", from.Name, to.Name)
 	}
@@ -170,6 +260,9 @@ func (d *Diff) TableDiff(from, to *schema.Table) ([]schema.Change, error) {
 
 	// Drop or modify columns.
 	for _, c1 := range from.Columns {
+		if excluded(c1.Name) {
+			continue
+		}
 		c2, ok := to.Column(c1.Name)
 		if !ok {
 			changes = append(changes, &schema.DropColumn{C: c1})
@@ -189,16 +282,35 @@ func (d *Diff) TableDiff(from, to *schema.Table) ([]schema.Change, error) {
 	}
 	// Add columns.
 	for _, c1 := range to.Columns {
-		if _, ok := from.Column(c1.Name); !ok {
+		if _, ok := from.Column(c1.Name); !ok && !excluded(c1.Name) {
 			changes = append(changes, &schema.AddColumn{C: c1})
 		}
 	}
 
 	// Index changes.
-	changes = append(changes, d.indexDiff(from, to)...)
+	for _, c := range d.indexDiff(from, to) {
+		switch c := c.(type) {
+		case *schema.AddIndex:
+			if excluded(c.I.Name) {
+				continue
+			}
+		case *schema.DropIndex:
+			if excluded(c.I.Name) {
+				continue
+			}
+		case *schema.ModifyIndex:
+			if excluded(c.From.Name) {
+				continue
+			}
+		}
+		changes = append(changes, c)
+	}
 
 	// Drop or modify foreign-keys.
 	for _, fk1 := range from.ForeignKeys {
+		if excluded(fk1.Symbol) {
+			continue
+		}
 		fk2, ok := to.ForeignKey(fk1.Symbol)
 		if !ok {
 			changes = append(changes, &schema.DropForeignKey{F: fk1})
@@ -214,11 +326,152 @@ func (d *Diff) TableDiff(from, to *schema.Table) ([]schema.Change, error) {
 	}
 	// Add foreign-keys.
 	for _, fk1 := range to.ForeignKeys {
-		if _, ok := from.ForeignKey(fk1.Symbol); !ok {
+		if _, ok := from.ForeignKey(fk1.Symbol); !ok && !excluded(fk1.Symbol) {
 			changes = append(changes, &schema.AddForeignKey{F: fk1})
 		}
 	}
-	return changes, nil
+	return filterChanges(changes, o), nil
+}
+
+// filterChanges removes the changes that should be skipped or ignored according
+// to the diff options. Modify changes of ignored kinds are trimmed, and dropped
+// if there is nothing left to change. Trimmed changes are returned as new values,
+// and their desired elements keep the current values of the ignored kinds, as the
+// planners render the desired element (e.g. MODIFY COLUMN) and not only the change.
+func filterChanges(changes []schema.Change, o *schema.DiffOptions) []schema.Change {
+	var filtered []schema.Change
+	for _, c := range changes {
+		if o.Skipped(c) || o.Ignored(c) {
+			continue
+		}
+		switch m := c.(type) {
+		case *schema.ModifySchema:
+			cs := filterChanges(m.Changes, o)
+			if len(cs) == 0 {
+				continue
+			}
+			c = &schema.ModifySchema{S: m.S, Changes: cs}
+		case *schema.ModifyTable:
+			cs := filterChanges(m.Changes, o)
+			if len(cs) == 0 {
+				continue
+			}
+			c = &schema.ModifyTable{T: m.T, Changes: cs}
+		case *schema.ModifyColumn:
+			k := m.Change &^ o.Ignore
+			if k == schema.NoChange {
+				continue
+			}
+			if k != m.Change {
+				c = &schema.ModifyColumn{From: m.From, To: ignoredColumn(m.From, m.To, m.Change&o.Ignore), Change: k}
+			}
+		case *schema.ModifyIndex:
+			k := m.Change &^ o.Ignore
+			if k == schema.NoChange {
+				continue
+			}
+			if k != m.Change {
+				c = &schema.ModifyIndex{From: m.From, To: ignoredIndex(m.From, m.To, m.Change&o.Ignore), Change: k}
+			}
+		case *schema.ModifyForeignKey:
+			k := m.Change &^ o.Ignore
+			if k == schema.NoChange {
+				continue
+			}
+			if k != m.Change {
+				c = &schema.ModifyForeignKey{From: m.From, To: ignoredForeignKey(m.From, m.To, m.Change&o.Ignore), Change: k}
+			}
+		}
+		filtered = append(filtered, c)
+	}
+	return filtered
+}
+
+// ignoredColumn returns a copy of the desired column, with the values
+// of the ignored change kinds taken from the current column.
+func ignoredColumn(from, to *schema.Column, k schema.ChangeKind) *schema.Column {
+	c := *to
+	c.Attrs = ignoredAttrs(from.Attrs, to.Attrs, k)
+	if from.Type != nil && to.Type != nil && k.Is(schema.ChangeType|schema.ChangeNull) {
+		t := *to.Type
+		if k.Is(schema.ChangeType) {
+			t.Type, t.Raw = from.Type.Type, from.Type.Raw
+		}
+		if k.Is(schema.ChangeNull) {
+			t.Null = from.Type.Null
+		}
+		c.Type = &t
+	}
+	if k.Is(schema.ChangeDefault) {
+		c.Default = from.Default
+	}
+	return &c
+}
+
+// ignoredIndex returns a copy of the desired index, with the values
+// of the ignored change kinds taken from the current index.
+func ignoredIndex(from, to *schema.Index, k schema.ChangeKind) *schema.Index {
+	idx := *to
+	idx.Attrs = ignoredAttrs(from.Attrs, to.Attrs, k)
+	if k.Is(schema.ChangeUnique) {
+		idx.Unique = from.Unique
+	}
+	if k.Is(schema.ChangeParts) {
+		idx.Parts = from.Parts
+	}
+	return &idx
+}
+
+// ignoredForeignKey returns a copy of the desired foreign key, with
+// the values of the ignored change kinds taken from the current one.
+func ignoredForeignKey(from, to *schema.ForeignKey, k schema.ChangeKind) *schema.ForeignKey {
+	fk := *to
+	if k.Is(schema.ChangeColumn) {
+		fk.Columns = from.Columns
+	}
+	if k.Is(schema.ChangeRefColumn) {
+		fk.RefColumns = from.RefColumns
+	}
+	if k.Is(schema.ChangeRefTable) {
+		fk.RefTable = from.RefTable
+	}
+	if k.Is(schema.ChangeUpdateAction) {
+		fk.OnUpdate = from.OnUpdate
+	}
+	if k.Is(schema.ChangeDeleteAction) {
+		fk.OnDelete = from.OnDelete
+	}
+	return &fk
+}
+
+// ignoredAttrs returns the desired attributes, with the attributes
+// of the ignored change kinds taken from the current attributes.
+func ignoredAttrs(from, to []schema.Attr, k schema.ChangeKind) []schema.Attr {
+	ignored := func(a schema.Attr) bool {
+		switch a.(type) {
+		case *schema.Comment:
+			return k.Is(schema.ChangeComment)
+		case *schema.Charset:
+			return k.Is(schema.ChangeCharset)
+		case *schema.Collation:
+			return k.Is(schema.ChangeCollate)
+		case *schema.GeneratedExpr:
+			return k.Is(schema.ChangeGenerated)
+		}
+		return false
+	}
+	attrs := make([]schema.Attr, 0, len(to))
+	for _, a := range to {
+		if !ignored(a) {
+			attrs = append(attrs, a)
+		}
+	}
+	for _, a := range from {
+		if ignored(a) {
+			attrs = append(attrs, a)
+		}
+	}
+	return attrs
 }
 
 // indexDiff returns the schema changes (if any) for migrating table
@@ -342,6 +595,9 @@ func (d *Diff) fkChange(from, to *schema.ForeignKey) schema.ChangeKind {
 	if d.ReferenceChanged(from.OnDelete, to.OnDelete) {
 		change |= schema.ChangeDeleteAction
 	}
+	if ac, ok := d.DiffDriver.(ForeignKeyAttrChanger); ok && ac.ForeignKeyAttrChanged(from.Attrs, to.Attrs) {
+		change |= schema.ChangeAttr
+	}
 	return change
 }
 
@@ -470,6 +726,73 @@ func CheckDiff(from, to *schema.Table, compare ...func(c1, c2 *schema.Check) boo
 }
 
 // checks extracts all constraints from table attributes.
+// RealmObjectDiff is a helper used by the different drivers to diff the realm-level objects (e.g.
+// roles and permissions) of two realms. Objects are matched using the find function and compared
+// using the changed function. Objects that are missing in the desired state are dropped, unless
+// keep reports they are not managed or dropped together with the objects they refer to.
+func RealmObjectDiff(from, to *schema.Realm, find func(*schema.Realm, schema.Object) (schema.Object, bool), changed func(from, to schema.Object) bool, keep func(schema.Object) bool) []schema.Change {
+	var changes []schema.Change
+	// Drop or modify objects.
+	for _, o1 := range from.Objects {
+		o2, ok := find(to, o1)
+		if !ok {
+			if !keep(o1) {
+				changes = append(changes, &schema.DropObject{O: o1})
+			}
+			continue
+		}
+		if changed(o1, o2) {
+			changes = append(changes, &schema.ModifyObject{From: o1, To: o2})
+		}
+	}
+	// Add objects.
+	for _, o1 := range to.Objects {
+		if _, ok := find(from, o1); !ok {
+			changes = append(changes, &schema.AddObject{O: o1})
+		}
+	}
+	return changes
+}
+
+// PrivilegesDiff returns the privileges that need to be granted and revoked
+// in order to move a permission from one set of privileges to the other.
+func PrivilegesDiff(from, to []string) (grant, revoke []string) {
+	has := func(privileges []string, v string) bool {
+		for _, v2 := range privileges {
+			if strings.EqualFold(v, v2) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, v := range to {
+		if !has(from, v) {
+			grant = append(grant, v)
+		}
+	}
+	for _, v := range from {
+		if !has(to, v) {
+			revoke = append(revoke, v)
+		}
+	}
+	return grant, revoke
+}
+
+// HasPrivilegeTarget reports if the object of a permission (i.e. a schema, a table or
+// a table column) exists in the given realm. The table and the column are optional.
+func HasPrivilegeTarget(r *schema.Realm, s *schema.Schema, t *schema.Table, c *schema.Column) bool {
+	s, ok := r.Schema(s.Name)
+	if !ok || t == nil {
+		return ok
+	}
+	t, ok = s.Table(t.Name)
+	if !ok || c == nil {
+		return ok
+	}
+	_, ok = t.Column(c.Name)
+	return ok
+}
+
 func checks(attr []schema.Attr) (checks []*schema.Check) {
 	for i := range attr {
 		if c, ok := attr[i].(*schema.Check); ok {
@@ -0,0 +1,262 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"path"
+	"strings"
+
+	"ariga.io/atlas/sql/schema"
+)
+
+// A NameFilter filters schema elements (e.g. schemas or tables) by their names.
+// Patterns are glob patterns (see path.Match) that may be qualified with the
+// names of the parent elements, separated by dots. For example, "tmp_*" or
+// "public.tmp_*". A pattern with N parts is matched against the last N names
+// of the element.
+type NameFilter struct {
+	Names    []string // Exact names to include.
+	Patterns []string // Patterns to include.
+	Exclude  []string // Patterns to exclude.
+}
+
+// NewNameFilter returns a NameFilter for the given names and exclude patterns.
+// Names that contain glob meta-characters or are qualified with the names of
+// their parents are treated as include patterns, and names prefixed with "!"
+// are treated as exclude patterns.
+func NewNameFilter(names, exclude []string) *NameFilter {
+	f := &NameFilter{Exclude: append([]string(nil), exclude...)}
+	for _, n := range names {
+		switch {
+		case strings.HasPrefix(n, "!"):
+			f.Exclude = append(f.Exclude, n[1:])
+		case IsGlob(n) || strings.Contains(n, "."):
+			f.Patterns = append(f.Patterns, n)
+		default:
+			f.Names = append(f.Names, n)
+		}
+	}
+	return f
+}
+
+// RealmSchemasFilter returns the NameFilter for inspecting the schemas of a
+// realm. Schema-qualified table patterns (e.g. "public.audit_*") select their
+// schemas, and unqualified exclude patterns exclude schemas.
+func RealmSchemasFilter(opts *schema.InspectRealmOption) *NameFilter {
+	if opts == nil {
+		return &NameFilter{}
+	}
+	var names, exclude []string
+	for _, n := range opts.Schemas {
+		switch i := strings.IndexByte(n, '.'); {
+		case i == -1:
+			names = append(names, n)
+		case !strings.HasPrefix(n, "!"):
+			names = append(names, n[:i])
+		}
+	}
+	for _, p := range opts.Exclude {
+		if !strings.Contains(p, ".") {
+			exclude = append(exclude, p)
+		}
+	}
+	return NewNameFilter(names, exclude)
+}
+
+// RealmTablesOptions returns the InspectOptions for inspecting the tables of
+// a realm, based on the schema-qualified patterns of the given option. If some
+// tables were selected using qualified patterns, the other selected schemas
+// are inspected with all their tables. A nil value is returned if there are no
+// qualified patterns.
+func RealmTablesOptions(opts *schema.InspectRealmOption) *schema.InspectOptions {
+	if opts == nil {
+		return nil
+	}
+	var (
+		tables, exclude, all []string
+		qualified            = make(map[string]bool)
+	)
+	for _, n := range opts.Schemas {
+		switch i := strings.IndexByte(n, '.'); {
+		case i == -1 && !strings.HasPrefix(n, "!"):
+			all = append(all, n)
+		case i == -1:
+		case strings.HasPrefix(n, "!"):
+			exclude = append(exclude, n[1:])
+		default:
+			tables = append(tables, n)
+			qualified[n[:i]] = true
+		}
+	}
+	for _, p := range opts.Exclude {
+		if strings.Contains(p, ".") {
+			exclude = append(exclude, p)
+		}
+	}
+	if len(tables) > 0 {
+		for _, n := range all {
+			if !qualified[n] {
+				tables = append(tables, n+".*")
+			}
+		}
+	}
+	if len(tables)+len(exclude) == 0 {
+		return nil
+	}
+	return &schema.InspectOptions{Tables: tables, Exclude: exclude}
+}
+
+// IsGlob reports whether the given name contains glob meta-characters.
+func IsGlob(s string) bool {
+	return strings.ContainsAny(s, `*?[\`)
+}
+
+// Empty reports whether the filter matches all names.
+func (f *NameFilter) Empty() bool {
+	return len(f.Names)+len(f.Patterns)+len(f.Exclude) == 0
+}
+
+// Match reports whether the element with the given names (e.g. schema and
+// table names) passes the filter.
+func (f *NameFilter) Match(names ...string) bool {
+	for _, p := range f.Exclude {
+		if match(p, names) {
+			return false
+		}
+	}
+	if len(f.Names)+len(f.Patterns) == 0 {
+		return true
+	}
+	for _, n := range f.Names {
+		if len(names) > 0 && n == names[len(names)-1] {
+			return true
+		}
+	}
+	for _, p := range f.Patterns {
+		if match(p, names) {
+			return true
+		}
+	}
+	return false
+}
+
+// Cond returns an SQL condition for filtering rows by the given columns (e.g.
+// schema and table columns) or an empty string if there is nothing to filter.
+// The arg function is called for each argument value and returns its placeholder,
+// and the escape is appended to each LIKE expression (e.g. " ESCAPE '\'").
+//
+// Patterns that cannot be expressed using LIKE (e.g. character classes) are
+// left out, and therefore, the results should be checked using Match as well.
+func (f *NameFilter) Cond(columns []string, escape string, arg func(string) string) string {
+	if len(columns) == 0 {
+		return ""
+	}
+	var conds []string
+	if include, ok := f.include(columns, escape, arg); ok && include != "" {
+		conds = append(conds, include)
+	}
+	for _, p := range f.Exclude {
+		if c, ok := likeCond(p, columns, escape, arg); ok {
+			conds = append(conds, "NOT "+c)
+		}
+	}
+	return strings.Join(conds, " AND ")
+}
+
+// include returns the condition for the include names and patterns. It reports
+// false if one of the patterns cannot be expressed using LIKE, as the condition
+// would filter out rows that should be included.
+func (f *NameFilter) include(columns []string, escape string, arg func(string) string) (string, bool) {
+	var (
+		conds []string
+		last  = columns[len(columns)-1]
+	)
+	if len(f.Names) > 0 {
+		args := make([]string, len(f.Names))
+		for i, n := range f.Names {
+			args[i] = arg(n)
+		}
+		conds = append(conds, last+" IN ("+strings.Join(args, ", ")+")")
+	}
+	for _, p := range f.Patterns {
+		c, ok := likeCond(p, columns, escape, arg)
+		if !ok {
+			return "", false
+		}
+		conds = append(conds, c)
+	}
+	if len(conds) < 2 {
+		return strings.Join(conds, ""), true
+	}
+	return "(" + strings.Join(conds, " OR ") + ")", true
+}
+
+// likeCond returns the LIKE condition for the given pattern.
+func likeCond(p string, columns []string, escape string, arg func(string) string) (string, bool) {
+	parts := strings.Split(p, ".")
+	if len(parts) > len(columns) {
+		return "", false
+	}
+	columns = columns[len(columns)-len(parts):]
+	conds := make([]string, len(parts))
+	for i := range parts {
+		like, ok := likePattern(parts[i])
+		if !ok {
+			return "", false
+		}
+		conds[i] = columns[i] + " LIKE " + arg(like) + escape
+	}
+	if len(conds) == 1 {
+		return conds[0], true
+	}
+	return "(" + strings.Join(conds, " AND ") + ")", true
+}
+
+// likePattern converts a glob pattern to a LIKE pattern that uses
+// backslash as an escape character. It reports false if the pattern
+// cannot be expressed using LIKE.
+func likePattern(p string) (string, bool) {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; c {
+		case '*':
+			b.WriteByte('%')
+		case '?':
+			b.WriteByte('_')
+		case '[':
+			return "", false
+		case '\\':
+			if i++; i == len(p) {
+				return "", false
+			}
+			writeLike(&b, p[i])
+		default:
+			writeLike(&b, c)
+		}
+	}
+	return b.String(), true
+}
+
+func writeLike(b *strings.Builder, c byte) {
+	if c == '%' || c == '_' || c == '\\' {
+		b.WriteByte('\\')
+	}
+	b.WriteByte(c)
+}
+
+// match reports whether the pattern matches the last names.
+func match(p string, names []string) bool {
+	parts := strings.Split(p, ".")
+	if len(parts) > len(names) {
+		return false
+	}
+	names = names[len(names)-len(parts):]
+	for i := range parts {
+		if ok, _ := path.Match(parts[i], names[i]); !ok {
+			return false
+		}
+	}
+	return true
+}
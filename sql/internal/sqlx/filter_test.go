@@ -0,0 +1,75 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlx
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNameFilter_Match(t *testing.T) {
+	f := NewNameFilter([]string{"users", "audit_*", "!audit_tmp"}, []string{"public.tmp_*"})
+	require.Equal(t, []string{"users"}, f.Names)
+	require.Equal(t, []string{"audit_*"}, f.Patterns)
+	require.Equal(t, []string{"public.tmp_*", "audit_tmp"}, f.Exclude)
+	require.True(t, f.Match("public", "users"))
+	require.True(t, f.Match("public", "audit_log"))
+	require.False(t, f.Match("public", "audit_tmp"))
+	require.False(t, f.Match("public", "pets"))
+
+	f = NewNameFilter(nil, []string{"public.tmp_*", "schema_migrations"})
+	require.True(t, f.Match("public", "users"))
+	require.True(t, f.Match("other", "tmp_users"))
+	require.False(t, f.Match("public", "tmp_users"))
+	require.False(t, f.Match("other", "schema_migrations"))
+	require.True(t, (&NameFilter{}).Match("users"))
+}
+
+func TestNameFilter_Cond(t *testing.T) {
+	var args []string
+	arg := func(v string) string {
+		args = append(args, v)
+		return "?"
+	}
+	f := NewNameFilter([]string{"users", "pets"}, nil)
+	require.Equal(t, "t IN (?, ?)", f.Cond([]string{"s", "t"}, "", arg))
+	require.Equal(t, []string{"users", "pets"}, args)
+
+	args = nil
+	f = NewNameFilter([]string{"users", "audit_*"}, []string{"public.tmp_*", "part_[0-9]"})
+	require.Equal(t, "(t IN (?) OR t LIKE ?) AND NOT (s LIKE ? AND t LIKE ?)", f.Cond([]string{"s", "t"}, "", arg))
+	require.Equal(t, []string{"users", `audit\_%`, "public", `tmp\_%`}, args)
+
+	// Include patterns that cannot be expressed with LIKE disable the include condition.
+	args = nil
+	f = NewNameFilter([]string{"part_[0-9]", "a?c"}, []string{"b*"})
+	require.Equal(t, "NOT t LIKE ? ESCAPE '\\'", f.Cond([]string{"t"}, " ESCAPE '\\'", arg))
+	require.Equal(t, []string{"b%"}, args)
+	require.Empty(t, (&NameFilter{}).Cond([]string{"t"}, "", arg))
+}
+
+func TestRealmFilters(t *testing.T) {
+	require.Nil(t, RealmTablesOptions(nil))
+	require.Nil(t, RealmTablesOptions(&schema.InspectRealmOption{Schemas: []string{"public"}, Exclude: []string{"tmp"}}))
+	require.Equal(t, &schema.InspectOptions{Exclude: []string{"public.tmp_*"}}, RealmTablesOptions(&schema.InspectRealmOption{Exclude: []string{"tmp", "public.tmp_*"}}))
+	require.Equal(t, &schema.InspectOptions{
+		Tables:  []string{"public.audit_*", "other.*"},
+		Exclude: []string{"public.audit_tmp"},
+	}, RealmTablesOptions(&schema.InspectRealmOption{Schemas: []string{"public", "other", "public.audit_*", "!public.audit_tmp", "!internal"}}))
+
+	f := RealmSchemasFilter(&schema.InspectRealmOption{Schemas: []string{"app_*", "!app_test", "public.audit_*", "!public.audit_tmp"}, Exclude: []string{"tmp", "public.tmp_*"}})
+	require.Equal(t, []string{"public"}, f.Names)
+	require.Equal(t, []string{"app_*"}, f.Patterns)
+	require.Equal(t, []string{"tmp", "app_test"}, f.Exclude)
+	require.True(t, RealmSchemasFilter(nil).Empty())
+
+	f = NewNameFilter([]string{"public.users", "other.*"}, nil)
+	require.True(t, f.Match("public", "users"))
+	require.True(t, f.Match("other", "pets"))
+	require.False(t, f.Match("public", "pets"))
+}
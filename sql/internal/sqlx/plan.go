@@ -17,7 +17,7 @@ import (
 
 type execPlanner interface {
 	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
-	PlanChanges(context.Context, string, []schema.Change) (*migrate.Plan, error)
+	PlanChanges(context.Context, string, []schema.Change, ...migrate.PlanOption) (*migrate.Plan, error)
 }
 
 // ApplyChanges is a helper used by the different drivers to apply changes.
@@ -37,12 +37,88 @@ func ApplyChanges(ctx context.Context, changes []schema.Change, p execPlanner) e
 	return nil
 }
 
+// Privileges describes the privileges of a permission state. It is used by
+// PlanPermissions for planning the GRANT and REVOKE statements of a permission.
+type Privileges struct {
+	Role      string   // Name of the grantee, used in comments.
+	Target    string   // Qualified name of the object, used in comments.
+	List      []string // Granted privileges.
+	Grantable bool     // Privileges were granted WITH GRANT OPTION.
+	// Grant and Revoke return the dialect statements for granting and revoking
+	// the given privileges of the permission. The grantOption flag indicates if
+	// the grant option should be revoked as well.
+	Grant  func(privileges []string) string
+	Revoke func(privileges []string, grantOption bool) string
+}
+
+// PlanPermissions is a helper used by the different drivers to plan permission changes.
+// It splits the changes of permission objects from the rest, and returns the GRANT and
+// REVOKE changes planned for them. The privileges function returns the Privileges of a
+// permission object, or false if the given object is not a permission.
+func PlanPermissions(changes []schema.Change, privileges func(schema.Object) (*Privileges, bool)) (planned []schema.Change, grants, revokes []*migrate.Change, err error) {
+	grant := func(c schema.Change, p *Privileges, list []string, grantOption bool) {
+		grants = append(grants, &migrate.Change{
+			Cmd:     p.Grant(list),
+			Source:  c,
+			Comment: fmt.Sprintf("grant privileges on %q to %q", p.Target, p.Role),
+			Reverse: p.Revoke(list, grantOption),
+		})
+	}
+	revoke := func(c schema.Change, p *Privileges, list []string, grantOption bool) {
+		revokes = append(revokes, &migrate.Change{
+			Cmd:     p.Revoke(list, grantOption),
+			Source:  c,
+			Comment: fmt.Sprintf("revoke privileges on %q from %q", p.Target, p.Role),
+			Reverse: p.Grant(list),
+		})
+	}
+	planned = make([]schema.Change, 0, len(changes))
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddObject:
+			if p, ok := privileges(c.O); ok {
+				grant(c, p, p.List, p.Grantable)
+				continue
+			}
+		case *schema.DropObject:
+			if p, ok := privileges(c.O); ok {
+				revoke(c, p, p.List, p.Grantable)
+				continue
+			}
+		case *schema.ModifyObject:
+			if from, ok := privileges(c.From); ok {
+				to, ok := privileges(c.To)
+				if !ok {
+					return nil, nil, nil, fmt.Errorf("unsupported object modification %T -> %T", c.From, c.To)
+				}
+				add, drop := PrivilegesDiff(from.List, to.List)
+				if len(drop) > 0 {
+					revoke(c, from, drop, false)
+				}
+				if len(add) > 0 {
+					grant(c, to, add, false)
+				}
+				continue
+			}
+		}
+		planned = append(planned, c)
+	}
+	return planned, grants, revokes, nil
+}
+
 // DetachCycles takes a list of schema changes, and detaches
 // references between changes if there is at least one circular
 // reference in the changeset. More explicitly, it postpones fks
 // creation, or deletes fks before deletes their tables.
 func DetachCycles(changes []schema.Change) ([]schema.Change, error) {
-	sorted, err := sortMap(changes)
+	return DetachCyclesWith(changes, nil)
+}
+
+// DetachCyclesWith is like DetachCycles, but allows passing a function that returns the
+// tables a table depends on, in addition to the tables it references. For example, table
+// partitions depend on their parent tables.
+func DetachCyclesWith(changes []schema.Change, depends func(*schema.Table) []*schema.Table) ([]schema.Change, error) {
+	sorted, err := sortMap(changes, depends)
 	if err == errCycle {
 		return detachReferences(changes), nil
 	}
@@ -124,12 +200,12 @@ var errCycle = errors.New("cycle detected")
 // sortMap returns an index-map indicates the position of table in a topological
 // sort in reversed order based on its references, and a boolean indicate if there
 // is a non-self loop.
-func sortMap(changes []schema.Change) (map[string]int, error) {
+func sortMap(changes []schema.Change, depends func(*schema.Table) []*schema.Table) (map[string]int, error) {
 	var (
 		visit     func(string) bool
 		sorted    = make(map[string]int)
 		progress  = make(map[string]bool)
-		deps, err = dependencies(changes)
+		deps, err = dependencies(changes, depends)
 	)
 	if err != nil {
 		return nil, err
@@ -160,11 +236,18 @@ func sortMap(changes []schema.Change) (map[string]int, error) {
 }
 
 // dependencies returned an adjacency list of all tables and the table they depend on
-func dependencies(changes []schema.Change) (map[string][]*schema.Table, error) {
+func dependencies(changes []schema.Change, depends func(*schema.Table) []*schema.Table) (map[string][]*schema.Table, error) {
 	deps := make(map[string][]*schema.Table)
 	for _, change := range changes {
 		switch change := change.(type) {
 		case *schema.AddTable:
+			if depends != nil {
+				for _, t := range depends(change.T) {
+					if isAdded(changes, t) {
+						deps[change.T.Name] = append(deps[change.T.Name], t)
+					}
+				}
+			}
 			for _, fk := range change.T.ForeignKeys {
 				if err := checkFK(fk); err != nil {
 					return nil, err
@@ -174,6 +257,13 @@ func dependencies(changes []schema.Change) (map[string][]*schema.Table, error) {
 				}
 			}
 		case *schema.DropTable:
+			if depends != nil {
+				for _, t := range depends(change.T) {
+					if isDropped(changes, t) {
+						deps[t.Name] = append(deps[t.Name], change.T)
+					}
+				}
+			}
 			for _, fk := range change.T.ForeignKeys {
 				if err := checkFK(fk); err != nil {
 					return nil, err
@@ -239,6 +329,16 @@ func table(change schema.Change) (t string) {
 	return
 }
 
+// isAdded checks if the given table is marked as a created in the changeset.
+func isAdded(changes []schema.Change, t *schema.Table) bool {
+	for _, c := range changes {
+		if c, ok := c.(*schema.AddTable); ok && c.T.Name == t.Name {
+			return true
+		}
+	}
+	return false
+}
+
 // isDropped checks if the given table is marked as a deleted in the changeset.
 func isDropped(changes []schema.Change, t *schema.Table) bool {
 	for _, c := range changes {
@@ -5,6 +5,7 @@
 package sqlx
 
 import (
+	"strings"
 	"testing"
 
 	"ariga.io/atlas/sql/schema"
@@ -92,3 +93,81 @@ func TestDetachCycles(t *testing.T) {
 	workplaces.ForeignKeys = nil
 	require.Equal(t, deletion, planned[2:])
 }
+
+func TestDetachCyclesWith(t *testing.T) {
+	var (
+		logs    = schema.NewTable("logs").AddColumns(schema.NewIntColumn("id", "int"))
+		logsA   = schema.NewTable("logs_a")
+		users   = schema.NewTable("users").AddColumns(schema.NewIntColumn("log_id", "int"))
+		fk      = schema.NewForeignKey("log").SetTable(users).AddColumns(users.Columns[0]).SetRefTable(logsA).AddRefColumns(logs.Columns[0])
+		depends = func(t *schema.Table) []*schema.Table {
+			if t == logsA {
+				return []*schema.Table{logs}
+			}
+			return nil
+		}
+	)
+	changes := []schema.Change{
+		&schema.AddTable{T: logsA},
+		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddForeignKey{F: fk}}},
+		&schema.AddTable{T: logs},
+	}
+	planned, err := DetachCyclesWith(changes, depends)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{changes[2], changes[0], changes[1]}, planned)
+
+	deletion := []schema.Change{&schema.DropTable{T: logs}, &schema.DropTable{T: logsA}}
+	planned, err = DetachCyclesWith(deletion, depends)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{deletion[1], deletion[0]}, planned)
+}
+
+func TestPlanPermissions(t *testing.T) {
+	type (
+		perm struct {
+			schema.Object
+			list []string
+		}
+		role struct{ schema.Object }
+	)
+	privileges := func(o schema.Object) (*Privileges, bool) {
+		p, ok := o.(*perm)
+		if !ok {
+			return nil, false
+		}
+		return &Privileges{
+			Role:   "user",
+			Target: "public.users",
+			List:   p.list,
+			Grant: func(list []string) string {
+				return "GRANT " + strings.Join(list, ", ")
+			},
+			Revoke: func(list []string, grantOption bool) string {
+				if grantOption {
+					list = append(list, "GRANT OPTION")
+				}
+				return "REVOKE " + strings.Join(list, ", ")
+			},
+		}, true
+	}
+	table := &schema.AddTable{T: schema.NewTable("users")}
+	changes := []schema.Change{
+		table,
+		&schema.ModifyObject{From: &perm{list: []string{"SELECT", "INSERT"}}, To: &perm{list: []string{"select", "UPDATE"}}},
+		&schema.DropObject{O: &perm{list: []string{"DELETE"}}},
+	}
+	planned, grants, revokes, err := PlanPermissions(changes, privileges)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{table}, planned)
+	require.Len(t, grants, 1)
+	require.Equal(t, "GRANT UPDATE", grants[0].Cmd)
+	require.Equal(t, "REVOKE UPDATE", grants[0].Reverse)
+	require.Equal(t, `grant privileges on "public.users" to "user"`, grants[0].Comment)
+	require.Len(t, revokes, 2)
+	require.Equal(t, "REVOKE INSERT", revokes[0].Cmd)
+	require.Equal(t, "GRANT INSERT", revokes[0].Reverse)
+	require.Equal(t, "REVOKE DELETE", revokes[1].Cmd)
+
+	_, _, _, err = PlanPermissions([]schema.Change{&schema.ModifyObject{From: &perm{}, To: &role{}}}, privileges)
+	require.Error(t, err)
+}
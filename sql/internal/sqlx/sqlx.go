@@ -14,6 +14,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 
 	"ariga.io/atlas/sql/schema"
 )
@@ -58,6 +59,54 @@ func ValidString(s sql.NullString) bool {
 	return s.Valid && s.String != "" && strings.ToLower(s.String) != "null"
 }
 
+// PrivilegeTarget returns the schema, table and column in the realm that a privilege was
+// granted on, as returned by the privileges queries of the drivers. The table and column
+// names are optional, and false is returned if one of the objects was not inspected.
+func PrivilegeTarget(r *schema.Realm, ns string, table, column sql.NullString) (s *schema.Schema, t *schema.Table, c *schema.Column, ok bool) {
+	if s, ok = r.Schema(ns); !ok {
+		return nil, nil, nil, false
+	}
+	if ValidString(table) {
+		if t, ok = s.Table(table.String); !ok {
+			return nil, nil, nil, false
+		}
+	}
+	if ValidString(column) && t != nil {
+		if c, ok = t.Column(column.String); !ok {
+			return nil, nil, nil, false
+		}
+	}
+	return s, t, c, true
+}
+
+// PrivilegeTargetName returns the qualified name of the object a permission was granted on.
+func PrivilegeTargetName(s *schema.Schema, t *schema.Table, c *schema.Column) string {
+	n := s.Name
+	if t != nil {
+		n += "." + t.Name
+	}
+	if c != nil {
+		n += "." + c.Name
+	}
+	return n
+}
+
+// PermissionKey returns a key that identifies a permission by its grantee, the object
+// it was granted on and its grant option. Permissions with the same key are merged.
+func PermissionKey(grantee []string, s *schema.Schema, t *schema.Table, c *schema.Column, grantable bool) string {
+	k := append(grantee[:len(grantee):len(grantee)], "", "", "", strconv.FormatBool(grantable))
+	if s != nil {
+		k[len(grantee)] = s.Name
+	}
+	if t != nil {
+		k[len(grantee)+1] = t.Name
+	}
+	if c != nil {
+		k[len(grantee)+2] = c.Name
+	}
+	return strings.Join(k, "\x00")
+}
+
 // ScanOne scans one record and closes the rows at the end.
 func ScanOne(rows *sql.Rows, dest ...interface{}) error {
 	defer rows.Close()
@@ -198,7 +247,7 @@ func ValuesEqual(v1, v2 []string) bool {
 // ModeInspectSchema returns the InspectMode or its default.
 func ModeInspectSchema(o *schema.InspectOptions) schema.InspectMode {
 	if o == nil || o.Mode == 0 {
-		return schema.InspectSchemas | schema.InspectTables
+		return schema.InspectSchemas | schema.InspectTables | schema.InspectObjects
 	}
 	return o.Mode
 }
@@ -206,11 +255,73 @@ func ModeInspectSchema(o *schema.InspectOptions) schema.InspectMode {
 // ModeInspectRealm returns the InspectMode or its default.
 func ModeInspectRealm(o *schema.InspectRealmOption) schema.InspectMode {
 	if o == nil || o.Mode == 0 {
-		return schema.InspectSchemas | schema.InspectTables
+		return schema.InspectSchemas | schema.InspectTables | schema.InspectObjects
 	}
 	return o.Mode
 }
 
+// ConcurrencyInspectRealm returns the number of schemas that can be inspected
+// concurrently using the given connection. Only connection pools (i.e. *sql.DB)
+// can be queried concurrently, and their limit of open connections is respected.
+func ConcurrencyInspectRealm(conn schema.ExecQuerier, o *schema.InspectRealmOption) int {
+	db, ok := conn.(*sql.DB)
+	if !ok || o == nil || o.Concurrency < 2 {
+		return 1
+	}
+	if m := db.Stats().MaxOpenConnections; m > 0 && m < o.Concurrency {
+		return m
+	}
+	return o.Concurrency
+}
+
+// ForEachSchema calls f for each of the given schemas using at most n goroutines.
+// Since each call is expected to modify only its own schema, the results do not
+// depend on the order in which the schemas were processed. If one of the calls
+// fails, the context passed to the others is canceled and the error is returned.
+func ForEachSchema(ctx context.Context, n int, schemas []*schema.Schema, f func(context.Context, *schema.Schema) error) error {
+	if n < 2 || len(schemas) < 2 {
+		for _, s := range schemas {
+			if err := f(ctx, s); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg   sync.WaitGroup
+		once sync.Once
+		err  error
+		sem  = make(chan struct{}, n)
+	)
+	for _, s := range schemas {
+		sem <- struct{}{}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(s *schema.Schema) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if ferr := f(ctx, s); ferr != nil {
+				once.Do(func() {
+					err = ferr
+					cancel()
+				})
+			}
+		}(s)
+	}
+	wg.Wait()
+	if err == nil {
+		// The parent context was canceled.
+		err = ctx.Err()
+	}
+	return err
+}
+
 // A Builder provides a syntactic sugar API for writing SQL statements.
 type Builder struct {
 	bytes.Buffer
@@ -5,11 +5,15 @@
 package sqlx
 
 import (
+	"context"
+	"errors"
 	"strconv"
+	"sync/atomic"
 	"testing"
 
 	"ariga.io/atlas/sql/schema"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -41,6 +45,58 @@ func TestModeInspectSchema(t *testing.T) {
 	require.False(t, m.Is(schema.InspectTables))
 }
 
+func TestConcurrencyInspectRealm(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	require.Equal(t, 1, ConcurrencyInspectRealm(db, nil))
+	require.Equal(t, 1, ConcurrencyInspectRealm(db, &schema.InspectRealmOption{}))
+	require.Equal(t, 8, ConcurrencyInspectRealm(db, &schema.InspectRealmOption{Concurrency: 8}))
+	db.SetMaxOpenConns(4)
+	require.Equal(t, 4, ConcurrencyInspectRealm(db, &schema.InspectRealmOption{Concurrency: 8}))
+	// Single connections cannot be queried concurrently.
+	require.Equal(t, 1, ConcurrencyInspectRealm(nopCloser{db}, &schema.InspectRealmOption{Concurrency: 8}))
+}
+
+func TestForEachSchema(t *testing.T) {
+	schemas := make([]*schema.Schema, 10)
+	for i := range schemas {
+		schemas[i] = schema.New(strconv.Itoa(i))
+	}
+	var running, max int32
+	err := ForEachSchema(context.Background(), 3, schemas, func(_ context.Context, s *schema.Schema) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		s.AddTables(schema.NewTable("t" + s.Name))
+		return nil
+	})
+	require.NoError(t, err)
+	require.LessOrEqual(t, max, int32(3))
+	for i, s := range schemas {
+		require.Equal(t, "t"+strconv.Itoa(i), s.Tables[0].Name)
+	}
+
+	err = ForEachSchema(context.Background(), 3, schemas, func(ctx context.Context, s *schema.Schema) error {
+		if s.Name == "5" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	require.EqualError(t, err, "boom")
+	err = ForEachSchema(context.Background(), 1, schemas, func(ctx context.Context, s *schema.Schema) error {
+		if s.Name == "5" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	require.EqualError(t, err, "boom")
+}
+
 func TestBuilder(t *testing.T) {
 	var (
 		b       = &Builder{QuoteChar: '"'}
@@ -0,0 +1,185 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Command describes an external binary that is invoked by the Executor for statements
+// that were planned as command invocations instead of SQL. For example, ALTER TABLE
+// statements of large tables that are executed using gh-ost or pt-online-schema-change.
+//
+//	migrate.NewExecutor(drv, dir, rrw, migrate.WithCommands(migrate.Command{
+//		Name: "gh-ost",
+//		Path: "/usr/local/bin/gh-ost",
+//		Args: []string{"--host=localhost", "--user=root"},
+//	}))
+type Command struct {
+	Name string   // Name of the command, as written in the migration file. e.g. gh-ost.
+	Path string   // Path to the binary to invoke. Defaults to Name (resolved using PATH).
+	Args []string // Additional arguments to pass the binary, such as connection flags.
+}
+
+// WithCommands configures the Executor to invoke the given external commands for
+// statements that start with their names, instead of executing them on the database.
+func WithCommands(cmds ...Command) ExecutorOption {
+	return func(ex *Executor) error {
+		for _, c := range cmds {
+			if c.Name == "" {
+				return errors.New("sql/migrate: execute: command name cannot be empty")
+			}
+		}
+		ex.cmds = append(ex.cmds, cmds...)
+		return nil
+	}
+}
+
+// StmtCommand returns the first word of the statement, ignoring its leading
+// comments and hints. For statements that were planned as command invocations,
+// it is the name of the command (e.g. gh-ost). For SQL statements, it is the
+// SQL command (e.g. ALTER).
+func StmtCommand(stmt string) string {
+	if f := strings.Fields(trimComments(stmt)); len(f) > 0 {
+		return f[0]
+	}
+	return ""
+}
+
+// CommandArgs returns the arguments of a statement that invokes an external command,
+// starting with the command name. Leading comments and the trailing semicolon are ignored.
+func CommandArgs(stmt string) ([]string, error) {
+	return cmdArgs(strings.TrimSuffix(trimComments(stmt), ";"))
+}
+
+// A CommandTranslator is an optional interface a Driver can implement for translating
+// statements that invoke external commands (e.g. gh-ost) into equivalent SQL statements.
+// It is used when replaying migration files on a dev database, as the external commands
+// are not invoked there.
+type CommandTranslator interface {
+	// TranslateCommand returns the SQL statement that is equivalent to the command statement.
+	// If the statement does not invoke a command that is known to the driver, false is returned.
+	TranslateCommand(stmt string) (string, bool, error)
+}
+
+// ReplayStmt returns the statement to execute in place of the given one when replaying
+// migration files on a dev database. Statements that invoke external commands are
+// translated to SQL, in case the driver implements the CommandTranslator interface.
+func ReplayStmt(drv Driver, stmt string) (string, error) {
+	t, ok := drv.(CommandTranslator)
+	if !ok {
+		return stmt, nil
+	}
+	switch s, ok, err := t.TranslateCommand(stmt); {
+	case err != nil:
+		return "", err
+	case ok:
+		return s, nil
+	default:
+		return stmt, nil
+	}
+}
+
+// command returns the configured command the statement should be executed with, if any.
+func (e *Executor) command(stmt string) (*Command, bool) {
+	name := StmtCommand(stmt)
+	for i := range e.cmds {
+		if e.cmds[i].Name == name {
+			return &e.cmds[i], true
+		}
+	}
+	return nil, false
+}
+
+// trimComments trims the leading comments and hints (e.g. /*+ ... */) of the statement.
+func trimComments(stmt string) string {
+	for {
+		stmt = strings.TrimSpace(stmt)
+		switch {
+		case strings.HasPrefix(stmt, "--"), strings.HasPrefix(stmt, "#"):
+			i := strings.IndexByte(stmt, '\n')
+			if i == -1 {
+				return ""
+			}
+			stmt = stmt[i+1:]
+		case strings.HasPrefix(stmt, "/*"):
+			i := strings.Index(stmt, "*/")
+			if i == -1 {
+				return ""
+			}
+			stmt = stmt[i+2:]
+		default:
+			return stmt
+		}
+	}
+}
+
+// run invokes the binary with the arguments written in the statement.
+func (c *Command) run(ctx context.Context, stmt string) error {
+	args, err := CommandArgs(stmt)
+	if err != nil {
+		return fmt.Errorf("parse %s command: %w", c.Name, err)
+	}
+	path := c.Path
+	if path == "" {
+		path = c.Name
+	}
+	out, err := exec.CommandContext(ctx, path, append(append([]string(nil), c.Args...), args[1:]...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %w: %s", c.Name, err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// cmdArgs splits the command line into its arguments. Arguments are separated by
+// whitespace and may be quoted using single or double quotes. Within double quotes,
+// or outside quotes, a backslash escapes the character that follows it.
+func cmdArgs(s string) ([]string, error) {
+	var (
+		args    []string
+		arg     strings.Builder
+		hasArg  bool
+		escaped bool
+		quote   rune
+	)
+	for _, r := range s {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, hasArg = true, true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			arg.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, hasArg = r, true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if hasArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				hasArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			hasArg = true
+		}
+	}
+	switch {
+	case quote != 0:
+		return nil, fmt.Errorf("unclosed quote %q", quote)
+	case escaped:
+		return nil, errors.New("unexpected end of command after escape character")
+	case hasArg:
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
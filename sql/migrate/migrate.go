@@ -62,6 +62,16 @@ type (
 
 		// The Source that caused this change, or nil.
 		Source schema.Change
+
+		// NonTransactional indicates the change cannot be executed inside
+		// a transaction block. e.g. CREATE INDEX CONCURRENTLY in PostgreSQL.
+		// Formatters write these changes into separate migration files.
+		NonTransactional bool
+
+		// SeparateFile indicates the change should be written into a separate
+		// migration file, after the changes that precede it. e.g. validating
+		// constraints that were added as NOT VALID in PostgreSQL.
+		SeparateFile bool
 	}
 )
 
@@ -78,11 +88,20 @@ type (
 		PlanApplier
 	}
 
+	// TxDriver is a Driver that executes its statements in a database transaction.
+	// It is implemented by the sqlclient.TxClient, and used by the Executor for
+	// executing migration files in transactions (see WithTx).
+	TxDriver interface {
+		Driver
+		Commit() error
+		Rollback() error
+	}
+
 	// PlanApplier wraps the methods for planning and applying changes
 	// on the database.
 	PlanApplier interface {
 		// PlanChanges returns a migration plan for applying the given changeset.
-		PlanChanges(context.Context, string, []schema.Change) (*Plan, error)
+		PlanChanges(context.Context, string, []schema.Change, ...PlanOption) (*Plan, error)
 
 		// ApplyChanges is responsible for applying the given changeset.
 		// An error may return from ApplyChanges if the driver is unable
@@ -90,6 +109,20 @@ type (
 		ApplyChanges(context.Context, []schema.Change) error
 	}
 
+	// PlanOption configures how a driver plans a changeset. Options are
+	// defined by the drivers (e.g. online DDL settings for MySQL), and
+	// drivers ignore options they do not recognize. The PlanOption
+	// interface can be implemented outside this package as follows:
+	//
+	//	type OnlineDDL struct {
+	//		migrate.PlanOption
+	//		Lock string
+	//	}
+	//
+	PlanOption interface {
+		planOption()
+	}
+
 	// StateReader wraps the method for reading a database/schema state.
 	// The types below provides a few builtin options for reading a state
 	// from a migration directory, a static object (e.g. a parsed file).
@@ -139,6 +172,117 @@ func Conn(drv Driver, opts *schema.InspectRealmOption) StateReader {
 	})
 }
 
+// SQLFiles returns a StateReader for SQL (DDL) files. The state is read by executing the
+// statements of the files (in the given order) on the database the Driver is connected to
+// (i.e. a dev database), and inspecting it thereafter. Like replaying a migration directory,
+// the database must be clean, and it is cleaned after the state was read.
+func SQLFiles(drv Driver, files ...File) StateReader {
+	return StateReaderFunc(func(ctx context.Context) (realm *schema.Realm, err error) {
+		l, ok := drv.(schema.Locker)
+		if !ok {
+			return nil, ErrLockUnsupported
+		}
+		unlock, err := l.Lock(ctx, "atlas_sql_files_state", 0)
+		if err != nil {
+			return nil, fmt.Errorf("sql/migrate: acquiring database lock: %w", err)
+		}
+		defer unlock()
+		if err := IsClean(ctx, drv); err != nil {
+			return nil, fmt.Errorf("sql/migrate: checking database state: %w", err)
+		}
+		defer func() {
+			if err2 := Clean(ctx, drv); err2 != nil {
+				err = wrap(err2, err)
+			}
+		}()
+		for _, f := range files {
+			stmts, err := stmts(string(f.Bytes()))
+			if err != nil {
+				return nil, fmt.Errorf("sql/migrate: scanning statements from file %q: %w", f.Name(), err)
+			}
+			for _, stmt := range stmts {
+				if _, err := drv.ExecContext(ctx, stmt); err != nil {
+					return nil, fmt.Errorf("sql/migrate: executing statement %q from file %q: %w", stmt, f.Name(), err)
+				}
+			}
+		}
+		return drv.InspectRealm(ctx, nil)
+	})
+}
+
+// ParseFiles returns a StateReader for SQL (DDL) files that is read without a database. Unlike
+// SQLFiles, the state is read by applying the statements of the files (in the given order) on an
+// empty realm using the DDLParser. Statements the DDLParser cannot model fail the reading.
+func ParseFiles(p DDLParser, files ...File) StateReader {
+	return StateReaderFunc(func(context.Context) (*schema.Realm, error) {
+		return parseStmts(p, files, func(f File) ([]string, error) {
+			return stmts(string(f.Bytes()))
+		})
+	})
+}
+
+// ParseDir returns a StateReader for a migration directory that is read without a database.
+// Like ParseFiles, the state is read by parsing the statements of the migration files with
+// the DDLParser, instead of replaying them on a dev database.
+func ParseDir(p DDLParser, dir Dir) StateReader {
+	return StateReaderFunc(func(context.Context) (*schema.Realm, error) {
+		sc, ok := dir.(Scanner)
+		if !ok {
+			return nil, errors.New("sql/migrate: parse dir: no scanner available")
+		}
+		files, err := sc.Files()
+		if err != nil {
+			return nil, fmt.Errorf("sql/migrate: reading migration files: %w", err)
+		}
+		return parseStmts(p, files, sc.Stmts)
+	})
+}
+
+// parseStmts applies the statements of the files on an empty realm.
+func parseStmts(p DDLParser, files []File, scan func(File) ([]string, error)) (*schema.Realm, error) {
+	r := schema.NewRealm()
+	for _, f := range files {
+		stmts, err := scan(f)
+		if err != nil {
+			return nil, fmt.Errorf("sql/migrate: scanning statements from file %q: %w", f.Name(), err)
+		}
+		for _, stmt := range stmts {
+			if _, err := p.ParseDDL(r, stmt); err != nil {
+				return nil, fmt.Errorf("sql/migrate: parsing statement from file %q: %w", f.Name(), err)
+			}
+		}
+	}
+	return r, nil
+}
+
+type (
+	// A DDLParser builds the schema state described by DDL statements without
+	// executing them on a database. It is implemented by the drivers, and can be
+	// used for reading the state of migration files (see ParseFiles and ParseDir),
+	// or for analyzing them when no dev database is available.
+	DDLParser interface {
+		// ParseDDL applies the changes described by the statement on the realm,
+		// and returns them. Statements that cannot be modeled by the parser are
+		// reported with an UnsupportedStmtError.
+		ParseDDL(*schema.Realm, string) (schema.Changes, error)
+	}
+
+	// UnsupportedStmtError is returned by a DDLParser for statements it cannot model.
+	UnsupportedStmtError struct {
+		Stmt   string // statement text
+		Reason string // reason the statement is not supported
+	}
+)
+
+// Error implements the error interface.
+func (e UnsupportedStmtError) Error() string {
+	stmt := strings.Join(strings.Fields(e.Stmt), " ")
+	if len(stmt) > 60 {
+		stmt = stmt[:57] + "..."
+	}
+	return fmt.Sprintf("unsupported statement %q: %s", stmt, e.Reason)
+}
+
 type (
 	// Dir describes the methods needed for a Planner to manage migration files.
 	Dir interface {
@@ -186,11 +330,13 @@ type (
 	// Planner can plan the steps to take to migrate from one state to another. It uses the enclosed Dir to write
 	// those changes to versioned migration files.
 	Planner struct {
-		drv Driver    // driver to use
-		dir Dir       // where migration files are stored and read from
-		fmt Formatter // how to format a plan to migration files
-		sc  Scanner   // how to interpret a migration dir
-		sum bool      // whether to create a sum file for the migration directory
+		drv   Driver              // driver to use
+		dir   Dir                 // where migration files are stored and read from
+		fmt   Formatter           // how to format a plan to migration files
+		sc    Scanner             // how to interpret a migration dir
+		sum   bool                // whether to create a sum file for the migration directory
+		opts  []PlanOption        // options to pass to the driver planner
+		dopts []schema.DiffOption // options to pass to the driver differ
 	}
 
 	// PlannerOption allows managing a Planner using functional arguments.
@@ -226,10 +372,18 @@ type (
 
 	// Executor is responsible to manage and execute a set of migration files against a database.
 	Executor struct {
-		drv Driver             // The Driver to access and manage the database.
-		dir Dir                // The Dir with migration files to use.
-		rrw RevisionReadWriter // The RevisionReadWriter to read and write database revisions to.
-		log Logger             // The Logger to use.
+		drv  Driver             // The Driver to access and manage the database.
+		dir  Dir                // The Dir with migration files to use.
+		rrw  RevisionReadWriter // The RevisionReadWriter to read and write database revisions to.
+		log  Logger             // The Logger to use.
+		cmds []Command          // External commands to invoke for non-SQL statements.
+
+		// Opens a transaction for each migration file (see WithTx).
+		tx func(context.Context) (TxDriver, error)
+		// Replaying the directory on a dev database (see ReadState).
+		replay bool
+		// Inspect the roles created by the replay (see Planner.Plan).
+		roles bool
 	}
 
 	// ExecutorOption allows configuring an Executor using functional arguments.
@@ -262,9 +416,27 @@ func DisableChecksum() PlannerOption {
 	}
 }
 
+// WithPlanOptions sets the options that are passed to the driver when planning changes.
+func WithPlanOptions(opts ...PlanOption) PlannerOption {
+	return func(p *Planner) {
+		p.opts = append(p.opts, opts...)
+	}
+}
+
+// WithDiffOptions sets the options that are passed to the driver when diffing states.
+func WithDiffOptions(opts ...schema.DiffOption) PlannerOption {
+	return func(p *Planner) {
+		p.dopts = append(p.dopts, opts...)
+	}
+}
+
 // Plan calculates the migration Plan required for moving the current state (from) state to
 // the next state (to). A StateReader can be a directory, static schema elements or a Driver connection.
 func (p *Planner) Plan(ctx context.Context, name string, to StateReader) (*Plan, error) {
+	desired, err := to.ReadState(ctx)
+	if err != nil {
+		return nil, err
+	}
 	var from StateReader
 	if sr, ok := p.dir.(StateReader); ok {
 		from = sr
@@ -274,30 +446,29 @@ func (p *Planner) Plan(ctx context.Context, name string, to StateReader) (*Plan,
 		if err != nil {
 			return nil, err
 		}
+		// Roles are inspected only if they are managed by the desired state,
+		// as they are server-wide and require access to the system catalogs.
+		ex.roles = desired != nil && len(desired.Objects) > 0
 		from = ex
 	}
 	current, err := from.ReadState(ctx)
 	if err != nil {
 		return nil, err
 	}
-	desired, err := to.ReadState(ctx)
-	if err != nil {
-		return nil, err
-	}
-	changes, err := p.drv.RealmDiff(current, desired)
+	changes, err := schema.RealmDiff(p.drv, current, desired, p.dopts...)
 	if err != nil {
 		return nil, err
 	}
 	if len(changes) == 0 {
 		return nil, ErrNoPlan
 	}
-	return p.drv.PlanChanges(ctx, name, changes)
+	return p.drv.PlanChanges(ctx, name, changes, p.opts...)
 }
 
 // WritePlan writes the given Plan to the Dir based on the configured Formatter.
 func (p *Planner) WritePlan(plan *Plan) error {
 	// Format the plan into files.
-	files, err := p.fmt.Format(plan)
+	files, err := p.format(plan)
 	if err != nil {
 		return err
 	}
@@ -318,6 +489,40 @@ func (p *Planner) WritePlan(plan *Plan) error {
 	return nil
 }
 
+// format formats the plan into migration files. The versions of the files that are
+// generated by a TemplateFormatter follow the latest version of the directory, as
+// plans that are split into multiple files may use versions ahead of the current time.
+func (p *Planner) format(plan *Plan) ([]File, error) {
+	t, ok := p.fmt.(*TemplateFormatter)
+	if !ok {
+		return p.fmt.Format(plan)
+	}
+	sc, ok := p.dir.(Scanner)
+	if !ok {
+		return t.format(plan, time.Time{})
+	}
+	files, err := sc.Files()
+	if err != nil {
+		return nil, err
+	}
+	var latest time.Time
+	for _, f := range files {
+		v, err := sc.Version(f)
+		if err != nil {
+			return nil, err
+		}
+		// Versions that are not timestamps (e.g. 1, 2) are not taken into account.
+		m := reTimeVersion.FindStringSubmatch(v)
+		if len(m) == 0 {
+			continue
+		}
+		if vt, err := time.Parse(versionFormat, m[1]); err == nil && vt.After(latest) {
+			latest = vt
+		}
+	}
+	return t.format(plan, latest)
+}
+
 const (
 	// StateOngoing is set once a migration file has been started to be applied.
 	StateOngoing = "ongoing"
@@ -370,6 +575,17 @@ func WithLogger(log Logger) ExecutorOption {
 	}
 }
 
+// WithTx configures the Executor to execute each migration file in its own transaction,
+// opened by the given function. Files that are marked with the "atlas:txmode none"
+// directive (e.g. files with CREATE INDEX CONCURRENTLY statements) are executed
+// outside a transaction block, using the Driver of the Executor.
+func WithTx(open func(context.Context) (TxDriver, error)) ExecutorOption {
+	return func(ex *Executor) error {
+		ex.tx = open
+		return nil
+	}
+}
+
 // Lock acquires a lock for the executor.
 // It is considered a user error to not call Lock before the Pending and Execute methods.
 func (e *Executor) Lock(ctx context.Context) (schema.UnlockFunc, error) {
@@ -451,6 +667,7 @@ func (e *Executor) Pending(ctx context.Context) ([]File, error) {
 
 // Execute executes the given migration file on the database. It does not check for the database to be clean before
 // attempting to apply the changes. This behavior is required to enabled "fixing" a broken state.
+// If the Executor was configured using WithTx, the file is executed in its own transaction.
 func (e *Executor) Execute(ctx context.Context, m File) (err error) {
 	r := &Revision{ExecutedAt: time.Now(), ExecutionState: StateOngoing}
 	// Make sure to store the Revision information.
@@ -488,11 +705,43 @@ func (e *Executor) Execute(ctx context.Context, m File) (err error) {
 	if err := e.rrw.WriteRevision(ctx, r); err != nil {
 		return fmt.Errorf("sql/migrate: execute: write revision: %w", err)
 	}
+	drv := e.drv
+	if e.tx != nil && txMode(m) != txModeNone {
+		var tx TxDriver
+		if tx, err = e.tx(ctx); err != nil {
+			return r.setGoErr(fmt.Errorf("sql/migrate: execute: open transaction for file %q: %w", m.Name(), err))
+		}
+		// Commit the transaction before the revision is written.
+		defer func() {
+			if err != nil {
+				if err2 := tx.Rollback(); err2 != nil {
+					err = wrap(err2, err)
+				}
+				return
+			}
+			if err = tx.Commit(); err != nil {
+				err = r.setGoErr(fmt.Errorf("sql/migrate: execute: commit transaction of file %q: %w", m.Name(), err))
+			}
+		}()
+		drv = tx
+	}
 	for _, stmt := range stmts {
 		if e.log != nil {
 			e.log.Log(LogStmt{stmt})
 		}
-		if _, err := e.drv.ExecContext(ctx, stmt); err != nil {
+		switch c, ok := e.command(stmt); {
+		// External commands are not invoked on dev databases.
+		case e.replay:
+			var s string
+			if s, err = ReplayStmt(drv, stmt); err == nil {
+				_, err = drv.ExecContext(ctx, s)
+			}
+		case ok:
+			err = c.run(ctx, stmt)
+		default:
+			_, err = drv.ExecContext(ctx, stmt)
+		}
+		if err != nil {
 			return r.setSQLErr(
 				fmt.Errorf("sql/migrate: execute: executing statement %q from version %q: %w", stmt, r.Version, err),
 				stmt,
@@ -581,12 +830,21 @@ func (e *Executor) ReadState(ctx context.Context) (realm *schema.Realm, err erro
 			err = wrap(err2, err)
 		}
 	}()
-	// Replay the migration directory on the database.
-	if err := e.ExecuteN(ctx, 0); err != nil && !errors.Is(err, ErrNoPendingFiles) {
+	// Replay the migration directory on the database. Statements that invoke
+	// external commands are executed using their SQL equivalents, if any.
+	ex := *e
+	ex.replay = true
+	if err := ex.ExecuteN(ctx, 0); err != nil && !errors.Is(err, ErrNoPendingFiles) {
 		return nil, fmt.Errorf("sql/migrate: read migration directory state: %w", err)
 	}
 	// Inspect the database back and return the result.
-	realm, err = e.drv.InspectRealm(ctx, nil)
+	var opts *schema.InspectRealmOption
+	if e.roles {
+		opts = &schema.InspectRealmOption{
+			Mode: schema.InspectSchemas | schema.InspectTables | schema.InspectObjects | schema.InspectRoles,
+		}
+	}
+	realm, err = e.drv.InspectRealm(ctx, opts)
 	return
 }
 
@@ -815,7 +1073,7 @@ var _ File = (*LocalFile)(nil)
 
 var (
 	// templateFuncs contains the template.FuncMap for the DefaultFormatter.
-	templateFuncs = template.FuncMap{"now": func() string { return time.Now().UTC().Format("20060102150405") }}
+	templateFuncs = template.FuncMap{"now": func() string { return time.Now().UTC().Format(versionFormat) }}
 	// DefaultFormatter is a default implementation for Formatter.
 	DefaultFormatter = &TemplateFormatter{
 		templates: []struct{ N, C *template.Template }{
@@ -824,7 +1082,8 @@ var (
 					"{{ now }}{{ with .Name }}_{{ . }}{{ end }}.sql",
 				)),
 				C: template.Must(template.New("").Funcs(templateFuncs).Parse(
-					`{{ range .Changes }}{{ with .Comment }}-- {{ println . }}{{ end }}{{ printf "%s;\n" .Cmd }}{{ end }}`,
+					`{{ with .Changes }}{{ if (index . 0).NonTransactional }}{{ println "-- atlas:txmode none\n" }}{{ end }}{{ end }}` +
+						`{{ range .Changes }}{{ with .Comment }}-- {{ println . }}{{ end }}{{ printf "%s;\n" .Cmd }}{{ end }}`,
 				)),
 			},
 		},
@@ -855,24 +1114,89 @@ func NewTemplateFormatter(templates ...*template.Template) (*TemplateFormatter,
 }
 
 // Format implements the Formatter interface.
+//
+// Non-transactional changes, and changes that are marked to be written to separate
+// files, are written to their own files. In this case, the
+// "now" function returns sequential versions for the files of the plan.
 func (t *TemplateFormatter) Format(plan *Plan) ([]File, error) {
-	files := make([]File, 0, len(t.templates))
-	for _, tpl := range t.templates {
-		var n, c bytes.Buffer
-		if err := tpl.N.Execute(&n, plan); err != nil {
-			return nil, err
-		}
-		if err := tpl.C.Execute(&c, plan); err != nil {
-			return nil, err
+	return t.format(plan, time.Time{})
+}
+
+// format formats the plan into files. The versions returned by the "now" function
+// are ensured to follow the given latest version (e.g. of the migration directory).
+func (t *TemplateFormatter) format(plan *Plan, latest time.Time) ([]File, error) {
+	var (
+		plans = splitPlan(plan)
+		files = make([]File, 0, len(t.templates)*len(plans))
+		names = make(map[string]bool)
+		now   = time.Now().UTC().Truncate(time.Second)
+		after = !latest.Before(now)
+	)
+	if after {
+		now = latest.Add(time.Second)
+	}
+	for i, p := range plans {
+		for _, tpl := range t.templates {
+			nt, ct := tpl.N, tpl.C
+			if len(plans) > 1 || after {
+				var err error
+				if nt, err = withNow(nt, now.Add(time.Duration(i)*time.Second)); err != nil {
+					return nil, err
+				}
+				if ct, err = withNow(ct, now.Add(time.Duration(i)*time.Second)); err != nil {
+					return nil, err
+				}
+			}
+			var n, c bytes.Buffer
+			if err := nt.Execute(&n, p); err != nil {
+				return nil, err
+			}
+			if err := ct.Execute(&c, p); err != nil {
+				return nil, err
+			}
+			if len(plans) > 1 && names[n.String()] {
+				return nil, fmt.Errorf("sql/migrate: duplicate migration file name %q", n.String())
+			}
+			names[n.String()] = true
+			files = append(files, &templateFile{
+				Buffer: &c,
+				n:      n.String(),
+			})
 		}
-		files = append(files, &templateFile{
-			Buffer: &c,
-			n:      n.String(),
-		})
 	}
 	return files, nil
 }
 
+// splitPlan splits the plan into consecutive plans, where non-transactional
+// changes and changes marked as SeparateFile are placed in their own plans.
+func splitPlan(plan *Plan) []*Plan {
+	var plans []*Plan
+	for i, c := range plan.Changes {
+		if i == 0 || c.NonTransactional != plan.Changes[i-1].NonTransactional || c.SeparateFile != plan.Changes[i-1].SeparateFile {
+			plans = append(plans, &Plan{
+				Name:          plan.Name,
+				Reversible:    plan.Reversible,
+				Transactional: plan.Transactional && !c.NonTransactional,
+			})
+		}
+		p := plans[len(plans)-1]
+		p.Changes = append(p.Changes, c)
+	}
+	if len(plans) < 2 {
+		return []*Plan{plan}
+	}
+	return plans
+}
+
+// withNow returns a copy of the template with a "now" function that returns the given time.
+func withNow(t *template.Template, now time.Time) (*template.Template, error) {
+	t, err := t.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return t.Funcs(template.FuncMap{"now": func() string { return now.Format(versionFormat) }}), nil
+}
+
 type templateFile struct {
 	*bytes.Buffer
 	n string
@@ -886,8 +1210,37 @@ const (
 	HashFileName = "atlas.sum"
 	// Directive used it a file should be excluded by the sum computation.
 	directiveNone = "ignore"
+	// Directive used if the statements of a file cannot be executed
+	// inside a transaction block. e.g. CREATE INDEX CONCURRENTLY.
+	txModeNone = "none"
+	// Format of the versions generated by the "now" template function.
+	versionFormat = "20060102150405"
 )
 
+// Determine if a version is a timestamp generated by the "now" template function.
+// Versions may be prefixed, e.g. V20220101000000 in Flyway.
+var reTimeVersion = regexp.MustCompile(`^[a-zA-Z]*(\d{14})`)
+
+// Determine if an "atlas:txmode" directive is used on the file.
+var reTxModeDirective = regexp.MustCompile(`^--\s*atlas:txmode ([a-zA-Z-]*)`)
+
+// txMode returns the transaction mode set by the "atlas:txmode" directive
+// in the header (leading comments) of the file, or an empty string.
+func txMode(f File) string {
+	for _, l := range strings.Split(string(f.Bytes()), "\n") {
+		switch l = strings.TrimSpace(l); {
+		case l == "":
+		case !strings.HasPrefix(l, "--"):
+			return ""
+		default:
+			if m := reTxModeDirective.FindStringSubmatch(l); len(m) > 0 {
+				return m[1]
+			}
+		}
+	}
+	return ""
+}
+
 // Determine if an "atlas:sum" directive is used on the file.
 var reSumDirective = regexp.MustCompile(`atlas:sum ([a-zA-Z-]*)`)
 
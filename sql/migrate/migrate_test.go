@@ -14,6 +14,8 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 	"text/template"
 	"time"
@@ -60,6 +62,79 @@ func TestPlanner_WritePlan(t *testing.T) {
 	requireFileEqual(t, d, "add_t1_and_t2.down.sql", "DROP TABLE t1 IF EXISTS\nDROP TABLE t2\n")
 }
 
+func TestTemplateFormatter_NonTransactional(t *testing.T) {
+	plan := &migrate.Plan{
+		Name:          "add_index",
+		Transactional: false,
+		Changes: []*migrate.Change{
+			{Cmd: "CREATE TABLE t1(c int)"},
+			{Cmd: "CREATE INDEX CONCURRENTLY i1 ON t2(c)", NonTransactional: true},
+			{Cmd: "CREATE INDEX CONCURRENTLY i2 ON t2(c)", NonTransactional: true},
+			{Cmd: "CREATE TABLE t3(c int)"},
+		},
+	}
+	files, err := migrate.DefaultFormatter.Format(plan)
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+	var versions []string
+	for _, f := range files {
+		v := strings.SplitN(f.Name(), "_", 2)
+		require.Equal(t, "add_index.sql", v[1])
+		versions = append(versions, v[0])
+	}
+	require.True(t, sort.StringsAreSorted(versions))
+	require.NotEqual(t, versions[0], versions[1])
+	require.NotEqual(t, versions[1], versions[2])
+	require.Equal(t, "CREATE TABLE t1(c int);\n", string(files[0].Bytes()))
+	require.Equal(t, "-- atlas:txmode none\n\nCREATE INDEX CONCURRENTLY i1 ON t2(c);\nCREATE INDEX CONCURRENTLY i2 ON t2(c);\n", string(files[1].Bytes()))
+	require.Equal(t, "CREATE TABLE t3(c int);\n", string(files[2].Bytes()))
+
+	// Split files must have unique names.
+	f, err := migrate.NewTemplateFormatter(
+		template.Must(template.New("").Parse("{{ .Name }}.sql")),
+		template.Must(template.New("").Parse("{{ range .Changes }}{{ println .Cmd }}{{ end }}")),
+	)
+	require.NoError(t, err)
+	_, err = f.Format(plan)
+	require.EqualError(t, err, `sql/migrate: duplicate migration file name "add_index.sql"`)
+
+	// Versions follow the latest version of the directory.
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	latest := time.Now().UTC().Add(time.Hour).Format("20060102150405")
+	require.NoError(t, d.WriteFile(latest+"_init.sql", []byte("CREATE TABLE t2(c int);\n")))
+	pl := migrate.NewPlanner(nil, d, migrate.DisableChecksum())
+	require.NoError(t, pl.WritePlan(plan))
+	files, err = d.Files()
+	require.NoError(t, err)
+	require.Len(t, files, 4)
+	require.Equal(t, latest+"_init.sql", files[0].Name())
+	for i, f := range files[1:] {
+		v, err := time.Parse("20060102150405", strings.SplitN(f.Name(), "_", 2)[0])
+		require.NoError(t, err)
+		l, err := time.Parse("20060102150405", latest)
+		require.NoError(t, err)
+		require.Equal(t, l.Add(time.Duration(i+1)*time.Second), v)
+	}
+}
+
+func TestTemplateFormatter_SeparateFile(t *testing.T) {
+	plan := &migrate.Plan{
+		Name:          "add_check",
+		Transactional: true,
+		Changes: []*migrate.Change{
+			{Cmd: "ALTER TABLE t1 ADD CONSTRAINT c1 CHECK (c > 0) NOT VALID"},
+			{Cmd: "ALTER TABLE t1 VALIDATE CONSTRAINT c1", SeparateFile: true},
+		},
+	}
+	files, err := migrate.DefaultFormatter.Format(plan)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	require.NotEqual(t, files[0].Name(), files[1].Name())
+	require.Equal(t, "ALTER TABLE t1 ADD CONSTRAINT c1 CHECK (c > 0) NOT VALID;\n", string(files[0].Bytes()))
+	require.Equal(t, "ALTER TABLE t1 VALIDATE CONSTRAINT c1;\n", string(files[1].Bytes()))
+}
+
 func TestPlanner_Plan(t *testing.T) {
 	var (
 		drv = &lockMockDriver{&mockDriver{}}
@@ -88,6 +163,19 @@ func TestPlanner_Plan(t *testing.T) {
 	plan, err = pl.Plan(ctx, "", migrate.Realm(nil))
 	require.NoError(t, err)
 	require.Equal(t, drv.plan, plan)
+	for _, o := range drv.inspected {
+		require.Nil(t, o)
+	}
+
+	// Roles are inspected if the desired state defines them.
+	drv.inspected = nil
+	_, err = pl.Plan(ctx, "", migrate.Realm(schema.NewRealm().AddObjects(&mockRole{})))
+	require.NoError(t, err)
+	var roles bool
+	for _, o := range drv.inspected {
+		roles = roles || o != nil && o.Mode.Is(schema.InspectRoles)
+	}
+	require.True(t, roles)
 }
 
 func TestHashSum(t *testing.T) {
@@ -259,6 +347,98 @@ func TestExecutor_ReadState(t *testing.T) {
 	require.True(t, drv.released())
 }
 
+func TestExecutor_ReadStateReplay(t *testing.T) {
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, d.WriteFile("1_osc.sql", []byte("CREATE TABLE t(c int);\ngh-ost --table=t --alter=\"ADD COLUMN d int\" --execute;\n")))
+	sum, err := migrate.HashSum(d)
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	drv := &translateMockDriver{&lockMockDriver{&mockDriver{}}}
+	ex, err := migrate.NewExecutor(drv, d, migrate.NopRevisionReadWriter{}, migrate.WithCommands(migrate.Command{Name: "gh-ost", Path: "/does/not/exist"}))
+	require.NoError(t, err)
+	_, err = ex.ReadState(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"CREATE TABLE t(c int);", "ALTER TABLE t ADD COLUMN d int"}, drv.executed)
+
+	require.NoError(t, d.WriteFile("2_osc.sql", []byte("gh-ost --execute;\n")))
+	sum, err = migrate.HashSum(d)
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(d, sum))
+	_, err = ex.ReadState(context.Background())
+	require.EqualError(t, err, "sql/migrate: read migration directory state: sql/migrate: execute: executing statement \"gh-ost --execute;\" from version \"2\": missing alter")
+}
+
+func TestSQLFiles(t *testing.T) {
+	ctx := context.Background()
+	files := []migrate.File{
+		migrate.NewLocalFile("schema.sql", []byte("CREATE TABLE t1(c int);\n-- comment\nCREATE TABLE t2(\n  c int\n);\n")),
+		migrate.NewLocalFile("views.sql", []byte("CREATE VIEW v AS SELECT c FROM t1;")),
+	}
+
+	// Locking not supported.
+	_, err := migrate.SQLFiles(&mockDriver{}, files...).ReadState(ctx)
+	require.ErrorIs(t, err, migrate.ErrLockUnsupported)
+
+	// Does not work if database is not clean.
+	drv := &lockMockDriver{&mockDriver{realm: schema.Realm{Schemas: []*schema.Schema{{Name: "schema"}}}}}
+	_, err = migrate.SQLFiles(drv, files...).ReadState(ctx)
+	require.ErrorAs(t, err, &migrate.NotCleanError{})
+	require.Empty(t, drv.executed)
+	require.True(t, drv.released())
+
+	// Works.
+	edrv := &emptyMockDriver{drv}
+	realm, err := migrate.SQLFiles(edrv, files...).ReadState(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &drv.realm, realm)
+	require.Equal(t, []string{"CREATE TABLE t1(c int);", "CREATE TABLE t2(\n  c int\n);", "CREATE VIEW v AS SELECT c FROM t1;"}, drv.executed)
+	require.Equal(t, []schema.Change{
+		&schema.DropSchema{
+			S:     &schema.Schema{Name: "schema"},
+			Extra: []schema.Clause{&schema.IfExists{}},
+		},
+	}, edrv.applied)
+	require.True(t, drv.released())
+}
+
+func TestParseFiles(t *testing.T) {
+	ctx := context.Background()
+	files := []migrate.File{
+		migrate.NewLocalFile("schema.sql", []byte("CREATE TABLE t1(c int);\n-- comment\nCREATE TABLE t2(\n  c int\n);\n")),
+		migrate.NewLocalFile("views.sql", []byte("CREATE VIEW v AS SELECT c FROM t1;")),
+	}
+	p := &mockDDLParser{}
+	realm, err := migrate.ParseFiles(p, files[0]).ReadState(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"CREATE TABLE t1(c int);", "CREATE TABLE t2(\n  c int\n);"}, p.parsed)
+	require.Len(t, realm.Schemas, 1)
+	require.Len(t, realm.Schemas[0].Tables, 2)
+
+	// Unsupported statements fail the reading.
+	p = &mockDDLParser{}
+	_, err = migrate.ParseFiles(p, files...).ReadState(ctx)
+	require.ErrorAs(t, err, &migrate.UnsupportedStmtError{})
+	require.EqualError(t, err, `sql/migrate: parsing statement from file "views.sql": unsupported statement "CREATE VIEW v AS SELECT c FROM t1;": view`)
+}
+
+func TestParseDir(t *testing.T) {
+	ctx := context.Background()
+	d, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	// Scanning statements is required.
+	_, err = migrate.ParseDir(&mockDDLParser{}, struct{ migrate.Dir }{d}).ReadState(ctx)
+	require.EqualError(t, err, "sql/migrate: parse dir: no scanner available")
+
+	require.NoError(t, d.WriteFile("1.sql", []byte("CREATE TABLE t1(c int);")))
+	require.NoError(t, d.WriteFile("2.sql", []byte("CREATE TABLE t2(c int);")))
+	p := &mockDDLParser{}
+	realm, err := migrate.ParseDir(p, d).ReadState(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"CREATE TABLE t1(c int);", "CREATE TABLE t2(c int);"}, p.parsed)
+	require.Len(t, realm.Schemas[0].Tables, 2)
+}
+
 func TestLocalDir(t *testing.T) {
 	// Files don't work.
 	d, err := migrate.NewLocalDir("migrate.go")
@@ -469,6 +649,106 @@ func TestExecutor(t *testing.T) {
 	require.True(t, drv.released())
 }
 
+func TestExecutor_Commands(t *testing.T) {
+	var (
+		tmp    = t.TempDir()
+		out    = filepath.Join(tmp, "args")
+		script = filepath.Join(tmp, "gh-ost")
+	)
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\nprintf '%s\\n' \"$@\" > "+out+"\n"), 0700))
+	dir, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, dir.WriteFile("1_osc.sql", []byte("CREATE TABLE t(c int);\n# Leading comments and hints are ignored.\n/*+ hint */ gh-ost --table=\"t\" --alter=\"ADD COLUMN \\`d\\` int DEFAULT 'a;b'\" --execute;\n")))
+	sum, err := migrate.HashSum(dir)
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(dir, sum))
+
+	_, err = migrate.NewExecutor(&lockMockDriver{&mockDriver{}}, dir, &mockRevisionReadWriter{}, migrate.WithCommands(migrate.Command{}))
+	require.EqualError(t, err, "sql/migrate: execute: command name cannot be empty")
+
+	drv := &lockMockDriver{&mockDriver{}}
+	ex, err := migrate.NewExecutor(drv, dir, &mockRevisionReadWriter{}, migrate.WithCommands(migrate.Command{
+		Name: "gh-ost",
+		Path: script,
+		Args: []string{"--host=localhost"},
+	}))
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(context.Background(), 0))
+	require.Equal(t, []string{"CREATE TABLE t(c int);"}, drv.executed)
+	args, err := os.ReadFile(out)
+	require.NoError(t, err)
+	require.Equal(t, "--host=localhost\n--table=t\n--alter=ADD COLUMN `d` int DEFAULT 'a;b'\n--execute\n", string(args))
+
+	// Command errors are recorded on the revision.
+	rrw := &mockRevisionReadWriter{}
+	ex, err = migrate.NewExecutor(&lockMockDriver{&mockDriver{}}, dir, rrw, migrate.WithCommands(migrate.Command{Name: "gh-ost", Path: "false"}))
+	require.NoError(t, err)
+	require.Error(t, ex.ExecuteN(context.Background(), 0))
+	require.Len(t, *rrw, 1)
+	require.Equal(t, migrate.StateError, (*rrw)[0].ExecutionState)
+	require.Contains(t, (*rrw)[0].Error, "gh-ost: exit status 1")
+}
+
+func TestExecutor_Tx(t *testing.T) {
+	dir, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, dir.WriteFile("1_add_check.sql", []byte("ALTER TABLE t ADD CONSTRAINT c CHECK (c > 0) NOT VALID;\n")))
+	require.NoError(t, dir.WriteFile("2_add_index.sql", []byte("-- atlas:txmode none\n\nCREATE INDEX CONCURRENTLY i ON t(c);\n")))
+	require.NoError(t, dir.WriteFile("3_validate_check.sql", []byte("ALTER TABLE t VALIDATE CONSTRAINT c;\n")))
+	sum, err := migrate.HashSum(dir)
+	require.NoError(t, err)
+	require.NoError(t, migrate.WriteSumFile(dir, sum))
+
+	var (
+		txs []*mockTxDriver
+		drv = &lockMockDriver{&mockDriver{}}
+		rrw = &mockRevisionReadWriter{}
+	)
+	ex, err := migrate.NewExecutor(drv, dir, rrw, migrate.WithTx(func(context.Context) (migrate.TxDriver, error) {
+		txs = append(txs, &mockTxDriver{mockDriver: &mockDriver{}})
+		return txs[len(txs)-1], nil
+	}))
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(context.Background(), 0))
+	// Each file is executed in its own transaction, except
+	// the one that is marked as non-transactional.
+	require.Len(t, txs, 2)
+	require.Equal(t, []string{"ALTER TABLE t ADD CONSTRAINT c CHECK (c > 0) NOT VALID;"}, txs[0].executed)
+	require.True(t, txs[0].committed)
+	require.Equal(t, []string{"ALTER TABLE t VALIDATE CONSTRAINT c;"}, txs[1].executed)
+	require.True(t, txs[1].committed)
+	require.Equal(t, []string{"CREATE INDEX CONCURRENTLY i ON t(c);"}, drv.executed)
+	require.Len(t, *rrw, 3)
+
+	// Failed files are rolled back.
+	txs, rrw = nil, &mockRevisionReadWriter{}
+	ex, err = migrate.NewExecutor(&lockMockDriver{&mockDriver{}}, dir, rrw, migrate.WithTx(func(context.Context) (migrate.TxDriver, error) {
+		txs = append(txs, &mockTxDriver{mockDriver: &mockDriver{}, err: errors.New("exec error")})
+		return txs[len(txs)-1], nil
+	}))
+	require.NoError(t, err)
+	require.EqualError(t, ex.ExecuteN(context.Background(), 0), `sql/migrate: execute: executing statement "ALTER TABLE t ADD CONSTRAINT c CHECK (c > 0) NOT VALID;" from version "1": exec error`)
+	require.Len(t, txs, 1)
+	require.False(t, txs[0].committed)
+	require.True(t, txs[0].rolledback)
+	require.Equal(t, migrate.StateError, (*rrw)[0].ExecutionState)
+}
+
+func TestStmtCommand(t *testing.T) {
+	for stmt, name := range map[string]string{
+		"":                                    "",
+		"-- comment":                          "",
+		"/* unclosed":                         "",
+		"ALTER TABLE t ADD COLUMN c int":      "ALTER",
+		"-- comment\ngh-ost --execute":        "gh-ost",
+		"# comment\n\n  gh-ost --execute":     "gh-ost",
+		"/*+ hint */ pt-online-schema-change": "pt-online-schema-change",
+		"/* a */ -- b\n/* c */\tgh-ost":       "gh-ost",
+	} {
+		require.Equal(t, name, migrate.StmtCommand(stmt), stmt)
+	}
+}
+
 type (
 	mockDriver struct {
 		migrate.Driver
@@ -477,6 +757,7 @@ type (
 		applied       []schema.Change
 		realm         schema.Realm
 		executed      []string
+		inspected     []*schema.InspectRealmOption
 		locks         map[string]struct{}
 		lockCounter   int
 		unlockCounter int
@@ -490,13 +771,38 @@ func (m *mockDriver) ExecContext(_ context.Context, query string, _ ...interface
 	return nil, nil
 }
 
-func (m *mockDriver) InspectRealm(context.Context, *schema.InspectRealmOption) (*schema.Realm, error) {
+// mockTxDriver records the statements executed in a transaction.
+type mockTxDriver struct {
+	*mockDriver
+	err                   error
+	committed, rolledback bool
+}
+
+func (m *mockTxDriver) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.mockDriver.ExecContext(ctx, query, args...)
+}
+
+func (m *mockTxDriver) Commit() error {
+	m.committed = true
+	return nil
+}
+
+func (m *mockTxDriver) Rollback() error {
+	m.rolledback = true
+	return nil
+}
+
+func (m *mockDriver) InspectRealm(_ context.Context, opts *schema.InspectRealmOption) (*schema.Realm, error) {
+	m.inspected = append(m.inspected, opts)
 	return &m.realm, nil
 }
 func (m *mockDriver) RealmDiff(_, _ *schema.Realm) ([]schema.Change, error) {
 	return m.changes, nil
 }
-func (m *mockDriver) PlanChanges(context.Context, string, []schema.Change) (*migrate.Plan, error) {
+func (m *mockDriver) PlanChanges(context.Context, string, []schema.Change, ...migrate.PlanOption) (*migrate.Plan, error) {
 	return m.plan, nil
 }
 func (m *mockDriver) ApplyChanges(_ context.Context, changes []schema.Change) error {
@@ -525,6 +831,53 @@ func (m *emptyMockDriver) IsClean(context.Context) error {
 	return nil
 }
 
+// mockRole is a realm-level object, such as a role or a user.
+type mockRole struct{ schema.Object }
+
+// translateMockDriver translates gh-ost commands into ALTER TABLE statements.
+type translateMockDriver struct{ *lockMockDriver }
+
+func (m *translateMockDriver) TranslateCommand(stmt string) (string, bool, error) {
+	if migrate.StmtCommand(stmt) != "gh-ost" {
+		return "", false, nil
+	}
+	args, err := migrate.CommandArgs(stmt)
+	if err != nil {
+		return "", false, err
+	}
+	var table, alter string
+	for _, a := range args {
+		if strings.HasPrefix(a, "--table=") {
+			table = strings.TrimPrefix(a, "--table=")
+		}
+		if strings.HasPrefix(a, "--alter=") {
+			alter = strings.TrimPrefix(a, "--alter=")
+		}
+	}
+	if table == "" || alter == "" {
+		return "", false, errors.New("missing alter")
+	}
+	return "ALTER TABLE " + table + " " + alter, true, nil
+}
+
+// mockDDLParser adds a table for each CREATE TABLE statement to the "main" schema.
+type mockDDLParser struct{ parsed []string }
+
+func (m *mockDDLParser) ParseDDL(r *schema.Realm, stmt string) (schema.Changes, error) {
+	if !strings.HasPrefix(stmt, "CREATE TABLE") {
+		return nil, migrate.UnsupportedStmtError{Stmt: stmt, Reason: "view"}
+	}
+	m.parsed = append(m.parsed, stmt)
+	if len(r.Schemas) == 0 {
+		s := schema.New("main")
+		s.SetRealm(r)
+		r.Schemas = append(r.Schemas, s)
+	}
+	t := schema.NewTable(fmt.Sprintf("t%d", len(m.parsed)))
+	r.Schemas[0].AddTables(t)
+	return schema.Changes{&schema.AddTable{T: t}}, nil
+}
+
 type mockRevisionReadWriter migrate.Revisions
 
 func (rrw *mockRevisionReadWriter) WriteRevision(_ context.Context, r *migrate.Revision) error {
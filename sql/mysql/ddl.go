@@ -0,0 +1,190 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// NewDDLParser returns a migrate.DDLParser for MySQL statements. Tables
+// with unqualified names are created in the schema with the given name.
+func NewDDLParser(name string) migrate.DDLParser {
+	return &sqlx.DDLParser{
+		DDLDriver:    ddl{},
+		Schema:       name,
+		Quotes:       "`",
+		Escapes:      true,
+		HashComments: true,
+	}
+}
+
+// ddl implements the sqlx.DDLDriver interface for MySQL.
+type ddl struct{}
+
+// ParseType implements sqlx.DDLDriver.
+func (ddl) ParseType(t string) (schema.Type, error) {
+	if t == "" {
+		return nil, errors.New("missing column type")
+	}
+	// Normalize synonyms to the form that is
+	// returned by the INFORMATION_SCHEMA.
+	switch t {
+	case "integer":
+		t = TypeInt
+	case "dec":
+		t = TypeDecimal
+	}
+	return ParseType(t)
+}
+
+// DefaultExpr implements sqlx.DDLDriver.
+func (ddl) DefaultExpr(c *schema.Column, x string) schema.Expr {
+	switch {
+	case sqlx.IsLiteralBool(x), sqlx.IsLiteralNumber(x), sqlx.IsQuoted(x, '\'', '"'), isHex(x):
+		return &schema.Literal{V: x}
+	case reCurrTimestamp.MatchString(x):
+		return &schema.RawExpr{X: x}
+	default:
+		return &schema.RawExpr{X: sqlx.MayWrap(x)}
+	}
+}
+
+// ParseOption implements sqlx.DDLDriver.
+func (d ddl) ParseOption(s *sqlx.DDLScanner, v interface{}) (bool, error) {
+	switch v := v.(type) {
+	case *schema.Table:
+		return d.tableOption(s, v)
+	case *schema.Column:
+		switch {
+		case s.Accept("AUTO_INCREMENT"):
+			v.AddAttrs(&AutoIncrement{})
+		case s.Accept("ON", "UPDATE"):
+			x, err := s.Term()
+			if err != nil {
+				return false, err
+			}
+			v.AddAttrs(&OnUpdate{A: x})
+		case s.Accept("VISIBLE"), s.Accept("INVISIBLE"):
+		default:
+			return false, nil
+		}
+	case *schema.Index:
+		switch {
+		case s.Is("FULLTEXT"), s.Is("SPATIAL"), s.Accept("USING"):
+			t, err := s.Value()
+			if err != nil {
+				return false, err
+			}
+			v.AddAttrs(&IndexType{T: strings.ToUpper(t)})
+		case s.Accept("COMMENT"):
+			c, err := s.Str()
+			if err != nil {
+				return false, err
+			}
+			v.SetComment(c)
+		case s.Accept("VISIBLE"), s.Accept("INVISIBLE"):
+		case s.Accept("KEY_BLOCK_SIZE"), s.Accept("ALGORITHM"), s.Accept("LOCK"):
+			s.Accept("=")
+			if _, err := s.Value(); err != nil {
+				return false, err
+			}
+		default:
+			return false, nil
+		}
+	case *schema.IndexPart:
+		if !s.Accept("(") {
+			return false, nil
+		}
+		n, err := s.Int()
+		if err != nil {
+			return false, err
+		}
+		v.AddAttrs(&SubPart{Len: int(n)})
+		return true, s.Expect(")")
+	case *schema.Check:
+		switch {
+		case s.Accept("ENFORCED"):
+			v.AddAttrs(&Enforced{V: true})
+		case s.Accept("NOT", "ENFORCED"):
+			v.AddAttrs(&Enforced{V: false})
+		default:
+			return false, nil
+		}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// tableOptions holds the table options that are not modeled by the parser.
+var tableOptions = []string{
+	"ENGINE", "ROW_FORMAT", "KEY_BLOCK_SIZE", "AVG_ROW_LENGTH", "MAX_ROWS", "MIN_ROWS", "CHECKSUM", "COMPRESSION",
+	"DELAY_KEY_WRITE", "ENCRYPTION", "INSERT_METHOD", "PACK_KEYS", "STATS_AUTO_RECALC", "STATS_PERSISTENT", "STATS_SAMPLE_PAGES",
+}
+
+// tableOption parses the table options (e.g. ENGINE or AUTO_INCREMENT).
+func (ddl) tableOption(s *sqlx.DDLScanner, t *schema.Table) (bool, error) {
+	if s.Accept("AUTO_INCREMENT") {
+		s.Accept("=")
+		n, err := s.Int()
+		if err != nil {
+			return false, err
+		}
+		t.AddAttrs(&AutoIncrement{V: n})
+		return true, nil
+	}
+	for _, o := range tableOptions {
+		if s.Accept(o) {
+			s.Accept("=")
+			_, err := s.Value()
+			return err == nil, err
+		}
+	}
+	return false, nil
+}
+
+// DefaultName implements sqlx.DDLDriver.
+func (ddl) DefaultName(t *schema.Table, v interface{}) string {
+	switch v := v.(type) {
+	case *schema.Index:
+		if v == t.PrimaryKey {
+			return "PRIMARY"
+		}
+		// Unnamed indexes are named after their first column.
+		name := "functional_index"
+		if len(v.Parts) > 0 && v.Parts[0].C != nil {
+			name = v.Parts[0].C.Name
+		}
+		if _, ok := t.Index(name); !ok {
+			return name
+		}
+		for i := 2; ; i++ {
+			if _, ok := t.Index(fmt.Sprintf("%s_%d", name, i)); !ok {
+				return fmt.Sprintf("%s_%d", name, i)
+			}
+		}
+	case *schema.ForeignKey:
+		for i := 1; ; i++ {
+			if _, ok := t.ForeignKey(fmt.Sprintf("%s_ibfk_%d", t.Name, i)); !ok {
+				return fmt.Sprintf("%s_ibfk_%d", t.Name, i)
+			}
+		}
+	case *schema.Check:
+		var n int
+		for _, a := range t.Attrs {
+			if _, ok := a.(*schema.Check); ok {
+				n++
+			}
+		}
+		return fmt.Sprintf("%s_chk_%d", t.Name, n)
+	}
+	return ""
+}
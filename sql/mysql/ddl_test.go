@@ -0,0 +1,113 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package mysql
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDDLParser(t *testing.T) {
+	var (
+		r = &schema.Realm{}
+		p = NewDDLParser("test")
+	)
+	for _, stmt := range []string{
+		"SET NAMES utf8mb4",
+		"CREATE DATABASE IF NOT EXISTS `test` CHARACTER SET utf8mb4",
+		"CREATE TABLE `users` (\n  `id` bigint unsigned NOT NULL AUTO_INCREMENT,\n  `name` varchar(255) NOT NULL COMMENT 'user name',\n  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,\n  PRIMARY KEY (`id`),\n  KEY (`name`(10))\n) ENGINE=InnoDB AUTO_INCREMENT=100 DEFAULT CHARSET=utf8mb4 # users table.",
+		"CREATE TABLE `posts` (`id` int PRIMARY KEY, `user_id` bigint unsigned, `title` text, FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE, CHECK (`id` > 0) NOT ENFORCED)",
+		"ALTER TABLE `posts` ADD FULLTEXT INDEX `title` (`title`), MODIFY COLUMN `title` text NOT NULL",
+	} {
+		_, err := p.ParseDDL(r, stmt)
+		require.NoError(t, err, stmt)
+	}
+	require.Len(t, r.Schemas, 1)
+	s := r.Schemas[0]
+	require.Equal(t, "test", s.Name)
+	require.Equal(t, []schema.Attr{&schema.Charset{V: "utf8mb4"}}, s.Attrs)
+
+	users, ok := s.Table("users")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&AutoIncrement{V: 100}, &schema.Charset{V: "utf8mb4"}}, users.Attrs)
+	id, ok := users.Column("id")
+	require.True(t, ok)
+	require.Equal(t, &schema.ColumnType{Raw: "bigint unsigned", Type: &schema.IntegerType{T: TypeBigInt, Unsigned: true}}, id.Type)
+	require.Equal(t, []schema.Attr{&AutoIncrement{}}, id.Attrs)
+	createdAt, ok := users.Column("created_at")
+	require.True(t, ok)
+	require.Equal(t, &schema.RawExpr{X: "CURRENT_TIMESTAMP"}, createdAt.Default)
+	require.Equal(t, []schema.Attr{&OnUpdate{A: "CURRENT_TIMESTAMP"}}, createdAt.Attrs)
+	require.Equal(t, "PRIMARY", users.PrimaryKey.Name)
+	name, ok := users.Index("name")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&SubPart{Len: 10}}, name.Parts[0].Attrs)
+
+	posts, ok := s.Table("posts")
+	require.True(t, ok)
+	require.Equal(t, "posts_ibfk_1", posts.ForeignKeys[0].Symbol)
+	require.Equal(t, users, posts.ForeignKeys[0].RefTable)
+	require.Equal(t, []schema.Attr{&schema.Check{Name: "posts_chk_1", Expr: "`id` > 0", Attrs: []schema.Attr{&Enforced{V: false}}}}, posts.Attrs)
+	title, ok := posts.Index("title")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&IndexType{T: IndexTypeFullText}}, title.Attrs)
+	c, ok := posts.Column("title")
+	require.True(t, ok)
+	require.False(t, c.Type.Null)
+}
+
+func TestDDLParser_Statements(t *testing.T) {
+	var (
+		r = &schema.Realm{}
+		p = NewDDLParser("test")
+	)
+	for _, stmt := range []string{
+		"CREATE TABLE `users` (`id` int, `name` varchar(255))",
+		"ALTER TABLE `users` ADD COLUMN `email` varchar(255) NOT NULL AFTER `id`, ADD `age` int FIRST",
+		"ALTER TABLE `users` MODIFY COLUMN `age` bigint NULL AFTER `name`",
+	} {
+		_, err := p.ParseDDL(r, stmt)
+		require.NoError(t, err, stmt)
+	}
+	users, ok := r.Schemas[0].Table("users")
+	require.True(t, ok)
+	require.Len(t, users.Columns, 4)
+	email, ok := users.Column("email")
+	require.True(t, ok)
+	require.False(t, email.Type.Null)
+	age, ok := users.Column("age")
+	require.True(t, ok)
+	require.Equal(t, "bigint", age.Type.Raw)
+
+	changes, err := p.ParseDDL(r, "CREATE TABLE `pets` (`id` int)")
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	changes, err = p.ParseDDL(r, "RENAME TABLE `users` TO `people`, `pets` TO `animals`")
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	rename := changes[0].(*schema.RenameTable)
+	require.Equal(t, "users", rename.From.Name)
+	require.Equal(t, "people", rename.To.Name)
+	require.Equal(t, users, rename.To)
+	rename = changes[1].(*schema.RenameTable)
+	require.Equal(t, "pets", rename.From.Name)
+	require.Equal(t, "animals", rename.To.Name)
+	_, err = p.ParseDDL(r, "RENAME TABLE `people` TO `animals`")
+	require.EqualError(t, err, `table "animals" already exists`)
+
+	// Unqualified names are resolved in the schema selected by USE.
+	changes, err = p.ParseDDL(r, "USE `other`")
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	_, err = p.ParseDDL(r, "CREATE TABLE `users` (`id` int)")
+	require.NoError(t, err)
+	require.Len(t, r.Schemas, 2)
+	require.Equal(t, "other", r.Schemas[1].Name)
+	_, ok = r.Schemas[1].Table("users")
+	require.True(t, ok)
+}
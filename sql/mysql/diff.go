@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"unicode"
 
 	"ariga.io/atlas/sql/internal/sqlx"
 	"ariga.io/atlas/sql/schema"
@@ -48,6 +49,24 @@ func (d *diff) SchemaAttrDiff(from, to *schema.Schema) []schema.Change {
 	return changes
 }
 
+// RealmObjectDiff returns a changeset for migrating realm-level objects (e.g. roles) from one state to the other.
+func (d *diff) RealmObjectDiff(from, to *schema.Realm, opts *schema.DiffOptions) ([]schema.Change, error) {
+	return sqlx.RealmObjectDiff(from, to, realmObject, realmObjectChanged, func(o schema.Object) bool {
+		switch o := o.(type) {
+		case *Role:
+			// Roles are server-wide. Roles that are not defined in
+			// the desired state are kept, unless managed exclusively.
+			return !opts.ExclusiveRoles
+		case *Permission:
+			// Privileges on dropped schemas, tables or
+			// columns are revoked together with them.
+			_, ok := realmRole(to, o.Role.Name, o.Role.Host)
+			return !ok && !opts.ExclusiveRoles || !sqlx.HasPrivilegeTarget(to, o.Schema, o.Table, o.Column)
+		}
+		return false
+	}), nil
+}
+
 // TableAttrDiff returns a changeset for migrating table attributes from one state to the other.
 func (d *diff) TableAttrDiff(from, to *schema.Table) ([]schema.Change, error) {
 	var changes []schema.Change
@@ -63,6 +82,9 @@ func (d *diff) TableAttrDiff(from, to *schema.Table) ([]schema.Change, error) {
 	if change := d.collationChange(from.Attrs, from.Schema.Attrs, to.Attrs); change != noChange {
 		changes = append(changes, change)
 	}
+	if change := partitionChange(from.Attrs, to.Attrs); change != noChange {
+		changes = append(changes, change)
+	}
 	if !d.SupportsCheck() && sqlx.Has(to.Attrs, &schema.Check{}) {
 		return nil, fmt.Errorf("version %q does not support CHECK constraints", d.V)
 	}
@@ -264,6 +286,125 @@ func (*diff) charsetChange(from, top, to []schema.Attr) schema.Change {
 	return noChange
 }
 
+// partitionChange returns the schema change for migrating the table partitioning.
+func partitionChange(from, to []schema.Attr) schema.Change {
+	var fromP, toP Partition
+	switch fromHas, toHas := sqlx.Has(from, &fromP), sqlx.Has(to, &toP); {
+	case !fromHas && !toHas:
+	case !fromHas:
+		return &schema.AddAttr{
+			A: &toP,
+		}
+	case !toHas:
+		return &schema.DropAttr{
+			A: &fromP,
+		}
+	case !partitionKeyEqual(&fromP, &toP) || partitionCount(&fromP) != partitionCount(&toP) || !partsEqual(fromP.Parts, toP.Parts):
+		return &schema.ModifyAttr{
+			From: &fromP,
+			To:   &toP,
+		}
+	}
+	return noChange
+}
+
+// partitionKeyEqual reports if the two partitions have the same type and key.
+func partitionKeyEqual(p1, p2 *Partition) bool {
+	if !strings.EqualFold(p1.T, p2.T) || p1.Linear != p2.Linear || normalizeExpr(p1.Expr) != normalizeExpr(p2.Expr) || len(p1.Columns) != len(p2.Columns) {
+		return false
+	}
+	for i := range p1.Columns {
+		if p1.Columns[i].Name != p2.Columns[i].Name {
+			return false
+		}
+	}
+	return true
+}
+
+// partitionCount returns the number of HASH or KEY partitions.
+// If the PARTITIONS clause is omitted, the number of partitions defaults to 1.
+func partitionCount(p *Partition) int {
+	if p.Count == 0 && (strings.EqualFold(p.T, PartitionTypeHash) || strings.EqualFold(p.T, PartitionTypeKey)) {
+		return 1
+	}
+	return p.Count
+}
+
+// partsEqual reports if the two lists of RANGE or LIST partitions are equal.
+func partsEqual(p1, p2 []*PartitionPart) bool {
+	if len(p1) != len(p2) {
+		return false
+	}
+	for i := range p1 {
+		if !partEqual(p1[i], p2[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func partEqual(p1, p2 *PartitionPart) bool {
+	return p1.Name == p2.Name && normalizeValues(p1.Values) == normalizeValues(p2.Values)
+}
+
+// normalizeExpr normalizes a partitioning expression for comparison,
+// as the database returns it with quoted identifiers and in lowercase.
+func normalizeExpr(x string) string {
+	return strings.ToLower(strings.Join(strings.Fields(strings.ReplaceAll(x, "`", "")), ""))
+}
+
+// normalizeValues normalizes a partition value-list for comparison by removing
+// the whitespace characters and uppercasing keywords (e.g. MAXVALUE) that are not
+// part of string literals.
+func normalizeValues(v string) string {
+	var (
+		b      strings.Builder
+		quoted bool
+	)
+	for _, r := range v {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case quoted:
+		case unicode.IsSpace(r):
+			continue
+		default:
+			r = unicode.ToUpper(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// realmObject returns the realm object that matches the given one. Roles are
+// matched by their names and hosts, and permissions by their key.
+func realmObject(r *schema.Realm, o schema.Object) (schema.Object, bool) {
+	switch o := o.(type) {
+	case *Role:
+		return realmRole(r, o.Name, o.Host)
+	case *Permission:
+		k := permissionKey(o)
+		for _, o2 := range r.Objects {
+			if p, ok := o2.(*Permission); ok && permissionKey(p) == k {
+				return p, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// realmObjectChanged reports if the realm object was changed.
+func realmObjectChanged(from, to schema.Object) bool {
+	switch from := from.(type) {
+	case *Role:
+		return from.Login != to.(*Role).Login
+	case *Permission:
+		add, drop := sqlx.PrivilegesDiff(from.Privileges, to.(*Permission).Privileges)
+		return len(add) > 0 || len(drop) > 0
+	}
+	return false
+}
+
 // columnCharsetChange indicates if there is a change to the column charset.
 func (d *diff) columnCharsetChanged(fromT *schema.Table, from, to *schema.Column) (bool, error) {
 	if err := d.defaultCharset(&to.Attrs); err != nil {
@@ -5,6 +5,7 @@
 package mysql
 
 import (
+	"context"
 	"testing"
 
 	"ariga.io/atlas/sql/schema"
@@ -49,6 +50,50 @@ func TestDiff_TableDiff(t *testing.T) {
 			to:      &schema.Table{Name: "users"},
 			wantErr: true,
 		},
+		{
+			name: "add partitioning",
+			from: &schema.Table{Name: "logs", Schema: &schema.Schema{Name: "public"}},
+			to:   &schema.Table{Name: "logs", Attrs: []schema.Attr{&Partition{T: PartitionTypeHash, Expr: "id", Count: 4}}},
+			wantChanges: []schema.Change{
+				&schema.AddAttr{
+					A: &Partition{T: PartitionTypeHash, Expr: "id", Count: 4},
+				},
+			},
+		},
+		{
+			name: "remove partitioning",
+			from: &schema.Table{Name: "logs", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&Partition{T: PartitionTypeHash, Expr: "id", Count: 4}}},
+			to:   &schema.Table{Name: "logs"},
+			wantChanges: []schema.Change{
+				&schema.DropAttr{
+					A: &Partition{T: PartitionTypeHash, Expr: "id", Count: 4},
+				},
+			},
+		},
+		{
+			name: "no partition changes",
+			from: &schema.Table{Name: "logs", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{
+				&Partition{T: PartitionTypeRange, Expr: "year(`created`)", Parts: []*PartitionPart{{Name: "p0", Values: "2022"}, {Name: "p1", Values: "MAXVALUE"}}},
+			}},
+			to: &schema.Table{Name: "logs", Attrs: []schema.Attr{
+				&Partition{T: PartitionTypeRange, Expr: "YEAR(created)", Parts: []*PartitionPart{{Name: "p0", Values: " 2022"}, {Name: "p1", Values: "maxvalue"}}},
+			}},
+		},
+		{
+			name: "modify partitions",
+			from: &schema.Table{Name: "logs", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{
+				&Partition{T: PartitionTypeList, Expr: "id", Parts: []*PartitionPart{{Name: "p0", Values: "1,2"}}},
+			}},
+			to: &schema.Table{Name: "logs", Attrs: []schema.Attr{
+				&Partition{T: PartitionTypeList, Expr: "id", Parts: []*PartitionPart{{Name: "p0", Values: "1, 2"}, {Name: "p1", Values: "3"}}},
+			}},
+			wantChanges: []schema.Change{
+				&schema.ModifyAttr{
+					From: &Partition{T: PartitionTypeList, Expr: "id", Parts: []*PartitionPart{{Name: "p0", Values: "1,2"}}},
+					To:   &Partition{T: PartitionTypeList, Expr: "id", Parts: []*PartitionPart{{Name: "p0", Values: "1, 2"}, {Name: "p1", Values: "3"}}},
+				},
+			},
+		},
 		{
 			name: "modify counter",
 			from: &schema.Table{Name: "users", Schema: &schema.Schema{Name: "public"}, Attrs: []schema.Attr{&AutoIncrement{V: 1}}},
@@ -623,3 +668,138 @@ func TestDiff_RealmDiff(t *testing.T) {
 		&schema.AddTable{T: to.Schemas[1].Tables[0]},
 	}, changes)
 }
+
+func TestDiff_DiffOptions(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("8.0.19")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	from := &schema.Realm{
+		Schemas: []*schema.Schema{
+			{
+				Name: "public",
+				Tables: []*schema.Table{
+					{
+						Name: "users",
+						Columns: []*schema.Column{
+							{Name: "id", Type: &schema.ColumnType{Raw: "int", Type: &schema.IntegerType{T: "int"}}},
+							{Name: "name", Type: &schema.ColumnType{Raw: "text", Type: &schema.StringType{T: "text"}}, Attrs: []schema.Attr{&schema.Comment{Text: "a"}}},
+						},
+					},
+					{Name: "tmp_users"},
+				},
+				Attrs: []schema.Attr{
+					&schema.Collation{V: "latin1"},
+				},
+			},
+			{Name: "internal"},
+		},
+	}
+	to := &schema.Realm{
+		Schemas: []*schema.Schema{
+			{
+				Name: "public",
+				Tables: []*schema.Table{
+					{
+						Name: "users",
+						Columns: []*schema.Column{
+							{Name: "name", Type: &schema.ColumnType{Raw: "text", Type: &schema.StringType{T: "text"}}, Attrs: []schema.Attr{&schema.Comment{Text: "b"}}},
+						},
+					},
+					{Name: "tmp_pets"},
+				},
+				Attrs: []schema.Attr{
+					&schema.Collation{V: "utf8"},
+				},
+			},
+		},
+	}
+	for _, r := range []*schema.Realm{from, to} {
+		for _, s := range r.Schemas {
+			s.Realm = r
+			for _, t := range s.Tables {
+				t.Schema = s
+			}
+		}
+	}
+	changes, err := schema.RealmDiff(drv, from, to,
+		schema.DiffSkipChanges(&schema.DropSchema{}, &schema.DropColumn{}),
+		schema.DiffExclude("*.tmp_*"),
+		schema.DiffIgnore(schema.ChangeCollate|schema.ChangeComment),
+	)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	changes, err = schema.RealmDiff(drv, from, to, schema.DiffExclude("public.users.id", "public.tmp_*", "internal"))
+	require.NoError(t, err)
+	require.EqualValues(t, []schema.Change{
+		&schema.ModifySchema{S: to.Schemas[0], Changes: []schema.Change{&schema.ModifyAttr{From: from.Schemas[0].Attrs[0], To: to.Schemas[0].Attrs[0]}}},
+		&schema.ModifyTable{T: to.Schemas[0].Tables[0], Changes: []schema.Change{
+			&schema.ModifyColumn{From: from.Schemas[0].Tables[0].Columns[1], To: to.Schemas[0].Tables[0].Columns[0], Change: schema.ChangeComment},
+		}},
+	}, changes)
+
+	// Ignored attributes of modified columns keep their current values.
+	name := to.Schemas[0].Tables[0].Columns[0]
+	name.Type = &schema.ColumnType{Raw: "varchar(255)", Type: &schema.StringType{T: "varchar", Size: 255}}
+	changes, err = schema.RealmDiff(drv, from, to, schema.DiffExclude("public.users.id", "public.tmp_*", "internal"), schema.DiffIgnore(schema.ChangeCollate|schema.ChangeComment))
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	modify := changes[0].(*schema.ModifyTable).Changes[0].(*schema.ModifyColumn)
+	require.Equal(t, schema.ChangeType, modify.Change)
+	require.Equal(t, []schema.Attr{&schema.Comment{Text: "a"}}, modify.To.Attrs)
+	require.Equal(t, name.Type, modify.To.Type)
+	// The desired state is not modified.
+	require.Equal(t, []schema.Attr{&schema.Comment{Text: "b"}}, name.Attrs)
+	plan, err := drv.PlanChanges(context.Background(), "", changes)
+	require.NoError(t, err)
+	require.Equal(t, "ALTER TABLE `public`.`users` MODIFY COLUMN `name` varchar(255) NOT NULL COMMENT \"a\"", plan.Changes[0].Cmd)
+}
+
+func TestDiff_RoleDiff(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("8.0.19")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	var (
+		from   = schema.NewRealm(schema.New("test").AddTables(schema.NewTable("users"), schema.NewTable("pets")))
+		to     = schema.NewRealm(schema.New("test").AddTables(schema.NewTable("users")))
+		s1, s2 = from.Schemas[0], to.Schemas[0]
+		app1   = &Role{Name: "app", Host: "%"}
+		app2   = &Role{Name: "app", Host: "%", Login: true}
+	)
+	from.AddObjects(
+		app1, &Role{Name: "app", Host: "localhost"},
+		&Permission{Role: app1, Schema: s1, Table: s1.Tables[0], Privileges: []string{"SELECT"}},
+		// Privileges on dropped tables are revoked implicitly.
+		&Permission{Role: app1, Schema: s1, Table: s1.Tables[1], Privileges: []string{"SELECT"}},
+	)
+	to.AddObjects(
+		app2,
+		&Permission{Role: app2, Schema: s2, Table: s2.Tables[0], Privileges: []string{"SELECT"}},
+		&Permission{Role: app2, Schema: s2, Privileges: []string{"CREATE VIEW"}},
+	)
+	// Roles that are not defined in the desired state are kept with their permissions.
+	from.AddObjects(&Permission{Role: from.Objects[1].(*Role), Schema: s1, Privileges: []string{"SELECT"}})
+	changes, err := drv.RealmDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	require.IsType(t, &schema.DropTable{}, changes[0])
+	require.Equal(t, []schema.Change{
+		&schema.ModifyObject{From: from.Objects[0], To: to.Objects[0]},
+		&schema.AddObject{O: to.Objects[2]},
+	}, changes[1:])
+
+	changes, err = schema.RealmDiff(drv, from, to, schema.DiffExclusiveRoles())
+	require.NoError(t, err)
+	require.Len(t, changes, 5)
+	require.IsType(t, &schema.DropTable{}, changes[0])
+	require.Equal(t, []schema.Change{
+		&schema.ModifyObject{From: from.Objects[0], To: to.Objects[0]},
+		&schema.DropObject{O: from.Objects[1]},
+		&schema.DropObject{O: from.Objects[4]},
+		&schema.AddObject{O: to.Objects[2]},
+	}, changes[1:])
+}
@@ -23,7 +23,7 @@ type (
 	// generating diff between schema elements and apply migrations changes.
 	Driver struct {
 		conn
-		schema.Differ
+		schema.OptionsDiffer
 		schema.Inspector
 		migrate.PlanApplier
 	}
@@ -60,17 +60,17 @@ func Open(db schema.ExecQuerier) (migrate.Driver, error) {
 	}
 	if c.TiDB() {
 		return &Driver{
-			conn:        c,
-			Differ:      &sqlx.Diff{DiffDriver: &tdiff{diff{conn: c}}},
-			Inspector:   &tinspect{inspect{c}},
-			PlanApplier: &tplanApply{planApply{c}},
+			conn:          c,
+			OptionsDiffer: &sqlx.Diff{DiffDriver: &tdiff{diff{conn: c}}},
+			Inspector:     &tinspect{inspect{c}},
+			PlanApplier:   &tplanApply{planApply{c}},
 		}, nil
 	}
 	return &Driver{
-		conn:        c,
-		Differ:      &sqlx.Diff{DiffDriver: &diff{conn: c}},
-		Inspector:   &inspect{c},
-		PlanApplier: &planApply{c},
+		conn:          c,
+		OptionsDiffer: &sqlx.Diff{DiffDriver: &diff{conn: c}},
+		Inspector:     &inspect{c},
+		PlanApplier:   &planApply{c},
 	}, nil
 }
 
@@ -264,3 +264,28 @@ const (
 	stored     = "STORED"
 	persistent = "PERSISTENT"
 )
+
+// List of ALGORITHM and LOCK options for online DDL.
+const (
+	AlgorithmInstant = "INSTANT"
+	AlgorithmInplace = "INPLACE"
+	AlgorithmCopy    = "COPY"
+
+	LockNone      = "NONE"
+	LockShared    = "SHARED"
+	LockExclusive = "EXCLUSIVE"
+)
+
+// List of supported online schema change tools.
+const (
+	ToolGhOst = "gh-ost"
+	ToolPtOSC = "pt-online-schema-change"
+)
+
+// List of PARTITION BY types.
+const (
+	PartitionTypeRange = "RANGE"
+	PartitionTypeList  = "LIST"
+	PartitionTypeHash  = "HASH"
+	PartitionTypeKey   = "KEY"
+)
@@ -28,13 +28,21 @@ func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOpt
 		return nil, err
 	}
 	r := schema.NewRealm(schemas...).SetCharset(i.charset).SetCollation(i.collate)
-	if len(schemas) == 0 || !sqlx.ModeInspectRealm(opts).Is(schema.InspectTables) {
+	if len(schemas) == 0 {
 		return r, nil
 	}
-	if err := i.inspectTables(ctx, r, nil); err != nil {
-		return nil, err
+	mode := sqlx.ModeInspectRealm(opts)
+	if mode.Is(schema.InspectTables) {
+		if err := i.inspectTables(ctx, r, sqlx.RealmTablesOptions(opts), sqlx.ConcurrencyInspectRealm(i.ExecQuerier, opts)); err != nil {
+			return nil, err
+		}
+		sqlx.LinkSchemaTables(schemas)
+	}
+	if mode.Is(schema.InspectRoles) {
+		if err := i.inspectRoles(ctx, r); err != nil {
+			return nil, err
+		}
 	}
-	sqlx.LinkSchemaTables(schemas)
 	return r, nil
 }
 
@@ -53,7 +61,7 @@ func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.I
 	}
 	r := schema.NewRealm(schemas...).SetCharset(i.charset).SetCollation(i.collate)
 	if sqlx.ModeInspectSchema(opts).Is(schema.InspectTables) {
-		if err := i.inspectTables(ctx, r, opts); err != nil {
+		if err := i.inspectTables(ctx, r, opts, 1); err != nil {
 			return nil, err
 		}
 		sqlx.LinkSchemaTables(schemas)
@@ -61,51 +69,168 @@ func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.I
 	return r.Schemas[0], nil
 }
 
-func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *schema.InspectOptions) error {
+// inspectTables inspects the tables of the realm schemas. The tables of each schema are
+// inspected separately, using at most n concurrent goroutines (and connections).
+func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *schema.InspectOptions, n int) error {
 	if err := i.tables(ctx, r, opts); err != nil {
 		return err
 	}
-	for _, s := range r.Schemas {
-		if len(s.Tables) == 0 {
-			continue
+	return sqlx.ForEachSchema(ctx, n, r.Schemas, i.inspectSchemaTables)
+}
+
+// inspectSchemaTables inspects the columns, indexes and constraints of the schema tables.
+func (i *inspect) inspectSchemaTables(ctx context.Context, s *schema.Schema) error {
+	if len(s.Tables) == 0 {
+		return nil
+	}
+	if err := i.columns(ctx, s); err != nil {
+		return err
+	}
+	if err := i.indexes(ctx, s); err != nil {
+		return err
+	}
+	if err := i.fks(ctx, s); err != nil {
+		return err
+	}
+	if err := i.checks(ctx, s); err != nil {
+		return err
+	}
+	if err := i.partitions(ctx, s); err != nil {
+		return err
+	}
+	return i.showCreate(ctx, s)
+}
+
+// inspectRoles inspects the users and roles of the database, and the privileges they
+// were granted on the realm schemas, tables and columns. It is called after the tables
+// were inspected, as permissions reference them.
+func (i *inspect) inspectRoles(ctx context.Context, r *schema.Realm) error {
+	if err := i.roles(ctx, r); err != nil {
+		return err
+	}
+	return i.permissions(ctx, r)
+}
+
+// roles queries and appends the (non-system) users and roles of the database to the realm.
+func (i *inspect) roles(ctx context.Context, r *schema.Realm) error {
+	query := myRolesQuery
+	if i.Maria() {
+		query = marRolesQuery
+	}
+	rows, err := i.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("mysql: querying roles: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		role := &Role{}
+		if err := rows.Scan(&role.Name, &role.Host, &role.Login); err != nil {
+			return fmt.Errorf("mysql: scan role information: %w", err)
 		}
-		if err := i.columns(ctx, s); err != nil {
-			return err
+		r.AddObjects(role)
+	}
+	return rows.Close()
+}
+
+// permissions queries and appends the privileges granted to the inspected roles on the
+// realm schemas, tables and columns. Privileges on tables that were not inspected are
+// skipped.
+func (i *inspect) permissions(ctx context.Context, r *schema.Realm) error {
+	args := make([]interface{}, 0, len(r.Schemas)*3)
+	for j := 0; j < 3; j++ {
+		for _, s := range r.Schemas {
+			args = append(args, s.Name)
 		}
-		if err := i.indexes(ctx, s); err != nil {
-			return err
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(permissionsQuery, nArgs(len(r.Schemas))), args...)
+	if err != nil {
+		return fmt.Errorf("mysql: querying permissions: %w", err)
+	}
+	defer rows.Close()
+	perms := make(map[string]*Permission)
+	for rows.Next() {
+		var (
+			grantee, ns, privilege, grantable string
+			tableName, columnName             sql.NullString
+		)
+		if err := rows.Scan(&grantee, &ns, &tableName, &columnName, &privilege, &grantable); err != nil {
+			return fmt.Errorf("mysql: scan permission information: %w", err)
 		}
-		if err := i.fks(ctx, s); err != nil {
-			return err
+		name, host := parseGrantee(grantee)
+		role, ok := realmRole(r, name, host)
+		if !ok {
+			continue
 		}
-		if err := i.checks(ctx, s); err != nil {
-			return err
+		p := &Permission{Role: role, Grantable: strings.EqualFold(grantable, "YES")}
+		if p.Schema, p.Table, p.Column, ok = sqlx.PrivilegeTarget(r, ns, tableName, columnName); !ok {
+			continue
 		}
-		if err := i.showCreate(ctx, s); err != nil {
-			return err
+		k := permissionKey(p)
+		if _, ok := perms[k]; !ok {
+			perms[k] = p
+			r.AddObjects(p)
 		}
+		perms[k].Privileges = append(perms[k].Privileges, strings.ToUpper(privilege))
 	}
-	return nil
+	return rows.Close()
+}
+
+// parseGrantee parses the GRANTEE column of the privileges tables. e.g. 'user'@'host'.
+func parseGrantee(s string) (name, host string) {
+	if i := strings.LastIndex(s, "@"); i != -1 {
+		name, host = s[:i], s[i+1:]
+	} else {
+		name = s
+	}
+	unquote := func(s string) string {
+		if len(s) > 1 && s[0] == '\'' && s[len(s)-1] == '\'' {
+			s = strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+		}
+		return s
+	}
+	return unquote(name), unquote(host)
+}
+
+// realmRole returns the role with the given name and host from the realm objects.
+func realmRole(r *schema.Realm, name, host string) (*Role, bool) {
+	for _, o := range r.Objects {
+		if role, ok := o.(*Role); ok && role.Name == name && role.Host == host {
+			return role, true
+		}
+	}
+	return nil, false
+}
+
+// permissionKey returns the key of the permission. See sqlx.PermissionKey for more info.
+func permissionKey(p *Permission) string {
+	return sqlx.PermissionKey([]string{p.Role.Name, p.Role.Host}, p.Schema, p.Table, p.Column, p.Grantable)
 }
 
 // schemas returns the list of the schemas in the database.
 func (i *inspect) schemas(ctx context.Context, opts *schema.InspectRealmOption) ([]*schema.Schema, error) {
 	var (
-		args  []interface{}
-		query = schemasQuery
+		args   []interface{}
+		query  = schemasQuery
+		filter = sqlx.RealmSchemasFilter(opts)
+		glob   = len(filter.Patterns)+len(filter.Exclude) > 0
 	)
-	if opts != nil {
-		switch n := len(opts.Schemas); {
-		case n == 1 && opts.Schemas[0] == "":
-			query = fmt.Sprintf(schemasQueryArgs, "= SCHEMA()")
-		case n == 1 && opts.Schemas[0] != "":
-			query = fmt.Sprintf(schemasQueryArgs, "= ?")
-			args = append(args, opts.Schemas[0])
-		case n > 0:
-			query = fmt.Sprintf(schemasQueryArgs, "IN ("+nArgs(len(opts.Schemas))+")")
-			for _, s := range opts.Schemas {
-				args = append(args, s)
-			}
+	switch n := len(filter.Names); {
+	case glob:
+		if cond := filter.Cond([]string{"`SCHEMA_NAME`"}, "", func(v string) string {
+			args = append(args, v)
+			return "?"
+		}); cond != "" {
+			query = fmt.Sprintf(schemasQueryFilter, cond)
+		}
+	case n == 1 && filter.Names[0] == "":
+		query = fmt.Sprintf(schemasQueryArgs, "= SCHEMA()")
+	case n == 1 && filter.Names[0] != "":
+		query = fmt.Sprintf(schemasQueryArgs, "= ?")
+		args = append(args, filter.Names[0])
+	case n > 0:
+		query = fmt.Sprintf(schemasQueryArgs, "IN ("+nArgs(n)+")")
+		for _, s := range filter.Names {
+			args = append(args, s)
 		}
 	}
 	rows, err := i.QueryContext(ctx, query, args...)
@@ -119,6 +244,10 @@ func (i *inspect) schemas(ctx context.Context, opts *schema.InspectRealmOption)
 		if err := rows.Scan(&name, &charset, &collation); err != nil {
 			return nil, err
 		}
+		// Patterns that cannot be expressed in the query are checked here.
+		if glob && !filter.Match(name) {
+			continue
+		}
 		schemas = append(schemas, &schema.Schema{
 			Name: name,
 			Attrs: []schema.Attr{
@@ -136,17 +265,21 @@ func (i *inspect) schemas(ctx context.Context, opts *schema.InspectRealmOption)
 
 func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.InspectOptions) error {
 	var (
-		args  []interface{}
-		query = fmt.Sprintf(tablesQuery, nArgs(len(realm.Schemas)))
+		args   []interface{}
+		query  = fmt.Sprintf(tablesQuery, nArgs(len(realm.Schemas)))
+		filter = &sqlx.NameFilter{}
 	)
 	for _, s := range realm.Schemas {
 		args = append(args, s.Name)
 	}
-	if opts != nil && len(opts.Tables) > 0 {
-		for _, t := range opts.Tables {
-			args = append(args, t)
-		}
-		query = fmt.Sprintf(tablesQueryArgs, nArgs(len(realm.Schemas)), nArgs(len(opts.Tables)))
+	if opts != nil {
+		filter = sqlx.NewNameFilter(opts.Tables, opts.Exclude)
+	}
+	if cond := filter.Cond([]string{"TABLE_SCHEMA", "TABLE_NAME"}, "", func(v string) string {
+		args = append(args, v)
+		return "?"
+	}); cond != "" {
+		query = fmt.Sprintf(tablesQueryArgs, nArgs(len(realm.Schemas)), cond)
 	}
 	rows, err := i.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -164,6 +297,9 @@ func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.
 		if !sqlx.ValidString(tSchema) || !sqlx.ValidString(name) {
 			return fmt.Errorf("invalid schema or table name: %q.%q", tSchema.String, name.String)
 		}
+		if !filter.Match(tSchema.String, name.String) {
+			continue
+		}
 		s, ok := realm.Schema(tSchema.String)
 		if !ok {
 			return fmt.Errorf("schema %q was not found in realm", tSchema.String)
@@ -185,6 +321,12 @@ func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.
 				Text: comment.String,
 			})
 		}
+		// The "partitioned" option is not a valid table option,
+		// and the partitioning info is inspected separately.
+		if opts, ok := trimPartitioned(options.String); ok {
+			t.Attrs = append(t.Attrs, &Partition{})
+			options.String = opts
+		}
 		if sqlx.ValidString(options) {
 			t.Attrs = append(t.Attrs, &CreateOptions{
 				V: options.String,
@@ -442,6 +584,111 @@ func (i *inspect) checks(ctx context.Context, s *schema.Schema) error {
 	return rows.Err()
 }
 
+// partitions queries and sets the partitioning info of the partitioned tables in the schema.
+func (i *inspect) partitions(ctx context.Context, s *schema.Schema) error {
+	args := []interface{}{s.Name}
+	for _, t := range s.Tables {
+		if sqlx.Has(t.Attrs, &Partition{}) {
+			args = append(args, t.Name)
+		}
+	}
+	if len(args) == 1 {
+		return nil
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(partitionsQuery, nArgs(len(args)-1)), args...)
+	if err != nil {
+		return fmt.Errorf("mysql: querying %q partitions: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, name, method, expr, values sql.NullString
+		if err := rows.Scan(&table, &name, &method, &expr, &values); err != nil {
+			return fmt.Errorf("mysql: %w", err)
+		}
+		t, ok := s.Table(table.String)
+		if !ok {
+			return fmt.Errorf("table %q was not found in schema", table.String)
+		}
+		p, ok := tablePartition(t)
+		if !ok {
+			return fmt.Errorf("missing partition attribute for table %q", t.Name)
+		}
+		// First partition of the table.
+		if p.T == "" {
+			if err := setPartitionKey(p, t, method.String, expr.String); err != nil {
+				return err
+			}
+		}
+		switch {
+		case p.T == PartitionTypeHash || p.T == PartitionTypeKey:
+			p.Count++
+		// Sub-partitions are returned as separate rows
+		// with the same (parent) partition name.
+		case len(p.Parts) > 0 && p.Parts[len(p.Parts)-1].Name == name.String:
+		default:
+			p.Parts = append(p.Parts, &PartitionPart{Name: name.String, Values: values.String})
+		}
+	}
+	return rows.Err()
+}
+
+// tablePartition returns the partition attribute of the table.
+func tablePartition(t *schema.Table) (*Partition, bool) {
+	for _, a := range t.Attrs {
+		if p, ok := a.(*Partition); ok {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// setPartitionKey sets the partitioning type and key of the
+// partition from the information_schema.PARTITIONS table.
+func setPartitionKey(p *Partition, t *schema.Table, method, expr string) error {
+	method = strings.ToUpper(method)
+	if strings.HasPrefix(method, "LINEAR ") {
+		p.Linear = true
+		method = strings.TrimPrefix(method, "LINEAR ")
+	}
+	switch method {
+	case PartitionTypeRange, PartitionTypeList, PartitionTypeHash:
+		p.T, p.Expr = method, expr
+	case PartitionTypeRange + " COLUMNS", PartitionTypeList + " COLUMNS", PartitionTypeKey:
+		p.T = strings.TrimSuffix(method, " COLUMNS")
+		// An empty list of columns for KEY partitioning means the primary key.
+		for _, n := range strings.Split(expr, ",") {
+			if n = strings.Trim(strings.TrimSpace(n), "`"); n == "" {
+				continue
+			}
+			c, ok := t.Column(n)
+			if !ok {
+				return fmt.Errorf("mysql: column %q was not found for partition key of table %q", n, t.Name)
+			}
+			p.Columns = append(p.Columns, c)
+		}
+	default:
+		return fmt.Errorf("mysql: unexpected partition method %q for table %q", method, t.Name)
+	}
+	return nil
+}
+
+// trimPartitioned trims the "partitioned" option from the
+// CREATE_OPTIONS column, and reports if it was found.
+func trimPartitioned(opts string) (string, bool) {
+	var (
+		found bool
+		rest  []string
+	)
+	for _, o := range strings.Fields(opts) {
+		if strings.EqualFold(o, "partitioned") {
+			found = true
+		} else {
+			rest = append(rest, o)
+		}
+	}
+	return strings.Join(rest, " "), found
+}
+
 // supportsCheck reports if the connected database supports
 // the CHECK clause, and return the querying for getting them.
 func (i *inspect) supportsCheck() (string, bool) {
@@ -675,6 +922,9 @@ const (
 	// Query to list specific database schemas.
 	schemasQueryArgs = "SELECT `SCHEMA_NAME`, `DEFAULT_CHARACTER_SET_NAME`, `DEFAULT_COLLATION_NAME` from `INFORMATION_SCHEMA`.`SCHEMATA` WHERE `SCHEMA_NAME` %s ORDER BY `SCHEMA_NAME`"
 
+	// Query to list database schemas that match glob patterns.
+	schemasQueryFilter = "SELECT `SCHEMA_NAME`, `DEFAULT_CHARACTER_SET_NAME`, `DEFAULT_COLLATION_NAME` from `INFORMATION_SCHEMA`.`SCHEMATA` WHERE `SCHEMA_NAME` NOT IN ('mysql', 'information_schema', 'performance_schema', 'sys') AND %s ORDER BY `SCHEMA_NAME`"
+
 	// Query to list table columns.
 	columnsQuery     = "SELECT `TABLE_NAME`, `COLUMN_NAME`, `COLUMN_TYPE`, `COLUMN_COMMENT`, `IS_NULLABLE`, `COLUMN_KEY`, `COLUMN_DEFAULT`, `EXTRA`, `CHARACTER_SET_NAME`, `COLLATION_NAME`, NULL AS `GENERATION_EXPRESSION` FROM `INFORMATION_SCHEMA`.`COLUMNS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` IN (%s) ORDER BY `ORDINAL_POSITION`"
 	columnsExprQuery = "SELECT `TABLE_NAME`, `COLUMN_NAME`, `COLUMN_TYPE`, `COLUMN_COMMENT`, `IS_NULLABLE`, `COLUMN_KEY`, `COLUMN_DEFAULT`, `EXTRA`, `CHARACTER_SET_NAME`, `COLLATION_NAME`, `GENERATION_EXPRESSION` FROM `INFORMATION_SCHEMA`.`COLUMNS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` IN (%s) ORDER BY `ORDINAL_POSITION`"
@@ -718,11 +968,18 @@ FROM
 	ON t1.TABLE_COLLATION = t2.COLLATION_NAME
 WHERE
 	TABLE_SCHEMA IN (%s)
-	AND TABLE_NAME IN (%s)
+	AND %s
 ORDER BY
 	TABLE_SCHEMA, TABLE_NAME
 `
 
+	// Query to get the estimated number of rows of a table.
+	tableRowsQuery = "SELECT `TABLE_ROWS` FROM `INFORMATION_SCHEMA`.`TABLES` WHERE `TABLE_SCHEMA` = IFNULL(?, DATABASE()) AND `TABLE_NAME` = ?"
+
+	// Query to list table partitions. Sub-partitions are returned as
+	// separate rows and are ordered after their parent partitions.
+	partitionsQuery = "SELECT `TABLE_NAME`, `PARTITION_NAME`, `PARTITION_METHOD`, `PARTITION_EXPRESSION`, `PARTITION_DESCRIPTION` FROM `INFORMATION_SCHEMA`.`PARTITIONS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` IN (%s) AND `PARTITION_NAME` IS NOT NULL ORDER BY `TABLE_NAME`, `PARTITION_ORDINAL_POSITION`, `SUBPARTITION_ORDINAL_POSITION`"
+
 	// Query to list table check constraints.
 	myChecksQuery  = `SELECT t1.TABLE_NAME, t1.CONSTRAINT_NAME, t2.CHECK_CLAUSE, t1.ENFORCED` + checksQuery
 	marChecksQuery = `SELECT t1.TABLE_NAME, t1.CONSTRAINT_NAME, t2.CHECK_CLAUSE, "YES" AS ENFORCED` + checksQuery
@@ -766,6 +1023,53 @@ WHERE
 ORDER BY
 	t1.CONSTRAINT_NAME,
 	t1.ORDINAL_POSITION`
+
+	// Queries to list the users and roles of the database. System accounts
+	// (e.g. mysql.sys) and the root user are created on installation, and
+	// therefore, are skipped.
+	myRolesQuery  = "SELECT `User`, `Host`, `account_locked` = 'N' AS `login` FROM `mysql`.`user` WHERE `User` NOT LIKE 'mysql.%' AND `User` NOT IN ('', 'root') ORDER BY `User`, `Host`"
+	marRolesQuery = "SELECT `User`, `Host`, `is_role` = 'N' AS `login` FROM `mysql`.`user` WHERE `User` NOT LIKE 'mysql.%' AND `User` NOT IN ('', 'root') ORDER BY `User`, `Host`"
+
+	// Query to list the privileges granted on the given schemas, their tables and columns.
+	permissionsQuery = `
+SELECT
+	GRANTEE,
+	TABLE_SCHEMA,
+	NULL AS TABLE_NAME,
+	NULL AS COLUMN_NAME,
+	PRIVILEGE_TYPE,
+	IS_GRANTABLE
+FROM
+	INFORMATION_SCHEMA.SCHEMA_PRIVILEGES
+WHERE
+	TABLE_SCHEMA IN (%[1]s)
+UNION ALL
+SELECT
+	GRANTEE,
+	TABLE_SCHEMA,
+	TABLE_NAME,
+	NULL AS COLUMN_NAME,
+	PRIVILEGE_TYPE,
+	IS_GRANTABLE
+FROM
+	INFORMATION_SCHEMA.TABLE_PRIVILEGES
+WHERE
+	TABLE_SCHEMA IN (%[1]s)
+UNION ALL
+SELECT
+	GRANTEE,
+	TABLE_SCHEMA,
+	TABLE_NAME,
+	COLUMN_NAME,
+	PRIVILEGE_TYPE,
+	IS_GRANTABLE
+FROM
+	INFORMATION_SCHEMA.COLUMN_PRIVILEGES
+WHERE
+	TABLE_SCHEMA IN (%[1]s)
+ORDER BY
+	1, 2, 3, 4, 5
+`
 )
 
 type (
@@ -788,6 +1092,66 @@ type (
 		S string
 	}
 
+	// Partition describes the partitioning of a table (PARTITION BY clause).
+	// Sub-partitioning is not supported.
+	Partition struct {
+		schema.Attr
+		// T defines the partitioning type.
+		// Can be one of: RANGE, LIST, HASH, KEY.
+		T string
+		// Linear indicates a LINEAR HASH or LINEAR KEY partitioning.
+		Linear bool
+		// Expr is the partitioning expression. For example,
+		// "YEAR(`created_at`)" in "PARTITION BY RANGE (YEAR(`created_at`))".
+		Expr string
+		// Columns of RANGE COLUMNS and LIST COLUMNS partitioning, or
+		// the KEY partitioning columns. An empty KEY means the primary key.
+		Columns []*schema.Column
+		// Count is the number of partitions of HASH and KEY partitioning.
+		Count int
+		// Parts are the (ordered) partitions of RANGE and LIST partitioning.
+		Parts []*PartitionPart
+	}
+
+	// Role describes a database user or role. In MySQL, roles are accounts that are
+	// not allowed to log in (locked). In MariaDB, roles are not bound to a host, and
+	// their Host is empty. Passwords and other secrets are neither inspected nor managed.
+	// https://dev.mysql.com/doc/refman/8.0/en/roles.html
+	Role struct {
+		schema.Object
+		Name  string
+		Host  string
+		Login bool
+	}
+
+	// Permission describes the privileges granted to a role on a schema, a table
+	// or a table column. Schema is always set, Table is set for table and column
+	// privileges, and Column is set only for column privileges.
+	// https://dev.mysql.com/doc/refman/8.0/en/grant.html
+	Permission struct {
+		schema.Object
+		Role   *Role
+		Schema *schema.Schema
+		Table  *schema.Table
+		Column *schema.Column
+		// Privileges holds the granted privileges
+		// in upper-case (e.g. SELECT, CREATE VIEW).
+		Privileges []string
+		// Grantable indicates the privileges were
+		// granted WITH GRANT OPTION.
+		Grantable bool
+	}
+
+	// PartitionPart describes a RANGE or LIST partition.
+	PartitionPart struct {
+		Name string
+		// Values holds the value-list of the partition as returned by the
+		// PARTITION_DESCRIPTION column. For example, "2022" or "MAXVALUE" in
+		// RANGE partitioning (VALUES LESS THAN), and "1,2,3" in LIST partitioning
+		// (VALUES IN).
+		Values string
+	}
+
 	// OnUpdate attribute for columns with "ON UPDATE CURRENT_TIMESTAMP" as a default.
 	OnUpdate struct {
 		schema.Attr
@@ -28,6 +28,7 @@ var (
 	queryIndexesExpr      = sqltest.Escape(fmt.Sprintf(indexesExprQuery, "?"))
 	queryMyChecks         = sqltest.Escape(fmt.Sprintf(myChecksQuery, "?"))
 	queryMarChecks        = sqltest.Escape(fmt.Sprintf(marChecksQuery, "?"))
+	queryPartitions       = sqltest.Escape(fmt.Sprintf(partitionsQuery, "?"))
 )
 
 func TestDriver_InspectTable(t *testing.T) {
@@ -702,6 +703,103 @@ func TestDriver_InspectTable(t *testing.T) {
 				}, t.Attrs)
 			},
 		},
+		{
+			name: "range partitions",
+			before: func(m mock) {
+				m.ExpectQuery(queryTable).
+					WithArgs("public").
+					WillReturnRows(sqltest.Rows(`
++--------------+--------------+--------------------+--------------------+----------------+---------------+------------------------------+
+| TABLE_SCHEMA | TABLE_NAME   | CHARACTER_SET_NAME | TABLE_COLLATION    | AUTO_INCREMENT | TABLE_COMMENT | CREATE_OPTIONS               |
++--------------+--------------+--------------------+--------------------+----------------+---------------+------------------------------+
+| public       | logs         | utf8mb4            | utf8mb4_0900_ai_ci | nil            |               | row_format=DYNAMIC partitioned |
++--------------+--------------+--------------------+--------------------+----------------+---------------+------------------------------+
+`))
+				m.ExpectQuery(queryColumns).
+					WithArgs("public", "logs").
+					WillReturnRows(sqltest.Rows(`
++------------+-------------+-------------+----------------+-------------+------------+----------------+-------+--------------------+----------------+-----------------------+
+| table_name | column_name | column_type | column_comment | is_nullable | column_key | column_default | extra | character_set_name | collation_name | generation_expression |
++------------+-------------+-------------+----------------+-------------+------------+----------------+-------+--------------------+----------------+-----------------------+
+| logs       | id          | int         |                | NO          |            | NULL           |       | NULL               | NULL           | NULL                  |
+| logs       | created     | date        |                | NO          |            | NULL           |       | NULL               | NULL           | NULL                  |
++------------+-------------+-------------+----------------+-------------+------------+----------------+-------+--------------------+----------------+-----------------------+
+`))
+				m.noIndexes()
+				m.noFKs()
+				m.ExpectQuery(queryPartitions).
+					WithArgs("public", "logs").
+					WillReturnRows(sqltest.Rows(`
++------------+----------------+------------------+----------------------+-----------------------+
+| TABLE_NAME | PARTITION_NAME | PARTITION_METHOD | PARTITION_EXPRESSION | PARTITION_DESCRIPTION |
++------------+----------------+------------------+----------------------+-----------------------+
+| logs       | p2021          | RANGE            | year(created)        | 2022                  |
+| logs       | p2021          | RANGE            | year(created)        | 2022                  |
+| logs       | pmax           | RANGE            | year(created)        | MAXVALUE              |
++------------+----------------+------------------+----------------------+-----------------------+
+`))
+			},
+			expect: func(require *require.Assertions, t *schema.Table, err error) {
+				require.NoError(err)
+				require.EqualValues([]schema.Attr{
+					&schema.Charset{V: "utf8mb4"},
+					&schema.Collation{V: "utf8mb4_0900_ai_ci"},
+					&Partition{
+						T:    PartitionTypeRange,
+						Expr: "year(created)",
+						Parts: []*PartitionPart{
+							{Name: "p2021", Values: "2022"},
+							{Name: "pmax", Values: "MAXVALUE"},
+						},
+					},
+					&CreateOptions{V: "row_format=DYNAMIC"},
+				}, t.Attrs)
+			},
+		},
+		{
+			name: "linear key partitions",
+			before: func(m mock) {
+				m.ExpectQuery(queryTable).
+					WithArgs("public").
+					WillReturnRows(sqltest.Rows(`
++--------------+--------------+--------------------+--------------------+----------------+---------------+------------------------------+
+| TABLE_SCHEMA | TABLE_NAME   | CHARACTER_SET_NAME | TABLE_COLLATION    | AUTO_INCREMENT | TABLE_COMMENT | CREATE_OPTIONS               |
++--------------+--------------+--------------------+--------------------+----------------+---------------+------------------------------+
+| public       | logs         | utf8mb4            | utf8mb4_0900_ai_ci | nil            |               | partitioned                  |
++--------------+--------------+--------------------+--------------------+----------------+---------------+------------------------------+
+`))
+				m.ExpectQuery(queryColumns).
+					WithArgs("public", "logs").
+					WillReturnRows(sqltest.Rows(`
++------------+-------------+-------------+----------------+-------------+------------+----------------+-------+--------------------+----------------+-----------------------+
+| table_name | column_name | column_type | column_comment | is_nullable | column_key | column_default | extra | character_set_name | collation_name | generation_expression |
++------------+-------------+-------------+----------------+-------------+------------+----------------+-------+--------------------+----------------+-----------------------+
+| logs       | id          | int         |                | NO          |            | NULL           |       | NULL               | NULL           | NULL                  |
+| logs       | created     | date        |                | NO          |            | NULL           |       | NULL               | NULL           | NULL                  |
++------------+-------------+-------------+----------------+-------------+------------+----------------+-------+--------------------+----------------+-----------------------+
+`))
+				m.noIndexes()
+				m.noFKs()
+				m.ExpectQuery(queryPartitions).
+					WithArgs("public", "logs").
+					WillReturnRows(sqltest.Rows(`
++------------+----------------+------------------+----------------------+-----------------------+
+| TABLE_NAME | PARTITION_NAME | PARTITION_METHOD | PARTITION_EXPRESSION | PARTITION_DESCRIPTION |
++------------+----------------+------------------+----------------------+-----------------------+
+| logs       | p0             | LINEAR KEY       | id,created           | NULL                  |
+| logs       | p1             | LINEAR KEY       | id,created           | NULL                  |
++------------+----------------+------------------+----------------------+-----------------------+
+`))
+			},
+			expect: func(require *require.Assertions, t *schema.Table, err error) {
+				require.NoError(err)
+				require.EqualValues([]schema.Attr{
+					&schema.Charset{V: "utf8mb4"},
+					&schema.Collation{V: "utf8mb4_0900_ai_ci"},
+					&Partition{T: PartitionTypeKey, Linear: true, Columns: t.Columns, Count: 2},
+				}, t.Attrs)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -959,6 +1057,129 @@ func TestDriver_Realm(t *testing.T) {
 	}(), realm)
 }
 
+func TestDriver_RealmConcurrency(t *testing.T) {
+	names := []string{"s1", "s2", "s3", "s4"}
+	for i := 0; i < 5; i++ {
+		db, m, err := sqlmock.New()
+		require.NoError(t, err)
+		// Schemas are inspected concurrently, and their queries may arrive in any order.
+		m.MatchExpectationsInOrder(false)
+		mk := mock{m}
+		mk.version("8.0.13")
+		drv, err := Open(db)
+		require.NoError(t, err)
+		schemas := sqlmock.NewRows([]string{"SCHEMA_NAME", "DEFAULT_CHARACTER_SET_NAME", "DEFAULT_COLLATION_NAME"})
+		tables := sqlmock.NewRows([]string{"schema", "table", "charset", "collate", "inc", "comment", "options"})
+		for _, n := range names {
+			schemas.AddRow(n, "utf8mb4", "utf8mb4_unicode_ci")
+			tables.AddRow(n, "users", nil, nil, nil, nil, nil)
+			mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(columnsExprQuery, "?"))).
+				WithArgs(n, "users").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "COLUMN_NAME", "COLUMN_TYPE", "COLUMN_COMMENT", "IS_NULLABLE", "COLUMN_KEY", "COLUMN_DEFAULT", "EXTRA", "CHARACTER_SET_NAME", "COLLATION_NAME", "GENERATION_EXPRESSION"}).
+					AddRow("users", "id", "int", "", "NO", "PRI", nil, "", nil, nil, nil).
+					AddRow("users", n+"_id", "int", "", "YES", "", nil, "", nil, nil, nil))
+			mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(indexesExprQuery, "?"))).
+				WithArgs(n, "users").
+				WillReturnRows(sqlmock.NewRows([]string{"table_name", "index_name", "column_name", "non_unique", "key_part", "expression"}))
+			mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(fksQuery, "?"))).
+				WithArgs(n, "users").
+				WillReturnRows(sqlmock.NewRows([]string{"CONSTRAINT_NAME", "TABLE_NAME", "COLUMN_NAME", "TABLE_SCHEMA", "REFERENCED_TABLE_NAME", "REFERENCED_COLUMN_NAME", "REFERENCED_SCHEMA_NAME", "UPDATE_RULE", "DELETE_RULE"}))
+		}
+		mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "IN (?, ?, ?, ?)"))).
+			WithArgs("s1", "s2", "s3", "s4").
+			WillReturnRows(schemas)
+		mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "?, ?, ?, ?"))).
+			WithArgs("s1", "s2", "s3", "s4").
+			WillReturnRows(tables)
+		realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Schemas: names, Concurrency: 4})
+		require.NoError(t, err)
+		require.NoError(t, m.ExpectationsWereMet())
+		require.Len(t, realm.Schemas, len(names))
+		for j, s := range realm.Schemas {
+			require.Equal(t, names[j], s.Name)
+			require.Len(t, s.Tables, 1)
+			require.Equal(t, s, s.Tables[0].Schema)
+			require.Len(t, s.Tables[0].Columns, 2)
+			require.Equal(t, "id", s.Tables[0].Columns[0].Name)
+			require.Equal(t, s.Name+"_id", s.Tables[0].Columns[1].Name)
+		}
+	}
+}
+
+func TestDriver_RealmPatterns(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("8.0.13")
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryFilter, "`SCHEMA_NAME` LIKE ?"))).
+		WithArgs(`app\_%`).
+		WillReturnRows(sqltest.Rows(`
++-------------+----------------------------+------------------------+
+| SCHEMA_NAME | DEFAULT_CHARACTER_SET_NAME | DEFAULT_COLLATION_NAME |
++-------------+----------------------------+------------------------+
+| app_1       | utf8mb4                    | utf8mb4_unicode_ci     |
+| app_users   | utf8mb4                    | utf8mb4_unicode_ci     |
++-------------+----------------------------+------------------------+
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQueryArgs, "?", "NOT (TABLE_SCHEMA LIKE ? AND TABLE_NAME LIKE ?)"))).
+		WithArgs("app_users", `app\_%`, `tmp\_%`).
+		WillReturnRows(sqlmock.NewRows([]string{"schema", "table", "charset", "collate", "inc", "comment", "options"}))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{
+		Schemas: []string{"app_*"},
+		// Character classes are not pushed down to the query.
+		Exclude: []string{"app_[0-9]", "app_*.tmp_*"},
+	})
+	require.NoError(t, err)
+	require.Len(t, realm.Schemas, 1)
+	require.Equal(t, "app_users", realm.Schemas[0].Name)
+}
+
+func TestDriver_InspectRoles(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("8.0.13")
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqltest.Rows(`
++-------------+----------------------------+------------------------+
+| SCHEMA_NAME | DEFAULT_CHARACTER_SET_NAME | DEFAULT_COLLATION_NAME |
++-------------+----------------------------+------------------------+
+| test        | utf8mb4                    | utf8mb4_unicode_ci     |
++-------------+----------------------------+------------------------+
+`))
+	mk.ExpectQuery(sqltest.Escape(myRolesQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"User", "Host", "login"}).
+			AddRow("app", "%", true).
+			AddRow("app", "localhost", true).
+			AddRow("reader", "%", false))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(permissionsQuery, "?"))).
+		WithArgs("test", "test", "test").
+		WillReturnRows(sqlmock.NewRows([]string{"GRANTEE", "TABLE_SCHEMA", "TABLE_NAME", "COLUMN_NAME", "PRIVILEGE_TYPE", "IS_GRANTABLE"}).
+			AddRow("'app'@'%'", "test", nil, nil, "SELECT", "NO").
+			AddRow("'app'@'%'", "test", nil, nil, "CREATE VIEW", "NO").
+			AddRow("'app'@'localhost'", "test", nil, nil, "SELECT", "YES").
+			AddRow("'root'@'%'", "test", nil, nil, "SELECT", "NO").
+			AddRow("'reader'@'%'", "test", "users", nil, "SELECT", "NO"))
+	drv, err := Open(db)
+	require.NoError(t, err)
+	r, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Mode: schema.InspectSchemas | schema.InspectRoles})
+	require.NoError(t, err)
+	var (
+		s      = r.Schemas[0]
+		app1   = &Role{Name: "app", Host: "%", Login: true}
+		app2   = &Role{Name: "app", Host: "localhost", Login: true}
+		reader = &Role{Name: "reader", Host: "%"}
+	)
+	// Unknown roles and tables that were not inspected are skipped.
+	require.Equal(t, []schema.Object{
+		app1, app2, reader,
+		&Permission{Role: app1, Schema: s, Privileges: []string{"SELECT", "CREATE VIEW"}},
+		&Permission{Role: app2, Schema: s, Privileges: []string{"SELECT"}, Grantable: true},
+	}, r.Objects)
+}
+
 func TestInspectMode_InspectRealm(t *testing.T) {
 	db, m, err := sqlmock.New()
 	require.NoError(t, err)
@@ -84,6 +84,47 @@ func (v V) SupportsIndexComment() bool {
 	return v.Maria() || v.GTE("5.5.3")
 }
 
+// SupportsInstantAddColumn reports if the version supports
+// adding columns using the INSTANT algorithm.
+func (v V) SupportsInstantAddColumn() bool {
+	u := "8.0.12"
+	if v.Maria() {
+		u = "10.3.2"
+	}
+	return v.GTE(u)
+}
+
+// SupportsInstantDropColumn reports if the version supports
+// dropping columns using the INSTANT algorithm.
+func (v V) SupportsInstantDropColumn() bool {
+	u := "8.0.29"
+	if v.Maria() {
+		u = "10.4"
+	}
+	return v.GTE(u)
+}
+
+// SupportsInstantRenameColumn reports if the version supports
+// renaming columns using the INSTANT algorithm.
+func (v V) SupportsInstantRenameColumn() bool {
+	u := "8.0.28"
+	if v.Maria() {
+		u = "10.5.2"
+	}
+	return v.GTE(u)
+}
+
+// SupportsInstantAlter reports if the version supports the
+// INSTANT algorithm for metadata-only changes, such as
+// changing a column default or renaming an index.
+func (v V) SupportsInstantAlter() bool {
+	u := "8.0.12"
+	if v.Maria() {
+		u = "10.3.2"
+	}
+	return v.GTE(u)
+}
+
 // CharsetToCollate returns the mapping from charset to its default collation.
 func (v V) CharsetToCollate() (map[string]string, error) {
 	name := "is/charset2collate"
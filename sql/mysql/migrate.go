@@ -6,6 +6,7 @@ package mysql
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"strconv"
@@ -19,8 +20,40 @@ import (
 // A planApply provides migration capabilities for schema elements.
 type planApply struct{ conn }
 
+// OnlineDDL is a migrate.PlanOption for planning ALTER TABLE statements
+// with online DDL in mind. When passed to PlanChanges, each ALTER TABLE
+// change is annotated with the algorithm MySQL is expected to use, and
+// changes that require a full table copy are split from the rest.
+type OnlineDDL struct {
+	migrate.PlanOption
+	// Clause indicates if the ALGORITHM and LOCK clauses
+	// should be appended to the ALTER TABLE statements.
+	Clause bool
+	// Lock holds the LOCK clause value (e.g. NONE or SHARED). It is
+	// ignored for statements that use the INSTANT or COPY algorithms.
+	Lock string
+}
+
+// OnlineSchemaChange is a migrate.PlanOption for planning the ALTER TABLE statements
+// of large tables as gh-ost or pt-online-schema-change invocations instead of raw SQL.
+// Tables are selected by name or by their estimated number of rows, and the generated
+// statements are expected to be executed using the migrate.WithCommands option.
+type OnlineSchemaChange struct {
+	migrate.PlanOption
+	// Tool is the tool used for altering the selected
+	// tables. ToolGhOst or ToolPtOSC.
+	Tool string
+	// Tables lists the tables to alter with the tool. Names
+	// can be qualified with their schema. e.g. "app.users".
+	Tables []string
+	// MinRows selects the tables that have at least MinRows
+	// rows, as estimated by the INFORMATION_SCHEMA. Zero means
+	// tables are not selected by their size.
+	MinRows int64
+}
+
 // PlanChanges returns a migration plan for the given schema changes.
-func (p *planApply) PlanChanges(_ context.Context, name string, changes []schema.Change) (*migrate.Plan, error) {
+func (p *planApply) PlanChanges(ctx context.Context, name string, changes []schema.Change, opts ...migrate.PlanOption) (*migrate.Plan, error) {
 	s := &state{
 		conn: p.conn,
 		Plan: migrate.Plan{
@@ -33,6 +66,23 @@ func (p *planApply) PlanChanges(_ context.Context, name string, changes []schema
 			Transactional: false,
 		},
 	}
+	for _, o := range opts {
+		switch o := o.(type) {
+		case *OnlineDDL:
+			s.online = o
+		case OnlineDDL:
+			s.online = &o
+		case *OnlineSchemaChange:
+			s.osc = o
+		case OnlineSchemaChange:
+			s.osc = &o
+		}
+	}
+	if s.osc != nil {
+		if err := s.oscTables(ctx, changes); err != nil {
+			return nil, err
+		}
+	}
 	if err := s.plan(changes); err != nil {
 		return nil, err
 	}
@@ -58,6 +108,10 @@ func (p *planApply) ApplyChanges(ctx context.Context, changes []schema.Change) e
 type state struct {
 	conn
 	migrate.Plan
+	online *OnlineDDL
+	osc    *OnlineSchemaChange
+	// Tables that are altered using the online schema change tool.
+	oscT map[*schema.Table]bool
 }
 
 // plan builds the migration plan for applying the
@@ -67,6 +121,10 @@ func (s *state) plan(changes []schema.Change) error {
 	if err != nil {
 		return err
 	}
+	planned, deferred, err := s.objects(planned)
+	if err != nil {
+		return err
+	}
 	planned, err = sqlx.DetachCycles(planned)
 	if err != nil {
 		return err
@@ -88,9 +146,162 @@ func (s *state) plan(changes []schema.Change) error {
 			return err
 		}
 	}
+	s.append(deferred...)
 	return nil
 }
 
+// objects plans the creation and modification of roles (and users) before the table changes,
+// and returns the object changes that should be planned after them. Privileges are granted
+// after the tables they are granted on were created, and roles are dropped last, after their
+// privileges were revoked.
+func (s *state) objects(changes []schema.Change) ([]schema.Change, []*migrate.Change, error) {
+	changes, grants, revokes, err := sqlx.PlanPermissions(changes, s.privileges)
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		roles, drops []*migrate.Change
+		planned      = make([]schema.Change, 0, len(changes))
+	)
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddObject:
+			o, ok := c.O.(*Role)
+			if !ok {
+				return nil, nil, fmt.Errorf("unsupported object %T", c.O)
+			}
+			roles = append(roles, &migrate.Change{
+				Cmd:     s.createRole(o),
+				Source:  c,
+				Comment: fmt.Sprintf("create %q role", o.Name),
+				Reverse: s.dropRole(o),
+			})
+		case *schema.DropObject:
+			o, ok := c.O.(*Role)
+			if !ok {
+				return nil, nil, fmt.Errorf("unsupported object %T", c.O)
+			}
+			drops = append(drops, &migrate.Change{
+				Cmd:     s.dropRole(o),
+				Source:  c,
+				Comment: fmt.Sprintf("drop %q role", o.Name),
+				Reverse: s.createRole(o),
+			})
+		case *schema.ModifyObject:
+			from, ok1 := c.From.(*Role)
+			to, ok2 := c.To.(*Role)
+			if !ok1 || !ok2 {
+				return nil, nil, fmt.Errorf("unsupported object modification %T -> %T", c.From, c.To)
+			}
+			// MariaDB roles are not accounts, and cannot be locked or unlocked.
+			if s.Maria() {
+				return nil, nil, fmt.Errorf("changing the login option of role %q is not supported by MariaDB", to.Name)
+			}
+			lock := func(r *Role) string {
+				b := Build("ALTER USER").P(account(r), "ACCOUNT")
+				if r.Login {
+					return b.P("UNLOCK").String()
+				}
+				return b.P("LOCK").String()
+			}
+			roles = append(roles, &migrate.Change{
+				Cmd:     lock(to),
+				Source:  c,
+				Comment: fmt.Sprintf("modify %q role", to.Name),
+				Reverse: lock(from),
+			})
+		default:
+			planned = append(planned, c)
+		}
+	}
+	s.append(roles...)
+	deferred := append(revokes, grants...)
+	return planned, append(deferred, drops...), nil
+}
+
+// privileges returns the sqlx.Privileges of the given permission object.
+func (s *state) privileges(o schema.Object) (*sqlx.Privileges, bool) {
+	p, ok := o.(*Permission)
+	if !ok {
+		return nil, false
+	}
+	return &sqlx.Privileges{
+		Role:      p.Role.Name,
+		Target:    sqlx.PrivilegeTargetName(p.Schema, p.Table, p.Column),
+		List:      p.Privileges,
+		Grantable: p.Grantable,
+		Grant: func(privileges []string) string {
+			return s.grant(p, privileges)
+		},
+		Revoke: func(privileges []string, grantOption bool) string {
+			return s.revoke(p, privileges, grantOption)
+		},
+	}, true
+}
+
+// createRole returns the CREATE USER (or CREATE ROLE) statement of the given role.
+func (s *state) createRole(r *Role) string {
+	if r.Login {
+		return Build("CREATE USER").P(account(r)).String()
+	}
+	return Build("CREATE ROLE").P(account(r)).String()
+}
+
+// dropRole returns the DROP USER (or DROP ROLE) statement of the given role.
+func (s *state) dropRole(r *Role) string {
+	if r.Login {
+		return Build("DROP USER").P(account(r)).String()
+	}
+	return Build("DROP ROLE").P(account(r)).String()
+}
+
+// grant returns the GRANT statement for the given privileges of the permission.
+func (s *state) grant(p *Permission, privileges []string) string {
+	b := privilegesOn(Build("GRANT"), p, privileges).P("TO", account(p.Role))
+	if p.Grantable {
+		b.P("WITH GRANT OPTION")
+	}
+	return b.String()
+}
+
+// revoke returns the REVOKE statement for the given privileges of the permission.
+// Unlike PostgreSQL, the grant option is revoked only if it is listed explicitly.
+func (s *state) revoke(p *Permission, privileges []string, grantOption bool) string {
+	if grantOption {
+		privileges = append(privileges[:len(privileges):len(privileges)], "GRANT OPTION")
+	}
+	return privilegesOn(Build("REVOKE"), p, privileges).P("FROM", account(p.Role)).String()
+}
+
+// privilegesOn writes the privileges list and the object they are granted on.
+func privilegesOn(b *sqlx.Builder, p *Permission, privileges []string) *sqlx.Builder {
+	b.MapComma(privileges, func(i int, b *sqlx.Builder) {
+		b.P(privileges[i])
+		// The grant option applies to all privileges of the object.
+		if p.Column != nil && privileges[i] != "GRANT OPTION" {
+			b.Wrap(func(b *sqlx.Builder) {
+				b.Ident(p.Column.Name)
+			})
+		}
+	})
+	if p.Table != nil {
+		return b.P("ON").Table(p.Table)
+	}
+	return b.P("ON", Build("").Ident(p.Schema.Name).String()+".*")
+}
+
+// account returns the account name of the role. e.g. 'user'@'host'.
+// MariaDB roles are not bound to a host, and their name is returned.
+func account(r *Role) string {
+	quote := func(s string) string {
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+	if r.Host == "" {
+		return quote(r.Name)
+	}
+	return quote(r.Name) + "@" + quote(r.Host)
+}
+
 // topLevel appends first the changes for creating or dropping schemas (top-level schema elements).
 func (s *state) topLevel(changes []schema.Change) ([]schema.Change, error) {
 	planned := make([]schema.Change, 0, len(changes))
@@ -244,6 +455,9 @@ func (s *state) addTable(add *schema.AddTable) error {
 		return fmt.Errorf("create table %q: %s", add.T.Name, strings.Join(errs, ", "))
 	}
 	s.tableAttr(b, add, add.T.Attrs...)
+	if p := (&Partition{}); sqlx.Has(add.T.Attrs, p) {
+		partitionBy(b, p)
+	}
 	s.append(&migrate.Change{
 		Cmd:     b.String(),
 		Source:  add,
@@ -271,8 +485,17 @@ func (s *state) dropTable(drop *schema.DropTable) {
 // modifyTable builds and appends the migration changes for
 // bringing the table into its modified state.
 func (s *state) modifyTable(modify *schema.ModifyTable) error {
-	var changes [2][]schema.Change
+	var (
+		changes [2][]schema.Change
+		// Partitioning changes cannot be combined with
+		// other alterations, and are executed separately.
+		partitions []schema.Change
+	)
 	for _, change := range skipAutoChanges(modify.Changes) {
+		if isPartitionChange(change) {
+			partitions = append(partitions, change)
+			continue
+		}
 		switch change := change.(type) {
 		// Foreign-key modification is translated into 2 steps.
 		// Dropping the current foreign key and creating a new one.
@@ -315,18 +538,192 @@ func (s *state) modifyTable(modify *schema.ModifyTable) error {
 			}
 		}
 	}
+	for _, c := range partitions {
+		if err := s.alterPartition(modify.T, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isPartitionChange reports if the change is a table partitioning change.
+func isPartitionChange(c schema.Change) bool {
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		_, ok := c.A.(*Partition)
+		return ok
+	case *schema.DropAttr:
+		_, ok := c.A.(*Partition)
+		return ok
+	case *schema.ModifyAttr:
+		_, ok := c.To.(*Partition)
+		return ok
+	}
+	return false
+}
+
+// alterPartition builds and appends the migration change for
+// bringing the table partitioning into its modified state.
+func (s *state) alterPartition(t *schema.Table, c schema.Change) error {
+	var (
+		b, r    *sqlx.Builder
+		comment = fmt.Sprintf("modify partitions of %q table", t.Name)
+	)
+	switch c := c.(type) {
+	case *schema.AddAttr:
+		b, r = partitionBy(Build("ALTER TABLE").Table(t), c.A.(*Partition)), Build("ALTER TABLE").Table(t).P("REMOVE PARTITIONING")
+		comment = fmt.Sprintf("partition %q table", t.Name)
+	case *schema.DropAttr:
+		b, r = Build("ALTER TABLE").Table(t).P("REMOVE PARTITIONING"), partitionBy(Build("ALTER TABLE").Table(t), c.A.(*Partition))
+		comment = fmt.Sprintf("remove partitioning of %q table", t.Name)
+	case *schema.ModifyAttr:
+		from, ok := c.From.(*Partition)
+		if !ok {
+			return fmt.Errorf("mismatch ModifyAttr attributes: %T != %T", c.To, c.From)
+		}
+		to := c.To.(*Partition)
+		switch pt := strings.ToUpper(to.T); {
+		// Changing the partitioning type or key requires repartitioning the table.
+		case !partitionKeyEqual(from, to):
+			b, r = partitionBy(Build("ALTER TABLE").Table(t), to), partitionBy(Build("ALTER TABLE").Table(t), from)
+		case pt == PartitionTypeHash || pt == PartitionTypeKey:
+			n1, n2 := partitionCount(from), partitionCount(to)
+			if n1 == n2 {
+				return nil
+			}
+			b, r = alterCount(t, n1, n2), alterCount(t, n2, n1)
+		default:
+			if b, r = alterParts(t, to, from.Parts, to.Parts), alterParts(t, to, to.Parts, from.Parts); b == nil {
+				return nil
+			}
+		}
+	}
+	s.append(&migrate.Change{
+		Cmd:     b.String(),
+		Source:  c,
+		Reverse: r.String(),
+		Comment: comment,
+	})
 	return nil
 }
 
+// alterCount returns the statement for changing the number of HASH or KEY partitions.
+func alterCount(t *schema.Table, from, to int) *sqlx.Builder {
+	b := Build("ALTER TABLE").Table(t)
+	if from < to {
+		return b.P("ADD PARTITION PARTITIONS", strconv.Itoa(to-from))
+	}
+	return b.P("COALESCE PARTITION", strconv.Itoa(from-to))
+}
+
+// alterParts returns the statement for migrating the RANGE or LIST partitions from one list
+// to the other, or nil if there is no change. Partitions that are not changed are skipped, and
+// the rest are added, dropped or reorganized.
+func alterParts(t *schema.Table, p *Partition, from, to []*PartitionPart) *sqlx.Builder {
+	var i, j int
+	for i < len(from) && i < len(to) && partEqual(from[i], to[i]) {
+		i++
+	}
+	for j < len(from)-i && j < len(to)-i && partEqual(from[len(from)-j-1], to[len(to)-j-1]) {
+		j++
+	}
+	// RANGE partitions can be added only at the end of the
+	// list. Hence, the partition that follows them is reorganized.
+	if i == len(from)-j && i < len(to)-j && strings.EqualFold(p.T, PartitionTypeRange) && j > 0 {
+		j--
+	}
+	var (
+		b            = Build("ALTER TABLE").Table(t)
+		oldP, newP   = from[i : len(from)-j], to[i : len(to)-j]
+		names, parts = func(ps []*PartitionPart) func(int, *sqlx.Builder) {
+			return func(i int, b *sqlx.Builder) { b.Ident(ps[i].Name) }
+		}, func(ps []*PartitionPart) func(int, *sqlx.Builder) {
+			return func(i int, b *sqlx.Builder) { partitionDef(b, p, ps[i]) }
+		}
+	)
+	switch {
+	case len(oldP) == 0 && len(newP) == 0:
+		return nil
+	case len(oldP) == 0:
+		b.P("ADD PARTITION").Wrap(func(b *sqlx.Builder) { b.MapComma(newP, parts(newP)) })
+	case len(newP) == 0:
+		b.P("DROP PARTITION").MapComma(oldP, names(oldP))
+	default:
+		b.P("REORGANIZE PARTITION").MapComma(oldP, names(oldP)).P("INTO").Wrap(func(b *sqlx.Builder) { b.MapComma(newP, parts(newP)) })
+	}
+	return b
+}
+
+// partitionBy writes the PARTITION BY clause of the given partitioning to the builder.
+func partitionBy(b *sqlx.Builder, p *Partition) *sqlx.Builder {
+	typ := strings.ToUpper(p.T)
+	b.P("PARTITION BY")
+	if p.Linear {
+		b.P("LINEAR")
+	}
+	b.P(typ)
+	switch {
+	case typ == PartitionTypeKey || len(p.Columns) > 0:
+		if typ != PartitionTypeKey {
+			b.P("COLUMNS")
+		}
+		b.Wrap(func(b *sqlx.Builder) {
+			b.MapComma(p.Columns, func(i int, b *sqlx.Builder) {
+				b.Ident(p.Columns[i].Name)
+			})
+		}).WriteByte(' ')
+	default:
+		b.P(sqlx.MayWrap(p.Expr))
+	}
+	switch {
+	case (typ == PartitionTypeHash || typ == PartitionTypeKey) && p.Count > 0:
+		b.P("PARTITIONS", strconv.Itoa(p.Count))
+	case len(p.Parts) > 0:
+		b.Wrap(func(b *sqlx.Builder) {
+			b.MapComma(p.Parts, func(i int, b *sqlx.Builder) {
+				partitionDef(b, p, p.Parts[i])
+			})
+		})
+	}
+	return b
+}
+
+// partitionDef writes the definition of a RANGE or LIST partition to the builder.
+func partitionDef(b *sqlx.Builder, p *Partition, part *PartitionPart) {
+	b.P("PARTITION").Ident(part.Name)
+	switch {
+	// The values of multi-column LIST partitions are a list of tuples.
+	case strings.EqualFold(p.T, PartitionTypeList) && len(p.Columns) > 1:
+		b.P("VALUES IN", "("+part.Values+")")
+	case strings.EqualFold(p.T, PartitionTypeList):
+		b.P("VALUES IN", sqlx.MayWrap(part.Values))
+	case strings.EqualFold(strings.TrimSpace(part.Values), "MAXVALUE"):
+		b.P("VALUES LESS THAN MAXVALUE")
+	default:
+		b.P("VALUES LESS THAN", sqlx.MayWrap(part.Values))
+	}
+}
+
 // alterTable modifies the given table by executing on it a list of
 // changes in one SQL statement.
 func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
+	// Changes that require a full table copy are executed separately,
+	// to allow the rest to be executed in-place or instantly.
+	if s.online != nil && !s.oscT[t] {
+		inplace, copied := s.splitCopy(changes)
+		if len(inplace) > 0 && len(copied) > 0 {
+			if err := s.alterTable(t, inplace); err != nil {
+				return err
+			}
+			return s.alterTable(t, copied)
+		}
+	}
 	var (
 		reverse    []schema.Change
 		reversible = true
 	)
 	build := func(changes []schema.Change) (string, error) {
-		b := Build("ALTER TABLE").Table(t)
+		b := Build("")
 		err := b.MapCommaErr(changes, func(i int, b *sqlx.Builder) error {
 			switch change := changes[i].(type) {
 			case *schema.AddColumn:
@@ -429,6 +826,21 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 		if err != nil {
 			return "", err
 		}
+		// The online schema change tool accepts
+		// only the ALTER TABLE specification.
+		if s.oscT[t] {
+			return s.oscCommand(t, b.String()), nil
+		}
+		b = Build("ALTER TABLE").Table(t).P(b.String())
+		if s.online != nil && s.online.Clause {
+			a := s.algorithms(changes)
+			b.Comma().P("ALGORITHM=" + a)
+			// The COPY algorithm does not permit concurrent writes
+			// (e.g. LOCK=NONE), and it picks the lock it requires.
+			if s.online.Lock != "" && a == AlgorithmInplace {
+				b.Comma().P("LOCK=" + s.online.Lock)
+			}
+		}
 		return b.String(), nil
 	}
 	cmd, err := build(changes)
@@ -443,6 +855,17 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 		},
 		Comment: fmt.Sprintf("modify %q table", t.Name),
 	}
+	switch {
+	case s.oscT[t]:
+		change.Comment += " using " + s.osc.Tool
+	case s.online != nil:
+		switch a := s.algorithms(changes); a {
+		case AlgorithmCopy:
+			change.Comment += fmt.Sprintf(" (algorithm: %s, requires a full table copy)", a)
+		default:
+			change.Comment += fmt.Sprintf(" (algorithm: %s)", a)
+		}
+	}
 	if reversible {
 		// Changes should be reverted in
 		// a reversed order they were created.
@@ -455,6 +878,273 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 	return nil
 }
 
+// oscTables collects the modified tables that should be
+// altered using the configured online schema change tool.
+func (s *state) oscTables(ctx context.Context, changes []schema.Change) error {
+	switch s.osc.Tool {
+	case ToolGhOst, ToolPtOSC:
+	default:
+		return fmt.Errorf("mysql: unknown online schema change tool %q", s.osc.Tool)
+	}
+	s.oscT = make(map[*schema.Table]bool)
+	for _, c := range changes {
+		m, ok := c.(*schema.ModifyTable)
+		if !ok {
+			continue
+		}
+		for _, n := range s.osc.Tables {
+			if n == m.T.Name || m.T.Schema != nil && n == m.T.Schema.Name+"."+m.T.Name {
+				s.oscT[m.T] = true
+			}
+		}
+		if s.oscT[m.T] || s.osc.MinRows <= 0 {
+			continue
+		}
+		var ns *string
+		if m.T.Schema != nil && m.T.Schema.Name != "" {
+			ns = &m.T.Schema.Name
+		}
+		rows, err := s.QueryContext(ctx, tableRowsQuery, ns, m.T.Name)
+		if err != nil {
+			return fmt.Errorf("mysql: query table %q rows: %w", m.T.Name, err)
+		}
+		var n sql.NullInt64
+		if err := sqlx.ScanOne(rows, &n); err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("mysql: scan table %q rows: %w", m.T.Name, err)
+		}
+		s.oscT[m.T] = n.Int64 >= s.osc.MinRows
+	}
+	return nil
+}
+
+// oscCommand returns the command line for executing
+// the ALTER TABLE specification using the tool.
+func (s *state) oscCommand(t *schema.Table, spec string) string {
+	quote := func(s string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`").Replace(s) + `"`
+	}
+	var b strings.Builder
+	b.WriteString(s.osc.Tool)
+	switch s.osc.Tool {
+	case ToolGhOst:
+		if t.Schema != nil && t.Schema.Name != "" {
+			b.WriteString(" --database=" + quote(t.Schema.Name))
+		}
+		b.WriteString(" --table=" + quote(t.Name))
+		b.WriteString(" --alter=" + quote(spec))
+	case ToolPtOSC:
+		b.WriteString(" --alter=" + quote(spec))
+		dsn := "t=" + t.Name
+		if t.Schema != nil && t.Schema.Name != "" {
+			dsn = "D=" + t.Schema.Name + "," + dsn
+		}
+		b.WriteString(" " + quote(dsn))
+	}
+	b.WriteString(" --execute")
+	return b.String()
+}
+
+// TranslateCommand implements the migrate.CommandTranslator interface. It translates the
+// gh-ost and pt-online-schema-change invocations planned by the OnlineSchemaChange option
+// back into the ALTER TABLE statements they execute, for replaying them on dev databases.
+func (d *Driver) TranslateCommand(stmt string) (string, bool, error) {
+	tool := migrate.StmtCommand(stmt)
+	if tool != ToolGhOst && tool != ToolPtOSC {
+		return "", false, nil
+	}
+	args, err := migrate.CommandArgs(stmt)
+	if err != nil {
+		return "", false, fmt.Errorf("mysql: parse %s command: %w", tool, err)
+	}
+	var (
+		t    = &schema.Table{}
+		spec string
+	)
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		// The DSN of pt-online-schema-change. e.g. D=app,t=users.
+		if !strings.HasPrefix(arg, "-") {
+			for _, kv := range strings.Split(arg, ",") {
+				switch kv := strings.SplitN(kv, "=", 2); {
+				case len(kv) != 2 || tool != ToolPtOSC:
+				case kv[0] == "D":
+					t.Schema = schema.New(kv[1])
+				case kv[0] == "t":
+					t.Name = kv[1]
+				}
+			}
+			continue
+		}
+		kv := strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)
+		switch kv[0] {
+		case "alter", "database", "table":
+		default:
+			continue
+		}
+		// Flag values can be given as separate arguments.
+		if len(kv) == 1 && i+1 < len(args) {
+			kv = append(kv, args[i+1])
+			i++
+		}
+		switch {
+		case len(kv) != 2:
+		case kv[0] == "alter":
+			spec = kv[1]
+		case kv[0] == "database" && tool == ToolGhOst:
+			t.Schema = schema.New(kv[1])
+		case kv[0] == "table" && tool == ToolGhOst:
+			t.Name = kv[1]
+		}
+	}
+	if t.Name == "" || spec == "" {
+		return "", false, fmt.Errorf("mysql: missing table name or alter specification in %s command", tool)
+	}
+	return Build("ALTER TABLE").Table(t).P(spec).String(), true, nil
+}
+
+// splitCopy splits the given table changes into the ones that can be executed in-place
+// and the ones that require a full table copy. Index and foreign-key changes that refer
+// to columns that are copied (e.g. an index on a STORED generated column, or the key of
+// an AUTO_INCREMENT column) are kept with them, as they cannot be executed separately.
+func (s *state) splitCopy(changes []schema.Change) (inplace, copied []schema.Change) {
+	columns := make(map[string]bool)
+	for _, c := range changes {
+		if s.algorithm(c) != AlgorithmCopy {
+			continue
+		}
+		switch c := c.(type) {
+		case *schema.AddColumn:
+			columns[c.C.Name] = true
+		case *schema.ModifyColumn:
+			columns[c.From.Name], columns[c.To.Name] = true, true
+		}
+	}
+	for _, c := range changes {
+		if s.algorithm(c) == AlgorithmCopy || refersTo(c, columns) {
+			copied = append(copied, c)
+		} else {
+			inplace = append(inplace, c)
+		}
+	}
+	return inplace, copied
+}
+
+// refersTo reports if the given index or foreign-key change refers to one of the columns.
+func refersTo(c schema.Change, columns map[string]bool) bool {
+	var refs []*schema.Column
+	switch c := c.(type) {
+	case *schema.AddIndex:
+		refs = partColumns(c.I)
+	case *schema.DropIndex:
+		refs = partColumns(c.I)
+	case *schema.RenameIndex:
+		refs = partColumns(c.To)
+	case *schema.AddForeignKey:
+		refs = c.F.Columns
+	case *schema.DropForeignKey:
+		refs = c.F.Columns
+	}
+	for _, c := range refs {
+		if columns[c.Name] {
+			return true
+		}
+	}
+	return false
+}
+
+// partColumns returns the columns of the index parts.
+func partColumns(idx *schema.Index) []*schema.Column {
+	var columns []*schema.Column
+	for _, p := range idx.Parts {
+		if p.C != nil {
+			columns = append(columns, p.C)
+		}
+	}
+	return columns
+}
+
+// algorithms returns the strongest algorithm required for
+// executing the given changes in one ALTER TABLE statement.
+func (s *state) algorithms(changes []schema.Change) string {
+	a := AlgorithmInstant
+	for _, c := range changes {
+		switch s.algorithm(c) {
+		case AlgorithmCopy:
+			return AlgorithmCopy
+		case AlgorithmInplace:
+			a = AlgorithmInplace
+		}
+	}
+	return a
+}
+
+// algorithm returns the algorithm MySQL is expected to use for
+// executing the given table change. Note, the returned value is
+// an estimation based on the server version, as the actual
+// algorithm may also depend on the table state (e.g. FULLTEXT
+// indexes or the row format).
+func (s *state) algorithm(c schema.Change) string {
+	instant := func(ok bool) string {
+		if ok {
+			return AlgorithmInstant
+		}
+		return AlgorithmInplace
+	}
+	switch c := c.(type) {
+	case *schema.AddColumn:
+		x := &schema.GeneratedExpr{}
+		switch {
+		case sqlx.Has(c.C.Attrs, &AutoIncrement{}), sqlx.Has(c.C.Attrs, x) && storedOrPersisted(x.Type):
+			return AlgorithmCopy
+		default:
+			return instant(s.SupportsInstantAddColumn())
+		}
+	case *schema.DropColumn:
+		return instant(s.SupportsInstantDropColumn())
+	case *schema.RenameColumn:
+		return instant(s.SupportsInstantRenameColumn())
+	case *schema.ModifyColumn:
+		switch {
+		case c.Change.Is(schema.ChangeType | schema.ChangeCharset | schema.ChangeCollate | schema.ChangeGenerated | schema.ChangeAttr):
+			return AlgorithmCopy
+		case c.Change.Is(schema.ChangeNull | schema.ChangeComment):
+			return AlgorithmInplace
+		case c.Change.Is(schema.ChangeDefault):
+			return instant(s.SupportsInstantAlter())
+		default:
+			return AlgorithmCopy
+		}
+	case *schema.RenameIndex:
+		return instant(s.SupportsInstantAlter())
+	case *schema.AddIndex, *schema.DropIndex, *schema.DropForeignKey, *schema.DropCheck:
+		return AlgorithmInplace
+	case *schema.AddAttr:
+		return attrAlgorithm(c.A)
+	case *schema.ModifyAttr:
+		return attrAlgorithm(c.To)
+	default:
+		// Foreign keys are added using COPY unless foreign_key_checks
+		// is disabled, and CHECK constraints are validated by copying.
+		return AlgorithmCopy
+	}
+}
+
+// attrAlgorithm returns the algorithm for adding
+// or changing the given table attribute.
+func attrAlgorithm(a schema.Attr) string {
+	switch a.(type) {
+	case *schema.Comment, *AutoIncrement, *schema.Charset, *schema.Collation:
+		return AlgorithmInplace
+	default:
+		return AlgorithmCopy
+	}
+}
+
+// storedOrPersisted reports if the generated column type is stored.
+func storedOrPersisted(t string) bool {
+	return strings.EqualFold(t, stored) || strings.EqualFold(t, persistent)
+}
+
 func (s *state) renameTable(c *schema.RenameTable) {
 	s.append(&migrate.Change{
 		Source:  c,
@@ -653,8 +1343,8 @@ func (s *state) collation(t *schema.Table) string {
 	return s.collate
 }
 
-func (s *state) append(c *migrate.Change) {
-	s.Changes = append(s.Changes, c)
+func (s *state) append(c ...*migrate.Change) {
+	s.Changes = append(s.Changes, c...)
 }
 
 func (*state) attr(b *sqlx.Builder, attrs ...schema.Attr) {
@@ -175,6 +175,7 @@ func TestPlanChanges(t *testing.T) {
 	tests := []struct {
 		version  string
 		changes  []schema.Change
+		opts     []migrate.PlanOption
 		wantPlan *migrate.Plan
 		wantErr  bool
 	}{
@@ -857,6 +858,329 @@ func TestPlanChanges(t *testing.T) {
 				},
 			},
 		},
+		{
+			changes: []schema.Change{
+				&schema.AddTable{
+					T: func() *schema.Table {
+						c := schema.NewIntColumn("id", "int")
+						return schema.NewTable("logs").AddColumns(c).AddAttrs(&Partition{T: PartitionTypeRange, Columns: []*schema.Column{c}, Parts: []*PartitionPart{{Name: "p0", Values: "10"}, {Name: "p1", Values: "MAXVALUE"}}})
+					}(),
+				},
+				&schema.AddTable{
+					T: func() *schema.Table {
+						c := schema.NewIntColumn("id", "int")
+						return schema.NewTable("events").AddColumns(c).AddAttrs(&Partition{T: PartitionTypeKey, Linear: true, Count: 4})
+					}(),
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{Cmd: "CREATE TABLE `logs` (`id` int NOT NULL) PARTITION BY RANGE COLUMNS (`id`) (PARTITION `p0` VALUES LESS THAN (10), PARTITION `p1` VALUES LESS THAN MAXVALUE)", Reverse: "DROP TABLE `logs`"},
+					{Cmd: "CREATE TABLE `events` (`id` int NOT NULL) PARTITION BY LINEAR KEY () PARTITIONS 4", Reverse: "DROP TABLE `events`"},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("logs"),
+					Changes: []schema.Change{
+						&schema.AddAttr{A: &Partition{T: PartitionTypeHash, Expr: "id", Count: 4}},
+					},
+				},
+				&schema.ModifyTable{
+					T: schema.NewTable("events"),
+					Changes: []schema.Change{
+						&schema.ModifyAttr{
+							From: &Partition{T: PartitionTypeHash, Expr: "id", Count: 4},
+							To:   &Partition{T: PartitionTypeHash, Expr: "id", Count: 2},
+						},
+						&schema.AddAttr{A: &schema.Comment{Text: "c"}},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Changes: []*migrate.Change{
+					{Cmd: "ALTER TABLE `logs` PARTITION BY HASH (id) PARTITIONS 4", Reverse: "ALTER TABLE `logs` REMOVE PARTITIONING"},
+					{Cmd: "ALTER TABLE `events` COMMENT \"c\""},
+					{Cmd: "ALTER TABLE `events` COALESCE PARTITION 2", Reverse: "ALTER TABLE `events` ADD PARTITION PARTITIONS 2"},
+				},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("logs"),
+					Changes: []schema.Change{
+						&schema.ModifyAttr{
+							From: &Partition{T: PartitionTypeRange, Expr: "year(c)", Parts: []*PartitionPart{{Name: "p0", Values: "2021"}}},
+							To:   &Partition{T: PartitionTypeRange, Expr: "year(c)", Parts: []*PartitionPart{{Name: "p0", Values: "2021"}, {Name: "p1", Values: "2022"}}},
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes:    []*migrate.Change{{Cmd: "ALTER TABLE `logs` ADD PARTITION (PARTITION `p1` VALUES LESS THAN (2022))", Reverse: "ALTER TABLE `logs` DROP PARTITION `p1`"}},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("logs"),
+					Changes: []schema.Change{
+						&schema.ModifyAttr{
+							From: &Partition{T: PartitionTypeRange, Expr: "year(c)", Parts: []*PartitionPart{{Name: "p0", Values: "2021"}, {Name: "p1", Values: "2022"}, {Name: "p2", Values: "MAXVALUE"}}},
+							To:   &Partition{T: PartitionTypeRange, Expr: "year(c)", Parts: []*PartitionPart{{Name: "p0", Values: "2021"}, {Name: "p2", Values: "MAXVALUE"}}},
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes:    []*migrate.Change{{Cmd: "ALTER TABLE `logs` DROP PARTITION `p1`", Reverse: "ALTER TABLE `logs` REORGANIZE PARTITION `p2` INTO (PARTITION `p1` VALUES LESS THAN (2022), PARTITION `p2` VALUES LESS THAN MAXVALUE)"}},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("logs"),
+					Changes: []schema.Change{
+						&schema.ModifyAttr{
+							From: &Partition{T: PartitionTypeRange, Expr: "year(c)", Parts: []*PartitionPart{{Name: "p0", Values: "2021"}, {Name: "p2", Values: "MAXVALUE"}}},
+							To:   &Partition{T: PartitionTypeRange, Expr: "year(c)", Parts: []*PartitionPart{{Name: "p0", Values: "2021"}, {Name: "p1", Values: "2022"}, {Name: "p2", Values: "MAXVALUE"}}},
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes:    []*migrate.Change{{Cmd: "ALTER TABLE `logs` REORGANIZE PARTITION `p2` INTO (PARTITION `p1` VALUES LESS THAN (2022), PARTITION `p2` VALUES LESS THAN MAXVALUE)", Reverse: "ALTER TABLE `logs` DROP PARTITION `p1`"}},
+			},
+		},
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("logs"),
+					Changes: []schema.Change{
+						&schema.ModifyAttr{
+							From: &Partition{T: PartitionTypeList, Expr: "year(c)", Parts: []*PartitionPart{{Name: "p0", Values: "1,2"}, {Name: "p1", Values: "3"}}},
+							To:   &Partition{T: PartitionTypeList, Expr: "year(c)", Parts: []*PartitionPart{{Name: "p0", Values: "1"}, {Name: "p2", Values: "2"}, {Name: "p1", Values: "3"}}},
+						},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes:    []*migrate.Change{{Cmd: "ALTER TABLE `logs` REORGANIZE PARTITION `p0` INTO (PARTITION `p0` VALUES IN (1), PARTITION `p2` VALUES IN (2))", Reverse: "ALTER TABLE `logs` REORGANIZE PARTITION `p0`, `p2` INTO (PARTITION `p0` VALUES IN (1,2))"}},
+			},
+		},
+		// Roles are created first and dropped last. Privileges are granted after the tables were created.
+		{
+			changes: func() []schema.Change {
+				s := schema.New("test")
+				users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+				s.AddTables(users)
+				app, old := &Role{Name: "app", Host: "%", Login: true}, &Role{Name: "old", Host: "%"}
+				return []schema.Change{
+					&schema.AddTable{T: users},
+					&schema.AddObject{O: &Permission{Role: app, Schema: s, Table: users, Privileges: []string{"SELECT", "INSERT"}, Grantable: true}},
+					&schema.AddObject{O: &Permission{Role: app, Schema: s, Table: users, Column: users.Columns[0], Privileges: []string{"UPDATE"}}},
+					&schema.AddObject{O: app},
+					&schema.DropObject{O: &Permission{Role: old, Schema: s, Privileges: []string{"CREATE VIEW"}, Grantable: true}},
+					&schema.DropObject{O: old},
+					&schema.ModifyObject{From: &Role{Name: "admin", Host: "localhost"}, To: &Role{Name: "admin", Host: "localhost", Login: true}},
+					&schema.ModifyObject{
+						From: &Permission{Role: app, Schema: s, Privileges: []string{"SELECT"}},
+						To:   &Permission{Role: app, Schema: s, Privileges: []string{"SELECT", "DELETE"}},
+					},
+				}
+			}(),
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{Cmd: "CREATE USER 'app'@'%'", Reverse: "DROP USER 'app'@'%'"},
+					{Cmd: "ALTER USER 'admin'@'localhost' ACCOUNT UNLOCK", Reverse: "ALTER USER 'admin'@'localhost' ACCOUNT LOCK"},
+					{Cmd: "CREATE TABLE `test`.`users` (`id` int NOT NULL)", Reverse: "DROP TABLE `test`.`users`"},
+					{Cmd: "REVOKE CREATE VIEW, GRANT OPTION ON `test`.* FROM 'old'@'%'", Reverse: "GRANT CREATE VIEW ON `test`.* TO 'old'@'%' WITH GRANT OPTION"},
+					{Cmd: "GRANT SELECT, INSERT ON `test`.`users` TO 'app'@'%' WITH GRANT OPTION", Reverse: "REVOKE SELECT, INSERT, GRANT OPTION ON `test`.`users` FROM 'app'@'%'"},
+					{Cmd: "GRANT UPDATE (`id`) ON `test`.`users` TO 'app'@'%'", Reverse: "REVOKE UPDATE (`id`) ON `test`.`users` FROM 'app'@'%'"},
+					{Cmd: "GRANT DELETE ON `test`.* TO 'app'@'%'", Reverse: "REVOKE DELETE ON `test`.* FROM 'app'@'%'"},
+					{Cmd: "DROP ROLE 'old'@'%'", Reverse: "CREATE ROLE 'old'@'%'"},
+				},
+			},
+		},
+		// MariaDB roles are not bound to a host, and cannot be changed to users.
+		{
+			version: "10.5.8-MariaDB",
+			changes: []schema.Change{
+				&schema.AddObject{O: &Role{Name: "reader"}},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes:    []*migrate.Change{{Cmd: "CREATE ROLE 'reader'", Reverse: "DROP ROLE 'reader'"}},
+			},
+		},
+		{
+			version: "10.5.8-MariaDB",
+			changes: []schema.Change{
+				&schema.ModifyObject{From: &Role{Name: "reader"}, To: &Role{Name: "reader", Login: true}},
+			},
+			wantErr: true,
+		},
+		// Changes that require a table copy are split from the rest.
+		{
+			version: "8.0.30",
+			opts:    []migrate.PlanOption{&OnlineDDL{Clause: true, Lock: LockNone}},
+			changes: func() []schema.Change {
+				users := &schema.Table{
+					Name:   "users",
+					Schema: schema.New("test"),
+					Columns: []*schema.Column{
+						{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
+					},
+				}
+				name := &schema.Column{Name: "name", Type: &schema.ColumnType{Type: &schema.StringType{T: "varchar", Size: 255}, Null: true}}
+				return []schema.Change{
+					&schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.AddColumn{C: name},
+							&schema.ModifyColumn{
+								From:   &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "int"}}},
+								To:     users.Columns[0],
+								Change: schema.ChangeType,
+							},
+							&schema.AddIndex{I: &schema.Index{Name: "name", Table: users, Parts: []*schema.IndexPart{{C: name}}}},
+						},
+					},
+				}
+			}(),
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     "ALTER TABLE `test`.`users` ADD COLUMN `name` varchar(255) NULL, ADD INDEX `name` (`name`), ALGORITHM=INPLACE, LOCK=NONE",
+						Reverse: "ALTER TABLE `test`.`users` DROP INDEX `name`, DROP COLUMN `name`, ALGORITHM=INPLACE, LOCK=NONE",
+						Comment: `modify "users" table (algorithm: INPLACE)`,
+					},
+					{
+						Cmd:     "ALTER TABLE `test`.`users` MODIFY COLUMN `id` bigint NOT NULL, ALGORITHM=COPY",
+						Reverse: "ALTER TABLE `test`.`users` MODIFY COLUMN `id` int NOT NULL, ALGORITHM=COPY",
+						Comment: `modify "users" table (algorithm: COPY, requires a full table copy)`,
+					},
+				},
+			},
+		},
+		// Index changes of copied columns are kept in the same statement.
+		{
+			version: "8.0.30",
+			opts:    []migrate.PlanOption{&OnlineDDL{Clause: true, Lock: LockNone}},
+			changes: func() []schema.Change {
+				users := &schema.Table{
+					Name:   "users",
+					Schema: schema.New("test"),
+					Columns: []*schema.Column{
+						{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}, Attrs: []schema.Attr{&AutoIncrement{}}},
+					},
+				}
+				full := &schema.Column{
+					Name:  "full_name",
+					Type:  &schema.ColumnType{Type: &schema.StringType{T: "varchar", Size: 255}, Null: true},
+					Attrs: []schema.Attr{&schema.GeneratedExpr{Expr: "`id`", Type: "STORED"}},
+				}
+				age := &schema.Column{Name: "age", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "int"}, Null: true}}
+				return []schema.Change{
+					&schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.ModifyColumn{
+								From:   &schema.Column{Name: "id", Type: &schema.ColumnType{Type: &schema.IntegerType{T: "bigint"}}},
+								To:     users.Columns[0],
+								Change: schema.ChangeAttr,
+							},
+							&schema.AddColumn{C: full},
+							&schema.AddColumn{C: age},
+							&schema.AddIndex{I: &schema.Index{Name: "id", Unique: true, Table: users, Parts: []*schema.IndexPart{{C: users.Columns[0]}}}},
+							&schema.AddIndex{I: &schema.Index{Name: "full_name", Table: users, Parts: []*schema.IndexPart{{C: full}}}},
+							&schema.AddIndex{I: &schema.Index{Name: "age", Table: users, Parts: []*schema.IndexPart{{C: age}}}},
+						},
+					},
+				}
+			}(),
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     "ALTER TABLE `test`.`users` ADD COLUMN `age` int NULL, ADD INDEX `age` (`age`), ALGORITHM=INPLACE, LOCK=NONE",
+						Reverse: "ALTER TABLE `test`.`users` DROP INDEX `age`, DROP COLUMN `age`, ALGORITHM=INPLACE, LOCK=NONE",
+						Comment: `modify "users" table (algorithm: INPLACE)`,
+					},
+					{
+						Cmd:     "ALTER TABLE `test`.`users` MODIFY COLUMN `id` bigint NOT NULL AUTO_INCREMENT, ADD COLUMN `full_name` varchar(255) AS (`id`) STORED NULL, ADD UNIQUE INDEX `id` (`id`), ADD INDEX `full_name` (`full_name`), ALGORITHM=COPY",
+						Reverse: "ALTER TABLE `test`.`users` DROP INDEX `full_name`, DROP INDEX `id`, DROP COLUMN `full_name`, MODIFY COLUMN `id` bigint NOT NULL, ALGORITHM=COPY",
+						Comment: `modify "users" table (algorithm: COPY, requires a full table copy)`,
+					},
+				},
+			},
+		},
+		// The LOCK clause is not allowed with the INSTANT algorithm.
+		{
+			version: "8.0.30",
+			opts:    []migrate.PlanOption{OnlineDDL{Clause: true, Lock: LockNone}},
+			changes: []schema.Change{
+				&schema.ModifyTable{
+					T: schema.NewTable("users"),
+					Changes: []schema.Change{
+						&schema.AddColumn{C: schema.NewNullStringColumn("name", "varchar(255)")},
+					},
+				},
+			},
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     "ALTER TABLE `users` ADD COLUMN `name` varchar(255) NULL, ALGORITHM=INSTANT",
+						Reverse: "ALTER TABLE `users` DROP COLUMN `name`, ALGORITHM=INSTANT",
+						Comment: `modify "users" table (algorithm: INSTANT)`,
+					},
+				},
+			},
+		},
+		// Older versions do not support INSTANT, and changes are annotated without the clauses.
+		{
+			version: "5.7.35",
+			opts:    []migrate.PlanOption{&OnlineDDL{}},
+			changes: func() []schema.Change {
+				users := schema.NewTable("users")
+				owners := schema.NewTable("owners").AddColumns(schema.NewIntColumn("id", "int"))
+				return []schema.Change{
+					&schema.ModifyTable{
+						T: users,
+						Changes: []schema.Change{
+							&schema.AddColumn{C: schema.NewNullStringColumn("name", "varchar(255)")},
+							&schema.AddForeignKey{F: &schema.ForeignKey{Symbol: "owner", Table: users, Columns: []*schema.Column{schema.NewIntColumn("owner_id", "int")}, RefTable: owners, RefColumns: owners.Columns}},
+						},
+					},
+				}
+			}(),
+			wantPlan: &migrate.Plan{
+				Reversible: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     "ALTER TABLE `users` ADD COLUMN `name` varchar(255) NULL",
+						Reverse: "ALTER TABLE `users` DROP COLUMN `name`",
+						Comment: `modify "users" table (algorithm: INPLACE)`,
+					},
+					{
+						Cmd:     "ALTER TABLE `users` ADD CONSTRAINT `owner` FOREIGN KEY (`owner_id`) REFERENCES `owners` (`id`)",
+						Reverse: "ALTER TABLE `users` DROP FOREIGN KEY `owner`",
+						Comment: `modify "users" table (algorithm: COPY, requires a full table copy)`,
+					},
+				},
+			},
+		},
 	}
 	for i, tt := range tests {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
@@ -865,7 +1189,7 @@ func TestPlanChanges(t *testing.T) {
 			}
 			db, _, err := newMigrate(tt.version)
 			require.NoError(t, err)
-			plan, err := db.PlanChanges(context.Background(), "wantPlan", tt.changes)
+			plan, err := db.PlanChanges(context.Background(), "wantPlan", tt.changes, tt.opts...)
 			if tt.wantErr {
 				require.Error(t, err, "expect plan to fail")
 				return
@@ -878,11 +1202,75 @@ func TestPlanChanges(t *testing.T) {
 			for i, c := range plan.Changes {
 				require.Equal(t, tt.wantPlan.Changes[i].Cmd, c.Cmd)
 				require.Equal(t, tt.wantPlan.Changes[i].Reverse, c.Reverse)
+				if tt.wantPlan.Changes[i].Comment != "" {
+					require.Equal(t, tt.wantPlan.Changes[i].Comment, c.Comment)
+				}
 			}
 		})
 	}
 }
 
+func TestPlanChanges_OnlineSchemaChange(t *testing.T) {
+	var (
+		users = schema.NewTable("users").SetSchema(schema.New("test"))
+		pets  = schema.NewTable("pets").SetSchema(schema.New("test"))
+		name  = schema.NewNullStringColumn("name", "varchar(255)")
+	)
+	changes := []schema.Change{
+		&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddColumn{C: name}}},
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{&schema.AddColumn{C: name}}},
+	}
+
+	// Tables are selected by name.
+	db, _, err := newMigrate("8.0.30")
+	require.NoError(t, err)
+	plan, err := db.PlanChanges(context.Background(), "plan", changes, &OnlineSchemaChange{Tool: ToolGhOst, Tables: []string{"test.users"}})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, "gh-ost --database=\"test\" --table=\"users\" --alter=\"ADD COLUMN \\`name\\` varchar(255) NULL\" --execute", plan.Changes[0].Cmd)
+	require.Equal(t, "gh-ost --database=\"test\" --table=\"users\" --alter=\"DROP COLUMN \\`name\\`\" --execute", plan.Changes[0].Reverse)
+	require.Equal(t, `modify "users" table using gh-ost`, plan.Changes[0].Comment)
+	require.Equal(t, "ALTER TABLE `test`.`pets` ADD COLUMN `name` varchar(255) NULL", plan.Changes[1].Cmd)
+
+	// Tables are selected by their size.
+	db, m, err := newMigrate("8.0.30")
+	require.NoError(t, err)
+	m.ExpectQuery(sqltest.Escape(tableRowsQuery)).
+		WithArgs("test", "users").
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_ROWS"}).AddRow(5000000))
+	m.ExpectQuery(sqltest.Escape(tableRowsQuery)).
+		WithArgs("test", "pets").
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_ROWS"}).AddRow(100))
+	plan, err = db.PlanChanges(context.Background(), "plan", changes, OnlineSchemaChange{Tool: ToolPtOSC, MinRows: 1000000})
+	require.NoError(t, err)
+	require.NoError(t, m.ExpectationsWereMet())
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, "pt-online-schema-change --alter=\"ADD COLUMN \\`name\\` varchar(255) NULL\" \"D=test,t=users\" --execute", plan.Changes[0].Cmd)
+	require.Equal(t, "ALTER TABLE `test`.`pets` ADD COLUMN `name` varchar(255) NULL", plan.Changes[1].Cmd)
+
+	_, err = db.PlanChanges(context.Background(), "plan", changes, &OnlineSchemaChange{Tool: "unknown"})
+	require.EqualError(t, err, `mysql: unknown online schema change tool "unknown"`)
+}
+
+func TestDriver_TranslateCommand(t *testing.T) {
+	drv := &Driver{}
+	for stmt, expected := range map[string]string{
+		"gh-ost --database=\"test\" --table=\"users\" --alter=\"ADD COLUMN \\`name\\` varchar(255) NULL\" --execute;": "ALTER TABLE `test`.`users` ADD COLUMN `name` varchar(255) NULL",
+		"pt-online-schema-change --alter=\"ADD COLUMN \\`name\\` varchar(255) NULL\" \"D=test,t=users\" --execute":    "ALTER TABLE `test`.`users` ADD COLUMN `name` varchar(255) NULL",
+		"-- Flag values can be separate arguments.\ngh-ost --table users --alter 'DROP COLUMN c' --execute":           "ALTER TABLE `users` DROP COLUMN c",
+	} {
+		s, ok, err := drv.TranslateCommand(stmt)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, expected, s)
+	}
+	_, ok, err := drv.TranslateCommand("ALTER TABLE `users` DROP COLUMN c")
+	require.NoError(t, err)
+	require.False(t, ok)
+	_, _, err = drv.TranslateCommand("gh-ost --table=users --execute")
+	require.EqualError(t, err, "mysql: missing table name or alter specification in gh-ost command")
+}
+
 func newMigrate(version string) (migrate.PlanApplier, *mock, error) {
 	db, m, err := sqlmock.New()
 	if err != nil {
@@ -19,8 +19,10 @@ import (
 )
 
 type doc struct {
-	Tables  []*sqlspec.Table  `spec:"table"`
-	Schemas []*sqlspec.Schema `spec:"schema"`
+	Tables      []*sqlspec.Table           `spec:"table"`
+	Schemas     []*sqlspec.Schema          `spec:"schema"`
+	Roles       []*specutil.RoleSpec       `spec:"role"`
+	Permissions []*specutil.PermissionSpec `spec:"permission"`
 }
 
 // evalSpec evaluates an Atlas DDL document into v using the input.
@@ -44,10 +46,16 @@ func evalSpec(p *hclparse.Parser, v interface{}, input map[string]string) error
 				return err
 			}
 		}
+		if err := convertRoles(d.Roles, d.Permissions, v); err != nil {
+			return err
+		}
 	case *schema.Schema:
 		if len(d.Schemas) != 1 {
 			return fmt.Errorf("mysql: expecting document to contain a single schema, got %d", len(d.Schemas))
 		}
+		if len(d.Roles) > 0 || len(d.Permissions) > 0 {
+			return errors.New("mysql: roles and permissions can be evaluated only into a *schema.Realm")
+		}
 		var r schema.Realm
 		if err := specutil.Scan(&r, d.Schemas, d.Tables, convertTable); err != nil {
 			return err
@@ -65,7 +73,27 @@ func evalSpec(p *hclparse.Parser, v interface{}, input map[string]string) error
 
 // MarshalSpec marshals v into an Atlas DDL document using a schemahcl.Marshaler.
 func MarshalSpec(v interface{}, marshaler schemahcl.Marshaler) ([]byte, error) {
-	return specutil.Marshal(v, marshaler, schemaSpec)
+	r, ok := v.(*schema.Realm)
+	if !ok || len(r.Objects) == 0 {
+		return specutil.Marshal(v, marshaler, schemaSpec)
+	}
+	var d doc
+	for _, s := range r.Schemas {
+		spec, tables, err := schemaSpec(s)
+		if err != nil {
+			return nil, fmt.Errorf("mysql: failed converting schema to spec: %w", err)
+		}
+		d.Tables = append(d.Tables, tables...)
+		d.Schemas = append(d.Schemas, spec)
+	}
+	if err := specutil.QualifyDuplicates(d.Tables); err != nil {
+		return nil, err
+	}
+	var err error
+	if d.Roles, d.Permissions, err = roleSpecs(r); err != nil {
+		return nil, fmt.Errorf("mysql: failed converting roles to spec: %w", err)
+	}
+	return marshaler.MarshalSpec(&d)
 }
 
 var (
@@ -73,8 +101,10 @@ var (
 		schemahcl.WithTypes(TypeRegistry.Specs()),
 		schemahcl.WithScopedEnums("table.index.type", IndexTypeBTree, IndexTypeHash, IndexTypeFullText, IndexTypeSpatial),
 		schemahcl.WithScopedEnums("table.column.as.type", stored, persistent, virtual),
+		schemahcl.WithScopedEnums("table.partition.type", PartitionTypeRange, PartitionTypeList, PartitionTypeHash, PartitionTypeKey),
 		schemahcl.WithScopedEnums("table.foreign_key.on_update", specutil.ReferenceVars...),
 		schemahcl.WithScopedEnums("table.foreign_key.on_delete", specutil.ReferenceVars...),
+		schemahcl.WithScopedEnums("permission.privileges", privileges...),
 	)
 	// MarshalHCL marshals v into an Atlas HCL DDL document.
 	MarshalHCL = schemahcl.MarshalerFunc(func(v interface{}) ([]byte, error) {
@@ -88,6 +118,121 @@ var (
 	EvalHCLBytes = specutil.HCLBytesFunc(EvalHCL)
 )
 
+// privileges lists the privileges that can be granted on schemas, tables and columns.
+// Privileges that contain spaces are written with underscores (e.g. CREATE_VIEW).
+var privileges = []string{
+	"SELECT", "INSERT", "UPDATE", "DELETE", "CREATE", "DROP", "REFERENCES", "INDEX", "ALTER",
+	"CREATE_VIEW", "SHOW_VIEW", "CREATE_ROUTINE", "ALTER_ROUTINE", "EXECUTE", "EVENT", "TRIGGER",
+	"CREATE_TEMPORARY_TABLES", "LOCK_TABLES", "DELETE_HISTORY",
+}
+
+// defaultHost is the host of accounts that are defined without one.
+const defaultHost = "%"
+
+// convertRoles converts the role and permission specs into Roles and
+// Permissions, and adds them to the realm objects.
+func convertRoles(roles []*specutil.RoleSpec, perms []*specutil.PermissionSpec, r *schema.Realm) error {
+	for _, spec := range roles {
+		if _, ok := roleByName(r, spec.Name); ok {
+			return fmt.Errorf("mysql: role %q is defined more than once", spec.Name)
+		}
+		role := &Role{Name: spec.Name, Host: defaultHost}
+		if attr, ok := spec.Attr("host"); ok {
+			h, err := attr.String()
+			if err != nil {
+				return fmt.Errorf("mysql: role %q: %w", spec.Name, err)
+			}
+			role.Host = h
+		}
+		if attr, ok := spec.Attr("login"); ok {
+			b, err := attr.Bool()
+			if err != nil {
+				return fmt.Errorf("mysql: role %q: %w", spec.Name, err)
+			}
+			role.Login = b
+		}
+		r.AddObjects(role)
+	}
+	for _, spec := range perms {
+		p, err := convertPermission(spec, r)
+		if err != nil {
+			return err
+		}
+		r.AddObjects(p)
+	}
+	return nil
+}
+
+// roleByName returns the first role in the realm with the given name.
+func roleByName(r *schema.Realm, name string) (*Role, bool) {
+	for _, o := range r.Objects {
+		if role, ok := o.(*Role); ok && role.Name == name {
+			return role, true
+		}
+	}
+	return nil, false
+}
+
+// convertPermission converts a PermissionSpec into a Permission.
+func convertPermission(spec *specutil.PermissionSpec, r *schema.Realm) (*Permission, error) {
+	g, err := specutil.ConvertGrant(spec, r)
+	if err != nil {
+		return nil, fmt.Errorf("mysql: %w", err)
+	}
+	role, ok := roleByName(r, g.Role)
+	if !ok {
+		return nil, fmt.Errorf("mysql: permission: role %q was not found", g.Role)
+	}
+	return &Permission{
+		Role:       role,
+		Schema:     g.Schema,
+		Table:      g.Table,
+		Column:     g.Column,
+		Privileges: g.Privileges,
+		Grantable:  g.Grantable,
+	}, nil
+}
+
+// roleSpecs converts the roles and permissions of the realm into specs.
+func roleSpecs(r *schema.Realm) ([]*specutil.RoleSpec, []*specutil.PermissionSpec, error) {
+	var (
+		roles []*specutil.RoleSpec
+		perms []*specutil.PermissionSpec
+	)
+	for _, o := range r.Objects {
+		switch o := o.(type) {
+		case *Role:
+			// Roles are referenced by their names, and
+			// therefore, their names must be unique.
+			if r1, _ := roleByName(r, o.Name); r1 != o {
+				return nil, nil, fmt.Errorf("role %q is defined for multiple hosts", o.Name)
+			}
+			spec := &specutil.RoleSpec{Name: o.Name}
+			if o.Host != defaultHost {
+				spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.StrAttr("host", o.Host))
+			}
+			if o.Login {
+				spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.BoolAttr("login", true))
+			}
+			roles = append(roles, spec)
+		case *Permission:
+			spec, err := specutil.FromGrant(r, &specutil.Grant{
+				Role:       o.Role.Name,
+				Schema:     o.Schema,
+				Table:      o.Table,
+				Column:     o.Column,
+				Privileges: o.Privileges,
+				Grantable:  o.Grantable,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			perms = append(perms, spec)
+		}
+	}
+	return roles, perms, nil
+}
+
 // convertTable converts a sqlspec.Table to a schema.Table. Table conversion is done without converting
 // ForeignKeySpecs into ForeignKeys, as the target tables do not necessarily exist in the schema
 // at this point. Instead, the linking is done by the convertSchema function.
@@ -108,9 +253,72 @@ func convertTable(spec *sqlspec.Table, parent *schema.Schema) (*schema.Table, er
 		}
 		t.AddAttrs(&AutoIncrement{V: v})
 	}
+	if err := convertPartition(spec.Extra, t); err != nil {
+		return nil, err
+	}
 	return t, err
 }
 
+// convertPartition converts and appends the partition block into the table attributes if exists.
+func convertPartition(spec schemahcl.Resource, t *schema.Table) error {
+	r, ok := spec.Resource("partition")
+	if !ok {
+		return nil
+	}
+	var p struct {
+		Type    string           `spec:"type"`
+		Linear  bool             `spec:"linear"`
+		Expr    string           `spec:"expr"`
+		Columns []*schemahcl.Ref `spec:"columns"`
+		Count   int              `spec:"partitions"`
+		Parts   []*struct {
+			Name   string `spec:",name"`
+			Values string `spec:"values"`
+		} `spec:"part"`
+	}
+	if err := r.As(&p); err != nil {
+		return fmt.Errorf("parsing %s.partition: %w", t.Name, err)
+	}
+	key := &Partition{T: strings.ToUpper(p.Type), Linear: p.Linear, Expr: p.Expr, Count: p.Count}
+	switch key.T {
+	case "":
+		return fmt.Errorf("missing attribute %s.partition.type", t.Name)
+	case PartitionTypeRange, PartitionTypeList:
+		if len(p.Parts) == 0 {
+			return fmt.Errorf("missing partitions for %s.partition", t.Name)
+		}
+		if p.Linear || p.Count > 0 {
+			return fmt.Errorf(`attributes "linear" and "partitions" are not supported by %s partitioning of %s`, key.T, t.Name)
+		}
+	case PartitionTypeHash, PartitionTypeKey:
+		if len(p.Parts) > 0 {
+			return fmt.Errorf("%s partitioning of %s does not support part blocks, use the partitions attribute", key.T, t.Name)
+		}
+	}
+	switch {
+	case p.Expr != "" && len(p.Columns) > 0:
+		return fmt.Errorf(`multiple definitions for %s.partition, use "columns" or "expr"`, t.Name)
+	case p.Expr == "" && len(p.Columns) == 0 && key.T != PartitionTypeKey:
+		return fmt.Errorf("missing columns or expression for %s.partition", t.Name)
+	case p.Expr != "" && key.T == PartitionTypeKey:
+		return fmt.Errorf("KEY partitioning of %s does not support expressions", t.Name)
+	case len(p.Columns) > 0 && key.T == PartitionTypeHash:
+		return fmt.Errorf(`HASH partitioning of %s does not support columns, use "expr"`, t.Name)
+	}
+	for _, r := range p.Columns {
+		c, err := specutil.ColumnByRef(t, r)
+		if err != nil {
+			return err
+		}
+		key.Columns = append(key.Columns, c)
+	}
+	for _, part := range p.Parts {
+		key.Parts = append(key.Parts, &PartitionPart{Name: part.Name, Values: part.Values})
+	}
+	t.AddAttrs(key)
+	return nil
+}
+
 // convertIndex converts a sqlspec.Index into a schema.Index.
 func convertIndex(spec *sqlspec.Index, parent *schema.Table) (*schema.Index, error) {
 	idx, err := specutil.Index(spec, parent, convertPart)
@@ -227,9 +435,46 @@ func tableSpec(t *schema.Table) (*sqlspec.Table, error) {
 	if c, ok := hasCollate(t.Attrs, t.Schema.Attrs); ok {
 		ts.Extra.Attrs = append(ts.Extra.Attrs, specutil.StrAttr("collate", c))
 	}
+	if p := (&Partition{}); sqlx.Has(t.Attrs, p) {
+		ts.Extra.Children = append(ts.Extra.Children, fromPartition(p))
+	}
 	return ts, nil
 }
 
+// fromPartition returns the resource spec for representing the partition block.
+func fromPartition(p *Partition) *schemahcl.Resource {
+	r := &schemahcl.Resource{
+		Type: "partition",
+		Attrs: []*schemahcl.Attr{
+			specutil.VarAttr("type", strings.ToUpper(p.T)),
+		},
+	}
+	if p.Linear {
+		r.Attrs = append(r.Attrs, specutil.BoolAttr("linear", true))
+	}
+	if p.Expr != "" {
+		r.Attrs = append(r.Attrs, specutil.StrAttr("expr", p.Expr))
+	}
+	if len(p.Columns) > 0 {
+		columns := make([]schemahcl.Value, 0, len(p.Columns))
+		for _, c := range p.Columns {
+			columns = append(columns, specutil.ColumnRef(c.Name))
+		}
+		r.Attrs = append(r.Attrs, &schemahcl.Attr{K: "columns", V: &schemahcl.ListValue{V: columns}})
+	}
+	if p.Count > 0 {
+		r.Attrs = append(r.Attrs, specutil.IntAttr("partitions", p.Count))
+	}
+	for _, part := range p.Parts {
+		r.Children = append(r.Children, &schemahcl.Resource{
+			Type:  "part",
+			Name:  part.Name,
+			Attrs: []*schemahcl.Attr{specutil.StrAttr("values", part.Values)},
+		})
+	}
+	return r
+}
+
 func indexSpec(idx *schema.Index) (*sqlspec.Index, error) {
 	spec, err := specutil.FromIndex(idx, partAttr)
 	if err != nil {
@@ -502,6 +502,85 @@ schema "test" {
 	require.EqualValues(t, expected, string(buf))
 }
 
+func TestMarshalSpec_Partition(t *testing.T) {
+	c := schema.NewIntColumn("id", TypeInt)
+	s := schema.New("test").
+		AddTables(
+			schema.NewTable("logs").
+				AddColumns(c).
+				AddAttrs(&Partition{
+					T:       PartitionTypeRange,
+					Columns: []*schema.Column{c},
+					Parts:   []*PartitionPart{{Name: "p0", Values: "10"}, {Name: "p1", Values: "MAXVALUE"}},
+				}),
+			schema.NewTable("events").
+				AddColumns(schema.NewIntColumn("id", TypeInt)).
+				AddAttrs(&Partition{T: PartitionTypeHash, Linear: true, Expr: "id", Count: 4}),
+		)
+	buf, err := MarshalSpec(s, hclState)
+	require.NoError(t, err)
+	const expected = `table "logs" {
+  schema = schema.test
+  column "id" {
+    null = false
+    type = int
+  }
+  partition {
+    type    = RANGE
+    columns = [column.id]
+    part "p0" {
+      values = "10"
+    }
+    part "p1" {
+      values = "MAXVALUE"
+    }
+  }
+}
+table "events" {
+  schema = schema.test
+  column "id" {
+    null = false
+    type = int
+  }
+  partition {
+    type       = HASH
+    linear     = true
+    expr       = "id"
+    partitions = 4
+  }
+}
+schema "test" {
+}
+`
+	require.EqualValues(t, expected, string(buf))
+
+	got := &schema.Schema{}
+	require.NoError(t, EvalHCLBytes(buf, got, nil))
+	logs, ok := got.Table("logs")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&Partition{
+		T:       PartitionTypeRange,
+		Columns: logs.Columns,
+		Parts:   []*PartitionPart{{Name: "p0", Values: "10"}, {Name: "p1", Values: "MAXVALUE"}},
+	}}, logs.Attrs)
+	events, ok := got.Table("events")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&Partition{T: PartitionTypeHash, Linear: true, Expr: "id", Count: 4}}, events.Attrs)
+
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+table "logs" {
+	schema = schema.test
+	column "id" { type = int }
+	partition {
+		type = RANGE
+		expr = "id"
+	}
+}
+`), &schema.Schema{}, nil)
+	require.EqualError(t, err, "missing partitions for logs.partition")
+}
+
 func TestMarshalSpec_Check(t *testing.T) {
 	s := schema.New("test").
 		AddTables(
@@ -541,6 +620,69 @@ schema "test" {
 	require.EqualValues(t, expected, string(buf))
 }
 
+func TestMarshalSpec_Roles(t *testing.T) {
+	var (
+		r      = schema.NewRealm(schema.New("test"))
+		users  = schema.NewTable("users").AddColumns(schema.NewIntColumn("id", TypeInt))
+		app    = &Role{Name: "app", Host: "%", Login: true}
+		reader = &Role{Name: "reader", Host: "localhost"}
+	)
+	r.Schemas[0].AddTables(users)
+	r.AddObjects(
+		app, reader,
+		&Permission{Role: app, Schema: r.Schemas[0], Privileges: []string{"SELECT", "CREATE VIEW"}},
+		&Permission{Role: reader, Schema: r.Schemas[0], Table: users, Column: users.Columns[0], Privileges: []string{"SELECT"}, Grantable: true},
+	)
+	buf, err := MarshalSpec(r, hclState)
+	require.NoError(t, err)
+	const expected = `table "users" {
+  schema = schema.test
+  column "id" {
+    null = false
+    type = int
+  }
+}
+schema "test" {
+}
+role "app" {
+  login = true
+}
+role "reader" {
+  host = "localhost"
+}
+permission {
+  to         = role.app
+  on         = schema.test
+  privileges = [SELECT, CREATE_VIEW]
+}
+permission {
+  to         = role.reader
+  on         = table.users.column.id
+  privileges = [SELECT]
+  grantable  = true
+}
+`
+	require.EqualValues(t, expected, string(buf))
+
+	var got schema.Realm
+	err = EvalHCLBytes(buf, &got, nil)
+	require.NoError(t, err)
+	require.Len(t, got.Objects, 4)
+	gotApp, gotReader := got.Objects[0].(*Role), got.Objects[1].(*Role)
+	require.Equal(t, app, gotApp)
+	require.Equal(t, reader, gotReader)
+	gotUsers := got.Schemas[0].Tables[0]
+	require.Equal(t, []schema.Object{
+		&Permission{Role: gotApp, Schema: got.Schemas[0], Privileges: []string{"SELECT", "CREATE VIEW"}},
+		&Permission{Role: gotReader, Schema: got.Schemas[0], Table: gotUsers, Column: gotUsers.Columns[0], Privileges: []string{"SELECT"}, Grantable: true},
+	}, got.Objects[2:])
+
+	// Roles are referenced by their names, and must be unique.
+	r.AddObjects(&Role{Name: "app", Host: "localhost"})
+	_, err = MarshalSpec(r, hclState)
+	require.Error(t, err)
+}
+
 func TestUnmarshalSpec_IndexParts(t *testing.T) {
 	var (
 		s schema.Schema
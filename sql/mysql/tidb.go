@@ -81,7 +81,7 @@ func flat(changes []schema.Change) []schema.Change {
 }
 
 // PlanChanges returns a migration plan for the given schema changes.
-func (p *tplanApply) PlanChanges(ctx context.Context, name string, changes []schema.Change) (*migrate.Plan, error) {
+func (p *tplanApply) PlanChanges(ctx context.Context, name string, changes []schema.Change, opts ...migrate.PlanOption) (*migrate.Plan, error) {
 	fc := flat(changes)
 	sort.SliceStable(fc, func(i, j int) bool {
 		return priority(fc[i]) < priority(fc[j])
@@ -98,7 +98,7 @@ func (p *tplanApply) PlanChanges(ctx context.Context, name string, changes []sch
 	}
 	for _, c := range fc {
 		// Use the planner of MySQL with each "atomic" change.
-		plan, err := p.planApply.PlanChanges(ctx, name, []schema.Change{c})
+		plan, err := p.planApply.PlanChanges(ctx, name, []schema.Change{c}, opts...)
 		if err != nil {
 			return nil, err
 		}
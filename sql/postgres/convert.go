@@ -41,6 +41,16 @@ func FormatType(t schema.Type) (string, error) {
 			return "", errors.New("postgres: missing enum type name")
 		}
 		f = t.T
+	case *DomainType:
+		if t.T == "" {
+			return "", errors.New("postgres: missing domain type name")
+		}
+		f = t.T
+	case *CompositeType:
+		if t.T == "" {
+			return "", errors.New("postgres: missing composite type name")
+		}
+		f = t.T
 	case *schema.IntegerType:
 		switch f = strings.ToLower(t.T); f {
 		case TypeSmallInt, TypeInteger, TypeBigInt:
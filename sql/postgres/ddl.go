@@ -0,0 +1,185 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"errors"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// NewDDLParser returns a migrate.DDLParser for PostgreSQL statements. Tables with
+// unqualified names are created in the schema with the given name, or in "public"
+// if it is empty.
+func NewDDLParser(name string) migrate.DDLParser {
+	if name == "" {
+		name = "public"
+	}
+	return &sqlx.DDLParser{
+		DDLDriver: ddl{},
+		Schema:    name,
+		Quotes:    `"`,
+		Fold:      true,
+	}
+}
+
+// ddl implements the sqlx.DDLDriver interface for PostgreSQL.
+type ddl struct{}
+
+// ParseType implements sqlx.DDLDriver.
+func (ddl) ParseType(t string) (schema.Type, error) {
+	if t == "" {
+		return nil, errors.New("missing column type")
+	}
+	return ParseType(t)
+}
+
+// DefaultExpr implements sqlx.DDLDriver.
+func (ddl) DefaultExpr(c *schema.Column, x string) schema.Expr {
+	return defaultExpr(c, x)
+}
+
+// ParseOption implements sqlx.DDLDriver.
+func (d ddl) ParseOption(s *sqlx.DDLScanner, v interface{}) (bool, error) {
+	switch v := v.(type) {
+	case *schema.Schema:
+		if !s.Accept("AUTHORIZATION") {
+			return false, nil
+		}
+		_, err := s.Ident()
+		return err == nil, err
+	case *schema.Column:
+		if !s.Is("GENERATED") {
+			return false, nil
+		}
+		return true, d.identity(s, v)
+	case *schema.Index:
+		switch {
+		// CONCURRENTLY is a planning attribute, and
+		// it does not change the state of the index.
+		case s.Accept("CONCURRENTLY"):
+		case s.Accept("USING"):
+			t, err := s.Ident()
+			if err != nil {
+				return false, err
+			}
+			v.AddAttrs(&IndexType{T: strings.ToUpper(t)})
+		case s.Accept("WHERE"):
+			v.AddAttrs(&IndexPredicate{P: s.Rest()})
+		default:
+			return false, nil
+		}
+	case *schema.IndexPart:
+		switch {
+		case s.Accept("NULLS", "FIRST"):
+			v.AddAttrs(&IndexColumnProperty{NullsFirst: true})
+		case s.Accept("NULLS", "LAST"):
+			v.AddAttrs(&IndexColumnProperty{NullsLast: true})
+		default:
+			return false, nil
+		}
+	case *schema.Check:
+		switch {
+		case s.Accept("NO", "INHERIT"):
+			v.AddAttrs(&NoInherit{})
+		case s.Accept("NOT", "VALID"):
+			v.AddAttrs(&NotValid{})
+		default:
+			return false, nil
+		}
+	case *schema.ForeignKey:
+		switch {
+		case s.Accept("NOT", "VALID"):
+			v.AddAttrs(&NotValid{})
+		case s.Accept("DEFERRABLE"):
+			v.AddAttrs(&Deferrable{InitiallyDeferred: s.Accept("INITIALLY", "DEFERRED")})
+		case s.Accept("NOT", "DEFERRABLE"), s.Accept("INITIALLY", "IMMEDIATE"):
+		default:
+			return false, nil
+		}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// identity parses the GENERATED AS IDENTITY option of a column.
+func (ddl) identity(s *sqlx.DDLScanner, c *schema.Column) error {
+	id := &Identity{Sequence: &Sequence{Start: 1, Increment: 1}}
+	switch {
+	case s.Accept("GENERATED", "ALWAYS", "AS", "IDENTITY"):
+		id.Generation = "ALWAYS"
+	case s.Accept("GENERATED", "BY", "DEFAULT", "AS", "IDENTITY"):
+		id.Generation = "BY DEFAULT"
+	default:
+		return s.Unexpected()
+	}
+	if s.Accept("(") {
+		for !s.Accept(")") {
+			var (
+				v   int64
+				err error
+			)
+			switch {
+			case s.Accept("START"):
+				s.Accept("WITH")
+				v, err = s.Int()
+				id.Sequence.Start = v
+			case s.Accept("INCREMENT"):
+				s.Accept("BY")
+				v, err = s.Int()
+				id.Sequence.Increment = v
+			default:
+				err = s.Unexpected()
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	c.AddAttrs(id)
+	return nil
+}
+
+// DefaultName implements sqlx.DDLDriver.
+func (ddl) DefaultName(t *schema.Table, v interface{}) string {
+	switch v := v.(type) {
+	case *schema.Index:
+		switch {
+		case v == t.PrimaryKey:
+			return t.Name + "_pkey"
+		case v.Unique:
+			return partsName(t, v.Parts) + "_key"
+		default:
+			return partsName(t, v.Parts) + "_idx"
+		}
+	case *schema.ForeignKey:
+		names := []string{t.Name}
+		for _, c := range v.Columns {
+			names = append(names, c.Name)
+		}
+		return strings.Join(names, "_") + "_fkey"
+	case *schema.Check:
+		return t.Name + "_check"
+	}
+	return ""
+}
+
+// partsName returns the table name joined with the names of the
+// index parts, as PostgreSQL names indexes that were not named.
+func partsName(t *schema.Table, parts []*schema.IndexPart) string {
+	names := []string{t.Name}
+	for _, p := range parts {
+		if p.C != nil {
+			names = append(names, p.C.Name)
+		} else {
+			names = append(names, "expr")
+		}
+	}
+	return strings.Join(names, "_")
+}
@@ -0,0 +1,69 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package postgres
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDDLParser(t *testing.T) {
+	var (
+		r = &schema.Realm{}
+		p = NewDDLParser("")
+	)
+	for _, stmt := range []string{
+		`CREATE TABLE "Users" (
+  id bigint GENERATED BY DEFAULT AS IDENTITY (START WITH 100),
+  Name character varying(255) NOT NULL,
+  created_at timestamp with time zone DEFAULT now(),
+  PRIMARY KEY (id),
+  UNIQUE (Name)
+)`,
+		`CREATE TABLE posts (id serial PRIMARY KEY, user_id bigint REFERENCES "Users" (id) DEFERRABLE INITIALLY DEFERRED, body text CHECK (length(body) > 0) NO INHERIT)`,
+		`CREATE INDEX CONCURRENTLY ON posts USING hash (user_id) WHERE body IS NOT NULL`,
+		`COMMENT ON TABLE posts IS 'user posts'`,
+		`COMMENT ON COLUMN posts.body IS 'post body'`,
+	} {
+		_, err := p.ParseDDL(r, stmt)
+		require.NoError(t, err, stmt)
+	}
+	require.Len(t, r.Schemas, 1)
+	s := r.Schemas[0]
+	require.Equal(t, "public", s.Name)
+
+	users, ok := s.Table("Users")
+	require.True(t, ok)
+	id, ok := users.Column("id")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&Identity{Generation: "BY DEFAULT", Sequence: &Sequence{Start: 100, Increment: 1}}}, id.Attrs)
+	require.False(t, id.Type.Null)
+	name, ok := users.Column("name")
+	require.True(t, ok)
+	require.Equal(t, &schema.StringType{T: TypeCharVar, Size: 255}, name.Type.Type)
+	createdAt, ok := users.Column("created_at")
+	require.True(t, ok)
+	precision := 6
+	require.Equal(t, &schema.TimeType{T: TypeTimestampTZ, Precision: &precision}, createdAt.Type.Type)
+	require.Equal(t, &schema.RawExpr{X: "now()"}, createdAt.Default)
+	require.Equal(t, "Users_pkey", users.PrimaryKey.Name)
+	_, ok = users.Index("Users_name_key")
+	require.True(t, ok)
+
+	posts, ok := s.Table("posts")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&schema.Check{Name: "posts_check", Expr: "length(body) > 0", Attrs: []schema.Attr{&NoInherit{}}}, &schema.Comment{Text: "user posts"}}, posts.Attrs)
+	require.Equal(t, "posts_user_id_fkey", posts.ForeignKeys[0].Symbol)
+	require.Equal(t, []schema.Attr{&Deferrable{InitiallyDeferred: true}}, posts.ForeignKeys[0].Attrs)
+	idx, ok := posts.Index("posts_user_id_idx")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&IndexType{T: IndexTypeHash}, &IndexPredicate{P: "body IS NOT NULL"}}, idx.Attrs)
+	body, ok := posts.Column("body")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&schema.Comment{Text: "post body"}}, body.Attrs)
+}
@@ -8,7 +8,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
@@ -26,6 +28,54 @@ func (d *diff) SchemaAttrDiff(_, _ *schema.Schema) []schema.Change {
 	return nil
 }
 
+// SchemaObjectDiff returns a changeset for migrating schema objects from one state to the other.
+func (d *diff) SchemaObjectDiff(from, to *schema.Schema) ([]schema.Change, error) {
+	var changes []schema.Change
+	// Drop or modify objects.
+	for _, o1 := range from.Objects {
+		o2, ok := objectByName(to, o1)
+		if !ok {
+			// Sequences that are owned by a dropped column, are dropped
+			// together with it. Sequences that are owned by serial columns
+			// are created implicitly, and therefore, they are not defined
+			// in the desired state.
+			if s, ok := o1.(*Sequence); ok && implicitDrop(s, to) {
+				continue
+			}
+			changes = append(changes, &schema.DropObject{O: o1})
+			continue
+		}
+		if objectChanged(o1, o2) {
+			changes = append(changes, &schema.ModifyObject{From: o1, To: o2})
+		}
+	}
+	// Add objects.
+	for _, o1 := range to.Objects {
+		if _, ok := objectByName(from, o1); !ok {
+			changes = append(changes, &schema.AddObject{O: o1})
+		}
+	}
+	return changes, nil
+}
+
+// RealmObjectDiff returns a changeset for migrating realm-level objects (e.g. roles) from one state to the other.
+func (d *diff) RealmObjectDiff(from, to *schema.Realm, opts *schema.DiffOptions) ([]schema.Change, error) {
+	return sqlx.RealmObjectDiff(from, to, realmObject, realmObjectChanged, func(o schema.Object) bool {
+		switch o := o.(type) {
+		case *Role:
+			// Roles are cluster-wide. Roles that are not defined in
+			// the desired state are kept, unless managed exclusively.
+			return !opts.ExclusiveRoles
+		case *Permission:
+			// Privileges on dropped schemas, tables or
+			// columns are revoked together with them.
+			_, ok := realmRole(to, o.Role.Name)
+			return !ok && !opts.ExclusiveRoles || !sqlx.HasPrivilegeTarget(to, o.Schema, o.Table, o.Column)
+		}
+		return false
+	}), nil
+}
+
 // TableAttrDiff returns a changeset for migrating table attributes from one state to the other.
 func (d *diff) TableAttrDiff(from, to *schema.Table) ([]schema.Change, error) {
 	var changes []schema.Change
@@ -35,8 +85,16 @@ func (d *diff) TableAttrDiff(from, to *schema.Table) ([]schema.Change, error) {
 	if err := d.partitionChanged(from, to); err != nil {
 		return nil, err
 	}
+	if change := partitionOfChange(from, to); change != nil {
+		changes = append(changes, change)
+	}
+	if change := rowSecurityChange(from, to); change != nil {
+		changes = append(changes, change)
+	}
+	changes = append(changes, policiesDiff(from, to)...)
 	return append(changes, sqlx.CheckDiff(from, to, func(c1, c2 *schema.Check) bool {
-		return sqlx.Has(c1.Attrs, &NoInherit{}) == sqlx.Has(c2.Attrs, &NoInherit{})
+		return sqlx.Has(c1.Attrs, &NoInherit{}) == sqlx.Has(c2.Attrs, &NoInherit{}) &&
+			sqlx.Has(c1.Attrs, &NotValid{}) == sqlx.Has(c2.Attrs, &NotValid{})
 	})...), nil
 }
 
@@ -127,6 +185,143 @@ func (*diff) partitionChanged(from, to *schema.Table) error {
 	return nil
 }
 
+// partitionOfChange returns the change (if any) for attaching
+// or detaching a table to or from its partitioned (parent) table.
+func partitionOfChange(from, to *schema.Table) schema.Change {
+	var fromP, toP PartitionOf
+	switch fromHas, toHas := sqlx.Has(from.Attrs, &fromP), sqlx.Has(to.Attrs, &toP); {
+	case fromHas && !toHas:
+		return &schema.DropAttr{A: &fromP}
+	case !fromHas && toHas:
+		return &schema.AddAttr{A: &toP}
+	case fromHas && toHas && (parentChanged(fromP.Parent, toP.Parent) || !boundEqual(fromP.Bound, toP.Bound)):
+		return &schema.ModifyAttr{From: &fromP, To: &toP}
+	}
+	return nil
+}
+
+// parentChanged reports if the parent tables of the partitions are different.
+// Schema names are compared only if both tables are attached to a schema.
+func parentChanged(t1, t2 *schema.Table) bool {
+	switch {
+	case t1 == nil || t2 == nil:
+		return t1 != t2
+	case t1.Name != t2.Name:
+		return true
+	case t1.Schema != nil && t2.Schema != nil:
+		return t1.Schema.Name != t2.Schema.Name
+	}
+	return false
+}
+
+// boundEqual reports if the two partition bounds are equal, ignoring whitespace
+// differences. Note that bounds are compared as returned by the database, e.g.
+// "FOR VALUES FROM ('2022-01-01') TO ('2023-01-01')".
+func boundEqual(b1, b2 string) bool {
+	return strings.Join(strings.Fields(b1), " ") == strings.Join(strings.Fields(b2), " ")
+}
+
+// rowSecurityChange returns the change (if any) for the row-level security settings of the table.
+func rowSecurityChange(from, to *schema.Table) schema.Change {
+	var fromS, toS RowSecurity
+	switch fromHas, toHas := sqlx.Has(from.Attrs, &fromS), sqlx.Has(to.Attrs, &toS); {
+	case fromHas && !toHas && (fromS.Enabled || fromS.Forced):
+		return &schema.DropAttr{A: &fromS}
+	case !fromHas && toHas && (toS.Enabled || toS.Forced):
+		return &schema.AddAttr{A: &toS}
+	case fromHas && toHas && fromS != toS:
+		return &schema.ModifyAttr{From: &fromS, To: &toS}
+	}
+	return nil
+}
+
+// policiesDiff returns the changes for adding, dropping or modifying the policies of the table.
+func policiesDiff(from, to *schema.Table) []schema.Change {
+	var changes []schema.Change
+	for _, p1 := range policies(from.Attrs) {
+		switch p2, ok := policy(to.Attrs, p1.Name); {
+		case !ok:
+			changes = append(changes, &schema.DropAttr{A: p1})
+		case policyChanged(p1, p2):
+			changes = append(changes, &schema.ModifyAttr{From: p1, To: p2})
+		}
+	}
+	for _, p2 := range policies(to.Attrs) {
+		if _, ok := policy(from.Attrs, p2.Name); !ok {
+			changes = append(changes, &schema.AddAttr{A: p2})
+		}
+	}
+	return changes
+}
+
+// policies returns the policies of the table attributes.
+func policies(attrs []schema.Attr) (ps []*Policy) {
+	for _, a := range attrs {
+		if p, ok := a.(*Policy); ok {
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
+
+// policy returns the policy with the given name from the table attributes.
+func policy(attrs []schema.Attr, name string) (*Policy, bool) {
+	for _, p := range policies(attrs) {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// policyChanged reports if the policy was changed. The default values
+// of the policy type, command and roles are ignored in comparison.
+func policyChanged(from, to *Policy) bool {
+	return policyAs(from) != policyAs(to) || policyFor(from) != policyFor(to) ||
+		!sqlx.ValuesEqual(policyRoles(from), policyRoles(to)) ||
+		policyExprChanged(from.Using, to.Using) || policyExprChanged(from.Check, to.Check)
+}
+
+// policyAs returns the policy type, or its default.
+func policyAs(p *Policy) string {
+	if p.As == "" {
+		return PolicyPermissive
+	}
+	return strings.ToUpper(p.As)
+}
+
+// policyFor returns the policy command, or its default.
+func policyFor(p *Policy) string {
+	if p.For == "" {
+		return PolicyForAll
+	}
+	return strings.ToUpper(p.For)
+}
+
+// policyRoles returns the sorted roles of the policy,
+// where an explicit PUBLIC is treated as the default.
+func policyRoles(p *Policy) []string {
+	roles := make([]string, 0, len(p.To))
+	for _, r := range p.To {
+		if !strings.EqualFold(r, "PUBLIC") {
+			roles = append(roles, r)
+		}
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// policyExprChanged reports if the policy expression was changed,
+// ignoring the wrapping parentheses added by the database.
+func policyExprChanged(x1, x2 string) bool {
+	switch {
+	case x1 == "" || x2 == "":
+		return x1 != x2
+	default:
+		return sqlx.MayWrap(x1) != sqlx.MayWrap(x2)
+	}
+}
+
 // IsGeneratedIndexName reports if the index name was generated by the database.
 func (d *diff) IsGeneratedIndexName(t *schema.Table, idx *schema.Index) bool {
 	names := make([]string, len(idx.Parts))
@@ -157,7 +352,11 @@ func (*diff) IndexAttrChanged(from, to []schema.Attr) bool {
 	if sqlx.Has(to, t2) {
 		t2.T = strings.ToUpper(t2.T)
 	}
-	if t1.T != t2.T {
+	if t1.T != t2.T || isExclude(from) != isExclude(to) {
+		return true
+	}
+	// Invalid indexes are rebuilt.
+	if sqlx.Has(from, &IndexInvalid{}) != sqlx.Has(to, &IndexInvalid{}) || deferrableChanged(from, to) {
 		return true
 	}
 	var p1, p2 IndexPredicate
@@ -175,7 +374,17 @@ func (*diff) IndexPartAttrChanged(from, to *schema.IndexPart) bool {
 	sqlx.Has(from.Attrs, p1)
 	p2 := &IndexColumnProperty{NullsFirst: to.Desc, NullsLast: !to.Desc}
 	sqlx.Has(to.Attrs, p2)
-	return p1.NullsFirst != p2.NullsFirst || p1.NullsLast != p2.NullsLast
+	if p1.NullsFirst != p2.NullsFirst || p1.NullsLast != p2.NullsLast {
+		return true
+	}
+	var o1, o2 ExcludeOp
+	return sqlx.Has(from.Attrs, &o1) != sqlx.Has(to.Attrs, &o2) || o1.Op != o2.Op
+}
+
+// isExclude reports if the index attributes describe an exclusion constraint.
+func isExclude(attrs []schema.Attr) bool {
+	c := &ConType{}
+	return sqlx.Has(attrs, c) && c.IsExclude()
 }
 
 // ReferenceChanged reports if the foreign key referential action was changed.
@@ -191,6 +400,18 @@ func (*diff) ReferenceChanged(from, to schema.ReferenceOption) bool {
 	return from != to
 }
 
+// ForeignKeyAttrChanged reports if the foreign key attributes were changed.
+// A foreign key that was not validated yet is distinct from a validated one.
+func (*diff) ForeignKeyAttrChanged(from, to []schema.Attr) bool {
+	return sqlx.Has(from, &NotValid{}) != sqlx.Has(to, &NotValid{}) || deferrableChanged(from, to)
+}
+
+// deferrableChanged reports if the deferrability of the constraint was changed.
+func deferrableChanged(from, to []schema.Attr) bool {
+	var d1, d2 Deferrable
+	return sqlx.Has(from, &d1) != sqlx.Has(to, &d2) || d1.InitiallyDeferred != d2.InitiallyDeferred
+}
+
 func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
 	fromT, toT := from.Type.Type, to.Type.Type
 	if fromT == nil || toT == nil {
@@ -240,6 +461,12 @@ func (d *diff) typeChanged(from, to *schema.Column) (bool, error) {
 	case *CurrencyType:
 		toT := toT.(*CurrencyType)
 		changed = fromT.T != toT.T
+	case *DomainType:
+		toT := toT.(*DomainType)
+		changed = fromT.T != toT.T
+	case *CompositeType:
+		toT := toT.(*CompositeType)
+		changed = fromT.T != toT.T
 	case *UUIDType:
 		toT := toT.(*UUIDType)
 		changed = fromT.T != toT.T
@@ -383,6 +610,259 @@ func identity(attrs []schema.Attr) (*Identity, bool) {
 	return i, true
 }
 
+// objectName returns the name of the given object, and reports if the object is supported.
+func objectName(o schema.Object) (string, bool) {
+	switch o := o.(type) {
+	case *Sequence:
+		return o.Name, true
+	case *Extension:
+		return o.Name, true
+	case *DomainType:
+		return o.T, true
+	case *CompositeType:
+		return o.T, true
+	}
+	return "", false
+}
+
+// objectByName returns the first object in the schema that has the same type and name as o.
+func objectByName(s *schema.Schema, o schema.Object) (schema.Object, bool) {
+	n1, ok := objectName(o)
+	if !ok {
+		return nil, false
+	}
+	for _, o2 := range s.Objects {
+		if n2, ok := objectName(o2); ok && n1 == n2 && reflect.TypeOf(o) == reflect.TypeOf(o2) {
+			return o2, true
+		}
+	}
+	return nil, false
+}
+
+// realmObject returns the realm object that matches the given one. Roles
+// are matched by their names, and permissions by their key.
+func realmObject(r *schema.Realm, o schema.Object) (schema.Object, bool) {
+	switch o := o.(type) {
+	case *Role:
+		return realmRole(r, o.Name)
+	case *Permission:
+		k := permissionKey(o)
+		for _, o2 := range r.Objects {
+			if p, ok := o2.(*Permission); ok && permissionKey(p) == k {
+				return p, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// realmObjectChanged reports if the realm object was changed.
+func realmObjectChanged(from, to schema.Object) bool {
+	switch from := from.(type) {
+	case *Role:
+		to := to.(*Role)
+		return from.Login != to.Login || from.Superuser != to.Superuser || from.CreateDB != to.CreateDB || from.CreateRole != to.CreateRole
+	case *Permission:
+		add, drop := sqlx.PrivilegesDiff(from.Privileges, to.(*Permission).Privileges)
+		return len(add) > 0 || len(drop) > 0
+	}
+	return false
+}
+
+// objectChanged reports if the object was changed.
+func objectChanged(from, to schema.Object) bool {
+	switch from := from.(type) {
+	case *Sequence:
+		return sequenceChanged(from, to.(*Sequence))
+	case *Extension:
+		return extensionChanged(from, to.(*Extension))
+	case *DomainType:
+		return domainChanged(from, to.(*DomainType))
+	case *CompositeType:
+		return compositeChanged(from, to.(*CompositeType))
+	}
+	return false
+}
+
+// domainChanged reports if the domain definition was changed.
+func domainChanged(from, to *DomainType) bool {
+	return baseChanged(from, to) || from.Null != to.Null || domainDefaultChanged(from, to) || len(domainChecksDiff(from, to)) > 0 || len(domainChecksDiff(to, from)) > 0
+}
+
+// baseChanged reports if the base type of the domain was changed.
+func baseChanged(from, to *DomainType) bool {
+	t1, err1 := FormatType(from.Base)
+	t2, err2 := FormatType(to.Base)
+	return err1 != nil || err2 != nil || t1 != t2
+}
+
+// domainDefaultChanged reports if the default value of the domain was changed.
+func domainDefaultChanged(from, to *DomainType) bool {
+	d1, ok1 := sqlx.DefaultValue(&schema.Column{Default: from.Default})
+	d2, ok2 := sqlx.DefaultValue(&schema.Column{Default: to.Default})
+	return ok1 != ok2 || trimCast(d1) != trimCast(d2)
+}
+
+// domainChecksDiff returns the checks of the first domain that do not exist in the second.
+// Similar to table checks, constraints are matched by their name or their expression.
+func domainChecksDiff(d1, d2 *DomainType) []*schema.Check {
+	var checks []*schema.Check
+	for _, c1 := range d1.Checks {
+		var found bool
+		for _, c2 := range d2.Checks {
+			if c1.Name != "" && c1.Name == c2.Name || c1.Expr == c2.Expr {
+				found = true
+				break
+			}
+		}
+		if !found {
+			checks = append(checks, c1)
+		}
+	}
+	return checks
+}
+
+// compositeChanged reports if the fields of the composite type were changed.
+func compositeChanged(from, to *CompositeType) bool {
+	if len(from.Fields) != len(to.Fields) {
+		return true
+	}
+	for _, f1 := range from.Fields {
+		f2, ok := compositeField(to, f1.Name)
+		if !ok || fieldChanged(f1, f2) {
+			return true
+		}
+	}
+	return false
+}
+
+// compositeField returns the field of the composite type by its name.
+func compositeField(c *CompositeType, name string) (*CompositeField, bool) {
+	for _, f := range c.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// fieldChanged reports if the type of the composite field was changed.
+func fieldChanged(from, to *CompositeField) bool {
+	t1, err1 := FormatType(from.Type)
+	t2, err2 := FormatType(to.Type)
+	return err1 != nil || err2 != nil || t1 != t2
+}
+
+// extensionChanged reports if the extension version was changed. An
+// empty version in the desired state means any (i.e. the default) version.
+func extensionChanged(from, to *Extension) bool {
+	return to.Version != "" && from.Version != to.Version
+}
+
+// implicitDrop reports if the sequence is removed from the desired state implicitly,
+// because its owner column does not exist there, or it is defined as a serial column.
+func implicitDrop(seq *Sequence, to *schema.Schema) bool {
+	if seq.OwnedBy == nil {
+		return false
+	}
+	s := to
+	if o := seq.OwnedBy.T.Schema; o != nil && o.Name != to.Name {
+		if to.Realm == nil {
+			return false
+		}
+		if s, _ = to.Realm.Schema(o.Name); s == nil {
+			return true
+		}
+	}
+	t, ok := s.Table(seq.OwnedBy.T.Name)
+	if !ok {
+		return true
+	}
+	c, ok := t.Column(seq.OwnedBy.C.Name)
+	if !ok {
+		return true
+	}
+	_, ok = c.Type.Type.(*SerialType)
+	return ok
+}
+
+// sequenceChanged reports if one of the sequence options was changed.
+func sequenceChanged(from, to *Sequence) bool {
+	s1, s2 := sequence(from), sequence(to)
+	return s1.Type != s2.Type || s1.Start != s2.Start || s1.Increment != s2.Increment ||
+		s1.Min != s2.Min || s1.Max != s2.Max || s1.Cycle != s2.Cycle || ownerChanged(s1.OwnedBy, s2.OwnedBy)
+}
+
+// ownerChanged reports if the sequence ownership was changed.
+func ownerChanged(from, to *SequenceOwner) bool {
+	if from == nil || to == nil {
+		return from != to
+	}
+	if s1, s2 := from.T.Schema, to.T.Schema; s1 != nil && s2 != nil && s1.Name != s2.Name {
+		return true
+	}
+	return from.T.Name != to.T.Name || from.C.Name != to.C.Name
+}
+
+// Default sequence type.
+const defaultSeqType = TypeBigInt
+
+// sequence returns a copy of the given sequence with its unset options
+// replaced with the defaults of the sequence type and increment direction.
+func sequence(s *Sequence) *Sequence {
+	seq := *s
+	seq.Type = seqType(seq.Type)
+	if seq.Increment == 0 {
+		seq.Increment = defaultSeqIncrement
+	}
+	// Both zero values are not a valid range.
+	if seq.Min == 0 && seq.Max == 0 {
+		seq.Min, seq.Max = seqRange(seq.Type, seq.Increment)
+	}
+	// A zero start value outside the range is not valid.
+	if seq.Start == 0 && (seq.Min > 0 || seq.Max < 0) {
+		seq.Start = seqStart(&seq)
+	}
+	return &seq
+}
+
+// seqType returns the normalized data type of a sequence.
+func seqType(t string) string {
+	switch strings.ToLower(t) {
+	case TypeSmallInt, TypeInt2:
+		return TypeSmallInt
+	case TypeInteger, TypeInt, TypeInt4:
+		return TypeInteger
+	default:
+		return defaultSeqType
+	}
+}
+
+// seqRange returns the default MINVALUE and MAXVALUE of a sequence
+// according to its data type and its increment direction.
+func seqRange(t string, inc int64) (min, max int64) {
+	switch seqType(t) {
+	case TypeSmallInt:
+		max = math.MaxInt16
+	case TypeInteger:
+		max = math.MaxInt32
+	default:
+		max = math.MaxInt64
+	}
+	if inc < 0 {
+		return -max - 1, -1
+	}
+	return 1, max
+}
+
+// seqStart returns the default start value of a sequence.
+func seqStart(s *Sequence) int64 {
+	if s.Increment < 0 {
+		return s.Max
+	}
+	return s.Min
+}
+
 // formatPartition returns the string representation of the
 // partition key according to the PostgreSQL format/grammar.
 func formatPartition(p Partition) (string, error) {
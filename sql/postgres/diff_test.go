@@ -117,6 +117,120 @@ func TestDiff_TableDiff(t *testing.T) {
 				}),
 			wantErr: true,
 		},
+		{
+			name: "attach partition",
+			from: schema.NewTable("logs_a"),
+			to:   schema.NewTable("logs_a").AddAttrs(&PartitionOf{Parent: schema.NewTable("logs"), Bound: "DEFAULT"}),
+			wantChanges: []schema.Change{
+				&schema.AddAttr{A: &PartitionOf{Parent: schema.NewTable("logs"), Bound: "DEFAULT"}},
+			},
+		},
+		{
+			name: "detach partition",
+			from: schema.NewTable("logs_a").AddAttrs(&PartitionOf{Parent: schema.NewTable("logs"), Bound: "DEFAULT"}),
+			to:   schema.NewTable("logs_a"),
+			wantChanges: []schema.Change{
+				&schema.DropAttr{A: &PartitionOf{Parent: schema.NewTable("logs"), Bound: "DEFAULT"}},
+			},
+		},
+		{
+			name: "partition bound whitespace",
+			from: schema.NewTable("logs_a").AddAttrs(&PartitionOf{Parent: schema.NewTable("logs"), Bound: "FOR VALUES IN (1, 2)"}),
+			to:   schema.NewTable("logs_a").AddAttrs(&PartitionOf{Parent: schema.NewTable("logs"), Bound: " FOR VALUES  IN (1, 2)"}),
+		},
+		{
+			name: "change partition bound",
+			from: schema.NewTable("logs_a").AddAttrs(&PartitionOf{Parent: schema.NewTable("logs"), Bound: "FOR VALUES IN (1)"}),
+			to:   schema.NewTable("logs_a").AddAttrs(&PartitionOf{Parent: schema.NewTable("logs"), Bound: "FOR VALUES IN (2)"}),
+			wantChanges: []schema.Change{
+				&schema.ModifyAttr{
+					From: &PartitionOf{Parent: schema.NewTable("logs"), Bound: "FOR VALUES IN (1)"},
+					To:   &PartitionOf{Parent: schema.NewTable("logs"), Bound: "FOR VALUES IN (2)"},
+				},
+			},
+		},
+		{
+			name: "enable row-level security",
+			from: schema.NewTable("users"),
+			to:   schema.NewTable("users").AddAttrs(&RowSecurity{Enabled: true}),
+			wantChanges: []schema.Change{
+				&schema.AddAttr{A: &RowSecurity{Enabled: true}},
+			},
+		},
+		{
+			name: "force row-level security",
+			from: schema.NewTable("users").AddAttrs(&RowSecurity{Enabled: true}),
+			to:   schema.NewTable("users").AddAttrs(&RowSecurity{Enabled: true, Forced: true}),
+			wantChanges: []schema.Change{
+				&schema.ModifyAttr{From: &RowSecurity{Enabled: true}, To: &RowSecurity{Enabled: true, Forced: true}},
+			},
+		},
+		{
+			name: "policies with defaults",
+			from: schema.NewTable("users").AddAttrs(&Policy{Name: "p1", As: PolicyPermissive, For: PolicyForAll, Using: "(tenant = 1)"}),
+			to:   schema.NewTable("users").AddAttrs(&Policy{Name: "p1", To: []string{"public"}, Using: "tenant = 1"}),
+		},
+		{
+			name: "policies",
+			from: schema.NewTable("users").AddAttrs(
+				&Policy{Name: "p1", Using: "(tenant = 1)"},
+				&Policy{Name: "p2", For: PolicyForSelect, To: []string{"app"}, Using: "true"},
+			),
+			to: schema.NewTable("users").AddAttrs(
+				&Policy{Name: "p2", For: PolicyForSelect, To: []string{"app", "admin"}, Using: "true"},
+				&Policy{Name: "p3", As: PolicyRestrictive, Check: "tenant > 0"},
+			),
+			wantChanges: []schema.Change{
+				&schema.DropAttr{A: &Policy{Name: "p1", Using: "(tenant = 1)"}},
+				&schema.ModifyAttr{
+					From: &Policy{Name: "p2", For: PolicyForSelect, To: []string{"app"}, Using: "true"},
+					To:   &Policy{Name: "p2", For: PolicyForSelect, To: []string{"app", "admin"}, Using: "true"},
+				},
+				&schema.AddAttr{A: &Policy{Name: "p3", As: PolicyRestrictive, Check: "tenant > 0"}},
+			},
+		},
+		func() testcase {
+			var (
+				from = schema.NewTable("bookings").AddColumns(schema.NewIntColumn("room", "int"))
+				to   = schema.NewTable("bookings").AddColumns(schema.NewIntColumn("room", "int"))
+			)
+			from.AddIndexes(schema.NewIndex("room_excl").
+				AddAttrs(&IndexType{T: "gist"}, &ConType{T: "x"}).
+				AddParts(schema.NewColumnPart(from.Columns[0]).AddAttrs(&ExcludeOp{Op: "="})))
+			to.AddIndexes(schema.NewIndex("room_excl").
+				AddAttrs(&IndexType{T: "gist"}, &ConType{T: "x"}).
+				AddParts(schema.NewColumnPart(to.Columns[0]).AddAttrs(&ExcludeOp{Op: "<>"})))
+			return testcase{
+				name: "change exclusion operator",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.ModifyIndex{From: from.Indexes[0], To: to.Indexes[0], Change: schema.ChangeParts},
+				},
+			}
+		}(),
+		func() testcase {
+			var (
+				from = schema.NewTable("users").AddColumns(schema.NewStringColumn("name", "text"))
+				to   = schema.NewTable("users").AddColumns(schema.NewStringColumn("name", "text"))
+			)
+			from.AddIndexes(
+				schema.NewIndex("invalid").AddColumns(from.Columns[0]).AddAttrs(&IndexInvalid{}),
+				schema.NewIndex("concurrently").AddColumns(from.Columns[0]),
+			)
+			to.AddIndexes(
+				schema.NewIndex("invalid").AddColumns(to.Columns[0]),
+				schema.NewIndex("concurrently").AddColumns(to.Columns[0]).AddAttrs(&IndexConcurrently{}),
+			)
+			return testcase{
+				name: "rebuild invalid index",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.ModifyIndex{From: from.Indexes[0], To: to.Indexes[0], Change: schema.ChangeAttr},
+				},
+			}
+		}(),
 		{
 			name: "add check",
 			from: &schema.Table{Name: "t1", Schema: &schema.Schema{Name: "public"}},
@@ -137,6 +251,17 @@ func TestDiff_TableDiff(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "validate check",
+			from: &schema.Table{Name: "t1", Attrs: []schema.Attr{&schema.Check{Name: "t1_c1_check", Expr: "(c1 > 1)", Attrs: []schema.Attr{&NotValid{}}}}},
+			to:   &schema.Table{Name: "t1", Attrs: []schema.Attr{&schema.Check{Name: "t1_c1_check", Expr: "(c1 > 1)"}}},
+			wantChanges: []schema.Change{
+				&schema.ModifyCheck{
+					From: &schema.Check{Name: "t1_c1_check", Expr: "(c1 > 1)", Attrs: []schema.Attr{&NotValid{}}},
+					To:   &schema.Check{Name: "t1_c1_check", Expr: "(c1 > 1)"},
+				},
+			},
+		},
 		{
 			name: "add comment",
 			from: &schema.Table{Name: "t1", Schema: &schema.Schema{Name: "public"}},
@@ -351,6 +476,65 @@ func TestDiff_TableDiff(t *testing.T) {
 				},
 			}
 		}(),
+		func() testcase {
+			var (
+				ref = &schema.Table{
+					Name:    "t2",
+					Schema:  &schema.Schema{Name: "public"},
+					Columns: []*schema.Column{{Name: "id", Type: &schema.ColumnType{Raw: "int", Type: &schema.IntegerType{T: "int"}}}},
+				}
+				from = &schema.Table{
+					Name:    "t1",
+					Schema:  &schema.Schema{Name: "public"},
+					Columns: []*schema.Column{{Name: "t2_id", Type: &schema.ColumnType{Raw: "int", Type: &schema.IntegerType{T: "int"}}}},
+				}
+				to = &schema.Table{
+					Name:    "t1",
+					Schema:  &schema.Schema{Name: "public"},
+					Columns: []*schema.Column{{Name: "t2_id", Type: &schema.ColumnType{Raw: "int", Type: &schema.IntegerType{T: "int"}}}},
+				}
+			)
+			from.ForeignKeys = []*schema.ForeignKey{
+				{Symbol: "fk", Table: from, Columns: from.Columns, RefTable: ref, RefColumns: ref.Columns, Attrs: []schema.Attr{&NotValid{}}},
+			}
+			to.ForeignKeys = []*schema.ForeignKey{
+				{Symbol: "fk", Table: to, Columns: to.Columns, RefTable: ref, RefColumns: ref.Columns},
+			}
+			from.ForeignKeys[0].Attrs = append(from.ForeignKeys[0].Attrs, &Deferrable{})
+			to.ForeignKeys[0].Attrs = append(to.ForeignKeys[0].Attrs, &Deferrable{})
+			return testcase{
+				name: "validate foreign-key",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.ModifyForeignKey{
+						From:   from.ForeignKeys[0],
+						To:     to.ForeignKeys[0],
+						Change: schema.ChangeAttr,
+					},
+				},
+			}
+		}(),
+		func() testcase {
+			var (
+				from = schema.NewTable("t1").
+					SetSchema(schema.New("public")).
+					AddColumns(schema.NewIntColumn("c1", "int"))
+				to = schema.NewTable("t1").
+					SetSchema(schema.New("public")).
+					AddColumns(schema.NewIntColumn("c1", "int"))
+			)
+			from.AddIndexes(schema.NewUniqueIndex("t1_c1_key").AddColumns(from.Columns[0]).AddAttrs(&ConType{T: "u"}, &Deferrable{}))
+			to.AddIndexes(schema.NewUniqueIndex("t1_c1_key").AddColumns(to.Columns[0]).AddAttrs(&Deferrable{InitiallyDeferred: true}))
+			return testcase{
+				name: "deferrable unique constraint",
+				from: from,
+				to:   to,
+				wantChanges: []schema.Change{
+					&schema.ModifyIndex{From: from.Indexes[0], To: to.Indexes[0], Change: schema.ChangeAttr},
+				},
+			}
+		}(),
 	}
 	for _, tt := range tests {
 		db, m, err := sqlmock.New()
@@ -399,3 +583,172 @@ func TestDiff_SchemaDiff(t *testing.T) {
 		&schema.AddTable{T: to.Tables[1]},
 	}, changes)
 }
+
+func TestDiff_SequenceDiff(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	var (
+		from = schema.New("public").AddTables(
+			schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "integer").SetDefault(&schema.RawExpr{X: "nextval('users_id_seq'::regclass)"})),
+			schema.NewTable("pets").AddColumns(schema.NewIntColumn("id", "integer")),
+		)
+		to = schema.New("public").AddTables(
+			schema.NewTable("users").AddColumns(&schema.Column{Name: "id", Type: &schema.ColumnType{Type: &SerialType{T: "serial"}}}),
+		)
+		seqs = []*Sequence{
+			// Owned by a serial column in the desired state.
+			{Name: "users_id_seq", Schema: from, Type: "integer", Start: 1, Increment: 1, Min: 1, Max: 2147483647, OwnedBy: &SequenceOwner{T: from.Tables[0], C: from.Tables[0].Columns[0]}},
+			// Owned by a dropped table.
+			{Name: "pets_id_seq", Schema: from, Type: "integer", Start: 1, Increment: 1, Min: 1, Max: 2147483647, OwnedBy: &SequenceOwner{T: from.Tables[1], C: from.Tables[1].Columns[0]}},
+			{Name: "s1", Schema: from, Type: "bigint", Start: 1, Increment: 1, Min: 1, Max: 9223372036854775807},
+			{Name: "s2", Schema: from, Type: "bigint", Start: 1, Increment: 1, Min: 1, Max: 9223372036854775807},
+			{Name: "s3", Schema: from, Type: "bigint", Start: 1, Increment: 1, Min: 1, Max: 9223372036854775807},
+		}
+	)
+	from.AddObjects(seqs[0], seqs[1], seqs[2], seqs[3], seqs[4])
+	to.AddObjects(
+		// Unset options are treated as defaults.
+		&Sequence{Name: "s1", Schema: to},
+		&Sequence{Name: "s2", Schema: to, Increment: 2, Min: 1, Max: 100, Cycle: true},
+		&Sequence{Name: "s4", Schema: to, Type: "smallint"},
+	)
+	changes, err := drv.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.EqualValues(t, []schema.Change{
+		&schema.DropTable{T: from.Tables[1]},
+		&schema.ModifyObject{From: seqs[3], To: to.Objects[1]},
+		&schema.DropObject{O: seqs[4]},
+		&schema.AddObject{O: to.Objects[2]},
+	}, changes)
+}
+
+func TestDiff_ExtensionDiff(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	from, to := schema.New("public"), schema.New("public")
+	from.AddObjects(
+		&Extension{Name: "citext", Schema: from, Version: "1.6"},
+		&Extension{Name: "hstore", Schema: from, Version: "1.8"},
+		&Extension{Name: "pg_trgm", Schema: from, Version: "1.5"},
+	)
+	to.AddObjects(
+		// An empty version matches any installed version.
+		&Extension{Name: "citext", Schema: to},
+		&Extension{Name: "pg_trgm", Schema: to, Version: "1.6"},
+		&Extension{Name: "uuid-ossp", Schema: to},
+		// Objects of different types may share the same name.
+		&Sequence{Name: "citext", Schema: to},
+	)
+	changes, err := drv.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.EqualValues(t, []schema.Change{
+		&schema.DropObject{O: from.Objects[1]},
+		&schema.ModifyObject{From: from.Objects[2], To: to.Objects[1]},
+		&schema.AddObject{O: to.Objects[2]},
+		&schema.AddObject{O: to.Objects[3]},
+	}, changes)
+}
+
+func TestDiff_RoleDiff(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	var (
+		from = schema.NewRealm(
+			schema.New("public").AddTables(
+				schema.NewTable("users").AddColumns(schema.NewStringColumn("email", "text")),
+				schema.NewTable("posts"),
+			),
+		)
+		to = schema.NewRealm(
+			schema.New("public").AddTables(
+				schema.NewTable("users"),
+			),
+		)
+		s1, s2         = from.Schemas[0], to.Schemas[0]
+		app1, app2     = &Role{Name: "app"}, &Role{Name: "app", Login: true}
+		admin1, admin2 = &Role{Name: "admin"}, &Role{Name: "admin"}
+	)
+	from.AddObjects(
+		app1, admin1, &Role{Name: "old"},
+		&Permission{Role: app1, Schema: s1, Table: s1.Tables[0], Privileges: []string{"SELECT"}},
+		&Permission{Role: admin1, Schema: s1, Privileges: []string{"USAGE"}},
+		// Privileges on dropped tables and columns are revoked implicitly.
+		&Permission{Role: app1, Schema: s1, Table: s1.Tables[1], Privileges: []string{"SELECT"}},
+		&Permission{Role: app1, Schema: s1, Table: s1.Tables[0], Column: s1.Tables[0].Columns[0], Privileges: []string{"UPDATE"}},
+	)
+	to.AddObjects(
+		app2, admin2, &Role{Name: "new"},
+		&Permission{Role: app2, Schema: s2, Table: s2.Tables[0], Privileges: []string{"select", "INSERT"}},
+		&Permission{Role: admin2, Schema: s2, Privileges: []string{"USAGE"}, Grantable: true},
+	)
+	// Roles that are not defined in the desired state are kept.
+	changes, err := drv.RealmDiff(from, to)
+	require.NoError(t, err)
+	require.Len(t, changes, 7)
+	require.IsType(t, &schema.ModifyTable{}, changes[0])
+	require.IsType(t, &schema.DropTable{}, changes[1])
+	require.EqualValues(t, []schema.Change{
+		&schema.ModifyObject{From: from.Objects[0], To: to.Objects[0]},
+		&schema.ModifyObject{From: from.Objects[3], To: to.Objects[3]},
+		&schema.DropObject{O: from.Objects[4]},
+		&schema.AddObject{O: to.Objects[2]},
+		&schema.AddObject{O: to.Objects[4]},
+	}, changes[2:])
+
+	changes, err = schema.RealmDiff(drv, from, to, schema.DiffExclusiveRoles())
+	require.NoError(t, err)
+	require.Len(t, changes, 8)
+	require.EqualValues(t, []schema.Change{
+		&schema.ModifyObject{From: from.Objects[0], To: to.Objects[0]},
+		&schema.DropObject{O: from.Objects[2]},
+		&schema.ModifyObject{From: from.Objects[3], To: to.Objects[3]},
+		&schema.DropObject{O: from.Objects[4]},
+		&schema.AddObject{O: to.Objects[2]},
+		&schema.AddObject{O: to.Objects[4]},
+	}, changes[2:])
+}
+
+func TestDiff_TypeDiff(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{m}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	from, to := schema.New("public"), schema.New("public")
+	from.AddObjects(
+		&DomainType{T: "email", Schema: from, Base: &schema.StringType{T: "text"}, Checks: []*schema.Check{{Name: "email_check", Expr: "(VALUE ~~ '%@%'::text)"}}},
+		&DomainType{T: "positive", Schema: from, Base: &schema.IntegerType{T: "integer"}},
+		&CompositeType{T: "address", Schema: from, Fields: []*CompositeField{{Name: "street", Type: &schema.StringType{T: "text"}}}},
+		&CompositeType{T: "point3d", Schema: from, Fields: []*CompositeField{{Name: "x", Type: &schema.FloatType{T: "double precision"}}}},
+	)
+	to.AddObjects(
+		// Checks are matched by their names.
+		&DomainType{T: "email", Schema: to, Base: &schema.StringType{T: "text"}, Checks: []*schema.Check{{Name: "email_check", Expr: "VALUE ~~ '%@%'"}}},
+		&DomainType{T: "positive", Schema: to, Base: &schema.IntegerType{T: "int4"}, Null: true},
+		&CompositeType{T: "address", Schema: to, Fields: []*CompositeField{{Name: "street", Type: &schema.StringType{T: "text"}}}},
+		&CompositeType{T: "money2", Schema: to, Fields: []*CompositeField{{Name: "amount", Type: &schema.DecimalType{T: "numeric"}}}},
+	)
+	changes, err := drv.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.EqualValues(t, []schema.Change{
+		&schema.ModifyObject{From: from.Objects[1], To: to.Objects[1]},
+		&schema.DropObject{O: from.Objects[3]},
+		&schema.AddObject{O: to.Objects[3]},
+	}, changes)
+
+	// Column types are compared by their names.
+	from = schema.New("public").AddTables(schema.NewTable("users").AddColumns(&schema.Column{Name: "email", Type: &schema.ColumnType{Type: &DomainType{T: "email"}}}))
+	to = schema.New("public").AddTables(schema.NewTable("users").AddColumns(&schema.Column{Name: "email", Type: &schema.ColumnType{Type: &DomainType{T: "email", Base: &schema.StringType{T: "text"}}}}))
+	changes, err = drv.SchemaDiff(from, to)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
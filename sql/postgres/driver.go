@@ -24,7 +24,7 @@ type (
 	// generating diff between schema elements and apply migrations changes.
 	Driver struct {
 		conn
-		schema.Differ
+		schema.OptionsDiffer
 		schema.Inspector
 		migrate.PlanApplier
 	}
@@ -74,17 +74,17 @@ func Open(db schema.ExecQuerier) (migrate.Driver, error) {
 	// Means we are connected to CockroachDB because we have a result for name='crdb_version'. see `paramsQuery`.
 	if c.crdb = len(params) == 4; c.crdb {
 		return &Driver{
-			conn:        c,
-			Differ:      &sqlx.Diff{DiffDriver: &crdbDiff{diff{c}}},
-			Inspector:   &crdbInspect{inspect{c}},
-			PlanApplier: &planApply{c},
+			conn:          c,
+			OptionsDiffer: &sqlx.Diff{DiffDriver: &crdbDiff{diff{c}}},
+			Inspector:     &crdbInspect{inspect{c}},
+			PlanApplier:   &planApply{c},
 		}, nil
 	}
 	return &Driver{
-		conn:        c,
-		Differ:      &sqlx.Diff{DiffDriver: &diff{c}},
-		Inspector:   &inspect{c},
-		PlanApplier: &planApply{c},
+		conn:          c,
+		OptionsDiffer: &sqlx.Diff{DiffDriver: &diff{c}},
+		Inspector:     &inspect{c},
+		PlanApplier:   &planApply{c},
 	}, nil
 }
 
@@ -275,3 +275,14 @@ const (
 	PartitionTypeList  = "LIST"
 	PartitionTypeHash  = "HASH"
 )
+
+// List of row-level security policy types and commands.
+const (
+	PolicyPermissive  = "PERMISSIVE"
+	PolicyRestrictive = "RESTRICTIVE"
+	PolicyForAll      = "ALL"
+	PolicyForSelect   = "SELECT"
+	PolicyForInsert   = "INSERT"
+	PolicyForUpdate   = "UPDATE"
+	PolicyForDelete   = "DELETE"
+)
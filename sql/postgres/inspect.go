@@ -28,13 +28,26 @@ func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOpt
 	}
 	r := schema.NewRealm(schemas...).SetCollation(i.collate)
 	r.Attrs = append(r.Attrs, &CType{V: i.ctype})
-	if len(schemas) == 0 || !sqlx.ModeInspectRealm(opts).Is(schema.InspectTables) {
+	if len(schemas) == 0 {
 		return r, nil
 	}
-	if err := i.inspectTables(ctx, r, nil); err != nil {
-		return nil, err
+	mode := sqlx.ModeInspectRealm(opts)
+	if mode.Is(schema.InspectTables) {
+		if err := i.inspectTables(ctx, r, sqlx.RealmTablesOptions(opts), sqlx.ConcurrencyInspectRealm(i.ExecQuerier, opts)); err != nil {
+			return nil, err
+		}
+		sqlx.LinkSchemaTables(schemas)
+	}
+	if mode.Is(schema.InspectObjects) {
+		if err := i.inspectObjects(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+	if mode.Is(schema.InspectRoles) {
+		if err := i.inspectRoles(ctx, r); err != nil {
+			return nil, err
+		}
 	}
-	sqlx.LinkSchemaTables(schemas)
 	return r, nil
 }
 
@@ -53,56 +66,434 @@ func (i *inspect) InspectSchema(ctx context.Context, name string, opts *schema.I
 	}
 	r := schema.NewRealm(schemas...).SetCollation(i.collate)
 	r.Attrs = append(r.Attrs, &CType{V: i.ctype})
-	if sqlx.ModeInspectSchema(opts).Is(schema.InspectTables) {
-		if err := i.inspectTables(ctx, r, opts); err != nil {
+	mode := sqlx.ModeInspectSchema(opts)
+	if mode.Is(schema.InspectTables) {
+		if err := i.inspectTables(ctx, r, opts, 1); err != nil {
 			return nil, err
 		}
 		sqlx.LinkSchemaTables(schemas)
 	}
+	if mode.Is(schema.InspectObjects) {
+		if err := i.inspectObjects(ctx, r); err != nil {
+			return nil, err
+		}
+	}
 	return r.Schemas[0], nil
 }
 
-func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *schema.InspectOptions) error {
+// inspectTables inspects the tables of the realm schemas. The tables of each schema are
+// inspected separately, using at most n concurrent goroutines (and connections).
+func (i *inspect) inspectTables(ctx context.Context, r *schema.Realm, opts *schema.InspectOptions, n int) error {
 	if err := i.tables(ctx, r, opts); err != nil {
 		return err
 	}
+	if err := sqlx.ForEachSchema(ctx, n, r.Schemas, i.inspectSchemaTables); err != nil {
+		return err
+	}
+	if err := i.partitionTables(ctx, r); err != nil {
+		return err
+	}
+	return i.policies(ctx, r)
+}
+
+// inspectSchemaTables inspects the columns, indexes and constraints of the schema tables.
+func (i *inspect) inspectSchemaTables(ctx context.Context, s *schema.Schema) error {
+	if len(s.Tables) == 0 {
+		return nil
+	}
+	if err := i.columns(ctx, s); err != nil {
+		return err
+	}
+	if err := i.indexes(ctx, s); err != nil {
+		return err
+	}
+	if err := i.excludeOps(ctx, s); err != nil {
+		return err
+	}
+	if err := i.partitions(s); err != nil {
+		return err
+	}
+	if err := i.fks(ctx, s); err != nil {
+		return err
+	}
+	if err := i.constraintAttrs(ctx, s); err != nil {
+		return err
+	}
+	return i.checks(ctx, s)
+}
+
+// inspectObjects inspects the schema objects that are not tables. It is called
+// after the tables were inspected, as objects may reference them (e.g. OWNED BY).
+func (i *inspect) inspectObjects(ctx context.Context, r *schema.Realm) error {
+	// CockroachDB does not support the pg_sequences view, nor extensions.
+	if i.crdb {
+		return nil
+	}
+	if err := i.extensions(ctx, r); err != nil {
+		return err
+	}
+	if err := i.types(ctx, r); err != nil {
+		return err
+	}
+	return i.sequences(ctx, r)
+}
+
+// inspectRoles inspects the roles of the database and the privileges they were
+// granted on the realm schemas, tables and columns. It is called after the tables
+// were inspected, as permissions reference them.
+func (i *inspect) inspectRoles(ctx context.Context, r *schema.Realm) error {
+	// CockroachDB does not support the aclexplode function.
+	if i.crdb {
+		return nil
+	}
+	if err := i.roles(ctx, r); err != nil {
+		return err
+	}
+	return i.permissions(ctx, r)
+}
+
+// roles queries and appends the (non-system) roles of the database to the realm.
+func (i *inspect) roles(ctx context.Context, r *schema.Realm) error {
+	rows, err := i.QueryContext(ctx, rolesQuery)
+	if err != nil {
+		return fmt.Errorf("postgres: querying roles: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		role := &Role{}
+		if err := rows.Scan(&role.Name, &role.Login, &role.Superuser, &role.CreateDB, &role.CreateRole); err != nil {
+			return fmt.Errorf("postgres: scan role information: %w", err)
+		}
+		r.AddObjects(role)
+	}
+	return rows.Close()
+}
+
+// permissions queries and appends the privileges granted to the inspected roles on
+// the realm schemas, tables and columns. Privileges of the object owners are implicit
+// and therefore, are skipped. Privileges on tables that were not inspected are skipped
+// as well.
+func (i *inspect) permissions(ctx context.Context, r *schema.Realm) error {
+	args := make([]interface{}, 0, len(r.Schemas))
 	for _, s := range r.Schemas {
-		if len(s.Tables) == 0 {
+		args = append(args, s.Name)
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(permissionsQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying permissions: %w", err)
+	}
+	defer rows.Close()
+	perms := make(map[string]*Permission)
+	for rows.Next() {
+		var (
+			grantable              bool
+			ns, grantee, privilege string
+			tableName, columnName  sql.NullString
+		)
+		if err := rows.Scan(&ns, &tableName, &columnName, &grantee, &privilege, &grantable); err != nil {
+			return fmt.Errorf("postgres: scan permission information: %w", err)
+		}
+		role, ok := realmRole(r, grantee)
+		if !ok {
 			continue
 		}
-		if err := i.columns(ctx, s); err != nil {
-			return err
+		p := &Permission{Role: role, Grantable: grantable}
+		if p.Schema, p.Table, p.Column, ok = sqlx.PrivilegeTarget(r, ns, tableName, columnName); !ok {
+			continue
 		}
-		if err := i.indexes(ctx, s); err != nil {
-			return err
+		k := permissionKey(p)
+		if _, ok := perms[k]; !ok {
+			perms[k] = p
+			r.AddObjects(p)
+		}
+		perms[k].Privileges = append(perms[k].Privileges, strings.ToUpper(privilege))
+	}
+	return rows.Close()
+}
+
+// realmRole returns the role with the given name from the realm objects.
+func realmRole(r *schema.Realm, name string) (*Role, bool) {
+	for _, o := range r.Objects {
+		if role, ok := o.(*Role); ok && role.Name == name {
+			return role, true
+		}
+	}
+	return nil, false
+}
+
+// permissionKey returns the key of the permission. See sqlx.PermissionKey for more info.
+func permissionKey(p *Permission) string {
+	return sqlx.PermissionKey([]string{p.Role.Name}, p.Schema, p.Table, p.Column, p.Grantable)
+}
+
+// extensions queries and appends the extensions installed in the given realm schemas.
+func (i *inspect) extensions(ctx context.Context, r *schema.Realm) error {
+	args := make([]interface{}, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(extensionsQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying extensions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			ns  string
+			ext = &Extension{}
+		)
+		if err := rows.Scan(&ns, &ext.Name, &ext.Version); err != nil {
+			return fmt.Errorf("postgres: scan extension information: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q was not found in realm", ns)
+		}
+		ext.Schema = s
+		s.AddObjects(ext)
+	}
+	return rows.Close()
+}
+
+// types queries and appends the domain and composite types of the given realm schemas,
+// and sets them as the types of the columns that use them. Enum types are not included,
+// as they are inspected together with their columns.
+func (i *inspect) types(ctx context.Context, r *schema.Realm) error {
+	args := make([]interface{}, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(typesQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying types: %w", err)
+	}
+	var (
+		domainIDs, compositeIDs []interface{}
+		domains                 = make(map[int64]*DomainType)
+		composites              = make(map[int64]*CompositeType)
+	)
+	if err := func() error {
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				id                int64
+				notnull           bool
+				ns, name, typtype string
+				base, defaults    sql.NullString
+			)
+			if err := rows.Scan(&id, &ns, &name, &typtype, &base, &notnull, &defaults); err != nil {
+				return fmt.Errorf("postgres: scan type information: %w", err)
+			}
+			s, ok := r.Schema(ns)
+			if !ok {
+				return fmt.Errorf("postgres: schema %q was not found in realm", ns)
+			}
+			switch typtype {
+			case "d":
+				t, err := ParseType(base.String)
+				if err != nil {
+					return err
+				}
+				d := &DomainType{T: name, Schema: s, Base: t, Null: !notnull}
+				if sqlx.ValidString(defaults) {
+					d.Default = defaultExpr(&schema.Column{Type: &schema.ColumnType{Type: t, Raw: base.String}}, defaults.String)
+				}
+				domains[id] = d
+				domainIDs = append(domainIDs, id)
+				s.AddObjects(d)
+			case "c":
+				c := &CompositeType{T: name, Schema: s}
+				composites[id] = c
+				compositeIDs = append(compositeIDs, id)
+				s.AddObjects(c)
+			}
 		}
-		if err := i.partitions(s); err != nil {
+		return rows.Close()
+	}(); err != nil {
+		return err
+	}
+	if len(domains) > 0 {
+		if err := i.domainChecks(ctx, domains, domainIDs); err != nil {
 			return err
 		}
-		if err := i.fks(ctx, s); err != nil {
+	}
+	if len(composites) > 0 {
+		if err := i.compositeFields(ctx, composites, compositeIDs); err != nil {
 			return err
 		}
-		if err := i.checks(ctx, s); err != nil {
+	}
+	return i.typeColumns(ctx, r, domains, composites)
+}
+
+// domainChecks queries and appends the CHECK constraints of the given domains.
+func (i *inspect) domainChecks(ctx context.Context, domains map[int64]*DomainType, args []interface{}) error {
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(domainChecksQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying domain checks: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			id   int64
+			name string
+			expr string
+		)
+		if err := rows.Scan(&id, &name, &expr); err != nil {
+			return fmt.Errorf("postgres: scan domain check: %w", err)
+		}
+		if d, ok := domains[id]; ok {
+			d.Checks = append(d.Checks, &schema.Check{Name: name, Expr: expr})
+		}
+	}
+	return rows.Close()
+}
+
+// compositeFields queries and appends the fields of the given composite types.
+func (i *inspect) compositeFields(ctx context.Context, composites map[int64]*CompositeType, args []interface{}) error {
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(compositeFieldsQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying composite fields: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			id        int64
+			name, typ string
+		)
+		if err := rows.Scan(&id, &name, &typ); err != nil {
+			return fmt.Errorf("postgres: scan composite field: %w", err)
+		}
+		t, err := ParseType(typ)
+		if err != nil {
 			return err
 		}
+		if c, ok := composites[id]; ok {
+			c.Fields = append(c.Fields, &CompositeField{Name: name, Type: t})
+		}
 	}
-	return nil
+	return rows.Close()
+}
+
+// typeColumns sets the domain and composite types as the types of the inspected columns
+// that use them. The information schema reports the base type for domain columns, and a
+// user-defined type for composite columns.
+func (i *inspect) typeColumns(ctx context.Context, r *schema.Realm, domains map[int64]*DomainType, composites map[int64]*CompositeType) error {
+	if len(domains) == 0 && len(composites) == 0 {
+		return nil
+	}
+	var args []interface{}
+	for _, s := range r.Schemas {
+		if len(s.Tables) > 0 {
+			args = append(args, s.Name)
+		}
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(typeColumnsQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying type columns: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			id                int64
+			ns, table, column string
+		)
+		if err := rows.Scan(&ns, &table, &column, &id); err != nil {
+			return fmt.Errorf("postgres: scan type column: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			continue
+		}
+		t, ok := s.Table(table)
+		if !ok {
+			continue
+		}
+		c, ok := t.Column(column)
+		if !ok {
+			continue
+		}
+		if d, ok := domains[id]; ok {
+			c.Type.Type = d
+		} else if ct, ok := composites[id]; ok {
+			c.Type.Type = ct
+		}
+	}
+	return rows.Close()
+}
+
+// sequences queries and appends the standalone sequences of the given realm schemas.
+// Sequences that back identity columns are skipped, as they are managed by their columns.
+func (i *inspect) sequences(ctx context.Context, r *schema.Realm) error {
+	args := make([]interface{}, 0, len(r.Schemas))
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(sequencesQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying sequences: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			seq                    = &Sequence{}
+			last                   sql.NullInt64
+			ns                     string
+			ownerS, ownerT, ownerC sql.NullString
+		)
+		if err := rows.Scan(&ns, &seq.Name, &seq.Type, &seq.Start, &seq.Min, &seq.Max, &seq.Increment, &seq.Cycle, &last, &ownerS, &ownerT, &ownerC); err != nil {
+			return fmt.Errorf("postgres: scan sequence information: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q was not found in realm", ns)
+		}
+		seq.Schema = s
+		seq.Last = last.Int64
+		if sqlx.ValidString(ownerT) && sqlx.ValidString(ownerC) {
+			seq.OwnedBy = sequenceOwner(r, ownerS.String, ownerT.String, ownerC.String)
+		}
+		s.AddObjects(seq)
+	}
+	return rows.Close()
+}
+
+// sequenceOwner returns the owner of a sequence. In case the owner table
+// was not inspected, a table that holds only the owner column is returned.
+func sequenceOwner(r *schema.Realm, ns, table, column string) *SequenceOwner {
+	s, ok := r.Schema(ns)
+	if !ok {
+		s = schema.New(ns)
+	}
+	if t, ok := s.Table(table); ok {
+		if c, ok := t.Column(column); ok {
+			return &SequenceOwner{T: t, C: c}
+		}
+	}
+	c := schema.NewColumn(column)
+	return &SequenceOwner{T: &schema.Table{Name: table, Schema: s, Columns: []*schema.Column{c}}, C: c}
 }
 
 // table returns the table from the database, or a NotExistError if the table was not found.
 func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.InspectOptions) error {
 	var (
-		args  []interface{}
-		query = fmt.Sprintf(tablesQuery, nArgs(0, len(realm.Schemas)))
+		args   []interface{}
+		query  = fmt.Sprintf(tablesQuery, nArgs(0, len(realm.Schemas)))
+		filter = &sqlx.NameFilter{}
 	)
 	for _, s := range realm.Schemas {
 		args = append(args, s.Name)
 	}
-	if opts != nil && len(opts.Tables) > 0 {
-		for _, t := range opts.Tables {
-			args = append(args, t)
-		}
-		query = fmt.Sprintf(tablesQueryArgs, nArgs(0, len(realm.Schemas)), nArgs(len(realm.Schemas), len(opts.Tables)))
+	if opts != nil {
+		filter = sqlx.NewNameFilter(opts.Tables, opts.Exclude)
+	}
+	if cond := filter.Cond([]string{"t1.table_schema", "t1.table_name"}, "", func(v string) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}); cond != "" {
+		query = fmt.Sprintf(tablesQueryArgs, nArgs(0, len(realm.Schemas)), cond)
 	}
 	rows, err := i.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -117,6 +508,9 @@ func (i *inspect) tables(ctx context.Context, realm *schema.Realm, opts *schema.
 		if !sqlx.ValidString(tSchema) || !sqlx.ValidString(name) {
 			return fmt.Errorf("invalid schema or table name: %q.%q", tSchema.String, name.String)
 		}
+		if !filter.Match(tSchema.String, name.String) {
+			continue
+		}
 		s, ok := realm.Schema(tSchema.String)
 		if !ok {
 			return fmt.Errorf("schema %q was not found in realm", tSchema.String)
@@ -373,12 +767,12 @@ func (i *inspect) addIndexes(s *schema.Schema, rows *sql.Rows) error {
 	names := make(map[string]*schema.Index)
 	for rows.Next() {
 		var (
-			uniq, primary                                 bool
+			uniq, primary, valid                          bool
 			table, name, typ                              string
 			desc, nullsfirst, nullslast                   sql.NullBool
 			column, contype, pred, expr, comment, options sql.NullString
 		)
-		if err := rows.Scan(&table, &name, &typ, &column, &primary, &uniq, &contype, &pred, &expr, &desc, &nullsfirst, &nullslast, &comment, &options); err != nil {
+		if err := rows.Scan(&table, &name, &typ, &column, &primary, &uniq, &contype, &pred, &expr, &desc, &nullsfirst, &nullslast, &comment, &options, &valid); err != nil {
 			return fmt.Errorf("postgres: scanning indexes for schema %q: %w", s.Name, err)
 		}
 		t, ok := s.Table(table)
@@ -411,6 +805,9 @@ func (i *inspect) addIndexes(s *schema.Schema, rows *sql.Rows) error {
 				}
 				idx.Attrs = append(idx.Attrs, p)
 			}
+			if !valid {
+				idx.Attrs = append(idx.Attrs, &IndexInvalid{})
+			}
 			names[name] = idx
 			if primary {
 				t.PrimaryKey = idx
@@ -444,6 +841,41 @@ func (i *inspect) addIndexes(s *schema.Schema, rows *sql.Rows) error {
 	return nil
 }
 
+// excludeOps queries and sets the element operators of the exclusion constraints in the schema.
+func (i *inspect) excludeOps(ctx context.Context, s *schema.Schema) error {
+	indexes := make(map[[2]string]*schema.Index)
+	for _, t := range s.Tables {
+		for _, idx := range t.Indexes {
+			if c := (ConType{}); sqlx.Has(idx.Attrs, &c) && c.IsExclude() {
+				indexes[[2]string{t.Name, idx.Name}] = idx
+			}
+		}
+	}
+	if len(indexes) == 0 {
+		return nil
+	}
+	rows, err := i.querySchema(ctx, excludeOpsQuery, s)
+	if err != nil {
+		return fmt.Errorf("postgres: querying schema %q exclusion constraints: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			ord             int
+			table, name, op string
+		)
+		if err := rows.Scan(&table, &name, &ord, &op); err != nil {
+			return fmt.Errorf("postgres: scanning exclusion constraints for schema %q: %w", s.Name, err)
+		}
+		idx, ok := indexes[[2]string{table, name}]
+		if !ok || ord < 1 || ord > len(idx.Parts) {
+			return fmt.Errorf("postgres: unexpected element %d for exclusion constraint %q", ord, name)
+		}
+		idx.Parts[ord-1].Attrs = append(idx.Parts[ord-1].Attrs, &ExcludeOp{Op: op})
+	}
+	return rows.Err()
+}
+
 // partitions builds the partition each table in the schema.
 func (i *inspect) partitions(s *schema.Schema) error {
 	for _, t := range s.Tables {
@@ -451,49 +883,230 @@ func (i *inspect) partitions(s *schema.Schema) error {
 		if !sqlx.Has(t.Attrs, &d) {
 			continue
 		}
-		switch s := strings.ToLower(d.start); s {
-		case "r":
-			d.T = PartitionTypeRange
-		case "l":
-			d.T = PartitionTypeList
-		case "h":
-			d.T = PartitionTypeHash
+		if err := partitionKey(&d, t.Name, t.Columns); err != nil {
+			return err
+		}
+		schema.ReplaceOrAppend(&t.Attrs, &d)
+	}
+	return nil
+}
+
+// partitionKey parses the strategy and the parts of the partition key
+// of the given table from the internal info returned by the database.
+func partitionKey(d *Partition, table string, columns []*schema.Column) error {
+	switch s := strings.ToLower(d.start); s {
+	case "r":
+		d.T = PartitionTypeRange
+	case "l":
+		d.T = PartitionTypeList
+	case "h":
+		d.T = PartitionTypeHash
+	default:
+		return fmt.Errorf("postgres: unexpected partition strategy %q", s)
+	}
+	idxs := strings.Split(strings.TrimSpace(d.attrs), " ")
+	if len(idxs) == 0 {
+		return fmt.Errorf("postgres: no columns/expressions were found in partition key for column %q", table)
+	}
+	for i := range idxs {
+		switch idx, err := strconv.Atoi(idxs[i]); {
+		case err != nil:
+			return fmt.Errorf("postgres: faild parsing partition key index %q", idxs[i])
+		// An expression.
+		case idx == 0:
+			j := sqlx.ExprLastIndex(d.exprs)
+			if j == -1 {
+				return fmt.Errorf("postgres: no expression found in partition key: %q", d.exprs)
+			}
+			d.Parts = append(d.Parts, &PartitionPart{
+				X: &schema.RawExpr{X: d.exprs[:j+1]},
+			})
+			d.exprs = strings.TrimPrefix(d.exprs[j+1:], ", ")
+		// A column at index idx-1.
 		default:
-			return fmt.Errorf("postgres: unexpected partition strategy %q", s)
-		}
-		idxs := strings.Split(strings.TrimSpace(d.attrs), " ")
-		if len(idxs) == 0 {
-			return fmt.Errorf("postgres: no columns/expressions were found in partition key for column %q", t.Name)
-		}
-		for i := range idxs {
-			switch idx, err := strconv.Atoi(idxs[i]); {
-			case err != nil:
-				return fmt.Errorf("postgres: faild parsing partition key index %q", idxs[i])
-			// An expression.
-			case idx == 0:
-				j := sqlx.ExprLastIndex(d.exprs)
-				if j == -1 {
-					return fmt.Errorf("postgres: no expression found in partition key: %q", d.exprs)
+			if idx > len(columns) {
+				return fmt.Errorf("postgres: unexpected column index %d", idx)
+			}
+			d.Parts = append(d.Parts, &PartitionPart{
+				C: columns[idx-1],
+			})
+		}
+	}
+	return nil
+}
+
+// partitionTables queries and appends the partitions (PARTITION OF) of the partitioned
+// tables in the realm. Partitions inherit their columns, indexes and constraints from
+// their parent tables, and therefore, only their bounds and sub-partition keys are set.
+func (i *inspect) partitionTables(ctx context.Context, r *schema.Realm) error {
+	var (
+		args        []interface{}
+		partitioned bool
+	)
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+		for _, t := range s.Tables {
+			partitioned = partitioned || sqlx.Has(t.Attrs, &Partition{})
+		}
+	}
+	// CockroachDB does not support declarative partitioning.
+	if !partitioned || i.crdb {
+		return nil
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(partitionsQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying partitions: %w", err)
+	}
+	type partition struct {
+		t              *schema.Table
+		s              *schema.Schema
+		pSchema, pName string
+	}
+	var parts []*partition
+	if err := func() error {
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				p                                 partition
+				ns, name, bound                   string
+				comment, partattrs, start, pexprs sql.NullString
+			)
+			if err := rows.Scan(&ns, &name, &p.pSchema, &p.pName, &bound, &comment, &partattrs, &start, &pexprs); err != nil {
+				return fmt.Errorf("postgres: scan partition information: %w", err)
+			}
+			s, ok := r.Schema(ns)
+			if !ok {
+				return fmt.Errorf("postgres: schema %q was not found in realm", ns)
+			}
+			p.s, p.t = s, &schema.Table{Name: name}
+			p.t.AddAttrs(&PartitionOf{Bound: bound})
+			if sqlx.ValidString(comment) {
+				p.t.SetComment(comment.String)
+			}
+			if sqlx.ValidString(partattrs) {
+				p.t.AddAttrs(&Partition{start: start.String, attrs: partattrs.String, exprs: pexprs.String})
+			}
+			parts = append(parts, &p)
+		}
+		return rows.Close()
+	}(); err != nil {
+		return err
+	}
+	// Partitions are linked to their parents in passes, as
+	// sub-partitions may be returned before their parents.
+	for len(parts) > 0 {
+		var next []*partition
+		for _, p := range parts {
+			ps, ok := r.Schema(p.pSchema)
+			if !ok {
+				continue
+			}
+			parent, ok := ps.Table(p.pName)
+			if !ok {
+				next = append(next, p)
+				continue
+			}
+			for _, a := range p.t.Attrs {
+				if of, ok := a.(*PartitionOf); ok {
+					of.Parent = parent
 				}
-				d.Parts = append(d.Parts, &PartitionPart{
-					X: &schema.RawExpr{X: d.exprs[:j+1]},
-				})
-				d.exprs = strings.TrimPrefix(d.exprs[j+1:], ", ")
-			// A column at index idx-1.
-			default:
-				if idx > len(t.Columns) {
-					return fmt.Errorf("postgres: unexpected column index %d", idx)
+			}
+			p.s.AddTables(p.t)
+			if d := (&Partition{}); sqlx.Has(p.t.Attrs, d) {
+				if err := partitionKey(d, p.t.Name, partitionRoot(p.t).Columns); err != nil {
+					return err
 				}
-				d.Parts = append(d.Parts, &PartitionPart{
-					C: t.Columns[idx-1],
-				})
+				schema.ReplaceOrAppend(&p.t.Attrs, d)
 			}
 		}
-		schema.ReplaceOrAppend(&t.Attrs, &d)
+		// Partitions of tables that were not inspected are skipped.
+		if len(next) == len(parts) {
+			break
+		}
+		parts = next
 	}
 	return nil
 }
 
+// partitionRoot returns the root table of the given partition.
+func partitionRoot(t *schema.Table) *schema.Table {
+	for {
+		of := &PartitionOf{}
+		if !sqlx.Has(t.Attrs, of) || of.Parent == nil {
+			return t
+		}
+		t = of.Parent
+	}
+}
+
+// policies queries and appends the row-level security settings and the
+// policies of the realm tables. Policies are stored as table attributes.
+func (i *inspect) policies(ctx context.Context, r *schema.Realm) error {
+	var (
+		args   []interface{}
+		tables bool
+	)
+	for _, s := range r.Schemas {
+		args = append(args, s.Name)
+		tables = tables || len(s.Tables) > 0
+	}
+	// CockroachDB does not support row-level security.
+	if !tables || i.crdb {
+		return nil
+	}
+	rows, err := i.QueryContext(ctx, fmt.Sprintf(policiesQuery, nArgs(0, len(args))), args...)
+	if err != nil {
+		return fmt.Errorf("postgres: querying policies: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			enabled, forced, permissive    bool
+			ns, table                      string
+			name, cmd, roles, using, check sql.NullString
+		)
+		if err := rows.Scan(&ns, &table, &enabled, &forced, &name, &permissive, &cmd, &roles, &using, &check); err != nil {
+			return fmt.Errorf("postgres: scan policy information: %w", err)
+		}
+		s, ok := r.Schema(ns)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q was not found in realm", ns)
+		}
+		// Policies of tables that were not inspected are skipped.
+		t, ok := s.Table(table)
+		if !ok {
+			continue
+		}
+		if (enabled || forced) && !sqlx.Has(t.Attrs, &RowSecurity{}) {
+			t.AddAttrs(&RowSecurity{Enabled: enabled, Forced: forced})
+		}
+		if !sqlx.ValidString(name) {
+			continue
+		}
+		p := &Policy{Name: name.String, As: PolicyPermissive, Using: using.String, Check: check.String}
+		if !permissive {
+			p.As = PolicyRestrictive
+		}
+		switch cmd.String {
+		case "r":
+			p.For = PolicyForSelect
+		case "a":
+			p.For = PolicyForInsert
+		case "w":
+			p.For = PolicyForUpdate
+		case "d":
+			p.For = PolicyForDelete
+		default:
+			p.For = PolicyForAll
+		}
+		if roles.String != "" && roles.String != "PUBLIC" {
+			p.To = strings.Split(roles.String, ",")
+		}
+		t.AddAttrs(p)
+	}
+	return rows.Err()
+}
+
 // fks queries and appends the foreign keys of the given table.
 func (i *inspect) fks(ctx context.Context, s *schema.Schema) error {
 	rows, err := i.querySchema(ctx, fksQuery, s)
@@ -507,6 +1120,58 @@ func (i *inspect) fks(ctx context.Context, s *schema.Schema) error {
 	return rows.Err()
 }
 
+// constraintAttrs queries and sets the attributes of the foreign keys and the
+// index-based constraints in the schema that were not validated or are deferrable.
+func (i *inspect) constraintAttrs(ctx context.Context, s *schema.Schema) error {
+	var (
+		fks     = make(map[[2]string]*schema.ForeignKey)
+		indexes = make(map[[2]string]*schema.Index)
+	)
+	for _, t := range s.Tables {
+		for _, fk := range t.ForeignKeys {
+			fks[[2]string{t.Name, fk.Symbol}] = fk
+		}
+		for _, idx := range t.Indexes {
+			if c := (ConType{}); sqlx.Has(idx.Attrs, &c) && (c.IsUnique() || c.IsExclude()) {
+				indexes[[2]string{t.Name, idx.Name}] = idx
+			}
+		}
+	}
+	if len(fks) == 0 && len(indexes) == 0 {
+		return nil
+	}
+	rows, err := i.querySchema(ctx, constraintAttrsQuery, s)
+	if err != nil {
+		return fmt.Errorf("postgres: querying schema %q constraint attributes: %w", s.Name, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			table, name, typ                string
+			validated, deferrable, deferred bool
+		)
+		if err := rows.Scan(&table, &name, &typ, &validated, &deferrable, &deferred); err != nil {
+			return fmt.Errorf("postgres: scanning constraint attributes for schema %q: %w", s.Name, err)
+		}
+		var attrs *[]schema.Attr
+		switch k := [2]string{table, name}; {
+		case typ == "f" && fks[k] != nil:
+			attrs = &fks[k].Attrs
+		case typ != "f" && indexes[k] != nil:
+			attrs = &indexes[k].Attrs
+		default:
+			continue
+		}
+		if !validated {
+			*attrs = append(*attrs, &NotValid{})
+		}
+		if deferrable {
+			*attrs = append(*attrs, &Deferrable{InitiallyDeferred: deferred})
+		}
+	}
+	return rows.Err()
+}
+
 // checks queries and appends the check constraints of the given table.
 func (i *inspect) checks(ctx context.Context, s *schema.Schema) error {
 	rows, err := i.querySchema(ctx, checksQuery, s)
@@ -525,10 +1190,10 @@ func (i *inspect) addChecks(s *schema.Schema, rows *sql.Rows) error {
 	names := make(map[string]*schema.Check)
 	for rows.Next() {
 		var (
-			noInherit                            bool
+			noInherit, validated                 bool
 			table, name, column, clause, indexes string
 		)
-		if err := rows.Scan(&table, &name, &clause, &column, &indexes, &noInherit); err != nil {
+		if err := rows.Scan(&table, &name, &clause, &column, &indexes, &noInherit, &validated); err != nil {
 			return fmt.Errorf("postgres: scanning check: %w", err)
 		}
 		t, ok := s.Table(table)
@@ -544,6 +1209,9 @@ func (i *inspect) addChecks(s *schema.Schema, rows *sql.Rows) error {
 			if noInherit {
 				check.Attrs = append(check.Attrs, &NoInherit{})
 			}
+			if !validated {
+				check.Attrs = append(check.Attrs, &NotValid{})
+			}
 			names[name] = check
 			t.Attrs = append(t.Attrs, check)
 		}
@@ -556,21 +1224,28 @@ func (i *inspect) addChecks(s *schema.Schema, rows *sql.Rows) error {
 // schemas returns the list of the schemas in the database.
 func (i *inspect) schemas(ctx context.Context, opts *schema.InspectRealmOption) ([]*schema.Schema, error) {
 	var (
-		args  []interface{}
-		query = schemasQuery
+		args   []interface{}
+		query  = schemasQuery
+		filter = sqlx.RealmSchemasFilter(opts)
+		glob   = len(filter.Patterns)+len(filter.Exclude) > 0
 	)
-	if opts != nil {
-		switch n := len(opts.Schemas); {
-		case n == 1 && opts.Schemas[0] == "":
-			query = fmt.Sprintf(schemasQueryArgs, "= CURRENT_SCHEMA()")
-		case n == 1 && opts.Schemas[0] != "":
-			query = fmt.Sprintf(schemasQueryArgs, "= $1")
-			args = append(args, opts.Schemas[0])
-		case n > 0:
-			query = fmt.Sprintf(schemasQueryArgs, "IN ("+nArgs(0, len(opts.Schemas))+")")
-			for _, s := range opts.Schemas {
-				args = append(args, s)
-			}
+	switch n := len(filter.Names); {
+	case glob:
+		if cond := filter.Cond([]string{"schema_name"}, "", func(v string) string {
+			args = append(args, v)
+			return "$" + strconv.Itoa(len(args))
+		}); cond != "" {
+			query = fmt.Sprintf(schemasQueryFilter, cond)
+		}
+	case n == 1 && filter.Names[0] == "":
+		query = fmt.Sprintf(schemasQueryArgs, "= CURRENT_SCHEMA()")
+	case n == 1 && filter.Names[0] != "":
+		query = fmt.Sprintf(schemasQueryArgs, "= $1")
+		args = append(args, filter.Names[0])
+	case n > 0:
+		query = fmt.Sprintf(schemasQueryArgs, "IN ("+nArgs(0, n)+")")
+		for _, s := range filter.Names {
+			args = append(args, s)
 		}
 	}
 	rows, err := i.QueryContext(ctx, query, args...)
@@ -584,6 +1259,10 @@ func (i *inspect) schemas(ctx context.Context, opts *schema.InspectRealmOption)
 		if err := rows.Scan(&name); err != nil {
 			return nil, err
 		}
+		// Patterns that cannot be expressed in the query are checked here.
+		if glob && !filter.Match(name) {
+			continue
+		}
 		schemas = append(schemas, &schema.Schema{
 			Name: name,
 		})
@@ -739,13 +1418,111 @@ type (
 		T string // c, f, p, u, t, x.
 	}
 
-	// Sequence defines (the supported) sequence options.
+	// Sequence defines (the supported) sequence options. A Sequence is either a
+	// standalone schema object, or the sequence of an identity column. In the
+	// latter case, its name, schema and ownership are managed by the database.
 	// https://www.postgresql.org/docs/current/sql-createsequence.html
 	Sequence struct {
+		schema.Object
+		Name   string
+		Schema *schema.Schema
+		// Type holds the data type of the sequence. One
+		// of: smallint, integer or bigint (the default).
+		Type             string
 		Start, Increment int64
+		// Min and Max hold the MINVALUE and MAXVALUE options. If both
+		// are zero, the defaults of the sequence type are used.
+		Min, Max int64
+		Cycle    bool
 		// Last sequence value written to disk.
 		// https://www.postgresql.org/docs/current/view-pg-sequences.html.
 		Last int64
+		// OwnedBy holds the column the sequence is owned by, if any.
+		OwnedBy *SequenceOwner
+	}
+
+	// SequenceOwner describes the table column that owns a sequence (OWNED BY).
+	// An owned sequence is dropped automatically when its column is dropped.
+	SequenceOwner struct {
+		T *schema.Table
+		C *schema.Column
+	}
+
+	// Extension describes a PostgreSQL extension that is installed in a schema.
+	// https://www.postgresql.org/docs/current/sql-createextension.html
+	Extension struct {
+		schema.Object
+		Name   string
+		Schema *schema.Schema
+		// Version holds the installed version. An empty
+		// version stands for the default version.
+		Version string
+	}
+
+	// DomainType defines a domain type. A domain is a schema object that
+	// wraps a base type with an optional NOT NULL, DEFAULT and CHECK
+	// constraints, and can be used as a column type.
+	// https://www.postgresql.org/docs/current/sql-createdomain.html
+	DomainType struct {
+		schema.Type
+		schema.Object
+		T      string
+		Schema *schema.Schema
+		// Base holds the underlying type of the domain.
+		Base schema.Type
+		// Null reports if the domain accepts null values,
+		// i.e. it was not defined with NOT NULL.
+		Null    bool
+		Default schema.Expr
+		Checks  []*schema.Check
+	}
+
+	// CompositeType defines a composite type. A composite type is a
+	// schema object that describes the structure of a row, and can
+	// be used as a column type.
+	// https://www.postgresql.org/docs/current/rowtypes.html
+	CompositeType struct {
+		schema.Type
+		schema.Object
+		T      string
+		Schema *schema.Schema
+		Fields []*CompositeField
+	}
+
+	// CompositeField describes a field (attribute) of a composite type.
+	CompositeField struct {
+		Name string
+		Type schema.Type
+	}
+
+	// Role describes a database role. Users are roles that are allowed to log in.
+	// Passwords and other secrets are neither inspected nor managed by Atlas.
+	// https://www.postgresql.org/docs/current/sql-createrole.html
+	Role struct {
+		schema.Object
+		Name       string
+		Login      bool
+		Superuser  bool
+		CreateDB   bool
+		CreateRole bool
+	}
+
+	// Permission describes the privileges granted to a role on a schema, a table
+	// or a table column. Schema is always set, Table is set for table and column
+	// privileges, and Column is set only for column privileges.
+	// https://www.postgresql.org/docs/current/sql-grant.html
+	Permission struct {
+		schema.Object
+		Role   *Role
+		Schema *schema.Schema
+		Table  *schema.Table
+		Column *schema.Column
+		// Privileges holds the granted privileges
+		// in upper-case (e.g. SELECT, INSERT).
+		Privileges []string
+		// Grantable indicates the privileges were
+		// granted WITH GRANT OPTION.
+		Grantable bool
 	}
 
 	// Identity defines an identity column.
@@ -790,12 +1567,53 @@ type (
 		PagesPerRange int64
 	}
 
+	// ExcludeOp describes the operator of an exclusion constraint element, and
+	// is stored in the index parts of indexes that implement exclusion constraints
+	// (i.e. indexes with ConType "x"). For example, "=" or "&&".
+	// https://www.postgresql.org/docs/current/sql-createtable.html#SQL-CREATETABLE-EXCLUDE
+	ExcludeOp struct {
+		schema.Attr
+		Op string
+	}
+
+	// IndexConcurrently instructs the planner to create or drop the index
+	// using the CONCURRENTLY option, without blocking writes on its table.
+	// It is a planning attribute and it is not returned by inspection.
+	// https://www.postgresql.org/docs/current/sql-createindex.html#SQL-CREATEINDEX-CONCURRENTLY
+	IndexConcurrently struct {
+		schema.Attr
+	}
+
+	// IndexInvalid describes an index that is not valid for queries (i.e.
+	// pg_index.indisvalid is false). For example, an index that was left
+	// behind by a failed CREATE INDEX CONCURRENTLY command.
+	IndexInvalid struct {
+		schema.Attr
+	}
+
 	// NoInherit attribute defines the NO INHERIT flag for CHECK constraint.
 	// https://www.postgresql.org/docs/current/catalog-pg-constraint.html
 	NoInherit struct {
 		schema.Attr
 	}
 
+	// NotValid describes a CHECK or FOREIGN KEY constraint that was added with the
+	// NOT VALID option, and was not validated yet (i.e. pg_constraint.convalidated
+	// is false). Such constraints are enforced for new rows, but existing rows are
+	// not guaranteed to satisfy them. See ValidateConstraints for more info.
+	NotValid struct {
+		schema.Attr
+	}
+
+	// Deferrable describes a FOREIGN KEY, UNIQUE or EXCLUDE constraint that is
+	// DEFERRABLE, i.e. its checking can be postponed to the end of the transaction.
+	// If InitiallyDeferred is set, the constraint is checked at the end of each
+	// transaction by default (INITIALLY DEFERRED).
+	Deferrable struct {
+		schema.Attr
+		InitiallyDeferred bool
+	}
+
 	// CheckColumns attribute hold the column named used by the CHECK constraints.
 	// This attribute is added on inspection for internal usage and has no meaning
 	// on migration.
@@ -818,6 +1636,18 @@ type (
 		start, attrs, exprs string
 	}
 
+	// PartitionOf describes a table that is a partition of another
+	// (partitioned) table. Partitions inherit their columns, indexes
+	// and constraints from their parents.
+	PartitionOf struct {
+		schema.Attr
+		// Parent is the partitioned table.
+		Parent *schema.Table
+		// Bound is the partition bound specification. For example:
+		// "FOR VALUES FROM ('2022-01-01') TO ('2023-01-01')" or "DEFAULT".
+		Bound string
+	}
+
 	// An PartitionPart represents an index part that
 	// can be either an expression or a column.
 	PartitionPart struct {
@@ -825,11 +1655,44 @@ type (
 		C     *schema.Column
 		Attrs []schema.Attr
 	}
+
+	// RowSecurity describes the row-level security settings of a table.
+	// https://www.postgresql.org/docs/current/ddl-rowsecurity.html
+	RowSecurity struct {
+		schema.Attr
+		// Enabled indicates the table was altered
+		// with ENABLE ROW LEVEL SECURITY.
+		Enabled bool
+		// Forced indicates the table was altered with FORCE ROW
+		// LEVEL SECURITY, i.e. policies apply to the table owner.
+		Forced bool
+	}
+
+	// Policy describes a row-level security policy of a table.
+	// https://www.postgresql.org/docs/current/sql-createpolicy.html
+	Policy struct {
+		schema.Attr
+		Name string
+		// As holds the policy type. One of: PERMISSIVE (the default) or RESTRICTIVE.
+		As string
+		// For holds the command the policy applies to. One
+		// of: ALL (the default), SELECT, INSERT, UPDATE or DELETE.
+		For string
+		// To holds the roles the policy applies to.
+		// An empty list stands for PUBLIC.
+		To []string
+		// Using and Check hold the USING and
+		// the WITH CHECK expressions, if any.
+		Using, Check string
+	}
 )
 
 // IsUnique reports if the type is unique constraint.
 func (c ConType) IsUnique() bool { return strings.ToLower(c.T) == "u" }
 
+// IsExclude reports if the type is exclusion constraint.
+func (c ConType) IsExclude() bool { return strings.ToLower(c.T) == "x" }
+
 // newIndexStorage parses and returns the index storage parameters.
 func newIndexStorage(opts string) (*IndexStorageParams, error) {
 	params := &IndexStorageParams{}
@@ -866,6 +1729,9 @@ const (
 	// Query to list specific database schemas.
 	schemasQueryArgs = "SELECT schema_name FROM information_schema.schemata WHERE schema_name %s ORDER BY schema_name"
 
+	// Query to list database schemas that match glob patterns.
+	schemasQueryFilter = "SELECT schema_name FROM information_schema.schemata WHERE schema_name NOT IN ('information_schema', 'pg_catalog', 'pg_toast', 'crdb_internal', 'pg_extension') AND schema_name NOT LIKE 'pg_%%temp_%%' AND %s ORDER BY schema_name"
+
 	// Query to list table information.
 	tablesQuery = `
 SELECT
@@ -904,9 +1770,62 @@ WHERE
 	t1.table_type = 'BASE TABLE'
 	AND NOT COALESCE(t3.relispartition, false)
 	AND t1.table_schema IN (%s)
-	AND t1.table_name IN (%s)
+	AND %s
 ORDER BY
 	t1.table_schema, t1.table_name
+`
+	// Query to list the row-level security settings and the policies of tables.
+	policiesQuery = `
+SELECT
+	n.nspname AS table_schema,
+	c.relname AS table_name,
+	c.relrowsecurity AS row_security,
+	c.relforcerowsecurity AS force_row_security,
+	p.polname AS policy_name,
+	COALESCE(p.polpermissive, true) AS permissive,
+	p.polcmd AS command,
+	(
+		SELECT string_agg(r.name, ',' ORDER BY r.name)
+		FROM (SELECT CASE WHEN u.oid = 0 THEN 'PUBLIC' ELSE pg_catalog.pg_get_userbyid(u.oid)::text END AS name FROM unnest(p.polroles) AS u(oid)) AS r
+	) AS roles,
+	pg_catalog.pg_get_expr(p.polqual, p.polrelid) AS using_expr,
+	pg_catalog.pg_get_expr(p.polwithcheck, p.polrelid) AS check_expr
+FROM
+	pg_catalog.pg_class AS c
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+	LEFT JOIN pg_catalog.pg_policy AS p ON p.polrelid = c.oid
+WHERE
+	n.nspname IN (%s)
+	AND c.relkind IN ('r', 'p')
+	AND (c.relrowsecurity OR c.relforcerowsecurity OR p.oid IS NOT NULL)
+ORDER BY
+	n.nspname, c.relname, p.polname
+`
+	// Query to list the partitions of partitioned tables.
+	partitionsQuery = `
+SELECT
+	n1.nspname AS table_schema,
+	c1.relname AS table_name,
+	n2.nspname AS parent_schema,
+	c2.relname AS parent_name,
+	pg_get_expr(c1.relpartbound, c1.oid) AS partition_bound,
+	pg_catalog.obj_description(c1.oid, 'pg_class') AS comment,
+	t.partattrs AS partition_attrs,
+	t.partstrat AS partition_strategy,
+	pg_get_expr(t.partexprs, t.partrelid) AS partition_exprs
+FROM
+	pg_catalog.pg_inherits AS i
+	JOIN pg_catalog.pg_class AS c1 ON c1.oid = i.inhrelid
+	JOIN pg_catalog.pg_namespace AS n1 ON n1.oid = c1.relnamespace
+	JOIN pg_catalog.pg_class AS c2 ON c2.oid = i.inhparent
+	JOIN pg_catalog.pg_namespace AS n2 ON n2.oid = c2.relnamespace
+	LEFT JOIN pg_catalog.pg_partitioned_table AS t ON t.partrelid = c1.oid
+WHERE
+	c1.relispartition
+	AND c1.relkind IN ('r', 'p')
+	AND n1.nspname IN (%s)
+ORDER BY
+	n1.nspname, c1.relname
 `
 	// Query to list table columns.
 	columnsQuery = `
@@ -961,7 +1880,8 @@ SELECT
 	pg_index_column_has_property(idx.indexrelid, idx.ord, 'nulls_first') AS nulls_first,
 	pg_index_column_has_property(idx.indexrelid, idx.ord, 'nulls_last') AS nulls_last,
 	obj_description(i.oid, 'pg_class') AS comment,
-	i.reloptions AS options
+	i.reloptions AS options,
+	idx.indisvalid AS valid
 FROM
 	(
 		select
@@ -982,6 +1902,26 @@ WHERE
 	AND COALESCE(c.contype, '') <> 'f'
 ORDER BY
 	table_name, index_name, idx.ord
+`
+	// Query to list the element operators of exclusion constraints.
+	excludeOpsQuery = `
+SELECT
+	t.relname AS table_name,
+	c.conname AS constraint_name,
+	o.ord AS ordinal,
+	p.oprname AS operator
+FROM
+	pg_catalog.pg_constraint AS c
+	JOIN pg_catalog.pg_class AS t ON t.oid = c.conrelid
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = t.relnamespace
+	CROSS JOIN unnest(c.conexclop) WITH ORDINALITY AS o(oid, ord)
+	JOIN pg_catalog.pg_operator AS p ON p.oid = o.oid
+WHERE
+	c.contype = 'x'
+	AND n.nspname = $1
+	AND t.relname IN (%s)
+ORDER BY
+	table_name, constraint_name, ordinal
 `
 	fksQuery = `
 SELECT
@@ -1014,6 +1954,29 @@ ORDER BY
     t2.ordinal_position
 `
 
+	// Query to list the foreign keys and the index-based constraints
+	// that were not validated yet, or that are deferrable.
+	constraintAttrsQuery = `
+SELECT
+	t.relname AS table_name,
+	c.conname AS constraint_name,
+	c.contype AS constraint_type,
+	c.convalidated AS validated,
+	c.condeferrable AS deferrable,
+	c.condeferred AS deferred
+FROM
+	pg_catalog.pg_constraint AS c
+	JOIN pg_catalog.pg_class AS t ON t.oid = c.conrelid
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = t.relnamespace
+WHERE
+	c.contype IN ('f', 'u', 'x')
+	AND (NOT c.convalidated OR c.condeferrable)
+	AND n.nspname = $1
+	AND t.relname IN (%s)
+ORDER BY
+	table_name, constraint_name
+`
+
 	// Query to list table check constraints.
 	checksQuery = `
 SELECT
@@ -1022,7 +1985,8 @@ SELECT
 	pg_get_expr(t1.conbin, t1.conrelid) as expression,
 	t2.attname as column_name,
 	t1.conkey as column_indexes,
-	t1.connoinherit as no_inherit
+	t1.connoinherit as no_inherit,
+	t1.convalidated as validated
 FROM
 	pg_constraint t1
 	JOIN pg_attribute t2
@@ -1039,4 +2003,202 @@ WHERE
 ORDER BY
 	t1.conname, array_position(t1.conkey, t2.attnum)
 `
+
+	// Query to list standalone sequences (i.e. not identity sequences) and their owners.
+	sequencesQuery = `
+SELECT
+	s.schemaname AS schema_name,
+	s.sequencename AS sequence_name,
+	s.data_type,
+	s.start_value,
+	s.min_value,
+	s.max_value,
+	s.increment_by,
+	s.cycle,
+	s.last_value,
+	n2.nspname AS owner_schema,
+	t2.relname AS owner_table,
+	a.attname AS owner_column
+FROM
+	pg_catalog.pg_sequences AS s
+	JOIN pg_catalog.pg_namespace AS n1 ON n1.nspname = s.schemaname
+	JOIN pg_catalog.pg_class AS t1 ON t1.relnamespace = n1.oid AND t1.relname = s.sequencename
+	LEFT JOIN pg_catalog.pg_depend AS d ON d.classid = 'pg_catalog.pg_class'::regclass AND d.objid = t1.oid AND d.refobjsubid > 0 AND d.deptype = 'a'
+	LEFT JOIN pg_catalog.pg_class AS t2 ON t2.oid = d.refobjid
+	LEFT JOIN pg_catalog.pg_namespace AS n2 ON n2.oid = t2.relnamespace
+	LEFT JOIN pg_catalog.pg_attribute AS a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+WHERE
+	s.schemaname IN (%s)
+	AND NOT EXISTS (
+		SELECT 1 FROM pg_catalog.pg_depend AS i
+		WHERE i.classid = 'pg_catalog.pg_class'::regclass AND i.objid = t1.oid AND i.deptype = 'i'
+	)
+ORDER BY
+	s.schemaname, s.sequencename
+`
+
+	// Query to list the domain and composite types defined in the given schemas. Composite
+	// types that describe the row type of tables (or other relations) are excluded.
+	typesQuery = `
+SELECT
+	t.oid,
+	n.nspname AS schema_name,
+	t.typname AS type_name,
+	t.typtype,
+	(CASE WHEN t.typtype = 'd' THEN pg_catalog.format_type(t.typbasetype, t.typtypmod) END) AS base_type,
+	t.typnotnull,
+	t.typdefault
+FROM
+	pg_catalog.pg_type AS t
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = t.typnamespace
+	LEFT JOIN pg_catalog.pg_class AS c ON c.oid = t.typrelid
+WHERE
+	n.nspname IN (%s)
+	AND (t.typtype = 'd' OR (t.typtype = 'c' AND c.relkind = 'c'))
+ORDER BY
+	n.nspname, t.typtype DESC, t.typname
+`
+
+	// Query to list the CHECK constraints of the given domain types.
+	domainChecksQuery = `
+SELECT
+	contypid,
+	conname,
+	pg_catalog.pg_get_expr(conbin, 0) AS expression
+FROM
+	pg_catalog.pg_constraint
+WHERE
+	contype = 'c'
+	AND contypid IN (%s)
+ORDER BY
+	contypid, conname
+`
+
+	// Query to list the fields of the given composite types.
+	compositeFieldsQuery = `
+SELECT
+	t.oid,
+	a.attname,
+	pg_catalog.format_type(a.atttypid, a.atttypmod) AS format_type
+FROM
+	pg_catalog.pg_type AS t
+	JOIN pg_catalog.pg_attribute AS a ON a.attrelid = t.typrelid
+WHERE
+	t.oid IN (%s)
+	AND a.attnum > 0
+	AND NOT a.attisdropped
+ORDER BY
+	t.oid, a.attnum
+`
+
+	// Query to list the table columns that use domain or composite types.
+	typeColumnsQuery = `
+SELECT
+	n.nspname AS table_schema,
+	c.relname AS table_name,
+	a.attname AS column_name,
+	t.oid AS type_id
+FROM
+	pg_catalog.pg_attribute AS a
+	JOIN pg_catalog.pg_class AS c ON c.oid = a.attrelid AND c.relkind IN ('r', 'p')
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+	JOIN pg_catalog.pg_type AS t ON t.oid = a.atttypid AND t.typtype IN ('d', 'c')
+WHERE
+	n.nspname IN (%s)
+	AND a.attnum > 0
+	AND NOT a.attisdropped
+ORDER BY
+	n.nspname, c.relname, a.attnum
+`
+
+	// Query to list the extensions installed in the given schemas.
+	extensionsQuery = `
+SELECT
+	n.nspname AS schema_name,
+	e.extname AS extension_name,
+	e.extversion AS extension_version
+FROM
+	pg_catalog.pg_extension AS e
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = e.extnamespace
+WHERE
+	n.nspname IN (%s)
+ORDER BY
+	n.nspname, e.extname
+`
+
+	// Query to list the roles of the database. System roles (e.g. pg_monitor) and
+	// the bootstrap superuser are created by initdb, and therefore, are skipped.
+	rolesQuery = `
+SELECT
+	r.rolname,
+	r.rolcanlogin,
+	r.rolsuper,
+	r.rolcreatedb,
+	r.rolcreaterole
+FROM
+	pg_catalog.pg_roles AS r
+WHERE
+	r.oid >= 16384
+	AND r.rolname !~ '^pg_'
+ORDER BY
+	r.rolname
+`
+
+	// Query to list the privileges granted on the given schemas, their tables and columns.
+	// Privileges granted to the object owners or to PUBLIC are skipped.
+	permissionsQuery = `
+SELECT
+	n.nspname AS schema_name,
+	NULL AS table_name,
+	NULL AS column_name,
+	r.rolname AS grantee,
+	a.privilege_type,
+	a.is_grantable
+FROM
+	pg_catalog.pg_namespace AS n
+	CROSS JOIN LATERAL aclexplode(n.nspacl) AS a
+	JOIN pg_catalog.pg_roles AS r ON r.oid = a.grantee
+WHERE
+	n.nspname IN (%[1]s)
+	AND a.grantee <> n.nspowner
+UNION ALL
+SELECT
+	n.nspname AS schema_name,
+	c.relname AS table_name,
+	NULL AS column_name,
+	r.rolname AS grantee,
+	a.privilege_type,
+	a.is_grantable
+FROM
+	pg_catalog.pg_class AS c
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+	CROSS JOIN LATERAL aclexplode(c.relacl) AS a
+	JOIN pg_catalog.pg_roles AS r ON r.oid = a.grantee
+WHERE
+	n.nspname IN (%[1]s)
+	AND c.relkind IN ('r', 'p')
+	AND a.grantee <> c.relowner
+UNION ALL
+SELECT
+	n.nspname AS schema_name,
+	c.relname AS table_name,
+	t.attname AS column_name,
+	r.rolname AS grantee,
+	a.privilege_type,
+	a.is_grantable
+FROM
+	pg_catalog.pg_attribute AS t
+	JOIN pg_catalog.pg_class AS c ON c.oid = t.attrelid
+	JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace
+	CROSS JOIN LATERAL aclexplode(t.attacl) AS a
+	JOIN pg_catalog.pg_roles AS r ON r.oid = a.grantee
+WHERE
+	n.nspname IN (%[1]s)
+	AND c.relkind IN ('r', 'p')
+	AND t.attnum > 0
+	AND NOT t.attisdropped
+	AND a.grantee <> c.relowner
+ORDER BY
+	1, 2, 3, 4, 5
+`
 )
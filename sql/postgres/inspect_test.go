@@ -22,10 +22,15 @@ var (
 	queryFKs         = sqltest.Escape(fmt.Sprintf(fksQuery, "$2"))
 	queryTables      = sqltest.Escape(fmt.Sprintf(tablesQuery, "$1"))
 	queryChecks      = sqltest.Escape(fmt.Sprintf(checksQuery, "$2"))
+	queryConAttrs    = sqltest.Escape(fmt.Sprintf(constraintAttrsQuery, "$2"))
 	queryColumns     = sqltest.Escape(fmt.Sprintf(columnsQuery, "$2"))
 	queryCrdbColumns = sqltest.Escape(fmt.Sprintf(crdbColumnsQuery, "$2"))
 	queryIndexes     = sqltest.Escape(fmt.Sprintf(indexesQuery, "$2"))
 	queryCrdbIndexes = sqltest.Escape(fmt.Sprintf(crdbIndexesQuery, "$2"))
+	querySequences   = sqltest.Escape(fmt.Sprintf(sequencesQuery, "$1"))
+	queryExtensions  = sqltest.Escape(fmt.Sprintf(extensionsQuery, "$1"))
+	queryTypes       = sqltest.Escape(fmt.Sprintf(typesQuery, "$1"))
+	queryPolicies    = sqltest.Escape(fmt.Sprintf(policiesQuery, "$1"))
 )
 
 func TestDriver_InspectTable(t *testing.T) {
@@ -146,19 +151,27 @@ users      | parent_id   | bigint              | int8      |  YES         |
 				m.ExpectQuery(queryIndexes).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
-   table_name   |    index_name   | index_type  | column_name | primary | unique | constraint_type | predicate             |   expression              | desc | nulls_first | nulls_last | comment   | options
-----------------+-----------------+-------------+-------------+---------+--------+-----------------+-----------------------+---------------------------+------+-------------+------------+-----------+-----------
-users           | idx             | hash        |             | f       | f      |                 |                       | "left"((c11)::text, 100)  | t    | t           | f          | boring    |
-users           | idx1            | btree       |             | f       | f      |                 | (id <> NULL::integer) | "left"((c11)::text, 100)  | t    | t           | f          |           |
-users           | t1_c1_key       | btree       | c1          | f       | t      | u               |                       | c1                        | t    | t           | f          |           |
-users           | t1_pkey         | btree       | id          | t       | t      | p               |                       | id                        | t    | f           | f          |           |
-users           | idx4            | btree       | c1          | f       | t      |                 |                       | c1                        | f    | f           | f          |           |
-users           | idx4            | btree       | id          | f       | t      |                 |                       | id                        | f    | f           | t          |           |
-users           | idx5            | btree       | c1          | f       | t      |                 |                       | c1                        | f    | f           | f          |           |
-users           | idx5            | btree       |             | f       | t      |                 |                       | coalesce(parent_id, 0)    | f    | f           | f          |           |
-users           | idx6            | brin        | c1          | f       | t      |                 |                       |                           | f    | f           | f          |           | {autosummarize=true,pages_per_range=2}
+ table_name | index_name | index_type | column_name | primary | unique | constraint_type | predicate             | expression               | desc | nulls_first | nulls_last | comment | options                                | valid
+------------+------------+------------+-------------+---------+--------+-----------------+-----------------------+--------------------------+------+-------------+------------+---------+----------------------------------------+-------
+ users      | idx        | hash       |             | f       | f      |                 |                       | "left"((c11)::text, 100) | t    | t           | f          | boring  |                                        | t
+ users      | idx1       | btree      |             | f       | f      |                 | (id <> NULL::integer) | "left"((c11)::text, 100) | t    | t           | f          |         |                                        | t
+ users      | t1_c1_key  | btree      | c1          | f       | t      | u               |                       | c1                       | t    | t           | f          |         |                                        | t
+ users      | t1_pkey    | btree      | id          | t       | t      | p               |                       | id                       | t    | f           | f          |         |                                        | t
+ users      | idx4       | btree      | c1          | f       | t      |                 |                       | c1                       | f    | f           | f          |         |                                        | t
+ users      | idx4       | btree      | id          | f       | t      |                 |                       | id                       | f    | f           | t          |         |                                        | t
+ users      | idx5       | btree      | c1          | f       | t      |                 |                       | c1                       | f    | f           | f          |         |                                        | t
+ users      | idx5       | btree      |             | f       | t      |                 |                       | coalesce(parent_id, 0)   | f    | f           | f          |         |                                        | t
+ users      | idx6       | brin       | c1          | f       | t      |                 |                       |                          | f    | f           | f          |         | {autosummarize=true,pages_per_range=2} | t
+ users      | idx7       | btree      | parent_id   | f       | f      |                 |                       | parent_id                | f    | f           | f          |         |                                        | f
 `))
 				m.noFKs()
+				m.ExpectQuery(queryConAttrs).
+					WithArgs("public", "users").
+					WillReturnRows(sqltest.Rows(`
+ table_name | constraint_name | constraint_type | validated | deferrable | deferred
+------------+-----------------+-----------------+-----------+------------+----------
+ users      | t1_c1_key       | u               | t         | t          | t
+`))
 				m.noChecks()
 			},
 			expect: func(require *require.Assertions, t *schema.Table, err error) {
@@ -172,10 +185,11 @@ users           | idx6            | brin        | c1          | f       | t
 				indexes := []*schema.Index{
 					{Name: "idx", Table: t, Attrs: []schema.Attr{&IndexType{T: "hash"}, &schema.Comment{Text: "boring"}}, Parts: []*schema.IndexPart{{SeqNo: 1, X: &schema.RawExpr{X: `"left"((c11)::text, 100)`}, Desc: true, Attrs: []schema.Attr{&IndexColumnProperty{NullsFirst: true}}}}},
 					{Name: "idx1", Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}, &IndexPredicate{P: `(id <> NULL::integer)`}}, Parts: []*schema.IndexPart{{SeqNo: 1, X: &schema.RawExpr{X: `"left"((c11)::text, 100)`}, Desc: true, Attrs: []schema.Attr{&IndexColumnProperty{NullsFirst: true}}}}},
-					{Name: "t1_c1_key", Unique: true, Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}, &ConType{T: "u"}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[1], Desc: true, Attrs: []schema.Attr{&IndexColumnProperty{NullsFirst: true}}}}},
+					{Name: "t1_c1_key", Unique: true, Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}, &ConType{T: "u"}, &Deferrable{InitiallyDeferred: true}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[1], Desc: true, Attrs: []schema.Attr{&IndexColumnProperty{NullsFirst: true}}}}},
 					{Name: "idx4", Unique: true, Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[1]}, {SeqNo: 2, C: columns[0], Attrs: []schema.Attr{&IndexColumnProperty{NullsLast: true}}}}},
 					{Name: "idx5", Unique: true, Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[1]}, {SeqNo: 2, X: &schema.RawExpr{X: `coalesce(parent_id, 0)`}}}},
 					{Name: "idx6", Unique: true, Table: t, Attrs: []schema.Attr{&IndexType{T: "brin"}, &IndexStorageParams{AutoSummarize: true, PagesPerRange: 2}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[1]}}},
+					{Name: "idx7", Table: t, Attrs: []schema.Attr{&IndexType{T: "btree"}, &IndexInvalid{}}, Parts: []*schema.IndexPart{{SeqNo: 1, C: columns[2]}}},
 				}
 				pk := &schema.Index{
 					Name:   "t1_pkey",
@@ -185,12 +199,58 @@ users           | idx6            | brin        | c1          | f       | t
 					Parts:  []*schema.IndexPart{{SeqNo: 1, C: columns[0], Desc: true}},
 				}
 				columns[0].Indexes = append(columns[0].Indexes, pk, indexes[3])
-				columns[1].Indexes = indexes[2:]
+				columns[1].Indexes = indexes[2:6]
+				columns[2].Indexes = indexes[6:]
 				require.EqualValues(columns, t.Columns)
 				require.EqualValues(indexes, t.Indexes)
 				require.EqualValues(pk, t.PrimaryKey)
 			},
 		},
+		{
+			name: "exclusion constraints",
+			before: func(m mock) {
+				m.tableExists("public", "bookings", true)
+				m.ExpectQuery(queryColumns).
+					WithArgs("public", "bookings").
+					WillReturnRows(sqltest.Rows(`
+table_name | column_name | data_type | formatted |  is_nullable | column_default | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment | identity_last | identity_generation | generation_expression | comment | typtype |  oid
+-----------+-------------+-----------+-----------+--------------+----------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+---------------+---------------------+-----------------------+---------+---------+-------
+bookings   | room        | integer   | int4      |  NO          |                |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |               |                     |                       |         | b       |    23
+bookings   | during      | tsrange   | tsrange   |  NO          |                |                          |                   |                    |               |               |                    |                | NO          |                |                    |               |                     |                       |         | r       |  3908
+`))
+				m.ExpectQuery(queryIndexes).
+					WithArgs("public", "bookings").
+					WillReturnRows(sqltest.Rows(`
+ table_name | index_name       | index_type | column_name | primary | unique | constraint_type | predicate  | expression | desc | nulls_first | nulls_last | comment | options | valid
+------------+------------------+------------+-------------+---------+--------+-----------------+------------+------------+------+-------------+------------+---------+---------+-------
+ bookings   | bookings_overlap | gist       | room        | f       | f      | x               | (room > 0) | room       | f    | f           | f          |         |         | t
+ bookings   | bookings_overlap | gist       | during      | f       | f      | x               | (room > 0) | during     | f    | f           | f          |         |         | t
+`))
+				m.ExpectQuery(sqltest.Escape(fmt.Sprintf(excludeOpsQuery, "$2"))).
+					WithArgs("public", "bookings").
+					WillReturnRows(sqltest.Rows(`
+ table_name | constraint_name  | ordinal | operator
+------------+------------------+---------+----------
+ bookings   | bookings_overlap | 1       | =
+ bookings   | bookings_overlap | 2       | &&
+`))
+				m.noFKs()
+				m.noConAttrs()
+				m.noChecks()
+			},
+			expect: func(require *require.Assertions, t *schema.Table, err error) {
+				require.NoError(err)
+				require.Len(t.Indexes, 1)
+				idx := t.Indexes[0]
+				require.Equal("bookings_overlap", idx.Name)
+				require.Equal([]schema.Attr{&IndexType{T: "gist"}, &ConType{T: "x"}, &IndexPredicate{P: "(room > 0)"}}, idx.Attrs)
+				require.Len(idx.Parts, 2)
+				require.Equal(t.Columns[0], idx.Parts[0].C)
+				require.Equal([]schema.Attr{&ExcludeOp{Op: "="}}, idx.Parts[0].Attrs)
+				require.Equal(t.Columns[1], idx.Parts[1].C)
+				require.Equal([]schema.Attr{&ExcludeOp{Op: "&&"}}, idx.Parts[1].Attrs)
+			},
+		},
 		{
 			name: "fks",
 			before: func(m mock) {
@@ -215,6 +275,14 @@ multi_column    | users      | id          | public       | t1
 multi_column    | users      | oid         | public       | t1                    | gid                    | public                 | NO ACTION   | CASCADE
 multi_column    | users      | oid         | public       | t1                    | xid                    | public                 | NO ACTION   | CASCADE
 self_reference  | users      | uid         | public       | users                 | id                     | public                 | NO ACTION   | CASCADE
+`))
+				m.ExpectQuery(queryConAttrs).
+					WithArgs("public", "users").
+					WillReturnRows(sqltest.Rows(`
+table_name | constraint_name | constraint_type | validated | deferrable | deferred
+-----------+-----------------+-----------------+-----------+------------+----------
+users      | multi_column    | f               | t         | t          | f
+users      | self_reference  | f               | f         | f          | f
 `))
 				m.noChecks()
 			},
@@ -223,8 +291,8 @@ self_reference  | users      | uid         | public       | users
 				require.Equal("users", t.Name)
 				require.Equal("public", t.Schema.Name)
 				fks := []*schema.ForeignKey{
-					{Symbol: "multi_column", Table: t, OnUpdate: schema.NoAction, OnDelete: schema.Cascade, RefTable: &schema.Table{Name: "t1", Schema: t.Schema}, RefColumns: []*schema.Column{{Name: "gid"}, {Name: "xid"}}},
-					{Symbol: "self_reference", Table: t, OnUpdate: schema.NoAction, OnDelete: schema.Cascade, RefTable: t},
+					{Symbol: "multi_column", Table: t, OnUpdate: schema.NoAction, OnDelete: schema.Cascade, RefTable: &schema.Table{Name: "t1", Schema: t.Schema}, RefColumns: []*schema.Column{{Name: "gid"}, {Name: "xid"}}, Attrs: []schema.Attr{&Deferrable{}}},
+					{Symbol: "self_reference", Table: t, OnUpdate: schema.NoAction, OnDelete: schema.Cascade, RefTable: t, Attrs: []schema.Attr{&NotValid{}}},
 				}
 				columns := []*schema.Column{
 					{Name: "id", Type: &schema.ColumnType{Raw: "integer", Type: &schema.IntegerType{T: "integer"}}, ForeignKeys: fks[0:1]},
@@ -256,18 +324,17 @@ users      | c3         | integer   | int4      | NO          |                |
 				m.ExpectQuery(queryChecks).
 					WithArgs("public", "users").
 					WillReturnRows(sqltest.Rows(`
-table_name   | constraint_name    |       expression        | column_name | column_indexes | no_inherit
--------------+--------------------+-------------------------+-------------+----------------+----------------
-users        | boring             | (c1 > 1)                | c1          | {1}            | t
-users        | users_c2_check     | (c2 > 0)                | c2          | {2}            | f
-users        | users_c2_check1    | (c2 > 0)                | c2          | {2}            | f
-users        | users_check        | ((c2 + c1) > 2)         | c2          | {2,1}          | f
-users        | users_check        | ((c2 + c1) > 2)         | c1          | {2,1}          | f
-users        | users_check1       | (((c2 + c1) + c3) > 10) | c2          | {2,1,3}        | f
-users        | users_check1       | (((c2 + c1) + c3) > 10) | c1          | {2,1,3}        | f
-users        | users_check1       | (((c2 + c1) + c3) > 10) | c3          | {2,1,3}        | f
+table_name   | constraint_name    |       expression        | column_name | column_indexes | no_inherit | validated
+-------------+--------------------+-------------------------+-------------+----------------+------------+-----------
+users        | boring             | (c1 > 1)                | c1          | {1}            | t          | t
+users        | users_c2_check     | (c2 > 0)                | c2          | {2}            | f          | f
+users        | users_c2_check1    | (c2 > 0)                | c2          | {2}            | f          | t
+users        | users_check        | ((c2 + c1) > 2)         | c2          | {2,1}          | f          | t
+users        | users_check        | ((c2 + c1) > 2)         | c1          | {2,1}          | f          | t
+users        | users_check1       | (((c2 + c1) + c3) > 10) | c2          | {2,1,3}        | f          | t
+users        | users_check1       | (((c2 + c1) + c3) > 10) | c1          | {2,1,3}        | f          | t
+users        | users_check1       | (((c2 + c1) + c3) > 10) | c3          | {2,1,3}        | f          | t
 `))
-				m.noChecks()
 			},
 			expect: func(require *require.Assertions, t *schema.Table, err error) {
 				require.NoError(err)
@@ -280,7 +347,7 @@ users        | users_check1       | (((c2 + c1) + c3) > 10) | c3          | {2,1
 				}, t.Columns)
 				require.EqualValues([]schema.Attr{
 					&schema.Check{Name: "boring", Expr: "(c1 > 1)", Attrs: []schema.Attr{&CheckColumns{Columns: []string{"c1"}}, &NoInherit{}}},
-					&schema.Check{Name: "users_c2_check", Expr: "(c2 > 0)", Attrs: []schema.Attr{&CheckColumns{Columns: []string{"c2"}}}},
+					&schema.Check{Name: "users_c2_check", Expr: "(c2 > 0)", Attrs: []schema.Attr{&CheckColumns{Columns: []string{"c2"}}, &NotValid{}}},
 					&schema.Check{Name: "users_c2_check1", Expr: "(c2 > 0)", Attrs: []schema.Attr{&CheckColumns{Columns: []string{"c2"}}}},
 					&schema.Check{Name: "users_check", Expr: "((c2 + c1) > 2)", Attrs: []schema.Attr{&CheckColumns{Columns: []string{"c2", "c1"}}}},
 					&schema.Check{Name: "users_check1", Expr: "(((c2 + c1) + c3) > 10)", Attrs: []schema.Attr{&CheckColumns{Columns: []string{"c2", "c1", "c3"}}}},
@@ -305,6 +372,10 @@ users        | users_check1       | (((c2 + c1) + c3) > 10) | c3          | {2,1
  public
 `))
 			tt.before(mk)
+			mk.noPolicies()
+			mk.noExtensions()
+			mk.noTypes()
+			mk.noSequences()
 			s, err := drv.InspectSchema(context.Background(), "public", nil)
 			require.NoError(t, err)
 			tt.expect(require.New(t), s.Tables[0], err)
@@ -352,6 +423,20 @@ logs3      | c5         | integer   | integer   | NO          |                |
 		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "table_name", "column_name", "referenced_table_name", "referenced_column_name", "referenced_table_schema", "update_rule", "delete_rule"}))
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(checksQuery, "$2, $3, $4"))).
 		WillReturnRows(sqlmock.NewRows([]string{"table_name", "constraint_name", "expression", "column_name", "column_indexes"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(partitionsQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ table_schema | table_name  | parent_schema | parent_name |       partition_bound        | comment | partition_attrs | partition_strategy | partition_exprs
+--------------+-------------+---------------+-------------+------------------------------+---------+-----------------+--------------------+-----------------
+ public       | logs2_a     | public        | logs2       | FOR VALUES FROM (1) TO (10)  |         |                 |                    |
+ public       | logs2_b     | public        | logs2_c     | FOR VALUES IN (1)            |         |                 |                    |
+ public       | logs2_c     | public        | logs2       | DEFAULT                      | c       | 2               | l                  |
+ public       | logs9_a     | public        | logs9       | DEFAULT                      |         |                 |                    |
+`))
+	mk.noPolicies()
+	mk.noExtensions()
+	mk.noTypes()
+	mk.noSequences()
 	s, err := drv.InspectSchema(context.Background(), "", &schema.InspectOptions{})
 	require.NoError(t, err)
 
@@ -378,6 +463,73 @@ logs3      | c5         | integer   | integer   | NO          |                |
 		{X: &schema.RawExpr{X: "(a + b)"}},
 		{X: &schema.RawExpr{X: "(a + (b * 2))"}},
 	}, key.Parts)
+
+	// Partitions of tables that were not inspected are skipped.
+	_, ok = s.Table("logs9_a")
+	require.False(t, ok)
+	p1, ok := s.Table("logs2_a")
+	require.True(t, ok)
+	require.Empty(t, p1.Columns)
+	require.Equal(t, []schema.Attr{&PartitionOf{Parent: t2, Bound: "FOR VALUES FROM (1) TO (10)"}}, p1.Attrs)
+	p3, ok := s.Table("logs2_c")
+	require.True(t, ok)
+	require.Equal(t, &PartitionOf{Parent: t2, Bound: "DEFAULT"}, p3.Attrs[0])
+	require.Equal(t, &schema.Comment{Text: "c"}, p3.Attrs[1])
+	key = p3.Attrs[2].(*Partition)
+	require.Equal(t, PartitionTypeList, key.T)
+	require.Equal(t, []*PartitionPart{{C: t2.Columns[1]}}, key.Parts)
+	p2, ok := s.Table("logs2_b")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&PartitionOf{Parent: p3, Bound: "FOR VALUES IN (1)"}}, p2.Attrs)
+}
+
+func TestDriver_InspectPolicies(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= $1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ schema_name
+-------------
+ public
+`))
+	mk.tableExists("public", "users", true)
+	mk.ExpectQuery(queryColumns).
+		WithArgs("public", "users").
+		WillReturnRows(sqltest.Rows(`
+table_name | column_name | data_type | formatted | is_nullable | column_default | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment | identity_last | identity_generation | generation_expression | comment | typtype | oid
+-----------+-------------+-----------+-----------+-------------+----------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+---------------+---------------------+-----------------------+---------+---------+-----
+users      | tenant      | integer   | int4      | NO          |                |                          |                32 |                    |             0 |               |                    |                | NO          |                |                    |               |                     |                       |         | b       |  23
+`))
+	mk.noIndexes()
+	mk.noFKs()
+	mk.noChecks()
+	mk.ExpectQuery(queryPolicies).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ table_schema | table_name | row_security | force_row_security | policy_name | permissive | command |    roles    |                           using_expr                           | check_expr
+--------------+------------+--------------+--------------------+-------------+------------+---------+-------------+----------------------------------------------------------------+------------
+ public       | users      | t            | t                  | p1          | t          | *       | PUBLIC      | (tenant = (current_setting('app.tenant'::text))::integer)      | NULL
+ public       | users      | t            | t                  | p2          | f          | a       | admin,app   | NULL                                                           | (tenant > 0)
+ public       | logs       | t            | f                  | NULL        | t          | NULL    | NULL        | NULL                                                           | NULL
+`))
+	mk.noExtensions()
+	mk.noTypes()
+	mk.noSequences()
+	s, err := drv.InspectSchema(context.Background(), "public", nil)
+	require.NoError(t, err)
+	users, ok := s.Table("users")
+	require.True(t, ok)
+	// Policies of tables that were not inspected are skipped.
+	require.Equal(t, []schema.Attr{
+		&RowSecurity{Enabled: true, Forced: true},
+		&Policy{Name: "p1", As: PolicyPermissive, For: PolicyForAll, Using: "(tenant = (current_setting('app.tenant'::text))::integer)"},
+		&Policy{Name: "p2", As: PolicyRestrictive, For: PolicyForInsert, To: []string{"admin", "app"}, Check: "(tenant > 0)"},
+	}, users.Attrs)
 }
 
 func TestDriver_InspectCRDBSchema(t *testing.T) {
@@ -427,6 +579,7 @@ users       | idx5       | b           | false   | false  |                 | CR
 users       | idx5       | c           | false   | false  |                 | CREATE INDEX idx5 ON defaultdb.public.serial USING btree (a ASC, b ASC, c ASC)  |           | c          |  
 `))
 	mk.noFKs()
+	mk.noConAttrs()
 	mk.noChecks()
 	s, err := drv.InspectSchema(context.Background(), "public", nil)
 	require.NoError(t, err)
@@ -469,6 +622,15 @@ test
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "$1"))).
 		WithArgs("test").
 		WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name", "comment", "partition_attrs", "partition_strategy", "partition_exprs"}))
+	m.ExpectQuery(queryExtensions).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows(extColumns))
+	m.ExpectQuery(queryTypes).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows(typeColumns))
+	m.ExpectQuery(querySequences).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows(seqColumns))
 	s, err := drv.InspectSchema(context.Background(), "", &schema.InspectOptions{})
 	require.NoError(t, err)
 	require.EqualValues(t, func() *schema.Schema {
@@ -493,6 +655,33 @@ test
 	}(), s)
 }
 
+func TestDriver_InspectSchemaPatterns(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= $1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+   schema_name
+--------------------
+public
+`))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQueryArgs, "$1", "(t1.table_name IN ($2) OR t1.table_name LIKE $3) AND NOT t1.table_name LIKE $4"))).
+		WithArgs("public", "users", `audit\_%`, `audit\_tmp`).
+		WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name", "comment", "partition_attrs", "partition_strategy", "partition_exprs"}))
+	s, err := drv.InspectSchema(context.Background(), "public", &schema.InspectOptions{
+		Mode:    schema.InspectTables,
+		Tables:  []string{"users", "audit_*"},
+		Exclude: []string{"audit_tmp"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "public", s.Name)
+	require.Empty(t, s.Tables)
+}
+
 func TestDriver_Realm(t *testing.T) {
 	db, m, err := sqlmock.New()
 	require.NoError(t, err)
@@ -510,6 +699,15 @@ public
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "$1, $2"))).
 		WithArgs("test", "public").
 		WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name", "comment", "partition_attrs", "partition_strategy", "partition_exprs"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(extensionsQuery, "$1, $2"))).
+		WithArgs("test", "public").
+		WillReturnRows(sqlmock.NewRows(extColumns))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(typesQuery, "$1, $2"))).
+		WithArgs("test", "public").
+		WillReturnRows(sqlmock.NewRows(typeColumns))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(sequencesQuery, "$1, $2"))).
+		WithArgs("test", "public").
+		WillReturnRows(sqlmock.NewRows(seqColumns))
 	realm, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{})
 	require.NoError(t, err)
 	require.EqualValues(t, func() *schema.Realm {
@@ -548,6 +746,15 @@ public
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "$1, $2"))).
 		WithArgs("test", "public").
 		WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name", "comment", "partition_attrs", "partition_strategy", "partition_exprs"}))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(extensionsQuery, "$1, $2"))).
+		WithArgs("test", "public").
+		WillReturnRows(sqlmock.NewRows(extColumns))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(typesQuery, "$1, $2"))).
+		WithArgs("test", "public").
+		WillReturnRows(sqlmock.NewRows(typeColumns))
+	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(sequencesQuery, "$1, $2"))).
+		WithArgs("test", "public").
+		WillReturnRows(sqlmock.NewRows(seqColumns))
 	realm, err = drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Schemas: []string{"test", "public"}})
 	require.NoError(t, err)
 	require.EqualValues(t, func() *schema.Realm {
@@ -585,6 +792,15 @@ public
 	m.ExpectQuery(sqltest.Escape(fmt.Sprintf(tablesQuery, "$1"))).
 		WithArgs("test").
 		WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name", "comment", "partition_attrs", "partition_strategy", "partition_exprs"}))
+	m.ExpectQuery(queryExtensions).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows(extColumns))
+	m.ExpectQuery(queryTypes).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows(typeColumns))
+	m.ExpectQuery(querySequences).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows(seqColumns))
 	realm, err = drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Schemas: []string{"test"}})
 	require.NoError(t, err)
 	require.EqualValues(t, func() *schema.Realm {
@@ -609,6 +825,211 @@ public
 	}(), realm)
 }
 
+func TestDriver_InspectExtensions(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= $1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ schema_name
+-------------
+ public
+`))
+	mk.ExpectQuery(queryExtensions).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ schema_name | extension_name | extension_version
+-------------+----------------+-------------------
+ public      | citext         | 1.6
+ public      | pg_trgm        | 1.5
+`))
+	mk.noTypes()
+	mk.noSequences()
+	s, err := drv.InspectSchema(context.Background(), "public", &schema.InspectOptions{Mode: schema.InspectSchemas | schema.InspectObjects})
+	require.NoError(t, err)
+	require.Equal(t, []schema.Object{
+		&Extension{Name: "citext", Schema: s, Version: "1.6"},
+		&Extension{Name: "pg_trgm", Schema: s, Version: "1.5"},
+	}, s.Objects)
+}
+
+func TestDriver_InspectTypes(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= $1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ schema_name
+-------------
+ public
+`))
+	mk.tableExists("public", "users", true)
+	mk.ExpectQuery(queryColumns).
+		WithArgs("public", "users").
+		WillReturnRows(sqltest.Rows(`
+table_name | column_name | data_type    | formatted | is_nullable | column_default | character_maximum_length | numeric_precision | datetime_precision | numeric_scale | interval_type | character_set_name | collation_name | is_identity | identity_start | identity_increment | identity_last | identity_generation | generation_expression | comment | typtype |  oid
+-----------+-------------+--------------+-----------+-------------+----------------+--------------------------+-------------------+--------------------+---------------+---------------+--------------------+----------------+-------------+----------------+--------------------+---------------+---------------------+-----------------------+---------+---------+-------
+users      | email       | text         | email     | NO          |                |                          |                   |                    |               |               |                    |                | NO          |                |                    |               |                     |                       |         | b       |    25
+users      | address     | USER-DEFINED | address   | YES         |                |                          |                   |                    |               |               |                    |                | NO          |                |                    |               |                     |                       |         | c       | 16400
+`))
+	mk.noIndexes()
+	mk.noFKs()
+	mk.noChecks()
+	mk.noPolicies()
+	mk.noExtensions()
+	mk.ExpectQuery(queryTypes).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+  oid  | schema_name | type_name | typtype | base_type | typnotnull | typdefault
+-------+-------------+-----------+---------+-----------+------------+------------
+ 16390 | public      | email     | d       | text      | t          | 'a@b'::text
+ 16400 | public      | address   | c       |           | f          |
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(domainChecksQuery, "$1"))).
+		WithArgs(16390).
+		WillReturnRows(sqltest.Rows(`
+ contypid |   conname   |       expression
+----------+-------------+------------------------
+    16390 | email_check | (VALUE ~~ '%@%'::text)
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(compositeFieldsQuery, "$1"))).
+		WithArgs(16400).
+		WillReturnRows(sqltest.Rows(`
+  oid  | attname |      format_type
+-------+---------+-----------------------
+ 16400 | street  | text
+ 16400 | zip     | character varying(10)
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(typeColumnsQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ table_schema | table_name | column_name | type_id
+--------------+------------+-------------+---------
+ public       | users      | email       |   16390
+ public       | users      | address     |   16400
+`))
+	mk.noSequences()
+	s, err := drv.InspectSchema(context.Background(), "public", nil)
+	require.NoError(t, err)
+	email := &DomainType{
+		T:       "email",
+		Schema:  s,
+		Base:    &schema.StringType{T: "text"},
+		Default: &schema.Literal{V: "'a@b'"},
+		Checks:  []*schema.Check{{Name: "email_check", Expr: "(VALUE ~~ '%@%'::text)"}},
+	}
+	address := &CompositeType{
+		T:      "address",
+		Schema: s,
+		Fields: []*CompositeField{
+			{Name: "street", Type: &schema.StringType{T: "text"}},
+			{Name: "zip", Type: &schema.StringType{T: "character varying", Size: 10}},
+		},
+	}
+	require.Equal(t, []schema.Object{email, address}, s.Objects)
+	users, ok := s.Table("users")
+	require.True(t, ok)
+	require.Same(t, s.Objects[0], users.Columns[0].Type.Type)
+	require.Same(t, s.Objects[1], users.Columns[1].Type.Type)
+}
+
+func TestDriver_InspectSequences(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(schemasQueryArgs, "= $1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ schema_name
+-------------
+ public
+`))
+	mk.ExpectQuery(queryExtensions).
+		WithArgs("public").
+		WillReturnRows(sqlmock.NewRows(extColumns))
+	mk.ExpectQuery(queryTypes).
+		WithArgs("public").
+		WillReturnRows(sqlmock.NewRows(typeColumns))
+	mk.ExpectQuery(querySequences).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ schema_name | sequence_name | data_type | start_value | min_value |      max_value      | increment_by | cycle | last_value | owner_schema | owner_table | owner_column
+-------------+---------------+-----------+-------------+-----------+---------------------+--------------+-------+------------+--------------+-------------+--------------
+ public      | s1            | bigint    |           1 |         1 | 9223372036854775807 |            1 | f     |         10 |              |             |
+ public      | s2            | integer   |         100 |        10 |                1000 |            5 | t     |            | public       | users       | id
+`))
+	s, err := drv.InspectSchema(context.Background(), "public", &schema.InspectOptions{Mode: schema.InspectSchemas | schema.InspectObjects})
+	require.NoError(t, err)
+	require.Len(t, s.Objects, 2)
+	owner := s.Objects[1].(*Sequence).OwnedBy
+	require.Equal(t, "users", owner.T.Name)
+	require.Equal(t, "public", owner.T.Schema.Name)
+	require.Equal(t, "id", owner.C.Name)
+	require.Equal(t, []schema.Object{
+		&Sequence{Name: "s1", Schema: s, Type: "bigint", Start: 1, Min: 1, Max: 9223372036854775807, Increment: 1, Last: 10},
+		&Sequence{Name: "s2", Schema: s, Type: "integer", Start: 100, Min: 10, Max: 1000, Increment: 5, Cycle: true, OwnedBy: owner},
+	}, s.Objects)
+}
+
+func TestDriver_InspectRoles(t *testing.T) {
+	db, m, err := sqlmock.New()
+	require.NoError(t, err)
+	mk := mock{m}
+	mk.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	mk.ExpectQuery(sqltest.Escape(schemasQuery)).
+		WillReturnRows(sqltest.Rows(`
+ schema_name
+-------------
+ public
+`))
+	mk.ExpectQuery(sqltest.Escape(rolesQuery)).
+		WillReturnRows(sqltest.Rows(`
+ rolname | rolcanlogin | rolsuper | rolcreatedb | rolcreaterole
+---------+-------------+----------+-------------+---------------
+ admin   | t           | f        | t           | t
+ app     | t           | f        | f           | f
+ reader  | f           | f        | f           | f
+`))
+	mk.ExpectQuery(sqltest.Escape(fmt.Sprintf(permissionsQuery, "$1"))).
+		WithArgs("public").
+		WillReturnRows(sqltest.Rows(`
+ schema_name | table_name | column_name | grantee  | privilege_type | is_grantable
+-------------+------------+-------------+----------+----------------+--------------
+ public      | NULL       | NULL        | admin    | CREATE         | t
+ public      | NULL       | NULL        | admin    | USAGE          | t
+ public      | NULL       | NULL        | app      | USAGE          | f
+ public      | NULL       | NULL        | postgres | USAGE          | f
+ public      | users      | NULL        | reader   | SELECT         | f
+`))
+	r, err := drv.InspectRealm(context.Background(), &schema.InspectRealmOption{Mode: schema.InspectSchemas | schema.InspectRoles})
+	require.NoError(t, err)
+	var (
+		s      = r.Schemas[0]
+		admin  = &Role{Name: "admin", Login: true, CreateDB: true, CreateRole: true}
+		app    = &Role{Name: "app", Login: true}
+		reader = &Role{Name: "reader"}
+	)
+	// Unknown roles and tables that were not inspected are skipped.
+	require.Equal(t, []schema.Object{
+		admin, app, reader,
+		&Permission{Role: admin, Schema: s, Privileges: []string{"CREATE", "USAGE"}, Grantable: true},
+		&Permission{Role: app, Schema: s, Privileges: []string{"USAGE"}},
+	}, r.Objects)
+}
+
 func TestInspectMode_InspectRealm(t *testing.T) {
 	db, m, err := sqlmock.New()
 	require.NoError(t, err)
@@ -685,7 +1106,40 @@ func (m mock) noFKs() {
 		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "table_name", "column_name", "referenced_table_name", "referenced_column_name", "referenced_table_schema", "update_rule", "delete_rule"}))
 }
 
+func (m mock) noConAttrs() {
+	m.ExpectQuery(queryConAttrs).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "constraint_name", "constraint_type", "validated", "deferrable", "deferred"}))
+}
+
 func (m mock) noChecks() {
 	m.ExpectQuery(queryChecks).
 		WillReturnRows(sqlmock.NewRows([]string{"table_name", "constraint_name", "expression", "column_name", "column_indexes"}))
 }
+
+var policyColumns = []string{"table_schema", "table_name", "row_security", "force_row_security", "policy_name", "permissive", "command", "roles", "using_expr", "check_expr"}
+
+func (m mock) noPolicies() {
+	m.ExpectQuery(queryPolicies).
+		WillReturnRows(sqlmock.NewRows(policyColumns))
+}
+
+var seqColumns = []string{"schema_name", "sequence_name", "data_type", "start_value", "min_value", "max_value", "increment_by", "cycle", "last_value", "owner_schema", "owner_table", "owner_column"}
+
+var extColumns = []string{"schema_name", "extension_name", "extension_version"}
+
+func (m mock) noExtensions() {
+	m.ExpectQuery(queryExtensions).
+		WillReturnRows(sqlmock.NewRows(extColumns))
+}
+
+var typeColumns = []string{"oid", "schema_name", "type_name", "typtype", "base_type", "typnotnull", "typdefault"}
+
+func (m mock) noTypes() {
+	m.ExpectQuery(queryTypes).
+		WillReturnRows(sqlmock.NewRows(typeColumns))
+}
+
+func (m mock) noSequences() {
+	m.ExpectQuery(querySequences).
+		WillReturnRows(sqlmock.NewRows(seqColumns))
+}
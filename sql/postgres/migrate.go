@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -19,8 +20,31 @@ import (
 // A planApply provides migration capabilities for schema elements.
 type planApply struct{ conn }
 
+// ConcurrentIndex is a migrate.PlanOption for creating and dropping the indexes of
+// existing tables using the CONCURRENTLY option, without blocking writes on them.
+// Note, these changes cannot be executed inside a transaction block, and the
+// returned plan is marked as non-transactional.
+type ConcurrentIndex struct {
+	migrate.PlanOption
+}
+
+// ValidateConstraints is a migrate.PlanOption for adding CHECK and FOREIGN KEY constraints
+// to existing tables in two steps. First, the constraints are added as NOT VALID, which skips
+// the scan of the existing rows. Then, they are validated using VALIDATE CONSTRAINT, which
+// does not block writes on the table. The validation changes are placed at the end of the
+// plan, and if SeparateFile is set, they are written to a separate migration file.
+//
+// Note, adding a constraint as NOT VALID takes an ACCESS EXCLUSIVE lock on the table that
+// is held until the transaction commits. Therefore, when migration files are executed in
+// transactions, SeparateFile should be set to validate the constraints after the lock is
+// released. Unnamed constraints cannot be validated and are added as usual.
+type ValidateConstraints struct {
+	migrate.PlanOption
+	SeparateFile bool
+}
+
 // PlanChanges returns a migration plan for the given schema changes.
-func (p *planApply) PlanChanges(ctx context.Context, name string, changes []schema.Change) (*migrate.Plan, error) {
+func (p *planApply) PlanChanges(ctx context.Context, name string, changes []schema.Change, opts ...migrate.PlanOption) (*migrate.Plan, error) {
 	s := &state{
 		conn: p.conn,
 		Plan: migrate.Plan{
@@ -29,13 +53,27 @@ func (p *planApply) PlanChanges(ctx context.Context, name string, changes []sche
 			Transactional: true,
 		},
 	}
+	for _, o := range opts {
+		switch o := o.(type) {
+		case *ConcurrentIndex, ConcurrentIndex:
+			s.concurrent = true
+		case *ValidateConstraints:
+			s.validate = o
+		case ValidateConstraints:
+			s.validate = &o
+		}
+	}
 	if err := s.plan(ctx, changes); err != nil {
 		return nil, err
 	}
+	s.append(s.validation...)
 	for _, c := range s.Changes {
 		if c.Reverse == "" {
 			s.Reversible = false
 		}
+		if c.NonTransactional {
+			s.Transactional = false
+		}
 	}
 	return &s.Plan, nil
 }
@@ -53,16 +91,29 @@ func (p *planApply) ApplyChanges(ctx context.Context, changes []schema.Change) e
 type state struct {
 	conn
 	migrate.Plan
+	// Create and drop indexes of existing tables concurrently.
+	concurrent bool
+	// Add constraints as NOT VALID, and validate them
+	// at the end of the plan (see ValidateConstraints).
+	validate   *ValidateConstraints
+	validation []*migrate.Change
 }
 
 // Exec executes the changes on the database. An error is returned
 // if one of the operations fail, or a change is not supported.
 func (s *state) plan(ctx context.Context, changes []schema.Change) error {
 	planned := s.topLevel(changes)
-	planned, err := sqlx.DetachCycles(planned)
+	planned, deferred, err := s.objects(planned)
 	if err != nil {
 		return err
 	}
+	// Partitions are created after their parent tables, and dropped before them.
+	// The changes are sorted before detaching the cycles, as in case of cycles,
+	// the relative order of the created and dropped tables is preserved.
+	sortPartitions(planned)
+	if planned, err = sqlx.DetachCyclesWith(planned, partitionParent); err != nil {
+		return err
+	}
 	for _, c := range planned {
 		switch c := c.(type) {
 		case *schema.AddTable:
@@ -80,6 +131,7 @@ func (s *state) plan(ctx context.Context, changes []schema.Change) error {
 			return err
 		}
 	}
+	s.append(deferred...)
 	return nil
 }
 
@@ -118,6 +170,583 @@ func (s *state) topLevel(changes []schema.Change) []schema.Change {
 	return planned
 }
 
+// objects plans the creation and modification of schema objects (e.g. sequences) before the
+// table changes, and returns the object changes that should be planned after them. For example,
+// the ownership of a sequence is set only after its table exists, and sequences are dropped only
+// after the table columns that use them were modified or dropped. Extensions are created first
+// and dropped last, as other objects and tables may depend on the types or functions they provide.
+// Similarly, domains are created before composite types, as the latter may use them. Roles
+// are created first and dropped last, and privileges are granted (or revoked) after the tables
+// they are granted on were created.
+func (s *state) objects(changes []schema.Change) ([]schema.Change, []*migrate.Change, error) {
+	changes, grants, revokes, err := sqlx.PlanPermissions(changes, s.privileges)
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		roles, exts, domains, composites, pre   []*migrate.Change
+		deferred, dropTypes, dropDomains, drops []*migrate.Change
+		dropRoles                               []*migrate.Change
+		planned                                 = make([]schema.Change, 0, len(changes))
+	)
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddObject:
+			switch o := c.O.(type) {
+			case *Role:
+				roles = append(roles, &migrate.Change{
+					Cmd:     s.createRole(o),
+					Source:  c,
+					Comment: fmt.Sprintf("create %q role", o.Name),
+					Reverse: Build("DROP ROLE").Ident(o.Name).String(),
+				})
+			case *Extension:
+				exts = append(exts, &migrate.Change{
+					Cmd:     s.createExtension(o),
+					Source:  c,
+					Comment: fmt.Sprintf("create %q extension", o.Name),
+					Reverse: Build("DROP EXTENSION").Ident(o.Name).String(),
+				})
+			case *DomainType:
+				cmd, err := s.createDomain(o)
+				if err != nil {
+					return nil, nil, err
+				}
+				domains = append(domains, &migrate.Change{
+					Cmd:     cmd,
+					Source:  c,
+					Comment: fmt.Sprintf("create %q domain", o.T),
+					Reverse: Build("DROP DOMAIN").Table(typeTable(o.T, o.Schema)).String(),
+				})
+			case *CompositeType:
+				cmd, err := s.createComposite(o)
+				if err != nil {
+					return nil, nil, err
+				}
+				composites = append(composites, &migrate.Change{
+					Cmd:     cmd,
+					Source:  c,
+					Comment: fmt.Sprintf("create %q composite type", o.T),
+					Reverse: Build("DROP TYPE").Table(typeTable(o.T, o.Schema)).String(),
+				})
+			case *Sequence:
+				pre = append(pre, &migrate.Change{
+					Cmd:     s.createSequence(o),
+					Source:  c,
+					Comment: fmt.Sprintf("create %q sequence", o.Name),
+					Reverse: Build("DROP SEQUENCE").Table(seqTable(o)).String(),
+				})
+				if o.OwnedBy != nil {
+					deferred = append(deferred, &migrate.Change{
+						Cmd:     s.seqOwner(o, o.OwnedBy),
+						Source:  c,
+						Comment: fmt.Sprintf("set %q sequence owner", o.Name),
+						Reverse: s.seqOwner(o, nil),
+					})
+				}
+			default:
+				return nil, nil, fmt.Errorf("unsupported object %T", c.O)
+			}
+		case *schema.DropObject:
+			switch o := c.O.(type) {
+			case *Role:
+				dropRoles = append(dropRoles, &migrate.Change{
+					Cmd:     Build("DROP ROLE").Ident(o.Name).String(),
+					Source:  c,
+					Comment: fmt.Sprintf("drop %q role", o.Name),
+					Reverse: s.createRole(o),
+				})
+			case *Extension:
+				drops = append(drops, &migrate.Change{
+					Cmd:     Build("DROP EXTENSION").Ident(o.Name).String(),
+					Source:  c,
+					Comment: fmt.Sprintf("drop %q extension", o.Name),
+					Reverse: s.createExtension(o),
+				})
+			case *DomainType:
+				rev, err := s.createDomain(o)
+				if err != nil {
+					return nil, nil, err
+				}
+				dropDomains = append(dropDomains, &migrate.Change{
+					Cmd:     Build("DROP DOMAIN").Table(typeTable(o.T, o.Schema)).String(),
+					Source:  c,
+					Comment: fmt.Sprintf("drop %q domain", o.T),
+					Reverse: rev,
+				})
+			case *CompositeType:
+				rev, err := s.createComposite(o)
+				if err != nil {
+					return nil, nil, err
+				}
+				dropTypes = append(dropTypes, &migrate.Change{
+					Cmd:     Build("DROP TYPE").Table(typeTable(o.T, o.Schema)).String(),
+					Source:  c,
+					Comment: fmt.Sprintf("drop %q composite type", o.T),
+					Reverse: rev,
+				})
+			case *Sequence:
+				deferred = append(deferred, &migrate.Change{
+					Cmd:     Build("DROP SEQUENCE").Table(seqTable(o)).String(),
+					Source:  c,
+					Comment: fmt.Sprintf("drop %q sequence", o.Name),
+					Reverse: s.createSequence(o, o.OwnedBy),
+				})
+			default:
+				return nil, nil, fmt.Errorf("unsupported object %T", c.O)
+			}
+		case *schema.ModifyObject:
+			switch from := c.From.(type) {
+			case *Role:
+				to, ok := c.To.(*Role)
+				if !ok {
+					return nil, nil, fmt.Errorf("unsupported object modification %T -> %T", c.From, c.To)
+				}
+				roles = append(roles, &migrate.Change{
+					Cmd:     Build("ALTER ROLE").Ident(to.Name).P(roleOptions(from, to)...).String(),
+					Source:  c,
+					Comment: fmt.Sprintf("modify %q role", to.Name),
+					Reverse: Build("ALTER ROLE").Ident(from.Name).P(roleOptions(to, from)...).String(),
+				})
+			case *Extension:
+				to, ok := c.To.(*Extension)
+				if !ok {
+					return nil, nil, fmt.Errorf("unsupported object modification %T -> %T", c.From, c.To)
+				}
+				// Without a known version to return to, the update is irreversible.
+				var rev string
+				if from.Version != "" {
+					rev = Build("ALTER EXTENSION").Ident(from.Name).P("UPDATE TO", quote(from.Version)).String()
+				}
+				exts = append(exts, &migrate.Change{
+					Cmd:     Build("ALTER EXTENSION").Ident(to.Name).P("UPDATE TO", quote(to.Version)).String(),
+					Source:  c,
+					Comment: fmt.Sprintf("update %q extension", to.Name),
+					Reverse: rev,
+				})
+			case *DomainType:
+				to, ok := c.To.(*DomainType)
+				if !ok {
+					return nil, nil, fmt.Errorf("unsupported object modification %T -> %T", c.From, c.To)
+				}
+				alter, err := s.alterDomain(from, to)
+				if err != nil {
+					return nil, nil, err
+				}
+				for _, a := range alter {
+					a.Source = c
+				}
+				domains = append(domains, alter...)
+			case *CompositeType:
+				to, ok := c.To.(*CompositeType)
+				if !ok {
+					return nil, nil, fmt.Errorf("unsupported object modification %T -> %T", c.From, c.To)
+				}
+				cmd, err := s.alterComposite(from, to)
+				if err != nil {
+					return nil, nil, err
+				}
+				rev, err := s.alterComposite(to, from)
+				if err != nil {
+					return nil, nil, err
+				}
+				if cmd != "" {
+					composites = append(composites, &migrate.Change{
+						Cmd:     cmd,
+						Source:  c,
+						Comment: fmt.Sprintf("modify %q composite type", to.T),
+						Reverse: rev,
+					})
+				}
+			case *Sequence:
+				to, ok := c.To.(*Sequence)
+				if !ok {
+					return nil, nil, fmt.Errorf("unsupported object modification %T -> %T", c.From, c.To)
+				}
+				var (
+					s1, s2   = sequence(from), sequence(to)
+					cmd, rev string
+					changed  = ownerChanged(s1.OwnedBy, s2.OwnedBy)
+				)
+				// Ownership is removed before the table changes, as dropping
+				// its owner column drops the sequence as well. The new owner
+				// is set after its table (or column) was created.
+				if changed && s1.OwnedBy != nil {
+					cmd, rev = s.alterSequence(s1, s2, nil), s.alterSequence(s2, s1, s1.OwnedBy)
+				} else {
+					cmd, rev = s.alterSequence(s1, s2), s.alterSequence(s2, s1)
+				}
+				if cmd != "" {
+					pre = append(pre, &migrate.Change{
+						Cmd:     cmd,
+						Source:  c,
+						Comment: fmt.Sprintf("modify %q sequence", to.Name),
+						Reverse: rev,
+					})
+				}
+				if changed && s2.OwnedBy != nil {
+					deferred = append(deferred, &migrate.Change{
+						Cmd:     s.seqOwner(to, s2.OwnedBy),
+						Source:  c,
+						Comment: fmt.Sprintf("set %q sequence owner", to.Name),
+						Reverse: s.seqOwner(to, nil),
+					})
+				}
+			default:
+				return nil, nil, fmt.Errorf("unsupported object modification %T -> %T", c.From, c.To)
+			}
+		default:
+			planned = append(planned, c)
+		}
+	}
+	s.append(roles...)
+	s.append(exts...)
+	s.append(domains...)
+	s.append(composites...)
+	s.append(pre...)
+	deferred = append(deferred, dropTypes...)
+	deferred = append(deferred, dropDomains...)
+	deferred = append(deferred, revokes...)
+	deferred = append(deferred, grants...)
+	deferred = append(deferred, drops...)
+	return planned, append(deferred, dropRoles...), nil
+}
+
+// createRole returns the CREATE ROLE statement of the given role.
+func (s *state) createRole(r *Role) string {
+	return Build("CREATE ROLE").Ident(r.Name).P(roleOptions(&Role{}, r)...).String()
+}
+
+// roleOptions returns the role options that need to be set in order
+// to move the role from one state to the other (e.g. LOGIN, NOCREATEDB).
+func roleOptions(from, to *Role) []string {
+	var opts []string
+	for _, o := range []struct {
+		name   string
+		v1, v2 bool
+	}{
+		{name: "LOGIN", v1: from.Login, v2: to.Login},
+		{name: "SUPERUSER", v1: from.Superuser, v2: to.Superuser},
+		{name: "CREATEDB", v1: from.CreateDB, v2: to.CreateDB},
+		{name: "CREATEROLE", v1: from.CreateRole, v2: to.CreateRole},
+	} {
+		switch {
+		case o.v1 == o.v2:
+		case o.v2:
+			opts = append(opts, o.name)
+		default:
+			opts = append(opts, "NO"+o.name)
+		}
+	}
+	return opts
+}
+
+// privileges returns the sqlx.Privileges of the given permission object.
+func (s *state) privileges(o schema.Object) (*sqlx.Privileges, bool) {
+	p, ok := o.(*Permission)
+	if !ok {
+		return nil, false
+	}
+	return &sqlx.Privileges{
+		Role:      p.Role.Name,
+		Target:    sqlx.PrivilegeTargetName(p.Schema, p.Table, p.Column),
+		List:      p.Privileges,
+		Grantable: p.Grantable,
+		Grant: func(privileges []string) string {
+			return s.grant(p, privileges)
+		},
+		// Unlike MySQL, revoking a privilege revokes its grant option as well.
+		Revoke: func(privileges []string, _ bool) string {
+			return s.revoke(p, privileges)
+		},
+	}, true
+}
+
+// grant returns the GRANT statement for the given privileges of the permission.
+func (s *state) grant(p *Permission, privileges []string) string {
+	b := privilegesOn(Build("GRANT"), p, privileges).P("TO").Ident(p.Role.Name)
+	if p.Grantable {
+		b.P("WITH GRANT OPTION")
+	}
+	return b.String()
+}
+
+// revoke returns the REVOKE statement for the given privileges of the permission.
+func (s *state) revoke(p *Permission, privileges []string) string {
+	return privilegesOn(Build("REVOKE"), p, privileges).P("FROM").Ident(p.Role.Name).String()
+}
+
+// privilegesOn writes the privileges list and the object they are granted on.
+func privilegesOn(b *sqlx.Builder, p *Permission, privileges []string) *sqlx.Builder {
+	b.MapComma(privileges, func(i int, b *sqlx.Builder) {
+		b.P(privileges[i])
+		if p.Column != nil {
+			b.Wrap(func(b *sqlx.Builder) {
+				b.Ident(p.Column.Name)
+			})
+		}
+	})
+	if p.Table != nil {
+		return b.P("ON TABLE").Table(p.Table)
+	}
+	return b.P("ON SCHEMA").Ident(p.Schema.Name)
+}
+
+// createDomain returns the CREATE DOMAIN statement of the given domain.
+func (s *state) createDomain(d *DomainType) (string, error) {
+	if d.Base == nil {
+		return "", fmt.Errorf("missing base type for domain %q", d.T)
+	}
+	t, err := FormatType(d.Base)
+	if err != nil {
+		return "", err
+	}
+	b := Build("CREATE DOMAIN").Table(typeTable(d.T, d.Schema)).P("AS", t)
+	if d.Default != nil {
+		s.columnDefault(b, domainColumn(d))
+	}
+	if !d.Null {
+		b.P("NOT NULL")
+	}
+	for _, c := range d.Checks {
+		check(b, c)
+	}
+	return b.String(), nil
+}
+
+// alterDomain returns the ALTER DOMAIN statements for migrating the domain from one state to the other.
+func (s *state) alterDomain(from, to *DomainType) ([]*migrate.Change, error) {
+	if baseChanged(from, to) {
+		return nil, fmt.Errorf("changing the base type of domain %q is not supported", to.T)
+	}
+	var (
+		changes []*migrate.Change
+		alter   = func() *sqlx.Builder { return Build("ALTER DOMAIN").Table(typeTable(to.T, to.Schema)) }
+		comment = fmt.Sprintf("modify %q domain", to.T)
+	)
+	if domainDefaultChanged(from, to) {
+		set := func(d *DomainType) string {
+			b := alter()
+			if d.Default == nil {
+				return b.P("DROP DEFAULT").String()
+			}
+			s.columnDefault(b.P("SET"), domainColumn(d))
+			return b.String()
+		}
+		changes = append(changes, &migrate.Change{Cmd: set(to), Reverse: set(from), Comment: comment})
+	}
+	if from.Null != to.Null {
+		set := func(d *DomainType) string {
+			if d.Null {
+				return alter().P("DROP NOT NULL").String()
+			}
+			return alter().P("SET NOT NULL").String()
+		}
+		changes = append(changes, &migrate.Change{Cmd: set(to), Reverse: set(from), Comment: comment})
+	}
+	addCheck := func(c *schema.Check) string {
+		b := alter().P("ADD")
+		check(b, c)
+		return b.String()
+	}
+	for _, c := range domainChecksDiff(from, to) {
+		changes = append(changes, &migrate.Change{
+			Cmd:     alter().P("DROP CONSTRAINT").Ident(c.Name).String(),
+			Reverse: addCheck(c),
+			Comment: comment,
+		})
+	}
+	for _, c := range domainChecksDiff(to, from) {
+		change := &migrate.Change{Cmd: addCheck(c), Comment: comment}
+		// Reverse operation is supported if
+		// the constraint name is not generated.
+		if c.Name != "" {
+			change.Reverse = alter().P("DROP CONSTRAINT").Ident(c.Name).String()
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// domainColumn returns a column that represents the domain definition. Used
+// for reusing the column formatting functions (e.g. default values).
+func domainColumn(d *DomainType) *schema.Column {
+	return &schema.Column{Type: &schema.ColumnType{Type: d.Base, Null: d.Null}, Default: d.Default}
+}
+
+// createComposite returns the CREATE TYPE statement of the given composite type.
+func (s *state) createComposite(c *CompositeType) (string, error) {
+	var (
+		err error
+		b   = Build("CREATE TYPE").Table(typeTable(c.T, c.Schema)).P("AS")
+	)
+	b.Wrap(func(b *sqlx.Builder) {
+		err = b.MapCommaErr(c.Fields, func(i int, b *sqlx.Builder) error {
+			t, err := FormatType(c.Fields[i].Type)
+			if err != nil {
+				return err
+			}
+			b.Ident(c.Fields[i].Name).P(t)
+			return nil
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// alterComposite returns the ALTER TYPE statement for migrating the fields of the
+// composite type from one state to the other, or an empty string if nothing changed.
+func (s *state) alterComposite(from, to *CompositeType) (string, error) {
+	var fields []func(b *sqlx.Builder) error
+	for _, f1 := range from.Fields {
+		if _, ok := compositeField(to, f1.Name); !ok {
+			f1 := f1
+			fields = append(fields, func(b *sqlx.Builder) error {
+				b.P("DROP ATTRIBUTE").Ident(f1.Name)
+				return nil
+			})
+		}
+	}
+	for _, f2 := range to.Fields {
+		f1, ok := compositeField(from, f2.Name)
+		if ok && !fieldChanged(f1, f2) {
+			continue
+		}
+		f2 := f2
+		fields = append(fields, func(b *sqlx.Builder) error {
+			t, err := FormatType(f2.Type)
+			if err != nil {
+				return err
+			}
+			if ok {
+				b.P("ALTER ATTRIBUTE").Ident(f2.Name).P("TYPE", t)
+			} else {
+				b.P("ADD ATTRIBUTE").Ident(f2.Name).P(t)
+			}
+			return nil
+		})
+	}
+	if len(fields) == 0 {
+		return "", nil
+	}
+	b := Build("ALTER TYPE").Table(typeTable(to.T, to.Schema))
+	if err := b.MapCommaErr(fields, func(i int, b *sqlx.Builder) error {
+		return fields[i](b)
+	}); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// typeTable returns a table that holds the type name and its schema for
+// building qualified type names. Similar to tables, types are qualified
+// only if their schema is known.
+func typeTable(name string, s *schema.Schema) *schema.Table {
+	return &schema.Table{Name: name, Schema: s}
+}
+
+// createExtension returns the CREATE EXTENSION statement of the given extension.
+func (s *state) createExtension(e *Extension) string {
+	b := Build("CREATE EXTENSION").Ident(e.Name)
+	if e.Schema != nil && e.Schema.Name != "" {
+		b.P("WITH SCHEMA").Ident(e.Schema.Name)
+	}
+	if e.Version != "" {
+		b.P("VERSION", quote(e.Version))
+	}
+	return b.String()
+}
+
+// createSequence returns the CREATE SEQUENCE statement of the given sequence.
+// Ownership is set only if owner is provided, as the owner table must exist.
+func (s *state) createSequence(seq *Sequence, owner ...*SequenceOwner) string {
+	seq = sequence(seq)
+	b := Build("CREATE SEQUENCE").Table(seqTable(seq))
+	if seq.Type != defaultSeqType {
+		b.P("AS", seq.Type)
+	}
+	if seq.Increment != defaultSeqIncrement {
+		b.P("INCREMENT BY", strconv.FormatInt(seq.Increment, 10))
+	}
+	// Options are compared to the defaults of the
+	// sequence type and its increment direction.
+	seqOptions(b, sequence(&Sequence{Type: seq.Type, Increment: seq.Increment}), seq)
+	if len(owner) > 0 && owner[0] != nil {
+		s.ownedBy(b, owner[0])
+	}
+	return b.String()
+}
+
+// alterSequence returns the ALTER SEQUENCE statement for migrating the sequence options
+// from one state to the other, or an empty string if there is nothing to change. The
+// OWNED BY clause is added only if owner is provided, and a nil owner removes it.
+func (s *state) alterSequence(from, to *Sequence, owner ...*SequenceOwner) string {
+	b := Build("ALTER SEQUENCE").Table(seqTable(to))
+	n := b.Len()
+	if from.Type != to.Type {
+		b.P("AS", to.Type)
+	}
+	if from.Increment != to.Increment {
+		b.P("INCREMENT BY", strconv.FormatInt(to.Increment, 10))
+	}
+	seqOptions(b, from, to)
+	if len(owner) > 0 {
+		s.ownedBy(b, owner[0])
+	}
+	if b.Len() == n {
+		return ""
+	}
+	return b.String()
+}
+
+// seqOwner returns the statement for setting the sequence owner, or removing it if owner is nil.
+func (s *state) seqOwner(seq *Sequence, owner *SequenceOwner) string {
+	b := Build("ALTER SEQUENCE").Table(seqTable(seq))
+	s.ownedBy(b, owner)
+	return b.String()
+}
+
+// ownedBy writes the OWNED BY clause of the given owner. A nil owner is written as NONE.
+func (*state) ownedBy(b *sqlx.Builder, owner *SequenceOwner) {
+	b.P("OWNED BY")
+	if owner == nil {
+		b.P("NONE")
+		return
+	}
+	b.Table(owner.T)
+	b.WriteByte('.')
+	b.Ident(owner.C.Name)
+}
+
+// seqOptions writes the MINVALUE, MAXVALUE, START and CYCLE
+// options of the sequence that differ from the base sequence.
+func seqOptions(b *sqlx.Builder, base, seq *Sequence) {
+	if base.Min != seq.Min {
+		b.P("MINVALUE", strconv.FormatInt(seq.Min, 10))
+	}
+	if base.Max != seq.Max {
+		b.P("MAXVALUE", strconv.FormatInt(seq.Max, 10))
+	}
+	if base.Start != seq.Start {
+		b.P("START WITH", strconv.FormatInt(seq.Start, 10))
+	}
+	if base.Cycle != seq.Cycle {
+		if seq.Cycle {
+			b.P("CYCLE")
+		} else {
+			b.P("NO CYCLE")
+		}
+	}
+}
+
+// seqTable returns a table representation of the sequence for
+// writing its (schema qualified) name using the sqlx.Builder.
+func seqTable(seq *Sequence) *schema.Table {
+	return &schema.Table{Name: seq.Name, Schema: seq.Schema}
+}
+
 // addTable builds and executes the query for creating a table in a schema.
 func (s *state) addTable(ctx context.Context, add *schema.AddTable) error {
 	// Create enum types before using them in the `CREATE TABLE` statement.
@@ -132,27 +761,36 @@ func (s *state) addTable(ctx context.Context, add *schema.AddTable) error {
 		b.P("IF NOT EXISTS")
 	}
 	b.Table(add.T)
-	b.Wrap(func(b *sqlx.Builder) {
-		b.MapComma(add.T.Columns, func(i int, b *sqlx.Builder) {
-			if err := s.column(b, add.T.Columns[i]); err != nil {
-				errs = append(errs, err.Error())
-			}
-		})
-		if pk := add.T.PrimaryKey; pk != nil {
-			b.Comma().P("PRIMARY KEY")
-			s.indexParts(b, pk.Parts)
-		}
-		if len(add.T.ForeignKeys) > 0 {
-			b.Comma()
-			s.fks(b, add.T.ForeignKeys...)
+	// Partitions inherit their columns, indexes and
+	// constraints from their parent (partitioned) table.
+	if p := (PartitionOf{}); sqlx.Has(add.T.Attrs, &p) {
+		if p.Parent == nil || p.Bound == "" {
+			return fmt.Errorf("create table %q: missing parent table or bound of partition", add.T.Name)
 		}
-		for _, attr := range add.T.Attrs {
-			if c, ok := attr.(*schema.Check); ok {
+		b.P("PARTITION OF").Table(p.Parent).P(p.Bound)
+	} else {
+		b.Wrap(func(b *sqlx.Builder) {
+			b.MapComma(add.T.Columns, func(i int, b *sqlx.Builder) {
+				if err := s.column(b, add.T.Columns[i]); err != nil {
+					errs = append(errs, err.Error())
+				}
+			})
+			if pk := add.T.PrimaryKey; pk != nil {
+				b.Comma().P("PRIMARY KEY")
+				s.indexParts(b, pk.Parts)
+			}
+			if len(add.T.ForeignKeys) > 0 {
 				b.Comma()
-				check(b, c)
+				s.fks(b, add.T.ForeignKeys...)
 			}
-		}
-	})
+			for _, attr := range add.T.Attrs {
+				if c, ok := attr.(*schema.Check); ok {
+					b.Comma()
+					check(b, c)
+				}
+			}
+		})
+	}
 	if p := (Partition{}); sqlx.Has(add.T.Attrs, &p) {
 		s, err := formatPartition(p)
 		if err != nil {
@@ -169,8 +807,13 @@ func (s *state) addTable(ctx context.Context, add *schema.AddTable) error {
 		Comment: fmt.Sprintf("create %q table", add.T.Name),
 		Reverse: Build("DROP TABLE").Table(add.T).String(),
 	})
-	s.addIndexes(add.T, add.T.Indexes...)
+	// Indexes of new tables are not created concurrently,
+	// as there are no writes to block on empty tables.
+	if err := s.addIndexes(add.T, false, add.T.Indexes...); err != nil {
+		return err
+	}
 	s.addComments(add.T)
+	s.addSecurity(add, add.T)
 	return nil
 }
 
@@ -196,6 +839,14 @@ func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) err
 		changes     []*migrate.Change
 	)
 	for _, change := range skipAutoChanges(modify.Changes) {
+		if pc, ok := partitionOfChanges(modify.T, change); ok {
+			changes = append(changes, pc...)
+			continue
+		}
+		if sc, ok := securityChanges(modify.T, change); ok {
+			changes = append(changes, sc...)
+			continue
+		}
 		switch change := change.(type) {
 		case *schema.AddAttr, *schema.ModifyAttr:
 			from, to, err := commentChange(change)
@@ -231,9 +882,14 @@ func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) err
 					continue
 				}
 			}
-			// Index modification requires rebuilding the index.
+			// Index modification requires rebuilding the index. Similar
+			// to DropIndex, UNIQUE constraints are dropped using ALTER TABLE.
+			if isUniqueConstraint(change.From) {
+				alter = append(alter, &schema.DropIndex{I: change.From})
+			} else {
+				dropI = append(dropI, change.From)
+			}
 			addI = append(addI, change.To)
-			dropI = append(dropI, change.From)
 		case *schema.RenameIndex:
 			changes = append(changes, &migrate.Change{
 				Source:  change,
@@ -242,6 +898,12 @@ func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) err
 				Reverse: Build("ALTER INDEX").Ident(change.To.Name).P("RENAME TO").Ident(change.From.Name).String(),
 			})
 		case *schema.ModifyForeignKey:
+			// Validating a NOT VALID foreign key (or the other way
+			// around) is handled by the ALTER TABLE statement below.
+			if change.Change == schema.ChangeAttr {
+				alter = append(alter, change)
+				continue
+			}
 			// Foreign-key modification is translated into 2 steps.
 			// Dropping the current foreign key and creating a new one.
 			alter = append(alter, &schema.DropForeignKey{
@@ -304,17 +966,274 @@ func (s *state) modifyTable(ctx context.Context, modify *schema.ModifyTable) err
 			alter = append(alter, change)
 		}
 	}
-	s.dropIndexes(modify.T, dropI...)
+	if err := s.dropIndexes(modify.T, dropI...); err != nil {
+		return err
+	}
 	if len(alter) > 0 {
 		if err := s.alterTable(modify.T, alter); err != nil {
 			return err
 		}
 	}
-	s.addIndexes(modify.T, addI...)
+	if err := s.addIndexes(modify.T, true, addI...); err != nil {
+		return err
+	}
 	s.append(changes...)
 	return nil
 }
 
+// partitionOfChanges returns the changes for attaching or detaching the table to or
+// from its parent (partitioned) table, and reports if the change was a PartitionOf change.
+func partitionOfChanges(t *schema.Table, change schema.Change) ([]*migrate.Change, bool) {
+	attach := func(p *PartitionOf) *migrate.Change {
+		return &migrate.Change{
+			Source:  change,
+			Comment: fmt.Sprintf("attach %q to partitioned table %q", t.Name, p.Parent.Name),
+			Cmd:     Build("ALTER TABLE").Table(p.Parent).P("ATTACH PARTITION").Table(t).P(p.Bound).String(),
+			Reverse: Build("ALTER TABLE").Table(p.Parent).P("DETACH PARTITION").Table(t).String(),
+		}
+	}
+	detach := func(p *PartitionOf) *migrate.Change {
+		return &migrate.Change{
+			Source:  change,
+			Comment: fmt.Sprintf("detach %q from partitioned table %q", t.Name, p.Parent.Name),
+			Cmd:     Build("ALTER TABLE").Table(p.Parent).P("DETACH PARTITION").Table(t).String(),
+			Reverse: Build("ALTER TABLE").Table(p.Parent).P("ATTACH PARTITION").Table(t).P(p.Bound).String(),
+		}
+	}
+	switch change := change.(type) {
+	case *schema.AddAttr:
+		if p, ok := change.A.(*PartitionOf); ok {
+			return []*migrate.Change{attach(p)}, true
+		}
+	case *schema.DropAttr:
+		if p, ok := change.A.(*PartitionOf); ok {
+			return []*migrate.Change{detach(p)}, true
+		}
+	case *schema.ModifyAttr:
+		from, ok1 := change.From.(*PartitionOf)
+		to, ok2 := change.To.(*PartitionOf)
+		if ok1 && ok2 {
+			return []*migrate.Change{detach(from), attach(to)}, true
+		}
+	}
+	return nil, false
+}
+
+// addSecurity enables the row-level security of a newly created table, and creates its policies.
+func (s *state) addSecurity(source schema.Change, t *schema.Table) {
+	if rs := (RowSecurity{}); sqlx.Has(t.Attrs, &rs) {
+		s.append(rowSecurity(source, t, &RowSecurity{}, &rs)...)
+	}
+	for _, p := range policies(t.Attrs) {
+		s.append(createPolicy(source, t, p))
+	}
+}
+
+// securityChanges returns the changes for the row-level security settings or the policies
+// of the table, and reports if the change was a row-level security change.
+func securityChanges(t *schema.Table, change schema.Change) ([]*migrate.Change, bool) {
+	switch change := change.(type) {
+	case *schema.AddAttr:
+		switch a := change.A.(type) {
+		case *RowSecurity:
+			return rowSecurity(change, t, &RowSecurity{}, a), true
+		case *Policy:
+			return []*migrate.Change{createPolicy(change, t, a)}, true
+		}
+	case *schema.DropAttr:
+		switch a := change.A.(type) {
+		case *RowSecurity:
+			return rowSecurity(change, t, a, &RowSecurity{}), true
+		case *Policy:
+			return []*migrate.Change{dropPolicy(change, t, a)}, true
+		}
+	case *schema.ModifyAttr:
+		switch from := change.From.(type) {
+		case *RowSecurity:
+			if to, ok := change.To.(*RowSecurity); ok {
+				return rowSecurity(change, t, from, to), true
+			}
+		case *Policy:
+			if to, ok := change.To.(*Policy); ok {
+				return alterPolicy(change, t, from, to), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// rowSecurity returns the changes for moving the row-level security settings of the table.
+func rowSecurity(source schema.Change, t *schema.Table, from, to *RowSecurity) []*migrate.Change {
+	var changes []*migrate.Change
+	if from.Enabled != to.Enabled {
+		cmd, rev, op := "ENABLE", "DISABLE", "enable"
+		if !to.Enabled {
+			cmd, rev, op = rev, cmd, "disable"
+		}
+		changes = append(changes, &migrate.Change{
+			Source:  source,
+			Comment: fmt.Sprintf("%s row-level security on %q table", op, t.Name),
+			Cmd:     Build("ALTER TABLE").Table(t).P(cmd, "ROW LEVEL SECURITY").String(),
+			Reverse: Build("ALTER TABLE").Table(t).P(rev, "ROW LEVEL SECURITY").String(),
+		})
+	}
+	if from.Forced != to.Forced {
+		cmd, rev, op := "FORCE", "NO FORCE", "force"
+		if !to.Forced {
+			cmd, rev, op = rev, cmd, "unforce"
+		}
+		changes = append(changes, &migrate.Change{
+			Source:  source,
+			Comment: fmt.Sprintf("%s row-level security on %q table", op, t.Name),
+			Cmd:     Build("ALTER TABLE").Table(t).P(cmd, "ROW LEVEL SECURITY").String(),
+			Reverse: Build("ALTER TABLE").Table(t).P(rev, "ROW LEVEL SECURITY").String(),
+		})
+	}
+	return changes
+}
+
+// createPolicy returns the change for creating the policy on the table.
+func createPolicy(source schema.Change, t *schema.Table, p *Policy) *migrate.Change {
+	return &migrate.Change{
+		Source:  source,
+		Comment: fmt.Sprintf("create policy %q on %q table", p.Name, t.Name),
+		Cmd:     policyCmd(t, p),
+		Reverse: Build("DROP POLICY").Ident(p.Name).P("ON").Table(t).String(),
+	}
+}
+
+// dropPolicy returns the change for dropping the policy from the table.
+func dropPolicy(source schema.Change, t *schema.Table, p *Policy) *migrate.Change {
+	return &migrate.Change{
+		Source:  source,
+		Comment: fmt.Sprintf("drop policy %q from %q table", p.Name, t.Name),
+		Cmd:     Build("DROP POLICY").Ident(p.Name).P("ON").Table(t).String(),
+		Reverse: policyCmd(t, p),
+	}
+}
+
+// alterPolicy returns the changes for modifying the policy. The type and the command
+// of a policy cannot be altered, nor its expressions removed. In this case, the policy
+// is dropped and created again.
+func alterPolicy(source schema.Change, t *schema.Table, from, to *Policy) []*migrate.Change {
+	if policyAs(from) != policyAs(to) || policyFor(from) != policyFor(to) ||
+		from.Using != "" && to.Using == "" || from.Check != "" && to.Check == "" {
+		return []*migrate.Change{dropPolicy(source, t, from), createPolicy(source, t, to)}
+	}
+	alter := func(from, to *Policy) string {
+		b := Build("ALTER POLICY").Ident(to.Name).P("ON").Table(t)
+		if !sqlx.ValuesEqual(policyRoles(from), policyRoles(to)) {
+			policyRolesClause(b, to)
+		}
+		if policyExprChanged(from.Using, to.Using) {
+			b.P("USING", sqlx.MayWrap(to.Using))
+		}
+		if policyExprChanged(from.Check, to.Check) {
+			b.P("WITH CHECK", sqlx.MayWrap(to.Check))
+		}
+		return b.String()
+	}
+	c := &migrate.Change{
+		Source:  source,
+		Comment: fmt.Sprintf("modify policy %q on %q table", to.Name, t.Name),
+		Cmd:     alter(from, to),
+	}
+	// Added expressions cannot be removed using ALTER POLICY.
+	if (from.Using != "" || to.Using == "") && (from.Check != "" || to.Check == "") {
+		c.Reverse = alter(to, from)
+	}
+	return []*migrate.Change{c}
+}
+
+// policyCmd returns the CREATE POLICY statement of the policy.
+func policyCmd(t *schema.Table, p *Policy) string {
+	b := Build("CREATE POLICY").Ident(p.Name).P("ON").Table(t)
+	if as := policyAs(p); as != PolicyPermissive {
+		b.P("AS", as)
+	}
+	if cmd := policyFor(p); cmd != PolicyForAll {
+		b.P("FOR", cmd)
+	}
+	if len(policyRoles(p)) > 0 {
+		policyRolesClause(b, p)
+	}
+	if p.Using != "" {
+		b.P("USING", sqlx.MayWrap(p.Using))
+	}
+	if p.Check != "" {
+		b.P("WITH CHECK", sqlx.MayWrap(p.Check))
+	}
+	return b.String()
+}
+
+// policyRolesClause writes the TO clause of the policy.
+func policyRolesClause(b *sqlx.Builder, p *Policy) {
+	b.P("TO")
+	roles := policyRoles(p)
+	if len(roles) == 0 {
+		b.P("PUBLIC")
+		return
+	}
+	b.MapComma(roles, func(i int, b *sqlx.Builder) {
+		switch r := strings.ToUpper(roles[i]); r {
+		case "CURRENT_ROLE", "CURRENT_USER", "SESSION_USER":
+			b.P(r)
+		default:
+			b.Ident(roles[i])
+		}
+	})
+}
+
+// sortPartitions sorts the created and dropped tables such that partitions are created after
+// their parent tables, and dropped before them. Only the order of the created and dropped tables
+// is changed, and other changes (e.g. foreign keys added to existing tables) keep their positions.
+func sortPartitions(changes []schema.Change) {
+	depth := func(t *schema.Table) (d int) {
+		for {
+			p := &PartitionOf{}
+			if !sqlx.Has(t.Attrs, p) || p.Parent == nil {
+				return d
+			}
+			d, t = d+1, p.Parent
+		}
+	}
+	var (
+		idx    []int
+		tables []schema.Change
+	)
+	for i, c := range changes {
+		switch c.(type) {
+		case *schema.AddTable, *schema.DropTable:
+			idx = append(idx, i)
+			tables = append(tables, c)
+		}
+	}
+	key := func(c schema.Change) int {
+		switch c := c.(type) {
+		case *schema.AddTable:
+			return depth(c.T)
+		case *schema.DropTable:
+			return -depth(c.T)
+		}
+		return 0
+	}
+	sort.SliceStable(tables, func(i, j int) bool {
+		return key(tables[i]) < key(tables[j])
+	})
+	for i, c := range tables {
+		changes[idx[i]] = c
+	}
+}
+
+// partitionParent returns the parent table of a partition, if any. It is used to create
+// partitions after their parent tables, and drop them before, when the changes are sorted.
+func partitionParent(t *schema.Table) []*schema.Table {
+	if p := (PartitionOf{}); sqlx.Has(t.Attrs, &p) && p.Parent != nil {
+		return []*schema.Table{p.Parent}
+	}
+	return nil
+}
+
 // alterTable modifies the given table by executing on it a list of changes in one SQL statement.
 func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 	var (
@@ -349,6 +1268,7 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 			case *schema.AddIndex:
 				b.P("ADD CONSTRAINT").Ident(change.I.Name).P("UNIQUE")
 				s.indexParts(b, change.I.Parts)
+				deferrable(b, change.I.Attrs)
 				// Skip reversing this operation as it is the inverse of
 				// the operation below and should not be used besides this.
 			case *schema.DropIndex:
@@ -357,12 +1277,28 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 			case *schema.AddForeignKey:
 				b.P("ADD")
 				s.fks(b, change.F)
+				notValid(b, change.F.Attrs)
 				reverse = append(reverse, &schema.DropForeignKey{F: change.F})
 			case *schema.DropForeignKey:
 				b.P("DROP CONSTRAINT").Ident(change.F.Symbol)
 				reverse = append(reverse, &schema.AddForeignKey{F: change.F})
+			case *schema.ModifyForeignKey:
+				// Only attribute changes reach here (see modifyTable).
+				if sqlx.Has(change.From.Attrs, &NotValid{}) && !sqlx.Has(change.To.Attrs, &NotValid{}) && !deferrableChanged(change.From.Attrs, change.To.Attrs) {
+					b.P("VALIDATE CONSTRAINT").Ident(change.To.Symbol)
+				} else {
+					b.P("DROP CONSTRAINT").Ident(change.From.Symbol).Comma().P("ADD")
+					s.fks(b, change.To)
+					notValid(b, change.To.Attrs)
+				}
+				reverse = append(reverse, &schema.ModifyForeignKey{
+					From:   change.To,
+					To:     change.From,
+					Change: change.Change,
+				})
 			case *schema.AddCheck:
 				check(b.P("ADD"), change.C)
+				notValid(b, change.C.Attrs)
 				// Reverse operation is supported if
 				// the constraint name is not generated.
 				if reversible = reversible && change.C.Name != ""; reversible {
@@ -377,11 +1313,13 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 					return errors.New("cannot modify unnamed check constraint")
 				case change.From.Name != change.To.Name:
 					return fmt.Errorf("mismatch check constraint names: %q != %q", change.From.Name, change.To.Name)
-				case change.From.Expr != change.To.Expr,
-					sqlx.Has(change.From.Attrs, &NoInherit{}) && !sqlx.Has(change.To.Attrs, &NoInherit{}),
-					!sqlx.Has(change.From.Attrs, &NoInherit{}) && sqlx.Has(change.To.Attrs, &NoInherit{}):
+				case checkRebuilt(change),
+					!sqlx.Has(change.From.Attrs, &NotValid{}) && sqlx.Has(change.To.Attrs, &NotValid{}):
 					b.P("DROP CONSTRAINT").Ident(change.From.Name).Comma().P("ADD")
 					check(b, change.To)
+					notValid(b, change.To.Attrs)
+				case sqlx.Has(change.From.Attrs, &NotValid{}) && !sqlx.Has(change.To.Attrs, &NotValid{}):
+					b.P("VALIDATE CONSTRAINT").Ident(change.To.Name)
 				default:
 					return errors.New("unknown check constraint change")
 				}
@@ -397,6 +1335,10 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 		}
 		return b.String(), nil
 	}
+	var validate []schema.Change
+	if s.validate != nil {
+		changes, validate = notValidChanges(changes)
+	}
 	cmd, err := build(changes)
 	if err != nil {
 		return fmt.Errorf("alter table %q: %v", t.Name, err)
@@ -418,9 +1360,65 @@ func (s *state) alterTable(t *schema.Table, changes []schema.Change) error {
 		}
 	}
 	s.append(change)
+	if len(validate) > 0 {
+		vs := &state{conn: s.conn}
+		if err := vs.alterTable(t, validate); err != nil {
+			return err
+		}
+		for _, c := range vs.Changes {
+			c.Comment = fmt.Sprintf("validate constraints of %q table", t.Name)
+			c.SeparateFile = s.validate.SeparateFile
+		}
+		s.validation = append(s.validation, vs.Changes...)
+	}
 	return nil
 }
 
+// notValidChanges replaces the named constraints that are added by the changes with
+// NOT VALID copies of them, and returns the changes for validating them afterwards.
+func notValidChanges(changes []schema.Change) ([]schema.Change, []schema.Change) {
+	var (
+		validate = make([]schema.Change, 0, len(changes))
+		planned  = make([]schema.Change, 0, len(changes))
+	)
+	for _, c := range changes {
+		switch c := c.(type) {
+		case *schema.AddForeignKey:
+			if c.F.Symbol != "" && !sqlx.Has(c.F.Attrs, &NotValid{}) {
+				fk := *c.F
+				fk.Attrs = append(fk.Attrs[:len(fk.Attrs):len(fk.Attrs)], &NotValid{})
+				planned = append(planned, &schema.AddForeignKey{F: &fk})
+				validate = append(validate, &schema.ModifyForeignKey{From: &fk, To: c.F, Change: schema.ChangeAttr})
+				continue
+			}
+		case *schema.AddCheck:
+			if c.C.Name != "" && !sqlx.Has(c.C.Attrs, &NotValid{}) {
+				ck := *c.C
+				ck.Attrs = append(ck.Attrs[:len(ck.Attrs):len(ck.Attrs)], &NotValid{})
+				planned = append(planned, &schema.AddCheck{C: &ck})
+				validate = append(validate, &schema.ModifyCheck{From: &ck, To: c.C})
+				continue
+			}
+		case *schema.ModifyCheck:
+			if checkRebuilt(c) && !sqlx.Has(c.To.Attrs, &NotValid{}) {
+				ck := *c.To
+				ck.Attrs = append(ck.Attrs[:len(ck.Attrs):len(ck.Attrs)], &NotValid{})
+				planned = append(planned, &schema.ModifyCheck{From: c.From, To: &ck})
+				validate = append(validate, &schema.ModifyCheck{From: &ck, To: c.To})
+				continue
+			}
+		}
+		planned = append(planned, c)
+	}
+	return planned, validate
+}
+
+// checkRebuilt reports if the CHECK constraint modification
+// requires dropping the constraint and adding it again.
+func checkRebuilt(c *schema.ModifyCheck) bool {
+	return c.From.Expr != c.To.Expr || sqlx.Has(c.From.Attrs, &NoInherit{}) != sqlx.Has(c.To.Attrs, &NoInherit{})
+}
+
 func (s *state) renameTable(c *schema.RenameTable) {
 	s.append(&migrate.Change{
 		Source:  c,
@@ -476,16 +1474,20 @@ func (*state) indexComment(t *schema.Table, idx *schema.Index, to, from string)
 	}
 }
 
-func (s *state) dropIndexes(t *schema.Table, indexes ...*schema.Index) {
-	rs := &state{conn: s.conn}
-	rs.addIndexes(t, indexes...)
+func (s *state) dropIndexes(t *schema.Table, indexes ...*schema.Index) error {
+	rs := &state{conn: s.conn, concurrent: s.concurrent}
+	if err := rs.addIndexes(t, true, indexes...); err != nil {
+		return err
+	}
 	for i, idx := range indexes {
 		s.append(&migrate.Change{
-			Cmd:     rs.Changes[i].Reverse,
-			Comment: fmt.Sprintf("drop index %q from table: %q", idx.Name, t.Name),
-			Reverse: rs.Changes[i].Cmd,
+			Cmd:              rs.Changes[i].Reverse,
+			Comment:          fmt.Sprintf("drop index %q from table: %q", idx.Name, t.Name),
+			Reverse:          rs.Changes[i].Cmd,
+			NonTransactional: rs.Changes[i].NonTransactional,
 		})
 	}
+	return nil
 }
 
 func (s *state) addTypes(ctx context.Context, columns ...*schema.Column) error {
@@ -545,23 +1547,55 @@ func (s *state) enumExists(ctx context.Context, name string) (bool, error) {
 	return rows.Next(), rows.Err()
 }
 
-func (s *state) addIndexes(t *schema.Table, indexes ...*schema.Index) {
+// addIndexes creates the given indexes on the table. If the table already
+// exists, the indexes may be created concurrently (see concurrently).
+func (s *state) addIndexes(t *schema.Table, exists bool, indexes ...*schema.Index) error {
 	for _, idx := range indexes {
+		// Exclusion constraints and deferrable unique constraints are added using
+		// ALTER TABLE, and their underlying indexes are dropped along with them.
+		if exclude := isExclude(idx.Attrs); exclude || idx.Unique && sqlx.Has(idx.Attrs, &Deferrable{}) {
+			if err := checkUnique(t, idx); !exclude && err != nil {
+				return err
+			}
+			b, c := Build("ALTER TABLE").Table(t).P("ADD CONSTRAINT").Ident(idx.Name), "unique"
+			if exclude {
+				b.P("EXCLUDE")
+				c = "exclusion"
+			} else {
+				b.P("UNIQUE")
+			}
+			s.index(b, idx)
+			deferrable(b, idx.Attrs)
+			s.append(&migrate.Change{
+				Cmd:     b.String(),
+				Comment: fmt.Sprintf("create %s constraint %q to table: %q", c, idx.Name, t.Name),
+				Reverse: Build("ALTER TABLE").Table(t).P("DROP CONSTRAINT").Ident(idx.Name).String(),
+			})
+			continue
+		}
+		concurrently := exists && s.concurrently(t, idx)
 		b := Build("CREATE")
 		if idx.Unique {
 			b.P("UNIQUE")
 		}
 		b.P("INDEX")
+		if concurrently {
+			b.P("CONCURRENTLY")
+		}
 		if idx.Name != "" {
 			b.Ident(idx.Name)
 		}
 		b.P("ON").Table(t)
 		s.index(b, idx)
 		s.append(&migrate.Change{
-			Cmd:     b.String(),
-			Comment: fmt.Sprintf("create index %q to table: %q", idx.Name, t.Name),
+			Cmd:              b.String(),
+			Comment:          fmt.Sprintf("create index %q to table: %q", idx.Name, t.Name),
+			NonTransactional: concurrently,
 			Reverse: func() string {
 				b := Build("DROP INDEX")
+				if concurrently {
+					b.P("CONCURRENTLY")
+				}
 				// Unlike MySQL, the DROP command is not attached to ALTER TABLE.
 				// Therefore, we print indexes with their qualified name, because
 				// the connection that executes the statements may not be attached
@@ -577,6 +1611,13 @@ func (s *state) addIndexes(t *schema.Table, indexes ...*schema.Index) {
 			}(),
 		})
 	}
+	return nil
+}
+
+// concurrently reports if the index should be created or dropped concurrently.
+// Partitioned tables do not support building their indexes concurrently.
+func (s *state) concurrently(t *schema.Table, idx *schema.Index) bool {
+	return (s.concurrent || sqlx.Has(idx.Attrs, &IndexConcurrently{})) && !sqlx.Has(t.Attrs, &Partition{})
 }
 
 func (s *state) column(b *sqlx.Builder, c *schema.Column) error {
@@ -736,10 +1777,15 @@ func (s *state) partAttrs(b *sqlx.Builder, p *schema.IndexPart) {
 			}
 		case *schema.Collation:
 			b.P("COLLATE").Ident(attr.V)
+		case *ExcludeOp:
+			// Written last, after the element options.
 		default:
 			panic(fmt.Sprintf("unexpected index part attribute: %T", attr))
 		}
 	}
+	if op := (ExcludeOp{}); sqlx.Has(p.Attrs, &op) {
+		b.P("WITH", op.Op)
+	}
 }
 
 func (s *state) index(b *sqlx.Builder, idx *schema.Index) {
@@ -748,7 +1794,10 @@ func (s *state) index(b *sqlx.Builder, idx *schema.Index) {
 		b.P("USING", t.T)
 	}
 	s.indexParts(b, idx.Parts)
-	if p := (IndexPredicate{}); sqlx.Has(idx.Attrs, &p) {
+	// In exclusion constraints, the predicate is written
+	// in parentheses, after the index parameters.
+	exclude := isExclude(idx.Attrs)
+	if p := (IndexPredicate{}); sqlx.Has(idx.Attrs, &p) && !exclude {
 		b.P("WHERE").P(p.P)
 	}
 	if p, ok := indexStorageParams(idx.Attrs); ok {
@@ -764,9 +1813,12 @@ func (s *state) index(b *sqlx.Builder, idx *schema.Index) {
 			b.WriteString(strings.Join(parts, ", "))
 		})
 	}
+	if p := (IndexPredicate{}); sqlx.Has(idx.Attrs, &p) && exclude {
+		b.P("WHERE", sqlx.MayWrap(p.P))
+	}
 	for _, attr := range idx.Attrs {
 		switch attr.(type) {
-		case *schema.Comment, *ConType, *IndexType, *IndexPredicate, *IndexStorageParams:
+		case *schema.Comment, *ConType, *IndexType, *IndexPredicate, *IndexStorageParams, *IndexConcurrently, *IndexInvalid, *Deferrable:
 		default:
 			panic(fmt.Sprintf("unexpected index attribute: %T", attr))
 		}
@@ -797,6 +1849,7 @@ func (s *state) fks(b *sqlx.Builder, fks ...*schema.ForeignKey) {
 		if fk.OnDelete != "" {
 			b.P("ON DELETE", string(fk.OnDelete))
 		}
+		deferrable(b, fk.Attrs)
 	})
 }
 
@@ -883,6 +1936,58 @@ func check(b *sqlx.Builder, c *schema.Check) {
 	}
 }
 
+// notValid writes the NOT VALID option to the builder if
+// the constraint is not validated (see NotValid).
+func notValid(b *sqlx.Builder, attrs []schema.Attr) {
+	if sqlx.Has(attrs, &NotValid{}) {
+		b.P("NOT VALID")
+	}
+}
+
+// deferrable writes the DEFERRABLE clause to the builder
+// if the constraint is deferrable (see Deferrable).
+func deferrable(b *sqlx.Builder, attrs []schema.Attr) {
+	if d := (Deferrable{}); sqlx.Has(attrs, &d) {
+		b.P("DEFERRABLE")
+		if d.InitiallyDeferred {
+			b.P("INITIALLY DEFERRED")
+		}
+	}
+}
+
+// checkUnique checks that the index can be created as a UNIQUE constraint (e.g. deferrable
+// unique indexes). Unlike indexes, UNIQUE constraints are defined on plain columns, and do
+// not accept index methods, predicates, expressions, sort orderings or collations.
+func checkUnique(t *schema.Table, idx *schema.Index) error {
+	var invalid []string
+	if it := (IndexType{}); sqlx.Has(idx.Attrs, &it) && strings.ToUpper(it.T) != IndexTypeBTree {
+		invalid = append(invalid, fmt.Sprintf("index method %q", it.T))
+	}
+	if sqlx.Has(idx.Attrs, &IndexPredicate{}) {
+		invalid = append(invalid, "predicate")
+	}
+	for _, p := range idx.Parts {
+		switch {
+		case p.X != nil:
+			invalid = append(invalid, "expression")
+		case p.Desc:
+			invalid = append(invalid, fmt.Sprintf("descending column %q", p.C.Name))
+		default:
+			for _, a := range p.Attrs {
+				// Only the default ordering of null values (NULLS LAST) is allowed.
+				if c, ok := a.(*IndexColumnProperty); !ok || c.NullsFirst {
+					invalid = append(invalid, fmt.Sprintf("options on column %q", p.C.Name))
+					break
+				}
+			}
+		}
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("unique constraint %q on table %q does not support: %s", idx.Name, t.Name, strings.Join(invalid, ", "))
+	}
+	return nil
+}
+
 // isUniqueConstraint reports if the index is a valid UNIQUE constraint.
 func isUniqueConstraint(i *schema.Index) bool {
 	if c := (ConType{}); !sqlx.Has(i.Attrs, &c) || !c.IsUnique() || !i.Unique {
@@ -6,6 +6,7 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"strconv"
 	"testing"
 
@@ -20,6 +21,7 @@ import (
 func TestPlanChanges(t *testing.T) {
 	tests := []struct {
 		changes []schema.Change
+		opts    []migrate.PlanOption
 		mock    func(mock)
 		plan    *migrate.Plan
 	}{
@@ -841,6 +843,504 @@ func TestPlanChanges(t *testing.T) {
 				},
 			},
 		},
+		// Create a sequence owned by a new table.
+		{
+			changes: func() []schema.Change {
+				public := schema.New("public")
+				users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "bigint").SetDefault(&schema.RawExpr{X: "nextval('public.users_seq')"}))
+				public.AddTables(users)
+				return []schema.Change{
+					&schema.AddTable{T: users},
+					&schema.AddObject{O: &Sequence{Name: "users_seq", Schema: public, Type: "integer", Start: 100, Increment: 10, Min: 1, Max: 2147483647, OwnedBy: &SequenceOwner{T: users, C: users.Columns[0]}}},
+				}
+			}(),
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `CREATE SEQUENCE "public"."users_seq" AS integer INCREMENT BY 10 START WITH 100`, Reverse: `DROP SEQUENCE "public"."users_seq"`},
+					{Cmd: `CREATE TABLE "public"."users" ("id" bigint NOT NULL DEFAULT nextval('public.users_seq'))`, Reverse: `DROP TABLE "public"."users"`},
+					{Cmd: `ALTER SEQUENCE "public"."users_seq" OWNED BY "public"."users" ."id"`, Reverse: `ALTER SEQUENCE "public"."users_seq" OWNED BY NONE`},
+				},
+			},
+		},
+		// Modify and drop sequences.
+		{
+			changes: func() []schema.Change {
+				public := schema.New("public")
+				users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "bigint"), schema.NewIntColumn("uid", "bigint"))
+				public.AddTables(users)
+				return []schema.Change{
+					&schema.ModifyObject{
+						From: &Sequence{Name: "s1", Schema: public, OwnedBy: &SequenceOwner{T: users, C: users.Columns[0]}},
+						To:   &Sequence{Name: "s1", Schema: public, Increment: -1, Min: -100, Max: -1, Start: -1, Cycle: true, OwnedBy: &SequenceOwner{T: users, C: users.Columns[1]}},
+					},
+					&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.DropColumn{C: users.Columns[0]}}},
+					&schema.DropObject{O: &Sequence{Name: "s2", Schema: public, Type: "smallint", Start: 1, Increment: 1, Min: 1, Max: 32767}},
+				}
+			}(),
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `ALTER SEQUENCE "public"."s1" INCREMENT BY -1 MINVALUE -100 MAXVALUE -1 START WITH -1 CYCLE OWNED BY NONE`, Reverse: `ALTER SEQUENCE "public"."s1" INCREMENT BY 1 MINVALUE 1 MAXVALUE 9223372036854775807 START WITH 1 NO CYCLE OWNED BY "public"."users" ."id"`},
+					{Cmd: `ALTER TABLE "public"."users" DROP COLUMN "id"`, Reverse: `ALTER TABLE "public"."users" ADD COLUMN "id" bigint NOT NULL`},
+					{Cmd: `ALTER SEQUENCE "public"."s1" OWNED BY "public"."users" ."uid"`, Reverse: `ALTER SEQUENCE "public"."s1" OWNED BY NONE`},
+					{Cmd: `DROP SEQUENCE "public"."s2"`, Reverse: `CREATE SEQUENCE "public"."s2" AS smallint`},
+				},
+			},
+		},
+		// Roles are created first and dropped last. Privileges are granted after the tables were created.
+		{
+			changes: func() []schema.Change {
+				public := schema.New("public")
+				users := schema.NewTable("users").AddColumns(schema.NewStringColumn("email", "text"))
+				public.AddTables(users)
+				app, old := &Role{Name: "app", Login: true}, &Role{Name: "old"}
+				return []schema.Change{
+					&schema.AddTable{T: users},
+					&schema.AddObject{O: &Permission{Role: app, Schema: public, Table: users, Privileges: []string{"SELECT", "INSERT"}, Grantable: true}},
+					&schema.AddObject{O: &Permission{Role: app, Schema: public, Table: users, Column: users.Columns[0], Privileges: []string{"UPDATE"}}},
+					&schema.AddObject{O: app},
+					&schema.DropObject{O: &Permission{Role: old, Schema: public, Privileges: []string{"USAGE"}}},
+					&schema.DropObject{O: old},
+					&schema.ModifyObject{From: &Role{Name: "admin"}, To: &Role{Name: "admin", Login: true, CreateDB: true}},
+					&schema.ModifyObject{
+						From: &Permission{Role: &Role{Name: "admin"}, Schema: public, Privileges: []string{"USAGE"}},
+						To:   &Permission{Role: &Role{Name: "admin"}, Schema: public, Privileges: []string{"CREATE"}},
+					},
+				}
+			}(),
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `CREATE ROLE "app" LOGIN`, Reverse: `DROP ROLE "app"`},
+					{Cmd: `ALTER ROLE "admin" LOGIN CREATEDB`, Reverse: `ALTER ROLE "admin" NOLOGIN NOCREATEDB`},
+					{Cmd: `CREATE TABLE "public"."users" ("email" text NOT NULL)`, Reverse: `DROP TABLE "public"."users"`},
+					{Cmd: `REVOKE USAGE ON SCHEMA "public" FROM "old"`, Reverse: `GRANT USAGE ON SCHEMA "public" TO "old"`},
+					{Cmd: `REVOKE USAGE ON SCHEMA "public" FROM "admin"`, Reverse: `GRANT USAGE ON SCHEMA "public" TO "admin"`},
+					{Cmd: `GRANT SELECT, INSERT ON TABLE "public"."users" TO "app" WITH GRANT OPTION`, Reverse: `REVOKE SELECT, INSERT ON TABLE "public"."users" FROM "app"`},
+					{Cmd: `GRANT UPDATE ("email") ON TABLE "public"."users" TO "app"`, Reverse: `REVOKE UPDATE ("email") ON TABLE "public"."users" FROM "app"`},
+					{Cmd: `GRANT CREATE ON SCHEMA "public" TO "admin"`, Reverse: `REVOKE CREATE ON SCHEMA "public" FROM "admin"`},
+					{Cmd: `DROP ROLE "old"`, Reverse: `CREATE ROLE "old"`},
+				},
+			},
+		},
+		// Extensions are created before other objects and tables, and dropped after them.
+		{
+			changes: func() []schema.Change {
+				public := schema.New("public")
+				users := schema.NewTable("users").AddColumns(&schema.Column{Name: "name", Type: &schema.ColumnType{Type: &UserDefinedType{T: "citext"}}})
+				public.AddTables(users)
+				return []schema.Change{
+					&schema.AddTable{T: users},
+					&schema.AddObject{O: &Sequence{Name: "s1", Schema: public}},
+					&schema.AddObject{O: &Extension{Name: "citext", Schema: public, Version: "1.6"}},
+					&schema.DropObject{O: &Extension{Name: "hstore", Schema: public}},
+					&schema.DropObject{O: &Sequence{Name: "s2", Schema: public}},
+					&schema.ModifyObject{From: &Extension{Name: "pg_trgm", Schema: public, Version: "1.5"}, To: &Extension{Name: "pg_trgm", Schema: public, Version: "1.6"}},
+				}
+			}(),
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `CREATE EXTENSION "citext" WITH SCHEMA "public" VERSION '1.6'`, Reverse: `DROP EXTENSION "citext"`},
+					{Cmd: `ALTER EXTENSION "pg_trgm" UPDATE TO '1.6'`, Reverse: `ALTER EXTENSION "pg_trgm" UPDATE TO '1.5'`},
+					{Cmd: `CREATE SEQUENCE "public"."s1"`, Reverse: `DROP SEQUENCE "public"."s1"`},
+					{Cmd: `CREATE TABLE "public"."users" ("name" citext NOT NULL)`, Reverse: `DROP TABLE "public"."users"`},
+					{Cmd: `DROP SEQUENCE "public"."s2"`, Reverse: `CREATE SEQUENCE "public"."s2"`},
+					{Cmd: `DROP EXTENSION "hstore"`, Reverse: `CREATE EXTENSION "hstore" WITH SCHEMA "public"`},
+				},
+			},
+		},
+		// Domain and composite types are created before the tables that use them, and dropped after them.
+		{
+			changes: func() []schema.Change {
+				public := schema.New("public")
+				email := &DomainType{T: "email", Schema: public, Base: &schema.StringType{T: "text"}, Default: &schema.Literal{V: "a@b"}, Checks: []*schema.Check{{Name: "email_check", Expr: "VALUE ~~ '%@%'"}}}
+				address := &CompositeType{T: "address", Schema: public, Fields: []*CompositeField{{Name: "street", Type: &schema.StringType{T: "text"}}, {Name: "email", Type: email}}}
+				users := schema.NewTable("users").AddColumns(
+					&schema.Column{Name: "email", Type: &schema.ColumnType{Type: email}},
+					&schema.Column{Name: "address", Type: &schema.ColumnType{Type: address, Null: true}},
+				)
+				public.AddTables(users)
+				return []schema.Change{
+					&schema.AddTable{T: users},
+					&schema.AddObject{O: address},
+					&schema.AddObject{O: email},
+					&schema.DropObject{O: &DomainType{T: "positive", Schema: public, Base: &schema.IntegerType{T: "integer"}, Null: true}},
+					&schema.DropObject{O: &CompositeType{T: "point", Schema: public, Fields: []*CompositeField{{Name: "x", Type: &schema.IntegerType{T: "integer"}}}}},
+				}
+			}(),
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `CREATE DOMAIN "public"."email" AS text DEFAULT 'a@b' NOT NULL CONSTRAINT "email_check" CHECK (VALUE ~~ '%@%')`, Reverse: `DROP DOMAIN "public"."email"`},
+					{Cmd: `CREATE TYPE "public"."address" AS ("street" text, "email" email)`, Reverse: `DROP TYPE "public"."address"`},
+					{Cmd: `CREATE TABLE "public"."users" ("email" email NOT NULL, "address" address NULL)`, Reverse: `DROP TABLE "public"."users"`},
+					{Cmd: `DROP TYPE "public"."point"`, Reverse: `CREATE TYPE "public"."point" AS ("x" integer)`},
+					{Cmd: `DROP DOMAIN "public"."positive"`, Reverse: `CREATE DOMAIN "public"."positive" AS integer`},
+				},
+			},
+		},
+		// Modify domain and composite types.
+		{
+			changes: func() []schema.Change {
+				public := schema.New("public")
+				return []schema.Change{
+					&schema.ModifyObject{
+						From: &DomainType{T: "email", Schema: public, Base: &schema.StringType{T: "text"}, Null: true, Checks: []*schema.Check{{Name: "c1", Expr: "VALUE <> ''"}}},
+						To:   &DomainType{T: "email", Schema: public, Base: &schema.StringType{T: "text"}, Default: &schema.Literal{V: "a@b"}, Checks: []*schema.Check{{Name: "c2", Expr: "VALUE ~~ '%@%'"}}},
+					},
+					&schema.ModifyObject{
+						From: &CompositeType{T: "address", Schema: public, Fields: []*CompositeField{{Name: "street", Type: &schema.StringType{T: "text"}}, {Name: "zip", Type: &schema.IntegerType{T: "integer"}}}},
+						To:   &CompositeType{T: "address", Schema: public, Fields: []*CompositeField{{Name: "zip", Type: &schema.StringType{T: "text"}}, {Name: "city", Type: &schema.StringType{T: "text"}}}},
+					},
+				}
+			}(),
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `ALTER DOMAIN "public"."email" SET DEFAULT 'a@b'`, Reverse: `ALTER DOMAIN "public"."email" DROP DEFAULT`},
+					{Cmd: `ALTER DOMAIN "public"."email" SET NOT NULL`, Reverse: `ALTER DOMAIN "public"."email" DROP NOT NULL`},
+					{Cmd: `ALTER DOMAIN "public"."email" DROP CONSTRAINT "c1"`, Reverse: `ALTER DOMAIN "public"."email" ADD CONSTRAINT "c1" CHECK (VALUE <> '')`},
+					{Cmd: `ALTER DOMAIN "public"."email" ADD CONSTRAINT "c2" CHECK (VALUE ~~ '%@%')`, Reverse: `ALTER DOMAIN "public"."email" DROP CONSTRAINT "c2"`},
+					{Cmd: `ALTER TYPE "public"."address" DROP ATTRIBUTE "street", ALTER ATTRIBUTE "zip" TYPE text, ADD ATTRIBUTE "city" text`, Reverse: `ALTER TYPE "public"."address" DROP ATTRIBUTE "city", ADD ATTRIBUTE "street" text, ALTER ATTRIBUTE "zip" TYPE integer`},
+				},
+			},
+		},
+		// Partitions are created after their parents.
+		{
+			changes: func() []schema.Change {
+				public := schema.New("public")
+				c := schema.NewIntColumn("id", "integer")
+				logs := schema.NewTable("logs").SetSchema(public).AddColumns(c).AddAttrs(&Partition{T: PartitionTypeRange, Parts: []*PartitionPart{{C: c}}})
+				a := schema.NewTable("logs_a").SetSchema(public).AddAttrs(&PartitionOf{Parent: logs, Bound: "FOR VALUES FROM (1) TO (10)"}, &Partition{T: PartitionTypeHash, Parts: []*PartitionPart{{C: c}}})
+				a1 := schema.NewTable("logs_a_1").SetSchema(public).AddAttrs(&PartitionOf{Parent: a, Bound: "FOR VALUES WITH (MODULUS 2, REMAINDER 0)"})
+				return []schema.Change{&schema.AddTable{T: a1}, &schema.AddTable{T: a}, &schema.AddTable{T: logs}}
+			}(),
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `CREATE TABLE "public"."logs" ("id" integer NOT NULL) PARTITION BY RANGE ("id")`, Reverse: `DROP TABLE "public"."logs"`},
+					{Cmd: `CREATE TABLE "public"."logs_a" PARTITION OF "public"."logs" FOR VALUES FROM (1) TO (10) PARTITION BY HASH ("id")`, Reverse: `DROP TABLE "public"."logs_a"`},
+					{Cmd: `CREATE TABLE "public"."logs_a_1" PARTITION OF "public"."logs_a" FOR VALUES WITH (MODULUS 2, REMAINDER 0)`, Reverse: `DROP TABLE "public"."logs_a_1"`},
+				},
+			},
+		},
+		// Partitions are created after their parents, and before the foreign keys
+		// that reference them. Parents are created after the tables they reference.
+		{
+			changes: func() []schema.Change {
+				c := schema.NewIntColumn("id", "integer")
+				owners := schema.NewTable("owners").AddColumns(schema.NewIntColumn("id", "integer"))
+				logs := schema.NewTable("logs").AddColumns(c, schema.NewIntColumn("owner_id", "integer")).AddAttrs(&Partition{T: PartitionTypeRange, Parts: []*PartitionPart{{C: c}}})
+				logs.AddForeignKeys(schema.NewForeignKey("owner").AddColumns(logs.Columns[1]).SetRefTable(owners).AddRefColumns(owners.Columns[0]))
+				a := schema.NewTable("logs_a").AddAttrs(&PartitionOf{Parent: logs, Bound: "DEFAULT"})
+				users := schema.NewTable("users").AddColumns(schema.NewIntColumn("log_id", "integer"))
+				fk := schema.NewForeignKey("users_log").SetTable(users).AddColumns(users.Columns[0]).SetRefTable(a).AddRefColumns(c)
+				return []schema.Change{
+					&schema.AddTable{T: a},
+					&schema.ModifyTable{T: users, Changes: []schema.Change{&schema.AddForeignKey{F: fk}}},
+					&schema.AddTable{T: logs},
+					&schema.AddTable{T: owners},
+				}
+			}(),
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `CREATE TABLE "owners" ("id" integer NOT NULL)`, Reverse: `DROP TABLE "owners"`},
+					{Cmd: `CREATE TABLE "logs" ("id" integer NOT NULL, "owner_id" integer NOT NULL, CONSTRAINT "owner" FOREIGN KEY ("owner_id") REFERENCES "owners" ("id")) PARTITION BY RANGE ("id")`, Reverse: `DROP TABLE "logs"`},
+					{Cmd: `CREATE TABLE "logs_a" PARTITION OF "logs" DEFAULT`, Reverse: `DROP TABLE "logs_a"`},
+					{Cmd: `ALTER TABLE "users" ADD CONSTRAINT "users_log" FOREIGN KEY ("log_id") REFERENCES "logs_a" ("id")`, Reverse: `ALTER TABLE "users" DROP CONSTRAINT "users_log"`},
+				},
+			},
+		},
+		// Partitions are dropped before their parents.
+		{
+			changes: func() []schema.Change {
+				logs := schema.NewTable("logs")
+				a := schema.NewTable("logs_a").AddAttrs(&PartitionOf{Parent: logs, Bound: "DEFAULT"})
+				return []schema.Change{&schema.DropTable{T: logs}, &schema.DropTable{T: a}}
+			}(),
+			plan: &migrate.Plan{
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `DROP TABLE "logs_a"`},
+					{Cmd: `DROP TABLE "logs"`},
+				},
+			},
+		},
+		// Attach and detach partitions.
+		{
+			changes: func() []schema.Change {
+				logs, logs2 := schema.NewTable("logs"), schema.NewTable("logs2")
+				return []schema.Change{
+					&schema.ModifyTable{T: schema.NewTable("logs_a"), Changes: []schema.Change{
+						&schema.AddAttr{A: &PartitionOf{Parent: logs, Bound: "FOR VALUES IN (1)"}},
+					}},
+					&schema.ModifyTable{T: schema.NewTable("logs_b"), Changes: []schema.Change{
+						&schema.DropAttr{A: &PartitionOf{Parent: logs, Bound: "DEFAULT"}},
+					}},
+					&schema.ModifyTable{T: schema.NewTable("logs_c"), Changes: []schema.Change{
+						&schema.ModifyAttr{
+							From: &PartitionOf{Parent: logs, Bound: "FOR VALUES IN (2)"},
+							To:   &PartitionOf{Parent: logs2, Bound: "FOR VALUES IN (3)"},
+						},
+					}},
+				}
+			}(),
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `ALTER TABLE "logs" ATTACH PARTITION "logs_a" FOR VALUES IN (1)`, Reverse: `ALTER TABLE "logs" DETACH PARTITION "logs_a"`},
+					{Cmd: `ALTER TABLE "logs" DETACH PARTITION "logs_b"`, Reverse: `ALTER TABLE "logs" ATTACH PARTITION "logs_b" DEFAULT`},
+					{Cmd: `ALTER TABLE "logs" DETACH PARTITION "logs_c"`, Reverse: `ALTER TABLE "logs" ATTACH PARTITION "logs_c" FOR VALUES IN (2)`},
+					{Cmd: `ALTER TABLE "logs2" ATTACH PARTITION "logs_c" FOR VALUES IN (3)`, Reverse: `ALTER TABLE "logs2" DETACH PARTITION "logs_c"`},
+				},
+			},
+		},
+		// Exclusion constraints.
+		{
+			changes: func() []schema.Change {
+				t := schema.NewTable("bookings").
+					AddColumns(schema.NewIntColumn("room", "int"), schema.NewColumn("during").SetType(&schema.UnsupportedType{T: "tsrange"}))
+				t.AddIndexes(schema.NewIndex("bookings_overlap").
+					AddAttrs(&IndexType{T: IndexTypeGiST}, &ConType{T: "x"}, &IndexPredicate{P: "room > 0"}).
+					AddParts(
+						schema.NewColumnPart(t.Columns[0]).AddAttrs(&ExcludeOp{Op: "="}),
+						schema.NewColumnPart(t.Columns[1]).AddAttrs(&ExcludeOp{Op: "&&"}),
+					))
+				return []schema.Change{
+					&schema.ModifyTable{T: t, Changes: []schema.Change{&schema.AddIndex{I: t.Indexes[0]}}},
+					&schema.ModifyTable{T: t, Changes: []schema.Change{&schema.DropIndex{I: t.Indexes[0]}}},
+				}
+			}(),
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `ALTER TABLE "bookings" ADD CONSTRAINT "bookings_overlap" EXCLUDE USING GIST ("room" WITH =, "during" WITH &&) WHERE (room > 0)`, Reverse: `ALTER TABLE "bookings" DROP CONSTRAINT "bookings_overlap"`},
+					{Cmd: `ALTER TABLE "bookings" DROP CONSTRAINT "bookings_overlap"`, Reverse: `ALTER TABLE "bookings" ADD CONSTRAINT "bookings_overlap" EXCLUDE USING GIST ("room" WITH =, "during" WITH &&) WHERE (room > 0)`},
+				},
+			},
+		},
+		// Row-level security and policies of new tables.
+		{
+			changes: []schema.Change{
+				&schema.AddTable{
+					T: schema.NewTable("users").
+						AddColumns(schema.NewIntColumn("tenant", "int")).
+						AddAttrs(
+							&RowSecurity{Enabled: true, Forced: true},
+							&Policy{Name: "tenant", Using: "tenant = current_setting('app.tenant')::int"},
+							&Policy{Name: "insert", As: PolicyRestrictive, For: PolicyForInsert, To: []string{"app", "CURRENT_USER"}, Check: "(tenant > 0)"},
+						),
+				},
+			},
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `CREATE TABLE "users" ("tenant" integer NOT NULL)`, Reverse: `DROP TABLE "users"`},
+					{Cmd: `ALTER TABLE "users" ENABLE ROW LEVEL SECURITY`, Reverse: `ALTER TABLE "users" DISABLE ROW LEVEL SECURITY`},
+					{Cmd: `ALTER TABLE "users" FORCE ROW LEVEL SECURITY`, Reverse: `ALTER TABLE "users" NO FORCE ROW LEVEL SECURITY`},
+					{Cmd: `CREATE POLICY "tenant" ON "users" USING (tenant = current_setting('app.tenant')::int)`, Reverse: `DROP POLICY "tenant" ON "users"`},
+					{Cmd: `CREATE POLICY "insert" ON "users" AS RESTRICTIVE FOR INSERT TO CURRENT_USER, "app" WITH CHECK (tenant > 0)`, Reverse: `DROP POLICY "insert" ON "users"`},
+				},
+			},
+		},
+		// Modify row-level security and policies.
+		{
+			changes: []schema.Change{
+				&schema.ModifyTable{T: schema.NewTable("users"), Changes: []schema.Change{
+					&schema.DropAttr{A: &RowSecurity{Enabled: true}},
+					&schema.DropAttr{A: &Policy{Name: "p1", Using: "true"}},
+					&schema.ModifyAttr{
+						From: &Policy{Name: "p2", To: []string{"app"}, Using: "(tenant = 1)"},
+						To:   &Policy{Name: "p2", Using: "(tenant = 2)", Check: "true"},
+					},
+					&schema.ModifyAttr{
+						From: &Policy{Name: "p3", For: PolicyForSelect, Using: "true"},
+						To:   &Policy{Name: "p3", For: PolicyForUpdate, Using: "true"},
+					},
+				}},
+			},
+			plan: &migrate.Plan{
+				Reversible:    false,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `ALTER TABLE "users" DISABLE ROW LEVEL SECURITY`, Reverse: `ALTER TABLE "users" ENABLE ROW LEVEL SECURITY`},
+					{Cmd: `DROP POLICY "p1" ON "users"`, Reverse: `CREATE POLICY "p1" ON "users" USING (true)`},
+					{Cmd: `ALTER POLICY "p2" ON "users" TO PUBLIC USING (tenant = 2) WITH CHECK (true)`},
+					{Cmd: `DROP POLICY "p3" ON "users"`, Reverse: `CREATE POLICY "p3" ON "users" FOR SELECT USING (true)`},
+					{Cmd: `CREATE POLICY "p3" ON "users" FOR UPDATE USING (true)`, Reverse: `DROP POLICY "p3" ON "users"`},
+				},
+			},
+		},
+		// Indexes of existing tables are created and dropped concurrently.
+		{
+			opts: []migrate.PlanOption{&ConcurrentIndex{}},
+			changes: func() []schema.Change {
+				users := schema.NewTable("users").
+					SetSchema(schema.New("public")).
+					AddColumns(schema.NewStringColumn("name", "text"))
+				pets := schema.NewTable("pets").
+					AddColumns(schema.NewStringColumn("name", "text"))
+				pets.AddIndexes(schema.NewIndex("pets_name").AddColumns(pets.Columns[0]))
+				return []schema.Change{
+					&schema.AddTable{T: pets},
+					&schema.ModifyTable{T: users, Changes: []schema.Change{
+						&schema.DropIndex{I: schema.NewIndex("old").SetTable(users).AddColumns(users.Columns[0])},
+						&schema.AddIndex{I: schema.NewUniqueIndex("users_name").SetTable(users).AddColumns(users.Columns[0])},
+					}},
+				}
+			}(),
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: false,
+				Changes: []*migrate.Change{
+					{Cmd: `CREATE TABLE "pets" ("name" text NOT NULL)`, Reverse: `DROP TABLE "pets"`},
+					{Cmd: `CREATE INDEX "pets_name" ON "pets" ("name")`, Reverse: `DROP INDEX "pets_name"`},
+					{Cmd: `DROP INDEX CONCURRENTLY "public"."old"`, Reverse: `CREATE INDEX CONCURRENTLY "old" ON "public"."users" ("name")`, NonTransactional: true},
+					{Cmd: `CREATE UNIQUE INDEX CONCURRENTLY "users_name" ON "public"."users" ("name")`, Reverse: `DROP INDEX CONCURRENTLY "public"."users_name"`, NonTransactional: true},
+				},
+			},
+		},
+		// Concurrent index creation configured per index, and
+		// skipped for partitioned tables that do not support it.
+		{
+			changes: func() []schema.Change {
+				users := schema.NewTable("users").
+					AddColumns(schema.NewStringColumn("name", "text"))
+				logs := schema.NewTable("logs").
+					AddColumns(schema.NewStringColumn("name", "text")).
+					AddAttrs(&Partition{T: PartitionTypeList, Parts: []*PartitionPart{{C: schema.NewStringColumn("name", "text")}}})
+				return []schema.Change{
+					&schema.ModifyTable{T: users, Changes: []schema.Change{
+						&schema.AddIndex{I: schema.NewIndex("users_name").SetTable(users).AddColumns(users.Columns[0]).AddAttrs(&IndexConcurrently{})},
+					}},
+					&schema.ModifyTable{T: logs, Changes: []schema.Change{
+						&schema.AddIndex{I: schema.NewIndex("logs_name").SetTable(logs).AddColumns(logs.Columns[0]).AddAttrs(&IndexConcurrently{})},
+					}},
+				}
+			}(),
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: false,
+				Changes: []*migrate.Change{
+					{Cmd: `CREATE INDEX CONCURRENTLY "users_name" ON "users" ("name")`, Reverse: `DROP INDEX CONCURRENTLY "users_name"`, NonTransactional: true},
+					{Cmd: `CREATE INDEX "logs_name" ON "logs" ("name")`, Reverse: `DROP INDEX "logs_name"`},
+				},
+			},
+		},
+		// Constraints are added as NOT VALID, and validated in a separate file.
+		{
+			opts: []migrate.PlanOption{&ValidateConstraints{SeparateFile: true}},
+			changes: func() []schema.Change {
+				users := schema.NewTable("users").
+					AddColumns(schema.NewIntColumn("id", "int"))
+				pets := schema.NewTable("pets").
+					AddColumns(schema.NewIntColumn("owner_id", "int"))
+				return []schema.Change{
+					&schema.ModifyTable{T: pets, Changes: []schema.Change{
+						&schema.AddForeignKey{F: schema.NewForeignKey("owner_id").SetTable(pets).AddColumns(pets.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0])},
+						&schema.AddCheck{C: schema.NewCheck().SetName("positive_owner").SetExpr("owner_id > 0")},
+						&schema.AddCheck{C: schema.NewCheck().SetExpr("owner_id < 100")},
+					}},
+				}
+			}(),
+			plan: &migrate.Plan{
+				Reversible:    false,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{Cmd: `ALTER TABLE "pets" ADD CONSTRAINT "owner_id" FOREIGN KEY ("owner_id") REFERENCES "users" ("id") NOT VALID, ADD CONSTRAINT "positive_owner" CHECK (owner_id > 0) NOT VALID, ADD CHECK (owner_id < 100)`},
+					{Cmd: `ALTER TABLE "pets" VALIDATE CONSTRAINT "owner_id", VALIDATE CONSTRAINT "positive_owner"`, Reverse: `ALTER TABLE "pets" DROP CONSTRAINT "positive_owner", ADD CONSTRAINT "positive_owner" CHECK (owner_id > 0) NOT VALID, DROP CONSTRAINT "owner_id", ADD CONSTRAINT "owner_id" FOREIGN KEY ("owner_id") REFERENCES "users" ("id") NOT VALID`, SeparateFile: true},
+				},
+			},
+		},
+		// Deferrable constraints. Changing the deferrability of a
+		// constraint is translated into dropping and adding it again.
+		{
+			changes: func() []schema.Change {
+				users := schema.NewTable("users").
+					AddColumns(schema.NewIntColumn("id", "int"))
+				pets := schema.NewTable("pets").
+					AddColumns(schema.NewIntColumn("owner_id", "int"), schema.NewIntColumn("tag", "int"))
+				fk := func() *schema.ForeignKey {
+					return schema.NewForeignKey("owner_id").SetTable(pets).AddColumns(pets.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0])
+				}
+				return []schema.Change{
+					&schema.ModifyTable{T: pets, Changes: []schema.Change{
+						&schema.ModifyForeignKey{
+							From:   fk(),
+							To:     fk().AddAttrs(&Deferrable{InitiallyDeferred: true}),
+							Change: schema.ChangeAttr,
+						},
+						&schema.ModifyIndex{
+							From:   schema.NewUniqueIndex("pets_tag_key").SetTable(pets).AddColumns(pets.Columns[1]).AddAttrs(&ConType{T: "u"}),
+							To:     schema.NewUniqueIndex("pets_tag_key").SetTable(pets).AddColumns(pets.Columns[1]).AddAttrs(&Deferrable{}),
+							Change: schema.ChangeAttr,
+						},
+					}},
+				}
+			}(),
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "pets" DROP CONSTRAINT "owner_id", ADD CONSTRAINT "owner_id" FOREIGN KEY ("owner_id") REFERENCES "users" ("id") DEFERRABLE INITIALLY DEFERRED, DROP CONSTRAINT "pets_tag_key"`,
+						Reverse: `ALTER TABLE "pets" ADD CONSTRAINT "pets_tag_key" UNIQUE ("tag"), DROP CONSTRAINT "owner_id", ADD CONSTRAINT "owner_id" FOREIGN KEY ("owner_id") REFERENCES "users" ("id")`,
+					},
+					{
+						Cmd:     `ALTER TABLE "pets" ADD CONSTRAINT "pets_tag_key" UNIQUE ("tag") DEFERRABLE`,
+						Reverse: `ALTER TABLE "pets" DROP CONSTRAINT "pets_tag_key"`,
+					},
+				},
+			},
+		},
+		// Validate constraints that were added as NOT VALID.
+		{
+			changes: func() []schema.Change {
+				users := schema.NewTable("users").
+					AddColumns(schema.NewIntColumn("id", "int"))
+				pets := schema.NewTable("pets").
+					AddColumns(schema.NewIntColumn("owner_id", "int"))
+				fk := schema.NewForeignKey("owner_id").SetTable(pets).AddColumns(pets.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0])
+				return []schema.Change{
+					&schema.ModifyTable{T: pets, Changes: []schema.Change{
+						&schema.ModifyForeignKey{
+							From:   schema.NewForeignKey("owner_id").SetTable(pets).AddColumns(pets.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0]).AddAttrs(&NotValid{}),
+							To:     fk,
+							Change: schema.ChangeAttr,
+						},
+						&schema.ModifyCheck{
+							From: schema.NewCheck().SetName("positive_owner").SetExpr("owner_id > 0").AddAttrs(&NotValid{}),
+							To:   schema.NewCheck().SetName("positive_owner").SetExpr("owner_id > 0"),
+						},
+						&schema.AddCheck{C: schema.NewCheck().SetName("small_owner").SetExpr("owner_id < 100").AddAttrs(&NotValid{})},
+					}},
+				}
+			}(),
+			plan: &migrate.Plan{
+				Reversible:    true,
+				Transactional: true,
+				Changes: []*migrate.Change{
+					{
+						Cmd:     `ALTER TABLE "pets" VALIDATE CONSTRAINT "owner_id", VALIDATE CONSTRAINT "positive_owner", ADD CONSTRAINT "small_owner" CHECK (owner_id < 100) NOT VALID`,
+						Reverse: `ALTER TABLE "pets" DROP CONSTRAINT "small_owner", DROP CONSTRAINT "positive_owner", ADD CONSTRAINT "positive_owner" CHECK (owner_id > 0) NOT VALID, DROP CONSTRAINT "owner_id", ADD CONSTRAINT "owner_id" FOREIGN KEY ("owner_id") REFERENCES "users" ("id") NOT VALID`,
+					},
+				},
+			},
+		},
 	}
 	for i, tt := range tests {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
@@ -853,14 +1353,128 @@ func TestPlanChanges(t *testing.T) {
 			}
 			drv, err := Open(db)
 			require.NoError(t, err)
-			plan, err := drv.PlanChanges(context.Background(), "plan", tt.changes)
+			plan, err := drv.PlanChanges(context.Background(), "plan", tt.changes, tt.opts...)
 			require.NoError(t, err)
 			require.Equal(t, tt.plan.Reversible, plan.Reversible)
 			require.Equal(t, tt.plan.Transactional, plan.Transactional)
+			require.Len(t, plan.Changes, len(tt.plan.Changes))
 			for i, c := range plan.Changes {
 				require.Equal(t, tt.plan.Changes[i].Cmd, c.Cmd)
 				require.Equal(t, tt.plan.Changes[i].Reverse, c.Reverse)
+				require.Equal(t, tt.plan.Changes[i].NonTransactional, c.NonTransactional)
+				require.Equal(t, tt.plan.Changes[i].SeparateFile, c.SeparateFile)
 			}
 		})
 	}
 }
+
+func TestPlanChanges_ValidateConstraintsApply(t *testing.T) {
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	m := mock{mk}
+	m.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	users := schema.NewTable("users").
+		AddColumns(schema.NewIntColumn("id", "int"))
+	plan, err := drv.PlanChanges(context.Background(), "add_check", []schema.Change{
+		&schema.ModifyTable{T: users, Changes: []schema.Change{
+			&schema.AddCheck{C: schema.NewCheck().SetName("positive_id").SetExpr("id > 0")},
+		}},
+	}, &ValidateConstraints{SeparateFile: true})
+	require.NoError(t, err)
+	dir, err := migrate.NewLocalDir(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, migrate.NewPlanner(drv, dir).WritePlan(plan))
+
+	// The NOT VALID constraint is committed, and its lock is
+	// released, before the validation transaction starts.
+	m.ExpectQuery(sqltest.Escape("SELECT pg_try_advisory_lock($1)")).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(1))
+	m.ExpectBegin()
+	m.version("130000")
+	m.ExpectExec(sqltest.Escape(`ALTER TABLE "users" ADD CONSTRAINT "positive_id" CHECK (id > 0) NOT VALID;`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectCommit()
+	m.ExpectBegin()
+	m.version("130000")
+	m.ExpectExec(sqltest.Escape(`ALTER TABLE "users" VALIDATE CONSTRAINT "positive_id";`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectCommit()
+	m.ExpectQuery(sqltest.Escape("SELECT pg_advisory_unlock($1)")).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_advisory_unlock"}).AddRow(1))
+	ex, err := migrate.NewExecutor(drv, dir, migrate.NopRevisionReadWriter{}, migrate.WithTx(func(ctx context.Context) (migrate.TxDriver, error) {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		drv, err := Open(tx)
+		if err != nil {
+			return nil, err
+		}
+		return &txDriver{Driver: drv, tx: tx}, nil
+	}))
+	require.NoError(t, err)
+	require.NoError(t, ex.ExecuteN(context.Background(), 0))
+	require.NoError(t, mk.ExpectationsWereMet())
+}
+
+func TestPlanChanges_DeferrableUnique(t *testing.T) {
+	db, mk, err := sqlmock.New()
+	require.NoError(t, err)
+	mock{mk}.version("130000")
+	drv, err := Open(db)
+	require.NoError(t, err)
+	pets := schema.NewTable("pets").
+		AddColumns(schema.NewIntColumn("owner_id", "int"), schema.NewStringColumn("tag", "text"))
+	for _, tt := range []struct {
+		idx *schema.Index
+		err string
+	}{
+		{
+			idx: schema.NewUniqueIndex("pets_tag_key").AddColumns(pets.Columns[1]).AddAttrs(&IndexType{T: IndexTypeHash}),
+			err: `unique constraint "pets_tag_key" on table "pets" does not support: index method "HASH"`,
+		},
+		{
+			idx: schema.NewUniqueIndex("pets_tag_key").AddColumns(pets.Columns[1]).AddAttrs(&IndexPredicate{P: "owner_id > 0"}),
+			err: `unique constraint "pets_tag_key" on table "pets" does not support: predicate`,
+		},
+		{
+			idx: schema.NewUniqueIndex("pets_tag_key").AddParts(&schema.IndexPart{C: pets.Columns[1], Desc: true}),
+			err: `unique constraint "pets_tag_key" on table "pets" does not support: descending column "tag"`,
+		},
+		{
+			idx: schema.NewUniqueIndex("pets_tag_key").AddParts(&schema.IndexPart{X: &schema.RawExpr{X: "lower(tag)"}}),
+			err: `unique constraint "pets_tag_key" on table "pets" does not support: expression`,
+		},
+		{
+			idx: schema.NewUniqueIndex("pets_tag_key").AddParts(&schema.IndexPart{C: pets.Columns[1], Attrs: []schema.Attr{&schema.Collation{V: "C"}}}),
+			err: `unique constraint "pets_tag_key" on table "pets" does not support: options on column "tag"`,
+		},
+	} {
+		tt.idx.SetTable(pets).AddAttrs(&Deferrable{})
+		_, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{
+			&schema.ModifyTable{T: pets, Changes: []schema.Change{&schema.AddIndex{I: tt.idx}}},
+		})
+		require.EqualError(t, err, tt.err)
+	}
+
+	// The default ordering of null values is allowed.
+	idx := schema.NewUniqueIndex("pets_tag_key").SetTable(pets).
+		AddParts(&schema.IndexPart{C: pets.Columns[1], Attrs: []schema.Attr{&IndexColumnProperty{NullsLast: true}}}).
+		AddAttrs(&IndexType{T: IndexTypeBTree}, &Deferrable{})
+	plan, err := drv.PlanChanges(context.Background(), "plan", []schema.Change{
+		&schema.ModifyTable{T: pets, Changes: []schema.Change{&schema.AddIndex{I: idx}}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `ALTER TABLE "pets" ADD CONSTRAINT "pets_tag_key" UNIQUE ("tag") DEFERRABLE`, plan.Changes[0].Cmd)
+}
+
+// txDriver is a migrate.TxDriver for drivers that were opened on a transaction.
+type txDriver struct {
+	migrate.Driver
+	tx *sql.Tx
+}
+
+func (d *txDriver) Commit() error   { return d.tx.Commit() }
+func (d *txDriver) Rollback() error { return d.tx.Rollback() }
@@ -5,6 +5,7 @@
 package postgres
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -20,9 +21,15 @@ import (
 
 type (
 	doc struct {
-		Tables  []*sqlspec.Table  `spec:"table"`
-		Schemas []*sqlspec.Schema `spec:"schema"`
-		Enums   []*Enum           `spec:"enum"`
+		Tables      []*sqlspec.Table           `spec:"table"`
+		Schemas     []*sqlspec.Schema          `spec:"schema"`
+		Enums       []*Enum                    `spec:"enum"`
+		Sequences   []*sequenceSpec            `spec:"sequence"`
+		Extensions  []*extensionSpec           `spec:"extension"`
+		Domains     []*domainSpec              `spec:"domain"`
+		Composites  []*compositeSpec           `spec:"composite"`
+		Roles       []*specutil.RoleSpec       `spec:"role"`
+		Permissions []*specutil.PermissionSpec `spec:"permission"`
 	}
 	// Enum holds a specification for an enum, that can be referenced as a column type.
 	Enum struct {
@@ -31,10 +38,55 @@ type (
 		Values []string       `spec:"values"`
 		schemahcl.DefaultExtension
 	}
+	// sequenceSpec holds a specification for a standalone sequence. The sequence
+	// options (e.g. start or increment) are stored as extra attributes, as their
+	// defaults depend on the sequence type and its increment direction.
+	sequenceSpec struct {
+		Name    string          `spec:",name"`
+		Schema  *schemahcl.Ref  `spec:"schema"`
+		Type    *schemahcl.Type `spec:"type"`
+		OwnedBy *schemahcl.Ref  `spec:"owned_by"`
+		schemahcl.DefaultExtension
+	}
+	// extensionSpec holds a specification for an extension installed in a schema.
+	// An empty version stands for the default version of the extension.
+	extensionSpec struct {
+		Name    string         `spec:",name"`
+		Schema  *schemahcl.Ref `spec:"schema"`
+		Version string         `spec:"version,omitempty"`
+		schemahcl.DefaultExtension
+	}
+	// domainSpec holds a specification for a domain type, that can be referenced as a column type.
+	domainSpec struct {
+		Name    string           `spec:",name"`
+		Schema  *schemahcl.Ref   `spec:"schema"`
+		Type    *schemahcl.Type  `spec:"type"`
+		Null    bool             `spec:"null"`
+		Default schemahcl.Value  `spec:"default"`
+		Checks  []*sqlspec.Check `spec:"check"`
+		schemahcl.DefaultExtension
+	}
+	// compositeSpec holds a specification for a composite type, that can be referenced as a column type.
+	compositeSpec struct {
+		Name   string         `spec:",name"`
+		Schema *schemahcl.Ref `spec:"schema"`
+		Fields []*fieldSpec   `spec:"field"`
+		schemahcl.DefaultExtension
+	}
+	// fieldSpec holds a specification for a field of a composite type.
+	fieldSpec struct {
+		Name string          `spec:",name"`
+		Type *schemahcl.Type `spec:"type"`
+		schemahcl.DefaultExtension
+	}
 )
 
 func init() {
 	schemahcl.Register("enum", &Enum{})
+	schemahcl.Register("sequence", &sequenceSpec{})
+	schemahcl.Register("extension", &extensionSpec{})
+	schemahcl.Register("domain", &domainSpec{})
+	schemahcl.Register("composite", &compositeSpec{})
 }
 
 // evalSpec evaluates an Atlas DDL document into v using the input.
@@ -55,10 +107,34 @@ func evalSpec(p *hclparse.Parser, v interface{}, input map[string]string) error
 				}
 			}
 		}
+		if err := convertExtensions(d.Extensions, v); err != nil {
+			return err
+		}
+		if err := convertTypes(d.Domains, d.Composites, v); err != nil {
+			return err
+		}
+		if err := convertTypeRefs(d.Tables, v); err != nil {
+			return err
+		}
+		if err := convertPartitionOf(d.Tables, v); err != nil {
+			return err
+		}
+		if err := convertForeignKeys(d.Tables, v); err != nil {
+			return err
+		}
+		if err := convertSequences(d.Sequences, v); err != nil {
+			return err
+		}
+		if err := convertRoles(d.Roles, d.Permissions, v); err != nil {
+			return err
+		}
 	case *schema.Schema:
 		if len(d.Schemas) != 1 {
 			return fmt.Errorf("specutil: expecting document to contain a single schema, got %d", len(d.Schemas))
 		}
+		if len(d.Roles) > 0 || len(d.Permissions) > 0 {
+			return errors.New("specutil: roles and permissions can be evaluated only into a *schema.Realm")
+		}
 		var r schema.Realm
 		if err := specutil.Scan(&r, d.Schemas, d.Tables, convertTable); err != nil {
 			return err
@@ -66,6 +142,24 @@ func evalSpec(p *hclparse.Parser, v interface{}, input map[string]string) error
 		if err := convertEnums(d.Tables, d.Enums, r.Schemas[0]); err != nil {
 			return err
 		}
+		if err := convertExtensions(d.Extensions, &r); err != nil {
+			return err
+		}
+		if err := convertTypes(d.Domains, d.Composites, &r); err != nil {
+			return err
+		}
+		if err := convertTypeRefs(d.Tables, &r); err != nil {
+			return err
+		}
+		if err := convertPartitionOf(d.Tables, &r); err != nil {
+			return err
+		}
+		if err := convertForeignKeys(d.Tables, &r); err != nil {
+			return err
+		}
+		if err := convertSequences(d.Sequences, &r); err != nil {
+			return err
+		}
 		r.Schemas[0].Realm = nil
 		*v = *r.Schemas[0]
 	default:
@@ -87,6 +181,10 @@ func MarshalSpec(v interface{}, marshaler schemahcl.Marshaler) ([]byte, error) {
 		d.Tables = doc.Tables
 		d.Schemas = doc.Schemas
 		d.Enums = doc.Enums
+		d.Sequences = doc.Sequences
+		d.Extensions = doc.Extensions
+		d.Domains = doc.Domains
+		d.Composites = doc.Composites
 	case *schema.Realm:
 		for _, s := range s.Schemas {
 			doc, err := schemaSpec(s)
@@ -96,6 +194,14 @@ func MarshalSpec(v interface{}, marshaler schemahcl.Marshaler) ([]byte, error) {
 			d.Tables = append(d.Tables, doc.Tables...)
 			d.Schemas = append(d.Schemas, doc.Schemas...)
 			d.Enums = append(d.Enums, doc.Enums...)
+			d.Sequences = append(d.Sequences, doc.Sequences...)
+			d.Extensions = append(d.Extensions, doc.Extensions...)
+			d.Domains = append(d.Domains, doc.Domains...)
+			d.Composites = append(d.Composites, doc.Composites...)
+		}
+		var err error
+		if d.Roles, d.Permissions, err = roleSpecs(s); err != nil {
+			return nil, fmt.Errorf("specutil: failed converting roles to spec: %w", err)
 		}
 	default:
 		return nil, fmt.Errorf("specutil: failed marshaling spec. %T is not supported", v)
@@ -112,9 +218,12 @@ var (
 		schemahcl.WithScopedEnums("table.index.type", IndexTypeBTree, IndexTypeHash, IndexTypeGIN, IndexTypeGiST, IndexTypeBRIN),
 		schemahcl.WithScopedEnums("table.partition.type", PartitionTypeRange, PartitionTypeList, PartitionTypeHash),
 		schemahcl.WithScopedEnums("table.column.identity.generated", GeneratedTypeAlways, GeneratedTypeByDefault),
+		schemahcl.WithScopedEnums("table.policy.as", PolicyPermissive, PolicyRestrictive),
+		schemahcl.WithScopedEnums("table.policy.for", PolicyForAll, PolicyForSelect, PolicyForInsert, PolicyForUpdate, PolicyForDelete),
 		schemahcl.WithScopedEnums("table.column.as.type", "STORED"),
 		schemahcl.WithScopedEnums("table.foreign_key.on_update", specutil.ReferenceVars...),
 		schemahcl.WithScopedEnums("table.foreign_key.on_delete", specutil.ReferenceVars...),
+		schemahcl.WithScopedEnums("permission.privileges", privileges...),
 	)
 	// MarshalHCL marshals v into an Atlas HCL DDL document.
 	MarshalHCL = schemahcl.MarshalerFunc(func(v interface{}) ([]byte, error) {
@@ -132,18 +241,211 @@ var (
 // ForeignKeySpecs into ForeignKeys, as the target tables do not necessarily exist in the schema
 // at this point. Instead, the linking is done by the convertSchema function.
 func convertTable(spec *sqlspec.Table, parent *schema.Schema) (*schema.Table, error) {
-	t, err := specutil.Table(spec, parent, convertColumn, specutil.PrimaryKey, convertIndex, specutil.Check)
+	t, err := specutil.Table(spec, parent, convertColumn, specutil.PrimaryKey, convertIndex, convertCheck)
 	if err != nil {
 		return nil, err
 	}
-	if err := convertPartition(spec.Extra, t); err != nil {
+	// The partition key of partitions (sub-partitioning) references the columns
+	// of their parent tables, and therefore, it is converted after all tables
+	// were created. See convertPartitionOf for more info.
+	if _, ok := spec.Extra.Resource("partition_of"); !ok {
+		if err := convertPartition(spec.Extra, t, t); err != nil {
+			return nil, err
+		}
+	}
+	if err := convertSecurity(spec.Extra, t); err != nil {
 		return nil, err
 	}
 	return t, nil
 }
 
+// convertCheck converts a sqlspec.Check to a schema.Check.
+func convertCheck(spec *sqlspec.Check) (*schema.Check, error) {
+	c, err := specutil.Check(spec)
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := notValidAttr(spec.DefaultExtension); err != nil {
+		return nil, fmt.Errorf("parsing check %q: %w", spec.Name, err)
+	} else if ok {
+		c.Attrs = append(c.Attrs, &NotValid{})
+	}
+	return c, nil
+}
+
+// convertForeignKeys converts the attributes of the foreign keys that were linked by specutil.Scan.
+func convertForeignKeys(tbls []*sqlspec.Table, r *schema.Realm) error {
+	for _, spec := range tbls {
+		for _, f := range spec.ForeignKeys {
+			var attrs []schema.Attr
+			ok, err := notValidAttr(f.DefaultExtension)
+			if err != nil {
+				return fmt.Errorf("parsing foreign key %q: %w", f.Symbol, err)
+			}
+			if ok {
+				attrs = append(attrs, &NotValid{})
+			}
+			d, err := deferrableAttr(f.DefaultExtension)
+			if err != nil {
+				return fmt.Errorf("parsing foreign key %q: %w", f.Symbol, err)
+			}
+			if d != nil {
+				attrs = append(attrs, d)
+			}
+			if len(attrs) == 0 {
+				continue
+			}
+			n, err := specutil.SchemaName(spec.Schema)
+			if err != nil {
+				return err
+			}
+			s, ok := r.Schema(n)
+			if !ok {
+				return fmt.Errorf("postgres: schema %q not found for table %q", n, spec.Name)
+			}
+			t, ok := s.Table(spec.Name)
+			if !ok {
+				return fmt.Errorf("postgres: table %q not found in schema %q", spec.Name, s.Name)
+			}
+			fk, ok := t.ForeignKey(f.Symbol)
+			if !ok {
+				return fmt.Errorf("postgres: foreign key %q not found in table %q", f.Symbol, t.Name)
+			}
+			fk.Attrs = append(fk.Attrs, attrs...)
+		}
+	}
+	return nil
+}
+
+// notValidAttr reports if the not_valid attribute is set on the constraint spec.
+func notValidAttr(ext schemahcl.DefaultExtension) (bool, error) {
+	attr, ok := ext.Attr("not_valid")
+	if !ok {
+		return false, nil
+	}
+	return attr.Bool()
+}
+
+// deferrableAttr returns the Deferrable attribute of the constraint spec, if it is
+// deferrable. Note, INITIALLY DEFERRED constraints are also DEFERRABLE.
+func deferrableAttr(ext schemahcl.DefaultExtension) (*Deferrable, error) {
+	var d, deferred bool
+	if attr, ok := ext.Attr("deferrable"); ok {
+		b, err := attr.Bool()
+		if err != nil {
+			return nil, err
+		}
+		d = b
+	}
+	if attr, ok := ext.Attr("initially_deferred"); ok {
+		b, err := attr.Bool()
+		if err != nil {
+			return nil, err
+		}
+		deferred = b
+	}
+	if !d && !deferred {
+		return nil, nil
+	}
+	return &Deferrable{InitiallyDeferred: deferred}, nil
+}
+
+// deferrableSpec returns the spec attributes of the Deferrable attribute, if exists.
+func deferrableSpec(attrs []schema.Attr) []*schemahcl.Attr {
+	d := &Deferrable{}
+	if !sqlx.Has(attrs, d) {
+		return nil
+	}
+	if d.InitiallyDeferred {
+		return []*schemahcl.Attr{specutil.BoolAttr("deferrable", true), specutil.BoolAttr("initially_deferred", true)}
+	}
+	return []*schemahcl.Attr{specutil.BoolAttr("deferrable", true)}
+}
+
+// convertSecurity converts and appends the row_security and the policy blocks into the table attributes.
+func convertSecurity(spec schemahcl.Resource, t *schema.Table) error {
+	if r, ok := spec.Resource("row_security"); ok {
+		var rs struct {
+			Enabled bool `spec:"enabled"`
+			Forced  bool `spec:"forced"`
+		}
+		if err := r.As(&rs); err != nil {
+			return fmt.Errorf("parsing %s.row_security: %w", t.Name, err)
+		}
+		t.AddAttrs(&RowSecurity{Enabled: rs.Enabled, Forced: rs.Forced})
+	}
+	for _, r := range spec.Children {
+		if r.Type != "policy" {
+			continue
+		}
+		var p struct {
+			As    string   `spec:"as"`
+			For   string   `spec:"for"`
+			To    []string `spec:"to"`
+			Using string   `spec:"using"`
+			Check string   `spec:"check"`
+		}
+		if err := r.As(&p); err != nil {
+			return fmt.Errorf("parsing %s.policy.%s: %w", t.Name, r.Name, err)
+		}
+		if p.Using == "" && p.Check == "" {
+			return fmt.Errorf("missing attribute using or check for %s.policy.%s", t.Name, r.Name)
+		}
+		t.AddAttrs(&Policy{
+			Name:  r.Name,
+			As:    strings.ToUpper(p.As),
+			For:   strings.ToUpper(p.For),
+			To:    p.To,
+			Using: p.Using,
+			Check: p.Check,
+		})
+	}
+	return nil
+}
+
+// fromSecurity returns the resource specs for representing the
+// row-level security settings and the policies of the table.
+func fromSecurity(t *schema.Table) []*schemahcl.Resource {
+	var specs []*schemahcl.Resource
+	if rs := (RowSecurity{}); sqlx.Has(t.Attrs, &rs) && (rs.Enabled || rs.Forced) {
+		r := &schemahcl.Resource{Type: "row_security"}
+		if rs.Enabled {
+			r.Attrs = append(r.Attrs, specutil.BoolAttr("enabled", true))
+		}
+		if rs.Forced {
+			r.Attrs = append(r.Attrs, specutil.BoolAttr("forced", true))
+		}
+		specs = append(specs, r)
+	}
+	for _, p := range policies(t.Attrs) {
+		r := &schemahcl.Resource{Type: "policy", Name: p.Name}
+		if as := policyAs(p); as != PolicyPermissive {
+			r.Attrs = append(r.Attrs, specutil.VarAttr("as", as))
+		}
+		if cmd := policyFor(p); cmd != PolicyForAll {
+			r.Attrs = append(r.Attrs, specutil.VarAttr("for", cmd))
+		}
+		if roles := policyRoles(p); len(roles) > 0 {
+			for i := range roles {
+				roles[i] = strconv.Quote(roles[i])
+			}
+			r.Attrs = append(r.Attrs, specutil.ListAttr("to", roles...))
+		}
+		if p.Using != "" {
+			r.Attrs = append(r.Attrs, specutil.StrAttr("using", p.Using))
+		}
+		if p.Check != "" {
+			r.Attrs = append(r.Attrs, specutil.StrAttr("check", p.Check))
+		}
+		specs = append(specs, r)
+	}
+	return specs
+}
+
 // convertPartition converts and appends the partition block into the table attributes if exists.
-func convertPartition(spec schemahcl.Resource, table *schema.Table) error {
+// The columns of the partition key are resolved from the root table, which is the table itself,
+// or the root partitioned table in case the table is a partition.
+func convertPartition(spec schemahcl.Resource, table, root *schema.Table) error {
 	r, ok := spec.Resource("partition")
 	if !ok {
 		return nil
@@ -170,7 +472,7 @@ func convertPartition(spec schemahcl.Resource, table *schema.Table) error {
 		return fmt.Errorf(`multiple definitions for %s.partition, use "columns" or "by"`, table.Name)
 	case n > 0:
 		for _, r := range p.Columns {
-			c, err := specutil.ColumnByRef(table, r)
+			c, err := specutil.ColumnByRef(root, r)
 			if err != nil {
 				return err
 			}
@@ -184,7 +486,7 @@ func convertPartition(spec schemahcl.Resource, table *schema.Table) error {
 			case p.Column != nil && p.Expr != "":
 				return fmt.Errorf("multiple definitions for  %s.partition.by at position %d", table.Name, i)
 			case p.Column != nil:
-				c, err := specutil.ColumnByRef(table, p.Column)
+				c, err := specutil.ColumnByRef(root, p.Column)
 				if err != nil {
 					return err
 				}
@@ -198,6 +500,74 @@ func convertPartition(spec schemahcl.Resource, table *schema.Table) error {
 	return nil
 }
 
+// convertPartitionOf converts the partition_of blocks of the table specs,
+// and links the partitions to their parent (partitioned) tables.
+func convertPartitionOf(tbls []*sqlspec.Table, r *schema.Realm) error {
+	var parts []*schema.Table
+	specs := make(map[*schema.Table]*sqlspec.Table)
+	for _, spec := range tbls {
+		b, ok := spec.Extra.Resource("partition_of")
+		if !ok {
+			continue
+		}
+		var p struct {
+			Table *schemahcl.Ref `spec:"table"`
+			Bound string         `spec:"bound"`
+		}
+		if err := b.As(&p); err != nil {
+			return fmt.Errorf("parsing %s.partition_of: %w", spec.Name, err)
+		}
+		if p.Table == nil || p.Bound == "" {
+			return fmt.Errorf("missing attribute table or bound for %s.partition_of", spec.Name)
+		}
+		n, err := specutil.SchemaName(spec.Schema)
+		if err != nil {
+			return err
+		}
+		s, ok := r.Schema(n)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q not found for table %q", n, spec.Name)
+		}
+		t, ok := s.Table(spec.Name)
+		if !ok {
+			return fmt.Errorf("postgres: table %q not found in schema %q", spec.Name, s.Name)
+		}
+		if len(t.Columns) > 0 || len(t.Indexes) > 0 || len(t.ForeignKeys) > 0 || t.PrimaryKey != nil {
+			return fmt.Errorf("postgres: partition %q cannot define columns, indexes or constraints (inherited from its parent table)", t.Name)
+		}
+		parent, err := specutil.TableByRef(p.Table, s)
+		if err != nil {
+			return err
+		}
+		t.AddAttrs(&PartitionOf{Parent: parent, Bound: p.Bound})
+		parts = append(parts, t)
+		specs[t] = spec
+	}
+	// Sub-partition keys are converted after all
+	// partitions were linked to their parents.
+	for _, t := range parts {
+		if err := convertPartition(specs[t].Extra, t, partitionRoot(t)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fromPartitionOf returns the resource spec for representing the partition_of block.
+func fromPartitionOf(t *schema.Table, p PartitionOf) *schemahcl.Resource {
+	var qualifier string
+	if p.Parent.Schema != nil && t.Schema != nil && p.Parent.Schema.Name != t.Schema.Name {
+		qualifier = p.Parent.Schema.Name
+	}
+	return &schemahcl.Resource{
+		Type: "partition_of",
+		Attrs: []*schemahcl.Attr{
+			specutil.RefAttr("table", specutil.TableRef(qualifier, p.Parent.Name)),
+			specutil.StrAttr("bound", p.Bound),
+		},
+	}
+}
+
 // fromPartition returns the resource spec for representing the partition block.
 func fromPartition(p Partition) *schemahcl.Resource {
 	key := &schemahcl.Resource{
@@ -293,10 +663,27 @@ func fixDefaultQuotes(value schemahcl.Value) error {
 
 // convertIndex converts a sqlspec.Index into a schema.Index.
 func convertIndex(spec *sqlspec.Index, t *schema.Table) (*schema.Index, error) {
-	idx, err := specutil.Index(spec, t)
+	idx, err := specutil.Index(spec, t, convertExcludeOp)
 	if err != nil {
 		return nil, err
 	}
+	if attr, ok := spec.Attr("exclude"); ok {
+		exclude, err := attr.Bool()
+		if err != nil {
+			return nil, err
+		}
+		if exclude {
+			if idx.Unique {
+				return nil, fmt.Errorf("exclusion constraint %q cannot be unique", idx.Name)
+			}
+			for i, p := range idx.Parts {
+				if !sqlx.Has(p.Attrs, &ExcludeOp{}) {
+					return nil, fmt.Errorf("missing operator for exclusion constraint %q at position %d", idx.Name, i)
+				}
+			}
+			idx.Attrs = append(idx.Attrs, &ConType{T: "x"})
+		}
+	}
 	if attr, ok := spec.Attr("type"); ok {
 		t, err := attr.String()
 		if err != nil {
@@ -311,6 +698,23 @@ func convertIndex(spec *sqlspec.Index, t *schema.Table) (*schema.Index, error) {
 		}
 		idx.Attrs = append(idx.Attrs, &IndexPredicate{P: p})
 	}
+	if attr, ok := spec.Attr("concurrently"); ok {
+		c, err := attr.Bool()
+		if err != nil {
+			return nil, err
+		}
+		if c {
+			idx.Attrs = append(idx.Attrs, &IndexConcurrently{})
+		}
+	}
+	switch d, err := deferrableAttr(spec.DefaultExtension); {
+	case err != nil:
+		return nil, err
+	case d != nil && !idx.Unique && !isExclude(idx.Attrs):
+		return nil, fmt.Errorf("index %q must be unique or an exclusion constraint to be deferrable", idx.Name)
+	case d != nil:
+		idx.Attrs = append(idx.Attrs, d)
+	}
 	if attr, ok := spec.Attr("page_per_range"); ok {
 		p, err := attr.Int64()
 		if err != nil {
@@ -321,6 +725,20 @@ func convertIndex(spec *sqlspec.Index, t *schema.Table) (*schema.Index, error) {
 	return idx, nil
 }
 
+// convertExcludeOp converts the operator of an exclusion constraint element, if exists.
+func convertExcludeOp(spec *sqlspec.IndexPart, part *schema.IndexPart) error {
+	attr, ok := spec.Attr("op")
+	if !ok {
+		return nil
+	}
+	op, err := attr.String()
+	if err != nil {
+		return err
+	}
+	part.Attrs = append(part.Attrs, &ExcludeOp{Op: op})
+	return nil
+}
+
 const defaultTimePrecision = 6
 
 // convertColumnType converts a sqlspec.Column into a concrete Postgres schema.Type.
@@ -344,7 +762,7 @@ func convertColumnType(spec *sqlspec.Column) (schema.Type, error) {
 func convertEnums(tbls []*sqlspec.Table, enums []*Enum, sch *schema.Schema) error {
 	for _, tbl := range tbls {
 		for _, col := range tbl.Columns {
-			if col.Type.IsRef {
+			if col.Type.IsRef && strings.HasPrefix(col.Type.T, "$enum.") {
 				e, err := resolveEnum(col.Type, enums)
 				if err != nil {
 					return err
@@ -390,6 +808,429 @@ func enumName(ref *schemahcl.Type) (string, error) {
 	return s[1], nil
 }
 
+// convertTypes converts the domain and composite specs into DomainTypes and
+// CompositeTypes, and adds them to their schemas in the realm.
+func convertTypes(domains []*domainSpec, composites []*compositeSpec, r *schema.Realm) error {
+	for _, spec := range domains {
+		s, err := typeSchema(r, spec.Schema, "domain", spec.Name)
+		if err != nil {
+			return err
+		}
+		if spec.Type == nil {
+			return fmt.Errorf("postgres: missing type for domain %q", spec.Name)
+		}
+		if err := fixDefaultQuotes(spec.Default); err != nil {
+			return err
+		}
+		c, err := specutil.Column(&sqlspec.Column{Name: spec.Name, Null: spec.Null, Type: spec.Type, Default: spec.Default}, convertColumnType)
+		if err != nil {
+			return fmt.Errorf("postgres: domain %q: %w", spec.Name, err)
+		}
+		d := &DomainType{T: spec.Name, Schema: s, Base: c.Type.Type, Null: c.Type.Null, Default: c.Default}
+		for _, c := range spec.Checks {
+			d.Checks = append(d.Checks, &schema.Check{Name: c.Name, Expr: c.Expr})
+		}
+		s.AddObjects(d)
+	}
+	for _, spec := range composites {
+		s, err := typeSchema(r, spec.Schema, "composite", spec.Name)
+		if err != nil {
+			return err
+		}
+		c := &CompositeType{T: spec.Name, Schema: s}
+		for _, f := range spec.Fields {
+			if f.Type == nil {
+				return fmt.Errorf("postgres: missing type for field %q in composite %q", f.Name, spec.Name)
+			}
+			t, err := fieldType(r, f.Type)
+			if err != nil {
+				return fmt.Errorf("postgres: composite %q: %w", spec.Name, err)
+			}
+			c.Fields = append(c.Fields, &CompositeField{Name: f.Name, Type: t})
+		}
+		s.AddObjects(c)
+	}
+	return nil
+}
+
+// typeSchema returns the schema of the type object by its reference.
+func typeSchema(r *schema.Realm, ref *schemahcl.Ref, kind, name string) (*schema.Schema, error) {
+	n, err := specutil.SchemaName(ref)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: %s %q: %w", kind, name, err)
+	}
+	s, ok := r.Schema(n)
+	if !ok {
+		return nil, fmt.Errorf("postgres: schema %q not found for %s %q", n, kind, name)
+	}
+	return s, nil
+}
+
+// fieldType converts the type of composite field. Fields are allowed to reference domain types.
+func fieldType(r *schema.Realm, t *schemahcl.Type) (schema.Type, error) {
+	if t.IsRef {
+		return resolveTypeRef(r, t)
+	}
+	return convertColumnType(&sqlspec.Column{Type: t})
+}
+
+// convertTypeRefs sets the types of the columns that reference domain or composite types.
+func convertTypeRefs(tbls []*sqlspec.Table, r *schema.Realm) error {
+	for _, spec := range tbls {
+		for _, col := range spec.Columns {
+			if !col.Type.IsRef || strings.HasPrefix(col.Type.T, "$enum.") {
+				continue
+			}
+			n, err := specutil.SchemaName(spec.Schema)
+			if err != nil {
+				return err
+			}
+			s, ok := r.Schema(n)
+			if !ok {
+				return fmt.Errorf("postgres: schema %q not found for table %q", n, spec.Name)
+			}
+			t, ok := s.Table(spec.Name)
+			if !ok {
+				return fmt.Errorf("postgres: table %q not found in schema %q", spec.Name, s.Name)
+			}
+			c, ok := t.Column(col.Name)
+			if !ok {
+				return fmt.Errorf("postgres: column %q not found in table %q", col.Name, t.Name)
+			}
+			if c.Type.Type, err = resolveTypeRef(r, col.Type); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolveTypeRef returns the domain or composite type referenced by the given type.
+func resolveTypeRef(r *schema.Realm, ref *schemahcl.Type) (schema.Type, error) {
+	var kind, name string
+	switch {
+	case strings.HasPrefix(ref.T, "$domain."):
+		kind, name = "domain", strings.TrimPrefix(ref.T, "$domain.")
+	case strings.HasPrefix(ref.T, "$composite."):
+		kind, name = "composite", strings.TrimPrefix(ref.T, "$composite.")
+	default:
+		return nil, fmt.Errorf("postgres: unexpected type reference %q", ref.T)
+	}
+	for _, s := range r.Schemas {
+		for _, o := range s.Objects {
+			switch t := o.(type) {
+			case *DomainType:
+				if kind == "domain" && t.T == name {
+					return t, nil
+				}
+			case *CompositeType:
+				if kind == "composite" && t.T == name {
+					return t, nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("postgres: %s %q not found", kind, name)
+}
+
+// typeSpecs converts the domain and composite types of the given schema into specs.
+func typeSpecs(s *schema.Schema) ([]*domainSpec, []*compositeSpec, error) {
+	var (
+		domains    []*domainSpec
+		composites []*compositeSpec
+	)
+	for _, o := range s.Objects {
+		switch t := o.(type) {
+		case *DomainType:
+			c, err := specutil.FromColumn(domainColumn(t), columnTypeSpec)
+			if err != nil {
+				return nil, nil, fmt.Errorf("postgres: domain %q: %w", t.T, err)
+			}
+			spec := &domainSpec{Name: t.T, Schema: specutil.SchemaRef(s.Name), Type: c.Type, Null: c.Null, Default: c.Default}
+			for _, c := range t.Checks {
+				spec.Checks = append(spec.Checks, &sqlspec.Check{Name: c.Name, Expr: c.Expr})
+			}
+			domains = append(domains, spec)
+		case *CompositeType:
+			spec := &compositeSpec{Name: t.T, Schema: specutil.SchemaRef(s.Name)}
+			for _, f := range t.Fields {
+				c, err := columnTypeSpec(f.Type)
+				if err != nil {
+					return nil, nil, fmt.Errorf("postgres: composite %q: %w", t.T, err)
+				}
+				spec.Fields = append(spec.Fields, &fieldSpec{Name: f.Name, Type: c.Type})
+			}
+			composites = append(composites, spec)
+		}
+	}
+	return domains, composites, nil
+}
+
+// convertExtensions converts the extension specs into Extensions and
+// adds them to their schemas in the realm.
+func convertExtensions(specs []*extensionSpec, r *schema.Realm) error {
+	for _, spec := range specs {
+		n, err := specutil.SchemaName(spec.Schema)
+		if err != nil {
+			return fmt.Errorf("postgres: extension %q: %w", spec.Name, err)
+		}
+		s, ok := r.Schema(n)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q not found for extension %q", n, spec.Name)
+		}
+		s.AddObjects(&Extension{Name: spec.Name, Schema: s, Version: spec.Version})
+	}
+	return nil
+}
+
+// extensionSpecs converts the extensions of the given schema into extension specs.
+func extensionSpecs(s *schema.Schema) []*extensionSpec {
+	var specs []*extensionSpec
+	for _, o := range s.Objects {
+		if e, ok := o.(*Extension); ok {
+			specs = append(specs, &extensionSpec{Name: e.Name, Schema: specutil.SchemaRef(s.Name), Version: e.Version})
+		}
+	}
+	return specs
+}
+
+// privileges lists the privileges that can be granted on schemas, tables and columns.
+var privileges = []string{
+	"SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE",
+	"REFERENCES", "TRIGGER", "USAGE", "CREATE",
+}
+
+// roleAttrs returns the role options that are stored as extra
+// attributes of the role spec, and the fields they map to.
+func roleAttrs(r *Role) []struct {
+	k string
+	v *bool
+} {
+	return []struct {
+		k string
+		v *bool
+	}{
+		{k: "login", v: &r.Login},
+		{k: "superuser", v: &r.Superuser},
+		{k: "create_db", v: &r.CreateDB},
+		{k: "create_role", v: &r.CreateRole},
+	}
+}
+
+// convertRoles converts the role and permission specs into Roles and
+// Permissions, and adds them to the realm objects.
+func convertRoles(roles []*specutil.RoleSpec, perms []*specutil.PermissionSpec, r *schema.Realm) error {
+	for _, spec := range roles {
+		if _, ok := realmRole(r, spec.Name); ok {
+			return fmt.Errorf("postgres: role %q is defined more than once", spec.Name)
+		}
+		role := &Role{Name: spec.Name}
+		for _, a := range roleAttrs(role) {
+			if attr, ok := spec.Attr(a.k); ok {
+				b, err := attr.Bool()
+				if err != nil {
+					return fmt.Errorf("postgres: role %q: %w", spec.Name, err)
+				}
+				*a.v = b
+			}
+		}
+		r.AddObjects(role)
+	}
+	for _, spec := range perms {
+		p, err := convertPermission(spec, r)
+		if err != nil {
+			return err
+		}
+		r.AddObjects(p)
+	}
+	return nil
+}
+
+// convertPermission converts a PermissionSpec into a Permission.
+func convertPermission(spec *specutil.PermissionSpec, r *schema.Realm) (*Permission, error) {
+	g, err := specutil.ConvertGrant(spec, r)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: %w", err)
+	}
+	role, ok := realmRole(r, g.Role)
+	if !ok {
+		return nil, fmt.Errorf("postgres: permission: role %q was not found", g.Role)
+	}
+	return &Permission{
+		Role:       role,
+		Schema:     g.Schema,
+		Table:      g.Table,
+		Column:     g.Column,
+		Privileges: g.Privileges,
+		Grantable:  g.Grantable,
+	}, nil
+}
+
+// roleSpecs converts the roles and permissions of the realm into specs.
+func roleSpecs(r *schema.Realm) ([]*specutil.RoleSpec, []*specutil.PermissionSpec, error) {
+	var (
+		roles []*specutil.RoleSpec
+		perms []*specutil.PermissionSpec
+	)
+	for _, o := range r.Objects {
+		switch o := o.(type) {
+		case *Role:
+			spec := &specutil.RoleSpec{Name: o.Name}
+			for _, a := range roleAttrs(o) {
+				if *a.v {
+					spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.BoolAttr(a.k, true))
+				}
+			}
+			roles = append(roles, spec)
+		case *Permission:
+			spec, err := specutil.FromGrant(r, &specutil.Grant{
+				Role:       o.Role.Name,
+				Schema:     o.Schema,
+				Table:      o.Table,
+				Column:     o.Column,
+				Privileges: o.Privileges,
+				Grantable:  o.Grantable,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			perms = append(perms, spec)
+		}
+	}
+	return roles, perms, nil
+}
+
+// convertSequences converts the sequence specs into Sequences and
+// adds them to their schemas in the realm.
+func convertSequences(specs []*sequenceSpec, r *schema.Realm) error {
+	for _, spec := range specs {
+		n, err := specutil.SchemaName(spec.Schema)
+		if err != nil {
+			return fmt.Errorf("postgres: sequence %q: %w", spec.Name, err)
+		}
+		s, ok := r.Schema(n)
+		if !ok {
+			return fmt.Errorf("postgres: schema %q not found for sequence %q", n, spec.Name)
+		}
+		seq, err := convertSequence(spec, s)
+		if err != nil {
+			return err
+		}
+		s.AddObjects(seq)
+	}
+	return nil
+}
+
+// convertSequence converts a sequenceSpec into a Sequence. Options that
+// are not set are filled with the defaults of the sequence type.
+func convertSequence(spec *sequenceSpec, s *schema.Schema) (*Sequence, error) {
+	seq := &Sequence{Name: spec.Name, Schema: s, Type: defaultSeqType, Increment: defaultSeqIncrement}
+	if spec.Type != nil {
+		switch t := strings.ToLower(spec.Type.T); t {
+		case TypeSmallInt, TypeInt2, TypeInteger, TypeInt, TypeInt4, TypeBigInt, TypeInt8:
+			seq.Type = seqType(t)
+		default:
+			return nil, fmt.Errorf("postgres: unexpected type %q for sequence %q", spec.Type.T, spec.Name)
+		}
+	}
+	for _, o := range []struct {
+		k string
+		v *int64
+	}{
+		{k: "start", v: &seq.Start},
+		{k: "increment", v: &seq.Increment},
+		{k: "min_value", v: &seq.Min},
+		{k: "max_value", v: &seq.Max},
+	} {
+		if a, ok := spec.Attr(o.k); ok {
+			i, err := a.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("postgres: sequence %q: %w", spec.Name, err)
+			}
+			*o.v = i
+		}
+	}
+	if seq.Increment == 0 {
+		return nil, fmt.Errorf("postgres: sequence %q: increment must not be zero", spec.Name)
+	}
+	min, max := seqRange(seq.Type, seq.Increment)
+	if _, ok := spec.Attr("min_value"); !ok {
+		seq.Min = min
+	}
+	if _, ok := spec.Attr("max_value"); !ok {
+		seq.Max = max
+	}
+	if _, ok := spec.Attr("start"); !ok {
+		seq.Start = seqStart(seq)
+	}
+	if a, ok := spec.Attr("cycle"); ok {
+		b, err := a.Bool()
+		if err != nil {
+			return nil, fmt.Errorf("postgres: sequence %q: %w", spec.Name, err)
+		}
+		seq.Cycle = b
+	}
+	if spec.OwnedBy != nil {
+		t, c, err := specutil.ExternalColumnByRef(spec.OwnedBy, s)
+		if err != nil {
+			return nil, fmt.Errorf("postgres: sequence %q owner: %w", spec.Name, err)
+		}
+		seq.OwnedBy = &SequenceOwner{T: t, C: c}
+	}
+	return seq, nil
+}
+
+// sequenceSpecs converts the sequences of the given schema into sequence specs.
+func sequenceSpecs(s *schema.Schema) ([]*sequenceSpec, error) {
+	var specs []*sequenceSpec
+	for _, o := range s.Objects {
+		seq, ok := o.(*Sequence)
+		if !ok {
+			continue
+		}
+		spec, err := fromSequence(seq, s)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// fromSequence returns the spec for representing the sequence. Options
+// that are equal to the defaults of the sequence type are omitted.
+func fromSequence(seq *Sequence, s *schema.Schema) (*sequenceSpec, error) {
+	seq = sequence(seq)
+	spec := &sequenceSpec{Name: seq.Name, Schema: specutil.SchemaRef(s.Name)}
+	if seq.Type != defaultSeqType {
+		t, err := TypeRegistry.Convert(&schema.IntegerType{T: seq.Type})
+		if err != nil {
+			return nil, err
+		}
+		spec.Type = t
+	}
+	base := sequence(&Sequence{Type: seq.Type, Increment: seq.Increment})
+	if seq.Start != base.Start {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.Int64Attr("start", seq.Start))
+	}
+	if seq.Increment != defaultSeqIncrement {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.Int64Attr("increment", seq.Increment))
+	}
+	if seq.Min != base.Min {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.Int64Attr("min_value", seq.Min))
+	}
+	if seq.Max != base.Max {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.Int64Attr("max_value", seq.Max))
+	}
+	if seq.Cycle {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.BoolAttr("cycle", true))
+	}
+	if o := seq.OwnedBy; o != nil {
+		spec.OwnedBy = specutil.ExternalColumnRef(o.C.Name, o.T.Name)
+	}
+	return spec, nil
+}
+
 // enumRef returns a reference string to the given enum name.
 func enumRef(n string) *schemahcl.Ref {
 	return &schemahcl.Ref{
@@ -406,6 +1247,13 @@ func schemaSpec(schem *schema.Schema) (*doc, error) {
 	}
 	d.Schemas = []*sqlspec.Schema{s}
 	d.Tables = tbls
+	if d.Sequences, err = sequenceSpecs(schem); err != nil {
+		return nil, err
+	}
+	d.Extensions = extensionSpecs(schem)
+	if d.Domains, d.Composites, err = typeSpecs(schem); err != nil {
+		return nil, err
+	}
 
 	enums := make(map[string]struct{})
 	for _, t := range schem.Tables {
@@ -432,18 +1280,44 @@ func tableSpec(table *schema.Table) (*sqlspec.Table, error) {
 		columnSpec,
 		specutil.FromPrimaryKey,
 		indexSpec,
-		specutil.FromForeignKey,
-		specutil.FromCheck,
+		foreignKeySpec,
+		checkSpec,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if p := (PartitionOf{}); sqlx.Has(table.Attrs, &p) && p.Parent != nil {
+		spec.Extra.Children = append(spec.Extra.Children, fromPartitionOf(table, p))
+	}
 	if p := (Partition{}); sqlx.Has(table.Attrs, &p) {
 		spec.Extra.Children = append(spec.Extra.Children, fromPartition(p))
 	}
+	spec.Extra.Children = append(spec.Extra.Children, fromSecurity(table)...)
+	return spec, nil
+}
+
+// foreignKeySpec converts a schema.ForeignKey to a sqlspec.ForeignKey.
+func foreignKeySpec(fk *schema.ForeignKey) (*sqlspec.ForeignKey, error) {
+	spec, err := specutil.FromForeignKey(fk)
+	if err != nil {
+		return nil, err
+	}
+	spec.Extra.Attrs = append(spec.Extra.Attrs, deferrableSpec(fk.Attrs)...)
+	if sqlx.Has(fk.Attrs, &NotValid{}) {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.BoolAttr("not_valid", true))
+	}
 	return spec, nil
 }
 
+// checkSpec converts a schema.Check to a sqlspec.Check.
+func checkSpec(c *schema.Check) *sqlspec.Check {
+	spec := specutil.FromCheck(c)
+	if sqlx.Has(c.Attrs, &NotValid{}) {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.BoolAttr("not_valid", true))
+	}
+	return spec
+}
+
 func indexSpec(idx *schema.Index) (*sqlspec.Index, error) {
 	spec, err := specutil.FromIndex(idx)
 	if err != nil {
@@ -459,6 +1333,27 @@ func indexSpec(idx *schema.Index) (*sqlspec.Index, error) {
 	if p, ok := indexStorageParams(idx.Attrs); ok {
 		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.Int64Attr("page_per_range", p.PagesPerRange))
 	}
+	if sqlx.Has(idx.Attrs, &IndexConcurrently{}) {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.BoolAttr("concurrently", true))
+	}
+	spec.Extra.Attrs = append(spec.Extra.Attrs, deferrableSpec(idx.Attrs)...)
+	if isExclude(idx.Attrs) {
+		spec.Extra.Attrs = append(spec.Extra.Attrs, specutil.BoolAttr("exclude", true))
+		// The operators of the exclusion constraint are
+		// defined on the parts, and therefore, columns are
+		// written as parts.
+		if len(spec.Columns) > 0 {
+			for _, c := range spec.Columns {
+				spec.Parts = append(spec.Parts, &sqlspec.IndexPart{Column: c})
+			}
+			spec.Columns = nil
+		}
+		for i, p := range idx.Parts {
+			if op := (ExcludeOp{}); sqlx.Has(p.Attrs, &op) {
+				spec.Parts[i].Extra.Attrs = append(spec.Parts[i].Extra.Attrs, specutil.StrAttr("op", op.Op))
+			}
+		}
+	}
 	return spec, nil
 }
 
@@ -505,6 +1400,13 @@ func columnTypeSpec(t schema.Type) (*sqlspec.Column, error) {
 			IsRef: true,
 		}}, nil
 	}
+	// Domain and composite types are referenced by their definitions.
+	switch t := t.(type) {
+	case *DomainType:
+		return &sqlspec.Column{Type: &schemahcl.Type{T: "$domain." + t.T, IsRef: true}}, nil
+	case *CompositeType:
+		return &sqlspec.Column{Type: &schemahcl.Type{T: "$composite." + t.T, IsRef: true}}, nil
+	}
 	st, err := TypeRegistry.Convert(t)
 	if err != nil {
 		return nil, err
@@ -409,6 +409,77 @@ table "logs" {
 		`), &schema.Schema{}, nil)
 		require.EqualError(t, err, `multiple definitions for logs.partition, use "columns" or "by"`)
 	})
+
+	t.Run("PartitionOf", func(t *testing.T) {
+		var (
+			s = &schema.Schema{}
+			f = `
+schema "test" {}
+table "logs" {
+	schema = schema.test
+	column "name" {
+		type = text
+	}
+	column "level" {
+		type = int
+	}
+	partition {
+		type = LIST
+		columns = [column.name]
+	}
+}
+table "logs_a" {
+	schema = schema.test
+	partition_of {
+		table = table.logs
+		bound = "FOR VALUES IN ('a')"
+	}
+	partition {
+		type = RANGE
+		columns = [table.logs.column.level]
+	}
+}
+table "logs_a_1" {
+	schema = schema.test
+	partition_of {
+		table = table.logs_a
+		bound = "DEFAULT"
+	}
+}
+`
+		)
+		err := EvalHCLBytes([]byte(f), s, nil)
+		require.NoError(t, err)
+		logs, ok := s.Table("logs")
+		require.True(t, ok)
+		a, ok := s.Table("logs_a")
+		require.True(t, ok)
+		require.Empty(t, a.Columns)
+		require.Equal(t, []schema.Attr{
+			&PartitionOf{Parent: logs, Bound: "FOR VALUES IN ('a')"},
+			&Partition{T: PartitionTypeRange, Parts: []*PartitionPart{{C: logs.Columns[1]}}},
+		}, a.Attrs)
+		a1, ok := s.Table("logs_a_1")
+		require.True(t, ok)
+		require.Equal(t, []schema.Attr{&PartitionOf{Parent: a, Bound: "DEFAULT"}}, a1.Attrs)
+
+		err = EvalHCLBytes([]byte(`
+			schema "test" {}
+			table "logs" {
+				schema = schema.test
+				column "name" { type = text }
+			}
+			table "logs_a" {
+				schema = schema.test
+				column "name" { type = text }
+				partition_of {
+					table = table.logs
+					bound = "DEFAULT"
+				}
+			}
+		`), &schema.Schema{}, nil)
+		require.EqualError(t, err, `postgres: partition "logs_a" cannot define columns, indexes or constraints (inherited from its parent table)`)
+	})
 }
 
 func TestMarshalSpec_Partitioned(t *testing.T) {
@@ -462,6 +533,267 @@ schema "test" {
 	})
 }
 
+func TestMarshalSpec_PartitionOf(t *testing.T) {
+	c := schema.NewStringColumn("name", "text")
+	logs := schema.NewTable("logs").AddColumns(c).AddAttrs(&Partition{T: PartitionTypeList, Parts: []*PartitionPart{{C: c}}})
+	s := schema.New("test").
+		AddTables(
+			logs,
+			schema.NewTable("logs_a").AddAttrs(&PartitionOf{Parent: logs, Bound: "FOR VALUES IN ('a')"}),
+		)
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, `table "logs" {
+  schema = schema.test
+  column "name" {
+    null = false
+    type = text
+  }
+  partition {
+    type    = LIST
+    columns = [column.name]
+  }
+}
+table "logs_a" {
+  schema = schema.test
+  partition_of {
+    table = table.logs
+    bound = "FOR VALUES IN ('a')"
+  }
+}
+schema "test" {
+}
+`, string(buf))
+	got := &schema.Schema{}
+	require.NoError(t, EvalHCLBytes(buf, got, nil))
+	a, ok := got.Table("logs_a")
+	require.True(t, ok)
+	require.Equal(t, "logs", a.Attrs[0].(*PartitionOf).Parent.Name)
+}
+
+func TestMarshalSpec_Policies(t *testing.T) {
+	s := schema.New("test").
+		AddTables(
+			schema.NewTable("users").
+				AddColumns(schema.NewIntColumn("tenant", "int")).
+				AddAttrs(
+					&RowSecurity{Enabled: true, Forced: true},
+					&Policy{Name: "tenant", As: PolicyPermissive, For: PolicyForAll, Using: "(tenant = 1)"},
+					&Policy{Name: "insert", As: PolicyRestrictive, For: PolicyForInsert, To: []string{"app", "admin"}, Check: "(tenant > 0)"},
+				),
+		)
+	buf, err := MarshalHCL(s)
+	require.NoError(t, err)
+	require.Equal(t, `table "users" {
+  schema = schema.test
+  column "tenant" {
+    null = false
+    type = int
+  }
+  row_security {
+    enabled = true
+    forced  = true
+  }
+  policy "tenant" {
+    using = "(tenant = 1)"
+  }
+  policy "insert" {
+    as    = RESTRICTIVE
+    for   = INSERT
+    to    = ["admin", "app"]
+    check = "(tenant > 0)"
+  }
+}
+schema "test" {
+}
+`, string(buf))
+	got := &schema.Schema{}
+	require.NoError(t, EvalHCLBytes(buf, got, nil))
+	users, ok := got.Table("users")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{
+		&RowSecurity{Enabled: true, Forced: true},
+		&Policy{Name: "tenant", Using: "(tenant = 1)"},
+		&Policy{Name: "insert", As: PolicyRestrictive, For: PolicyForInsert, To: []string{"admin", "app"}, Check: "(tenant > 0)"},
+	}, users.Attrs)
+}
+
+func TestMarshalSpec_ExcludeConstraint(t *testing.T) {
+	t1 := schema.NewTable("bookings").AddColumns(schema.NewIntColumn("room", "int"))
+	t1.AddIndexes(schema.NewIndex("bookings_overlap").
+		AddAttrs(&IndexType{T: IndexTypeGiST}, &ConType{T: "x"}, &IndexPredicate{P: "room > 0"}).
+		AddParts(
+			schema.NewColumnPart(t1.Columns[0]).AddAttrs(&ExcludeOp{Op: "="}),
+			schema.NewExprPart(&schema.RawExpr{X: "tsrange(lower, upper)"}).AddAttrs(&ExcludeOp{Op: "&&"}),
+		))
+	buf, err := MarshalHCL(schema.New("test").AddTables(t1))
+	require.NoError(t, err)
+	require.Equal(t, `table "bookings" {
+  schema = schema.test
+  column "room" {
+    null = false
+    type = int
+  }
+  index "bookings_overlap" {
+    type    = GIST
+    where   = "room > 0"
+    exclude = true
+    on {
+      column = column.room
+      op     = "="
+    }
+    on {
+      expr = "tsrange(lower, upper)"
+      op   = "&&"
+    }
+  }
+}
+schema "test" {
+}
+`, string(buf))
+	got := &schema.Schema{}
+	require.NoError(t, EvalHCLBytes(buf, got, nil))
+	idx := got.Tables[0].Indexes[0]
+	require.True(t, isExclude(idx.Attrs))
+	require.Equal(t, []schema.Attr{&ExcludeOp{Op: "="}}, idx.Parts[0].Attrs)
+	require.Equal(t, []schema.Attr{&ExcludeOp{Op: "&&"}}, idx.Parts[1].Attrs)
+
+	err = EvalHCLBytes([]byte(`
+table "bookings" {
+  schema = schema.test
+  column "room" {
+    type = int
+  }
+  index "bookings_overlap" {
+    type    = GIST
+    exclude = true
+    columns = [column.room]
+  }
+}
+schema "test" {
+}
+`), &schema.Schema{}, nil)
+	require.EqualError(t, err, `missing operator for exclusion constraint "bookings_overlap" at position 0`)
+}
+
+func TestMarshalSpec_IndexConcurrently(t *testing.T) {
+	t1 := schema.NewTable("users").AddColumns(schema.NewStringColumn("name", "text"))
+	t1.AddIndexes(schema.NewIndex("users_name").AddColumns(t1.Columns[0]).AddAttrs(&IndexConcurrently{}))
+	buf, err := MarshalHCL(schema.New("test").AddTables(t1))
+	require.NoError(t, err)
+	require.Equal(t, `table "users" {
+  schema = schema.test
+  column "name" {
+    null = false
+    type = text
+  }
+  index "users_name" {
+    columns      = [column.name]
+    concurrently = true
+  }
+}
+schema "test" {
+}
+`, string(buf))
+	got := &schema.Schema{}
+	require.NoError(t, EvalHCLBytes(buf, got, nil))
+	require.Equal(t, []schema.Attr{&IndexConcurrently{}}, got.Tables[0].Indexes[0].Attrs)
+}
+
+func TestMarshalSpec_NotValid(t *testing.T) {
+	users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"))
+	pets := schema.NewTable("pets").AddColumns(schema.NewIntColumn("owner_id", "int"))
+	pets.AddForeignKeys(schema.NewForeignKey("owner_id").AddColumns(pets.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0]).AddAttrs(&NotValid{}))
+	pets.AddChecks(schema.NewCheck().SetName("positive_owner").SetExpr("owner_id > 0").AddAttrs(&NotValid{}))
+	buf, err := MarshalHCL(schema.New("test").AddTables(users, pets))
+	require.NoError(t, err)
+	require.Equal(t, `table "users" {
+  schema = schema.test
+  column "id" {
+    null = false
+    type = int
+  }
+}
+table "pets" {
+  schema = schema.test
+  column "owner_id" {
+    null = false
+    type = int
+  }
+  foreign_key "owner_id" {
+    columns     = [column.owner_id]
+    ref_columns = [table.users.column.id]
+    not_valid   = true
+  }
+  check "positive_owner" {
+    expr      = "owner_id > 0"
+    not_valid = true
+  }
+}
+schema "test" {
+}
+`, string(buf))
+	got := &schema.Schema{}
+	require.NoError(t, EvalHCLBytes(buf, got, nil))
+	tp, ok := got.Table("pets")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&NotValid{}}, tp.ForeignKeys[0].Attrs)
+	require.Equal(t, []schema.Attr{&NotValid{}}, tp.Attrs[0].(*schema.Check).Attrs)
+}
+
+func TestMarshalSpec_Deferrable(t *testing.T) {
+	users := schema.NewTable("users").AddColumns(schema.NewIntColumn("id", "int"), schema.NewIntColumn("tag", "int"))
+	users.AddIndexes(schema.NewUniqueIndex("users_tag").AddColumns(users.Columns[1]).AddAttrs(&Deferrable{}))
+	users.AddForeignKeys(schema.NewForeignKey("parent").AddColumns(users.Columns[0]).SetRefTable(users).AddRefColumns(users.Columns[0]).AddAttrs(&Deferrable{InitiallyDeferred: true}))
+	buf, err := MarshalHCL(schema.New("test").AddTables(users))
+	require.NoError(t, err)
+	require.Equal(t, `table "users" {
+  schema = schema.test
+  column "id" {
+    null = false
+    type = int
+  }
+  column "tag" {
+    null = false
+    type = int
+  }
+  foreign_key "parent" {
+    columns            = [column.id]
+    ref_columns        = [column.id]
+    deferrable         = true
+    initially_deferred = true
+  }
+  index "users_tag" {
+    unique     = true
+    columns    = [column.tag]
+    deferrable = true
+  }
+}
+schema "test" {
+}
+`, string(buf))
+	got := &schema.Schema{}
+	require.NoError(t, EvalHCLBytes(buf, got, nil))
+	require.Equal(t, []schema.Attr{&Deferrable{}}, got.Tables[0].Indexes[0].Attrs)
+	require.Equal(t, []schema.Attr{&Deferrable{InitiallyDeferred: true}}, got.Tables[0].ForeignKeys[0].Attrs)
+
+	err = EvalHCLBytes([]byte(`
+table "users" {
+  schema = schema.test
+  column "tag" {
+    type = int
+  }
+  index "users_tag" {
+    columns    = [column.tag]
+    deferrable = true
+  }
+}
+schema "test" {
+}
+`), &schema.Schema{}, nil)
+	require.EqualError(t, err, `index "users_tag" must be unique or an exclusion constraint to be deferrable`)
+}
+
 func TestMarshalSpec_IndexPredicate(t *testing.T) {
 	s := &schema.Schema{
 		Name: "test",
@@ -720,6 +1052,297 @@ enum "account_type" {
 	require.EqualValues(t, expected, string(buf))
 }
 
+func TestMarshalSpec_Sequence(t *testing.T) {
+	s := schema.New("test").
+		AddTables(
+			schema.NewTable("users").
+				AddColumns(schema.NewIntColumn("id", "bigint")),
+		)
+	s.AddObjects(
+		&Sequence{Name: "s1", Schema: s, Type: "bigint", Start: 1, Increment: 1, Min: 1, Max: 9223372036854775807},
+		&Sequence{Name: "s2", Schema: s, Type: "integer", Start: -1, Increment: -2, Min: -1000, Max: -1, Cycle: true, OwnedBy: &SequenceOwner{T: s.Tables[0], C: s.Tables[0].Columns[0]}},
+	)
+	buf, err := MarshalSpec(s, hclState)
+	require.NoError(t, err)
+	const expected = `table "users" {
+  schema = schema.test
+  column "id" {
+    null = false
+    type = bigint
+  }
+}
+schema "test" {
+}
+sequence "s1" {
+  schema = schema.test
+}
+sequence "s2" {
+  schema    = schema.test
+  type      = integer
+  owned_by  = table.users.column.id
+  increment = -2
+  min_value = -1000
+  cycle     = true
+}
+`
+	require.EqualValues(t, expected, string(buf))
+}
+
+func TestUnmarshalSpec_Sequence(t *testing.T) {
+	var (
+		s schema.Schema
+		f = `
+schema "test" {}
+table "users" {
+	schema = schema.test
+	column "id" {
+		type = bigint
+		default = sql("nextval('test.s2')")
+	}
+}
+sequence "s1" {
+	schema = schema.test
+}
+sequence "s2" {
+	schema = schema.test
+	type = smallint
+	start = 10
+	increment = 5
+	max_value = 100
+	cycle = true
+	owned_by = table.users.column.id
+}
+`
+	)
+	err := EvalHCLBytes([]byte(f), &s, nil)
+	require.NoError(t, err)
+	require.Len(t, s.Objects, 2)
+	require.Equal(t, &Sequence{Name: "s1", Schema: &s, Type: "bigint", Start: 1, Increment: 1, Min: 1, Max: 9223372036854775807}, s.Objects[0])
+	require.Equal(t, &Sequence{Name: "s2", Schema: &s, Type: "smallint", Start: 10, Increment: 5, Min: 1, Max: 100, Cycle: true, OwnedBy: &SequenceOwner{T: s.Tables[0], C: s.Tables[0].Columns[0]}}, s.Objects[1])
+
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+sequence "s1" {
+	schema = schema.test
+	type = text
+}
+`), &s, nil)
+	require.Error(t, err)
+}
+
+func TestMarshalSpec_Extension(t *testing.T) {
+	s := schema.New("test")
+	s.AddObjects(
+		&Extension{Name: "citext", Schema: s, Version: "1.6"},
+		&Extension{Name: "pg_trgm", Schema: s},
+	)
+	buf, err := MarshalSpec(s, hclState)
+	require.NoError(t, err)
+	const expected = `schema "test" {
+}
+extension "citext" {
+  schema  = schema.test
+  version = "1.6"
+}
+extension "pg_trgm" {
+  schema = schema.test
+}
+`
+	require.EqualValues(t, expected, string(buf))
+
+	var got schema.Schema
+	err = EvalHCLBytes(buf, &got, nil)
+	require.NoError(t, err)
+	require.Equal(t, []schema.Object{
+		&Extension{Name: "citext", Schema: &got, Version: "1.6"},
+		&Extension{Name: "pg_trgm", Schema: &got},
+	}, got.Objects)
+
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+extension "citext" {
+	schema = schema.public
+}
+`), &got, nil)
+	require.Error(t, err)
+}
+
+func TestMarshalSpec_Roles(t *testing.T) {
+	var (
+		r     = schema.NewRealm(schema.New("public"), schema.New("other"))
+		users = schema.NewTable("users").AddColumns(schema.NewStringColumn("email", "text"))
+		app   = &Role{Name: "app", Login: true}
+		admin = &Role{Name: "admin", Login: true, CreateDB: true, CreateRole: true}
+	)
+	r.Schemas[0].AddTables(users)
+	r.Schemas[1].AddTables(schema.NewTable("users"))
+	r.AddObjects(
+		app, admin,
+		&Permission{Role: app, Schema: r.Schemas[0], Privileges: []string{"USAGE"}},
+		&Permission{Role: app, Schema: r.Schemas[0], Table: users, Privileges: []string{"SELECT", "INSERT"}},
+		&Permission{Role: app, Schema: r.Schemas[0], Table: users, Column: users.Columns[0], Privileges: []string{"UPDATE"}, Grantable: true},
+	)
+	buf, err := MarshalSpec(r, hclState)
+	require.NoError(t, err)
+	const expected = `table "public" "users" {
+  schema = schema.public
+  column "email" {
+    null = false
+    type = text
+  }
+}
+table "other" "users" {
+  schema = schema.other
+}
+schema "public" {
+}
+schema "other" {
+}
+role "app" {
+  login = true
+}
+role "admin" {
+  login       = true
+  create_db   = true
+  create_role = true
+}
+permission {
+  to         = role.app
+  on         = schema.public
+  privileges = [USAGE]
+}
+permission {
+  to         = role.app
+  on         = table.public.users
+  privileges = [SELECT, INSERT]
+}
+permission {
+  to         = role.app
+  on         = table.public.users.column.email
+  privileges = [UPDATE]
+  grantable  = true
+}
+`
+	require.EqualValues(t, expected, string(buf))
+
+	var got schema.Realm
+	err = EvalHCLBytes(buf, &got, nil)
+	require.NoError(t, err)
+	require.Len(t, got.Objects, 5)
+	gotApp := got.Objects[0].(*Role)
+	require.Equal(t, app, gotApp)
+	require.Equal(t, admin, got.Objects[1])
+	public, _ := got.Schema("public")
+	gotUsers, _ := public.Table("users")
+	require.Equal(t, []schema.Object{
+		&Permission{Role: gotApp, Schema: public, Privileges: []string{"USAGE"}},
+		&Permission{Role: gotApp, Schema: public, Table: gotUsers, Privileges: []string{"SELECT", "INSERT"}},
+		&Permission{Role: gotApp, Schema: public, Table: gotUsers, Column: gotUsers.Columns[0], Privileges: []string{"UPDATE"}, Grantable: true},
+	}, got.Objects[2:])
+
+	// Unqualified table references are resolved from all realm schemas.
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+schema "other" {}
+table "users" {
+	schema = schema.public
+}
+role "app" {}
+permission {
+	to = role.app
+	on = table.users
+	privileges = [SELECT]
+}
+`), &schema.Realm{}, nil)
+	require.NoError(t, err)
+	err = EvalHCLBytes([]byte(`
+schema "public" {}
+role "app" {}
+permission {
+	to = role.unknown
+	on = schema.public
+	privileges = [USAGE]
+}
+`), &schema.Realm{}, nil)
+	require.Error(t, err)
+}
+
+func TestMarshalSpec_Types(t *testing.T) {
+	s := schema.New("test")
+	email := &DomainType{T: "email", Schema: s, Base: &schema.StringType{T: "text"}, Default: &schema.Literal{V: "'a@b'"}, Checks: []*schema.Check{{Name: "email_check", Expr: "VALUE ~~ '%@%'"}}}
+	address := &CompositeType{T: "address", Schema: s, Fields: []*CompositeField{{Name: "street", Type: &schema.StringType{T: "text"}}, {Name: "email", Type: email}}}
+	s.AddObjects(email, address)
+	s.AddTables(
+		schema.NewTable("users").
+			AddColumns(
+				&schema.Column{Name: "email", Type: &schema.ColumnType{Type: email}},
+				&schema.Column{Name: "address", Type: &schema.ColumnType{Type: address, Null: true}},
+			),
+	)
+	buf, err := MarshalSpec(s, hclState)
+	require.NoError(t, err)
+	const expected = `table "users" {
+  schema = schema.test
+  column "email" {
+    null = false
+    type = domain.email
+  }
+  column "address" {
+    null = true
+    type = composite.address
+  }
+}
+schema "test" {
+}
+domain "email" {
+  schema  = schema.test
+  type    = text
+  null    = false
+  default = "a@b"
+  check "email_check" {
+    expr = "VALUE ~~ '%@%'"
+  }
+}
+composite "address" {
+  schema = schema.test
+  field "street" {
+    type = text
+  }
+  field "email" {
+    type = domain.email
+  }
+}
+`
+	require.EqualValues(t, expected, string(buf))
+
+	var got schema.Schema
+	err = EvalHCLBytes(buf, &got, nil)
+	require.NoError(t, err)
+	require.Len(t, got.Objects, 2)
+	d, c := got.Objects[0].(*DomainType), got.Objects[1].(*CompositeType)
+	require.Equal(t, "email", d.T)
+	require.Equal(t, &schema.StringType{T: "text"}, d.Base)
+	require.False(t, d.Null)
+	require.Equal(t, &schema.Literal{V: "'a@b'"}, d.Default)
+	require.Equal(t, []*schema.Check{{Name: "email_check", Expr: "VALUE ~~ '%@%'"}}, d.Checks)
+	require.Equal(t, "address", c.T)
+	require.Len(t, c.Fields, 2)
+	require.Same(t, d, c.Fields[1].Type)
+	require.Same(t, d, got.Tables[0].Columns[0].Type.Type)
+	require.Same(t, c, got.Tables[0].Columns[1].Type.Type)
+
+	err = EvalHCLBytes([]byte(`
+schema "test" {}
+table "users" {
+	schema = schema.test
+	column "email" {
+		type = domain.email
+	}
+}
+`), &got, nil)
+	require.Error(t, err)
+}
+
 func TestMarshalSpec_TimePrecision(t *testing.T) {
 	s := schema.New("test").
 		AddTables(
@@ -70,6 +70,12 @@ func (s *Schema) AddTables(tables ...*Table) *Schema {
 	return s
 }
 
+// AddObjects adds the given objects to the schema.
+func (s *Schema) AddObjects(objs ...Object) *Schema {
+	s.Objects = append(s.Objects, objs...)
+	return s
+}
+
 // NewRealm creates a new Realm.
 func NewRealm(schemas ...*Schema) *Realm {
 	r := &Realm{Schemas: schemas}
@@ -105,6 +111,12 @@ func (r *Realm) UnsetCollation() *Realm {
 	return r
 }
 
+// AddObjects adds the given objects to the realm.
+func (r *Realm) AddObjects(objs ...Object) *Realm {
+	r.Objects = append(r.Objects, objs...)
+	return r
+}
+
 // NewTable creates a new Table.
 func NewTable(name string) *Table {
 	return &Table{Name: name}
@@ -721,6 +733,12 @@ func (f *ForeignKey) SetOnDelete(o ReferenceOption) *ForeignKey {
 	return f
 }
 
+// AddAttrs adds additional attributes to the foreign key.
+func (f *ForeignKey) AddAttrs(attrs ...Attr) *ForeignKey {
+	f.Attrs = append(f.Attrs, attrs...)
+	return f
+}
+
 // replaceOrAppend searches an attribute of the same type as v in
 // the list and replaces it. Otherwise, v is appended to the list.
 func replaceOrAppend(attrs *[]Attr, v Attr) {
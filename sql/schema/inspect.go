@@ -43,6 +43,15 @@ const (
 	// InspectTables enables schema tables inspection including
 	// all its child resources (e.g. columns or indexes).
 	InspectTables
+
+	// InspectObjects enables inspection of schema objects that
+	// are not tables (e.g. sequences or extensions).
+	InspectObjects
+
+	// InspectRoles enables inspection of realm-level roles (or users)
+	// and their permissions. Unlike the other modes, it is not enabled
+	// by default, as it requires access to the system catalogs.
+	InspectRoles
 )
 
 // Is reports whether the given mode is enabled.
@@ -56,7 +65,12 @@ type (
 		Mode InspectMode
 
 		// Tables to inspect. Empty means all tables in the schema.
+		// Entries may be glob patterns (e.g. "audit_*"), and entries
+		// prefixed with "!" exclude the tables they match.
 		Tables []string
+
+		// Exclude defines a list of glob patterns of tables to skip.
+		Exclude []string
 	}
 
 	// InspectRealmOption describes options for RealmInspector.
@@ -66,7 +80,21 @@ type (
 		Mode InspectMode
 
 		// Schemas to inspect. Empty means all schemas in the realm.
+		// Entries may be glob patterns (e.g. "app_*"), or qualified
+		// table patterns for selecting only some tables of a schema
+		// (e.g. "public.audit_*"). Entries prefixed with "!" exclude
+		// the schemas or tables they match.
 		Schemas []string
+
+		// Exclude defines a list of glob patterns of schemas or tables to skip.
+		// Table patterns are qualified with their schema, e.g. "public.tmp_*".
+		Exclude []string
+
+		// Concurrency defines the maximum number of schemas that are inspected
+		// concurrently. Zero or one means that schemas are inspected sequentially.
+		// Drivers support it only if they were opened using a connection pool
+		// (e.g. *sql.DB), and it is bounded by the pool's maximum open connections.
+		Concurrency int
 	}
 
 	// Inspector is the interface implemented by the different database
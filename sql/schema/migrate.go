@@ -7,6 +7,10 @@ package schema
 import (
 	"context"
 	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
 	"time"
 )
 
@@ -160,6 +164,22 @@ type (
 		Change   ChangeKind
 	}
 
+	// AddObject describes a generic object creation change.
+	AddObject struct {
+		O Object
+	}
+
+	// DropObject describes a generic object removal change.
+	DropObject struct {
+		O Object
+	}
+
+	// ModifyObject describes a generic object modification change.
+	// Unlike tables, object changes are computed by the drivers.
+	ModifyObject struct {
+		From, To Object
+	}
+
 	// AddAttr describes an attribute addition.
 	AddAttr struct {
 		A Attr
@@ -263,6 +283,173 @@ type Differ interface {
 	TableDiff(from, to *Table) ([]Change, error)
 }
 
+// OptionsDiffer is an optional interface implemented by Differs that
+// support controlling the diffing process using DiffOptions.
+type OptionsDiffer interface {
+	Differ
+
+	// RealmDiffWith is like RealmDiff, but accepts diff options.
+	RealmDiffWith(from, to *Realm, opts ...DiffOption) ([]Change, error)
+
+	// SchemaDiffWith is like SchemaDiff, but accepts diff options.
+	SchemaDiffWith(from, to *Schema, opts ...DiffOption) ([]Change, error)
+
+	// TableDiffWith is like TableDiff, but accepts diff options.
+	TableDiffWith(from, to *Table, opts ...DiffOption) ([]Change, error)
+}
+
+// RealmDiff returns the diff between the two realms using the given Differ and
+// options. An error is returned if options were given, but the Differ does not
+// implement the OptionsDiffer interface.
+func RealmDiff(d Differ, from, to *Realm, opts ...DiffOption) ([]Change, error) {
+	if od, ok := d.(OptionsDiffer); ok {
+		return od.RealmDiffWith(from, to, opts...)
+	}
+	if len(opts) > 0 {
+		return nil, fmt.Errorf("sql/schema: %T does not support diff options", d)
+	}
+	return d.RealmDiff(from, to)
+}
+
+// SchemaDiff returns the diff between the two schemas using the given Differ and
+// options. An error is returned if options were given, but the Differ does not
+// implement the OptionsDiffer interface.
+func SchemaDiff(d Differ, from, to *Schema, opts ...DiffOption) ([]Change, error) {
+	if od, ok := d.(OptionsDiffer); ok {
+		return od.SchemaDiffWith(from, to, opts...)
+	}
+	if len(opts) > 0 {
+		return nil, fmt.Errorf("sql/schema: %T does not support diff options", d)
+	}
+	return d.SchemaDiff(from, to)
+}
+
+type (
+	// DiffOptions defines the standard configuration for the schema diffing process.
+	DiffOptions struct {
+		// SkipChanges defines a list of change types to skip. For example,
+		// &DropTable{} prevents the differ from suggesting to drop tables.
+		SkipChanges []Change
+
+		// Exclude defines a list of glob patterns for objects to ignore. A pattern
+		// is matched against the qualified name of the object, separated by dots.
+		// For example, "*.tmp_*" ignores the tables that start with "tmp_" in all
+		// schemas, and "public.users.legacy_*" ignores the columns, indexes and
+		// foreign keys of the "users" table that start with "legacy_".
+		Exclude []string
+
+		// Ignore defines the attribute categories (change kinds) to ignore.
+		// For example, ChangeComment ignores the comment changes of all
+		// elements. Supported kinds are ChangeComment, ChangeCharset and
+		// ChangeCollate, as well as column, index and foreign-key kinds.
+		Ignore ChangeKind
+
+		// ExclusiveRoles indicates the desired state defines all roles (or users) of
+		// the database server. By default, only the roles defined in the desired state
+		// and their permissions are managed, as roles are shared by all databases of the
+		// server (or cluster) and might be managed by other tools.
+		ExclusiveRoles bool
+	}
+
+	// DiffOption allows configuring the DiffOptions using functional options.
+	DiffOption func(*DiffOptions)
+)
+
+// NewDiffOptions creates a new DiffOptions from the given configuration.
+func NewDiffOptions(opts ...DiffOption) *DiffOptions {
+	o := &DiffOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// DiffSkipChanges returns a DiffOption that skips the given change types.
+// For example, in order to skip all destructive changes, use:
+//
+//	DiffSkipChanges(&DropSchema{}, &DropTable{}, &DropColumn{}, &DropIndex{}, &DropForeignKey{})
+func DiffSkipChanges(changes ...Change) DiffOption {
+	return func(o *DiffOptions) {
+		o.SkipChanges = append(o.SkipChanges, changes...)
+	}
+}
+
+// DiffExclude returns a DiffOption that ignores the objects matching the given glob patterns.
+func DiffExclude(patterns ...string) DiffOption {
+	return func(o *DiffOptions) {
+		o.Exclude = append(o.Exclude, patterns...)
+	}
+}
+
+// DiffIgnore returns a DiffOption that ignores changes of the given kinds.
+func DiffIgnore(k ChangeKind) DiffOption {
+	return func(o *DiffOptions) {
+		o.Ignore |= k
+	}
+}
+
+// DiffExclusiveRoles returns a DiffOption that drops the roles (and their
+// permissions) that are not defined in the desired state.
+func DiffExclusiveRoles() DiffOption {
+	return func(o *DiffOptions) {
+		o.ExclusiveRoles = true
+	}
+}
+
+// Skipped reports whether the given change should be skipped.
+func (o *DiffOptions) Skipped(c Change) bool {
+	for _, s := range o.SkipChanges {
+		if reflect.TypeOf(c) == reflect.TypeOf(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Excluded reports whether the object with the given qualified
+// name (e.g. schema, table and column) should be ignored.
+func (o *DiffOptions) Excluded(names ...string) bool {
+search:
+	for _, p := range o.Exclude {
+		parts := strings.Split(p, ".")
+		if len(parts) != len(names) {
+			continue
+		}
+		for i := range parts {
+			if ok, _ := path.Match(parts[i], names[i]); !ok {
+				continue search
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Ignored reports whether the attribute change should be ignored, based on
+// its category. For example, a comment change when ChangeComment is ignored.
+func (o *DiffOptions) Ignored(c Change) bool {
+	var a Attr
+	switch c := c.(type) {
+	case *AddAttr:
+		a = c.A
+	case *DropAttr:
+		a = c.A
+	case *ModifyAttr:
+		a = c.To
+	default:
+		return false
+	}
+	switch a.(type) {
+	case *Comment:
+		return o.Ignore.Is(ChangeComment)
+	case *Charset:
+		return o.Ignore.Is(ChangeCharset)
+	case *Collation:
+		return o.Ignore.Is(ChangeCollate)
+	}
+	return false
+}
+
 // ErrLocked is returned on Lock calls which have failed to obtain the lock.
 var ErrLocked = errors.New("sql/schema: lock is held by other session")
 
@@ -390,6 +577,9 @@ func (*RenameColumn) change()     {}
 func (*AddForeignKey) change()    {}
 func (*DropForeignKey) change()   {}
 func (*ModifyForeignKey) change() {}
+func (*AddObject) change()        {}
+func (*DropObject) change()       {}
+func (*ModifyObject) change()     {}
 
 // clauses.
 func (*IfExists) clause()    {}
@@ -10,14 +10,16 @@ type (
 	Realm struct {
 		Schemas []*Schema
 		Attrs   []Attr
+		Objects []Object // Realm-level objects (e.g. roles).
 	}
 
 	// A Schema describes a database schema (i.e. named database).
 	Schema struct {
-		Name   string
-		Realm  *Realm
-		Tables []*Table
-		Attrs  []Attr // Attrs and options.
+		Name    string
+		Realm   *Realm
+		Tables  []*Table
+		Attrs   []Attr   // Attrs and options.
+		Objects []Object // Schema objects (e.g. sequences).
 	}
 
 	// A Table represents a table definition.
@@ -82,6 +84,7 @@ type (
 		RefColumns []*Column
 		OnUpdate   ReferenceOption
 		OnDelete   ReferenceOption
+		Attrs      []Attr
 	}
 )
 
@@ -252,6 +255,22 @@ type (
 	}
 )
 
+// An Object represents a generic database object that is not a table, such as
+// sequences, extensions or user-defined types. Objects are implemented by the
+// drivers, and can be attached to a Schema or to a Realm.
+//
+// The Object interface can also be implemented outside this package as follows:
+//
+//	type Sequence struct {
+//		schema.Object
+//		Name string
+//	}
+//
+//	var o schema.Object = &Sequence{Name: "seq"}
+type Object interface {
+	object()
+}
+
 type (
 	// Expr defines an SQL expression in schema DDL.
 	Expr interface {
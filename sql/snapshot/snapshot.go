@@ -0,0 +1,1009 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package snapshot implements a versioned JSON format for storing snapshots of
+// schema.Realm objects, including their dialect-specific attributes, types and
+// objects. Snapshots can be used as a migrate.StateReader to compute diffs and
+// plan migrations without connecting to the database they were taken from.
+//
+// Dialect-specific values are serialized by their registered names. The values
+// of the MySQL, PostgreSQL and SQLite drivers are registered by this package, and
+// custom values can be registered using the Register function.
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlite"
+)
+
+// Version is the current version of the snapshot format.
+const Version = 1
+
+var (
+	// registry maps the names of the registered types to their types.
+	registry sync.Map
+	// names maps the registered types to their names.
+	names sync.Map
+)
+
+// Register registers the given values (pointers to structs) that implement
+// schema.Attr, schema.Type, schema.Object or schema.Expr, and allows storing
+// them in snapshots. The values are registered by their package and type names
+// (e.g. "postgres.ArrayType").
+func Register(vs ...interface{}) {
+	for _, v := range vs {
+		t := reflect.TypeOf(v)
+		if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+			panic(fmt.Sprintf("sql/snapshot: Register expects a pointer to a struct, got %T", v))
+		}
+		name := path.Base(t.Elem().PkgPath()) + "." + t.Elem().Name()
+		if prev, loaded := registry.LoadOrStore(name, t); loaded && prev != t {
+			panic(fmt.Sprintf("sql/snapshot: Register called twice for %q", name))
+		}
+		names.Store(t, name)
+	}
+}
+
+func init() {
+	Register(
+		// Types.
+		&schema.EnumType{}, &schema.BinaryType{}, &schema.StringType{}, &schema.BoolType{},
+		&schema.IntegerType{}, &schema.DecimalType{}, &schema.FloatType{}, &schema.TimeType{},
+		&schema.JSONType{}, &schema.SpatialType{}, &schema.UnsupportedType{},
+		// Expressions.
+		&schema.Literal{}, &schema.RawExpr{},
+		// Attributes.
+		&schema.Comment{}, &schema.Charset{}, &schema.Collation{}, &schema.Check{}, &schema.GeneratedExpr{},
+	)
+	Register(
+		&mysql.AutoIncrement{}, &mysql.CreateOptions{}, &mysql.CreateStmt{}, &mysql.Partition{}, &mysql.OnUpdate{},
+		&mysql.SubPart{}, &mysql.Enforced{}, &mysql.DisplayWidth{}, &mysql.ZeroFill{}, &mysql.IndexType{},
+		&mysql.BitType{}, &mysql.SetType{}, &mysql.Role{}, &mysql.Permission{},
+	)
+	Register(
+		&postgres.CType{}, &postgres.ConType{}, &postgres.Identity{}, &postgres.IndexType{}, &postgres.IndexPredicate{},
+		&postgres.IndexColumnProperty{}, &postgres.IndexStorageParams{}, &postgres.ExcludeOp{}, &postgres.IndexConcurrently{},
+		&postgres.IndexInvalid{}, &postgres.NoInherit{}, &postgres.NotValid{}, &postgres.Deferrable{}, &postgres.CheckColumns{},
+		&postgres.Partition{}, &postgres.PartitionOf{}, &postgres.RowSecurity{}, &postgres.Policy{}, &postgres.UserDefinedType{},
+		&postgres.ArrayType{}, &postgres.BitType{}, &postgres.IntervalType{}, &postgres.NetworkType{}, &postgres.CurrencyType{},
+		&postgres.SerialType{}, &postgres.UUIDType{}, &postgres.XMLType{}, &postgres.DomainType{}, &postgres.CompositeType{},
+		&postgres.Sequence{}, &postgres.Extension{}, &postgres.Role{}, &postgres.Permission{},
+	)
+	Register(
+		&sqlite.File{}, &sqlite.CreateStmt{}, &sqlite.AutoIncrement{}, &sqlite.WithoutRowID{},
+		&sqlite.IndexPredicate{}, &sqlite.IndexOrigin{}, &sqlite.UUIDType{},
+	)
+}
+
+// Marshal returns the JSON snapshot of the given realm.
+func Marshal(r *schema.Realm) ([]byte, error) {
+	e := &encoder{refs: make(map[interface{}]string)}
+	e.index(r)
+	f, err := e.file(r)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(f, "", "  ")
+}
+
+// Unmarshal parses the JSON snapshot and returns the realm it describes.
+func Unmarshal(data []byte) (*schema.Realm, error) {
+	var f fileJSON
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("sql/snapshot: decode snapshot: %w", err)
+	}
+	if f.Version < 1 || f.Version > Version {
+		return nil, fmt.Errorf("sql/snapshot: unsupported snapshot version %d", f.Version)
+	}
+	d := &decoder{refs: make(map[string]reflect.Value), schemas: make(map[string]*schema.Schema)}
+	return d.realm(&f.Realm)
+}
+
+// ReadFile reads the snapshot file at the given path and returns its realm.
+func ReadFile(name string) (*schema.Realm, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return Unmarshal(data)
+}
+
+// StateReader returns a migrate.StateReader that reads
+// the state from the snapshot file at the given path.
+func StateReader(name string) migrate.StateReader {
+	return migrate.StateReaderFunc(func(context.Context) (*schema.Realm, error) {
+		return ReadFile(name)
+	})
+}
+
+type (
+	// fileJSON is the top-level JSON document of a snapshot.
+	fileJSON struct {
+		Version int       `json:"version"`
+		Realm   realmJSON `json:"realm"`
+	}
+
+	realmJSON struct {
+		Schemas []*schemaJSON     `json:"schemas,omitempty"`
+		Attrs   []json.RawMessage `json:"attrs,omitempty"`
+		Objects []json.RawMessage `json:"objects,omitempty"`
+	}
+
+	schemaJSON struct {
+		Name    string            `json:"name"`
+		Tables  []*tableJSON      `json:"tables,omitempty"`
+		Attrs   []json.RawMessage `json:"attrs,omitempty"`
+		Objects []json.RawMessage `json:"objects,omitempty"`
+	}
+
+	tableJSON struct {
+		Name        string            `json:"name"`
+		Columns     []*columnJSON     `json:"columns,omitempty"`
+		Indexes     []*indexJSON      `json:"indexes,omitempty"`
+		PrimaryKey  *indexJSON        `json:"primary_key,omitempty"`
+		ForeignKeys []*foreignKeyJSON `json:"foreign_keys,omitempty"`
+		Attrs       []json.RawMessage `json:"attrs,omitempty"`
+	}
+
+	columnJSON struct {
+		Name    string            `json:"name"`
+		Type    *columnTypeJSON   `json:"type,omitempty"`
+		Default json.RawMessage   `json:"default,omitempty"`
+		Attrs   []json.RawMessage `json:"attrs,omitempty"`
+		// References to the indexes and the foreign keys of the column.
+		Indexes     []string `json:"indexes,omitempty"`
+		ForeignKeys []string `json:"foreign_keys,omitempty"`
+	}
+
+	columnTypeJSON struct {
+		Type json.RawMessage `json:"type,omitempty"`
+		Raw  string          `json:"raw,omitempty"`
+		Null bool            `json:"null,omitempty"`
+	}
+
+	indexJSON struct {
+		Name   string            `json:"name,omitempty"`
+		Unique bool              `json:"unique,omitempty"`
+		Parts  []*indexPartJSON  `json:"parts,omitempty"`
+		Attrs  []json.RawMessage `json:"attrs,omitempty"`
+	}
+
+	indexPartJSON struct {
+		SeqNo int               `json:"seq_no,omitempty"`
+		Desc  bool              `json:"desc,omitempty"`
+		X     json.RawMessage   `json:"expr,omitempty"`
+		C     string            `json:"column,omitempty"`
+		Attrs []json.RawMessage `json:"attrs,omitempty"`
+	}
+
+	foreignKeyJSON struct {
+		Symbol     string            `json:"symbol,omitempty"`
+		Columns    []string          `json:"columns,omitempty"`
+		RefTable   string            `json:"ref_table,omitempty"`
+		RefColumns []string          `json:"ref_columns,omitempty"`
+		OnUpdate   string            `json:"on_update,omitempty"`
+		OnDelete   string            `json:"on_delete,omitempty"`
+		Attrs      []json.RawMessage `json:"attrs,omitempty"`
+	}
+
+	// valueJSON is the JSON representation of a registered value.
+	valueJSON struct {
+		Kind  string          `json:"kind"`
+		Value json.RawMessage `json:"value,omitempty"`
+	}
+
+	// refJSON is the JSON representation of a reference to a realm element.
+	refJSON struct {
+		Ref string `json:"$ref"`
+	}
+)
+
+// encoder encodes a realm to its JSON representation. The elements of the
+// realm (e.g. tables, columns or objects) are indexed by their paths in the
+// document, and references to them are encoded using these paths.
+type encoder struct {
+	refs map[interface{}]string
+}
+
+// index collects the paths of the realm elements.
+func (e *encoder) index(r *schema.Realm) {
+	for i, o := range r.Objects {
+		e.refs[o] = "objects/" + strconv.Itoa(i)
+	}
+	for i, s := range r.Schemas {
+		sp := "schemas/" + strconv.Itoa(i)
+		e.refs[s] = sp
+		for j, o := range s.Objects {
+			e.refs[o] = sp + "/objects/" + strconv.Itoa(j)
+		}
+		for j, t := range s.Tables {
+			tp := sp + "/tables/" + strconv.Itoa(j)
+			e.refs[t] = tp
+			for k, c := range t.Columns {
+				e.refs[c] = tp + "/columns/" + strconv.Itoa(k)
+			}
+			for k, idx := range t.Indexes {
+				e.refs[idx] = tp + "/indexes/" + strconv.Itoa(k)
+			}
+			if t.PrimaryKey != nil {
+				e.refs[t.PrimaryKey] = tp + "/primary_key"
+			}
+			for k, fk := range t.ForeignKeys {
+				e.refs[fk] = tp + "/foreign_keys/" + strconv.Itoa(k)
+			}
+		}
+	}
+}
+
+// ref returns the reference of the given element.
+func (e *encoder) ref(v interface{}) (string, error) {
+	r, ok := e.refs[v]
+	if !ok {
+		return "", fmt.Errorf("sql/snapshot: %T is not part of the realm", v)
+	}
+	return r, nil
+}
+
+// tableRef returns the reference of the given table. Tables that are not part
+// of the realm (e.g. stubs of tables that were not inspected) are referenced by
+// their qualified names.
+func (e *encoder) tableRef(t *schema.Table) string {
+	if r, ok := e.refs[t]; ok {
+		return r
+	}
+	r := externalRef(t, nil)
+	e.refs[t] = r
+	for _, c := range t.Columns {
+		if _, ok := e.refs[c]; !ok {
+			e.refs[c] = externalRef(t, c)
+		}
+	}
+	return r
+}
+
+// refColumns returns the references of the columns of the given table.
+// Columns that are not part of the realm are referenced by their qualified names.
+func (e *encoder) refColumns(t *schema.Table, columns []*schema.Column) []string {
+	refs := make([]string, 0, len(columns))
+	for _, c := range columns {
+		r, ok := e.refs[c]
+		if !ok {
+			r = externalRef(t, c)
+		}
+		refs = append(refs, r)
+	}
+	return refs
+}
+
+// externalPrefix is the prefix of references to elements outside the realm.
+const externalPrefix = "external:"
+
+// escaper escapes the separators in the names of external references.
+var escaper = strings.NewReplacer(`\`, `\\`, ".", `\.`)
+
+// externalRef returns the reference of a table (or one of its columns) that is not part of
+// the realm: its qualified name, with the dots and backslashes in names escaped.
+func externalRef(t *schema.Table, c *schema.Column) string {
+	var s string
+	if t.Schema != nil {
+		s = t.Schema.Name
+	}
+	parts := []string{s, t.Name}
+	if c != nil {
+		parts = append(parts, c.Name)
+	}
+	for i, p := range parts {
+		parts[i] = escaper.Replace(p)
+	}
+	return externalPrefix + strings.Join(parts, ".")
+}
+
+// splitExternal splits an external reference to its unescaped name parts.
+func splitExternal(r string) []string {
+	var (
+		parts []string
+		b     strings.Builder
+		s     = strings.TrimPrefix(r, externalPrefix)
+	)
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			i++
+			b.WriteByte(s[i])
+		case s[i] == '.':
+			parts = append(parts, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return append(parts, b.String())
+}
+
+func (e *encoder) file(r *schema.Realm) (*fileJSON, error) {
+	f := &fileJSON{Version: Version}
+	var err error
+	if f.Realm.Attrs, err = e.values(r.Attrs); err != nil {
+		return nil, err
+	}
+	if f.Realm.Objects, err = e.objects(r.Objects); err != nil {
+		return nil, err
+	}
+	for _, s := range r.Schemas {
+		sj := &schemaJSON{Name: s.Name}
+		if sj.Attrs, err = e.values(s.Attrs); err != nil {
+			return nil, err
+		}
+		if sj.Objects, err = e.objects(s.Objects); err != nil {
+			return nil, err
+		}
+		for _, t := range s.Tables {
+			tj, err := e.table(t)
+			if err != nil {
+				return nil, err
+			}
+			sj.Tables = append(sj.Tables, tj)
+		}
+		f.Realm.Schemas = append(f.Realm.Schemas, sj)
+	}
+	return f, nil
+}
+
+func (e *encoder) table(t *schema.Table) (*tableJSON, error) {
+	tj := &tableJSON{Name: t.Name}
+	var err error
+	if tj.Attrs, err = e.values(t.Attrs); err != nil {
+		return nil, err
+	}
+	for _, c := range t.Columns {
+		cj, err := e.column(c)
+		if err != nil {
+			return nil, err
+		}
+		tj.Columns = append(tj.Columns, cj)
+	}
+	for _, idx := range t.Indexes {
+		ij, err := e.idx(idx)
+		if err != nil {
+			return nil, err
+		}
+		tj.Indexes = append(tj.Indexes, ij)
+	}
+	if t.PrimaryKey != nil {
+		if tj.PrimaryKey, err = e.idx(t.PrimaryKey); err != nil {
+			return nil, err
+		}
+	}
+	for _, fk := range t.ForeignKeys {
+		fj := &foreignKeyJSON{Symbol: fk.Symbol, OnUpdate: string(fk.OnUpdate), OnDelete: string(fk.OnDelete)}
+		if fj.Columns, err = e.columnRefs(fk.Columns); err != nil {
+			return nil, err
+		}
+		if fk.RefTable != nil {
+			fj.RefTable = e.tableRef(fk.RefTable)
+			fj.RefColumns = e.refColumns(fk.RefTable, fk.RefColumns)
+		} else if fj.RefColumns, err = e.columnRefs(fk.RefColumns); err != nil {
+			return nil, err
+		}
+		if fj.Attrs, err = e.values(fk.Attrs); err != nil {
+			return nil, err
+		}
+		tj.ForeignKeys = append(tj.ForeignKeys, fj)
+	}
+	return tj, nil
+}
+
+func (e *encoder) column(c *schema.Column) (*columnJSON, error) {
+	cj := &columnJSON{Name: c.Name}
+	var err error
+	if c.Type != nil {
+		cj.Type = &columnTypeJSON{Raw: c.Type.Raw, Null: c.Type.Null}
+		if cj.Type.Type, err = e.value(c.Type.Type); err != nil {
+			return nil, err
+		}
+	}
+	if cj.Default, err = e.value(c.Default); err != nil {
+		return nil, err
+	}
+	if cj.Attrs, err = e.values(c.Attrs); err != nil {
+		return nil, err
+	}
+	for _, idx := range c.Indexes {
+		r, err := e.ref(idx)
+		if err != nil {
+			return nil, err
+		}
+		cj.Indexes = append(cj.Indexes, r)
+	}
+	for _, fk := range c.ForeignKeys {
+		r, err := e.ref(fk)
+		if err != nil {
+			return nil, err
+		}
+		cj.ForeignKeys = append(cj.ForeignKeys, r)
+	}
+	return cj, nil
+}
+
+func (e *encoder) idx(idx *schema.Index) (*indexJSON, error) {
+	ij := &indexJSON{Name: idx.Name, Unique: idx.Unique}
+	var err error
+	if ij.Attrs, err = e.values(idx.Attrs); err != nil {
+		return nil, err
+	}
+	for _, p := range idx.Parts {
+		pj := &indexPartJSON{SeqNo: p.SeqNo, Desc: p.Desc}
+		if pj.X, err = e.value(p.X); err != nil {
+			return nil, err
+		}
+		if p.C != nil {
+			if pj.C, err = e.ref(p.C); err != nil {
+				return nil, err
+			}
+		}
+		if pj.Attrs, err = e.values(p.Attrs); err != nil {
+			return nil, err
+		}
+		ij.Parts = append(ij.Parts, pj)
+	}
+	return ij, nil
+}
+
+func (e *encoder) columnRefs(columns []*schema.Column) ([]string, error) {
+	refs := make([]string, 0, len(columns))
+	for _, c := range columns {
+		r, err := e.ref(c)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, r)
+	}
+	return refs, nil
+}
+
+// values encodes a list of registered values (e.g. []schema.Attr).
+func (e *encoder) values(vs interface{}) ([]json.RawMessage, error) {
+	rv := reflect.ValueOf(vs)
+	msgs := make([]json.RawMessage, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		m, err := e.value(rv.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, m)
+	}
+	if len(msgs) == 0 {
+		return nil, nil
+	}
+	return msgs, nil
+}
+
+// objects encodes the definitions of the given objects.
+func (e *encoder) objects(objs []schema.Object) ([]json.RawMessage, error) {
+	msgs := make([]json.RawMessage, 0, len(objs))
+	for _, o := range objs {
+		m, err := e.define(o)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, m)
+	}
+	if len(msgs) == 0 {
+		return nil, nil
+	}
+	return msgs, nil
+}
+
+// value encodes a registered value, or a reference to it, if it is an element
+// of the realm (e.g. a domain type). A nil message is returned for nil values.
+func (e *encoder) value(v interface{}) (json.RawMessage, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil, nil
+	}
+	if rv.Kind() == reflect.Ptr {
+		if r, ok := e.refs[v]; ok {
+			return json.Marshal(refJSON{Ref: r})
+		}
+	}
+	return e.define(v)
+}
+
+// define encodes a registered value with its kind.
+func (e *encoder) define(v interface{}) (json.RawMessage, error) {
+	rv := reflect.ValueOf(v)
+	name, ok := names.Load(rv.Type())
+	if !ok {
+		return nil, fmt.Errorf("sql/snapshot: unregistered type %T", v)
+	}
+	x, err := e.encode(rv.Elem())
+	if err != nil {
+		return nil, err
+	}
+	vj := valueJSON{Kind: name.(string)}
+	if m, ok := x.(map[string]interface{}); !ok || len(m) > 0 {
+		if vj.Value, err = json.Marshal(x); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(vj)
+}
+
+var (
+	marshalerT = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	// realmTypes holds the types of the realm elements that are always encoded
+	// as references, as they cannot be encoded inline (e.g. due to cycles).
+	realmTypes = map[reflect.Type]bool{
+		reflect.TypeOf((*schema.Realm)(nil)):      true,
+		reflect.TypeOf((*schema.Schema)(nil)):     true,
+		reflect.TypeOf((*schema.Table)(nil)):      true,
+		reflect.TypeOf((*schema.Column)(nil)):     true,
+		reflect.TypeOf((*schema.Index)(nil)):      true,
+		reflect.TypeOf((*schema.ForeignKey)(nil)): true,
+	}
+)
+
+// encode encodes the given value to a JSON-encodable value. Exported struct
+// fields are encoded by their names, and zero values are omitted. Pointers to
+// realm elements are encoded as references, and interface values are encoded
+// as registered values.
+func (e *encoder) encode(rv reflect.Value) (interface{}, error) {
+	if rv.Type().Implements(marshalerT) {
+		return rv.Interface(), nil
+	}
+	switch rv.Kind() {
+	case reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		m, err := e.value(rv.Elem().Interface())
+		return m, err
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		if r, ok := e.refs[rv.Interface()]; ok {
+			return refJSON{Ref: r}, nil
+		}
+		if t, ok := rv.Interface().(*schema.Table); ok {
+			return refJSON{Ref: e.tableRef(t)}, nil
+		}
+		if realmTypes[rv.Type()] {
+			return nil, fmt.Errorf("sql/snapshot: %s is not part of the realm", rv.Type())
+		}
+		return e.encode(rv.Elem())
+	case reflect.Struct:
+		m := make(map[string]interface{})
+		for i := 0; i < rv.NumField(); i++ {
+			f := rv.Type().Field(i)
+			if !f.IsExported() || rv.Field(i).IsZero() {
+				continue
+			}
+			x, err := e.encode(rv.Field(i))
+			if err != nil {
+				return nil, err
+			}
+			m[f.Name] = x
+		}
+		return m, nil
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil, nil
+		}
+		l := make([]interface{}, rv.Len())
+		for i := range l {
+			x, err := e.encode(rv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			l[i] = x
+		}
+		return l, nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("sql/snapshot: unsupported map key type %s", rv.Type().Key())
+		}
+		m := make(map[string]interface{}, rv.Len())
+		for it := rv.MapRange(); it.Next(); {
+			x, err := e.encode(it.Value())
+			if err != nil {
+				return nil, err
+			}
+			m[it.Key().String()] = x
+		}
+		return m, nil
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return nil, fmt.Errorf("sql/snapshot: unsupported type %s", rv.Type())
+	default:
+		return rv.Interface(), nil
+	}
+}
+
+// decoder decodes a realm from its JSON representation. The realm elements are
+// allocated and indexed by their paths before their content is decoded, so that
+// references can be resolved regardless of the order of the elements.
+type decoder struct {
+	refs map[string]reflect.Value
+	// r is the decoded realm, and schemas holds
+	// the stubs of the schemas that are not part of it.
+	r       *schema.Realm
+	schemas map[string]*schema.Schema
+}
+
+func (d *decoder) realm(rj *realmJSON) (*schema.Realm, error) {
+	r := &schema.Realm{}
+	d.r = r
+	var err error
+	// Allocate and index all realm elements.
+	objects := make([][]reflect.Value, len(rj.Schemas)+1)
+	if objects[0], err = d.alloc("objects", rj.Objects); err != nil {
+		return nil, err
+	}
+	for i, sj := range rj.Schemas {
+		sp := "schemas/" + strconv.Itoa(i)
+		s := &schema.Schema{Name: sj.Name, Realm: r}
+		d.refs[sp] = reflect.ValueOf(s)
+		if objects[i+1], err = d.alloc(sp+"/objects", sj.Objects); err != nil {
+			return nil, err
+		}
+		for j, tj := range sj.Tables {
+			tp := sp + "/tables/" + strconv.Itoa(j)
+			t := &schema.Table{Name: tj.Name, Schema: s}
+			d.refs[tp] = reflect.ValueOf(t)
+			for k, cj := range tj.Columns {
+				c := &schema.Column{Name: cj.Name}
+				d.refs[tp+"/columns/"+strconv.Itoa(k)] = reflect.ValueOf(c)
+				t.Columns = append(t.Columns, c)
+			}
+			for k, ij := range tj.Indexes {
+				idx := &schema.Index{Name: ij.Name, Unique: ij.Unique, Table: t}
+				d.refs[tp+"/indexes/"+strconv.Itoa(k)] = reflect.ValueOf(idx)
+				t.Indexes = append(t.Indexes, idx)
+			}
+			if ij := tj.PrimaryKey; ij != nil {
+				t.PrimaryKey = &schema.Index{Name: ij.Name, Unique: ij.Unique, Table: t}
+				d.refs[tp+"/primary_key"] = reflect.ValueOf(t.PrimaryKey)
+			}
+			for k, fj := range tj.ForeignKeys {
+				fk := &schema.ForeignKey{
+					Symbol:   fj.Symbol,
+					Table:    t,
+					OnUpdate: schema.ReferenceOption(fj.OnUpdate),
+					OnDelete: schema.ReferenceOption(fj.OnDelete),
+				}
+				d.refs[tp+"/foreign_keys/"+strconv.Itoa(k)] = reflect.ValueOf(fk)
+				t.ForeignKeys = append(t.ForeignKeys, fk)
+			}
+			s.Tables = append(s.Tables, t)
+		}
+		r.Schemas = append(r.Schemas, s)
+	}
+	// Decode the content of the elements.
+	if err := d.fill(rj.Objects, objects[0], &r.Objects); err != nil {
+		return nil, err
+	}
+	if err := d.values(rj.Attrs, &r.Attrs); err != nil {
+		return nil, err
+	}
+	for i, sj := range rj.Schemas {
+		s := r.Schemas[i]
+		if err := d.fill(sj.Objects, objects[i+1], &s.Objects); err != nil {
+			return nil, err
+		}
+		if err := d.values(sj.Attrs, &s.Attrs); err != nil {
+			return nil, err
+		}
+		for j, tj := range sj.Tables {
+			if err := d.table(tj, s.Tables[j]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return r, nil
+}
+
+// alloc allocates and indexes the registered values of the given messages.
+func (d *decoder) alloc(prefix string, msgs []json.RawMessage) ([]reflect.Value, error) {
+	vs := make([]reflect.Value, len(msgs))
+	for i, m := range msgs {
+		var vj valueJSON
+		if err := json.Unmarshal(m, &vj); err != nil {
+			return nil, fmt.Errorf("sql/snapshot: decode %s/%d: %w", prefix, i, err)
+		}
+		t, ok := registry.Load(vj.Kind)
+		if !ok {
+			return nil, fmt.Errorf("sql/snapshot: unregistered kind %q", vj.Kind)
+		}
+		vs[i] = reflect.New(t.(reflect.Type).Elem())
+		d.refs[prefix+"/"+strconv.Itoa(i)] = vs[i]
+	}
+	return vs, nil
+}
+
+// fill decodes the content of the allocated values and appends them to the given slice.
+func (d *decoder) fill(msgs []json.RawMessage, vs []reflect.Value, to *[]schema.Object) error {
+	for i, m := range msgs {
+		var vj valueJSON
+		if err := json.Unmarshal(m, &vj); err != nil {
+			return err
+		}
+		if err := d.decode(vj.Value, vs[i].Elem()); err != nil {
+			return err
+		}
+		o, ok := vs[i].Interface().(schema.Object)
+		if !ok {
+			return fmt.Errorf("sql/snapshot: %q does not implement schema.Object", vj.Kind)
+		}
+		*to = append(*to, o)
+	}
+	return nil
+}
+
+func (d *decoder) table(tj *tableJSON, t *schema.Table) error {
+	if err := d.values(tj.Attrs, &t.Attrs); err != nil {
+		return err
+	}
+	for i, cj := range tj.Columns {
+		c := t.Columns[i]
+		if cj.Type != nil {
+			c.Type = &schema.ColumnType{Raw: cj.Type.Raw, Null: cj.Type.Null}
+			if err := d.value(cj.Type.Type, &c.Type.Type); err != nil {
+				return err
+			}
+		}
+		if err := d.value(cj.Default, &c.Default); err != nil {
+			return err
+		}
+		if err := d.values(cj.Attrs, &c.Attrs); err != nil {
+			return err
+		}
+		for _, r := range cj.Indexes {
+			if err := d.ref(r, &c.Indexes); err != nil {
+				return err
+			}
+		}
+		for _, r := range cj.ForeignKeys {
+			if err := d.ref(r, &c.ForeignKeys); err != nil {
+				return err
+			}
+		}
+	}
+	for i, ij := range tj.Indexes {
+		if err := d.idx(ij, t.Indexes[i]); err != nil {
+			return err
+		}
+	}
+	if tj.PrimaryKey != nil {
+		if err := d.idx(tj.PrimaryKey, t.PrimaryKey); err != nil {
+			return err
+		}
+	}
+	for i, fj := range tj.ForeignKeys {
+		fk := t.ForeignKeys[i]
+		for _, r := range fj.Columns {
+			if err := d.ref(r, &fk.Columns); err != nil {
+				return err
+			}
+		}
+		if fj.RefTable != "" {
+			if err := d.ref(fj.RefTable, &fk.RefTable); err != nil {
+				return err
+			}
+		}
+		for _, r := range fj.RefColumns {
+			if err := d.ref(r, &fk.RefColumns); err != nil {
+				return err
+			}
+		}
+		if err := d.values(fj.Attrs, &fk.Attrs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *decoder) idx(ij *indexJSON, idx *schema.Index) error {
+	if err := d.values(ij.Attrs, &idx.Attrs); err != nil {
+		return err
+	}
+	for _, pj := range ij.Parts {
+		p := &schema.IndexPart{SeqNo: pj.SeqNo, Desc: pj.Desc}
+		if err := d.value(pj.X, &p.X); err != nil {
+			return err
+		}
+		if pj.C != "" {
+			if err := d.ref(pj.C, &p.C); err != nil {
+				return err
+			}
+		}
+		if err := d.values(pj.Attrs, &p.Attrs); err != nil {
+			return err
+		}
+		idx.Parts = append(idx.Parts, p)
+	}
+	return nil
+}
+
+// ref resolves the given reference and stores it in the pointer
+// (e.g. **schema.Table), or appends it to the slice it points to.
+func (d *decoder) ref(r string, to interface{}) error {
+	v, ok := d.refs[r]
+	if !ok && strings.HasPrefix(r, externalPrefix) {
+		v, ok = d.external(r)
+	}
+	if !ok {
+		return fmt.Errorf("sql/snapshot: unknown reference %q", r)
+	}
+	rv := reflect.ValueOf(to).Elem()
+	if rv.Kind() == reflect.Slice {
+		if !v.Type().AssignableTo(rv.Type().Elem()) {
+			return fmt.Errorf("sql/snapshot: unexpected reference %q of type %s", r, v.Type())
+		}
+		rv.Set(reflect.Append(rv, v))
+		return nil
+	}
+	if !v.Type().AssignableTo(rv.Type()) {
+		return fmt.Errorf("sql/snapshot: unexpected reference %q of type %s", r, v.Type())
+	}
+	rv.Set(v)
+	return nil
+}
+
+// external returns the stub of the table (or the column) the given external
+// reference describes. Stubs are created once, and shared by all references.
+func (d *decoder) external(r string) (reflect.Value, bool) {
+	var v reflect.Value
+	switch parts := splitExternal(r); len(parts) {
+	case 2:
+		s, ok := d.r.Schema(parts[0])
+		if !ok {
+			if s, ok = d.schemas[parts[0]]; !ok {
+				s = &schema.Schema{Name: parts[0]}
+				d.schemas[parts[0]] = s
+			}
+		}
+		v = reflect.ValueOf(&schema.Table{Name: parts[1], Schema: s})
+	case 3:
+		tr := externalRef(&schema.Table{Name: parts[1], Schema: &schema.Schema{Name: parts[0]}}, nil)
+		tv, ok := d.refs[tr]
+		if !ok {
+			tv, _ = d.external(tr)
+		}
+		t := tv.Interface().(*schema.Table)
+		c := &schema.Column{Name: parts[2]}
+		t.Columns = append(t.Columns, c)
+		v = reflect.ValueOf(c)
+	default:
+		return reflect.Value{}, false
+	}
+	d.refs[r] = v
+	return v, true
+}
+
+// values decodes a list of registered values and appends them to
+// the slice the given pointer points to (e.g. *[]schema.Attr).
+func (d *decoder) values(msgs []json.RawMessage, to interface{}) error {
+	rv := reflect.ValueOf(to).Elem()
+	for _, m := range msgs {
+		v := reflect.New(rv.Type().Elem()).Elem()
+		if err := d.decode(m, v); err != nil {
+			return err
+		}
+		rv.Set(reflect.Append(rv, v))
+	}
+	return nil
+}
+
+// value decodes a registered value and stores it in the interface the given pointer
+// points to (e.g. *schema.Type). Nothing is stored for empty or null messages.
+func (d *decoder) value(m json.RawMessage, to interface{}) error {
+	return d.decode(m, reflect.ValueOf(to).Elem())
+}
+
+var unmarshalerT = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// decode decodes the JSON message into the given (settable) value.
+func (d *decoder) decode(m json.RawMessage, rv reflect.Value) error {
+	if len(m) == 0 || string(m) == "null" {
+		return nil
+	}
+	if reflect.PtrTo(rv.Type()).Implements(unmarshalerT) {
+		return json.Unmarshal(m, rv.Addr().Interface())
+	}
+	switch rv.Kind() {
+	case reflect.Interface:
+		var vj struct {
+			valueJSON
+			refJSON
+		}
+		if err := json.Unmarshal(m, &vj); err != nil {
+			return err
+		}
+		if vj.Ref != "" {
+			return d.ref(vj.Ref, rv.Addr().Interface())
+		}
+		t, ok := registry.Load(vj.Kind)
+		if !ok {
+			return fmt.Errorf("sql/snapshot: unregistered kind %q", vj.Kind)
+		}
+		v := reflect.New(t.(reflect.Type).Elem())
+		if !v.Type().AssignableTo(rv.Type()) {
+			return fmt.Errorf("sql/snapshot: %q does not implement %s", vj.Kind, rv.Type())
+		}
+		if err := d.decode(vj.Value, v.Elem()); err != nil {
+			return err
+		}
+		rv.Set(v)
+	case reflect.Ptr:
+		var r refJSON
+		if m[0] == '{' {
+			if err := json.Unmarshal(m, &r); err != nil {
+				return err
+			}
+		}
+		if r.Ref != "" {
+			return d.ref(r.Ref, rv.Addr().Interface())
+		}
+		v := reflect.New(rv.Type().Elem())
+		if err := d.decode(m, v.Elem()); err != nil {
+			return err
+		}
+		rv.Set(v)
+	case reflect.Struct:
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(m, &fields); err != nil {
+			return err
+		}
+		for i := 0; i < rv.NumField(); i++ {
+			f := rv.Type().Field(i)
+			if fm, ok := fields[f.Name]; ok && f.IsExported() {
+				if err := d.decode(fm, rv.Field(i)); err != nil {
+					return fmt.Errorf("%s.%s: %w", rv.Type(), f.Name, err)
+				}
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		var elems []json.RawMessage
+		if err := json.Unmarshal(m, &elems); err != nil {
+			return err
+		}
+		if rv.Kind() == reflect.Slice {
+			rv.Set(reflect.MakeSlice(rv.Type(), len(elems), len(elems)))
+		}
+		for i := 0; i < len(elems) && i < rv.Len(); i++ {
+			if err := d.decode(elems[i], rv.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		var elems map[string]json.RawMessage
+		if err := json.Unmarshal(m, &elems); err != nil {
+			return err
+		}
+		rv.Set(reflect.MakeMapWithSize(rv.Type(), len(elems)))
+		for k, em := range elems {
+			v := reflect.New(rv.Type().Elem()).Elem()
+			if err := d.decode(em, v); err != nil {
+				return err
+			}
+			rv.SetMapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()), v)
+		}
+	default:
+		return json.Unmarshal(m, rv.Addr().Interface())
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package snapshot_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/snapshot"
+
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	// Owner is an object that refers to other realm elements.
+	Owner struct {
+		schema.Object
+		Name    string
+		Schema  *schema.Schema
+		Columns []*schema.Column
+		Options map[string]int64
+	}
+
+	// Owned is an attribute that refers to a realm object.
+	Owned struct {
+		schema.Attr
+		By *Owner
+	}
+
+	// ElemType is a type that wraps another type.
+	ElemType struct {
+		schema.Type
+		T string
+	}
+)
+
+func init() {
+	snapshot.Register(&Owner{}, &Owned{}, &ElemType{})
+}
+
+func TestMarshal(t *testing.T) {
+	r := newRealm()
+	data, err := snapshot.Marshal(r)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"version": 1`)
+	require.Contains(t, string(data), `"kind": "snapshot_test.Owned"`)
+	require.Contains(t, string(data), `"$ref": "objects/0"`)
+
+	got, err := snapshot.Unmarshal(data)
+	require.NoError(t, err)
+	require.Equal(t, r, got)
+	users, pets := got.Schemas[0].Tables[0], got.Schemas[1].Tables[0]
+	require.True(t, pets.ForeignKeys[0].RefTable == users)
+	require.True(t, pets.ForeignKeys[0].RefColumns[0] == users.Columns[0])
+	require.True(t, users.Columns[0].Indexes[0] == users.PrimaryKey)
+	require.True(t, users.Attrs[len(users.Attrs)-1].(*Owned).By == got.Objects[0])
+	require.True(t, got.Objects[0].(*Owner).Columns[0] == users.Columns[1])
+
+	// Marshaling is deterministic.
+	again, err := snapshot.Marshal(got)
+	require.NoError(t, err)
+	require.Equal(t, string(data), string(again))
+}
+
+func TestMarshal_External(t *testing.T) {
+	// Stubs of tables that were not inspected, as created by the drivers.
+	var (
+		public = schema.New("public")
+		users  = &schema.Table{Name: "users", Schema: &schema.Schema{Name: "auth.v1"}}
+		groups = &schema.Table{Name: "groups", Schema: public}
+		pets   = schema.NewTable("pets").AddColumns(
+			schema.NewIntColumn("id", "int"),
+			schema.NewIntColumn("owner_id", "int"),
+			schema.NewIntColumn("admin_id", "int"),
+			schema.NewIntColumn("group_id", "int"),
+		)
+	)
+	pets.AddForeignKeys(
+		schema.NewForeignKey("owner").AddColumns(pets.Columns[1]).SetRefTable(users).AddRefColumns(schema.NewColumn("id")),
+		schema.NewForeignKey("admin").AddColumns(pets.Columns[2]).SetRefTable(users).AddRefColumns(schema.NewColumn("uid")),
+		schema.NewForeignKey("group").AddColumns(pets.Columns[3]).SetRefTable(groups).AddRefColumns(schema.NewColumn("id")),
+	)
+	r := schema.NewRealm(public.AddTables(pets))
+	data, err := snapshot.Marshal(r)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"ref_table": "external:auth\\.v1.users"`)
+	require.Contains(t, string(data), `"external:auth\\.v1.users.uid"`)
+	require.Contains(t, string(data), `"ref_table": "external:public.groups"`)
+
+	got, err := snapshot.Unmarshal(data)
+	require.NoError(t, err)
+	require.Len(t, got.Schemas, 1)
+	require.Len(t, got.Schemas[0].Tables, 1)
+	fks := got.Schemas[0].Tables[0].ForeignKeys
+	require.Len(t, fks, 3)
+	// References to the same table are resolved to the same stub.
+	require.True(t, fks[0].RefTable == fks[1].RefTable)
+	require.Equal(t, "users", fks[0].RefTable.Name)
+	require.Equal(t, "auth.v1", fks[0].RefTable.Schema.Name)
+	require.Equal(t, "id", fks[0].RefColumns[0].Name)
+	require.Equal(t, "uid", fks[1].RefColumns[0].Name)
+	// Stubs of tables in schemas of the realm are linked to their schemas.
+	require.True(t, fks[2].RefTable.Schema == got.Schemas[0])
+	require.Equal(t, "groups", fks[2].RefTable.Name)
+
+	// Marshaling is deterministic.
+	again, err := snapshot.Marshal(got)
+	require.NoError(t, err)
+	require.Equal(t, string(data), string(again))
+}
+
+func TestMarshal_MySQL(t *testing.T) {
+	users := schema.NewTable("users").
+		AddColumns(
+			schema.NewIntColumn("id", "int").AddAttrs(&mysql.AutoIncrement{V: 100}, &mysql.DisplayWidth{N: 10}),
+			schema.NewColumn("roles").SetType(&mysql.SetType{Values: []string{"admin", "user"}}),
+			schema.NewColumn("flags").SetType(&mysql.BitType{T: "bit"}),
+			schema.NewTimeColumn("updated_at", "timestamp").AddAttrs(&mysql.OnUpdate{A: "CURRENT_TIMESTAMP"}),
+		).
+		AddAttrs(&mysql.CreateOptions{V: "COMPRESSION='ZLIB'"})
+	users.SetPrimaryKey(schema.NewPrimaryKey(users.Columns[0]))
+	users.AddIndexes(schema.NewIndex("roles").AddParts(schema.NewColumnPart(users.Columns[1]).AddAttrs(&mysql.SubPart{Len: 10})).AddAttrs(&mysql.IndexType{T: "BTREE"}))
+	users.AddAttrs(&mysql.Partition{T: "KEY", Columns: users.Columns[:1], Count: 4})
+	r := schema.NewRealm(schema.New("test").AddTables(users)).AddObjects(&mysql.Role{Name: "reader", Host: "%"})
+
+	data, err := snapshot.Marshal(r)
+	require.NoError(t, err)
+	got, err := snapshot.Unmarshal(data)
+	require.NoError(t, err)
+	require.Equal(t, r, got)
+	users = got.Schemas[0].Tables[0]
+	require.True(t, users.Attrs[1].(*mysql.Partition).Columns[0] == users.Columns[0])
+}
+
+func TestMarshal_Postgres(t *testing.T) {
+	public := schema.New("public")
+	domain := &postgres.DomainType{T: "positive", Schema: public, Base: &schema.IntegerType{T: "integer"}, Null: true, Checks: []*schema.Check{{Name: "positive", Expr: "VALUE > 0"}}}
+	status := &schema.EnumType{T: "status", Values: []string{"active", "inactive"}}
+	users := schema.NewTable("users").
+		AddColumns(
+			schema.NewIntColumn("id", "bigint").AddAttrs(&postgres.Identity{Generation: "ALWAYS", Sequence: &postgres.Sequence{Start: 1, Increment: 1}}),
+			schema.NewColumn("tags").SetType(&postgres.ArrayType{Type: &schema.StringType{T: "text"}, T: "text[]"}),
+			schema.NewColumn("score").SetType(domain),
+			schema.NewColumn("status").SetType(status),
+			schema.NewTimeColumn("created_at", "timestamp"),
+		)
+	users.SetPrimaryKey(schema.NewPrimaryKey(users.Columns[0]))
+	users.AddIndexes(schema.NewIndex("created").AddColumns(users.Columns[4]).AddAttrs(&postgres.IndexType{T: "BRIN"}, &postgres.IndexPredicate{P: "id > 0"}))
+	users.AddAttrs(&postgres.Partition{T: "RANGE", Parts: []*postgres.PartitionPart{{C: users.Columns[4]}}}, &postgres.RowSecurity{Enabled: true})
+	public.AddTables(users).AddObjects(domain, &postgres.Sequence{Name: "seq", Schema: public, Start: 1, Increment: 1, OwnedBy: &postgres.SequenceOwner{T: users, C: users.Columns[0]}})
+	admin := &postgres.Role{Name: "admin", Login: true}
+	r := schema.NewRealm(public).AddObjects(admin, &postgres.Permission{Role: admin, Schema: public, Table: users, Privileges: []string{"SELECT"}})
+
+	data, err := snapshot.Marshal(r)
+	require.NoError(t, err)
+	got, err := snapshot.Unmarshal(data)
+	require.NoError(t, err)
+	require.Equal(t, r, got)
+	users = got.Schemas[0].Tables[0]
+	require.True(t, users.Columns[2].Type.Type.(*postgres.DomainType) == got.Schemas[0].Objects[0].(*postgres.DomainType), "domain type is shared with the schema objects")
+	require.True(t, got.Objects[1].(*postgres.Permission).Role == got.Objects[0])
+	require.True(t, users.Attrs[0].(*postgres.Partition).Parts[0].C == users.Columns[4])
+
+	// Sequences owned by tables that were not inspected.
+	r = schema.NewRealm(schema.New("public"))
+	c := schema.NewColumn("id")
+	r.Schemas[0].AddObjects(&postgres.Sequence{Name: "seq", Schema: r.Schemas[0], Start: 1, Increment: 1, OwnedBy: &postgres.SequenceOwner{
+		T: &schema.Table{Name: "users", Schema: schema.New("other"), Columns: []*schema.Column{c}},
+		C: c,
+	}})
+	data, err = snapshot.Marshal(r)
+	require.NoError(t, err)
+	got, err = snapshot.Unmarshal(data)
+	require.NoError(t, err)
+	owner := got.Schemas[0].Objects[0].(*postgres.Sequence).OwnedBy
+	require.Equal(t, "other", owner.T.Schema.Name)
+	require.Equal(t, "users", owner.T.Name)
+	require.True(t, owner.T.Columns[0] == owner.C)
+	require.Equal(t, "id", owner.C.Name)
+}
+
+func TestMarshal_Errors(t *testing.T) {
+	type unknown struct{ schema.Attr }
+	_, err := snapshot.Marshal(schema.NewRealm(schema.New("public").AddAttrs(&unknown{})))
+	require.EqualError(t, err, "sql/snapshot: unregistered type *snapshot_test.unknown")
+
+	owner := &Owner{Columns: []*schema.Column{schema.NewIntColumn("id", "int")}}
+	_, err = snapshot.Marshal(schema.NewRealm(schema.New("public")).AddObjects(owner))
+	require.EqualError(t, err, "sql/snapshot: *schema.Column is not part of the realm")
+
+	_, err = snapshot.Unmarshal([]byte(`{"version": 2}`))
+	require.EqualError(t, err, "sql/snapshot: unsupported snapshot version 2")
+	_, err = snapshot.Unmarshal([]byte(`{"version": 1, "realm": {"attrs": [{"kind": "unknown.Attr"}]}}`))
+	require.EqualError(t, err, `sql/snapshot: unregistered kind "unknown.Attr"`)
+	_, err = snapshot.Unmarshal([]byte(`{"version": 1, "realm": {"schemas": [{"name": "public", "tables": [{"name": "t", "indexes": [{"parts": [{"column": "schemas/0/tables/1/columns/0"}]}]}]}]}}`))
+	require.EqualError(t, err, `sql/snapshot: unknown reference "schemas/0/tables/1/columns/0"`)
+}
+
+func TestStateReader(t *testing.T) {
+	r := newRealm()
+	data, err := snapshot.Marshal(r)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	got, err := snapshot.StateReader(path).ReadState(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, r, got)
+
+	_, err = snapshot.StateReader(filepath.Join(t.TempDir(), "missing.json")).ReadState(context.Background())
+	require.Error(t, err)
+}
+
+func newRealm() *schema.Realm {
+	users := schema.NewTable("users").
+		AddColumns(
+			schema.NewIntColumn("id", "bigint"),
+			schema.NewStringColumn("name", "varchar", schema.StringSize(255)).SetComment("name").SetDefault(&schema.Literal{V: "'a8m'"}),
+			schema.NewNullColumn("tags").SetType(&ElemType{Type: &schema.StringType{T: "text"}, T: "text[]"}),
+			schema.NewTimeColumn("created_at", "timestamp", schema.TimePrecision(6)).SetDefault(&schema.RawExpr{X: "CURRENT_TIMESTAMP"}),
+		).
+		SetComment("users table")
+	users.SetPrimaryKey(schema.NewPrimaryKey(users.Columns[0]))
+	users.AddIndexes(
+		schema.NewUniqueIndex("name").AddParts(
+			schema.NewColumnPart(users.Columns[1]).SetDesc(true),
+			schema.NewExprPart(&schema.RawExpr{X: "lower(name)"}),
+		),
+	)
+	users.AddChecks(schema.NewCheck().SetName("positive").SetExpr("id > 0"))
+	pets := schema.NewTable("pets").AddColumns(schema.NewIntColumn("id", "bigint"), schema.NewNullIntColumn("owner_id", "bigint"))
+	pets.AddForeignKeys(
+		schema.NewForeignKey("owner").
+			AddColumns(pets.Columns[1]).
+			SetRefTable(users).
+			AddRefColumns(users.Columns[0]).
+			SetOnDelete(schema.Cascade),
+	)
+	public, other := schema.New("public").SetCharset("utf8"), schema.New("other")
+	public.AddTables(users)
+	other.AddTables(pets)
+	owner := &Owner{Name: "a8m", Schema: public, Columns: users.Columns[1:2], Options: map[string]int64{"max": 1 << 62}}
+	users.AddAttrs(&Owned{By: owner})
+	return schema.NewRealm(public, other).SetCollation("utf8_bin").AddObjects(owner)
+}
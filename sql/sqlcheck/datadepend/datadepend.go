@@ -43,9 +43,11 @@ func New(opts Options) *Analyzer {
 
 // Analyze implements sqlcheck.Analyzer.
 func (a *Analyzer) Analyze(ctx context.Context, p *sqlcheck.Pass) error {
-	f, ok := drivers.Load(p.Dev.Name)
-	if ok {
-		return f.(func(context.Context, *Analyzer, *sqlcheck.Pass) error)(ctx, a, p)
+	// The dev driver is not available in case the changes were parsed.
+	if p.Dev != nil {
+		if f, ok := drivers.Load(p.Dev.Name); ok {
+			return f.(func(context.Context, *Analyzer, *sqlcheck.Pass) error)(ctx, a, p)
+		}
 	}
 	// Fallback to the default implementation.
 	a.Report(p, a.Diagnostics(ctx, p))
@@ -0,0 +1,270 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package incompat provides an analyzer that detects backward-incompatible
+// changes, i.e. changes that do not lose data, but may break older versions
+// of the application that still run against the database during a rolling
+// deployment. For example, renaming a column or narrowing its type.
+package incompat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/schemahcl"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlcheck"
+)
+
+type (
+	// Options defines the configuration options
+	// for the backward-incompatible changes checker.
+	Options struct {
+		// RenameTable indicates if the analyzer should check for table renaming.
+		RenameTable *bool `spec:"rename_table,omitempty"`
+
+		// RenameColumn indicates if the analyzer should check for column renaming.
+		RenameColumn *bool `spec:"rename_column,omitempty"`
+
+		// NarrowType indicates if the analyzer should check for column type
+		// changes that narrow the type (or change its kind) of a column.
+		NarrowType *bool `spec:"narrow_type,omitempty"`
+
+		// NotNull indicates if the analyzer should check for
+		// nullable columns that were changed to NOT NULL.
+		NotNull *bool `spec:"not_null,omitempty"`
+
+		// EnumValues indicates if the analyzer should check for
+		// values that were removed from enum columns.
+		EnumValues *bool `spec:"enum_values,omitempty"`
+
+		// Allow drivers to extend the configuration.
+		schemahcl.DefaultExtension
+	}
+
+	// Analyzer checks for backward-incompatible changes.
+	Analyzer struct {
+		Options
+	}
+)
+
+// New creates a new backward-incompatible changes Analyzer with the given options.
+func New(opts Options) *Analyzer {
+	a := &Analyzer{}
+	a.RenameTable = abool(opts.RenameTable, true)
+	a.RenameColumn = abool(opts.RenameColumn, true)
+	a.NarrowType = abool(opts.NarrowType, true)
+	a.NotNull = abool(opts.NotNull, true)
+	a.EnumValues = abool(opts.EnumValues, true)
+	return a
+}
+
+// Analyze implements sqlcheck.Analyzer.
+func (a *Analyzer) Analyze(_ context.Context, p *sqlcheck.Pass) error {
+	var diags []sqlcheck.Diagnostic
+	for _, sc := range p.File.Changes {
+		for _, c := range sc.Changes {
+			switch c := c.(type) {
+			case *schema.RenameTable:
+				// Tables created in this file are unknown to older application versions.
+				if *a.RenameTable && p.File.TableSpan(c.To)&sqlcheck.SpanAdded == 0 {
+					diags = append(diags, sqlcheck.Diagnostic{
+						Pos:  sc.Pos,
+						Text: fmt.Sprintf("Renaming table %q to %q", c.From.Name, c.To.Name),
+					})
+				}
+			case *schema.ModifyTable:
+				if p.File.TableSpan(c.T)&sqlcheck.SpanAdded != 0 {
+					continue
+				}
+				for _, text := range a.columnChanges(p, c) {
+					diags = append(diags, sqlcheck.Diagnostic{Pos: sc.Pos, Text: text})
+				}
+			}
+		}
+	}
+	if len(diags) > 0 {
+		p.Reporter.WriteReport(sqlcheck.Report{
+			Text:        fmt.Sprintf("Backward incompatible changes detected in file %s", p.File.Name()),
+			Diagnostics: diags,
+		})
+	}
+	return nil
+}
+
+// columnChanges returns the diagnostics texts of the column changes of the table.
+func (a *Analyzer) columnChanges(p *sqlcheck.Pass, m *schema.ModifyTable) (texts []string) {
+	for _, c := range m.Changes {
+		switch c := c.(type) {
+		case *schema.RenameColumn:
+			if *a.RenameColumn && p.File.ColumnSpan(m.T, c.To)&sqlcheck.SpanAdded == 0 {
+				texts = append(texts, fmt.Sprintf("Renaming column %q to %q in table %q", c.From.Name, c.To.Name, m.T.Name))
+			}
+		case *schema.ModifyColumn:
+			// Columns added in this file are unknown to older application versions.
+			if p.File.ColumnSpan(m.T, c.To)&sqlcheck.SpanAdded != 0 {
+				continue
+			}
+			if *a.NotNull && c.Change.Is(schema.ChangeNull) && c.From.Type.Null && !c.To.Type.Null {
+				texts = append(texts, fmt.Sprintf("Modifying nullable column %q to non-nullable in table %q", c.To.Name, m.T.Name))
+			}
+			if !c.Change.Is(schema.ChangeType) {
+				continue
+			}
+			from, to := c.From.Type.Type, c.To.Type.Type
+			if fe, ok := from.(*schema.EnumType); ok {
+				if te, ok := to.(*schema.EnumType); ok {
+					if *a.EnumValues {
+						if vs := removed(fe.Values, te.Values); len(vs) > 0 {
+							texts = append(texts, fmt.Sprintf("Removing enum values %s from column %q in table %q", strings.Join(vs, ", "), c.To.Name, m.T.Name))
+						}
+					}
+					continue
+				}
+			}
+			if *a.NarrowType && narrows(from, to) {
+				texts = append(texts, fmt.Sprintf("Changing the type of column %q in table %q from %q to %q", c.To.Name, m.T.Name, typeString(c.From), typeString(c.To)))
+			}
+		}
+	}
+	return texts
+}
+
+// narrows reports if changing a column type from one type to
+// another may reject (or truncate) values that are valid today.
+func narrows(from, to schema.Type) bool {
+	switch from := from.(type) {
+	case nil, *schema.UnsupportedType:
+		// Unknown types cannot be compared.
+		return false
+	case *schema.StringType:
+		if to, ok := to.(*schema.StringType); ok {
+			return narrowsSize(from.Size, to.Size)
+		}
+	case *schema.BinaryType:
+		if to, ok := to.(*schema.BinaryType); ok {
+			return narrowsSize(from.Size, to.Size)
+		}
+	case *schema.IntegerType:
+		if to, ok := to.(*schema.IntegerType); ok {
+			fb, tb := intBits(from.T), intBits(to.T)
+			switch {
+			case fb == 0 || tb == 0:
+				return false
+			case !from.Unsigned && to.Unsigned:
+				// Negative values are rejected.
+				return true
+			case from.Unsigned && !to.Unsigned:
+				return tb <= fb
+			default:
+				return tb < fb
+			}
+		}
+	case *schema.DecimalType:
+		if to, ok := to.(*schema.DecimalType); ok {
+			return to.Scale < from.Scale || to.Precision-to.Scale < from.Precision-from.Scale || !from.Unsigned && to.Unsigned
+		}
+	case *schema.EnumType:
+		// Enum values can be stored in string columns.
+		if _, ok := to.(*schema.StringType); ok {
+			return false
+		}
+	default:
+		if _, ok := to.(*schema.UnsupportedType); ok {
+			return false
+		}
+		// Changing the kind of the type (e.g. string to integer) is always
+		// reported. Changes within the same kind are not considered as narrowing.
+		return fmt.Sprintf("%T", from) != fmt.Sprintf("%T", to)
+	}
+	_, ok := to.(*schema.UnsupportedType)
+	return !ok
+}
+
+// narrowsSize reports if changing the size of a string or binary type narrows it.
+// Unsized types (e.g. text or blob) are considered wider than any sized type.
+func narrowsSize(from, to int) bool {
+	return to > 0 && (from == 0 || to < from)
+}
+
+// intBits returns the size in bits of an integer type, or 0 if it is unknown.
+func intBits(t string) int {
+	switch strings.ToLower(t) {
+	case "tinyint", "int1":
+		return 8
+	case "smallint", "int2":
+		return 16
+	case "mediumint", "int3":
+		return 24
+	case "int", "integer", "int4":
+		return 32
+	case "bigint", "int8":
+		return 64
+	}
+	return 0
+}
+
+// removed returns the values that exist in the first list, but not in the second.
+func removed(from, to []string) []string {
+	exists := make(map[string]bool, len(to))
+	for _, v := range to {
+		exists[v] = true
+	}
+	var vs []string
+	for _, v := range from {
+		if !exists[v] {
+			vs = append(vs, fmt.Sprintf("%q", v))
+		}
+	}
+	return vs
+}
+
+// typeString returns the string representation of the column type.
+func typeString(c *schema.Column) string {
+	if c.Type.Raw != "" {
+		return c.Type.Raw
+	}
+	switch t := c.Type.Type.(type) {
+	case *schema.StringType:
+		if t.Size > 0 {
+			return fmt.Sprintf("%s(%d)", t.T, t.Size)
+		}
+		return t.T
+	case *schema.BinaryType:
+		if t.Size > 0 {
+			return fmt.Sprintf("%s(%d)", t.T, t.Size)
+		}
+		return t.T
+	case *schema.DecimalType:
+		return fmt.Sprintf("%s(%d,%d)", t.T, t.Precision, t.Scale)
+	case *schema.IntegerType:
+		if t.Unsigned {
+			return t.T + " unsigned"
+		}
+		return t.T
+	case *schema.EnumType:
+		return t.T
+	case *schema.FloatType:
+		return t.T
+	case *schema.TimeType:
+		return t.T
+	case *schema.JSONType:
+		return t.T
+	case *schema.BoolType:
+		return t.T
+	case *schema.SpatialType:
+		return t.T
+	case *schema.UnsupportedType:
+		return t.T
+	}
+	return fmt.Sprintf("%T", c.Type.Type)
+}
+
+func abool(p *bool, v bool) *bool {
+	if p != nil {
+		return p
+	}
+	return &v
+}
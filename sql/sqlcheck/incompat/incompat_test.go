@@ -0,0 +1,368 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package incompat_test
+
+import (
+	"context"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlcheck"
+	"ariga.io/atlas/sql/sqlcheck/incompat"
+	"ariga.io/atlas/sql/sqlclient"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzer_Rename(t *testing.T) {
+	var (
+		report *sqlcheck.Report
+		s      = schema.New("test")
+		users  = schema.NewTable("users").SetSchema(s)
+		pets   = schema.NewTable("pets").SetSchema(s)
+		pass   = &sqlcheck.Pass{
+			Dev: &sqlclient.Client{},
+			File: &sqlcheck.File{
+				File: testFile{name: "1.sql"},
+				Changes: []*sqlcheck.Change{
+					{
+						Stmt: "ALTER TABLE `user` RENAME TO `users`",
+						Changes: schema.Changes{
+							&schema.RenameTable{
+								From: schema.NewTable("user").SetSchema(s),
+								To:   users,
+							},
+						},
+					},
+					{
+						Stmt: "ALTER TABLE `users` RENAME COLUMN `name` TO `first_name`",
+						Pos:  37,
+						Changes: schema.Changes{
+							&schema.ModifyTable{
+								T: users,
+								Changes: schema.Changes{
+									&schema.RenameColumn{
+										From: schema.NewStringColumn("name", "varchar"),
+										To:   schema.NewStringColumn("first_name", "varchar"),
+									},
+								},
+							},
+						},
+					},
+					// Tables created in this file are skipped.
+					{
+						Stmt: "CREATE TABLE `pet` (`name` varchar(255))",
+						Changes: schema.Changes{
+							&schema.AddTable{T: pets},
+						},
+					},
+					{
+						Stmt: "ALTER TABLE `pet` RENAME TO `pets`",
+						Changes: schema.Changes{
+							&schema.RenameTable{
+								From: schema.NewTable("pet").SetSchema(s),
+								To:   pets,
+							},
+						},
+					},
+					{
+						Stmt: "ALTER TABLE `pets` RENAME COLUMN `name` TO `nickname`",
+						Changes: schema.Changes{
+							&schema.ModifyTable{
+								T: pets,
+								Changes: schema.Changes{
+									&schema.RenameColumn{
+										From: schema.NewStringColumn("name", "varchar"),
+										To:   schema.NewStringColumn("nickname", "varchar"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Reporter: sqlcheck.ReportWriterFunc(func(r sqlcheck.Report) {
+				report = &r
+			}),
+		}
+	)
+	az := incompat.New(incompat.Options{})
+	err := az.Analyze(context.Background(), pass)
+	require.NoError(t, err)
+	require.Equal(t, `Backward incompatible changes detected in file 1.sql`, report.Text)
+	require.Len(t, report.Diagnostics, 2)
+	require.Equal(t, `Renaming table "user" to "users"`, report.Diagnostics[0].Text)
+	require.Equal(t, `Renaming column "name" to "first_name" in table "users"`, report.Diagnostics[1].Text)
+	require.Equal(t, 37, report.Diagnostics[1].Pos)
+
+	report = nil
+	az = incompat.New(incompat.Options{RenameTable: new(bool), RenameColumn: new(bool)})
+	err = az.Analyze(context.Background(), pass)
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+func TestAnalyzer_ModifyColumn(t *testing.T) {
+	var (
+		report *sqlcheck.Report
+		users  = schema.NewTable("users").SetSchema(schema.New("test"))
+		modify = func(from, to *schema.Column, k schema.ChangeKind) schema.Change {
+			return &schema.ModifyColumn{From: from, To: to, Change: k}
+		}
+		column = func(name, raw string, t schema.Type, null bool) *schema.Column {
+			return &schema.Column{Name: name, Type: &schema.ColumnType{Raw: raw, Type: t, Null: null}}
+		}
+		pass = &sqlcheck.Pass{
+			Dev: &sqlclient.Client{},
+			File: &sqlcheck.File{
+				File: testFile{name: "1.sql"},
+				Changes: []*sqlcheck.Change{
+					{
+						Stmt: "ALTER TABLE `users` ...",
+						Changes: schema.Changes{
+							&schema.ModifyTable{
+								T: users,
+								Changes: schema.Changes{
+									// Narrowing.
+									modify(
+										column("name", "varchar(255)", &schema.StringType{T: "varchar", Size: 255}, false),
+										column("name", "varchar(64)", &schema.StringType{T: "varchar", Size: 64}, false),
+										schema.ChangeType,
+									),
+									modify(
+										column("id", "bigint", &schema.IntegerType{T: "bigint"}, false),
+										column("id", "int", &schema.IntegerType{T: "int"}, false),
+										schema.ChangeType,
+									),
+									modify(
+										column("score", "int", &schema.IntegerType{T: "int"}, false),
+										column("score", "int unsigned", &schema.IntegerType{T: "int", Unsigned: true}, false),
+										schema.ChangeType,
+									),
+									modify(
+										column("price", "decimal(10,2)", &schema.DecimalType{T: "decimal", Precision: 10, Scale: 2}, false),
+										column("price", "decimal(10,0)", &schema.DecimalType{T: "decimal", Precision: 10}, false),
+										schema.ChangeType,
+									),
+									modify(
+										column("zip", "varchar(10)", &schema.StringType{T: "varchar", Size: 10}, false),
+										column("zip", "int", &schema.IntegerType{T: "int"}, false),
+										schema.ChangeType,
+									),
+									// Widening.
+									modify(
+										column("bio", "varchar(64)", &schema.StringType{T: "varchar", Size: 64}, false),
+										column("bio", "varchar(255)", &schema.StringType{T: "varchar", Size: 255}, false),
+										schema.ChangeType,
+									),
+									modify(
+										column("count", "int", &schema.IntegerType{T: "int"}, false),
+										column("count", "bigint", &schema.IntegerType{T: "bigint"}, false),
+										schema.ChangeType,
+									),
+									modify(
+										column("total", "int unsigned", &schema.IntegerType{T: "int", Unsigned: true}, false),
+										column("total", "bigint", &schema.IntegerType{T: "bigint"}, false),
+										schema.ChangeType,
+									),
+									modify(
+										column("kind", "enum('a','b')", &schema.EnumType{T: "enum", Values: []string{"a", "b"}}, false),
+										column("kind", "varchar(255)", &schema.StringType{T: "varchar", Size: 255}, false),
+										schema.ChangeType,
+									),
+									// Null and enum changes.
+									modify(
+										column("email", "varchar(255)", &schema.StringType{T: "varchar", Size: 255}, true),
+										column("email", "varchar(255)", &schema.StringType{T: "varchar", Size: 255}, false),
+										schema.ChangeNull,
+									),
+									modify(
+										column("phone", "varchar(255)", &schema.StringType{T: "varchar", Size: 255}, false),
+										column("phone", "varchar(255)", &schema.StringType{T: "varchar", Size: 255}, true),
+										schema.ChangeNull,
+									),
+									modify(
+										column("state", "enum('a','b','c')", &schema.EnumType{T: "enum", Values: []string{"a", "b", "c"}}, false),
+										column("state", "enum('a','d')", &schema.EnumType{T: "enum", Values: []string{"a", "d"}}, false),
+										schema.ChangeType,
+									),
+									modify(
+										column("status", "enum('a')", &schema.EnumType{T: "enum", Values: []string{"a"}}, false),
+										column("status", "enum('a','b')", &schema.EnumType{T: "enum", Values: []string{"a", "b"}}, false),
+										schema.ChangeType,
+									),
+								},
+							},
+						},
+					},
+				},
+			},
+			Reporter: sqlcheck.ReportWriterFunc(func(r sqlcheck.Report) {
+				report = &r
+			}),
+		}
+	)
+	az := incompat.New(incompat.Options{})
+	err := az.Analyze(context.Background(), pass)
+	require.NoError(t, err)
+	require.Equal(t, `Backward incompatible changes detected in file 1.sql`, report.Text)
+	require.Len(t, report.Diagnostics, 7)
+	require.Equal(t, `Changing the type of column "name" in table "users" from "varchar(255)" to "varchar(64)"`, report.Diagnostics[0].Text)
+	require.Equal(t, `Changing the type of column "id" in table "users" from "bigint" to "int"`, report.Diagnostics[1].Text)
+	require.Equal(t, `Changing the type of column "score" in table "users" from "int" to "int unsigned"`, report.Diagnostics[2].Text)
+	require.Equal(t, `Changing the type of column "price" in table "users" from "decimal(10,2)" to "decimal(10,0)"`, report.Diagnostics[3].Text)
+	require.Equal(t, `Changing the type of column "zip" in table "users" from "varchar(10)" to "int"`, report.Diagnostics[4].Text)
+	require.Equal(t, `Modifying nullable column "email" to non-nullable in table "users"`, report.Diagnostics[5].Text)
+	require.Equal(t, `Removing enum values "b", "c" from column "state" in table "users"`, report.Diagnostics[6].Text)
+
+	report = nil
+	az = incompat.New(incompat.Options{NarrowType: new(bool), NotNull: new(bool), EnumValues: new(bool)})
+	err = az.Analyze(context.Background(), pass)
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+func TestAnalyzer_NarrowType(t *testing.T) {
+	for _, tt := range []struct {
+		from, to *schema.ColumnType
+		report   bool
+	}{
+		{
+			from:   &schema.ColumnType{Raw: "text", Type: &schema.StringType{T: "text"}},
+			to:     &schema.ColumnType{Raw: "varchar(64)", Type: &schema.StringType{T: "varchar", Size: 64}},
+			report: true,
+		},
+		{
+			from:   &schema.ColumnType{Raw: "varchar(64)", Type: &schema.StringType{T: "varchar", Size: 64}},
+			to:     &schema.ColumnType{Raw: "text", Type: &schema.StringType{T: "text"}},
+			report: false,
+		},
+		{
+			from:   &schema.ColumnType{Raw: "text", Type: &schema.StringType{T: "text"}},
+			to:     &schema.ColumnType{Raw: "longtext", Type: &schema.StringType{T: "longtext"}},
+			report: false,
+		},
+		{
+			from:   &schema.ColumnType{Raw: "varchar(64)", Type: &schema.StringType{T: "varchar", Size: 64}},
+			to:     &schema.ColumnType{Raw: "char(32)", Type: &schema.StringType{T: "char", Size: 32}},
+			report: true,
+		},
+		{
+			from:   &schema.ColumnType{Raw: "blob", Type: &schema.BinaryType{T: "blob"}},
+			to:     &schema.ColumnType{Raw: "varbinary(16)", Type: &schema.BinaryType{T: "varbinary", Size: 16}},
+			report: true,
+		},
+		{
+			from:   &schema.ColumnType{Raw: "varbinary(16)", Type: &schema.BinaryType{T: "varbinary", Size: 16}},
+			to:     &schema.ColumnType{Raw: "blob", Type: &schema.BinaryType{T: "blob"}},
+			report: false,
+		},
+		{
+			from:   &schema.ColumnType{Raw: "binary(16)", Type: &schema.BinaryType{T: "binary", Size: 16}},
+			to:     &schema.ColumnType{Raw: "binary(8)", Type: &schema.BinaryType{T: "binary", Size: 8}},
+			report: true,
+		},
+	} {
+		t.Run(tt.from.Raw+" to "+tt.to.Raw, func(t *testing.T) {
+			var (
+				report *sqlcheck.Report
+				users  = schema.NewTable("users").SetSchema(schema.New("test"))
+				pass   = &sqlcheck.Pass{
+					Dev: &sqlclient.Client{},
+					File: &sqlcheck.File{
+						File: testFile{name: "1.sql"},
+						Changes: []*sqlcheck.Change{
+							{
+								Stmt: "ALTER TABLE `users` ...",
+								Changes: schema.Changes{
+									&schema.ModifyTable{
+										T: users,
+										Changes: schema.Changes{
+											&schema.ModifyColumn{
+												From:   &schema.Column{Name: "c", Type: tt.from},
+												To:     &schema.Column{Name: "c", Type: tt.to},
+												Change: schema.ChangeType,
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+					Reporter: sqlcheck.ReportWriterFunc(func(r sqlcheck.Report) {
+						report = &r
+					}),
+				}
+			)
+			err := incompat.New(incompat.Options{}).Analyze(context.Background(), pass)
+			require.NoError(t, err)
+			if !tt.report {
+				require.Nil(t, report)
+				return
+			}
+			require.NotNil(t, report)
+			require.Len(t, report.Diagnostics, 1)
+			require.Equal(t, `Changing the type of column "c" in table "users" from "`+tt.from.Raw+`" to "`+tt.to.Raw+`"`, report.Diagnostics[0].Text)
+		})
+	}
+}
+
+func TestAnalyzer_SkipAddedColumn(t *testing.T) {
+	var (
+		report *sqlcheck.Report
+		users  = schema.NewTable("users").SetSchema(schema.New("test"))
+		pass   = &sqlcheck.Pass{
+			Dev: &sqlclient.Client{},
+			File: &sqlcheck.File{
+				File: testFile{name: "1.sql"},
+				Changes: []*sqlcheck.Change{
+					{
+						Stmt: "ALTER TABLE `users` ADD COLUMN `name` varchar(255) NULL",
+						Changes: schema.Changes{
+							&schema.ModifyTable{
+								T: users,
+								Changes: schema.Changes{
+									&schema.AddColumn{C: schema.NewNullStringColumn("name", "varchar", schema.StringSize(255))},
+								},
+							},
+						},
+					},
+					{
+						Stmt: "ALTER TABLE `users` MODIFY COLUMN `name` varchar(64) NOT NULL",
+						Changes: schema.Changes{
+							&schema.ModifyTable{
+								T: users,
+								Changes: schema.Changes{
+									&schema.ModifyColumn{
+										From:   schema.NewNullStringColumn("name", "varchar", schema.StringSize(255)),
+										To:     schema.NewStringColumn("name", "varchar", schema.StringSize(64)),
+										Change: schema.ChangeType | schema.ChangeNull,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Reporter: sqlcheck.ReportWriterFunc(func(r sqlcheck.Report) {
+				report = &r
+			}),
+		}
+	)
+	az := incompat.New(incompat.Options{})
+	err := az.Analyze(context.Background(), pass)
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+type testFile struct {
+	name string
+	migrate.File
+}
+
+func (t testFile) Name() string {
+	return t.name
+}
@@ -0,0 +1,561 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+// Package sqlconvert converts schema.Realm objects that were inspected or evaluated
+// for one SQL dialect to their equivalents in another dialect. Elements that have no
+// exact equivalent in the target dialect are reported as a list of losses.
+package sqlconvert
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlite"
+)
+
+// List of supported dialects.
+const (
+	MySQL    = "mysql"
+	Postgres = "postgres"
+	SQLite   = "sqlite"
+)
+
+// Dialects holds the names of the supported dialects.
+var Dialects = []string{MySQL, Postgres, SQLite}
+
+// A Loss describes a schema element that was converted with a loss of information.
+type Loss struct {
+	Path   string // Qualified name of the element (e.g. "public.users.id").
+	Reason string // Description of the loss.
+}
+
+// String implements the fmt.Stringer interface.
+func (l *Loss) String() string {
+	if l.Path == "" {
+		return l.Reason
+	}
+	return fmt.Sprintf("%s: %s", l.Path, l.Reason)
+}
+
+// Convert rewrites the types and attributes of the given realm from the source
+// dialect to the target dialect. The realm is modified in place, and the returned
+// losses describe the elements that have no exact equivalent in the target dialect.
+// A realm with a single schema is mapped to the "main" database when converted to
+// SQLite, and the "main" database of SQLite is mapped to the "public" schema when
+// converted to PostgreSQL.
+//
+//	losses, err := sqlconvert.Convert(r, sqlconvert.MySQL, sqlconvert.Postgres)
+//	if err != nil {
+//		return err
+//	}
+//	for _, l := range losses {
+//		fmt.Println(l)
+//	}
+func Convert(r *schema.Realm, from, to string) ([]*Loss, error) {
+	for _, d := range []string{from, to} {
+		if _, ok := formatters[d]; !ok {
+			return nil, fmt.Errorf("sqlconvert: unsupported dialect %q", d)
+		}
+	}
+	if from == to {
+		return nil, nil
+	}
+	c := &converter{from: from, to: to}
+	c.realm(r)
+	return c.losses, nil
+}
+
+// exprs maps common expressions, in lower case and without enclosing
+// parentheses, to their equivalents in each dialect.
+var exprs = map[string]map[string]string{
+	"current_timestamp":   {MySQL: "CURRENT_TIMESTAMP", Postgres: "CURRENT_TIMESTAMP", SQLite: "CURRENT_TIMESTAMP"},
+	"current_timestamp()": {MySQL: "CURRENT_TIMESTAMP", Postgres: "CURRENT_TIMESTAMP", SQLite: "CURRENT_TIMESTAMP"},
+	"now()":               {MySQL: "CURRENT_TIMESTAMP", Postgres: "CURRENT_TIMESTAMP", SQLite: "CURRENT_TIMESTAMP"},
+	"localtimestamp":      {MySQL: "CURRENT_TIMESTAMP", Postgres: "CURRENT_TIMESTAMP", SQLite: "CURRENT_TIMESTAMP"},
+	"current_date":        {MySQL: "(CURRENT_DATE)", Postgres: "CURRENT_DATE", SQLite: "CURRENT_DATE"},
+	"curdate()":           {MySQL: "(CURRENT_DATE)", Postgres: "CURRENT_DATE", SQLite: "CURRENT_DATE"},
+	"current_time":        {MySQL: "(CURRENT_TIME)", Postgres: "CURRENT_TIME", SQLite: "CURRENT_TIME"},
+	"curtime()":           {MySQL: "(CURRENT_TIME)", Postgres: "CURRENT_TIME", SQLite: "CURRENT_TIME"},
+	"uuid()":              {MySQL: "(uuid())", Postgres: "gen_random_uuid()"},
+	"gen_random_uuid()":   {MySQL: "(uuid())", Postgres: "gen_random_uuid()"},
+}
+
+// collations maps the collations of each dialect, in lower case, to their equivalents
+// in the other dialects. Collations that are missing from the table are dropped.
+var collations = map[string]map[string]map[string]string{
+	MySQL: {
+		"binary":             {Postgres: "C", SQLite: "BINARY"},
+		"ascii_bin":          {Postgres: "C", SQLite: "BINARY"},
+		"latin1_bin":         {Postgres: "C", SQLite: "BINARY"},
+		"utf8_bin":           {Postgres: "C", SQLite: "BINARY"},
+		"utf8mb3_bin":        {Postgres: "C", SQLite: "BINARY"},
+		"utf8mb4_bin":        {Postgres: "C", SQLite: "BINARY"},
+		"ascii_general_ci":   {SQLite: "NOCASE"},
+		"latin1_swedish_ci":  {SQLite: "NOCASE"},
+		"utf8_general_ci":    {SQLite: "NOCASE"},
+		"utf8mb3_general_ci": {SQLite: "NOCASE"},
+		"utf8mb4_general_ci": {SQLite: "NOCASE"},
+		"utf8mb4_unicode_ci": {SQLite: "NOCASE"},
+		"utf8mb4_0900_ai_ci": {SQLite: "NOCASE"},
+	},
+	Postgres: {
+		"c":     {MySQL: "utf8mb4_bin", SQLite: "BINARY"},
+		"posix": {MySQL: "utf8mb4_bin", SQLite: "BINARY"},
+	},
+	SQLite: {
+		"binary": {MySQL: "utf8mb4_bin", Postgres: "C"},
+		"nocase": {MySQL: "utf8mb4_general_ci"},
+	},
+}
+
+// indexTypes maps index types to their equivalents in each dialect.
+// An empty value stands for the default index type of the dialect.
+var indexTypes = map[string]map[string]string{
+	MySQL:    {"BTREE": "", "HASH": mysql.IndexTypeHash},
+	Postgres: {"BTREE": "", "HASH": postgres.IndexTypeHash},
+	SQLite:   {"BTREE": ""},
+}
+
+// converter holds the state of a single conversion.
+type converter struct {
+	from, to string
+	losses   []*Loss
+}
+
+// lossf records a loss for the element in the given path.
+func (c *converter) lossf(path, format string, args ...interface{}) {
+	c.losses = append(c.losses, &Loss{Path: path, Reason: fmt.Sprintf(format, args...)})
+}
+
+// path returns the qualified name of a table element.
+func (c *converter) path(t *schema.Table, name string) string {
+	p := t.Name
+	if t.Schema != nil {
+		p = t.Schema.Name + "." + p
+	}
+	if name != "" {
+		p += "." + name
+	}
+	return p
+}
+
+func (c *converter) realm(r *schema.Realm) {
+	r.Attrs = comments(r.Attrs)
+	for _, o := range r.Objects {
+		c.lossf("", "%s is dropped", objectName(o))
+	}
+	r.Objects = nil
+	// A single schema is mapped to the main database of SQLite,
+	// and the main database is mapped to the public schema.
+	switch {
+	case len(r.Schemas) != 1:
+	case c.to == SQLite:
+		r.Schemas[0].Name = "main"
+	case c.to == Postgres && r.Schemas[0].Name == "main":
+		r.Schemas[0].Name = "public"
+	}
+	for _, s := range r.Schemas {
+		c.schema(s)
+	}
+}
+
+func (c *converter) schema(s *schema.Schema) {
+	var coll schema.Collation
+	for _, a := range s.Attrs {
+		if a, ok := a.(*schema.Collation); ok {
+			coll = *a
+			c.lossf(s.Name, "collation %q is dropped", a.V)
+		}
+	}
+	s.Attrs = comments(s.Attrs)
+	for _, o := range s.Objects {
+		c.lossf(s.Name, "%s is dropped", objectName(o))
+	}
+	s.Objects = nil
+	// Foreign-key names are unique per schema in MySQL, and index names share
+	// the namespace of the tables in the other dialects.
+	names := make(map[string]struct{})
+	for _, t := range s.Tables {
+		if c.to != MySQL {
+			names[t.Name] = struct{}{}
+		}
+	}
+	for _, t := range s.Tables {
+		c.table(t, coll.V, names)
+	}
+}
+
+func (c *converter) table(t *schema.Table, coll string, names map[string]struct{}) {
+	var (
+		start int64
+		attrs []schema.Attr
+		path  = c.path(t, "")
+	)
+	for _, a := range t.Attrs {
+		switch a := a.(type) {
+		case *schema.Comment:
+			attrs = append(attrs, a)
+		case *schema.Check:
+			attrs = append(attrs, c.check(t, a))
+		case *mysql.AutoIncrement:
+			start = a.V
+		case *schema.Collation:
+			if a.V != coll {
+				c.lossf(path, "collation %q is dropped", a.V)
+			}
+		case *schema.Charset, *mysql.CreateOptions, *mysql.CreateStmt, *sqlite.CreateStmt, *sqlite.WithoutRowID:
+		default:
+			c.lossf(path, "attribute %s is dropped", attrName(a))
+		}
+	}
+	t.Attrs = attrs
+	for _, col := range t.Columns {
+		c.column(t, col, start)
+	}
+	if t.PrimaryKey != nil {
+		c.index(t, t.PrimaryKey)
+	}
+	for _, idx := range t.Indexes {
+		c.index(t, idx)
+		if c.to != MySQL {
+			idx.Name = c.unique(names, t, idx.Name)
+		}
+	}
+	for _, fk := range t.ForeignKeys {
+		c.foreignKey(t, fk)
+		if c.to == MySQL {
+			fk.Symbol = c.unique(names, t, fk.Symbol)
+		}
+	}
+}
+
+func (c *converter) column(t *schema.Table, col *schema.Column, start int64) {
+	var (
+		auto  bool
+		attrs []schema.Attr
+		path  = c.path(t, col.Name)
+	)
+	for _, a := range col.Attrs {
+		switch a := a.(type) {
+		case *schema.Comment:
+			attrs = append(attrs, a)
+		case *schema.Charset:
+			c.lossf(path, "charset %q is dropped", a.V)
+		case *schema.Collation:
+			if v, ok := c.collation(a.V); ok {
+				attrs = append(attrs, &schema.Collation{V: v})
+			} else if !strings.EqualFold(a.V, "default") {
+				c.lossf(path, "collation %q is dropped", a.V)
+			}
+		case *schema.GeneratedExpr:
+			x := &schema.GeneratedExpr{Expr: a.Expr, Type: a.Type}
+			// PostgreSQL supports only stored generated columns.
+			if c.to == Postgres && !strings.EqualFold(x.Type, "STORED") {
+				x.Type = "STORED"
+				c.lossf(path, "virtual generated column is converted to a stored one")
+			}
+			attrs = append(attrs, x)
+			c.lossf(path, "generated expression %q is copied as is", a.Expr)
+		case *mysql.OnUpdate:
+			c.lossf(path, "ON UPDATE %s is dropped", a.A)
+		case *mysql.AutoIncrement:
+			auto = true
+		case *sqlite.AutoIncrement:
+			if auto = true; a.Seq > 0 {
+				start = a.Seq + 1
+			}
+		case *postgres.Identity:
+			auto = true
+			if strings.EqualFold(a.Generation, "ALWAYS") {
+				c.lossf(path, "GENERATED ALWAYS is converted to a column that accepts explicit values")
+			}
+			if s := a.Sequence; s != nil {
+				if s.Start > 1 {
+					start = s.Start
+				}
+				if s.Increment > 1 {
+					c.lossf(path, "identity increment %d is dropped", s.Increment)
+				}
+			}
+		default:
+			c.lossf(path, "attribute %s is dropped", attrName(a))
+		}
+	}
+	col.Attrs = attrs
+	// Columns that are backed by sequences are converted to auto-increment columns.
+	if x, ok := col.Default.(*schema.RawExpr); ok && c.from == Postgres && strings.HasPrefix(x.X, "nextval(") {
+		auto, col.Default = true, nil
+	}
+	if _, ok := col.Type.Type.(*postgres.SerialType); ok {
+		auto = true
+	}
+	c.columnType(t, col)
+	c.defaultExpr(path, col)
+	// SQLite allows NULL values in PRIMARY KEY columns.
+	if c.from == SQLite && t.PrimaryKey != nil {
+		for _, p := range t.PrimaryKey.Parts {
+			if p.C == col {
+				col.Type.Null = false
+			}
+		}
+	}
+	if auto {
+		c.autoIncrement(t, col, start)
+	}
+}
+
+// autoIncrement converts the column to an auto-increment column in the target dialect.
+func (c *converter) autoIncrement(t *schema.Table, col *schema.Column, start int64) {
+	path := c.path(t, col.Name)
+	switch c.to {
+	case MySQL:
+		col.AddAttrs(&mysql.AutoIncrement{})
+		if start > 1 {
+			t.AddAttrs(&mysql.AutoIncrement{V: start})
+		}
+	case Postgres:
+		if _, ok := col.Type.Type.(*schema.IntegerType); !ok {
+			c.lossf(path, "AUTO_INCREMENT is supported only on integer columns")
+			return
+		}
+		if start < 1 {
+			start = 1
+		}
+		col.AddAttrs(&postgres.Identity{Generation: "BY DEFAULT", Sequence: &postgres.Sequence{Start: start, Increment: 1}})
+	case SQLite:
+		pk := t.PrimaryKey
+		if pk == nil || len(pk.Parts) != 1 || pk.Parts[0].C != col {
+			c.lossf(path, "AUTOINCREMENT is supported only on INTEGER PRIMARY KEY columns")
+			return
+		}
+		inc := &sqlite.AutoIncrement{}
+		if start > 1 {
+			inc.Seq = start - 1
+		}
+		col.AddAttrs(inc)
+		pk.AddAttrs(inc)
+	}
+}
+
+// defaultExpr converts the default value of the column to the target dialect.
+func (c *converter) defaultExpr(path string, col *schema.Column) {
+	switch x := col.Default.(type) {
+	case *schema.Literal:
+		if _, ok := col.Type.Type.(*schema.BoolType); ok && c.to == Postgres {
+			switch x.V {
+			case "1":
+				x.V = "true"
+			case "0":
+				x.V = "false"
+			}
+		}
+	case *schema.RawExpr:
+		v, ok := c.expr(x.X)
+		if !ok {
+			c.lossf(path, "default expression %q is copied as is", x.X)
+			return
+		}
+		// MySQL requires the precision of CURRENT_TIMESTAMP to match the column.
+		if t, ok := col.Type.Type.(*schema.TimeType); ok && c.to == MySQL && v == "CURRENT_TIMESTAMP" && t.Precision != nil && *t.Precision > 0 {
+			v = fmt.Sprintf("%s(%d)", v, *t.Precision)
+		}
+		x.X = v
+	}
+}
+
+// expr returns the equivalent of the given expression in the target dialect.
+func (c *converter) expr(x string) (string, bool) {
+	k := strings.ToLower(strings.TrimSpace(x))
+	for strings.HasPrefix(k, "(") && strings.HasSuffix(k, ")") {
+		k = strings.TrimSpace(k[1 : len(k)-1])
+	}
+	var p string
+	// Precision of CURRENT_TIMESTAMP(n).
+	if i := strings.IndexByte(k, '('); i > 0 && strings.HasSuffix(k, ")") && strings.Trim(k[i+1:len(k)-1], "0123456789") == "" && len(k) > i+2 {
+		k, p = k[:i], k[i:]
+	}
+	v, ok := exprs[k][c.to]
+	if ok && p != "" && v == "CURRENT_TIMESTAMP" && c.to != SQLite {
+		v += p
+	}
+	return v, ok
+}
+
+// collation returns the equivalent of the given collation in the target dialect.
+func (c *converter) collation(name string) (string, bool) {
+	v, ok := collations[c.from][strings.ToLower(name)][c.to]
+	return v, ok
+}
+
+func (c *converter) index(t *schema.Table, idx *schema.Index) {
+	var (
+		attrs []schema.Attr
+		path  = c.path(t, idx.Name)
+	)
+	for _, a := range idx.Attrs {
+		switch a := a.(type) {
+		case *schema.Comment:
+			attrs = append(attrs, a)
+		case *mysql.IndexType:
+			attrs = c.indexType(path, attrs, a.T)
+		case *postgres.IndexType:
+			attrs = c.indexType(path, attrs, a.T)
+		case *postgres.IndexPredicate:
+			attrs = c.predicate(path, attrs, a.P)
+		case *sqlite.IndexPredicate:
+			attrs = c.predicate(path, attrs, a.P)
+		case *sqlite.AutoIncrement:
+			// Added by autoIncrement on conversion.
+			if c.to == SQLite {
+				attrs = append(attrs, a)
+			}
+		case *postgres.ConType, *sqlite.IndexOrigin:
+		default:
+			c.lossf(path, "attribute %s is dropped", attrName(a))
+		}
+	}
+	idx.Attrs = attrs
+	for _, p := range idx.Parts {
+		var attrs []schema.Attr
+		for _, a := range p.Attrs {
+			switch a := a.(type) {
+			case *mysql.SubPart:
+				c.lossf(path, "prefix length %d of column %q is dropped", a.Len, p.C.Name)
+			case *postgres.IndexColumnProperty:
+			default:
+				c.lossf(path, "attribute %s is dropped", attrName(a))
+			}
+		}
+		p.Attrs = attrs
+		switch {
+		case p.X != nil:
+			if x, ok := p.X.(*schema.RawExpr); ok {
+				c.lossf(path, "index expression %q is copied as is", x.X)
+			}
+		// MySQL requires a prefix length for indexing TEXT and BLOB columns.
+		case c.to == MySQL && p.C != nil && isBlob(p.C.Type.Type):
+			p.Attrs = append(p.Attrs, &mysql.SubPart{Len: 255})
+			c.lossf(path, "prefix length 255 is added to column %q", p.C.Name)
+		}
+	}
+}
+
+// isBlob reports if the given MySQL type is a TEXT or BLOB type.
+func isBlob(t schema.Type) bool {
+	switch t := t.(type) {
+	case *schema.StringType:
+		return t.T == mysql.TypeLongText
+	case *schema.BinaryType:
+		return t.T == mysql.TypeLongBlob
+	}
+	return false
+}
+
+// indexType appends the equivalent of the given index type to the attributes.
+func (c *converter) indexType(path string, attrs []schema.Attr, t string) []schema.Attr {
+	v, ok := indexTypes[c.to][strings.ToUpper(t)]
+	switch {
+	case !ok:
+		c.lossf(path, "index type %s is converted to the default index type", t)
+	case v == "":
+	case c.to == MySQL:
+		attrs = append(attrs, &mysql.IndexType{T: v})
+	case c.to == Postgres:
+		attrs = append(attrs, &postgres.IndexType{T: v})
+	}
+	return attrs
+}
+
+// predicate appends the given partial index predicate to the attributes.
+func (c *converter) predicate(path string, attrs []schema.Attr, p string) []schema.Attr {
+	switch c.to {
+	case Postgres:
+		attrs = append(attrs, &postgres.IndexPredicate{P: p})
+	case SQLite:
+		attrs = append(attrs, &sqlite.IndexPredicate{P: p})
+	default:
+		c.lossf(path, "partial index predicate %q is dropped", p)
+	}
+	return attrs
+}
+
+func (c *converter) foreignKey(t *schema.Table, fk *schema.ForeignKey) {
+	path := c.path(t, fk.Symbol)
+	for _, a := range fk.Attrs {
+		c.lossf(path, "attribute %s is dropped", attrName(a))
+	}
+	fk.Attrs = nil
+	// SET DEFAULT is rejected by the InnoDB storage engine.
+	for _, o := range []*schema.ReferenceOption{&fk.OnUpdate, &fk.OnDelete} {
+		if c.to == MySQL && *o == schema.SetDefault {
+			*o = schema.NoAction
+			c.lossf(path, "SET DEFAULT is converted to NO ACTION")
+		}
+	}
+}
+
+func (c *converter) check(t *schema.Table, ck *schema.Check) *schema.Check {
+	var (
+		attrs []schema.Attr
+		path  = c.path(t, ck.Name)
+	)
+	for _, a := range ck.Attrs {
+		switch a := a.(type) {
+		case *mysql.Enforced:
+			if !a.V {
+				c.lossf(path, "NOT ENFORCED is dropped")
+			}
+		case *postgres.NoInherit:
+		default:
+			c.lossf(path, "attribute %s is dropped", attrName(a))
+		}
+	}
+	c.lossf(path, "check expression %q is copied as is", ck.Expr)
+	return &schema.Check{Name: ck.Name, Expr: ck.Expr, Attrs: attrs}
+}
+
+// unique returns a name that is unique in the given set. Conflicting
+// names are prefixed with the name of the table that owns them.
+func (c *converter) unique(names map[string]struct{}, t *schema.Table, name string) string {
+	if name == "" {
+		return name
+	}
+	if _, ok := names[name]; ok {
+		v := fmt.Sprintf("%s_%s", t.Name, name)
+		c.lossf(c.path(t, name), "%q is renamed to %q to avoid a name conflict", name, v)
+		name = v
+	}
+	names[name] = struct{}{}
+	return name
+}
+
+// comments returns only the comment attributes.
+func comments(attrs []schema.Attr) []schema.Attr {
+	var cs []schema.Attr
+	for _, a := range attrs {
+		if c, ok := a.(*schema.Comment); ok {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+// attrName returns the qualified name of the attribute type (e.g. mysql.Partition).
+func attrName(a interface{}) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", a), "*")
+}
+
+// objectName returns a description of the schema object (e.g. sequence "seq").
+func objectName(o schema.Object) string {
+	v := reflect.Indirect(reflect.ValueOf(o))
+	k := strings.ToLower(v.Type().Name())
+	if f := v.FieldByName("Name"); f.IsValid() && f.Kind() == reflect.String {
+		return fmt.Sprintf("%s %q", k, f.String())
+	}
+	return k
+}
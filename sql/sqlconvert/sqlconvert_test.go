@@ -0,0 +1,407 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlconvert_test
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlconvert"
+	"ariga.io/atlas/sql/sqlite"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert_MySQLToPostgres(t *testing.T) {
+	r := mysqlRealm(t)
+	losses, err := sqlconvert.Convert(r, sqlconvert.MySQL, sqlconvert.Postgres)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`app: collation "utf8mb4_0900_ai_ci" is dropped`,
+		`app.users.tags: set is converted to text[] without restricting its values to 'a', 'b'`,
+		`app.users.updated_at: ON UPDATE CURRENT_TIMESTAMP is dropped`,
+		`app.users.name: prefix length 10 of column "name" is dropped`,
+		`app.users.bio: index type FULLTEXT is converted to the default index type`,
+		`app.posts.user_id: unsigned bigint is converted to a signed type`,
+		`app.posts.price: unsigned decimal is converted to a signed type`,
+		`app.posts.year: year is converted to smallint`,
+		`app.posts.name: "name" is renamed to "posts_name" to avoid a name conflict`,
+	}, strs(losses))
+
+	users, ok := r.Schemas[0].Table("users")
+	require.True(t, ok)
+	name, ok := users.Column("name")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&schema.Collation{V: "C"}}, name.Attrs)
+	require.Equal(t, "character varying(255)", name.Type.Raw)
+
+	b, err := postgres.MarshalHCL(r)
+	require.NoError(t, err)
+	require.Equal(t, `table "users" {
+  schema = schema.app
+  column "id" {
+    null = false
+    type = bigint
+    identity {
+      generated = BY_DEFAULT
+      start     = 100
+    }
+  }
+  column "name" {
+    null = false
+    type = character_varying(255)
+  }
+  column "bio" {
+    null = true
+    type = text
+  }
+  column "role" {
+    null    = false
+    type    = enum.users_role
+    default = "user"
+  }
+  column "tags" {
+    null = true
+    type = sql("text[]")
+  }
+  column "active" {
+    null    = false
+    type    = boolean
+    default = true
+  }
+  column "created_at" {
+    null    = false
+    type    = timestamptz
+    default = sql("CURRENT_TIMESTAMP")
+  }
+  column "updated_at" {
+    null    = false
+    type    = timestamptz
+    default = sql("CURRENT_TIMESTAMP")
+  }
+  primary_key {
+    columns = [column.id]
+  }
+  index "name" {
+    unique  = true
+    columns = [column.name]
+  }
+  index "bio" {
+    columns = [column.bio]
+  }
+}
+table "posts" {
+  schema = schema.app
+  column "id" {
+    null = false
+    type = integer
+    identity {
+      generated = BY_DEFAULT
+    }
+  }
+  column "user_id" {
+    null = false
+    type = bigint
+  }
+  column "price" {
+    null = false
+    type = numeric(10,2)
+  }
+  column "year" {
+    null = false
+    type = smallint
+  }
+  primary_key {
+    columns = [column.id]
+  }
+  foreign_key "owner" {
+    columns     = [column.user_id]
+    ref_columns = [table.users.column.id]
+    on_delete   = CASCADE
+  }
+  index "posts_name" {
+    columns = [column.user_id]
+  }
+}
+schema "app" {
+}
+enum "users_role" {
+  schema = schema.app
+  values = ["admin", "user"]
+}
+`, string(b))
+}
+
+func TestConvert_MySQLToSQLite(t *testing.T) {
+	r := mysqlRealm(t)
+	losses, err := sqlconvert.Convert(r, sqlconvert.MySQL, sqlconvert.SQLite)
+	require.NoError(t, err)
+	require.Contains(t, strs(losses), "main.posts.year: year is converted to integer")
+	require.Equal(t, "main", r.Schemas[0].Name)
+
+	users, ok := r.Schemas[0].Table("users")
+	require.True(t, ok)
+	inc := &sqlite.AutoIncrement{Seq: 99}
+	require.Equal(t, []schema.Attr{inc}, users.Columns[0].Attrs)
+	require.Equal(t, []schema.Attr{inc}, users.PrimaryKey.Attrs)
+	require.Equal(t, []schema.Attr{&schema.Collation{V: "BINARY"}}, users.Columns[1].Attrs)
+	require.Equal(t, []schema.Attr{
+		&schema.Check{Name: "users_role_check", Expr: `"role" IN ('admin', 'user')`},
+	}, users.Attrs)
+
+	b, err := sqlite.MarshalHCL(r)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `  column "role" {
+    null    = false
+    type    = text
+    default = "user"
+  }`)
+	require.Contains(t, string(b), `  column "created_at" {
+    null    = false
+    type    = datetime
+    default = sql("CURRENT_TIMESTAMP")
+  }`)
+}
+
+func TestConvert_PostgresToMySQL(t *testing.T) {
+	r := &schema.Realm{}
+	require.NoError(t, postgres.EvalHCLBytes([]byte(`
+schema "public" {}
+table "users" {
+  schema = schema.public
+  column "id" {
+    type = bigserial
+  }
+  column "uid" {
+    type    = uuid
+    default = sql("gen_random_uuid()")
+  }
+  column "email" {
+    type = text
+  }
+  column "tags" {
+    type = sql("text[]")
+    null = true
+  }
+  column "seen" {
+    type    = timestamptz(3)
+    default = sql("now()")
+  }
+  column "n" {
+    type = numeric
+  }
+  primary_key {
+    columns = [column.id]
+  }
+  index "users_email" {
+    unique  = true
+    columns = [column.email]
+    where   = "email IS NOT NULL"
+  }
+}
+table "posts" {
+  schema = schema.public
+  column "id" {
+    type = integer
+    identity {
+      generated = ALWAYS
+      start     = 10
+    }
+  }
+  column "user_id" {
+    type = bigint
+    null = true
+  }
+  primary_key {
+    columns = [column.id]
+  }
+  foreign_key "fk" {
+    columns     = [column.user_id]
+    ref_columns = [table.users.column.id]
+    on_delete   = SET_DEFAULT
+  }
+}
+table "comments" {
+  schema = schema.public
+  column "post_id" {
+    type = integer
+  }
+  foreign_key "fk" {
+    columns     = [column.post_id]
+    ref_columns = [table.posts.column.id]
+  }
+}
+`), r, nil))
+	losses, err := sqlconvert.Convert(r, sqlconvert.Postgres, sqlconvert.MySQL)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`public.users.uid: uuid is converted to char(36)`,
+		`public.users.tags: array text[] is converted to json`,
+		`public.users.n: numeric without a precision in range [1, 65] is converted to decimal(65,30)`,
+		`public.users.users_email: partial index predicate "email IS NOT NULL" is dropped`,
+		`public.users.users_email: prefix length 255 is added to column "email"`,
+		`public.posts.id: GENERATED ALWAYS is converted to a column that accepts explicit values`,
+		`public.posts.fk: SET DEFAULT is converted to NO ACTION`,
+		`public.comments.fk: "fk" is renamed to "comments_fk" to avoid a name conflict`,
+	}, strs(losses))
+
+	users, posts := r.Schemas[0].Tables[0], r.Schemas[0].Tables[1]
+	require.Equal(t, &schema.IntegerType{T: "bigint"}, users.Columns[0].Type.Type)
+	require.Equal(t, []schema.Attr{&mysql.AutoIncrement{}}, users.Columns[0].Attrs)
+	require.Equal(t, &schema.RawExpr{X: "(uuid())"}, users.Columns[1].Default)
+	require.Equal(t, &schema.StringType{T: "longtext"}, users.Columns[2].Type.Type)
+	require.Equal(t, []schema.Attr{&mysql.SubPart{Len: 255}}, users.Indexes[0].Parts[0].Attrs)
+	require.Equal(t, "timestamp(3)", users.Columns[4].Type.Raw)
+	require.Equal(t, &schema.RawExpr{X: "CURRENT_TIMESTAMP(3)"}, users.Columns[4].Default)
+	require.Equal(t, []schema.Attr{&mysql.AutoIncrement{V: 10}}, posts.Attrs)
+	require.Equal(t, schema.NoAction, posts.ForeignKeys[0].OnDelete)
+
+	b, err := mysql.MarshalHCL(r)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `  column "id" {
+    null           = false
+    type           = bigint
+    auto_increment = true
+  }`)
+}
+
+func TestConvert_SQLiteToMySQL(t *testing.T) {
+	r := &schema.Realm{}
+	p := sqlite.NewDDLParser("")
+	for _, stmt := range []string{
+		"CREATE TABLE `users` (`id` integer PRIMARY KEY AUTOINCREMENT, `name` text COLLATE NOCASE, `data`)",
+		"CREATE INDEX `users_name` ON `users` (`name`) WHERE `name` IS NOT NULL",
+	} {
+		_, err := p.ParseDDL(r, stmt)
+		require.NoError(t, err)
+	}
+	users := r.Schemas[0].Tables[0]
+	users.Columns[0].Attrs[0].(*sqlite.AutoIncrement).Seq = 41
+	losses, err := sqlconvert.Convert(r, sqlconvert.SQLite, sqlconvert.MySQL)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"main.users.data: column without a type is converted to longtext",
+		"main.users.users_name: partial index predicate \"`name` IS NOT NULL\" is dropped",
+		`main.users.users_name: prefix length 255 is added to column "name"`,
+	}, strs(losses))
+	require.False(t, users.Columns[0].Type.Null, "primary-key columns are not nullable")
+	require.Equal(t, &schema.IntegerType{T: "bigint"}, users.Columns[0].Type.Type)
+	require.Equal(t, []schema.Attr{&mysql.AutoIncrement{}}, users.Columns[0].Attrs)
+	require.Equal(t, []schema.Attr{&mysql.AutoIncrement{V: 42}}, users.Attrs)
+	require.Empty(t, users.PrimaryKey.Attrs)
+	require.Equal(t, []schema.Attr{&schema.Collation{V: "utf8mb4_general_ci"}}, users.Columns[1].Attrs)
+}
+
+func TestConvert_Errors(t *testing.T) {
+	_, err := sqlconvert.Convert(&schema.Realm{}, sqlconvert.MySQL, "oracle")
+	require.EqualError(t, err, `sqlconvert: unsupported dialect "oracle"`)
+
+	r := mysqlRealm(t)
+	losses, err := sqlconvert.Convert(r, sqlconvert.MySQL, sqlconvert.MySQL)
+	require.NoError(t, err)
+	require.Empty(t, losses)
+	require.Equal(t, mysqlRealm(t), r)
+}
+
+func strs(losses []*sqlconvert.Loss) []string {
+	s := make([]string, len(losses))
+	for i, l := range losses {
+		s[i] = l.String()
+	}
+	return s
+}
+
+func mysqlRealm(t *testing.T) *schema.Realm {
+	r := &schema.Realm{}
+	require.NoError(t, mysql.EvalHCLBytes([]byte(`
+schema "app" {
+  charset = "utf8mb4"
+  collate = "utf8mb4_0900_ai_ci"
+}
+table "users" {
+  schema         = schema.app
+  auto_increment = 100
+  column "id" {
+    type           = bigint
+    auto_increment = true
+  }
+  column "name" {
+    type    = varchar(255)
+    collate = "utf8mb4_bin"
+  }
+  column "bio" {
+    type = longtext
+    null = true
+  }
+  column "role" {
+    type    = enum("admin", "user")
+    default = "user"
+  }
+  column "tags" {
+    type = set("a", "b")
+    null = true
+  }
+  column "active" {
+    type    = bool
+    default = 1
+  }
+  column "created_at" {
+    type    = timestamp
+    default = sql("CURRENT_TIMESTAMP")
+  }
+  column "updated_at" {
+    type      = timestamp
+    default   = sql("CURRENT_TIMESTAMP")
+    on_update = sql("CURRENT_TIMESTAMP")
+  }
+  primary_key {
+    columns = [column.id]
+  }
+  index "name" {
+    unique = true
+    on {
+      column = column.name
+      prefix = 10
+    }
+  }
+  index "bio" {
+    type    = FULLTEXT
+    columns = [column.bio]
+  }
+}
+table "posts" {
+  schema = schema.app
+  column "id" {
+    type           = int
+    auto_increment = true
+  }
+  column "user_id" {
+    type     = bigint
+    unsigned = true
+  }
+  column "price" {
+    type     = decimal(10,2)
+    unsigned = true
+  }
+  column "year" {
+    type = year
+  }
+  primary_key {
+    columns = [column.id]
+  }
+  index "name" {
+    columns = [column.user_id]
+  }
+  foreign_key "owner" {
+    columns     = [column.user_id]
+    ref_columns = [table.users.column.id]
+    on_delete   = CASCADE
+  }
+}
+`), r, nil))
+	return r
+}
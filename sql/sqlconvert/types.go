@@ -0,0 +1,430 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlconvert
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ariga.io/atlas/sql/mysql"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+	"ariga.io/atlas/sql/sqlite"
+)
+
+// The mapping tables below describe the type names of each dialect. The source
+// tables map a type name to its size or kind, and the target tables map the size
+// or kind back to a type name in the target dialect. Note, the SQLite entries are
+// based on the declared type names, as all its types share the same storage classes.
+var (
+	// intSize maps integer types to their storage size in bytes.
+	intSize = map[string]map[string]int{
+		MySQL: {
+			mysql.TypeTinyInt: 1, mysql.TypeSmallInt: 2, mysql.TypeMediumInt: 3,
+			mysql.TypeInt: 4, "integer": 4, mysql.TypeBigInt: 8,
+		},
+		Postgres: {
+			postgres.TypeSmallInt: 2, postgres.TypeInt2: 2, postgres.TypeInteger: 4,
+			postgres.TypeInt: 4, postgres.TypeInt4: 4, postgres.TypeBigInt: 8, postgres.TypeInt8: 8,
+		},
+		SQLite: {
+			"tinyint": 1, "smallint": 2, "int2": 2, "mediumint": 3, "int": 4,
+			sqlite.TypeInteger: 8, "bigint": 8, "int8": 8, "unsigned big int": 8,
+		},
+	}
+	// intType maps a storage size in bytes to an integer type.
+	intType = map[string]map[int]string{
+		MySQL:    {1: mysql.TypeTinyInt, 2: mysql.TypeSmallInt, 3: mysql.TypeMediumInt, 4: mysql.TypeInt, 8: mysql.TypeBigInt},
+		Postgres: {1: postgres.TypeSmallInt, 2: postgres.TypeSmallInt, 3: postgres.TypeInteger, 4: postgres.TypeInteger, 8: postgres.TypeBigInt},
+		SQLite:   {1: sqlite.TypeInteger, 2: sqlite.TypeInteger, 3: sqlite.TypeInteger, 4: sqlite.TypeInteger, 8: sqlite.TypeInteger},
+	}
+	// serialSize maps the PostgreSQL serial types to their storage size in bytes.
+	serialSize = map[string]int{
+		postgres.TypeSmallSerial: 2, postgres.TypeSerial2: 2,
+		postgres.TypeSerial: 4, postgres.TypeSerial4: 4,
+		postgres.TypeBigSerial: 8, postgres.TypeSerial8: 8,
+	}
+
+	// floatSize maps floating-point types to their storage size in bytes.
+	floatSize = map[string]map[string]int{
+		MySQL:    {mysql.TypeFloat: 4, mysql.TypeDouble: 8, mysql.TypeReal: 8},
+		Postgres: {postgres.TypeReal: 4, postgres.TypeFloat4: 4, postgres.TypeDouble: 8, postgres.TypeFloat8: 8, "float": 8},
+		SQLite:   {sqlite.TypeReal: 8, "double": 8, "double precision": 8, "float": 8},
+	}
+	// floatType maps a storage size in bytes to a floating-point type.
+	floatType = map[string]map[int]string{
+		MySQL:    {4: mysql.TypeFloat, 8: mysql.TypeDouble},
+		Postgres: {4: postgres.TypeReal, 8: postgres.TypeDouble},
+		SQLite:   {4: sqlite.TypeReal, 8: sqlite.TypeReal},
+	}
+
+	// decimalType holds the decimal type of each dialect.
+	decimalType = map[string]string{
+		MySQL:    mysql.TypeDecimal,
+		Postgres: postgres.TypeNumeric,
+		SQLite:   "decimal",
+	}
+
+	// strKind maps string types to one of: "char", "varchar" or "text".
+	strKind = map[string]map[string]string{
+		MySQL: {
+			mysql.TypeChar: "char", mysql.TypeVarchar: "varchar", mysql.TypeTinyText: "text",
+			mysql.TypeText: "text", mysql.TypeMediumText: "text", mysql.TypeLongText: "text",
+		},
+		Postgres: {
+			postgres.TypeCharacter: "char", postgres.TypeChar: "char", postgres.TypeCharVar: "varchar",
+			postgres.TypeVarChar: "varchar", postgres.TypeText: "text",
+		},
+		SQLite: {
+			"character": "char", "char": "char", "nchar": "char", "native character": "char",
+			"varchar": "varchar", "varying character": "varchar", "nvarchar": "varchar",
+			sqlite.TypeText: "text", "clob": "text",
+		},
+	}
+	// strType maps a string kind to a string type.
+	strType = map[string]map[string]string{
+		MySQL:    {"char": mysql.TypeChar, "varchar": mysql.TypeVarchar, "text": mysql.TypeLongText},
+		Postgres: {"char": postgres.TypeCharacter, "varchar": postgres.TypeCharVar, "text": postgres.TypeText},
+		SQLite:   {"char": "character", "varchar": "varchar", "text": sqlite.TypeText},
+	}
+
+	// binKind maps binary types to one of: "binary", "varbinary" or "blob".
+	binKind = map[string]map[string]string{
+		MySQL: {
+			mysql.TypeBinary: "binary", mysql.TypeVarBinary: "varbinary", mysql.TypeTinyBlob: "blob",
+			mysql.TypeBlob: "blob", mysql.TypeMediumBlob: "blob", mysql.TypeLongBlob: "blob",
+		},
+		Postgres: {postgres.TypeBytea: "blob"},
+		SQLite:   {sqlite.TypeBlob: "blob"},
+	}
+	// binType maps a binary kind to a binary type.
+	binType = map[string]map[string]string{
+		MySQL:    {"binary": mysql.TypeBinary, "varbinary": mysql.TypeVarBinary, "blob": mysql.TypeLongBlob},
+		Postgres: {"binary": postgres.TypeBytea, "varbinary": postgres.TypeBytea, "blob": postgres.TypeBytea},
+		SQLite:   {"binary": sqlite.TypeBlob, "varbinary": sqlite.TypeBlob, "blob": sqlite.TypeBlob},
+	}
+
+	// timeKind maps time types to one of: "date", "datetime",
+	// "timestamptz", "time", "timetz" or "year".
+	timeKind = map[string]map[string]string{
+		MySQL: {
+			mysql.TypeDate: "date", mysql.TypeDateTime: "datetime", mysql.TypeTimestamp: "timestamptz",
+			mysql.TypeTime: "time", mysql.TypeYear: "year",
+		},
+		Postgres: {
+			postgres.TypeDate: "date", postgres.TypeTimestamp: "datetime", postgres.TypeTimestampWOTZ: "datetime",
+			postgres.TypeTimestampTZ: "timestamptz", postgres.TypeTimestampWTZ: "timestamptz",
+			postgres.TypeTime: "time", postgres.TypeTimeWOTZ: "time", postgres.TypeTimeTZ: "timetz", postgres.TypeTimeWTZ: "timetz",
+		},
+		SQLite: {"date": "date", "datetime": "datetime", "timestamp": "datetime", "time": "time"},
+	}
+	// timeType maps a time kind to a time type. Kinds that are missing
+	// from the table are converted with a loss of information.
+	timeType = map[string]map[string]string{
+		MySQL: {
+			"date": mysql.TypeDate, "datetime": mysql.TypeDateTime, "timestamptz": mysql.TypeTimestamp,
+			"time": mysql.TypeTime, "year": mysql.TypeYear,
+		},
+		Postgres: {
+			"date": postgres.TypeDate, "datetime": postgres.TypeTimestamp, "timestamptz": postgres.TypeTimestampTZ,
+			"time": postgres.TypeTime, "timetz": postgres.TypeTimeTZ,
+		},
+		SQLite: {"date": "date", "datetime": "datetime", "timestamptz": "datetime", "time": "time"},
+	}
+
+	// jsonType holds the JSON type of each dialect.
+	jsonType = map[string]string{
+		MySQL:    mysql.TypeJSON,
+		Postgres: postgres.TypeJSONB,
+		SQLite:   "json",
+	}
+
+	// boolType holds the boolean type of each dialect.
+	boolType = map[string]string{
+		MySQL:    mysql.TypeBool,
+		Postgres: postgres.TypeBoolean,
+		SQLite:   "boolean",
+	}
+
+	// netSize maps the PostgreSQL network address types to the
+	// length of their longest textual representation.
+	netSize = map[string]int{
+		postgres.TypeInet: 43, postgres.TypeCIDR: 43,
+		postgres.TypeMACAddr: 17, postgres.TypeMACAddr8: 23,
+	}
+
+	// spatialType maps spatial types to the spatial types of MySQL.
+	// Types that are missing from the table are converted to GEOMETRY.
+	spatialType = map[string]string{
+		mysql.TypeGeometry: mysql.TypeGeometry, mysql.TypePoint: mysql.TypePoint,
+		mysql.TypeMultiPoint: mysql.TypeMultiPoint, mysql.TypeLineString: mysql.TypeLineString,
+		mysql.TypeMultiLineString: mysql.TypeMultiLineString, mysql.TypePolygon: mysql.TypePolygon,
+		mysql.TypeMultiPolygon: mysql.TypeMultiPolygon, mysql.TypeGeoCollection: mysql.TypeGeoCollection,
+		mysql.TypeGeometryCollection: mysql.TypeGeometryCollection,
+	}
+
+	// formatters holds the type formatters of each dialect.
+	formatters = map[string]func(schema.Type) (string, error){
+		MySQL:    mysql.FormatType,
+		Postgres: postgres.FormatType,
+		SQLite:   sqlite.FormatType,
+	}
+)
+
+// columnType converts the type of the given column to the target dialect.
+func (c *converter) columnType(t *schema.Table, col *schema.Column) {
+	path, raw := c.path(t, col.Name), col.Type.Raw
+	if raw == "" {
+		raw, _ = formatters[c.from](col.Type.Type)
+	}
+	switch typ := col.Type.Type.(type) {
+	case *schema.IntegerType:
+		n, ok := intSize[c.from][strings.ToLower(typ.T)]
+		if !ok {
+			n = 8
+		}
+		col.Type.Type = c.intType(path, n, typ.Unsigned || typ.T == "unsigned big int")
+		for _, a := range typ.Attrs {
+			if _, ok := a.(*mysql.ZeroFill); ok {
+				c.lossf(path, "ZEROFILL is not supported")
+			}
+		}
+	case *postgres.SerialType:
+		n, ok := serialSize[strings.ToLower(typ.T)]
+		if !ok {
+			n = 4
+		}
+		col.Type.Type = c.intType(path, n, false)
+	case *schema.BoolType:
+		col.Type.Type = &schema.BoolType{T: boolType[c.to]}
+	case *schema.FloatType:
+		n, ok := floatSize[c.from][strings.ToLower(typ.T)]
+		if !ok || typ.T == mysql.TypeFloat && typ.Precision > 24 {
+			n = 8
+		}
+		col.Type.Type = &schema.FloatType{T: floatType[c.to][n], Unsigned: typ.Unsigned && c.to == MySQL}
+		if typ.Unsigned && c.to != MySQL {
+			c.lossf(path, "unsigned %s is converted to a signed type", typ.T)
+		}
+	case *schema.DecimalType:
+		d := &schema.DecimalType{T: decimalType[c.to], Precision: typ.Precision, Scale: typ.Scale, Unsigned: typ.Unsigned && c.to == MySQL}
+		if typ.Unsigned && c.to != MySQL {
+			c.lossf(path, "unsigned %s is converted to a signed type", typ.T)
+		}
+		if c.to == MySQL && (d.Precision == 0 || d.Precision > 65) {
+			c.lossf(path, "%s without a precision in range [1, 65] is converted to decimal(65,30)", typ.T)
+			d.Precision, d.Scale = 65, 30
+		}
+		col.Type.Type = d
+	case *postgres.CurrencyType:
+		col.Type.Type = &schema.DecimalType{T: decimalType[c.to], Precision: 19, Scale: 2}
+		c.lossf(path, "%s is converted to decimal(19,2)", typ.T)
+	case *schema.StringType:
+		k, ok := strKind[c.from][strings.ToLower(typ.T)]
+		if !ok {
+			k = "text"
+		}
+		s := &schema.StringType{T: strType[c.to][k], Size: typ.Size}
+		switch {
+		case k == "text":
+			s.Size = 0
+		case c.to == MySQL && k == "varchar" && s.Size == 0:
+			s.T = mysql.TypeLongText
+		case c.to == MySQL && k == "char" && s.Size > 255:
+			s.T = mysql.TypeVarchar
+			c.lossf(path, "char(%d) is converted to varchar(%d)", typ.Size, typ.Size)
+		}
+		col.Type.Type = s
+	case *schema.BinaryType:
+		k, ok := binKind[c.from][strings.ToLower(typ.T)]
+		if !ok {
+			k = "blob"
+		}
+		b := &schema.BinaryType{T: binType[c.to][k]}
+		switch {
+		case c.to == MySQL && k != "blob":
+			b.Size = typ.Size
+		case c.to == Postgres && k != "blob" && typ.Size > 0:
+			c.lossf(path, "the size of %s(%d) is not supported by %s", typ.T, typ.Size, b.T)
+		}
+		col.Type.Type = b
+	case *schema.TimeType:
+		c.timeType(path, col, typ)
+	case *schema.JSONType:
+		col.Type.Type = &schema.JSONType{T: jsonType[c.to]}
+	case *schema.EnumType:
+		c.enumType(t, col, typ.Values)
+	case *mysql.SetType:
+		if c.to == Postgres {
+			col.Type.Type = &postgres.ArrayType{T: "text[]", Type: &schema.StringType{T: postgres.TypeText}}
+		} else {
+			col.Type.Type = &schema.StringType{T: strType[c.to]["text"]}
+		}
+		c.lossf(path, "set is converted to %s without restricting its values to %s", c.format(col.Type.Type), quote(typ.Values))
+	case *mysql.BitType:
+		n := bitLen(raw)
+		if c.to == Postgres {
+			col.Type.Type = &postgres.BitType{T: postgres.TypeBit, Len: n}
+		} else {
+			col.Type.Type = &schema.IntegerType{T: intType[c.to][8]}
+			c.lossf(path, "bit(%d) is converted to %s", n, c.format(col.Type.Type))
+		}
+	case *postgres.BitType:
+		if c.to == MySQL && strings.ToLower(typ.T) == postgres.TypeBit && typ.Len <= 1 {
+			col.Type.Type = &mysql.BitType{T: mysql.TypeBit}
+		} else {
+			col.Type.Type = &schema.BinaryType{T: binType[c.to]["blob"]}
+			c.lossf(path, "%s is converted to %s", raw, c.format(col.Type.Type))
+		}
+	case *postgres.UUIDType, *sqlite.UUIDType:
+		switch c.to {
+		case Postgres:
+			col.Type.Type = &postgres.UUIDType{T: postgres.TypeUUID}
+		case SQLite:
+			col.Type.Type = &sqlite.UUIDType{T: "uuid"}
+		default:
+			col.Type.Type = &schema.StringType{T: mysql.TypeChar, Size: 36}
+			c.lossf(path, "uuid is converted to char(36)")
+		}
+	case *postgres.NetworkType:
+		c.textType(path, col, raw, netSize[strings.ToLower(typ.T)])
+	case *postgres.IntervalType:
+		c.textType(path, col, raw, 255)
+	case *postgres.XMLType:
+		c.textType(path, col, raw, 0)
+	case *postgres.ArrayType:
+		col.Type.Type = &schema.JSONType{T: jsonType[c.to]}
+		c.lossf(path, "array %s is converted to %s", typ.T, c.format(col.Type.Type))
+	case *schema.SpatialType:
+		switch T, ok := spatialType[strings.ToLower(typ.T)]; {
+		case c.to == MySQL && ok:
+			col.Type.Type = &schema.SpatialType{T: T}
+		case c.to == MySQL:
+			col.Type.Type = &schema.SpatialType{T: mysql.TypeGeometry}
+			c.lossf(path, "%s is converted to geometry", typ.T)
+		default:
+			col.Type.Type = &schema.UnsupportedType{T: "geometry"}
+			c.lossf(path, "%s is converted to geometry which requires a spatial extension", typ.T)
+		}
+	case *schema.UnsupportedType:
+		if typ.T == "" {
+			col.Type.Type = &schema.StringType{T: strType[c.to]["text"]}
+			c.lossf(path, "column without a type is converted to %s", c.format(col.Type.Type))
+		} else {
+			c.lossf(path, "type %q is not supported and is kept as is", typ.T)
+		}
+	default:
+		if raw == "" {
+			raw = fmt.Sprintf("%T", typ)
+		}
+		col.Type.Type = &schema.UnsupportedType{T: raw}
+		c.lossf(path, "type %q is not supported and is kept as is", raw)
+	}
+	col.Type.Raw = c.format(col.Type.Type)
+}
+
+// intType returns the integer type that can hold values of the given size.
+// Unsigned integers are widened to the next size in dialects without them.
+func (c *converter) intType(path string, n int, unsigned bool) *schema.IntegerType {
+	switch {
+	case c.to == MySQL:
+		return &schema.IntegerType{T: intType[c.to][n], Unsigned: unsigned}
+	case unsigned && n < 8:
+		n *= 2
+		if n == 6 {
+			n = 8
+		}
+	case unsigned:
+		c.lossf(path, "unsigned bigint is converted to a signed type")
+	}
+	return &schema.IntegerType{T: intType[c.to][n]}
+}
+
+// timeType converts a time type to the target dialect.
+func (c *converter) timeType(path string, col *schema.Column, t *schema.TimeType) {
+	k, ok := timeKind[c.from][strings.ToLower(t.T)]
+	if !ok {
+		k = "datetime"
+	}
+	T, ok := timeType[c.to][k]
+	switch {
+	case ok:
+	case k == "year":
+		col.Type.Type = &schema.IntegerType{T: intType[c.to][2]}
+		c.lossf(path, "year is converted to %s", c.format(col.Type.Type))
+		return
+	case k == "timetz":
+		T = timeType[c.to]["time"]
+		c.lossf(path, "time zone of %s is dropped", t.T)
+	}
+	tt := &schema.TimeType{T: T, Precision: t.Precision}
+	if c.to == SQLite {
+		tt.Precision = nil
+	}
+	col.Type.Type = tt
+}
+
+// textType converts a type without an equivalent in the target dialect
+// to a string type. A non-zero size is used for the MySQL VARCHAR type.
+func (c *converter) textType(path string, col *schema.Column, raw string, size int) {
+	s := &schema.StringType{T: strType[c.to]["text"]}
+	if c.to == MySQL && size > 0 {
+		s.T, s.Size = mysql.TypeVarchar, size
+	}
+	c.lossf(path, "%s is converted to %s", raw, c.format(s))
+	col.Type.Type = s
+}
+
+// enumType converts an enum type to the target dialect. PostgreSQL enums are
+// named types, and SQLite enums are emulated using a CHECK constraint.
+func (c *converter) enumType(t *schema.Table, col *schema.Column, values []string) {
+	switch c.to {
+	case MySQL:
+		col.Type.Type = &schema.EnumType{T: mysql.TypeEnum, Values: values}
+	case Postgres:
+		col.Type.Type = &schema.EnumType{T: fmt.Sprintf("%s_%s", t.Name, col.Name), Values: values}
+	default:
+		col.Type.Type = &schema.StringType{T: sqlite.TypeText}
+		t.AddChecks(&schema.Check{
+			Name: fmt.Sprintf("%s_%s_check", t.Name, col.Name),
+			Expr: fmt.Sprintf("%q IN (%s)", col.Name, quote(values)),
+		})
+	}
+}
+
+// format returns the formatted type in the target dialect.
+func (c *converter) format(t schema.Type) string {
+	if u, ok := t.(*schema.UnsupportedType); ok {
+		return u.T
+	}
+	f, err := formatters[c.to](t)
+	if err != nil {
+		return ""
+	}
+	return f
+}
+
+// bitLen returns the length of the given MySQL BIT type.
+func bitLen(raw string) int64 {
+	i, j := strings.IndexByte(raw, '('), strings.IndexByte(raw, ')')
+	if i == -1 || j < i {
+		return 1
+	}
+	n, err := strconv.ParseInt(raw[i+1:j], 10, 64)
+	if err != nil {
+		return 1
+	}
+	return n
+}
+
+// quote returns the values as a list of quoted SQL strings.
+func quote(values []string) string {
+	q := make([]string, len(values))
+	for i, v := range values {
+		q[i] = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	}
+	return strings.Join(q, ", ")
+}
@@ -0,0 +1,110 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+
+	"ariga.io/atlas/sql/internal/sqlx"
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// NewDDLParser returns a migrate.DDLParser for SQLite statements. Tables with
+// unqualified names are created in the schema (database file) with the given
+// name, or in "main" if it is empty.
+func NewDDLParser(name string) migrate.DDLParser {
+	if name == "" {
+		name = mainFile
+	}
+	return &sqlx.DDLParser{
+		DDLDriver: ddl{},
+		Schema:    name,
+		Quotes:    "\"`[",
+		NullPK:    true,
+	}
+}
+
+// ddl implements the sqlx.DDLDriver interface for SQLite.
+type ddl struct{}
+
+// ParseType implements sqlx.DDLDriver.
+func (ddl) ParseType(t string) (schema.Type, error) {
+	return ParseType(t)
+}
+
+// DefaultExpr implements sqlx.DDLDriver.
+func (ddl) DefaultExpr(_ *schema.Column, x string) schema.Expr {
+	return defaultExpr(x)
+}
+
+// ParseOption implements sqlx.DDLDriver.
+func (ddl) ParseOption(s *sqlx.DDLScanner, v interface{}) (bool, error) {
+	switch v := v.(type) {
+	case *schema.Table:
+		switch {
+		case s.Accept("WITHOUT", "ROWID"):
+			v.AddAttrs(&WithoutRowID{})
+		case s.Accept("STRICT"):
+		default:
+			return false, nil
+		}
+	case *schema.Column:
+		switch {
+		case s.Accept("AUTOINCREMENT"):
+			// Like inspection, both the column and its
+			// primary key are annotated with AUTOINCREMENT.
+			inc := &AutoIncrement{}
+			for _, idx := range v.Indexes {
+				if idx.Table != nil && idx.Table.PrimaryKey == idx {
+					idx.AddAttrs(inc)
+				}
+			}
+			v.AddAttrs(inc)
+		case s.Accept("ON", "CONFLICT"):
+			if _, err := s.Ident(); err != nil {
+				return false, err
+			}
+		default:
+			return false, nil
+		}
+	case *schema.Index:
+		if !s.Accept("WHERE") {
+			return false, nil
+		}
+		v.AddAttrs(&IndexPredicate{P: s.Rest()})
+	case *schema.ForeignKey:
+		switch {
+		case s.Accept("DEFERRABLE"), s.Accept("NOT", "DEFERRABLE"):
+			_ = s.Accept("INITIALLY", "DEFERRED") || s.Accept("INITIALLY", "IMMEDIATE")
+		default:
+			return false, nil
+		}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// DefaultName implements sqlx.DDLDriver.
+func (ddl) DefaultName(t *schema.Table, v interface{}) string {
+	idx, ok := v.(*schema.Index)
+	switch {
+	case !ok:
+		return ""
+	case idx == t.PrimaryKey:
+		return "PRIMARY"
+	}
+	// Unnamed UNIQUE constraints are backed
+	// by indexes that are created by SQLite.
+	var n int
+	for _, idx := range t.Indexes {
+		if strings.HasPrefix(idx.Name, "sqlite_autoindex_"+t.Name) {
+			n++
+		}
+	}
+	return fmt.Sprintf("sqlite_autoindex_%s_%d", t.Name, n+1)
+}
@@ -0,0 +1,56 @@
+// Copyright 2021-present The Atlas Authors. All rights reserved.
+// This source code is licensed under the Apache 2.0 license found
+// in the LICENSE file in the root directory of this source tree.
+
+package sqlite
+
+import (
+	"testing"
+
+	"ariga.io/atlas/sql/schema"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDDLParser(t *testing.T) {
+	var (
+		r = &schema.Realm{}
+		p = NewDDLParser("")
+	)
+	for _, stmt := range []string{
+		"PRAGMA foreign_keys = off",
+		"CREATE TABLE `users` (\n  [id] integer PRIMARY KEY AUTOINCREMENT,\n  name text NOT NULL UNIQUE,\n  data\n)",
+		`CREATE TABLE "posts" ("id" int NOT NULL, "user_id" int, PRIMARY KEY ("id"), CONSTRAINT "owner" FOREIGN KEY ("user_id") REFERENCES "users" ("id")) WITHOUT ROWID`,
+		`CREATE INDEX "posts_user" ON "posts" ("user_id") WHERE "user_id" IS NOT NULL`,
+	} {
+		_, err := p.ParseDDL(r, stmt)
+		require.NoError(t, err, stmt)
+	}
+	require.Len(t, r.Schemas, 1)
+	s := r.Schemas[0]
+	require.Equal(t, "main", s.Name)
+
+	users, ok := s.Table("users")
+	require.True(t, ok)
+	id, ok := users.Column("id")
+	require.True(t, ok)
+	require.Equal(t, &schema.IntegerType{T: "integer"}, id.Type.Type)
+	require.True(t, id.Type.Null, "primary-key columns are nullable in SQLite")
+	require.Equal(t, []schema.Attr{&AutoIncrement{}}, id.Attrs)
+	require.Equal(t, "PRIMARY", users.PrimaryKey.Name)
+	require.Equal(t, []schema.Attr{&AutoIncrement{}}, users.PrimaryKey.Attrs)
+	_, ok = users.Index("sqlite_autoindex_users_1")
+	require.True(t, ok)
+	data, ok := users.Column("data")
+	require.True(t, ok)
+	require.Equal(t, &schema.UnsupportedType{}, data.Type.Type)
+
+	posts, ok := s.Table("posts")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&WithoutRowID{}}, posts.Attrs)
+	require.Equal(t, "owner", posts.ForeignKeys[0].Symbol)
+	require.Equal(t, users, posts.ForeignKeys[0].RefTable)
+	idx, ok := posts.Index("posts_user")
+	require.True(t, ok)
+	require.Equal(t, []schema.Attr{&IndexPredicate{P: `"user_id" IS NOT NULL`}}, idx.Attrs)
+}
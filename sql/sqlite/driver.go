@@ -27,7 +27,7 @@ type (
 	// generating diff between schema elements and apply migrations changes.
 	Driver struct {
 		conn
-		schema.Differ
+		schema.OptionsDiffer
 		schema.Inspector
 		migrate.PlanApplier
 	}
@@ -79,10 +79,10 @@ func Open(db schema.ExecQuerier) (migrate.Driver, error) {
 		return nil, fmt.Errorf("sqlite: scanning database collations: %w", err)
 	}
 	return &Driver{
-		conn:        c,
-		Differ:      &sqlx.Diff{DiffDriver: &diff{c}},
-		Inspector:   &inspect{c},
-		PlanApplier: &planApply{c},
+		conn:          c,
+		OptionsDiffer: &sqlx.Diff{DiffDriver: &diff{c}},
+		Inspector:     &inspect{c},
+		PlanApplier:   &planApply{c},
 	}, nil
 }
 
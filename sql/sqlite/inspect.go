@@ -35,12 +35,19 @@ func (i *inspect) InspectRealm(ctx context.Context, opts *schema.InspectRealmOpt
 	if !sqlx.ModeInspectRealm(opts).Is(schema.InspectTables) {
 		return realm, nil
 	}
+	filter := &sqlx.NameFilter{}
+	if topts := sqlx.RealmTablesOptions(opts); topts != nil {
+		filter = sqlx.NewNameFilter(topts.Tables, topts.Exclude)
+	}
 	for _, s := range schemas {
 		tables, err := i.tables(ctx, nil)
 		if err != nil {
 			return nil, err
 		}
 		for _, t := range tables {
+			if !filter.Match(s.Name, t.Name) {
+				continue
+			}
 			t.Schema = s
 			t, err := i.inspectTable(ctx, t)
 			if err != nil {
@@ -351,14 +358,18 @@ func (i *inspect) addFKs(t *schema.Table, rows *sql.Rows) error {
 // tableNames returns a list of all tables exist in the schema.
 func (i *inspect) tables(ctx context.Context, opts *schema.InspectOptions) ([]*schema.Table, error) {
 	var (
-		args  []interface{}
-		query = tablesQuery
+		args   []interface{}
+		query  = tablesQuery
+		filter = &sqlx.NameFilter{}
 	)
-	if opts != nil && len(opts.Tables) > 0 {
-		query += " AND name IN (" + strings.Repeat("?, ", len(opts.Tables)-1) + "?)"
-		for _, s := range opts.Tables {
-			args = append(args, s)
-		}
+	if opts != nil {
+		filter = sqlx.NewNameFilter(opts.Tables, opts.Exclude)
+	}
+	if cond := filter.Cond([]string{"name"}, likeEscape, func(v string) string {
+		args = append(args, v)
+		return "?"
+	}); cond != "" {
+		query += " AND " + cond
 	}
 	rows, err := i.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -371,6 +382,10 @@ func (i *inspect) tables(ctx context.Context, opts *schema.InspectOptions) ([]*s
 		if err := rows.Scan(&name, &stmt); err != nil {
 			return nil, fmt.Errorf("sqlite: scanning table: %w", err)
 		}
+		// Patterns that cannot be expressed in the query are checked here.
+		if !filter.Match(name) {
+			continue
+		}
 		stmt = strings.TrimSpace(stmt)
 		t := &schema.Table{
 			Name: name,
@@ -389,12 +404,22 @@ func (i *inspect) tables(ctx context.Context, opts *schema.InspectOptions) ([]*s
 // schemas returns the list of the schemas in the database.
 func (i *inspect) databases(ctx context.Context, opts *schema.InspectRealmOption) ([]*schema.Schema, error) {
 	var (
-		args  []interface{}
-		query = databasesQuery
+		args   []interface{}
+		query  = databasesQuery
+		filter = sqlx.RealmSchemasFilter(opts)
+		glob   = len(filter.Patterns)+len(filter.Exclude) > 0
 	)
-	if opts != nil && len(opts.Schemas) > 0 {
-		query = fmt.Sprintf(databasesQueryArgs, strings.Repeat("?, ", len(opts.Schemas)-1)+"?")
-		for _, s := range opts.Schemas {
+	switch {
+	case glob:
+		if cond := filter.Cond([]string{"`name`"}, likeEscape, func(v string) string {
+			args = append(args, v)
+			return "?"
+		}); cond != "" {
+			query = fmt.Sprintf(databasesQueryFilter, cond)
+		}
+	case len(filter.Names) > 0:
+		query = fmt.Sprintf(databasesQueryArgs, strings.Repeat("?, ", len(filter.Names)-1)+"?")
+		for _, s := range filter.Names {
 			args = append(args, s)
 		}
 	}
@@ -409,6 +434,9 @@ func (i *inspect) databases(ctx context.Context, opts *schema.InspectRealmOption
 		if err := rows.Scan(&name, &file); err != nil {
 			return nil, err
 		}
+		if glob && !filter.Match(name.String) {
+			continue
+		}
 		// File is missing if the database is not
 		// associated with a file (:memory: mode).
 		if file.String == "" {
@@ -706,6 +734,10 @@ const (
 	// Query to list attached database files.
 	databasesQuery     = "SELECT `name`, `file` FROM pragma_database_list() WHERE `name` <> 'temp'"
 	databasesQueryArgs = "SELECT `name`, `file` FROM pragma_database_list() WHERE `name` IN (%s)"
+	// Query to list attached database files that match glob patterns.
+	databasesQueryFilter = "SELECT `name`, `file` FROM pragma_database_list() WHERE `name` <> 'temp' AND %s"
+	// The escape clause of LIKE expressions, as SQLite does not define a default one.
+	likeEscape = " ESCAPE '\\'"
 	// Query to list database tables.
 	tablesQuery = "SELECT `name`, `sql` FROM sqlite_master WHERE `type` = 'table' AND `name` NOT LIKE 'sqlite_%'"
 	// Query to list table information.